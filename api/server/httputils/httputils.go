@@ -209,6 +209,30 @@ func GetKubeAccessClusterFromContext(ctx context.Context) (string, error) {
 	return name, nil
 }
 
+const kubeAccessTokenJTIKey = "kube_access_token_jti"
+
+// SetKubeAccessTokenJTIToContext 记录 kube gateway 请求所使用 Access Token 的 JTI，供审计使用。
+func SetKubeAccessTokenJTIToContext(c *gin.Context, jti string) {
+	c.Set(kubeAccessTokenJTIKey, jti)
+}
+
+func GetKubeAccessTokenJTIFromContext(ctx context.Context) string {
+	jti, _ := ctx.Value(kubeAccessTokenJTIKey).(string)
+	return jti
+}
+
+const auditObjectDiffKey = "audit_object_diff"
+
+// SetAuditObjectDiffToContext 记录代理请求更新 K8s 对象的前后差异，由审计中间件关联到审计记录。
+func SetAuditObjectDiffToContext(c *gin.Context, diff *model.AuditObjectDiff) {
+	c.Set(auditObjectDiffKey, diff)
+}
+
+func GetAuditObjectDiffFromContext(ctx context.Context) *model.AuditObjectDiff {
+	diff, _ := ctx.Value(auditObjectDiffKey).(*model.AuditObjectDiff)
+	return diff
+}
+
 func GetObjectFromRequest(c *gin.Context) (string, string, bool) {
 	return getObjectFromRequest(c.Request.URL.Path)
 }
@@ -29,6 +29,7 @@ import (
 
 	"github.com/caoyingjunz/pixiu/api/server/httputils"
 	"github.com/caoyingjunz/pixiu/cmd/app/options"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 )
@@ -37,11 +38,20 @@ const (
 	defaultAuditQueueSize = 2048
 	defaultAuditWorkers   = 2
 	auditWriteTimeout     = 3 * time.Second
+
+	proxyPathPrefix       = "/pixiu/proxy/"
+	kubeGatewayPathPrefix = "/k8s/"
 )
 
 type auditRecorder struct {
 	factory db.ShareDaoFactory
-	queue   chan *model.Audit
+	queue   chan *auditEntry
+}
+
+// auditEntry 审计记录及其关联的对象差异（仅代理更新 K8s 对象时存在）
+type auditEntry struct {
+	record *model.Audit
+	diff   *model.AuditObjectDiff
 }
 
 var (
@@ -53,7 +63,7 @@ func getAuditRecorder(o *options.Options) *auditRecorder {
 	recorderOnce.Do(func() {
 		recorderInst = &auditRecorder{
 			factory: o.Factory,
-			queue:   make(chan *model.Audit, defaultAuditQueueSize),
+			queue:   make(chan *auditEntry, defaultAuditQueueSize),
 		}
 		for i := 0; i < defaultAuditWorkers; i++ {
 			go recorderInst.run()
@@ -63,27 +73,38 @@ func getAuditRecorder(o *options.Options) *auditRecorder {
 }
 
 func (r *auditRecorder) run() {
-	for record := range r.queue {
-		r.write(record)
+	for entry := range r.queue {
+		r.write(entry)
 	}
 }
 
-func (r *auditRecorder) write(record *model.Audit) {
+func (r *auditRecorder) write(entry *auditEntry) {
 	ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
 	defer cancel()
 
-	if _, err := r.factory.Audit().Create(ctx, record); err != nil {
-		klog.Errorf("failed to create audit record [%s]: %v", record.String(), err)
+	record, err := r.factory.Audit().Create(ctx, entry.record)
+	if err != nil {
+		klog.Errorf("failed to create audit record [%s]: %v", entry.record.String(), err)
+		return
+	}
+	auditsink.Publish(record)
+	if entry.diff == nil {
+		return
+	}
+
+	entry.diff.AuditId = record.Id
+	if _, err = r.factory.Audit().ObjectDiff().Create(ctx, entry.diff); err != nil {
+		klog.Errorf("failed to create audit object diff for audit %d: %v", record.Id, err)
 	}
 }
 
-func (r *auditRecorder) enqueue(record *model.Audit) {
+func (r *auditRecorder) enqueue(entry *auditEntry) {
 	select {
-	case r.queue <- record:
+	case r.queue <- entry:
 	default:
 		// 队列满时降级同步写入，确保非 GET 请求都能落库
-		klog.Warningf("audit queue is full, fallback to direct write: %s", record.Path)
-		r.write(record)
+		klog.Warningf("audit queue is full, fallback to direct write: %s", entry.record.Path)
+		r.write(entry)
 	}
 }
 
@@ -97,7 +118,10 @@ func Audit(o *options.Options) gin.HandlerFunc {
 
 		startTime := time.Now()
 		c.Next()
-		recorder.enqueue(buildAuditRecord(c, startTime))
+		recorder.enqueue(&auditEntry{
+			record: buildAuditRecord(c, startTime),
+			diff:   httputils.GetAuditObjectDiffFromContext(c),
+		})
 	}
 }
 
@@ -107,7 +131,11 @@ func buildAuditRecord(c *gin.Context, startTime time.Time) *model.Audit {
 		userName = user.Name
 	}
 
-	cluster, resourceName, resourceNamespace := parseK8sProxyPath(c.Request.URL.Path)
+	cluster, resource, resourceName, resourceNamespace := parseK8sProxyPath(c.Request.URL.Path)
+	// 代理更新对象时以实际对象的身份为准
+	if diff := httputils.GetAuditObjectDiffFromContext(c); diff != nil {
+		resourceName, resourceNamespace = diff.Name, diff.Namespace
+	}
 
 	return &model.Audit{
 		RequestId:         requestid.Get(c),
@@ -122,22 +150,27 @@ func buildAuditRecord(c *gin.Context, startTime time.Time) *model.Audit {
 		Cluster:           cluster,
 		ResourceName:      resourceName,
 		ResourceNamespace: resourceNamespace,
+		Resource:          resource,
+		TokenJTI:          httputils.GetKubeAccessTokenJTIFromContext(c),
 	}
 }
 
-// parseK8sProxyPath 从 K8s proxy URL 路径中解析集群、资源名称和命名空间。
-// 支持以下路径格式：
+// parseK8sProxyPath 从 K8s proxy / kube gateway URL 路径中解析集群、资源类型、资源名称和命名空间。
+// 支持以下路径格式（kube gateway 以 /k8s/{cluster} 为前缀，其余相同）：
 //   - /pixiu/proxy/{cluster}/api/v1/namespaces/{namespace}/{resource}/{name}
 //   - /pixiu/proxy/{cluster}/apis/{group}/{version}/namespaces/{namespace}/{resource}/{name}
 //   - /pixiu/proxy/{cluster}/api/v1/{resource}/{name}（集群级资源）
-func parseK8sProxyPath(path string) (cluster, resourceName, resourceNamespace string) {
-	// /pixiu/proxy/{cluster}/...
-	const proxyPrefix = "/pixiu/proxy/"
-	if !strings.HasPrefix(path, proxyPrefix) {
+func parseK8sProxyPath(path string) (cluster, resource, resourceName, resourceNamespace string) {
+	var rest string
+	switch {
+	case strings.HasPrefix(path, proxyPathPrefix):
+		rest = path[len(proxyPathPrefix):]
+	case strings.HasPrefix(path, kubeGatewayPathPrefix):
+		rest = path[len(kubeGatewayPathPrefix):]
+	default:
 		return
 	}
 
-	rest := path[len(proxyPrefix):]
 	parts := strings.SplitN(rest, "/", 2)
 	if len(parts) < 1 || parts[0] == "" {
 		return
@@ -147,21 +180,31 @@ func parseK8sProxyPath(path string) (cluster, resourceName, resourceNamespace st
 		return
 	}
 
-	segments := strings.Split(parts[1], "/")
-	// 查找 namespaces/{ns} 段
-	for i := 0; i < len(segments)-1; i++ {
-		if segments[i] == "namespaces" {
-			resourceNamespace = segments[i+1]
-			// namespace 之后还有 {resource}/{name}
-			if i+3 < len(segments) {
-				resourceName = segments[i+3]
-			}
+	// 跳过 api/{version} 或 apis/{group}/{version}
+	segments := strings.Split(strings.Trim(parts[1], "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return
+	}
+
+	// namespaces/{ns} 之后为 {resource}/{name}，否则为 namespace 对象本身
+	if len(segments) >= 2 && segments[0] == "namespaces" {
+		resourceNamespace = segments[1]
+		if len(segments) == 2 {
+			resource, resourceName = segments[0], segments[1]
 			return
 		}
+		segments = segments[2:]
 	}
-	// 无 namespaces 段，集群级资源：api/v1/{resource}/{name} 或 apis/.../.../{resource}/{name}
 	if len(segments) > 0 {
-		resourceName = segments[len(segments)-1]
+		resource = segments[0]
+	}
+	if len(segments) > 1 {
+		resourceName = segments[1]
 	}
 	return
 }
@@ -170,7 +213,8 @@ func shouldAudit(c *gin.Context) bool {
 	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodOptions {
 		return false
 	}
-	return strings.HasPrefix(c.Request.URL.Path, "/pixiu")
+	// kube gateway 仅审计变更类请求，与平台接口一致
+	return strings.HasPrefix(c.Request.URL.Path, "/pixiu") || strings.HasPrefix(c.Request.URL.Path, kubeGatewayPathPrefix)
 }
 
 func detectObjectType(c *gin.Context) model.ObjectType {
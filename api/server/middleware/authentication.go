@@ -92,6 +92,7 @@ func authenticateKubeGateway(c *gin.Context, o *options.Options) error {
 	}
 	httputils.SetUserToContext(c, user)
 	httputils.SetKubeAccessClusterToContext(c, rec.ClusterName)
+	httputils.SetKubeAccessTokenJTIToContext(c, rec.JTI)
 	return nil
 }
 
@@ -0,0 +1,53 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+)
+
+// 未匹配任何路由（静态文件、404）的请求统一归到该标签，避免按原始路径产生大量序列
+const unmatchedRoute = "<unmatched>"
+
+// Metrics 按路由模板统计请求数与耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveHTTPRequest(c.Request.Method, routeOf(c), statusCode(c), time.Since(start))
+	}
+}
+
+func routeOf(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
+
+// statusCode 优先使用业务响应码，接口统一以 HTTP 200 返回时仍能区分失败请求
+func statusCode(c *gin.Context) int {
+	if code := httputils.GetResponseCode(c); code != 0 {
+		return code
+	}
+	return c.Writer.Status()
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/caoyingjunz/pixiu/cmd/app/options"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/util"
 )
 
@@ -46,11 +47,21 @@ func allowCustomRequest(c *gin.Context) bool {
 }
 
 func InstallMiddlewares(o *options.Options) {
+	o.HttpEngine.Use(requestid.New(requestid.WithGenerator(func() string {
+		return util.GenerateRequestID()
+	})))
+	if tracing.Enabled() {
+		// controller 普遍以 *gin.Context 作为 context 传递，需回落到 Request.Context() 才能读到 span
+		o.HttpEngine.ContextWithFallback = true
+		o.HttpEngine.Use(Tracing())
+	}
+	if o.ComponentConfig.Metrics.IsEnabled() {
+		alwaysAllowPath.Insert(o.ComponentConfig.Metrics.Path)
+		o.HttpEngine.Use(Metrics())
+	}
+
 	// 依次进行跨域，日志，单用户限速，总量限速，验证，鉴权和审计
 	o.HttpEngine.Use(
-		requestid.New(requestid.WithGenerator(func() string {
-			return util.GenerateRequestID()
-		})),
 		Cors(),
 		Logger(&o.ComponentConfig.Log),
 		UserRateLimiter(),
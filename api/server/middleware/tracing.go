@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
+)
+
+// Tracing 为每个请求生成 server span，并继承请求头中的 traceparent
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := routeOf(c)
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("pixiu.request_id", requestid.Get(c)),
+			),
+		)
+		// 需开启 engine.ContextWithFallback，controller 以 *gin.Context 作为 context 时才能取到该 span
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		code := statusCode(c)
+		span.SetAttributes(attribute.Int("http.response.status_code", code))
+		// 与 OpenTelemetry HTTP 语义约定一致，仅 5xx 视为 server span 失败
+		var err error
+		if code >= 500 {
+			if err = httputils.GetRawError(c); err == nil {
+				err = fmt.Errorf("response code %d", code)
+			}
+		}
+		tracing.End(span, err)
+	}
+}
@@ -30,6 +30,9 @@ const (
 	accountBaseURL      = assistantBaseURL + "/accounts"
 	conversationBaseURL = assistantBaseURL + "/conversations"
 	messageBaseURL      = assistantBaseURL + "/messages"
+	executionBaseURL    = assistantBaseURL + "/executions"
+	quotaBaseURL        = assistantBaseURL + "/quotas"
+	priceBaseURL        = assistantBaseURL + "/prices"
 )
 
 type router struct {
@@ -75,6 +78,7 @@ func (r *router) initRoutes(ginEngine *gin.Engine) {
 		BaseURL: conversationBaseURL,
 		Entries: []apiregistry.RouteEntry{
 			{Method: "DELETE", RelativePath: "/:conversationId", Handler: r.deleteConversation, Description: "Delete conversation"},
+			{Method: "PUT", RelativePath: "/:conversationId/pin", Handler: r.pinConversationItem, Description: "Pin or unpin conversation message"},
 			{Method: "GET", RelativePath: "", Handler: r.listConversations, Description: "List conversations"},
 			{Method: "GET", RelativePath: "/:conversationId", Handler: r.getConversation, Description: "Get conversation"},
 		},
@@ -92,11 +96,48 @@ func (r *router) initRoutes(ginEngine *gin.Engine) {
 	}
 	messageGroup.Register(ginEngine.Group(messageBaseURL), r.c.APIResource())
 
+	quotaGroup := &apiregistry.Group{
+		Name:    "智能助手",
+		BaseURL: quotaBaseURL,
+		Entries: []apiregistry.RouteEntry{
+			{Method: "POST", RelativePath: "", Handler: r.createQuota, Description: "Create assistant token quota"},
+			{Method: "PUT", RelativePath: "/:quotaId", Handler: r.updateQuota, Description: "Update assistant token quota"},
+			{Method: "DELETE", RelativePath: "/:quotaId", Handler: r.deleteQuota, Description: "Delete assistant token quota"},
+			{Method: "GET", RelativePath: "", Handler: r.listQuotas, Description: "List assistant token quotas"},
+			{Method: "GET", RelativePath: "/:quotaId", Handler: r.getQuota, Description: "Get assistant token quota"},
+		},
+	}
+	quotaGroup.Register(ginEngine.Group(quotaBaseURL), r.c.APIResource())
+
+	priceGroup := &apiregistry.Group{
+		Name:    "智能助手",
+		BaseURL: priceBaseURL,
+		Entries: []apiregistry.RouteEntry{
+			{Method: "POST", RelativePath: "", Handler: r.createPrice, Description: "Create assistant model price"},
+			{Method: "PUT", RelativePath: "/:priceId", Handler: r.updatePrice, Description: "Update assistant model price"},
+			{Method: "DELETE", RelativePath: "/:priceId", Handler: r.deletePrice, Description: "Delete assistant model price"},
+			{Method: "GET", RelativePath: "", Handler: r.listPrices, Description: "List assistant model prices"},
+			{Method: "GET", RelativePath: "/:priceId", Handler: r.getPrice, Description: "Get assistant model price"},
+		},
+	}
+	priceGroup.Register(ginEngine.Group(priceBaseURL), r.c.APIResource())
+
+	executionGroup := &apiregistry.Group{
+		Name:    "智能助手",
+		BaseURL: executionBaseURL,
+		Entries: []apiregistry.RouteEntry{
+			{Method: "POST", RelativePath: "/:executionId/approve", Handler: r.approveExecution, Description: "Approve assistant mutating operation"},
+			{Method: "POST", RelativePath: "/:executionId/reject", Handler: r.rejectExecution, Description: "Reject assistant mutating operation"},
+		},
+	}
+	executionGroup.Register(ginEngine.Group(executionBaseURL), r.c.APIResource())
+
 	respondGroup := &apiregistry.Group{
 		Name:    "智能助手",
 		BaseURL: assistantBaseURL,
 		Entries: []apiregistry.RouteEntry{
 			{Method: "POST", RelativePath: "/respond/stream", Handler: r.stream, Description: "Stream assistant response"},
+			{Method: "GET", RelativePath: "/usage", Handler: r.usageReport, Description: "Report assistant token usage and cost"},
 		},
 	}
 	respondGroup.Register(ginEngine.Group(assistantBaseURL), r.c.APIResource())
@@ -80,3 +80,23 @@ func (r *router) listConversations(c *gin.Context) {
 	}
 	httputils.SetSuccess(c, resp)
 }
+
+func (r *router) pinConversationItem(c *gin.Context) {
+	resp := httputils.NewResponse()
+
+	var (
+		idMeta conversationMeta
+		req    types.PinConversationItemRequest
+		err    error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &idMeta, nil); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	req.Id = idMeta.ConversationId
+	if err = r.c.Assistant().Conversation().Pin(c, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	httputils.SetSuccess(c, resp)
+}
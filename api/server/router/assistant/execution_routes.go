@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+type executionMeta struct {
+	ExecutionId int64 `uri:"executionId" binding:"required"`
+}
+
+func (r *router) approveExecution(c *gin.Context) {
+	resp := httputils.NewResponse()
+
+	var (
+		idMeta executionMeta
+		req    types.AIExecutionDecisionRequest
+		err    error
+	)
+	if err = bindExecutionDecision(c, &idMeta, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	if err = r.c.Assistant().ApproveExecution(c, idMeta.ExecutionId, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	httputils.SetSuccess(c, resp)
+}
+
+func (r *router) rejectExecution(c *gin.Context) {
+	resp := httputils.NewResponse()
+
+	var (
+		idMeta executionMeta
+		req    types.AIExecutionDecisionRequest
+		err    error
+	)
+	if err = bindExecutionDecision(c, &idMeta, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	if err = r.c.Assistant().RejectExecution(c, idMeta.ExecutionId, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	httputils.SetSuccess(c, resp)
+}
+
+// bindExecutionDecision 审批理由可选，请求体为空时只绑定路径参数
+func bindExecutionDecision(c *gin.Context, idMeta *executionMeta, req *types.AIExecutionDecisionRequest) error {
+	if err := httputils.ShouldBindAny(c, nil, idMeta, nil); err != nil {
+		return err
+	}
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	return c.ShouldBindJSON(req)
+}
@@ -80,3 +80,21 @@ func (r *router) listMessages(c *gin.Context) {
 	}
 	httputils.SetSuccess(c, resp)
 }
+
+func (r *router) usageReport(c *gin.Context) {
+	resp := httputils.NewResponse()
+
+	var (
+		req types.AIUsageReportRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, nil, nil, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	if resp.Result, err = r.c.Assistant().Message().Usage(c, &req); err != nil {
+		httputils.SetFailed(c, resp, err)
+		return
+	}
+	httputils.SetSuccess(c, resp)
+}
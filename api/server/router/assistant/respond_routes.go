@@ -18,9 +18,12 @@ package assistant
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
 	"github.com/caoyingjunz/pixiu/api/server/httputils"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
@@ -58,6 +61,12 @@ func (r *router) stream(c *gin.Context) {
 	}
 
 	if _, err = r.c.Assistant().Stream(c, &req, emit); err != nil {
-		_ = emit(&types.AIStreamEvent{Type: "error", Stage: "failed", Message: err.Error()})
+		stage := "failed"
+		// 配额耗尽单独标识，前端据此提示而不是当作模型故障
+		var apiErr apierrors.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests {
+			stage = "quota_exceeded"
+		}
+		_ = emit(&types.AIStreamEvent{Type: "error", Stage: stage, Message: err.Error()})
 	}
 }
@@ -42,6 +42,13 @@ func (a *auditRouter) initRoutes(ginEngine *gin.Engine) {
 		Entries: []apiregistry.RouteEntry{
 			//{Method: "GET", RelativePath: "/:auditId", Handler: a.getAudit, Description: "获取审计日志详情"},
 			{Method: "GET", RelativePath: "", Handler: a.listAudits, Description: "查看列表"},
+			{Method: "GET", RelativePath: "/verify", Handler: a.verifyChain, Description: "校验审计哈希链"},
+			{Method: "GET", RelativePath: "/sinks", Handler: a.listSinks, Description: "查看审计推送 sink 投递统计"},
+			{Method: "GET", RelativePath: "/:auditId/diffs", Handler: a.listObjectDiffs, Description: "查看 K8s 对象变更差异"},
+
+			{Method: "GET", RelativePath: "/sessions", Handler: a.listSessions, Description: "查看终端会话录像列表"},
+			{Method: "GET", RelativePath: "/sessions/:sessionId", Handler: a.getSession, Description: "查看终端会话录像详情"},
+			{Method: "GET", RelativePath: "/sessions/:sessionId/replay", Handler: a.replaySession, Description: "回放终端会话录像"},
 		},
 	}
 	group.Register(ginEngine.Group("/pixiu/audits"), a.c.APIResource())
@@ -64,3 +64,105 @@ func (a *auditRouter) listAudits(c *gin.Context) {
 
 	httputils.SetSuccess(c, r)
 }
+
+func (a *auditRouter) listObjectDiffs(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt AuditMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = a.c.Audit().ListObjectDiffs(c, opt.AuditId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (a *auditRouter) verifyChain(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var err error
+	if r.Result, err = a.c.Audit().Verify(c); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (a *auditRouter) listSinks(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var err error
+	if r.Result, err = a.c.Audit().ListSinks(c); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+type sessionMeta struct {
+	SessionId int64 `uri:"sessionId" binding:"required"`
+}
+
+func (a *auditRouter) listSessions(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		listOption types.ListOptions
+		err        error
+	)
+	if err = httputils.BindListOptionsWithUser(c, &listOption); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = a.c.Audit().ListSessions(c, listOption); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (a *auditRouter) getSession(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt sessionMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = a.c.Audit().GetSession(c, opt.SessionId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (a *auditRouter) replaySession(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt sessionMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = a.c.Audit().ReplaySession(c, opt.SessionId, c.Writer); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+}
@@ -55,12 +55,19 @@ func (cr *clusterRouter) initRoutes(ginEngine *gin.Engine) {
 			{Method: "GET", RelativePath: "", Handler: cr.listClusters, Description: "查看列表"},
 
 			{Method: "POST", RelativePath: "/ping", Handler: cr.pingCluster, Description: "连通测试"},
+			{Method: "POST", RelativePath: "/import/contexts", Handler: cr.listImportContexts, Description: "解析KubeConfig上下文"},
+			{Method: "POST", RelativePath: "/import", Handler: cr.importClusters, Description: "批量导入"},
 			{Method: "POST", RelativePath: "/protect/:clusterId", Handler: cr.protectCluster, Description: "删除保护"},
 			{Method: "GET", RelativePath: "/:clusterId/kubeconfig", Handler: cr.getClusterKubeconfig, Description: "查看KubeConfig"},
+			{Method: "POST", RelativePath: "/:clusterId/credential/rotate", Handler: cr.rotateClusterCredential, Description: "轮换凭据"},
 
 			{Method: "POST", RelativePath: "/:clusterId/proxy-kubeconfig", Handler: cr.createProxyKubeconfig, Description: "生成代理KubeConfig"},
 			{Method: "GET", RelativePath: "/:clusterId/proxy-kubeconfig", Handler: cr.getProxyKubeconfig, Description: "获取代理KubeConfig"},
 			{Method: "DELETE", RelativePath: "/:clusterId/access-tokens/:jti", Handler: cr.revokeAccessToken, Description: "删除访问令牌"},
+
+			{Method: "GET", RelativePath: "/:clusterId/health/events", Handler: cr.listClusterHealthEvents, Description: "健康事件"},
+			{Method: "GET", RelativePath: "/:clusterId/health/availability", Handler: cr.getClusterAvailability, Description: "可用率"},
+			{Method: "GET", RelativePath: "/:clusterId/health/node-flaps", Handler: cr.getClusterNodeFlaps, Description: "节点抖动"},
 		},
 	}
 	group.Register(ginEngine.Group("/pixiu/clusters"), cr.c.APIResource())
@@ -43,6 +43,42 @@ func (cr *clusterRouter) createCluster(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+func (cr *clusterRouter) listImportContexts(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		req types.ParseKubeconfigRequest
+		err error
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().ListImportContexts(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) importClusters(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		req types.ImportClustersRequest
+		err error
+	)
+	if err = httputils.BindCreateRequest(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().Import(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) updateCluster(c *gin.Context) {
 	r := httputils.NewResponse()
 	var (
@@ -160,6 +196,32 @@ func (cr *clusterRouter) protectCluster(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+func (cr *clusterRouter) rotateClusterCredential(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		idMeta IdMeta
+		req    types.RotateCredentialRequest
+		err    error
+	)
+	if err = c.ShouldBindUri(&idMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	// 请求体可选，未指定有效期时使用 cluster_credential.token_expire_hours
+	if c.Request.ContentLength != 0 {
+		if err = c.ShouldBindJSON(&req); err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+	}
+	if r.Result, err = cr.c.Cluster().RotateCredential(c, idMeta.ClusterId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) getClusterKubeconfig(c *gin.Context) {
 	r := httputils.NewResponse()
 
@@ -244,6 +306,69 @@ func (cr *clusterRouter) revokeAccessToken(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 }
 
+func (cr *clusterRouter) listClusterHealthEvents(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta struct {
+			ClusterId int64 `uri:"clusterId" binding:"required"`
+		}
+		query types.ClusterHealthQuery
+		err   error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().Health().ListEvents(c, meta.ClusterId, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) getClusterAvailability(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta struct {
+			ClusterId int64 `uri:"clusterId" binding:"required"`
+		}
+		query types.ClusterHealthQuery
+		err   error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().Health().Availability(c, meta.ClusterId, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (cr *clusterRouter) getClusterNodeFlaps(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		meta struct {
+			ClusterId int64 `uri:"clusterId" binding:"required"`
+		}
+		query types.ClusterHealthQuery
+		err   error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &meta, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = cr.c.Cluster().Health().NodeFlaps(c, meta.ClusterId, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
 func (cr *clusterRouter) aggregateEvents(c *gin.Context) {
 	r := httputils.NewResponse()
 	var (
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+func (hr *helmRouter) createApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err error
+		req types.CreateHelmApplication
+	)
+	if err = c.ShouldBindJSON(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = hr.c.Helm().Application().Create(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) updateApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err     error
+		appMeta types.HelmApplicationId
+		req     types.UpdateHelmApplication
+	)
+	if err = httputils.ShouldBindAny(c, &req, &appMeta, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = hr.c.Helm().Application().Update(c, appMeta.Id, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) deleteApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err     error
+		appMeta types.HelmApplicationId
+	)
+	if err = c.ShouldBindUri(&appMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = hr.c.Helm().Application().Delete(c, appMeta.Id); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) getApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err     error
+		appMeta types.HelmApplicationId
+	)
+	if err = c.ShouldBindUri(&appMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = hr.c.Helm().Application().Get(c, appMeta.Id); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) listApplications(c *gin.Context) {
+	r := httputils.NewResponse()
+	var err error
+
+	if r.Result, err = hr.c.Helm().Application().List(c); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// deployApplication 异步按批次发布，进度通过应用详情中的 targets 查看
+func (hr *helmRouter) deployApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err     error
+		appMeta types.HelmApplicationId
+	)
+	if err = c.ShouldBindUri(&appMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = hr.c.Helm().Application().Deploy(c, appMeta.Id); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) rollbackApplication(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err     error
+		appMeta types.HelmApplicationId
+	)
+	if err = c.ShouldBindUri(&appMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = hr.c.Helm().Application().Rollback(c, appMeta.Id); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
@@ -53,6 +53,9 @@ func (hr *helmRouter) initRoutes(httpEngine *gin.Engine) {
 			{Method: "GET", RelativePath: "/repositories/:id/charts", Handler: hr.getRepoCharts, Description: "获取仓库Chart列表", Persist: &persist},
 			{Method: "GET", RelativePath: "/repositories/charts", Handler: hr.getRepoChartsByURL, Description: "按URL获取Chart", Persist: &persist},
 			{Method: "GET", RelativePath: "/repositories/values", Handler: hr.getChartValues, Description: "获取Chart Values", Persist: &persist},
+			{Method: "POST", RelativePath: "/repositories/:id/sync", Handler: hr.syncRepository, Description: "同步仓库Chart目录", Persist: &persist},
+			{Method: "GET", RelativePath: "/repositories/:id/charts/:chart", Handler: hr.getChart, Description: "获取Chart详情", Persist: &persist},
+			{Method: "GET", RelativePath: "/charts", Handler: hr.searchCharts, Description: "搜索Chart", Persist: &persist},
 			{Method: "POST", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases", Handler: hr.InstallRelease, Description: "安装Release", Persist: &persist},
 			{Method: "PUT", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases", Handler: hr.UpgradeRelease, Description: "升级Release", Persist: &persist},
 			{Method: "DELETE", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases/:name", Handler: hr.UninstallRelease, Description: "卸载Release", Persist: &persist},
@@ -60,6 +63,15 @@ func (hr *helmRouter) initRoutes(httpEngine *gin.Engine) {
 			{Method: "GET", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases", Handler: hr.ListReleases, Description: "获取Release列表", Persist: &persist},
 			{Method: "GET", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases/:name/history", Handler: hr.GetReleaseHistory, Description: "获取Release历史", Persist: &persist},
 			{Method: "POST", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases/:name/rollback", Handler: hr.RollbackRelease, Description: "回滚Release", Persist: &persist},
+			{Method: "GET", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases/:name/diff", Handler: hr.DiffRelease, Description: "对比Release版本", Persist: &persist},
+			{Method: "GET", RelativePath: "/clusters/:cluster/namespaces/:namespace/releases/:name/drift", Handler: hr.GetReleaseDrift, Description: "检测Release漂移", Persist: &persist},
+			{Method: "POST", RelativePath: "/applications", Handler: hr.createApplication, Description: "创建多集群应用", Persist: &persist},
+			{Method: "PUT", RelativePath: "/applications/:id", Handler: hr.updateApplication, Description: "更新多集群应用", Persist: &persist},
+			{Method: "DELETE", RelativePath: "/applications/:id", Handler: hr.deleteApplication, Description: "删除多集群应用", Persist: &persist},
+			{Method: "GET", RelativePath: "/applications/:id", Handler: hr.getApplication, Description: "获取多集群应用详情", Persist: &persist},
+			{Method: "GET", RelativePath: "/applications", Handler: hr.listApplications, Description: "获取多集群应用列表", Persist: &persist},
+			{Method: "POST", RelativePath: "/applications/:id/deploy", Handler: hr.deployApplication, Description: "发布多集群应用", Persist: &persist},
+			{Method: "POST", RelativePath: "/applications/:id/rollback", Handler: hr.rollbackApplication, Description: "回滚多集群应用", Persist: &persist},
 		},
 	}
 	group.Register(httpEngine.Group(helmBaseURL), hr.c.APIResource())
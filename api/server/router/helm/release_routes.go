@@ -158,3 +158,44 @@ func (hr *helmRouter) RollbackRelease(c *gin.Context) {
 	}
 	httputils.SetSuccess(c, r)
 }
+
+// DiffRelease 对比 release 两个版本的 values 与 manifest
+func (hr *helmRouter) DiffRelease(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err      error
+		helmMeta types.PixiuObjectMeta
+		opts     types.ReleaseDiffOptions
+	)
+	if err = httputils.ShouldBindAny(c, nil, &helmMeta, &opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	releaseAPI := hr.c.Helm().Release(helmMeta.Cluster, helmMeta.Namespace)
+	if r.Result, err = releaseAPI.Diff(c, helmMeta.Name, opts); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+// GetReleaseDrift 检测 release 资源是否被手动修改或删除
+func (hr *helmRouter) GetReleaseDrift(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err      error
+		helmMeta types.PixiuObjectMeta
+	)
+	if err = c.ShouldBindUri(&helmMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	releaseAPI := hr.c.Helm().Release(helmMeta.Cluster, helmMeta.Namespace)
+	if r.Result, err = releaseAPI.Drift(c, helmMeta.Name); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
@@ -163,7 +163,7 @@ func (hr *helmRouter) getChartValues(c *gin.Context) {
 		httputils.SetFailed(c, r, err)
 		return
 	}
-	if r.Result, err = hr.c.Helm().Repository().GetChartValues(c, repoMeta.Chart, repoMeta.Version); err != nil {
+	if r.Result, err = hr.c.Helm().Repository().GetChartValues(c, repoMeta.RepositoryId, repoMeta.Chart, repoMeta.Version); err != nil {
 		httputils.SetFailed(c, r, err)
 		return
 	}
@@ -171,3 +171,58 @@ func (hr *helmRouter) getChartValues(c *gin.Context) {
 	httputils.SetSuccess(c, r)
 
 }
+
+func (hr *helmRouter) syncRepository(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err      error
+		repoMeta types.RepoId
+	)
+	if err = c.ShouldBindUri(&repoMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = hr.c.Helm().Repository().Sync(c, repoMeta.Id); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (hr *helmRouter) getChart(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err       error
+		chartMeta types.ChartName
+	)
+	if err = c.ShouldBindUri(&chartMeta); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = hr.c.Helm().Repository().GetChart(c, chartMeta.Id, chartMeta.Chart); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+// searchCharts 在所有仓库的 chart 目录缓存中搜索
+func (hr *helmRouter) searchCharts(c *gin.Context) {
+	r := httputils.NewResponse()
+	var (
+		err error
+		req types.SearchCharts
+	)
+	if err = c.ShouldBindQuery(&req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = hr.c.Helm().Repository().SearchCharts(c, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
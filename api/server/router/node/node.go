@@ -51,6 +51,11 @@ func (n *nodeRouter) initRoutes(ginEngine *gin.Engine) {
 			{Method: "GET", RelativePath: "", Handler: n.listNodes, Description: "查看列表"},
 
 			{Method: "POST", RelativePath: "/connectivity", Handler: n.checkConnectivity, Description: "连通性检测"},
+
+			{Method: "GET", RelativePath: "/:nodeId/hostkey", Handler: n.getHostKey, Description: "查看主机公钥"},
+			{Method: "POST", RelativePath: "/:nodeId/hostkey/pin", Handler: n.pinHostKey, Description: "固定主机公钥"},
+			{Method: "POST", RelativePath: "/:nodeId/hostkey/accept", Handler: n.acceptHostKey, Description: "接受新主机公钥"},
+			{Method: "DELETE", RelativePath: "/:nodeId/hostkey", Handler: n.resetHostKey, Description: "重置主机公钥"},
 		},
 	}
 	group.Register(ginEngine.Group(nodeBaseURL), n.c.APIResource())
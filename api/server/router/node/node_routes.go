@@ -135,3 +135,76 @@ func (n *nodeRouter) checkConnectivity(c *gin.Context) {
 	}
 	httputils.SetSuccess(c, r)
 }
+
+func (n *nodeRouter) getHostKey(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt nodeMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = n.c.Node().GetHostKey(c, opt.NodeId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (n *nodeRouter) pinHostKey(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt nodeMeta
+		req types.PinNodeHostKeyRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &opt, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = n.c.Node().PinHostKey(c, opt.NodeId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (n *nodeRouter) acceptHostKey(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt nodeMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = n.c.Node().AcceptHostKey(c, opt.NodeId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
+
+func (n *nodeRouter) resetHostKey(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt nodeMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = n.c.Node().ResetHostKey(c, opt.NodeId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
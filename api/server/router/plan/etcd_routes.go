@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+type etcdSnapshotMeta struct {
+	PlanId     int64 `uri:"planId" binding:"required"`
+	SnapshotId int64 `uri:"snapshotId" binding:"required"`
+}
+
+func (t *planRouter) getEtcdBackupPolicy(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt planMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = t.c.Plan().GetEtcdBackupPolicy(c, opt.PlanId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (t *planRouter) updateEtcdBackupPolicy(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt planMeta
+		req types.UpdateEtcdBackupPolicyRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &opt, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = t.c.Plan().UpdateEtcdBackupPolicy(c, opt.PlanId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (t *planRouter) createEtcdSnapshot(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt planMeta
+		req types.CreateEtcdSnapshotRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &opt, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = t.c.Plan().CreateEtcdSnapshot(c, opt.PlanId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (t *planRouter) listEtcdSnapshots(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt        planMeta
+		listOption types.ListOptions
+		err        error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &opt, &listOption); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = t.c.Plan().ListEtcdSnapshots(c, opt.PlanId, listOption); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (t *planRouter) deleteEtcdSnapshot(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt etcdSnapshotMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = t.c.Plan().DeleteEtcdSnapshot(c, opt.PlanId, opt.SnapshotId); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
+
+func (t *planRouter) downloadEtcdSnapshot(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt etcdSnapshotMeta
+		err error
+	)
+	if err = c.ShouldBindUri(&opt); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if err = t.c.Plan().DownloadEtcdSnapshot(c, opt.PlanId, opt.SnapshotId, c.Writer); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+}
+
+func (t *planRouter) restoreEtcd(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt planMeta
+		req types.RestoreEtcdRequest
+		err error
+	)
+	if err = httputils.ShouldBindAny(c, &req, &opt, nil); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if r.Result, err = t.c.Plan().RestoreEtcd(c, opt.PlanId, &req); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	httputils.SetSuccess(c, r)
+}
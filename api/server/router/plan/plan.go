@@ -47,12 +47,30 @@ func (t *planRouter) initRoutes(ginEngine *gin.Engine) {
 			{Method: "GET", RelativePath: "/:planId", Handler: t.getPlan, Description: "获取部署详情"},
 			{Method: "GET", RelativePath: "", Handler: t.listPlans, Description: "部署列表"},
 
+			// 声明式导出与导入
+			{Method: "GET", RelativePath: "/:planId/export", Handler: t.exportPlan, Description: "导出部署"},
+			{Method: "POST", RelativePath: "/apply", Handler: t.applyPlan, Description: "导入部署", Persist: &persist},
+
 			// 对任务的操作接口
 			{Method: "POST", RelativePath: "/:planId/start", Handler: t.startPlan, Description: "启动"},
 			{Method: "POST", RelativePath: "/:planId/destroy", Handler: t.destroyPlan, Description: "销毁"},
 
 			{Method: "GET", RelativePath: "/:planId/tasks", Handler: t.listTasks, Description: "查询任务"},
 			{Method: "GET", RelativePath: "/:planId/tasks/:taskId/logs", Handler: t.watchTaskLog, Description: "部署日志"},
+
+			// etcd 备份与恢复
+			{Method: "GET", RelativePath: "/:planId/etcd/policy", Handler: t.getEtcdBackupPolicy, Description: "获取etcd备份策略"},
+			{Method: "PUT", RelativePath: "/:planId/etcd/policy", Handler: t.updateEtcdBackupPolicy, Description: "更新etcd备份策略"},
+			{Method: "POST", RelativePath: "/:planId/etcd/snapshots", Handler: t.createEtcdSnapshot, Description: "创建etcd快照"},
+			{Method: "GET", RelativePath: "/:planId/etcd/snapshots", Handler: t.listEtcdSnapshots, Description: "etcd快照列表"},
+			{Method: "DELETE", RelativePath: "/:planId/etcd/snapshots/:snapshotId", Handler: t.deleteEtcdSnapshot, Description: "删除etcd快照"},
+			{Method: "GET", RelativePath: "/:planId/etcd/snapshots/:snapshotId/download", Handler: t.downloadEtcdSnapshot, Description: "下载etcd快照"},
+			{Method: "POST", RelativePath: "/:planId/etcd/restore", Handler: t.restoreEtcd, Description: "恢复etcd"},
+
+			// 证书巡检与续期
+			{Method: "GET", RelativePath: "/:planId/certificates", Handler: t.getCertificates, Description: "获取证书有效期"},
+			{Method: "POST", RelativePath: "/:planId/certificates/refresh", Handler: t.refreshCertificates, Description: "巡检证书"},
+			{Method: "POST", RelativePath: "/:planId/certificates/renew", Handler: t.renewCertificates, Description: "续期证书"},
 		},
 	}
 	group.Register(ginEngine.Group("/pixiu/plans"), t.c.APIResource())
@@ -17,6 +17,10 @@ limitations under the License.
 package plan
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/caoyingjunz/pixiu/api/server/httputils"
@@ -182,3 +186,66 @@ func (t *planRouter) destroyPlan(c *gin.Context) {
 
 	httputils.SetSuccess(c, r)
 }
+
+func (t *planRouter) exportPlan(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		opt   planMeta
+		query types.ExportPlanOptions
+		err   error
+	)
+	if err = httputils.ShouldBindAny(c, nil, &opt, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	data, err := t.c.Plan().Export(c, opt.PlanId, query.Secrets)
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="plan-%d.yaml"`, opt.PlanId))
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", data)
+}
+
+// 导入部署文档，支持 multipart 文件（file）或直接以请求体提交 YAML
+func (t *planRouter) applyPlan(c *gin.Context) {
+	r := httputils.NewResponse()
+
+	var (
+		query types.ApplyPlanOptions
+		err   error
+	)
+	if err = httputils.ShouldBindAny(c, nil, nil, &query); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+
+	// 部署文档不会很大，限制最大 1M
+	const maxDocumentSize = 1 << 20
+	var reader io.Reader = c.Request.Body
+	if fileHeader, fileErr := c.FormFile("file"); fileErr == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			httputils.SetFailed(c, r, err)
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxDocumentSize+1))
+	if err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	if len(data) > maxDocumentSize {
+		httputils.SetFailed(c, r, fmt.Errorf("document size exceeds limit %d", maxDocumentSize))
+		return
+	}
+
+	if r.Result, err = t.c.Plan().Apply(c, data, query.DryRun); err != nil {
+		httputils.SetFailed(c, r, err)
+		return
+	}
+	httputils.SetSuccess(c, r)
+}
@@ -19,12 +19,15 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/util/proxy"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
 )
 
 // forwardToCluster 构建传输层并转发请求到目标集群。
@@ -50,9 +53,37 @@ func (p *proxyRouter) forwardToCluster(c *gin.Context, clusterName string, targe
 	c.Request.Header.Del("Authorization")
 	c.Request.Header.Del("Cookie")
 
+	// 更新类请求在转发前后各读取一次对象，差异由审计中间件关联到审计记录
+	var before map[string]interface{}
+	if shouldCaptureDiff(c.Request, target) {
+		if before, err = fetchObject(transport, *target); err != nil {
+			klog.V(2).Infof("skip audit diff for cluster=%s path=%s: %v", clusterName, target.Path, err)
+		}
+	}
+
 	klog.V(2).Infof("proxying cluster=%s path=%s", clusterName, c.Request.URL.Path)
 	httpProxy := proxy.NewUpgradeAwareHandler(target, transport, false, false, nil)
 	httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
 	httpProxy.ServeHTTP(c.Writer, c.Request)
+
+	if before != nil && c.Writer.Status() < http.StatusMultipleChoices {
+		p.recordObjectDiff(c, clusterName, transport, *target, before)
+	}
 	return nil
 }
+
+func (p *proxyRouter) recordObjectDiff(c *gin.Context, clusterName string, transport http.RoundTripper, target url.URL, before map[string]interface{}) {
+	after, err := fetchObject(transport, target)
+	if err != nil {
+		klog.V(2).Infof("skip audit diff for cluster=%s path=%s: %v", clusterName, target.Path, err)
+		return
+	}
+	objectDiff, err := buildObjectDiff(clusterName, before, after)
+	if err != nil {
+		klog.Errorf("failed to build audit diff for cluster=%s path=%s: %v", clusterName, target.Path, err)
+		return
+	}
+	if objectDiff != nil {
+		httputils.SetAuditObjectDiffToContext(c, objectDiff)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/diff"
+)
+
+const (
+	// 对象快照的读取上限，超出时不记录差异
+	maxSnapshotBytes = 1 << 20
+	snapshotTimeout  = 5 * time.Second
+
+	redactedValue        = "<redacted>"
+	redactedChangedValue = "<redacted, changed>"
+
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// shouldCaptureDiff 判断代理请求是否需要记录对象前后差异：
+// 仅处理 PUT/PATCH 的对象（及其子资源）更新，跳过 dry-run、连接升级和 proxy 子资源。
+func shouldCaptureDiff(req *http.Request, target *url.URL) bool {
+	if req.Method != http.MethodPut && req.Method != http.MethodPatch {
+		return false
+	}
+	if req.Header.Get("Upgrade") != "" || target.Query().Has("dryRun") {
+		return false
+	}
+	for _, seg := range strings.Split(target.Path, "/") {
+		if seg == "proxy" {
+			return false
+		}
+	}
+	return strings.HasPrefix(target.Path, "/api/") || strings.HasPrefix(target.Path, "/apis/")
+}
+
+// fetchObject 以 JSON 形式读取目标对象的当前状态。
+func fetchObject(transport http.RoundTripper, target url.URL) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	target.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSnapshotBytes {
+		return nil, fmt.Errorf("object exceeds %d bytes", maxSnapshotBytes)
+	}
+
+	var object map[string]interface{}
+	if err = json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// buildObjectDiff 生成对象前后快照及差异，Secret 的数据在此脱敏；对象无变化时返回 nil。
+func buildObjectDiff(cluster string, before, after map[string]interface{}) (*model.AuditObjectDiff, error) {
+	sanitizeObject(before)
+	sanitizeObject(after)
+	if kindOf(after) == "Secret" {
+		redactSecret(before, after)
+	}
+
+	beforeYAML, err := yaml.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+	afterYAML, err := yaml.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	if string(beforeYAML) == string(afterYAML) {
+		return nil, nil
+	}
+
+	apiVersion, _ := after["apiVersion"].(string)
+	metadata, _ := after["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	return &model.AuditObjectDiff{
+		Cluster:    cluster,
+		APIVersion: apiVersion,
+		Kind:       kindOf(after),
+		Namespace:  namespace,
+		Name:       name,
+		Before:     string(beforeYAML),
+		After:      string(afterYAML),
+		Diff:       diff.Unified("before", "after", string(beforeYAML), string(afterYAML)),
+	}, nil
+}
+
+// sanitizeObject 去除每次写入都会变化或与对象本身重复的字段，避免干扰差异。
+func sanitizeObject(object map[string]interface{}) {
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(metadata, "managedFields")
+	delete(metadata, "resourceVersion")
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		// last-applied-configuration 包含完整对象（Secret 时即明文数据）
+		delete(annotations, lastAppliedAnnotation)
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
+
+// redactSecret 脱敏 Secret 的 data/stringData，仅保留键名及其值是否发生变化。
+func redactSecret(before, after map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		oldData, _ := before[field].(map[string]interface{})
+		newData, _ := after[field].(map[string]interface{})
+		for key, value := range newData {
+			if old, ok := oldData[key]; ok && old != value {
+				newData[key] = redactedChangedValue
+			} else {
+				newData[key] = redactedValue
+			}
+		}
+		for key := range oldData {
+			oldData[key] = redactedValue
+		}
+	}
+}
+
+func kindOf(object map[string]interface{}) string {
+	kind, _ := object["kind"].(string)
+	return kind
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestShouldCaptureDiff(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodPatch, "/apis/apps/v1/namespaces/default/deployments/nginx", true},
+		{http.MethodPut, "/api/v1/namespaces/default/configmaps/cm", true},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/default/deployments/nginx?dryRun=All", false},
+		{http.MethodPost, "/api/v1/namespaces/default/configmaps", false},
+		{http.MethodPut, "/api/v1/namespaces/default/services/svc/proxy/foo", false},
+		{http.MethodPut, "/version", false},
+	}
+	for _, tc := range cases {
+		target, _ := url.Parse(tc.path)
+		req := &http.Request{Method: tc.method, Header: http.Header{}}
+		if got := shouldCaptureDiff(req, target); got != tc.want {
+			t.Errorf("%s %s: expected %v, got %v", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestBuildObjectDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "nginx",
+			"namespace":       "default",
+			"resourceVersion": "1",
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec": map[string]interface{}{"image": "nginx:1.24"},
+	}
+	after := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "nginx",
+			"namespace":       "default",
+			"resourceVersion": "2",
+		},
+		"spec": map[string]interface{}{"image": "nginx:1.25"},
+	}
+
+	d, err := buildObjectDiff("c1", before, after)
+	if err != nil {
+		t.Fatalf("build diff: %v", err)
+	}
+	if d == nil {
+		t.Fatal("expected diff, got nil")
+	}
+	if d.Kind != "Deployment" || d.Name != "nginx" || d.Namespace != "default" || d.Cluster != "c1" {
+		t.Fatalf("unexpected object identity: %+v", d)
+	}
+	if !strings.Contains(d.Diff, "-  image: nginx:1.24") || !strings.Contains(d.Diff, "+  image: nginx:1.25") {
+		t.Fatalf("image change not in diff:\n%s", d.Diff)
+	}
+	if strings.Contains(d.Diff, "resourceVersion") || strings.Contains(d.Before, "managedFields") {
+		t.Fatalf("volatile metadata should be stripped:\n%s", d.Before)
+	}
+
+	// 仅 resourceVersion 变化视为无变更
+	unchanged := map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "cm", "resourceVersion": "1"}}
+	updated := map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "cm", "resourceVersion": "2"}}
+	if d, err = buildObjectDiff("c1", unchanged, updated); err != nil || d != nil {
+		t.Fatalf("expected no diff, got %+v, %v", d, err)
+	}
+}
+
+func TestBuildObjectDiffRedactsSecret(t *testing.T) {
+	secret := func(password, token string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name": "db",
+				"annotations": map[string]interface{}{
+					lastAppliedAnnotation: `{"data":{"password":"` + password + `"}}`,
+				},
+			},
+			"data": map[string]interface{}{"password": password, "token": token},
+		}
+	}
+
+	d, err := buildObjectDiff("c1", secret("b2xk", "dG9rZW4="), secret("bmV3", "dG9rZW4="))
+	if err != nil || d == nil {
+		t.Fatalf("expected diff, got %+v, %v", d, err)
+	}
+	for _, plain := range []string{"b2xk", "bmV3", "dG9rZW4="} {
+		if strings.Contains(d.Before+d.After+d.Diff, plain) {
+			t.Fatalf("secret value %q leaked:\n%s\n%s", plain, d.Before, d.After)
+		}
+	}
+	if !strings.Contains(d.Diff, "+  password: <redacted, changed>") {
+		t.Fatalf("changed key not marked:\n%s", d.Diff)
+	}
+	if strings.Contains(d.Diff, "+  token") || strings.Contains(d.Diff, "-  token") {
+		t.Fatalf("unchanged key should not appear in diff:\n%s", d.Diff)
+	}
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/caoyingjunz/pixiu/api/server/router/tunnel"
 	"github.com/caoyingjunz/pixiu/api/server/router/user"
 	"github.com/caoyingjunz/pixiu/cmd/app/options"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
 	"github.com/caoyingjunz/pixiu/pkg/static"
 )
 
@@ -84,6 +85,11 @@ func InstallRouters(o *options.Options) {
 
 	// 启动健康检查
 	o.HttpEngine.GET("/healthz", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	// Prometheus 指标，由 metrics.bearer_token 单独鉴权
+	if metricsOpts := o.ComponentConfig.Metrics; metricsOpts.IsEnabled() {
+		o.HttpEngine.GET(metricsOpts.Path, gin.WrapH(metrics.Handler(metricsOpts.BearerToken)))
+	}
 }
 
 func install(o *options.Options, fs ...RegisterFunc) {
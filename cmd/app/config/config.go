@@ -17,7 +17,17 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+
+	"github.com/caoyingjunz/pixiu/pkg/auditchain"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
+	"github.com/caoyingjunz/pixiu/pkg/certs"
+	"github.com/caoyingjunz/pixiu/pkg/chartrepo"
+	"github.com/caoyingjunz/pixiu/pkg/etcdbackup"
 	"github.com/caoyingjunz/pixiu/pkg/jobmanager"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
+	"github.com/caoyingjunz/pixiu/pkg/util/envelope"
 )
 
 type Mode string
@@ -36,11 +46,24 @@ type Config struct {
 	Mysql       MysqlOptions            `yaml:"mysql"`
 	Worker      WorkerOptions           `yaml:"worker"`
 	Audit       jobmanager.AuditOptions `yaml:"audit"`
+	AuditSinks  auditsink.Options       `yaml:"audit_sinks"`
+	AuditChain  auditchain.Options      `yaml:"audit_chain"`
 	Log         LogOptions              `yaml:"log"`
 	TLS         TLSOptions              `yaml:"tls"`
 	KubeGateway KubeGatewayOptions      `yaml:"kube_gateway"`
+	Encryption  EncryptionOptions       `yaml:"encryption"`
+	Recording   RecordingOptions        `yaml:"recording"`
+	EtcdBackup  etcdbackup.Options      `yaml:"etcd_backup"`
+	Certificate certs.Options           `yaml:"certificate"`
+	Metrics     metrics.Options         `yaml:"metrics"`
+	Tracing     tracing.Options         `yaml:"tracing"`
+
+	ChartCatalog chartrepo.CatalogOptions `yaml:"chart_catalog"`
 
-	AlertHistory jobmanager.AlertHistoryOptions `yaml:"alert"`
+	ClusterCredential ClusterCredentialOptions `yaml:"cluster_credential"`
+
+	AlertHistory  jobmanager.AlertHistoryOptions  `yaml:"alert"`
+	ClusterHealth jobmanager.ClusterHealthOptions `yaml:"cluster_health"`
 }
 
 // TLSOptions HTTPS 监听配置。kubectl 经 /k8s 网关必须走 HTTPS（client-go 不会在明文 HTTP 上发送 Bearer token）。
@@ -79,6 +102,8 @@ type DefaultOptions struct {
 
 	// 对外访问地址（Agent 反向隧道使用），例如 https://pixiu.example.com
 	PublicURL string `yaml:"public_url"`
+	// cluster-agent 镜像，留空则使用 deploy/cluster-agent/cluster-agent.yaml 中的镜像
+	AgentImage string `yaml:"agent_image"`
 
 	// 超级管理员初始化配置，留空则使用默认值
 	AdminUser     string `yaml:"admin_user"`
@@ -126,6 +151,98 @@ func (o KubeGatewayOptions) Valid() error {
 	return nil
 }
 
+// ClusterCredentialOptions 集群 kubeconfig 凭据过期告警与自动轮换配置。
+type ClusterCredentialOptions struct {
+	// 凭据剩余天数不足 WarnDays 时每天向 NotifyChannels 发送一次告警，NotifyChannels 为空则不告警
+	WarnDays       int     `yaml:"warn_days"`
+	NotifyChannels []int64 `yaml:"notify_channels"`
+
+	// Pixiu 签发的 ServiceAccount token 剩余天数不足 RotateBeforeDays 时自动轮换，默认开启
+	AutoRotate       *bool `yaml:"auto_rotate"`
+	RotateBeforeDays int   `yaml:"rotate_before_days"`
+	// 轮换签发的 token 有效期（小时），默认 8760（1 年）
+	TokenExpireHours int `yaml:"token_expire_hours"`
+
+	// 凭据过期检查与自动轮换任务的 cron 表达式，默认每小时执行
+	Schedule string `yaml:"schedule"`
+}
+
+func (o *ClusterCredentialOptions) AutoRotateEnabled() bool {
+	if o == nil || o.AutoRotate == nil {
+		return true
+	}
+	return *o.AutoRotate
+}
+
+// SetDefaults 补齐未配置的告警与轮换参数。
+func (o *ClusterCredentialOptions) SetDefaults() {
+	if o.WarnDays <= 0 {
+		o.WarnDays = 30
+	}
+	if o.RotateBeforeDays <= 0 {
+		o.RotateBeforeDays = 7
+	}
+	if o.TokenExpireHours <= 0 {
+		o.TokenExpireHours = 8760
+	}
+	if o.Schedule == "" {
+		o.Schedule = jobmanager.DefaultCredentialSyncSchedule
+	}
+}
+
+// EncryptionOptions 数据库敏感字段（kubeconfig、节点认证、数据源/告警渠道配置、API Key、SMTP 密码等）加密配置。
+// 配置 key_file 后开启，启动时自动加密历史明文数据。
+type EncryptionOptions struct {
+	// 密钥提供者，目前支持 local（本地密钥文件），默认 local
+	Provider string `yaml:"provider"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+const EncryptionProviderLocal = "local"
+
+func (o EncryptionOptions) IsEnabled() bool {
+	return len(o.KeyFile) != 0
+}
+
+func (o *EncryptionOptions) SetDefaults() {
+	if o.Provider == "" {
+		o.Provider = EncryptionProviderLocal
+	}
+}
+
+func (o EncryptionOptions) Valid() error {
+	if !o.IsEnabled() {
+		return nil
+	}
+	if o.Provider != EncryptionProviderLocal {
+		return fmt.Errorf("unsupported encryption provider %q", o.Provider)
+	}
+	return nil
+}
+
+// NewEnvelope 根据配置加载密钥并创建加密器
+func (o EncryptionOptions) NewEnvelope() (*envelope.Envelope, error) {
+	provider, err := envelope.LoadKeyFile(o.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.New(provider), nil
+}
+
+// RecordingOptions 交互式终端（Pod exec、节点 SSH、CloudShell）会话录像配置，默认开启。
+type RecordingOptions struct {
+	Enabled *bool `yaml:"enabled"`
+	// 录像存储目录，默认 <work_dir>/recordings
+	Dir string `yaml:"dir"`
+}
+
+func (o RecordingOptions) IsEnabled() bool {
+	if o.Enabled == nil {
+		return true
+	}
+	return *o.Enabled
+}
+
 // MysqlOptions 数据库具体配置
 type MysqlOptions struct {
 	Host     string `yaml:"host"`
@@ -174,6 +291,32 @@ func (c *Config) Valid() (err error) {
 		return
 	}
 	c.TLS.SetDefaults()
+	c.ClusterCredential.SetDefaults()
+	c.Encryption.SetDefaults()
+	if err = c.Encryption.Valid(); err != nil {
+		return
+	}
+	if err = c.EtcdBackup.Valid(); err != nil {
+		return
+	}
+	if err = c.ChartCatalog.Valid(); err != nil {
+		return
+	}
+	if err = c.Certificate.Valid(); err != nil {
+		return
+	}
+	if err = c.AuditSinks.Valid(); err != nil {
+		return
+	}
+	if err = c.Metrics.Valid(); err != nil {
+		return
+	}
+	if err = c.Tracing.Valid(); err != nil {
+		return
+	}
+	if err = c.AuditChain.Valid(); err != nil {
+		return
+	}
 
 	return
 }
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/cmd/app/options"
+	"github.com/caoyingjunz/pixiu/pkg/util/envelope"
+)
+
+// newEncryptionCommand 数据库敏感字段加密的运维命令
+func newEncryptionCommand(opts *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manage encryption of secrets stored in the database",
+	}
+
+	var keyID string
+	genCmd := &cobra.Command{
+		Use:   "generate-key",
+		Short: "Generate a new key line for the encryption key file",
+		Long:  "Generate a new key line for the encryption key file. Put it on the first line of the key file to make it the primary key.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			line, err := envelope.GenerateKeyLine(keyID)
+			if err != nil {
+				return err
+			}
+			fmt.Println(line)
+			return nil
+		},
+	}
+	genCmd.Flags().StringVar(&keyID, "id", "", "The id of the new key, e.g. k2")
+	_ = genCmd.MarkFlagRequired("id")
+
+	rotateCmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt all secrets with the primary key",
+		Long:  "Re-encrypt all secrets which are plaintext or encrypted by a non-primary key. Old keys can be removed from the key file afterwards.",
+		Run: func(cmd *cobra.Command, args []string) {
+			n, err := opts.RotateEncryptionKey(context.TODO())
+			if err != nil {
+				klog.Fatal(err)
+			}
+			fmt.Printf("re-encrypted %d secret(s)\n", n)
+		},
+	}
+	rotateCmd.Flags().StringVar(&opts.ConfigFile, "configfile", opts.ConfigFile, "The location of the pixiu configuration file")
+
+	cmd.AddCommand(genCmd, rotateCmd)
+	return cmd
+}
@@ -120,36 +120,58 @@ var defaultDistributionCatalog = []struct {
 
 var defaultAIProviderCatalog = []pixiuModel.AIProvider{
 	{
-		Name:        "openai",
-		BaseURL:     "https://api.openai.com",
-		Protocol:    "openai_responses",
-		Description: "OpenAI official API",
-		MaxTokens:   4096,
-		Builtin:     true,
+		Name:          "openai",
+		BaseURL:       "https://api.openai.com",
+		Protocol:      "openai_responses",
+		Description:   "OpenAI official API",
+		MaxTokens:     4096,
+		ContextWindow: 128000,
+		Builtin:       true,
 	},
 	{
-		Name:        "deepseek",
-		BaseURL:     "https://api.deepseek.com",
-		Protocol:    "openai_chat",
-		Description: "DeepSeek official API",
-		MaxTokens:   4096,
-		Builtin:     true,
+		Name:          "deepseek",
+		BaseURL:       "https://api.deepseek.com",
+		Protocol:      "openai_chat",
+		Description:   "DeepSeek official API",
+		MaxTokens:     4096,
+		ContextWindow: 64000,
+		Builtin:       true,
 	},
 	{
-		Name:        "siliconflow",
-		BaseURL:     "https://api.siliconflow.cn/v1",
-		Protocol:    "openai_chat",
-		Description: "SiliconFlow official API",
-		MaxTokens:   4096,
-		Builtin:     true,
+		Name:          "siliconflow",
+		BaseURL:       "https://api.siliconflow.cn/v1",
+		Protocol:      "openai_chat",
+		Description:   "SiliconFlow official API",
+		MaxTokens:     4096,
+		ContextWindow: 32768,
+		Builtin:       true,
 	},
 	{
-		Name:        "zhipu",
-		BaseURL:     "https://open.bigmodel.cn/api/paas/v4",
-		Protocol:    "openai_chat",
-		Description: "Zhipu GLM official API",
-		MaxTokens:   4096,
-		Builtin:     true,
+		Name:          "zhipu",
+		BaseURL:       "https://open.bigmodel.cn/api/paas/v4",
+		Protocol:      "openai_chat",
+		Description:   "Zhipu GLM official API",
+		MaxTokens:     4096,
+		ContextWindow: 128000,
+		Builtin:       true,
+	},
+	{
+		Name:          "anthropic",
+		BaseURL:       "https://api.anthropic.com",
+		Protocol:      "anthropic_messages",
+		Description:   "Anthropic official API",
+		MaxTokens:     4096,
+		ContextWindow: 200000,
+		Builtin:       true,
+	},
+	{
+		Name:          "ollama",
+		BaseURL:       "http://localhost:11434",
+		Protocol:      "ollama_chat",
+		Description:   "Ollama local models",
+		MaxTokens:     4096,
+		ContextWindow: 8192,
+		Builtin:       true,
 	},
 }
 
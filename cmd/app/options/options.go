@@ -17,9 +17,11 @@ limitations under the License.
 package options
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -27,12 +29,21 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"k8s.io/klog/v2"
 
 	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	"github.com/caoyingjunz/pixiu/pkg/auditchain"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
 	"github.com/caoyingjunz/pixiu/pkg/controller"
+	"github.com/caoyingjunz/pixiu/pkg/controller/node"
 	pixiudb "github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/jobmanager"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/recording"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/tunnel"
+	"github.com/caoyingjunz/pixiu/pkg/util/envelope"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
 	pixiuConfig "github.com/caoyingjunz/pixiulib/config"
 )
 
@@ -62,8 +73,9 @@ type Options struct {
 	HttpEngine      *gin.Engine
 
 	// 数据库接口
-	db      *gorm.DB
-	Factory pixiudb.ShareDaoFactory
+	db       *gorm.DB
+	envelope *envelope.Envelope
+	Factory  pixiudb.ShareDaoFactory
 	// 貔貅主控制接口
 	Controller controller.PixiuInterface
 
@@ -84,6 +96,68 @@ func NewOptions() (*Options, error) {
 
 // Complete completes all the required options
 func (o *Options) Complete(cmd *cobra.Command) error {
+	if err := o.LoadConfig(); err != nil {
+		return err
+	}
+
+	o.ComponentConfig.Log.Init(isCLIVerbositySet(cmd))
+
+	// 链路追踪需早于数据库与路由初始化，以便注册 gorm 回调和 HTTP 中间件
+	if err := tracing.Init(o.ComponentConfig.Tracing); err != nil {
+		return err
+	}
+
+	// 注册依赖组件
+	if err := o.register(); err != nil {
+		return err
+	}
+
+	o.Controller = controller.New(o.ComponentConfig, o.Factory)
+
+	// 初始化 Agent 反向隧道（必须在路由注册前）
+	tunnel.Init(tunnel.NewFactoryLookup(o.Factory))
+
+	// 节点 SSH 连接统一按库内记录的主机公钥校验
+	sshutil.SetHostKeyStore(node.HostKeyStore{Factory: o.Factory})
+
+	if err := o.bootstrapDatabase(); err != nil {
+		return err
+	}
+
+	// 审计记录实时推送到外部 sink
+	if _, err := auditsink.Init(o.ComponentConfig.AuditSinks); err != nil {
+		return err
+	}
+
+	auditChain, err := auditchain.NewChain(o.ComponentConfig.AuditChain, o.Factory)
+	if err != nil {
+		return err
+	}
+
+	o.AlertEvaluator = jobmanager.NewAlertEvaluator(o.Factory)
+	clusterHealth := jobmanager.NewClusterHealthRecorder(o.ComponentConfig.ClusterHealth, o.Factory)
+	accessOpts := o.ComponentConfig.Log.AccessOptions()
+	o.JobManager = jobmanager.NewManager(
+		&accessOpts,
+		jobmanager.NewAuditsCleaner(o.ComponentConfig.Audit, auditChain, o.Factory, recording.NewLocalStorage(o.ComponentConfig.Recording.Dir)),
+		jobmanager.NewAuditCheckpointer(o.ComponentConfig.AuditChain, auditChain),
+		jobmanager.NewAlertHistoryCleaner(o.ComponentConfig.AlertHistory, o.Factory),
+		jobmanager.NewClusterEventsCleaner(o.ComponentConfig.ClusterHealth, o.Factory),
+		jobmanager.NewClusterSyncer(o.Factory, o.ComponentConfig.Default.Mode.InDebug(), clusterHealth),
+		jobmanager.NewAgentSyncer(o.Factory),
+		jobmanager.NewTunnelSyncer(o.Factory, clusterHealth),
+		jobmanager.NewEtcdBackupScheduler(o.ComponentConfig.EtcdBackup, o.Factory),
+		jobmanager.NewCertificateChecker(o.ComponentConfig.Certificate, o.Factory),
+		jobmanager.NewChartIndexer(o.ComponentConfig.ChartCatalog, o.Factory),
+		// 凭据过期告警去重状态由该 cluster 控制器实例持有
+		jobmanager.NewCredentialSyncer(o.ComponentConfig.ClusterCredential.Schedule, o.Controller.Cluster()),
+		o.AlertEvaluator,
+	)
+	return nil
+}
+
+// LoadConfig 读取配置文件并补齐默认值
+func (o *Options) LoadConfig() error {
 	// 配置文件优先级: 默认配置，环境变量，命令行
 	if len(o.ConfigFile) == 0 {
 		// Try to read config file path from env.
@@ -117,6 +191,16 @@ func (o *Options) Complete(cmd *cobra.Command) error {
 	if o.ComponentConfig.Worker.WorkDir == "" {
 		o.ComponentConfig.Worker.WorkDir = defaultWorkDir
 	}
+	if o.ComponentConfig.Recording.Dir == "" {
+		o.ComponentConfig.Recording.Dir = filepath.Join(o.ComponentConfig.Worker.WorkDir, "recordings")
+	}
+	o.ComponentConfig.EtcdBackup.SetDefaults(o.ComponentConfig.Worker.WorkDir)
+	o.ComponentConfig.Certificate.SetDefaults()
+	o.ComponentConfig.ChartCatalog.SetDefaults()
+	o.ComponentConfig.AuditSinks.SetDefaults()
+	o.ComponentConfig.AuditChain.SetDefaults(o.ComponentConfig.Worker.WorkDir)
+	o.ComponentConfig.Metrics.SetDefaults()
+	o.ComponentConfig.Tracing.SetDefaults()
 	if len(o.ComponentConfig.Default.StaticFiles) == 0 {
 		o.ComponentConfig.Default.StaticFiles = defaultStaticDir
 	}
@@ -132,6 +216,18 @@ func (o *Options) Complete(cmd *cobra.Command) error {
 	if o.ComponentConfig.AlertHistory.DaysReserved == 0 {
 		o.ComponentConfig.AlertHistory.DaysReserved = jobmanager.DefaultAlertHistoryDaysReserved
 	}
+	if o.ComponentConfig.ClusterHealth.Schedule == "" {
+		o.ComponentConfig.ClusterHealth.Schedule = jobmanager.DefaultClusterHealthSchedule
+	}
+	if o.ComponentConfig.ClusterHealth.DaysReserved == 0 {
+		o.ComponentConfig.ClusterHealth.DaysReserved = jobmanager.DefaultClusterHealthDaysReserved
+	}
+	if o.ComponentConfig.ClusterHealth.FlapWindowMinutes == 0 {
+		o.ComponentConfig.ClusterHealth.FlapWindowMinutes = jobmanager.DefaultClusterFlapWindowMinutes
+	}
+	if o.ComponentConfig.ClusterHealth.FlapThreshold == 0 {
+		o.ComponentConfig.ClusterHealth.FlapThreshold = jobmanager.DefaultClusterFlapThreshold
+	}
 	if len(o.ComponentConfig.Default.AdminUser) == 0 {
 		o.ComponentConfig.Default.AdminUser = defaultAdminUser
 	}
@@ -142,34 +238,6 @@ func (o *Options) Complete(cmd *cobra.Command) error {
 	if err := o.ComponentConfig.Valid(); err != nil {
 		return err
 	}
-
-	o.ComponentConfig.Log.Init(isCLIVerbositySet(cmd))
-
-	// 注册依赖组件
-	if err := o.register(); err != nil {
-		return err
-	}
-
-	o.Controller = controller.New(o.ComponentConfig, o.Factory)
-
-	// 初始化 Agent 反向隧道（必须在路由注册前）
-	tunnel.Init(tunnel.FactoryLookup{Factory: o.Factory})
-
-	if err := o.bootstrapDatabase(); err != nil {
-		return err
-	}
-
-	o.AlertEvaluator = jobmanager.NewAlertEvaluator(o.Factory)
-	accessOpts := o.ComponentConfig.Log.AccessOptions()
-	o.JobManager = jobmanager.NewManager(
-		&accessOpts,
-		jobmanager.NewAuditsCleaner(o.ComponentConfig.Audit, o.Factory),
-		jobmanager.NewAlertHistoryCleaner(o.ComponentConfig.AlertHistory, o.Factory),
-		jobmanager.NewClusterSyncer(o.Factory, o.ComponentConfig.Default.Mode.InDebug()),
-		jobmanager.NewAgentSyncer(o.Factory),
-		jobmanager.NewTunnelSyncer(o.Factory),
-		o.AlertEvaluator,
-	)
 	return nil
 }
 
@@ -219,6 +287,16 @@ func (o *Options) registerDatabase() error {
 	}
 	o.db = db
 
+	// 敏感字段加密需在任何读写之前注册
+	if o.ComponentConfig.Encryption.IsEnabled() {
+		if o.envelope, err = o.ComponentConfig.Encryption.NewEnvelope(); err != nil {
+			return err
+		}
+		if err = pixiudb.RegisterEncryption(db, o.envelope); err != nil {
+			return err
+		}
+	}
+
 	// 设置数据库连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -226,9 +304,46 @@ func (o *Options) registerDatabase() error {
 	}
 	sqlDB.SetMaxIdleConns(maxIdleConns)
 	sqlDB.SetMaxOpenConns(maxOpenConns)
+	metrics.RegisterDBStats(sqlConfig.Name, sqlDB)
+
+	if tracing.Enabled() {
+		if err = tracing.RegisterGorm(db); err != nil {
+			return err
+		}
+	}
+
+	if o.Factory, err = pixiudb.NewDaoFactory(db, o.ComponentConfig.Default.AutoMigrate); err != nil {
+		return err
+	}
 
-	o.Factory, err = pixiudb.NewDaoFactory(db, o.ComponentConfig.Default.AutoMigrate)
-	return err
+	// 加密历史明文数据
+	if o.envelope != nil {
+		n, err := pixiudb.ReencryptSecrets(context.TODO(), db, o.envelope, true)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt existing secrets: %v", err)
+		}
+		if n > 0 {
+			klog.Infof("encrypted %d existing plaintext secret(s)", n)
+		}
+	}
+	return nil
+}
+
+// RotateEncryptionKey 使用当前主密钥重新加密所有敏感字段，返回更新的字段数。
+// 仅连接数据库，不启动其他组件。
+func (o *Options) RotateEncryptionKey(ctx context.Context) (int64, error) {
+	if err := o.LoadConfig(); err != nil {
+		return 0, err
+	}
+	if !o.ComponentConfig.Encryption.IsEnabled() {
+		return 0, fmt.Errorf("encryption is not configured, please set encryption.key_file")
+	}
+	// 复用数据库初始化流程，不做表结构迁移
+	o.ComponentConfig.Default.AutoMigrate = false
+	if err := o.registerDatabase(); err != nil {
+		return 0, err
+	}
+	return pixiudb.ReencryptSecrets(ctx, o.db, o.envelope, false)
 }
 
 // Validate validates all the required options.
@@ -32,6 +32,8 @@ import (
 
 	"github.com/caoyingjunz/pixiu/api/server/router"
 	"github.com/caoyingjunz/pixiu/cmd/app/options"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/util/tlscert"
 )
 
@@ -76,7 +78,7 @@ func NewServerCommand(version string) *cobra.Command {
 			fmt.Println(version)
 		},
 	}
-	cmd.AddCommand(verCmd)
+	cmd.AddCommand(verCmd, newEncryptionCommand(opts))
 	return cmd
 }
 
@@ -153,6 +155,15 @@ func Run(opt *options.Options) error {
 		opt.AlertEvaluator.Stop()
 	}
 
+	klog.Info("flushing audit sinks ...")
+	if d := auditsink.Default(); d != nil {
+		d.Close(ctx)
+	}
+
+	if err := tracing.Shutdown(ctx); err != nil {
+		klog.Errorf("failed to flush tracing spans: %v", err)
+	}
+
 	return nil
 }
 
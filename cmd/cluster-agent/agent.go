@@ -43,11 +43,23 @@ func main() {
 	defer cancel()
 
 	cg := clusteragent.Agent{
-		Server:   strings.TrimRight(server, "/"),
-		Token:    token,
-		Insecure: strings.EqualFold(os.Getenv("PIXIU_INSECURE"), "true"),
+		Server:         strings.TrimRight(server, "/"),
+		Token:          token,
+		Insecure:       strings.EqualFold(os.Getenv("PIXIU_INSECURE"), "true"),
+		AllowedTargets: splitTargets(os.Getenv("PIXIU_ALLOWED_TARGETS")),
 	}
 	if err := cg.Run(ctx); err != nil {
 		klog.Fatalf("agent run failed: %v", err)
 	}
 }
+
+// splitTargets 解析逗号分隔的允许名单，例如 "prometheus.monitoring:9090,redis.db:6379"
+func splitTargets(raw string) []string {
+	var targets []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditchain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// verifyBatchSize 校验时每批读取的审计记录数
+const verifyBatchSize = 500
+
+// Chain 审计哈希链的检查点签名、清理锚点与校验。
+// 记录的链接在 db.AuditInterface.Create 写入时完成。
+type Chain struct {
+	factory db.ShareDaoFactory
+	signer  *Signer
+}
+
+func NewChain(o Options, f db.ShareDaoFactory) (*Chain, error) {
+	signer, err := LoadOrCreateSigner(o.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if err = signer.Trust(o.TrustedKeys...); err != nil {
+		return nil, err
+	}
+	return &Chain{factory: f, signer: signer}, nil
+}
+
+// Checkpoint 对当前链头签名，链头自上次检查点后未前进时返回 nil
+func (c *Chain) Checkpoint(ctx context.Context) (*model.AuditCheckpoint, error) {
+	head, err := c.factory.Audit().GetChainHead(ctx)
+	if err != nil || head == nil || head.LastAuditId == 0 {
+		return nil, err
+	}
+
+	var since int64
+	latest, err := c.factory.Audit().Checkpoint().GetLatest(ctx, model.AuditCheckpointPeriodic)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		// 其他实例使用了不同的密钥，继续签名会产生本实例之外无法校验的检查点
+		if !c.signer.Known(latest.KeyId) {
+			return nil, fmt.Errorf("latest audit checkpoint is signed by unknown key %s (local key %s): "+
+				"all pixiu instances must share audit_chain.key_file, or list the key in audit_chain.trusted_keys", latest.KeyId, c.signer.KeyId())
+		}
+		if latest.AuditId >= head.LastAuditId {
+			return nil, nil
+		}
+		since = latest.AuditId
+	}
+
+	count, err := c.factory.Audit().Count(ctx, db.WithIdAfter(since), db.WithIdAtMost(head.LastAuditId))
+	if err != nil {
+		return nil, err
+	}
+	cp := &model.AuditCheckpoint{
+		Kind:     model.AuditCheckpointPeriodic,
+		AuditId:  head.LastAuditId,
+		Hash:     head.LastHash,
+		Count:    count,
+		SignedAt: time.Now().Truncate(time.Second),
+	}
+	c.signer.Sign(cp)
+	return c.factory.Audit().Checkpoint().Create(ctx, cp)
+}
+
+// Purge 删除 before 之前的审计记录。删除前写入签名锚点，记录最后一条被删除记录的哈希，
+// 使剩余记录仍可从锚点接续校验。按 id 截断，与 before 相差不足一秒的记录可能一并删除。
+func (c *Chain) Purge(ctx context.Context, before time.Time) (int64, error) {
+	last, err := c.factory.Audit().List(ctx, db.WithCreatedBefore(before), db.WithOrderByDesc(), db.WithLimit(1))
+	if err != nil || len(last) == 0 {
+		return 0, err
+	}
+	target := last[0]
+
+	count, err := c.factory.Audit().Count(ctx, db.WithIdAtMost(target.Id))
+	if err != nil {
+		return 0, err
+	}
+	anchor := &model.AuditCheckpoint{
+		Kind:     model.AuditCheckpointAnchor,
+		AuditId:  target.Id,
+		Hash:     target.Hash,
+		Count:    count,
+		SignedAt: time.Now().Truncate(time.Second),
+	}
+	c.signer.Sign(anchor)
+	if _, err = c.factory.Audit().Checkpoint().Create(ctx, anchor); err != nil {
+		return 0, err
+	}
+
+	return c.factory.Audit().BatchDelete(ctx, db.WithIdAtMost(target.Id))
+}
+
+// Verify 从最近的有效锚点起按 id 升序校验哈希链，并核对签名检查点与链头
+func (c *Chain) Verify(ctx context.Context) (*Report, error) {
+	// 先读取链头，校验期间新写入的记录不参与本次校验
+	head, err := c.factory.Audit().GetChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	anchors, err := c.factory.Audit().Checkpoint().List(ctx,
+		db.WithAuditCheckpointKind(model.AuditCheckpointAnchor), db.WithOrderByDesc())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		anchor          *model.AuditCheckpoint
+		signatureIssues []Issue
+	)
+	for i := range anchors {
+		if err = c.signer.Verify(&anchors[i]); err != nil {
+			signatureIssues = append(signatureIssues, Issue{AuditId: anchors[i].AuditId, Type: IssueInvalidSignature, Message: "anchor " + err.Error()})
+			continue
+		}
+		if anchor == nil || anchors[i].AuditId > anchor.AuditId {
+			anchor = &anchors[i]
+		}
+	}
+
+	var start int64
+	if anchor != nil {
+		start = anchor.AuditId
+	}
+	objects, err := c.factory.Audit().Checkpoint().List(ctx,
+		db.WithAuditCheckpointKind(model.AuditCheckpointPeriodic), db.WithAuditIdAfter(start), db.WithOrderByASC())
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints []model.AuditCheckpoint
+	for i := range objects {
+		if err = c.signer.Verify(&objects[i]); err != nil {
+			signatureIssues = append(signatureIssues, Issue{AuditId: objects[i].AuditId, Type: IssueInvalidSignature, Message: "checkpoint " + err.Error()})
+			continue
+		}
+		checkpoints = append(checkpoints, objects[i])
+	}
+
+	v := newVerifier(anchor, checkpoints)
+	for _, issue := range signatureIssues {
+		v.addIssue(issue.AuditId, issue.Type, "%s", issue.Message)
+	}
+
+	opts := []db.Options{db.WithOrderByASC(), db.WithLimit(verifyBatchSize)}
+	if head != nil {
+		opts = append(opts, db.WithIdAtMost(head.LastAuditId))
+	}
+	cursor := start
+	for {
+		audits, err := c.factory.Audit().List(ctx, append(opts, db.WithIdAfter(cursor))...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range audits {
+			v.check(&audits[i])
+		}
+		if len(audits) < verifyBatchSize {
+			break
+		}
+		cursor = audits[len(audits)-1].Id
+	}
+
+	report := v.finish(head)
+	if !report.Valid {
+		klog.Warningf("audit chain verification found %d issues", len(report.Issues))
+	}
+	return report, nil
+}
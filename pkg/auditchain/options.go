@@ -0,0 +1,55 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditchain
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultCheckpointSchedule 每 10 分钟对链头签名一次
+	DefaultCheckpointSchedule = "*/10 * * * *"
+	defaultKeyFileName        = "audit-chain.key"
+)
+
+// Options 审计哈希链配置
+type Options struct {
+	// ed25519 签名私钥（PKCS#8 PEM），不存在时自动生成并告警；多副本部署需共享同一密钥
+	KeyFile string `yaml:"key_file"`
+	// 受信任的公钥（PKIX PEM）文件，用于校验其他实例或轮换前密钥签名的检查点
+	TrustedKeys        []string `yaml:"trusted_keys"`
+	CheckpointSchedule string   `yaml:"checkpoint_schedule"`
+}
+
+func (o *Options) SetDefaults(workDir string) {
+	if o.KeyFile == "" {
+		o.KeyFile = filepath.Join(workDir, defaultKeyFileName)
+	}
+	if o.CheckpointSchedule == "" {
+		o.CheckpointSchedule = DefaultCheckpointSchedule
+	}
+}
+
+func (o *Options) Valid() error {
+	if _, err := cron.ParseStandard(o.CheckpointSchedule); err != nil {
+		return fmt.Errorf("audit_chain.checkpoint_schedule 无效: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditchain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// Signer 使用 ed25519 对检查点签名，校验时接受自身密钥与受信任的公钥
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyId string
+
+	// 受信任的公钥，按 key id 索引，包含自身公钥
+	trusted map[string]ed25519.PublicKey
+}
+
+// LoadOrCreateSigner 读取签名私钥，文件不存在时生成新密钥并以 0600 权限写入。
+// 生成的密钥只存在于本实例，多实例部署时其他实例的签名无法校验，因此生成时告警。
+func LoadOrCreateSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if data, err = generateKey(path); err != nil {
+			return nil, err
+		}
+		signer, err := parseSigner(path, data)
+		if err != nil {
+			return nil, err
+		}
+		// 公钥写入 <key_file>.pub，供其他实例配置为 trusted_keys
+		if public, err := signer.PublicKeyPEM(); err == nil {
+			if err = os.WriteFile(path+".pub", public, 0o644); err != nil {
+				klog.Warningf("failed to write audit chain public key %s.pub: %v", path, err)
+			}
+		}
+		klog.Warningf("generated audit chain key %s (key id %s); when running multiple pixiu instances, "+
+			"all of them must share audit_chain.key_file or list each other's public keys (%s.pub) in audit_chain.trusted_keys", path, signer.KeyId(), path)
+		return signer, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain key %s: %v", path, err)
+	}
+	return parseSigner(path, data)
+}
+
+func parseSigner(path string, data []byte) (*Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("audit chain key %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit chain key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("audit chain key %s is not an ed25519 key", path)
+	}
+	return NewSigner(key), nil
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	public := key.Public().(ed25519.PublicKey)
+	keyId := publicKeyId(public)
+	return &Signer{
+		key:     key,
+		keyId:   keyId,
+		trusted: map[string]ed25519.PublicKey{keyId: public},
+	}
+}
+
+func publicKeyId(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Trust 加载其他实例或历史密钥的公钥（PKIX PEM），也可直接使用私钥文件，用于校验其签名
+func (s *Signer) Trust(paths ...string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read audit chain trusted key %s: %v", path, err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return fmt.Errorf("audit chain trusted key %s is not PEM encoded", path)
+		}
+		var parsed interface{}
+		switch block.Type {
+		case "PUBLIC KEY":
+			parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "PRIVATE KEY":
+			var private interface{}
+			if private, err = x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+				if key, ok := private.(ed25519.PrivateKey); ok {
+					parsed = key.Public()
+				}
+			}
+		default:
+			return fmt.Errorf("audit chain trusted key %s has unsupported PEM type %s", path, block.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse audit chain trusted key %s: %v", path, err)
+		}
+		key, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("audit chain trusted key %s is not an ed25519 key", path)
+		}
+		s.trusted[publicKeyId(key)] = key
+	}
+	return nil
+}
+
+// Known 判断 key id 是否为自身或受信任的密钥
+func (s *Signer) Known(keyId string) bool {
+	_, ok := s.trusted[keyId]
+	return ok
+}
+
+// PublicKeyPEM 导出自身公钥，供其他实例配置为 trusted_keys
+func (s *Signer) PublicKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(s.key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func generateKey(path string) ([]byte, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if os.IsExist(err) {
+		// 并发生成时以先写入者为准
+		return os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit chain key %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return data, nil
+}
+
+// KeyId 公钥指纹
+func (s *Signer) KeyId() string {
+	return s.keyId
+}
+
+func (s *Signer) Sign(cp *model.AuditCheckpoint) {
+	cp.KeyId = s.keyId
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, cp.SigningPayload()))
+}
+
+func (s *Signer) Verify(cp *model.AuditCheckpoint) error {
+	public, ok := s.trusted[cp.KeyId]
+	if !ok {
+		return fmt.Errorf("signed by unknown key %s: all pixiu instances must share audit_chain.key_file, "+
+			"or list the key in audit_chain.trusted_keys", cp.KeyId)
+	}
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %v", err)
+	}
+	if !ed25519.Verify(public, cp.SigningPayload(), sig) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
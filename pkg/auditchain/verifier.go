@@ -0,0 +1,146 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditchain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// maxIssues 报告中保留的问题条数上限
+const maxIssues = 100
+
+type IssueType string
+
+const (
+	IssueBrokenLink       IssueType = "broken_link"         // 上一条哈希不匹配：记录被删除、插入或重排
+	IssueModified         IssueType = "modified"            // 内容与哈希不一致：记录被修改
+	IssueUnchained        IssueType = "unchained"           // 链中出现无哈希记录
+	IssueCheckpoint       IssueType = "checkpoint_mismatch" // 检查点所在记录缺失或哈希不一致
+	IssueInvalidSignature IssueType = "invalid_signature"   // 检查点或锚点签名无效
+	IssueHeadMismatch     IssueType = "head_mismatch"       // 链尾与链头记录不一致：尾部被截断
+)
+
+type Issue struct {
+	AuditId int64     `json:"audit_id"`
+	Type    IssueType `json:"type"`
+	Message string    `json:"message"`
+}
+
+// Report 哈希链校验结果
+type Report struct {
+	Valid        bool                   `json:"valid"`
+	Checked      int64                  `json:"checked"`   // 校验的链式记录数
+	Unchained    int64                  `json:"unchained"` // 启用哈希链前写入的历史记录数
+	FirstAuditId int64                  `json:"first_audit_id"`
+	LastAuditId  int64                  `json:"last_audit_id"`
+	Checkpoints  int                    `json:"checkpoints"` // 参与校验的签名检查点数
+	Anchor       *model.AuditCheckpoint `json:"anchor,omitempty"`
+	Issues       []Issue                `json:"issues"`
+	Truncated    bool                   `json:"truncated"` // 问题数超出上限，仅保留前 maxIssues 条
+}
+
+// verifier 按 id 升序逐条校验审计记录
+type verifier struct {
+	report *Report
+
+	started     bool
+	lastId      int64
+	prevHash    string
+	checkpoints map[int64]model.AuditCheckpoint
+}
+
+// newVerifier anchor 与 checkpoints 需已通过签名校验
+func newVerifier(anchor *model.AuditCheckpoint, checkpoints []model.AuditCheckpoint) *verifier {
+	v := &verifier{
+		report:      &Report{Anchor: anchor, Issues: []Issue{}},
+		checkpoints: make(map[int64]model.AuditCheckpoint, len(checkpoints)),
+	}
+	// 锚点之后的记录均应在链上，并接续被清理的最后一条记录
+	if anchor != nil && anchor.Hash != "" {
+		v.started = true
+		v.lastId, v.prevHash = anchor.AuditId, anchor.Hash
+	}
+	for _, cp := range checkpoints {
+		v.checkpoints[cp.AuditId] = cp
+	}
+	v.report.Checkpoints = len(checkpoints)
+	return v
+}
+
+func (v *verifier) addIssue(auditId int64, t IssueType, format string, args ...interface{}) {
+	if len(v.report.Issues) >= maxIssues {
+		v.report.Truncated = true
+		return
+	}
+	v.report.Issues = append(v.report.Issues, Issue{AuditId: auditId, Type: t, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *verifier) check(a *model.Audit) {
+	if a.Hash == "" {
+		if v.started {
+			v.addIssue(a.Id, IssueUnchained, "record has no hash inside the chain")
+		} else {
+			v.report.Unchained++
+		}
+		return
+	}
+
+	v.started = true
+	if a.PrevHash != v.prevHash {
+		v.addIssue(a.Id, IssueBrokenLink, "expected prev hash %q, got %q: records before it were removed, inserted or reordered", v.prevHash, a.PrevHash)
+	}
+	if a.ComputeHash() != a.Hash {
+		v.addIssue(a.Id, IssueModified, "content does not match its hash")
+	}
+	if cp, ok := v.checkpoints[a.Id]; ok {
+		if cp.Hash != a.Hash {
+			v.addIssue(a.Id, IssueCheckpoint, "hash differs from %s signed at %s", cp.Kind, cp.SignedAt.Format("2006-01-02 15:04:05"))
+		}
+		delete(v.checkpoints, a.Id)
+	}
+
+	// 以存储的哈希继续，单条记录被修改只报告一次
+	v.lastId, v.prevHash = a.Id, a.Hash
+	if v.report.FirstAuditId == 0 {
+		v.report.FirstAuditId = a.Id
+	}
+	v.report.LastAuditId = a.Id
+	v.report.Checked++
+}
+
+// finish 校验剩余检查点与链头，head 为校验开始前读取的链头
+func (v *verifier) finish(head *model.AuditChainHead) *Report {
+	missing := make([]int64, 0, len(v.checkpoints))
+	for auditId := range v.checkpoints {
+		missing = append(missing, auditId)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	for _, auditId := range missing {
+		cp := v.checkpoints[auditId]
+		v.addIssue(auditId, IssueCheckpoint, "record signed by %s at %s is missing", cp.Kind, cp.SignedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	if head != nil && head.LastAuditId != 0 && (head.LastAuditId != v.lastId || head.LastHash != v.prevHash) {
+		v.addIssue(head.LastAuditId, IssueHeadMismatch, "chain head is record %d, but the chain ends at record %d", head.LastAuditId, v.lastId)
+	}
+
+	v.report.Valid = len(v.report.Issues) == 0
+	return v.report
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditchain
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+// buildChain 模拟 db.AuditInterface.Create 的链接过程
+func buildChain(n int) []model.Audit {
+	audits := make([]model.Audit, 0, n)
+	prev := ""
+	for i := 1; i <= n; i++ {
+		a := model.Audit{
+			Model:    pixiu.Model{Id: int64(i), GmtCreate: time.Unix(1700000000+int64(i), 0)},
+			Operator: "admin",
+			Action:   "POST",
+			Path:     "/pixiu/clusters",
+			PrevHash: prev,
+		}
+		a.Hash = a.ComputeHash()
+		prev = a.Hash
+		audits = append(audits, a)
+	}
+	return audits
+}
+
+func headOf(audits []model.Audit) *model.AuditChainHead {
+	last := audits[len(audits)-1]
+	return &model.AuditChainHead{LastAuditId: last.Id, LastHash: last.Hash}
+}
+
+func verify(audits []model.Audit, head *model.AuditChainHead, anchor *model.AuditCheckpoint, checkpoints ...model.AuditCheckpoint) *Report {
+	v := newVerifier(anchor, checkpoints)
+	for i := range audits {
+		v.check(&audits[i])
+	}
+	return v.finish(head)
+}
+
+func expectIssues(t *testing.T, report *Report, want ...IssueType) {
+	t.Helper()
+	if len(report.Issues) != len(want) {
+		t.Fatalf("expected issues %v, got %+v", want, report.Issues)
+	}
+	for i, issue := range report.Issues {
+		if issue.Type != want[i] {
+			t.Fatalf("expected issue %d to be %s, got %+v", i, want[i], issue)
+		}
+	}
+	if report.Valid != (len(want) == 0) {
+		t.Fatalf("unexpected valid %v", report.Valid)
+	}
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	audits := buildChain(5)
+	// 启用哈希链前的历史记录不参与链接
+	legacy := model.Audit{Model: pixiu.Model{Id: 0}, Operator: "old"}
+	report := verify(append([]model.Audit{legacy}, audits...), headOf(audits), nil)
+
+	expectIssues(t, report)
+	if report.Checked != 5 || report.Unchained != 1 || report.FirstAuditId != 1 || report.LastAuditId != 5 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	t.Run("modified", func(t *testing.T) {
+		audits := buildChain(5)
+		head := headOf(audits)
+		audits[2].Operator = "someone-else"
+		report := verify(audits, head, nil)
+		expectIssues(t, report, IssueModified)
+		if report.Issues[0].AuditId != 3 {
+			t.Fatalf("expected record 3, got %+v", report.Issues[0])
+		}
+	})
+
+	t.Run("modified and rehashed", func(t *testing.T) {
+		audits := buildChain(5)
+		head := headOf(audits)
+		audits[2].Operator = "someone-else"
+		audits[2].Hash = audits[2].ComputeHash()
+		expectIssues(t, verify(audits, head, nil), IssueBrokenLink)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		audits := buildChain(5)
+		head := headOf(audits)
+		audits = append(audits[:1], audits[2:]...)
+		expectIssues(t, verify(audits, head, nil), IssueBrokenLink)
+	})
+
+	t.Run("tail truncated", func(t *testing.T) {
+		audits := buildChain(5)
+		head := headOf(audits)
+		expectIssues(t, verify(audits[:3], head, nil), IssueHeadMismatch)
+	})
+
+	t.Run("whole chain rewritten after checkpoint", func(t *testing.T) {
+		audits := buildChain(5)
+		cp := model.AuditCheckpoint{Kind: model.AuditCheckpointPeriodic, AuditId: 4, Hash: audits[3].Hash}
+
+		// 篡改第 2 条并重算其后全部哈希与链头，只有签名检查点能发现
+		audits[1].Operator = "someone-else"
+		for i := 1; i < len(audits); i++ {
+			audits[i].PrevHash = audits[i-1].Hash
+			audits[i].Hash = audits[i].ComputeHash()
+		}
+		expectIssues(t, verify(audits, headOf(audits), nil, cp), IssueCheckpoint)
+	})
+}
+
+func TestVerifyFromAnchor(t *testing.T) {
+	audits := buildChain(6)
+	anchor := &model.AuditCheckpoint{Kind: model.AuditCheckpointAnchor, AuditId: 3, Hash: audits[2].Hash, Count: 3}
+
+	report := verify(audits[3:], headOf(audits), anchor)
+	expectIssues(t, report)
+	if report.Checked != 3 || report.FirstAuditId != 4 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// 锚点之后首条记录被删除
+	expectIssues(t, verify(audits[4:], headOf(audits), anchor), IssueBrokenLink)
+
+	// 全部记录被清理且无新记录
+	head := &model.AuditChainHead{LastAuditId: 3, LastHash: audits[2].Hash}
+	expectIssues(t, verify(nil, head, anchor))
+}
+
+func TestSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "audit-chain.key")
+	signer, err := LoadOrCreateSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadOrCreateSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.KeyId() != reloaded.KeyId() {
+		t.Fatalf("reloaded key differs: %s != %s", signer.KeyId(), reloaded.KeyId())
+	}
+
+	cp := &model.AuditCheckpoint{Kind: model.AuditCheckpointPeriodic, AuditId: 10, Hash: "abc", Count: 10, SignedAt: time.Unix(1700000000, 0)}
+	signer.Sign(cp)
+	if err = reloaded.Verify(cp); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	cp.Hash = "abd"
+	if err = signer.Verify(cp); err == nil {
+		t.Fatal("expected signature mismatch after changing hash")
+	}
+
+	other, err := LoadOrCreateSigner(filepath.Join(t.TempDir(), "other.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Hash = "abc"
+	if err = other.Verify(cp); err == nil {
+		t.Fatal("expected unknown key error")
+	}
+
+	// 生成密钥时写入的公钥可供其他实例信任
+	if err = other.Trust(path + ".pub"); err != nil {
+		t.Fatalf("trust public key: %v", err)
+	}
+	if !other.Known(signer.KeyId()) {
+		t.Fatal("expected trusted key to be known")
+	}
+	if err = other.Verify(cp); err != nil {
+		t.Fatalf("verify with trusted key: %v", err)
+	}
+	if err = other.Trust(filepath.Join(t.TempDir(), "missing.pub")); err == nil {
+		t.Fatal("expected error for missing trusted key")
+	}
+}
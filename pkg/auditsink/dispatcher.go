@@ -0,0 +1,264 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// Sink 审计事件的外部投递目标，同一 sink 的 Send 只会被单个 goroutine 串行调用
+type Sink interface {
+	// Send 投递一批事件，返回错误时整批计为失败
+	Send(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// Stats sink 投递统计
+type Stats struct {
+	Name          string     `json:"name"`
+	Type          SinkType   `json:"type"`
+	Queued        int        `json:"queued"`   // 队列中待投递
+	Sent          int64      `json:"sent"`     // 投递成功
+	Failed        int64      `json:"failed"`   // 重试后仍投递失败
+	Dropped       int64      `json:"dropped"`  // 队列已满被丢弃
+	Filtered      int64      `json:"filtered"` // 不满足过滤条件
+	LastSentTime  *time.Time `json:"last_sent_time,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+}
+
+// Dispatcher 将审计记录按各 sink 的过滤条件分发到其独立队列
+type Dispatcher struct {
+	workers []*worker
+
+	// mu 保护 closed：Publish 持读锁入队，Close 持写锁关闭队列，避免向已关闭的 channel 发送
+	mu     sync.RWMutex
+	closed bool
+}
+
+type worker struct {
+	name      string
+	sinkType  SinkType
+	sink      Sink
+	filter    *filter
+	queue     chan Event
+	batchSize int
+	interval  time.Duration
+
+	ctx  context.Context
+	done chan struct{}
+
+	sent, failed, dropped, filtered int64
+
+	mu            sync.Mutex
+	lastSentTime  *time.Time
+	lastError     string
+	lastErrorTime *time.Time
+}
+
+var defaultDispatcher *Dispatcher
+
+// Init 创建进程级 Dispatcher，未配置 sink 时不推送。
+func Init(o Options) (*Dispatcher, error) {
+	d, err := NewDispatcher(context.Background(), o)
+	if err != nil {
+		return nil, err
+	}
+	defaultDispatcher = d
+	return d, nil
+}
+
+// Default 返回进程级 Dispatcher（Init 前为 nil）。
+func Default() *Dispatcher {
+	return defaultDispatcher
+}
+
+// Publish 推送审计记录到进程级 Dispatcher，未初始化时忽略。
+func Publish(a *model.Audit) {
+	if d := defaultDispatcher; d != nil {
+		d.Publish(a)
+	}
+}
+
+func NewDispatcher(ctx context.Context, o Options) (*Dispatcher, error) {
+	d := &Dispatcher{}
+	for _, opts := range o.Sinks {
+		sink, err := newSink(opts)
+		if err != nil {
+			d.Close(ctx)
+			return nil, fmt.Errorf("failed to create audit sink %q: %v", opts.Name, err)
+		}
+
+		w := &worker{
+			name:      opts.Name,
+			sinkType:  opts.Type,
+			sink:      sink,
+			filter:    newFilter(opts.Filter),
+			queue:     make(chan Event, opts.QueueSize),
+			batchSize: 1,
+			interval:  time.Second,
+			ctx:       ctx,
+			done:      make(chan struct{}),
+		}
+		if opts.Type == SinkWebhook {
+			w.batchSize = opts.Webhook.BatchSize
+			w.interval = time.Duration(opts.Webhook.FlushInterval) * time.Second
+		}
+		d.workers = append(d.workers, w)
+		go w.run()
+	}
+	return d, nil
+}
+
+func newSink(o SinkOptions) (Sink, error) {
+	switch o.Type {
+	case SinkSyslog:
+		return newSyslogSink(o.Syslog)
+	case SinkWebhook:
+		return newWebhookSink(o.Webhook)
+	case SinkFile:
+		return newFileSink(o.File)
+	default:
+		return nil, fmt.Errorf("unsupported sink type %q", o.Type)
+	}
+}
+
+// Publish 非阻塞入队，sink 队列已满或 Dispatcher 已关闭时丢弃，不影响审计落库
+func (d *Dispatcher) Publish(a *model.Audit) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		klog.V(2).Infof("audit dispatcher is closed, drop audit %d", a.Id)
+		return
+	}
+
+	event := NewEvent(a)
+	for _, w := range d.workers {
+		if !w.filter.match(event) {
+			atomic.AddInt64(&w.filtered, 1)
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			atomic.AddInt64(&w.dropped, 1)
+			klog.Warningf("audit sink %s queue is full, drop audit %d", w.name, a.Id)
+		}
+	}
+}
+
+func (d *Dispatcher) Stats() []Stats {
+	stats := make([]Stats, 0, len(d.workers))
+	for _, w := range d.workers {
+		w.mu.Lock()
+		stats = append(stats, Stats{
+			Name:          w.name,
+			Type:          w.sinkType,
+			Queued:        len(w.queue),
+			Sent:          atomic.LoadInt64(&w.sent),
+			Failed:        atomic.LoadInt64(&w.failed),
+			Dropped:       atomic.LoadInt64(&w.dropped),
+			Filtered:      atomic.LoadInt64(&w.filtered),
+			LastSentTime:  w.lastSentTime,
+			LastError:     w.lastError,
+			LastErrorTime: w.lastErrorTime,
+		})
+		w.mu.Unlock()
+	}
+	return stats
+}
+
+// Close 停止接收并投递队列中剩余事件，ctx 到期后不再等待。之后的 Publish 会被忽略，重复调用无副作用。
+func (d *Dispatcher) Close(ctx context.Context) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	for _, w := range d.workers {
+		close(w.queue)
+	}
+	d.mu.Unlock()
+
+	for _, w := range d.workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			klog.Warningf("audit sink %s closed with %d events pending", w.name, len(w.queue))
+		}
+		if err := w.sink.Close(); err != nil {
+			klog.Errorf("failed to close audit sink %s: %v", w.name, err)
+		}
+	}
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, w.batchSize)
+	for {
+		select {
+		case event, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *worker) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := w.sink.Send(w.ctx, batch); err != nil {
+		atomic.AddInt64(&w.failed, int64(len(batch)))
+		klog.Errorf("failed to send %d audit events to sink %s: %v", len(batch), w.name, err)
+
+		w.mu.Lock()
+		w.lastError, w.lastErrorTime = err.Error(), &now
+		w.mu.Unlock()
+		return
+	}
+
+	atomic.AddInt64(&w.sent, int64(len(batch)))
+	w.mu.Lock()
+	w.lastSentTime = &now
+	w.mu.Unlock()
+}
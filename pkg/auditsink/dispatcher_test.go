@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newFileDispatcher(t *testing.T) (*Dispatcher, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	o := Options{Sinks: []SinkOptions{{Name: "file", Type: SinkFile, File: FileOptions{Path: path}}}}
+	o.SetDefaults()
+	d, err := NewDispatcher(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, path
+}
+
+func TestDispatcherPublishAfterClose(t *testing.T) {
+	d, path := newFileDispatcher(t)
+	d.Publish(testAudit(1))
+	d.Close(context.Background())
+
+	// 关闭后继续 Publish 与重复 Close 均不应 panic
+	d.Publish(testAudit(2))
+	d.Close(context.Background())
+
+	events := readEvents(t, path)
+	if len(events) != 1 || events[0].Id != 1 {
+		t.Fatalf("expected only audit 1 delivered, got %+v", events)
+	}
+}
+
+func TestDispatcherPublishConcurrentClose(t *testing.T) {
+	d, _ := newFileDispatcher(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(base int64) {
+			defer wg.Done()
+			for j := int64(0); j < 200; j++ {
+				d.Publish(testAudit(base*1000 + j))
+			}
+		}(int64(i))
+	}
+	d.Close(context.Background())
+	wg.Wait()
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"strings"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// Event 推送到外部 sink 的审计事件
+type Event struct {
+	Id                int64     `json:"id"`
+	Time              time.Time `json:"time"`
+	RequestId         string    `json:"request_id"`
+	Ip                string    `json:"ip"`
+	Operator          string    `json:"operator"`
+	Action            string    `json:"action"`
+	Path              string    `json:"path"`
+	ResourceType      string    `json:"resource_type"`
+	Status            string    `json:"status"`
+	Duration          int64     `json:"duration"` // ms
+	ResponseCode      int       `json:"response_code"`
+	Cluster           string    `json:"cluster,omitempty"`
+	Resource          string    `json:"resource,omitempty"`
+	ResourceName      string    `json:"resource_name,omitempty"`
+	ResourceNamespace string    `json:"resource_namespace,omitempty"`
+	TokenJTI          string    `json:"token_jti,omitempty"`
+	Hash              string    `json:"hash,omitempty"` // 哈希链中的记录哈希，便于与外部副本比对
+}
+
+func NewEvent(a *model.Audit) Event {
+	return Event{
+		Id:                a.Id,
+		Time:              a.GmtCreate,
+		RequestId:         a.RequestId,
+		Ip:                a.Ip,
+		Operator:          a.Operator,
+		Action:            a.Action,
+		Path:              a.Path,
+		ResourceType:      string(a.ObjectType),
+		Status:            a.Status.String(),
+		Duration:          a.Duration,
+		ResponseCode:      a.ResponseCode,
+		Cluster:           a.Cluster,
+		Resource:          a.Resource,
+		ResourceName:      a.ResourceName,
+		ResourceNamespace: a.ResourceNamespace,
+		TokenJTI:          a.TokenJTI,
+		Hash:              a.Hash,
+	}
+}
+
+type filter struct {
+	objectTypes map[string]bool
+	actions     map[string]bool
+	statuses    map[string]bool
+}
+
+func newFilter(o FilterOptions) *filter {
+	return &filter{
+		objectTypes: toSet(o.ObjectTypes, false),
+		actions:     toSet(o.Actions, true),
+		statuses:    toSet(o.Statuses, false),
+	}
+}
+
+func (f *filter) match(e Event) bool {
+	if len(f.objectTypes) != 0 && !f.objectTypes[e.ResourceType] {
+		return false
+	}
+	if len(f.actions) != 0 && !f.actions[strings.ToUpper(e.Action)] {
+		return false
+	}
+	if len(f.statuses) != 0 && !f.statuses[e.Status] {
+		return false
+	}
+	return true
+}
+
+func toSet(items []string, upper bool) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if upper {
+			item = strings.ToUpper(item)
+		} else {
+			item = strings.ToLower(item)
+		}
+		set[item] = true
+	}
+	return set
+}
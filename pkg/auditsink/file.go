@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type fileSink struct {
+	opts    FileOptions
+	maxSize int64
+
+	file *os.File
+	size int64
+}
+
+func newFileSink(o FileOptions) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(o.Path), 0o700); err != nil {
+		return nil, err
+	}
+	f := &fileSink{
+		opts:    o,
+		maxSize: int64(o.MaxSizeMB) * 1024 * 1024,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileSink) open() error {
+	file, err := os.OpenFile(f.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Send 每个事件写为一行 JSON，写入前超出大小上限则先轮转
+func (f *fileSink) Send(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+			if err = f.rotate(); err != nil {
+				return fmt.Errorf("failed to rotate %s: %v", f.opts.Path, err)
+			}
+		}
+		n, err := f.file.Write(line)
+		f.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotate 将 <path>.N-1 依次后移为 <path>.N，当前文件改名为 <path>.1 后重新打开
+func (f *fileSink) rotate() error {
+	_ = f.file.Close()
+
+	// 改名失败时仍重新打开，保证后续事件可继续写入
+	err := f.shift()
+	if openErr := f.open(); openErr != nil {
+		return openErr
+	}
+	return err
+}
+
+func (f *fileSink) shift() error {
+	if f.opts.MaxBackups <= 0 {
+		if err := os.Remove(f.opts.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	_ = os.Remove(backupName(f.opts.Path, f.opts.MaxBackups))
+	for i := f.opts.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(f.opts.Path, i), backupName(f.opts.Path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.opts.Path, backupName(f.opts.Path, 1))
+}
+
+func backupName(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+func (f *fileSink) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func testAudit(id int64) *model.Audit {
+	return &model.Audit{
+		Model:    pixiu.Model{Id: id, GmtCreate: time.Now()},
+		Operator: "admin",
+		Action:   "DELETE",
+		Path:     "/pixiu/clusters/1",
+		Status:   model.AuditOpFail,
+	}
+}
+
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid jsonl line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	opts := SinkOptions{Name: "file", Type: SinkFile, File: FileOptions{Path: path, MaxBackups: 2}}
+	opts.SetDefaults()
+	sink, err := newFileSink(opts.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	line, _ := json.Marshal(testEvent(1, "succeed"))
+	// 每个文件最多容纳两条事件
+	sink.maxSize = int64(len(line)+1) * 2
+
+	for i := int64(1); i <= 7; i++ {
+		if err = sink.Send(context.Background(), []Event{testEvent(i, "succeed")}); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	// 7 条事件：当前文件 [7]，.1 [5 6]，.2 [3 4]，[1 2] 超出保留数被删除
+	expected := map[string][]int64{
+		path:        {7},
+		path + ".1": {5, 6},
+		path + ".2": {3, 4},
+	}
+	for file, ids := range expected {
+		events := readEvents(t, file)
+		if len(events) != len(ids) {
+			t.Fatalf("%s: expected %d events, got %d", file, len(ids), len(events))
+		}
+		for i, id := range ids {
+			if events[i].Id != id {
+				t.Fatalf("%s: expected event %d at %d, got %d", file, id, i, events[i].Id)
+			}
+		}
+	}
+	if _, err = os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third backup, got %v", err)
+	}
+}
+
+func TestFileSinkAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	opts := Options{Sinks: []SinkOptions{{Name: "file", Type: SinkFile, File: FileOptions{Path: path}}}}
+	opts.SetDefaults()
+
+	// 重启后继续追加到已有文件
+	for round := int64(0); round < 2; round++ {
+		d, err := NewDispatcher(context.Background(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Publish(testAudit(round + 1))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		d.Close(ctx)
+		cancel()
+	}
+
+	events := readEvents(t, path)
+	if len(events) != 2 || events[0].Id != 1 || events[1].Id != 2 || events[1].Status != "failed" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
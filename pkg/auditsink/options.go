@@ -0,0 +1,211 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+type SinkType string
+
+const (
+	SinkSyslog  SinkType = "syslog"
+	SinkWebhook SinkType = "webhook"
+	SinkFile    SinkType = "file"
+)
+
+const (
+	DefaultQueueSize     = 1024
+	DefaultTimeout       = 10 // 秒
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 5 // 秒
+	DefaultMaxRetries    = 3
+	DefaultRetryBackoff  = 1 // 秒
+	DefaultMaxSizeMB     = 100
+	DefaultMaxBackups    = 5
+	DefaultAppName       = "pixiu"
+	// DefaultFacility syslog facility 13（log audit）
+	DefaultFacility = 13
+)
+
+// Options 审计日志外部推送配置，每条审计记录落库后实时推送到匹配的 sink
+type Options struct {
+	Sinks []SinkOptions `yaml:"sinks"`
+}
+
+type SinkOptions struct {
+	Name string   `yaml:"name"`
+	Type SinkType `yaml:"type"`
+	// 每个 sink 独立的内存队列长度，队列满时丢弃并计入 dropped
+	QueueSize int `yaml:"queue_size"`
+
+	Filter  FilterOptions  `yaml:"filter"`
+	Syslog  SyslogOptions  `yaml:"syslog"`
+	Webhook WebhookOptions `yaml:"webhook"`
+	File    FileOptions    `yaml:"file"`
+}
+
+// FilterOptions 推送过滤条件，各字段为空表示不限制
+type FilterOptions struct {
+	ObjectTypes []string `yaml:"object_types"` // 资源类型，如 clusters、proxy
+	Actions     []string `yaml:"actions"`      // HTTP 方法，如 POST、DELETE
+	Statuses    []string `yaml:"statuses"`     // succeed / failed / unknown
+}
+
+type TLSOptions struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// SyslogOptions RFC5424 syslog，TCP 传输使用 RFC6587 octet-counting 分帧
+type SyslogOptions struct {
+	Network  string     `yaml:"network"` // tcp 或 tls
+	Address  string     `yaml:"address"`
+	TLS      TLSOptions `yaml:"tls"`
+	AppName  string     `yaml:"app_name"`
+	Hostname string     `yaml:"hostname"` // 默认为本机 hostname
+	Facility int        `yaml:"facility"`
+	Timeout  int        `yaml:"timeout"` // 连接与写入超时（秒）
+}
+
+// WebhookOptions 以 JSON 数组批量 POST 审计事件，网络错误、429 与 5xx 按指数退避重试
+type WebhookOptions struct {
+	URL           string            `yaml:"url"`
+	Headers       map[string]string `yaml:"headers"`
+	TLS           TLSOptions        `yaml:"tls"`
+	Timeout       int               `yaml:"timeout"`        // 单次请求超时（秒）
+	BatchSize     int               `yaml:"batch_size"`     // 单批最大事件数
+	FlushInterval int               `yaml:"flush_interval"` // 未攒满一批时的最长等待（秒）
+	MaxRetries    int               `yaml:"max_retries"`    // 小于 0 表示不重试
+	RetryBackoff  int               `yaml:"retry_backoff"`  // 首次重试间隔（秒），之后按 2 倍递增
+}
+
+// FileOptions 本地 JSONL 文件，超过 MaxSizeMB 时轮转为 <path>.1 ... <path>.N
+type FileOptions struct {
+	Path       string `yaml:"path"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"` // 小于 0 表示不保留历史文件
+}
+
+func (o *Options) SetDefaults() {
+	for i := range o.Sinks {
+		o.Sinks[i].SetDefaults()
+	}
+}
+
+func (o *SinkOptions) SetDefaults() {
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultQueueSize
+	}
+	switch o.Type {
+	case SinkSyslog:
+		if o.Syslog.Network == "" {
+			o.Syslog.Network = "tcp"
+		}
+		if o.Syslog.AppName == "" {
+			o.Syslog.AppName = DefaultAppName
+		}
+		if o.Syslog.Facility == 0 {
+			o.Syslog.Facility = DefaultFacility
+		}
+		if o.Syslog.Timeout <= 0 {
+			o.Syslog.Timeout = DefaultTimeout
+		}
+	case SinkWebhook:
+		if o.Webhook.Timeout <= 0 {
+			o.Webhook.Timeout = DefaultTimeout
+		}
+		if o.Webhook.BatchSize <= 0 {
+			o.Webhook.BatchSize = DefaultBatchSize
+		}
+		if o.Webhook.FlushInterval <= 0 {
+			o.Webhook.FlushInterval = DefaultFlushInterval
+		}
+		if o.Webhook.MaxRetries == 0 {
+			o.Webhook.MaxRetries = DefaultMaxRetries
+		}
+		if o.Webhook.RetryBackoff <= 0 {
+			o.Webhook.RetryBackoff = DefaultRetryBackoff
+		}
+	case SinkFile:
+		if o.File.MaxSizeMB <= 0 {
+			o.File.MaxSizeMB = DefaultMaxSizeMB
+		}
+		if o.File.MaxBackups == 0 {
+			o.File.MaxBackups = DefaultMaxBackups
+		}
+	}
+}
+
+func (o *Options) Valid() error {
+	names := make(map[string]bool)
+	for _, sink := range o.Sinks {
+		if sink.Name == "" {
+			return fmt.Errorf("audit_sinks.sinks[].name 不能为空")
+		}
+		if names[sink.Name] {
+			return fmt.Errorf("audit_sinks 存在重复的 sink 名称 %q", sink.Name)
+		}
+		names[sink.Name] = true
+		if err := sink.Valid(); err != nil {
+			return fmt.Errorf("audit_sinks %q: %v", sink.Name, err)
+		}
+	}
+	return nil
+}
+
+func (o *SinkOptions) Valid() error {
+	for _, status := range o.Filter.Statuses {
+		switch strings.ToLower(status) {
+		case model.AuditOpSuccess.String(), model.AuditOpFail.String(), model.AuditOpUnknown.String():
+		default:
+			return fmt.Errorf("filter.statuses 不支持 %q", status)
+		}
+	}
+
+	switch o.Type {
+	case SinkSyslog:
+		if o.Syslog.Network != "tcp" && o.Syslog.Network != "tls" {
+			return fmt.Errorf("syslog.network 仅支持 tcp 或 tls")
+		}
+		if o.Syslog.Address == "" {
+			return fmt.Errorf("syslog.address 不能为空")
+		}
+		if o.Syslog.Facility < 0 || o.Syslog.Facility > 23 {
+			return fmt.Errorf("syslog.facility 取值范围为 0-23")
+		}
+	case SinkWebhook:
+		u, err := url.Parse(o.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook.url 无效: %q", o.Webhook.URL)
+		}
+	case SinkFile:
+		if o.File.Path == "" {
+			return fmt.Errorf("file.path 不能为空")
+		}
+	default:
+		return fmt.Errorf("不支持的 sink 类型 %q", o.Type)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+const (
+	severityWarning = 4
+	severityNotice  = 5
+	severityInfo    = 6
+
+	// RFC5424 时间戳最多 6 位小数
+	rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+	syslogMsgId = "audit"
+)
+
+type syslogSink struct {
+	opts      SyslogOptions
+	tlsConfig *tls.Config
+	hostname  string
+	procId    string
+	timeout   time.Duration
+
+	conn net.Conn
+}
+
+func newSyslogSink(o SyslogOptions) (*syslogSink, error) {
+	s := &syslogSink{
+		opts:     o,
+		hostname: o.Hostname,
+		procId:   strconv.Itoa(os.Getpid()),
+		timeout:  time.Duration(o.Timeout) * time.Second,
+	}
+	if s.hostname == "" {
+		s.hostname, _ = os.Hostname()
+	}
+	if o.Network == "tls" {
+		tlsConfig, err := buildTLSConfig(o.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.tlsConfig = tlsConfig
+	}
+	return s, nil
+}
+
+func (s *syslogSink) Send(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		msg, err := s.format(event)
+		if err != nil {
+			return err
+		}
+		// 连接可能已被对端关闭，失败后重连重试一次
+		if err = s.write(ctx, msg); err != nil {
+			s.closeConn()
+			if err = s.write(ctx, msg); err != nil {
+				s.closeConn()
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// format 生成 RFC5424 消息，MSG 为审计事件 JSON
+func (s *syslogSink) format(e Event) ([]byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	severity := severityInfo
+	switch e.Status {
+	case model.AuditOpFail.String():
+		severity = severityWarning
+	case model.AuditOpUnknown.String():
+		severity = severityNotice
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s -",
+		s.opts.Facility*8+severity,
+		e.Time.UTC().Format(rfc5424Time),
+		headerField(s.hostname, 255),
+		headerField(s.opts.AppName, 48),
+		headerField(s.procId, 128),
+		syslogMsgId,
+	)
+	return append([]byte(header+" "), body...), nil
+}
+
+// write 按 RFC6587 octet-counting 分帧写入
+func (s *syslogSink) write(ctx context.Context, msg []byte) error {
+	if s.conn != nil && !s.connAlive() {
+		s.closeConn()
+	}
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(append([]byte(strconv.Itoa(len(msg))+" "), msg...))
+	return err
+}
+
+// connAlive 探测对端是否已关闭连接：syslog 服务端不会回写数据，
+// 短超时读取返回超时以外的错误（如 EOF）即表示连接已不可用，避免写入已关闭的连接导致事件丢失
+func (s *syslogSink) connAlive() bool {
+	if err := s.conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+		return false
+	}
+	var buf [1]byte
+	_, err := s.conn.Read(buf[:])
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+func (s *syslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	if s.tlsConfig != nil {
+		return (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", s.opts.Address)
+	}
+	return dialer.DialContext(ctx, "tcp", s.opts.Address)
+}
+
+func (s *syslogSink) closeConn() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *syslogSink) Close() error {
+	s.closeConn()
+	return nil
+}
+
+// headerField 头部字段只允许可见 ASCII 字符，为空时使用 NILVALUE
+func headerField(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
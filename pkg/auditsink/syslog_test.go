@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFrame 读取一条 RFC6587 octet-counting 帧
+func readFrame(r *bufio.Reader) (string, error) {
+	prefix, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(prefix))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func serveFrames(t *testing.T, ln net.Listener, count int) <-chan []string {
+	t.Helper()
+	result := make(chan []string, 1)
+	go func() {
+		var frames []string
+		defer func() { result <- frames }()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		for len(frames) < count {
+			frame, err := readFrame(r)
+			if err != nil {
+				return
+			}
+			frames = append(frames, frame)
+		}
+	}()
+	return result
+}
+
+func testEvent(id int64, status string) Event {
+	return Event{
+		Id:       id,
+		Time:     time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC),
+		Operator: "admin",
+		Action:   "POST",
+		Path:     "/pixiu/clusters",
+		Status:   status,
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := serveFrames(t, ln, 2)
+
+	opts := SinkOptions{Name: "syslog", Type: SinkSyslog, Syslog: SyslogOptions{Address: ln.Addr().String(), Hostname: "pixiu host"}}
+	opts.SetDefaults()
+	sink, err := newSyslogSink(opts.Syslog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err = sink.Send(context.Background(), []Event{testEvent(1, "succeed"), testEvent(2, "failed")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := <-frames
+	if len(got) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(got))
+	}
+	// facility 13 * 8 + info(6) = 110; warning(4) = 108
+	wantPrefix := fmt.Sprintf("<110>1 2026-01-02T03:04:05.123456Z pixiu_host pixiu %d audit - {", os.Getpid())
+	if !strings.HasPrefix(got[0], wantPrefix) {
+		t.Fatalf("unexpected header:\n%s\nwant prefix:\n%s", got[0], wantPrefix)
+	}
+	if !strings.HasPrefix(got[1], "<108>1 ") {
+		t.Fatalf("failed event should use warning severity: %s", got[1])
+	}
+
+	var event Event
+	if err = json.Unmarshal([]byte(got[0][strings.Index(got[0], "{"):]), &event); err != nil {
+		t.Fatalf("msg is not json: %v", err)
+	}
+	if event.Id != 1 || event.Operator != "admin" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
+
+func TestSyslogSinkTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: srv.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := serveFrames(t, ln, 1)
+
+	opts := SinkOptions{Name: "syslog", Type: SinkSyslog, Syslog: SyslogOptions{
+		Network: "tls",
+		Address: ln.Addr().String(),
+		TLS:     TLSOptions{CAFile: caFile, ServerName: "example.com"},
+	}}
+	opts.SetDefaults()
+	if err = opts.Valid(); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := newSyslogSink(opts.Syslog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err = sink.Send(context.Background(), []Event{testEvent(1, "succeed")}); err != nil {
+		t.Fatalf("send over tls: %v", err)
+	}
+	if got := <-frames; len(got) != 1 || !strings.Contains(got[0], `"id":1`) {
+		t.Fatalf("unexpected frames: %v", got)
+	}
+}
+
+func TestSyslogSinkReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	opts := SinkOptions{Name: "syslog", Type: SinkSyslog, Syslog: SyslogOptions{Address: ln.Addr().String()}}
+	opts.SetDefaults()
+	sink, err := newSyslogSink(opts.Syslog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	frames := serveFrames(t, ln, 1)
+	if err = sink.Send(context.Background(), []Event{testEvent(1, "succeed")}); err != nil {
+		t.Fatal(err)
+	}
+	<-frames
+
+	// 对端关闭连接后，下一条事件应通过重连送达
+	frames = serveFrames(t, ln, 1)
+	time.Sleep(50 * time.Millisecond)
+	if err = sink.Send(context.Background(), []Event{testEvent(2, "succeed")}); err != nil {
+		t.Fatalf("send after reconnect: %v", err)
+	}
+	select {
+	case got := <-frames:
+		if len(got) != 1 || !strings.Contains(got[0], `"id":2`) {
+			t.Fatalf("unexpected frames after reconnect: %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for reconnect")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditsink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func newTestWebhook(t *testing.T, url string, retries int) *webhookSink {
+	t.Helper()
+	opts := SinkOptions{Name: "webhook", Type: SinkWebhook, Webhook: WebhookOptions{
+		URL:        url,
+		Headers:    map[string]string{"X-Token": "secret"},
+		MaxRetries: retries,
+	}}
+	opts.SetDefaults()
+	if err := opts.Valid(); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := newWebhookSink(opts.Webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.backoff = time.Millisecond
+	return sink
+}
+
+func TestWebhookSinkRetry(t *testing.T) {
+	var calls int32
+	var received []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	sink := newTestWebhook(t, srv.URL, 3)
+	if err := sink.Send(context.Background(), []Event{testEvent(1, "succeed"), testEvent(2, "failed")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if len(received) != 2 || received[0].Id != 1 || received[1].Id != 2 {
+		t.Fatalf("unexpected batch: %+v", received)
+	}
+}
+
+func TestWebhookSinkNoRetryOnClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := newTestWebhook(t, srv.URL, 3).Send(context.Background(), []Event{testEvent(1, "succeed")})
+	var se *statusError
+	if !errors.As(err, &se) || se.code != http.StatusBadRequest {
+		t.Fatalf("expected 400 status error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("4xx should not be retried, got %d attempts", calls)
+	}
+
+	// max_retries 小于 0 时不重试
+	calls = 0
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	if err = newTestWebhook(t, srv.URL, -1).Send(context.Background(), []Event{testEvent(1, "succeed")}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected single attempt, got %d", calls)
+	}
+}
+
+func TestDispatcherWebhookBatching(t *testing.T) {
+	batches := make(chan []Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []Event
+		_ = json.NewDecoder(r.Body).Decode(&events)
+		batches <- events
+	}))
+	defer srv.Close()
+
+	opts := Options{Sinks: []SinkOptions{{
+		Name:    "webhook",
+		Type:    SinkWebhook,
+		Filter:  FilterOptions{Statuses: []string{"failed"}},
+		Webhook: WebhookOptions{URL: srv.URL, BatchSize: 2},
+	}}}
+	opts.SetDefaults()
+	d, err := NewDispatcher(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 5; i++ {
+		a := testAudit(i)
+		if i == 3 {
+			a.Status = model.AuditOpSuccess // 被过滤
+		}
+		d.Publish(a)
+	}
+
+	select {
+	case batch := <-batches:
+		if len(batch) != 2 || batch[0].Id != 1 || batch[1].Id != 2 {
+			t.Fatalf("unexpected first batch: %+v", batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for first batch")
+	}
+
+	// 关闭时投递不足一批的剩余事件
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	d.Close(ctx)
+	if batch := <-batches; len(batch) != 2 || batch[0].Id != 4 || batch[1].Id != 5 {
+		t.Fatalf("unexpected final batch: %+v", batch)
+	}
+
+	stats := d.Stats()[0]
+	if stats.Sent != 4 || stats.Filtered != 1 || stats.Failed != 0 || stats.LastSentTime == nil {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
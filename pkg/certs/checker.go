@@ -0,0 +1,201 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/controller/alert/notify"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+// 单个节点的证书采集超时
+const inspectTimeout = time.Minute
+
+// Checker 通过 SSH 采集 master 节点证书并保存巡检结果，到期前发送告警
+type Checker struct {
+	opts    Options
+	factory db.ShareDaoFactory
+	notify  *notify.Manager
+}
+
+func NewChecker(opts Options, f db.ShareDaoFactory) *Checker {
+	return &Checker{opts: opts, factory: f, notify: notify.NewManager(f)}
+}
+
+// MasterNodes 返回部署计划中的 master 节点
+func MasterNodes(nodes []model.Node) []model.Node {
+	var masters []model.Node
+	for _, node := range nodes {
+		if strings.Contains(node.Role, model.MasterRole) {
+			masters = append(masters, node)
+		}
+	}
+	return masters
+}
+
+// Inspect 采集部署计划全部 master 节点的证书。部分节点失败时返回已采集的结果与错误
+func (c *Checker) Inspect(ctx context.Context, planId int64) ([]model.PlanCertificate, error) {
+	nodes, err := c.factory.Plan().ListNodes(ctx, planId)
+	if err != nil {
+		return nil, err
+	}
+	masters := MasterNodes(nodes)
+	if len(masters) == 0 {
+		return nil, fmt.Errorf("部署计划没有 master 节点")
+	}
+
+	var (
+		certs []model.PlanCertificate
+		errs  []string
+	)
+	for _, node := range masters {
+		objects, err := c.inspectNode(ctx, node)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", node.Name, err))
+			continue
+		}
+		certs = append(certs, objects...)
+	}
+	if len(errs) != 0 {
+		return certs, fmt.Errorf("采集证书失败: %s", strings.Join(errs, "; "))
+	}
+	return certs, nil
+}
+
+func (c *Checker) inspectNode(ctx context.Context, node model.Node) ([]model.PlanCertificate, error) {
+	var auth types.PlanNodeAuth
+	if err := auth.Unmarshal(node.Auth); err != nil {
+		return nil, fmt.Errorf("解析节点认证信息失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, inspectTimeout)
+	defer cancel()
+
+	var stdout, stderr strings.Builder
+	if err := sshutil.RunScript(ctx, node.Ip, &auth, collectScript, nil, &stdout, &stderr); err != nil {
+		return nil, fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	certs, err := Parse(node.Name, []byte(stdout.String()))
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("未找到 kubernetes 证书")
+	}
+	return certs, nil
+}
+
+// Refresh 采集证书并替换保存的巡检结果，全部节点失败时保留上次结果
+func (c *Checker) Refresh(ctx context.Context, planId int64) ([]model.PlanCertificate, error) {
+	certs, err := c.Inspect(ctx, planId)
+	if len(certs) == 0 {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range certs {
+		certs[i].CheckedAt = now
+	}
+	if dbErr := c.factory.Plan().Certificate().Replace(ctx, planId, certs); dbErr != nil {
+		return nil, dbErr
+	}
+	return certs, err
+}
+
+// RunAll 巡检所有已注册集群的部署计划，返回巡检的计划数量
+func (c *Checker) RunAll(ctx context.Context) (int, error) {
+	clusters, err := c.factory.Cluster().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	checked := 0
+	for _, cluster := range clusters {
+		if cluster.PlanId == 0 {
+			continue
+		}
+		plan, err := c.factory.Plan().Get(ctx, cluster.PlanId)
+		if err != nil {
+			klog.Warningf("failed to get plan(%d) of cluster(%s): %v", cluster.PlanId, cluster.Name, err)
+			continue
+		}
+		// agent 模式下控制面无法直连节点；运行中的计划可能正在续期证书或恢复 etcd
+		if plan.ExecMode == model.PlanExecModeAgent || plan.Status == model.RunningPlanStatus {
+			continue
+		}
+
+		certs, err := c.Refresh(ctx, plan.Id)
+		if err != nil {
+			klog.Errorf("failed to inspect plan(%d) certificates: %v", plan.Id, err)
+		}
+		checked++
+		c.alert(ctx, plan, certs)
+	}
+	return checked, nil
+}
+
+// alert 汇总即将到期的证书并发送通知
+func (c *Checker) alert(ctx context.Context, plan *model.Plan, certs []model.PlanCertificate) {
+	if len(c.opts.NotifyChannels) == 0 || len(certs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	level := types.CertificateOK
+	var expiring []model.PlanCertificate
+	for _, cert := range certs {
+		l := c.opts.Evaluate(cert.NotAfter, now)
+		if l == types.CertificateOK {
+			continue
+		}
+		level = WorseLevel(level, l)
+		expiring = append(expiring, cert)
+	}
+	if len(expiring) == 0 {
+		return
+	}
+	sort.Slice(expiring, func(i, j int) bool { return expiring[i].NotAfter.Before(expiring[j].NotAfter) })
+
+	severity := model.AlertSeverityWarning
+	if level != types.CertificateWarning {
+		severity = model.AlertSeverityCritical
+	}
+	title := fmt.Sprintf("部署计划 %s 证书即将到期", plan.Name)
+	if level == types.CertificateExpired {
+		title = fmt.Sprintf("部署计划 %s 证书已过期", plan.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "部署计划 %s 有 %d 个证书将在 %d 天内到期，请及时续期证书：\n", plan.Name, len(expiring), c.opts.WarnDays)
+	for _, cert := range expiring {
+		fmt.Fprintf(&b, "- %s %s 到期时间 %s（剩余 %d 天）\n",
+			cert.Node, cert.Name, cert.NotAfter.Local().Format("2006-01-02 15:04"), ResidualDays(cert.NotAfter, now))
+	}
+	if err := c.notify.EnqueueMessage(ctx, c.opts.NotifyChannels, title, b.String(), severity); err != nil {
+		klog.Errorf("failed to enqueue certificate notification for plan(%d): %v", plan.Id, err)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	pkiDir        = "/etc/kubernetes/pki"
+	kubeletPKIDir = "/var/lib/kubelet/pki"
+	sectionPrefix = "==> "
+)
+
+// collectScript 输出 master 节点上的证书，每个文件以 "==> <path>" 开头。
+// 只输出证书部分，kubeconfig 只取 client-certificate-data，私钥不会离开节点
+const collectScript = `for f in ` + pkiDir + `/*.crt ` + pkiDir + `/etcd/*.crt ` + kubeletPKIDir + `/kubelet.crt ` + kubeletPKIDir + `/kubelet-client-current.pem; do
+  [ -f "$f" ] || continue
+  echo "` + sectionPrefix + `$f"
+  sed -n '/-----BEGIN CERTIFICATE-----/,/-----END CERTIFICATE-----/p' "$f"
+done
+for f in /etc/kubernetes/admin.conf /etc/kubernetes/super-admin.conf /etc/kubernetes/controller-manager.conf /etc/kubernetes/scheduler.conf /etc/kubernetes/kubelet.conf; do
+  [ -f "$f" ] || continue
+  echo "` + sectionPrefix + `$f"
+  grep -E '^[[:space:]]*client-certificate-data:' "$f" | head -n1 | awk '{print $2}'
+done
+`
+
+// Parse 解析 collectScript 的输出，无法解析的段落（例如 kubeconfig 引用外部证书文件）会被忽略
+func Parse(node string, output []byte) ([]model.PlanCertificate, error) {
+	var (
+		certs   []model.PlanCertificate
+		current string
+		body    bytes.Buffer
+	)
+	flush := func() error {
+		defer body.Reset()
+		if current == "" || body.Len() == 0 {
+			return nil
+		}
+		cert, err := parseCertificate(body.Bytes())
+		if err != nil {
+			return fmt.Errorf("%s: %v", current, err)
+		}
+		if cert == nil {
+			return nil
+		}
+		name, kind := classify(current, cert)
+		certs = append(certs, model.PlanCertificate{
+			Node:     node,
+			Name:     name,
+			Kind:     kind,
+			Path:     current,
+			Subject:  cert.Subject.String(),
+			NotAfter: cert.NotAfter,
+		})
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, sectionPrefix) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			current = strings.TrimSpace(strings.TrimPrefix(line, sectionPrefix))
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// parseCertificate 解析 PEM 或 base64 编码的 PEM（kubeconfig 内嵌），证书链只取第一张
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("解码证书失败: %v", err)
+		}
+		data = decoded
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func classify(p string, cert *x509.Certificate) (string, model.CertificateKind) {
+	base := path.Base(p)
+	name := strings.TrimSuffix(strings.TrimSuffix(base, ".crt"), ".pem")
+	if strings.HasSuffix(base, ".conf") {
+		name = base
+	}
+	if strings.HasPrefix(p, pkiDir+"/etcd/") {
+		name = "etcd-" + name
+	}
+
+	switch {
+	case cert.IsCA:
+		return name, model.CertificateCA
+	case strings.HasPrefix(p, kubeletPKIDir+"/") || base == "kubelet.conf":
+		return name, model.CertificateKubelet
+	}
+	return name, model.CertificateControlPlane
+}
+
+// ResidualDays 距到期的剩余天数，已过期时为负数
+func ResidualDays(notAfter, now time.Time) int {
+	d := notAfter.Sub(now)
+	days := int(d / (24 * time.Hour))
+	if d < 0 && d%(24*time.Hour) != 0 {
+		days--
+	}
+	return days
+}
+
+// Evaluate 根据剩余有效期计算证书等级
+func (o *Options) Evaluate(notAfter, now time.Time) types.CertificateLevel {
+	if !notAfter.After(now) {
+		return types.CertificateExpired
+	}
+	days := ResidualDays(notAfter, now)
+	switch {
+	case days <= o.CriticalDays:
+		return types.CertificateCritical
+	case days <= o.WarnDays:
+		return types.CertificateWarning
+	}
+	return types.CertificateOK
+}
+
+var levelOrder = map[types.CertificateLevel]int{
+	types.CertificateOK:       0,
+	types.CertificateWarning:  1,
+	types.CertificateCritical: 2,
+	types.CertificateExpired:  3,
+}
+
+// WorseLevel 返回两个等级中更严重的一个
+func WorseLevel(a, b types.CertificateLevel) types.CertificateLevel {
+	if levelOrder[b] > levelOrder[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+func newCertPEM(t *testing.T, cn string, isCA bool, notAfter time.Time) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParse(t *testing.T) {
+	notAfter := time.Date(2027, 10, 19, 0, 0, 0, 0, time.UTC)
+	ca := newCertPEM(t, "kubernetes", true, notAfter.Add(9*365*24*time.Hour))
+	apiserver := newCertPEM(t, "kube-apiserver", false, notAfter)
+	admin := newCertPEM(t, "kubernetes-admin", false, notAfter)
+
+	var b strings.Builder
+	b.WriteString("==> /etc/kubernetes/pki/ca.crt\n" + ca)
+	b.WriteString("==> /etc/kubernetes/pki/apiserver.crt\n" + apiserver)
+	b.WriteString("==> /etc/kubernetes/pki/etcd/server.crt\n" + apiserver)
+	b.WriteString("==> /var/lib/kubelet/pki/kubelet-client-current.pem\n" + apiserver)
+	b.WriteString("==> /etc/kubernetes/admin.conf\n" + base64.StdEncoding.EncodeToString([]byte(admin)) + "\n")
+	// kubelet.conf 引用外部证书文件时没有内嵌证书
+	b.WriteString("==> /etc/kubernetes/kubelet.conf\n")
+
+	certs, err := Parse("master-1", []byte(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		kind model.CertificateKind
+	}{
+		{"ca", model.CertificateCA},
+		{"apiserver", model.CertificateControlPlane},
+		{"etcd-server", model.CertificateControlPlane},
+		{"kubelet-client-current", model.CertificateKubelet},
+		{"admin.conf", model.CertificateControlPlane},
+	}
+	if len(certs) != len(want) {
+		t.Fatalf("got %d certificates, want %d", len(certs), len(want))
+	}
+	for i, w := range want {
+		if certs[i].Name != w.name || certs[i].Kind != w.kind || certs[i].Node != "master-1" {
+			t.Errorf("certs[%d] = %s/%s/%s, want %s/%s", i, certs[i].Node, certs[i].Name, certs[i].Kind, w.name, w.kind)
+		}
+	}
+	if !certs[1].NotAfter.Equal(notAfter) {
+		t.Errorf("apiserver NotAfter = %s, want %s", certs[1].NotAfter, notAfter)
+	}
+	if certs[4].Subject != "CN=kubernetes-admin" {
+		t.Errorf("admin.conf subject = %q", certs[4].Subject)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	opts := Options{WarnDays: 30, CriticalDays: 7}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	cases := []struct {
+		notAfter time.Time
+		want     types.CertificateLevel
+	}{
+		{now.Add(90 * day), types.CertificateOK},
+		{now.Add(30*day + time.Hour), types.CertificateWarning},
+		{now.Add(7*day + time.Hour), types.CertificateCritical},
+		{now.Add(-time.Hour), types.CertificateExpired},
+	}
+	for _, c := range cases {
+		if got := opts.Evaluate(c.notAfter, now); got != c.want {
+			t.Errorf("Evaluate(%s) = %s, want %s", c.notAfter, got, c.want)
+		}
+	}
+
+	if got := ResidualDays(now.Add(-time.Hour), now); got != -1 {
+		t.Errorf("ResidualDays = %d, want -1", got)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultSchedule 每天凌晨巡检一次证书，告警按巡检周期重复发送
+	DefaultSchedule     = "0 3 * * *"
+	DefaultWarnDays     = 30
+	DefaultCriticalDays = 7
+)
+
+// Options 证书巡检与告警配置
+type Options struct {
+	Schedule string `yaml:"schedule"`
+	// 剩余天数不超过 WarnDays 时告警，不超过 CriticalDays 时升级为严重
+	WarnDays     int `yaml:"warn_days"`
+	CriticalDays int `yaml:"critical_days"`
+	// 告警渠道（alert_channels ID），为空则只巡检不告警
+	NotifyChannels []int64 `yaml:"notify_channels"`
+}
+
+func (o *Options) SetDefaults() {
+	if o.Schedule == "" {
+		o.Schedule = DefaultSchedule
+	}
+	if o.WarnDays == 0 {
+		o.WarnDays = DefaultWarnDays
+	}
+	if o.CriticalDays == 0 {
+		o.CriticalDays = DefaultCriticalDays
+	}
+}
+
+func (o *Options) Valid() error {
+	if _, err := cron.ParseStandard(o.Schedule); err != nil {
+		return fmt.Errorf("certificate.schedule 无效: %v", err)
+	}
+	if o.CriticalDays < 0 || o.WarnDays < o.CriticalDays {
+		return fmt.Errorf("certificate.warn_days 需不小于 critical_days")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import "fmt"
+
+// RenewScript 使用 kubeadm 续期全部控制面证书（含 kubeconfig 内嵌证书），兼容旧版本的 alpha 子命令
+const RenewScript = `set -e
+command -v kubeadm >/dev/null 2>&1 || { echo "未找到 kubeadm" >&2; exit 1; }
+if kubeadm certs --help >/dev/null 2>&1; then
+  kubeadm certs renew all
+else
+  kubeadm alpha certs renew all
+fi
+`
+
+// RestartControlPlaneScript 移出并移回静态 Pod 清单以重启控制面组件，使其加载新证书，
+// 随后重启 kubelet 并等待本机 kube-apiserver 就绪
+func RestartControlPlaneScript(apiPort int) string {
+	return fmt.Sprintf(`set -e
+MANIFESTS=/etc/kubernetes/manifests
+STAGE=/etc/kubernetes/pixiu-renew
+mkdir -p $STAGE
+for f in kube-apiserver.yaml kube-controller-manager.yaml kube-scheduler.yaml etcd.yaml; do
+  if [ -f $MANIFESTS/$f ]; then mv $MANIFESTS/$f $STAGE/$f; fi
+done
+sleep 20
+for f in etcd.yaml kube-apiserver.yaml kube-controller-manager.yaml kube-scheduler.yaml; do
+  if [ -f $STAGE/$f ]; then mv $STAGE/$f $MANIFESTS/$f; fi
+done
+systemctl restart kubelet
+for i in $(seq 1 150); do
+  if curl -sk https://127.0.0.1:%d/healthz | grep -q ok; then
+    echo "kube-apiserver 已就绪"
+    exit 0
+  fi
+  sleep 2
+done
+echo "等待 kube-apiserver 就绪超时" >&2
+exit 1
+`, apiPort)
+}
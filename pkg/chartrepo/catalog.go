@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// ErrSyncRunning 仓库正在同步
+var ErrSyncRunning = fmt.Errorf("repository is being synchronized")
+
+// 正在同步的仓库，避免定时任务与手动刷新并发同步同一仓库
+var syncing sync.Map
+
+// Indexer 定期拉取仓库的 chart 索引并缓存到数据库，浏览与搜索不再实时下载 index.yaml
+type Indexer struct {
+	cfg     CatalogOptions
+	factory db.ShareDaoFactory
+}
+
+func NewIndexer(cfg CatalogOptions, f db.ShareDaoFactory) *Indexer {
+	cfg.SetDefaults()
+	return &Indexer{cfg: cfg, factory: f}
+}
+
+// SyncAll 并发同步所有仓库，单个仓库失败只记录在仓库状态中
+func (i *Indexer) SyncAll(ctx context.Context) (synced int, failed int, err error) {
+	repositories, err := i.factory.Repository().List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		sem  = make(chan struct{}, i.cfg.Concurrency)
+	)
+	for _, repository := range repositories {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(repository *model.Repository) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := i.Sync(ctx, repository)
+			if err == ErrSyncRunning {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				failed++
+				return
+			}
+			synced++
+		}(repository)
+	}
+	wg.Wait()
+	return synced, failed, nil
+}
+
+// Sync 同步单个仓库的 chart 目录，失败时保留上一次的目录并记录错误
+func (i *Indexer) Sync(ctx context.Context, repository *model.Repository) error {
+	if _, running := syncing.LoadOrStore(repository.Id, struct{}{}); running {
+		return ErrSyncRunning
+	}
+	defer syncing.Delete(repository.Id)
+
+	// 状态回写使用外层 ctx，避免拉取超时后无法记录失败原因
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Duration(i.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	charts, err := i.fetch(fetchCtx, repository)
+	now := time.Now()
+	if err != nil {
+		klog.Warningf("failed to sync charts of repository %s: %v", repository.Name, err)
+		if updateErr := i.factory.Repository().UpdateSyncStatus(ctx, repository.Id, map[string]interface{}{
+			"sync_status":     model.RepositorySyncFailed,
+			"last_sync_time":  &now,
+			"last_sync_error": err.Error(),
+		}); updateErr != nil {
+			klog.Errorf("failed to update sync status of repository %s: %v", repository.Name, updateErr)
+		}
+		return err
+	}
+
+	if err = i.factory.Repository().Chart().Replace(ctx, repository.Id, charts); err != nil {
+		klog.Errorf("failed to save charts of repository %s: %v", repository.Name, err)
+		return err
+	}
+	return i.factory.Repository().UpdateSyncStatus(ctx, repository.Id, map[string]interface{}{
+		"sync_status":     model.RepositorySyncSucceeded,
+		"last_sync_time":  &now,
+		"last_sync_error": "",
+		"chart_count":     len(charts),
+	})
+}
+
+func (i *Indexer) fetch(ctx context.Context, repository *model.Repository) ([]model.HelmChart, error) {
+	source, err := NewSource(repository)
+	if err != nil {
+		return nil, err
+	}
+	index, err := source.Index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return BuildCatalog(repository, index)
+}
+
+// BuildCatalog 将仓库索引转换为 chart 目录，每个 chart 取最新版本的元数据
+func BuildCatalog(repository *model.Repository, index *model.ChartIndex) ([]model.HelmChart, error) {
+	charts := make([]model.HelmChart, 0, len(index.Entries))
+	for name, versions := range index.Entries {
+		if len(versions) == 0 {
+			continue
+		}
+		SortChartVersions(versions)
+		latest := latestStable(versions)
+
+		summaries := make([]model.HelmChartVersion, 0, len(versions))
+		for _, v := range versions {
+			summary := model.HelmChartVersion{
+				Version:    v.Version,
+				AppVersion: v.AppVersion,
+				Digest:     v.Digest,
+				URLs:       v.URLs,
+				Deprecated: v.Deprecated,
+			}
+			if !v.Created.IsZero() {
+				created := v.Created
+				summary.Created = &created
+			}
+			summaries = append(summaries, summary)
+		}
+		versionsData, err := json.Marshal(summaries)
+		if err != nil {
+			return nil, err
+		}
+		var dependencies string
+		if len(latest.Dependencies) != 0 {
+			data, err := json.Marshal(latest.Dependencies)
+			if err != nil {
+				return nil, err
+			}
+			dependencies = string(data)
+		}
+
+		chart := model.HelmChart{
+			RepositoryId:   repository.Id,
+			RepositoryName: repository.Name,
+			Name:           name,
+			LatestVersion:  latest.Version,
+			AppVersion:     latest.AppVersion,
+			Description:    latest.Description,
+			Icon:           latest.Icon,
+			Home:           latest.Home,
+			Keywords:       joinKeywords(latest.Keywords),
+			Deprecated:     latest.Deprecated,
+			Dependencies:   dependencies,
+			Versions:       string(versionsData),
+			VersionCount:   len(versions),
+		}
+		if !latest.Created.IsZero() {
+			created := latest.Created
+			chart.Created = &created
+		}
+		charts = append(charts, chart)
+	}
+	return charts, nil
+}
+
+// latestStable 与 helm search 一致，优先取最新的正式版本，没有正式版本时取最新的预发布版本
+func latestStable(sorted []model.ChartVersion) model.ChartVersion {
+	for _, v := range sorted {
+		if sv, err := semver.NewVersion(v.Version); err == nil && sv.Prerelease() == "" {
+			return v
+		}
+	}
+	return sorted[0]
+}
+
+// joinKeywords 关键字转小写并以逗号包裹，便于按 ,keyword, 精确匹配
+func joinKeywords(keywords []string) string {
+	var normalized []string
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && !strings.Contains(k, ",") {
+			normalized = append(normalized, k)
+		}
+	}
+	if len(normalized) == 0 {
+		return ""
+	}
+	return "," + strings.Join(normalized, ",") + ","
+}
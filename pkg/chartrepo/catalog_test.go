@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestBuildCatalog(t *testing.T) {
+	index := &model.ChartIndex{Entries: model.Entries{
+		"nginx": {
+			{Name: "nginx", Version: "1.2.0", Description: "old", Keywords: []string{"Web"}},
+			{Name: "nginx", Version: "1.10.0", AppVersion: "1.25", Description: "web server", Keywords: []string{"Web", " HTTP "}},
+			{Name: "nginx", Version: "2.0.0-rc.1"},
+		},
+		"empty": {},
+	}}
+
+	charts, err := BuildCatalog(&model.Repository{Name: "bitnami"}, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(charts) != 1 {
+		t.Fatalf("expected 1 chart, got %d", len(charts))
+	}
+	chart := charts[0]
+	if chart.LatestVersion != "1.10.0" || chart.AppVersion != "1.25" || chart.VersionCount != 3 || chart.RepositoryName != "bitnami" {
+		t.Errorf("unexpected chart: %+v", chart)
+	}
+
+	var versions []model.HelmChartVersion
+	if err = json.Unmarshal([]byte(chart.Versions), &versions); err != nil {
+		t.Fatal(err)
+	}
+	if versions[1].Version != "1.10.0" || versions[2].Version != "1.2.0" {
+		t.Errorf("versions should be sorted by semver descending: %+v", versions)
+	}
+	if joinKeywords([]string{"Web", " HTTP ", ""}) != ",web,http," {
+		t.Errorf("unexpected keywords: %q", joinKeywords([]string{"Web", " HTTP "}))
+	}
+}
@@ -0,0 +1,461 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// helm chart 在 OCI 仓库中的媒体类型
+const (
+	ociManifestMediaType   = "application/vnd.oci.image.manifest.v1+json"
+	helmConfigMediaType    = "application/vnd.cncf.helm.config.v1+json"
+	helmChartMediaType     = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	maxManifestSize        = 4 << 20
+	maxCatalogRepositories = 1000
+)
+
+// ociSource 通过 OCI Distribution API 访问 chart，兼容 Harbor 等仓库的 Bearer Token 认证。
+// 仓库地址形如 oci://harbor.example.com/library，chart 为其下的同名 repository
+type ociSource struct {
+	host     string
+	path     string
+	username string
+	password string
+	charts   []string
+	client   *http.Client
+
+	lock   sync.Mutex
+	tokens map[string]string // scope -> bearer token
+}
+
+func newOCISource(repository *model.Repository, client *http.Client) (*ociSource, error) {
+	u, err := url.Parse(repository.URL)
+	if err != nil {
+		return nil, err
+	}
+	s := &ociSource{
+		host:     u.Host,
+		path:     strings.Trim(u.Path, "/"),
+		username: repository.Username,
+		password: repository.Password,
+		client:   client,
+		tokens:   make(map[string]string),
+	}
+	for _, name := range strings.Split(repository.Charts, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			s.charts = append(s.charts, name)
+		}
+	}
+	return s, nil
+}
+
+// repository 返回 chart 在镜像仓库中的 repository 名称
+func (s *ociSource) repository(name string) string {
+	if s.path == "" {
+		return name
+	}
+	return s.path + "/" + name
+}
+
+// chartRef 返回 chart 的 oci:// 地址，helm 会将版本中的 + 替换为 _ 作为 tag
+func (s *ociSource) chartRef(name, version string) string {
+	return fmt.Sprintf("oci://%s/%s:%s", s.host, s.repository(name), strings.ReplaceAll(version, "+", "_"))
+}
+
+func (s *ociSource) Index(ctx context.Context) (*model.ChartIndex, error) {
+	names, err := s.listCharts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &model.ChartIndex{APIVersion: "v1", Entries: model.Entries{}}
+	for _, name := range names {
+		tags, err := s.listTags(ctx, s.repository(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of chart %s: %v", name, err)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		versions := make([]model.ChartVersion, 0, len(tags))
+		for _, tag := range tags {
+			version := strings.ReplaceAll(tag, "_", "+")
+			versions = append(versions, model.ChartVersion{
+				Name:    name,
+				Version: version,
+				URLs:    []string{s.chartRef(name, version)},
+			})
+		}
+		SortChartVersions(versions)
+		// 逐个版本读取元数据开销较大，只补全最新版本的描述信息
+		if meta, err := s.chartMetadata(ctx, name, versions[0].Version); err == nil {
+			meta.URLs = versions[0].URLs
+			versions[0] = *meta
+		} else {
+			klog.Warningf("failed to get metadata of chart %s: %v", name, err)
+		}
+		index.Entries[name] = versions
+	}
+	return index, nil
+}
+
+// listCharts 优先使用仓库配置的 chart 列表，否则通过 _catalog 查找仓库路径下的 chart
+func (s *ociSource) listCharts(ctx context.Context) ([]string, error) {
+	if len(s.charts) != 0 {
+		return s.charts, nil
+	}
+
+	var catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := s.getJSON(ctx, fmt.Sprintf("/v2/_catalog?n=%d", maxCatalogRepositories), "", "registry:catalog:*", &catalog); err != nil {
+		return nil, fmt.Errorf("failed to list registry catalog, configure the chart names of the repository instead: %v", err)
+	}
+	prefix := ""
+	if s.path != "" {
+		prefix = s.path + "/"
+	}
+	var names []string
+	for _, repo := range catalog.Repositories {
+		if !strings.HasPrefix(repo, prefix) {
+			continue
+		}
+		if name := strings.TrimPrefix(repo, prefix); name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (s *ociSource) listTags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	next := fmt.Sprintf("/v2/%s/tags/list", repository)
+	for next != "" {
+		resp, err := s.do(ctx, next, "", pullScope(repository))
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		err = decodeJSON(resp, &list)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, list.Tags...)
+		next = nextLink(link)
+	}
+	return tags, nil
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	Config ociDescriptor   `json:"config"`
+	Layers []ociDescriptor `json:"layers"`
+}
+
+func (s *ociSource) manifest(ctx context.Context, name, version string) (*ociManifest, error) {
+	repository := s.repository(name)
+	var m ociManifest
+	tag := strings.ReplaceAll(version, "+", "_")
+	if err := s.getJSON(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, tag), ociManifestMediaType, pullScope(repository), &m); err != nil {
+		return nil, fmt.Errorf("failed to get manifest of chart %s:%s: %v", name, version, err)
+	}
+	if m.Config.MediaType != helmConfigMediaType {
+		return nil, fmt.Errorf("%s:%s is not a helm chart", name, version)
+	}
+	return &m, nil
+}
+
+// chartMetadata 读取 chart 的 config（即 Chart.yaml 的 JSON 形式）
+func (s *ociSource) chartMetadata(ctx context.Context, name, version string) (*model.ChartVersion, error) {
+	m, err := s.manifest(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.blob(ctx, s.repository(name), m.Config, maxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	var cv model.ChartVersion
+	if err = json.Unmarshal(data, &cv); err != nil {
+		return nil, fmt.Errorf("invalid chart config: %v", err)
+	}
+	cv.Digest = m.Config.Digest
+	return &cv, nil
+}
+
+func (s *ociSource) Pull(ctx context.Context, name, version string) ([]byte, error) {
+	if version == "" {
+		tags, err := s.listTags(ctx, s.repository(name))
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("chart %q not found in repository", name)
+		}
+		versions := make([]model.ChartVersion, 0, len(tags))
+		for _, tag := range tags {
+			versions = append(versions, model.ChartVersion{Version: strings.ReplaceAll(tag, "_", "+")})
+		}
+		SortChartVersions(versions)
+		version = versions[0].Version
+	}
+
+	m, err := s.manifest(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range m.Layers {
+		if layer.MediaType == helmChartMediaType {
+			return s.blob(ctx, s.repository(name), layer, maxChartSize)
+		}
+	}
+	return nil, fmt.Errorf("chart %s:%s has no chart content layer", name, version)
+}
+
+// blob 下载并校验 blob 摘要
+func (s *ociSource) blob(ctx context.Context, repository string, desc ociDescriptor, limit int64) ([]byte, error) {
+	if desc.Size > limit {
+		return nil, fmt.Errorf("blob %s exceeds size limit %d", desc.Digest, limit)
+	}
+	resp, err := s.do(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repository, desc.Digest), "", pullScope(repository))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := readLimited(resp.Body, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, expected, ok := strings.Cut(desc.Digest, ":")
+	if !ok || algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported digest %q", desc.Digest)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("digest mismatch for blob %s", desc.Digest)
+	}
+	return data, nil
+}
+
+func (s *ociSource) getJSON(ctx context.Context, path, accept, scope string, v interface{}) error {
+	resp, err := s.do(ctx, path, accept, scope)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeJSON(resp, v)
+}
+
+// do 发送 GET 请求，收到 401 时按 WWW-Authenticate 完成 Basic 或 Bearer Token 认证后重试一次
+func (s *ociSource) do(ctx context.Context, path, accept, scope string) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+s.host+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return s.client.Do(req)
+	}
+
+	resp, err := send(s.cachedAuthorization(scope))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		authorization, err := s.authorize(ctx, challenge, scope)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = send(authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+func (s *ociSource) cachedAuthorization(scope string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if token, ok := s.tokens[scope]; ok {
+		return "Bearer " + token
+	}
+	return ""
+}
+
+func (s *ociSource) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if s.username == "" {
+			return "", fmt.Errorf("registry %s requires authentication", s.host)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(s.username, s.password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported registry auth challenge %q", challenge)
+	}
+
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry auth challenge without realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", err
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get registry token: %s", resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = decodeJSON(resp, &token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("registry returned empty token")
+	}
+
+	s.lock.Lock()
+	s.tokens[scope] = token.Token
+	s.lock.Unlock()
+	return "Bearer " + token.Token, nil
+}
+
+func pullScope(repository string) string {
+	return fmt.Sprintf("repository:%s:pull", repository)
+}
+
+// parseChallenge 解析 WWW-Authenticate，例如 Bearer realm="https://x/service/token",service="harbor-registry"
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+	for rest != "" {
+		var pair string
+		// 值中可能包含逗号（例如多个 scope），按引号边界切分
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = strings.Trim(value, `"`)
+				break
+			}
+			pair, rest = value[1:end+1], value[end+2:]
+		} else {
+			pair, rest, _ = strings.Cut(value, ",")
+		}
+		params[key] = pair
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return scheme, params
+}
+
+// nextLink 解析分页 Link 头，例如 </v2/x/tags/list?last=y&n=100>; rel="next"
+func nextLink(link string) string {
+	if link == "" || !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
+
+func decodeJSON(resp *http.Response, v interface{}) error {
+	data, err := readLimited(resp.Body, maxManifestSize)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid registry response: %v", err)
+	}
+	return nil
+}
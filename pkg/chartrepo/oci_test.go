@@ -0,0 +1,167 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func chartArchive(t *testing.T, name, version string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		name + "/Chart.yaml":  fmt.Sprintf("apiVersion: v2\nname: %s\nversion: %s\n", name, version),
+		name + "/values.yaml": "replicas: 1\n",
+	}
+	for file, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newFakeRegistry 模拟 Harbor：未携带 token 时返回 Bearer 挑战，token 需通过 Basic 认证获取
+func newFakeRegistry(t *testing.T, chartData []byte) *httptest.Server {
+	config := []byte(`{"name":"nginx","version":"1.2.0","description":"web server","appVersion":"1.25"}`)
+	manifest, _ := json.Marshal(ociManifest{
+		Config: ociDescriptor{MediaType: helmConfigMediaType, Digest: digest(config), Size: int64(len(config))},
+		Layers: []ociDescriptor{{MediaType: helmChartMediaType, Digest: digest(chartData), Size: int64(len(chartData))}},
+	})
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/service/token" {
+			if u, p, ok := r.BasicAuth(); !ok || u != "admin" || p != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":"token-%s"}`, r.URL.Query().Get("scope"))
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/service/token",service="harbor-registry"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/_catalog":
+			fmt.Fprint(w, `{"repositories":["library/nginx","library/sub/ignored","other/redis"]}`)
+		case "/v2/library/nginx/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/library/nginx/tags/list?last=1.0.0&n=1>; rel="next"`)
+				fmt.Fprint(w, `{"tags":["1.0.0"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"tags":["1.2.0"]}`)
+		case "/v2/library/nginx/manifests/1.2.0":
+			w.Write(manifest)
+		case "/v2/library/nginx/blobs/" + digest(config):
+			w.Write(config)
+		case "/v2/library/nginx/blobs/" + digest(chartData):
+			w.Write(chartData)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv
+}
+
+func TestOCISource(t *testing.T) {
+	chartData := chartArchive(t, "nginx", "1.2.0")
+	srv := newFakeRegistry(t, chartData)
+	defer srv.Close()
+
+	repository := &model.Repository{
+		URL:                   "oci://" + strings.TrimPrefix(srv.URL, "https://") + "/library",
+		Username:              "admin",
+		Password:              "secret",
+		InsecureSkipTLSVerify: true,
+	}
+	source, err := NewSource(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := source.Index(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := index.Entries["nginx"]
+	if len(index.Entries) != 1 || len(versions) != 2 {
+		t.Fatalf("unexpected index entries: %+v", index.Entries)
+	}
+	if versions[0].Version != "1.2.0" || versions[0].AppVersion != "1.25" || versions[0].Description != "web server" {
+		t.Errorf("unexpected latest version: %+v", versions[0])
+	}
+
+	ch, err := LoadChart(context.Background(), source, "nginx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Metadata.Version != "1.2.0" {
+		t.Errorf("expected version 1.2.0, got %s", ch.Metadata.Version)
+	}
+
+	repository.Password = "wrong"
+	source, _ = NewSource(repository)
+	if _, err = source.Index(context.Background()); err == nil {
+		t.Error("expected error with wrong password")
+	}
+}
+
+func TestParseOCIChartRef(t *testing.T) {
+	cases := []struct {
+		ref, repoURL, name, version string
+	}{
+		{"oci://harbor.example.com/library/nginx:1.0.0_build.1", "oci://harbor.example.com/library", "nginx", "1.0.0+build.1"},
+		{"oci://localhost:5000/nginx", "oci://localhost:5000", "nginx", ""},
+	}
+	for _, c := range cases {
+		repoURL, name, version, err := ParseOCIChartRef(c.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repoURL != c.repoURL || name != c.name || version != c.version {
+			t.Errorf("ParseOCIChartRef(%q) = %q, %q, %q", c.ref, repoURL, name, version)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartrepo
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultCatalogSchedule 每 30 分钟刷新一次所有仓库的 chart 目录
+	DefaultCatalogSchedule    = "*/30 * * * *"
+	DefaultCatalogConcurrency = 4
+	DefaultCatalogTimeout     = 300
+)
+
+// CatalogOptions chart 目录后台索引配置
+type CatalogOptions struct {
+	Schedule string `yaml:"schedule"`
+	// 同时同步的仓库数
+	Concurrency int `yaml:"concurrency"`
+	// 单个仓库同步超时时间（秒）
+	Timeout int `yaml:"timeout"`
+}
+
+func (o *CatalogOptions) SetDefaults() {
+	if o.Schedule == "" {
+		o.Schedule = DefaultCatalogSchedule
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultCatalogConcurrency
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultCatalogTimeout
+	}
+}
+
+func (o *CatalogOptions) Valid() error {
+	if _, err := cron.ParseStandard(o.Schedule); err != nil {
+		return fmt.Errorf("chart_catalog.schedule 无效: %v", err)
+	}
+	return nil
+}
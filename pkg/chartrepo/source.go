@@ -0,0 +1,287 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chartrepo 访问 Helm chart 仓库，支持 index.yaml 仓库与 OCI 镜像仓库
+package chartrepo
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+const (
+	sourceTimeout = 60 * time.Second
+
+	// 与 helm 保持一致的大小限制，避免异常仓库耗尽内存
+	maxIndexSize = 64 << 20
+	maxChartSize = 20 << 20
+)
+
+// Source chart 仓库的统一访问方式，屏蔽 index.yaml 仓库与 OCI 仓库的差异
+type Source interface {
+	// Index 返回仓库中的 chart 及版本
+	Index(ctx context.Context) (*model.ChartIndex, error)
+	// Pull 下载指定版本的 chart 包（.tgz）
+	Pull(ctx context.Context, name, version string) ([]byte, error)
+}
+
+// DetectType 根据 URL 协议判断仓库类型
+func DetectType(repoURL string) (model.RepositoryType, error) {
+	u, err := url.Parse(strings.TrimSpace(repoURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid repository url %q: %v", repoURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return model.RepositoryHTTP, nil
+	case "oci":
+		if u.Host == "" {
+			return "", fmt.Errorf("invalid oci repository url %q", repoURL)
+		}
+		return model.RepositoryOCI, nil
+	}
+	return "", fmt.Errorf("unsupported repository url %q, only http(s):// and oci:// are supported", repoURL)
+}
+
+// NewSource 根据仓库配置创建访问方式，凭证与 TLS 配置对浏览和安装同时生效
+func NewSource(repository *model.Repository) (Source, error) {
+	t, err := DetectType(repository.URL)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewHTTPClient(repository.CAData, repository.InsecureSkipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	if t == model.RepositoryOCI {
+		return newOCISource(repository, client)
+	}
+	return &httpSource{
+		url:      strings.TrimSuffix(repository.URL, "/"),
+		username: repository.Username,
+		password: repository.Password,
+		client:   client,
+	}, nil
+}
+
+// NewHTTPClient 创建访问仓库的 HTTP 客户端，caData 为 PEM 格式的自定义 CA
+func NewHTTPClient(caData string, insecure bool) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if strings.TrimSpace(caData) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caData)) {
+			return nil, fmt.Errorf("invalid repository ca data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: sourceTimeout}, nil
+}
+
+// readLimited 读取响应体，超过 limit 时报错
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds size limit %d", limit)
+	}
+	return data, nil
+}
+
+// httpSource 传统 index.yaml 仓库
+type httpSource struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+func (s *httpSource) get(ctx context.Context, target string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	// 与 helm 一致，只向仓库同源地址发送凭证
+	if s.username != "" && sameOrigin(s.url, target) {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", target, resp.Status)
+	}
+	return readLimited(resp.Body, limit)
+}
+
+func (s *httpSource) Index(ctx context.Context) (*model.ChartIndex, error) {
+	indexURL, err := resolveReferenceURL(s.url, "index.yaml")
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.get(ctx, indexURL, maxIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	var index model.ChartIndex
+	if err = yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid index.yaml: %v", err)
+	}
+	return &index, nil
+}
+
+func (s *httpSource) Pull(ctx context.Context, name, version string) ([]byte, error) {
+	index, err := s.Index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cv, err := findChartVersion(index, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s-%s has no download url", name, cv.Version)
+	}
+	chartURL, err := resolveReferenceURL(s.url, cv.URLs[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, chartURL, maxChartSize)
+}
+
+// findChartVersion 在 index 中查找 chart 版本，version 为空时返回最新版本
+func findChartVersion(index *model.ChartIndex, name, version string) (*model.ChartVersion, error) {
+	versions := index.Entries[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("chart %q not found in repository", name)
+	}
+	if version == "" {
+		sorted := append([]model.ChartVersion(nil), versions...)
+		SortChartVersions(sorted)
+		return &sorted[0], nil
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("chart %q version %q not found in repository", name, version)
+}
+
+// SortChartVersions 按语义化版本倒序，无法解析的版本排在最后
+func SortChartVersions(versions []model.ChartVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, ei := semver.NewVersion(versions[i].Version)
+		vj, ej := semver.NewVersion(versions[j].Version)
+		if ei != nil || ej != nil {
+			return ei == nil
+		}
+		return vi.GreaterThan(vj)
+	})
+}
+
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && ua.Host == ub.Host
+}
+
+func resolveReferenceURL(baseURL, refURL string) (string, error) {
+	parsedRefURL, err := url.Parse(refURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s as URL, %v", refURL, err)
+	}
+
+	if parsedRefURL.IsAbs() {
+		return refURL, nil
+	}
+
+	parsedBaseURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s as URL, %v", baseURL, err)
+	}
+
+	parsedBaseURL.RawPath = strings.TrimSuffix(parsedBaseURL.RawPath, "/") + "/"
+	parsedBaseURL.Path = strings.TrimSuffix(parsedBaseURL.Path, "/") + "/"
+
+	resolvedURL := parsedBaseURL.ResolveReference(parsedRefURL)
+	resolvedURL.RawQuery = parsedBaseURL.RawQuery
+	return resolvedURL.String(), nil
+}
+
+// LoadChart 下载并加载 chart，校验 chart 类型可安装
+func LoadChart(ctx context.Context, source Source, name, version string) (*chart.Chart, error) {
+	data, err := source.Pull(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %v", name, err)
+	}
+	return ch, nil
+}
+
+// ParseOCIChartRef 解析 oci://host/path/name:tag 形式的 chart 地址
+func ParseOCIChartRef(ref string) (repoURL, name, version string, err error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "oci" || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid oci chart reference %q", ref)
+	}
+	p := strings.Trim(u.Path, "/")
+	if i := strings.LastIndex(p, ":"); i > strings.LastIndex(p, "/") {
+		p, version = p[:i], strings.ReplaceAll(p[i+1:], "_", "+")
+	}
+	dir, name := "", p
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		dir, name = p[:i], p[i+1:]
+	}
+	if name == "" {
+		return "", "", "", fmt.Errorf("invalid oci chart reference %q", ref)
+	}
+	return strings.TrimSuffix("oci://"+u.Host+"/"+dir, "/"), name, version, nil
+}
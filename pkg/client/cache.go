@@ -180,6 +180,29 @@ func (s *Cache) List() store {
 	return s.store
 }
 
+// InformerCacheSizes 统计各集群已启动 informer 缓存中的对象数：集群 -> 资源 -> 数量
+func (s *Cache) InformerCacheSizes() map[string]map[string]int {
+	s.RLock()
+	defer s.RUnlock()
+
+	sizes := make(map[string]map[string]int)
+	for name, cs := range s.store {
+		if cs.Informer == nil || cs.Informer.Shared == nil {
+			continue
+		}
+		resources := make(map[string]int)
+		for _, gvr := range groupVersionResources {
+			informer, err := cs.Informer.Shared.ForResource(gvr)
+			if err != nil {
+				continue
+			}
+			resources[gvr.Resource] = len(informer.Informer().GetStore().ListKeys())
+		}
+		sizes[name] = resources
+	}
+	return sizes
+}
+
 func (s *Cache) Clear() {
 	s.Lock()
 	defer s.Unlock()
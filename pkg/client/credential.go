@@ -0,0 +1,243 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// PixiuServiceAccountNamespace / PixiuServiceAccountName 凭据轮换时 Pixiu 在目标集群使用的 ServiceAccount
+	PixiuServiceAccountNamespace = "pixiu-system"
+	PixiuServiceAccountName      = "pixiu-admin"
+
+	pixiuServiceAccountUser = "pixiu-admin"
+)
+
+// kubeconfig 凭据类型
+const (
+	CredentialClientCertificate   = "client-certificate"
+	CredentialServiceAccountToken = "serviceaccount-token"
+	CredentialPixiuServiceAccount = "pixiu-serviceaccount-token" // 由 Pixiu 轮换签发的 token
+	CredentialBearerToken         = "bearer-token"
+	CredentialBasicAuth           = "basic"
+	CredentialExecPlugin          = "exec"
+	CredentialAuthProvider        = "auth-provider"
+	CredentialUnknown             = "unknown"
+)
+
+const (
+	pixiuServiceAccountSubject  = "system:serviceaccount:" + PixiuServiceAccountNamespace + ":" + PixiuServiceAccountName
+	serviceAccountSubjectPrefix = "system:serviceaccount:"
+	legacyServiceAccountIssuer  = "kubernetes/serviceaccount"
+)
+
+// CredentialInfo kubeconfig 当前上下文使用的凭据信息
+type CredentialInfo struct {
+	Type string
+	// ExpiresAt 凭据过期时间，nil 表示无法获知或永不过期（如旧式 ServiceAccount Secret token）
+	ExpiresAt *time.Time
+	Subject   string
+}
+
+// ParseCredential 解析 kubeconfig（base64）当前上下文的凭据类型与过期时间。
+// 客户端证书读取 NotAfter，JWT token 读取 exp（不校验签名，仅用于展示与告警）。
+func ParseCredential(kubeConfig string) (*CredentialInfo, error) {
+	data, err := ParseKubeConfigBytes(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	kctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context %q not found", cfg.CurrentContext)
+	}
+	authInfo, ok := cfg.AuthInfos[kctx.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("user %q not found", kctx.AuthInfo)
+	}
+
+	info := &CredentialInfo{Type: CredentialUnknown}
+	switch {
+	case authInfo.Exec != nil:
+		info.Type = CredentialExecPlugin
+	case authInfo.AuthProvider != nil:
+		info.Type = CredentialAuthProvider
+	case len(authInfo.ClientCertificateData) != 0:
+		info.Type = CredentialClientCertificate
+		cert, err := parseCertificate(authInfo.ClientCertificateData)
+		if err != nil {
+			return nil, err
+		}
+		notAfter := cert.NotAfter
+		info.ExpiresAt = &notAfter
+		info.Subject = cert.Subject.CommonName
+	case authInfo.Token != "":
+		info.Type = CredentialBearerToken
+		claims, ok := parseJWTClaims(authInfo.Token)
+		if !ok {
+			break
+		}
+		info.Subject = claims.Subject
+		if claims.Expiry > 0 {
+			exp := time.Unix(claims.Expiry, 0)
+			info.ExpiresAt = &exp
+		}
+		switch {
+		case claims.Subject == pixiuServiceAccountSubject:
+			info.Type = CredentialPixiuServiceAccount
+		case strings.HasPrefix(claims.Subject, serviceAccountSubjectPrefix) || claims.Issuer == legacyServiceAccountIssuer:
+			info.Type = CredentialServiceAccountToken
+		}
+	case authInfo.Username != "":
+		info.Type = CredentialBasicAuth
+	}
+	return info, nil
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("client certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+type jwtClaims struct {
+	Issuer  string `json:"iss"`
+	Subject string `json:"sub"`
+	Expiry  int64  `json:"exp"`
+}
+
+func parseJWTClaims(token string) (*jwtClaims, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+	var claims jwtClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, false
+	}
+	return &claims, true
+}
+
+// IssueServiceAccountKubeconfig 在目标集群确保 Pixiu ServiceAccount 及 cluster-admin 绑定存在，
+// 通过 TokenRequest 签发有效期为 expiration 的 token，并基于 kubeConfig（base64）的当前集群信息
+// 生成新的 kubeconfig（base64）。调用方需先确保 pixiu-system 命名空间存在。
+func IssueServiceAccountKubeconfig(ctx context.Context, cs kubernetes.Interface, kubeConfig string, expiration time.Duration) (string, *CredentialInfo, error) {
+	data, err := ParseKubeConfigBytes(kubeConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return "", nil, err
+	}
+	kctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return "", nil, fmt.Errorf("current context %q not found", cfg.CurrentContext)
+	}
+	cluster, ok := cfg.Clusters[kctx.Cluster]
+	if !ok {
+		return "", nil, fmt.Errorf("cluster %q not found", kctx.Cluster)
+	}
+
+	if err = ensurePixiuServiceAccount(ctx, cs); err != nil {
+		return "", nil, err
+	}
+
+	seconds := int64(expiration.Seconds())
+	tr, err := cs.CoreV1().ServiceAccounts(PixiuServiceAccountNamespace).CreateToken(ctx, PixiuServiceAccountName,
+		&authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds}},
+		metav1.CreateOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to request token for %s/%s: %v", PixiuServiceAccountNamespace, PixiuServiceAccountName, err)
+	}
+
+	out := clientcmdapi.NewConfig()
+	out.Clusters[kctx.Cluster] = cluster
+	out.AuthInfos[pixiuServiceAccountUser] = &clientcmdapi.AuthInfo{Token: tr.Status.Token}
+	out.Contexts[cfg.CurrentContext] = &clientcmdapi.Context{
+		Cluster:   kctx.Cluster,
+		AuthInfo:  pixiuServiceAccountUser,
+		Namespace: kctx.Namespace,
+	}
+	out.CurrentContext = cfg.CurrentContext
+	newData, err := clientcmd.Write(*out)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// apiserver 可能按 --service-account-max-token-expiration 缩短有效期，以实际返回为准
+	expiresAt := tr.Status.ExpirationTimestamp.Time
+	return base64.StdEncoding.EncodeToString(newData), &CredentialInfo{
+		Type:      CredentialPixiuServiceAccount,
+		ExpiresAt: &expiresAt,
+		Subject:   pixiuServiceAccountSubject,
+	}, nil
+}
+
+func ensurePixiuServiceAccount(ctx context.Context, cs kubernetes.Interface) error {
+	labels := map[string]string{"maintainer": "pixiu"}
+	_, err := cs.CoreV1().ServiceAccounts(PixiuServiceAccountNamespace).Create(ctx, &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: PixiuServiceAccountName, Labels: labels},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service account %s/%s: %v", PixiuServiceAccountNamespace, PixiuServiceAccountName, err)
+	}
+
+	_, err = cs.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: PixiuServiceAccountName, Labels: labels},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "cluster-admin",
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      PixiuServiceAccountName,
+			Namespace: PixiuServiceAccountNamespace,
+		}},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create cluster role binding %s: %v", PixiuServiceAccountName, err)
+	}
+	return nil
+}
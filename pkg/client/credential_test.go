@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testKubeConfig 生成仅包含一个上下文的 kubeconfig（base64）
+func testKubeConfig(t *testing.T, authInfo *clientcmdapi.AuthInfo) string {
+	t.Helper()
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["c"] = &clientcmdapi.Cluster{Server: "https://10.0.0.1:6443"}
+	cfg.AuthInfos["u"] = authInfo
+	cfg.Contexts["ctx"] = &clientcmdapi.Context{Cluster: "c", AuthInfo: "u"}
+	cfg.CurrentContext = "ctx"
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func testJWT(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".c2lnbmF0dXJl"
+}
+
+func testClientCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kubernetes-admin"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseCredential(t *testing.T) {
+	expiry := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		authInfo    *clientcmdapi.AuthInfo
+		wantType    string
+		wantExpiry  *time.Time
+		wantSubject string
+	}{
+		{
+			name:        "client certificate",
+			authInfo:    &clientcmdapi.AuthInfo{ClientCertificateData: testClientCertificate(t, expiry)},
+			wantType:    CredentialClientCertificate,
+			wantExpiry:  &expiry,
+			wantSubject: "kubernetes-admin",
+		},
+		{
+			name: "pixiu service account token",
+			authInfo: &clientcmdapi.AuthInfo{Token: testJWT(t, map[string]interface{}{
+				"sub": pixiuServiceAccountSubject, "exp": expiry.Unix(),
+			})},
+			wantType:    CredentialPixiuServiceAccount,
+			wantExpiry:  &expiry,
+			wantSubject: pixiuServiceAccountSubject,
+		},
+		{
+			name: "bound service account token",
+			authInfo: &clientcmdapi.AuthInfo{Token: testJWT(t, map[string]interface{}{
+				"sub": "system:serviceaccount:kube-system:admin", "exp": expiry.Unix(),
+			})},
+			wantType:    CredentialServiceAccountToken,
+			wantExpiry:  &expiry,
+			wantSubject: "system:serviceaccount:kube-system:admin",
+		},
+		{
+			name: "legacy secret token never expires",
+			authInfo: &clientcmdapi.AuthInfo{Token: testJWT(t, map[string]interface{}{
+				"iss": legacyServiceAccountIssuer,
+			})},
+			wantType: CredentialServiceAccountToken,
+		},
+		{
+			name:     "opaque bearer token",
+			authInfo: &clientcmdapi.AuthInfo{Token: "abcdef.0123456789abcdef"},
+			wantType: CredentialBearerToken,
+		},
+		{
+			name:     "basic auth",
+			authInfo: &clientcmdapi.AuthInfo{Username: "admin", Password: "secret"},
+			wantType: CredentialBasicAuth,
+		},
+		{
+			name:     "exec plugin",
+			authInfo: &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws", APIVersion: "client.authentication.k8s.io/v1beta1"}},
+			wantType: CredentialExecPlugin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseCredential(testKubeConfig(t, tt.authInfo))
+			if err != nil {
+				t.Fatalf("ParseCredential() error = %v", err)
+			}
+			if info.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", info.Type, tt.wantType)
+			}
+			if info.Subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", info.Subject, tt.wantSubject)
+			}
+			switch {
+			case tt.wantExpiry == nil && info.ExpiresAt != nil:
+				t.Errorf("expires at = %v, want nil", info.ExpiresAt)
+			case tt.wantExpiry != nil && (info.ExpiresAt == nil || !info.ExpiresAt.Equal(*tt.wantExpiry)):
+				t.Errorf("expires at = %v, want %v", info.ExpiresAt, tt.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestParseCredentialInvalid(t *testing.T) {
+	if _, err := ParseCredential("not base64!"); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+
+	cfg := clientcmdapi.NewConfig()
+	cfg.CurrentContext = "missing"
+	data, _ := clientcmd.Write(*cfg)
+	if _, err := ParseCredential(base64.StdEncoding.EncodeToString(data)); err == nil {
+		t.Error("expected error for missing current context")
+	}
+
+	kubeConfig := testKubeConfig(t, &clientcmdapi.AuthInfo{ClientCertificateData: []byte("not a pem")})
+	if _, err := ParseCredential(kubeConfig); err == nil {
+		t.Error("expected error for invalid client certificate")
+	}
+}
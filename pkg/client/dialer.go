@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/tunnel"
 )
 
@@ -103,6 +104,7 @@ func (cs *ClusterSet) CompleteWithOptions(cfg []byte, opts ClusterSetOptions) er
 // wrapTransport 为 client-go 传输层补齐显式超时。
 // rt 断言为 *http.Transport 并 Clone 后修改，避免污染 client-go tlsCache 共享的实例。
 // 隧道模式（dial != nil）由 remotedialer 注入 DialContext，这里只对直连设置拨号超时。
+// 开启链路追踪时再包一层 otelhttp，为每个 apiserver 请求生成 client span。
 func wrapTransport(dial DialContextFunc) func(rt http.RoundTripper) http.RoundTripper {
 	return func(rt http.RoundTripper) http.RoundTripper {
 		t, ok := rt.(*http.Transport)
@@ -125,6 +127,6 @@ func wrapTransport(dial DialContextFunc) func(rt http.RoundTripper) http.RoundTr
 				KeepAlive: 30 * time.Second,
 			}).DialContext
 		}
-		return t
+		return tracing.NewTransport(t)
 	}
 }
@@ -20,8 +20,10 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -37,6 +39,9 @@ type Agent struct {
 	Server   string
 	Token    string
 	Insecure bool
+	// AllowedTargets 本地允许名单（svc.ns:port，或 kubeconfig 中集群外的 apiserver 地址 host:port），
+	// 为空时放行所有 TCP 拨号；非空时仅放行集群内 kube-apiserver 与名单内的地址，作为服务端允许名单之外的兜底
+	AllowedTargets []string
 }
 
 // Run 建立并维持与服务端的隧道连接，断开时自动重连。
@@ -63,9 +68,7 @@ func (a *Agent) Run(ctx context.Context) error {
 		if ctx.Err() != nil {
 			return nil
 		}
-		err := remotedialer.ClientConnect(ctx, wsURL, headers, dialer, func(proto, address string) bool {
-			return proto == "tcp"
-		}, func(ctx context.Context, _ *remotedialer.Session) error {
+		err := remotedialer.ConnectToProxyWithDialer(ctx, wsURL, headers, a.authorizeDial, dialer, a.dialLocal, func(ctx context.Context, _ *remotedialer.Session) error {
 			klog.Infof("tunnel connected, waiting for dial requests")
 			<-ctx.Done()
 			return nil
@@ -82,6 +85,58 @@ func (a *Agent) Run(ctx context.Context) error {
 	}
 }
 
+// authorizeDial 校验服务端经隧道发起的拨号请求
+func (a *Agent) authorizeDial(proto, address string) bool {
+	if proto != "tcp" {
+		return false
+	}
+	if len(a.AllowedTargets) == 0 || isAPIServerAddress(address) {
+		return true
+	}
+	// 集群外的 apiserver 地址（负载均衡、代理）按 host:port 原样比较
+	for _, raw := range a.AllowedTargets {
+		if strings.EqualFold(strings.TrimSpace(raw), address) {
+			return true
+		}
+	}
+	target, err := tunnel.ParseTarget(address)
+	if err != nil {
+		klog.Warningf("tunnel dial %s denied: %v", address, err)
+		return false
+	}
+	for _, raw := range a.AllowedTargets {
+		allowed, err := tunnel.ParseTarget(raw)
+		if err == nil && allowed.Match(target) {
+			return true
+		}
+	}
+	klog.Warningf("tunnel dial %s denied: not in PIXIU_ALLOWED_TARGETS", address)
+	return false
+}
+
+// dialLocal 代服务端拨号；kubeconfig 中 apiserver 地址为回环或服务端无法解析时，服务端改拨
+// tunnel.APIServerAlias，此处优先解析为 KUBERNETES_SERVICE_HOST:PORT，不依赖集群 DNS（如 hostNetwork 部署）
+func (a *Agent) dialLocal(ctx context.Context, network, address string) (net.Conn, error) {
+	if address == tunnel.APIServerAlias {
+		if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" && port != "" {
+			address = net.JoinHostPort(host, port)
+		}
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+// isAPIServerAddress 判断是否为集群内 kube-apiserver 地址（kubernetes.default Service 或其 ClusterIP）
+func isAPIServerAddress(address string) bool {
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" && port != "" {
+		if address == net.JoinHostPort(host, port) {
+			return true
+		}
+	}
+	target, err := tunnel.ParseTarget(address)
+	return err == nil && target.Service == "kubernetes" && target.Namespace == "default"
+}
+
 func buildConnectURL(server, token string) (string, error) {
 	if !strings.Contains(server, "://") {
 		server = "https://" + server
@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusteragent
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/tunnel"
+)
+
+type fakeTokenLookup map[string]string
+
+func (f fakeTokenLookup) ClusterName(_ context.Context, token string) (string, error) {
+	return f[token], nil
+}
+
+func TestAuthorizeDial(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	a := &Agent{AllowedTargets: []string{"prometheus.monitoring:9090", "apiserver.example.com:6443"}}
+	tests := []struct {
+		proto, address string
+		want           bool
+	}{
+		{"tcp", tunnel.APIServerAlias, true},
+		{"tcp", "10.96.0.1:443", true},
+		{"tcp", "kubernetes.default:443", true},
+		{"tcp", "prometheus.monitoring.svc:9090", true},
+		{"tcp", "redis.default:6379", false},
+		{"tcp", "192.168.1.10:6443", false},
+		{"tcp", "APIServer.example.com:6443", true},
+		{"tcp", "apiserver.example.com:443", false},
+		{"udp", "prometheus.monitoring:9090", false},
+	}
+	for _, tt := range tests {
+		if got := a.authorizeDial(tt.proto, tt.address); got != tt.want {
+			t.Errorf("authorizeDial(%s, %s) = %v, want %v", tt.proto, tt.address, got, tt.want)
+		}
+	}
+}
+
+// TestProbeThroughAllowList 经真实隧道验证：agent 设置本地允许名单时，
+// 服务端 Probe 与 kube-apiserver 拨号仍可到达（apiserver 由本地监听模拟）
+func TestProbeThroughAllowList(t *testing.T) {
+	apiserver, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer apiserver.Close()
+	go func() {
+		for {
+			conn, err := apiserver.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("ok"))
+			_ = conn.Close()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(apiserver.Addr().String())
+	t.Setenv("KUBERNETES_SERVICE_HOST", host)
+	t.Setenv("KUBERNETES_SERVICE_PORT", port)
+
+	m := tunnel.Init(fakeTokenLookup{"token-a": "cluster-a"})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agent := &Agent{Server: srv.URL, Token: "token-a", AllowedTargets: []string{"prometheus.monitoring:9090"}}
+	go func() { _ = agent.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.HasSession("cluster-a") {
+		if time.Now().After(deadline) {
+			t.Fatal("agent tunnel session not established")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if !m.Probe(ctx, "cluster-a") {
+		t.Fatal("Probe() = false, want true with allow-list set")
+	}
+	// kubeconfig 中为回环地址时，ClusterDialContext 改拨 APIServerAlias
+	if got := readThrough(t, m.ClusterDialContext("cluster-a"), "127.0.0.1:6443"); got != "ok" {
+		t.Fatalf("apiserver dial read %q, want %q", got, "ok")
+	}
+	// 集群外的 apiserver 地址原样拨号，由 agent 允许名单决定，不在名单内时拒绝
+	if got := readThrough(t, m.ClusterDialContext("cluster-a"), "203.0.113.10:6443"); got != "" {
+		t.Fatalf("external apiserver dial read %q, want nothing", got)
+	}
+	if got := readThrough(t, m.Dialer("cluster-a"), "redis.default:6379"); got != "" {
+		t.Fatalf("denied target read %q, want nothing", got)
+	}
+}
+
+func readThrough(t *testing.T, dial func(context.Context, string, string) (net.Conn, error), address string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b, _ := io.ReadAll(conn)
+	return string(b)
+}
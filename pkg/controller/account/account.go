@@ -52,12 +52,17 @@ func New(cfg config.Config, f db.ShareDaoFactory) Interface {
 }
 
 func (c *controller) Create(ctx context.Context, req *types.CreateAIAccountRequest) error {
-	if err := validateAccountFields(req.Name, req.APIKey, req.Model, req.ProviderId, true); err != nil {
+	if err := validateAccountFields(req.Name, req.APIKey, req.Model, req.ProviderId, false); err != nil {
 		return err
 	}
-	if err := c.ensureProvider(ctx, req.ProviderId); err != nil {
+	provider, err := c.ensureProvider(ctx, req.ProviderId)
+	if err != nil {
 		return err
 	}
+	// 本地部署的 Ollama 默认无鉴权，允许不填写 API Key
+	if provider.Protocol != "ollama_chat" && strings.TrimSpace(req.APIKey) == "" {
+		return apierrors.NewError(fmt.Errorf("api_key is required"), http.StatusBadRequest)
+	}
 	userId, err := httputils.GetUserIdFromContext(ctx)
 	if err != nil {
 		return apierrors.NewError(fmt.Errorf("failed to get current user"), http.StatusUnauthorized)
@@ -82,7 +87,7 @@ func (c *controller) preUpdate(ctx context.Context, req *types.UpdateAIAccountRe
 	if err := validateAccountFields(req.Name, req.APIKey, req.Model, req.ProviderId, false); err != nil {
 		return err
 	}
-	if err := c.ensureProvider(ctx, req.ProviderId); err != nil {
+	if _, err := c.ensureProvider(ctx, req.ProviderId); err != nil {
 		return err
 	}
 
@@ -203,16 +208,16 @@ func (c *controller) List(ctx context.Context, listOption types.ListOptions) (in
 	}, nil
 }
 
-func (c *controller) ensureProvider(ctx context.Context, providerId int64) error {
+func (c *controller) ensureProvider(ctx context.Context, providerId int64) (*model.AIProvider, error) {
 	provider, err := c.factory.Assistant().Provider().Get(ctx, providerId)
 	if err != nil {
 		klog.Errorf("failed to get ai provider(%d): %v", providerId, err)
-		return apierrors.ErrServerInternal
+		return nil, apierrors.ErrServerInternal
 	}
 	if provider == nil {
-		return apierrors.NewError(fmt.Errorf("ai provider not found"), http.StatusBadRequest)
+		return nil, apierrors.NewError(fmt.Errorf("ai provider not found"), http.StatusBadRequest)
 	}
-	return nil
+	return provider, nil
 }
 
 func modelToType(object *model.AIAccount) *types.AIAccount {
@@ -227,13 +232,14 @@ func modelToType(object *model.AIAccount) *types.AIAccount {
 	}
 	if object.Provider != nil {
 		result.Provider = &types.AIProvider{
-			PixiuMeta:   types.PixiuMeta{Id: object.Provider.Id, ResourceVersion: object.Provider.ResourceVersion},
-			TimeMeta:    types.TimeMeta{GmtCreate: object.Provider.GmtCreate, GmtModified: object.Provider.GmtModified},
-			Name:        object.Provider.Name,
-			BaseURL:     object.Provider.BaseURL,
-			Protocol:    string(object.Provider.Protocol),
-			Description: object.Provider.Description,
-			MaxTokens:   object.Provider.MaxTokens,
+			PixiuMeta:     types.PixiuMeta{Id: object.Provider.Id, ResourceVersion: object.Provider.ResourceVersion},
+			TimeMeta:      types.TimeMeta{GmtCreate: object.Provider.GmtCreate, GmtModified: object.Provider.GmtModified},
+			Name:          object.Provider.Name,
+			BaseURL:       object.Provider.BaseURL,
+			Protocol:      string(object.Provider.Protocol),
+			Description:   object.Provider.Description,
+			MaxTokens:     object.Provider.MaxTokens,
+			ContextWindow: object.Provider.ContextWindow,
 		}
 	}
 	return result
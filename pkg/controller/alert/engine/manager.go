@@ -20,11 +20,14 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/klog/v2"
 
 	"github.com/caoyingjunz/pixiu/pkg/controller/alert/notify"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 )
 
 type Manager struct {
@@ -64,7 +67,15 @@ func (m *Manager) Run(ctx context.Context) error {
 	return m.DispatchPending(ctx)
 }
 
-func (m *Manager) EvaluateRule(ctx context.Context, rule *model.AlertRule) error {
+func (m *Manager) EvaluateRule(ctx context.Context, rule *model.AlertRule) (err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "alert.EvaluateRule",
+		attribute.Int64("pixiu.alert.rule_id", rule.Id), attribute.String("pixiu.alert.rule", rule.Name))
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveAlertEvaluation(rule.Id, rule.Name, time.Since(start), err)
+	}()
+
 	silences, err := m.silence.LoadActive(ctx, time.Now())
 	if err != nil {
 		klog.Errorf("failed to load active alert silences: %v", err)
@@ -33,6 +33,7 @@ import (
 	"github.com/caoyingjunz/pixiu/pkg/client"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 	dsutil "github.com/caoyingjunz/pixiu/pkg/util/datasource"
 )
@@ -57,7 +58,8 @@ func NewDatasourceMetricProvider(factory db.ShareDaoFactory) *DatasourceMetricPr
 	return &DatasourceMetricProvider{
 		factory: factory,
 		httpClient: &http.Client{
-			Timeout: prometheusQueryTimeout,
+			Timeout:   prometheusQueryTimeout,
+			Transport: tracing.NewTransport(http.DefaultTransport),
 		},
 		clusters: make(map[string]*client.ClusterSet),
 	}
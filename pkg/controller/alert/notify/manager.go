@@ -21,11 +21,14 @@ import (
 	"fmt"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/klog/v2"
 
 	"github.com/caoyingjunz/pixiu/pkg/controller/alert/labelmap"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 )
 
 const maxNotifyRetry = 3
@@ -93,6 +96,42 @@ func (n *Manager) EnqueueForEvent(ctx context.Context, rule *model.AlertRule, ev
 	return nil
 }
 
+// EnqueueMessage 向指定通知渠道投递一条与告警规则无关的系统通知（如集群抖动），
+// 由 DispatchPending 统一发送。
+func (n *Manager) EnqueueMessage(ctx context.Context, channelIDs []int64, title, content string, severity model.AlertSeverity) error {
+	if len(channelIDs) == 0 {
+		return nil
+	}
+	channels, err := n.factory.Alert().Channel().List(ctx, db.WithIDIn(channelIDs...), db.WithEnabled(true))
+	if err != nil {
+		klog.Errorf("failed to list channels %v: %v", channelIDs, err)
+		return err
+	}
+
+	for i := range channels {
+		channel := channels[i]
+		receiver, extension, err := resolveNotificationTargetFromChannel(&channel)
+		if err != nil {
+			klog.Errorf("skip notification %q for channel(%d): %v", title, channel.Id, err)
+			continue
+		}
+		if _, err = n.factory.Alert().Notification().Create(ctx, &model.AlertNotification{
+			Channel:     channel.ChannelType,
+			Receiver:    receiver,
+			Title:       title,
+			Content:     content,
+			Status:      model.AlertNotificationStatusPending,
+			Extension:   extension,
+			Severity:    severity,
+			ChannelName: channel.Name,
+		}); err != nil {
+			klog.Errorf("failed to create notification %q for channel(%d): %v", title, channel.Id, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func resolveNotificationTargetFromChannel(channel *model.AlertChannel) (receiver, extension string, err error) {
 	if channel == nil {
 		return "", "", fmt.Errorf("alert channel is nil")
@@ -173,7 +212,10 @@ func (n *Manager) dispatchOne(ctx context.Context, item *model.AlertNotification
 		return nil
 	}
 
+	_, span := tracing.Start(ctx, "alert.SendNotification", attribute.String("pixiu.alert.channel", item.Channel.String()))
 	sendErr := sendByChannel(item)
+	tracing.End(span, sendErr)
+	metrics.ObserveNotification(item.Channel.String(), sendErr)
 	if sendErr != nil {
 		klog.Errorf("failed to send notification(%d) via channel type %d: %v", item.Id, item.Channel, sendErr)
 	}
@@ -0,0 +1,335 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const anthropicAPIVersion = "2023-06-01"
+
+type anthropicStreamResult struct {
+	Raw        map[string]interface{}
+	Text       string
+	ResponseId string
+	StopReason string
+	ToolCalls  []responseToolCall
+	// Content 为本轮 assistant 消息的 content blocks，下一轮请求需要原样带回
+	Content []map[string]interface{}
+	Usage   responseUsage
+}
+
+type anthropicContentBlock struct {
+	Type  string
+	Id    string
+	Name  string
+	Text  strings.Builder
+	Input strings.Builder
+}
+
+func (c *controller) runAnthropicLoopStream(
+	ctx context.Context,
+	endpoint, apiKey, modelName string,
+	maxTokens int,
+	inputItems []map[string]interface{},
+	emit func(*types.AIStreamEvent) error,
+) (map[string]interface{}, string, string, error) {
+	system, messages := toAnthropicMessages(responseInputToChatMessages(inputItems))
+	system = strings.TrimSpace(c.defaultAIInstructions() + "\n\n" + system)
+	tools := toAnthropicTools(c.requestTools(ctx))
+
+	var usage responseUsage
+	for i := 0; i < 8; i++ {
+		_ = emit(&types.AIStreamEvent{Type: "status", Stage: "model", Message: "AI is generating a response", Model: modelName})
+		result, err := c.callAnthropicStream(ctx, endpoint, apiKey, modelName, system, maxTokens, messages, tools, emit)
+		if err != nil {
+			return usageRaw(usage), "", "", err
+		}
+		usage.add(result.Usage)
+		if len(result.ToolCalls) == 0 {
+			result.Raw["usage"] = usage.toRaw()
+			return result.Raw, result.Text, result.ResponseId, nil
+		}
+		if err = c.checkRoundQuota(ctx, usage); err != nil {
+			return usageRaw(usage), "", "", err
+		}
+
+		messages = append(messages, map[string]interface{}{"role": "assistant", "content": result.Content})
+		toolResults := make([]map[string]interface{}, 0, len(result.ToolCalls))
+		for _, call := range result.ToolCalls {
+			emitToolEvent(emit, "tool_start", "tool", describeToolCall(call), call.CallID, call.Name, call.Arguments, "")
+			output, toolErr := c.executeTool(ctx, call.CallID, call.Name, call.Arguments)
+			block := map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": call.CallID,
+				"content":     output,
+			}
+			if toolErr != nil {
+				block["content"] = toolErr.Error()
+				block["is_error"] = true
+				emitToolEvent(emit, "tool_result", "tool", fmt.Sprintf("tool %s failed", call.Name), call.CallID, call.Name, call.Arguments, toolErr.Error())
+			} else {
+				emitToolEvent(emit, "tool_result", "tool", fmt.Sprintf("tool %s completed", call.Name), call.CallID, call.Name, call.Arguments, output)
+			}
+			toolResults = append(toolResults, block)
+		}
+		// 所有 tool_result 必须放在同一条 user 消息中
+		messages = append(messages, map[string]interface{}{"role": "user", "content": toolResults})
+		trimToolOutputs(ctx, messages, len(result.ToolCalls), emit)
+	}
+
+	return usageRaw(usage), "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
+}
+
+func (c *controller) callAnthropicStream(
+	ctx context.Context,
+	endpoint, apiKey, modelName, system string,
+	maxTokens int,
+	messages, tools []map[string]interface{},
+	emit func(*types.AIStreamEvent) error,
+) (*anthropicStreamResult, error) {
+	payload := map[string]interface{}{
+		"model":      modelName,
+		"system":     system,
+		"messages":   messages,
+		"stream":     true,
+		"max_tokens": maxTokens,
+	}
+	if len(tools) > 0 {
+		payload["tools"] = tools
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apierrors.ErrServerInternal
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, apierrors.ErrServerInternal
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		klog.Errorf("failed to request ai endpoint %s: %v", endpoint, err)
+		return nil, apierrors.NewError(fmt.Errorf("request ai endpoint failed: %v", err), http.StatusBadGateway)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, apierrors.NewError(fmt.Errorf("ai endpoint returned status %d: %s", resp.StatusCode, string(responseBody)), http.StatusBadGateway)
+	}
+
+	result, err := parseAnthropicSSE(resp.Body, emit)
+	if err != nil {
+		klog.Errorf("failed to parse anthropic messages stream: %v", err)
+		return nil, apierrors.NewError(fmt.Errorf("invalid ai response"), http.StatusBadGateway)
+	}
+	return result, nil
+}
+
+// parseAnthropicSSE 解析 Messages API 的流式事件，事件类型以 data 中的 type 字段为准
+func parseAnthropicSSE(reader io.Reader, emit func(*types.AIStreamEvent) error) (*anthropicStreamResult, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	result := &anthropicStreamResult{}
+	var textBuilder strings.Builder
+	blocks := map[int]*anthropicContentBlock{}
+	seenEvent := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "" {
+			continue
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			continue
+		}
+		seenEvent = true
+
+		eventType, _ := event["type"].(string)
+		switch eventType {
+		case "message_start":
+			message, _ := event["message"].(map[string]interface{})
+			if id, _ := message["id"].(string); id != "" {
+				result.ResponseId = id
+			}
+			if usage, ok := message["usage"].(map[string]interface{}); ok {
+				result.Usage.InputTokens = toInt64(usage["input_tokens"]) +
+					toInt64(usage["cache_read_input_tokens"]) + toInt64(usage["cache_creation_input_tokens"])
+				result.Usage.CachedTokens = toInt64(usage["cache_read_input_tokens"])
+				result.Usage.OutputTokens = toInt64(usage["output_tokens"])
+			}
+		case "content_block_start":
+			index := int(toInt64(event["index"]))
+			content, _ := event["content_block"].(map[string]interface{})
+			block := &anthropicContentBlock{}
+			block.Type, _ = content["type"].(string)
+			block.Id, _ = content["id"].(string)
+			block.Name, _ = content["name"].(string)
+			if text, _ := content["text"].(string); text != "" {
+				block.Text.WriteString(text)
+				textBuilder.WriteString(text)
+				_ = emit(&types.AIStreamEvent{Type: "delta", Stage: "message", Delta: text})
+			}
+			blocks[index] = block
+		case "content_block_delta":
+			block := blocks[int(toInt64(event["index"]))]
+			if block == nil {
+				continue
+			}
+			delta, _ := event["delta"].(map[string]interface{})
+			switch delta["type"] {
+			case "text_delta":
+				if text, _ := delta["text"].(string); text != "" {
+					block.Text.WriteString(text)
+					textBuilder.WriteString(text)
+					_ = emit(&types.AIStreamEvent{Type: "delta", Stage: "message", Delta: text})
+				}
+			case "input_json_delta":
+				partial, _ := delta["partial_json"].(string)
+				block.Input.WriteString(partial)
+			}
+		case "message_delta":
+			delta, _ := event["delta"].(map[string]interface{})
+			if reason, _ := delta["stop_reason"].(string); reason != "" {
+				result.StopReason = reason
+			}
+			// message_delta 中的 output_tokens 为累计值
+			if usage, ok := event["usage"].(map[string]interface{}); ok {
+				if output := toInt64(usage["output_tokens"]); output > 0 {
+					result.Usage.OutputTokens = output
+				}
+			}
+		case "error":
+			errObject, _ := event["error"].(map[string]interface{})
+			message, _ := errObject["message"].(string)
+			if message == "" {
+				message = "anthropic stream error"
+			}
+			return nil, errors.New(message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !seenEvent {
+		return nil, fmt.Errorf("empty sse response")
+	}
+
+	indices := make([]int, 0, len(blocks))
+	for index := range blocks {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	for _, index := range indices {
+		block := blocks[index]
+		switch block.Type {
+		case "text":
+			result.Content = append(result.Content, map[string]interface{}{"type": "text", "text": block.Text.String()})
+		case "tool_use":
+			arguments := strings.TrimSpace(block.Input.String())
+			if arguments == "" {
+				arguments = "{}"
+			}
+			var input map[string]interface{}
+			if err := json.Unmarshal([]byte(arguments), &input); err != nil {
+				return nil, fmt.Errorf("invalid tool_use input for %s: %v", block.Name, err)
+			}
+			result.Content = append(result.Content, map[string]interface{}{
+				"type": "tool_use", "id": block.Id, "name": block.Name, "input": input,
+			})
+			result.ToolCalls = append(result.ToolCalls, responseToolCall{CallID: block.Id, Name: block.Name, Arguments: arguments})
+		}
+	}
+
+	result.Text = textBuilder.String()
+	result.Usage.TotalTokens = result.Usage.InputTokens + result.Usage.OutputTokens
+	result.Raw = map[string]interface{}{
+		"id":          result.ResponseId,
+		"type":        "message",
+		"role":        "assistant",
+		"content":     result.Content,
+		"stop_reason": result.StopReason,
+		"usage":       result.Usage.toRaw(),
+	}
+	return result, nil
+}
+
+// toAnthropicMessages system 消息不能出现在 messages 中，需要并入 system 参数；user / assistant 需要交替出现
+func toAnthropicMessages(chatMessages []map[string]interface{}) (string, []map[string]interface{}) {
+	var system []string
+	messages := make([]map[string]interface{}, 0, len(chatMessages))
+	for _, message := range chatMessages {
+		role, _ := message["role"].(string)
+		content, _ := message["content"].(string)
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		if role == "system" {
+			system = append(system, content)
+			continue
+		}
+		if role != "user" && role != "assistant" {
+			continue
+		}
+		if n := len(messages); n > 0 && messages[n-1]["role"] == role {
+			messages[n-1]["content"] = messages[n-1]["content"].(string) + "\n\n" + content
+			continue
+		}
+		messages = append(messages, map[string]interface{}{"role": role, "content": content})
+	}
+	// 历史压缩后可能以 assistant 消息开头，而 Messages API 要求第一条为 user
+	if len(messages) > 0 && messages[0]["role"] == "assistant" {
+		messages = append([]map[string]interface{}{{"role": "user", "content": "继续之前的对话。"}}, messages...)
+	}
+	return strings.Join(system, "\n\n"), messages
+}
+
+func toAnthropicTools(tools []toolDefinition) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		items = append(items, map[string]interface{}{
+			"name":         tool.Name,
+			"description":  tool.Description,
+			"input_schema": tool.Parameters,
+		})
+	}
+	return items
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+func anthropicSSE(events ...string) string {
+	var builder strings.Builder
+	for _, event := range events {
+		var payload map[string]interface{}
+		_ = json.Unmarshal([]byte(event), &payload)
+		fmt.Fprintf(&builder, "event: %s\ndata: %s\n\n", payload["type"], event)
+	}
+	return builder.String()
+}
+
+func discardEvents(*types.AIStreamEvent) error { return nil }
+
+func TestParseAnthropicSSE(t *testing.T) {
+	stream := anthropicSSE(
+		`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":20,"cache_read_input_tokens":5,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"查看"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"事件"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"k8s_events","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"cluster\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"dev\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":15}}`,
+		`{"type":"message_stop"}`,
+	)
+
+	var deltas []string
+	result, err := parseAnthropicSSE(strings.NewReader(stream), func(event *types.AIStreamEvent) error {
+		deltas = append(deltas, event.Delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "查看事件" || strings.Join(deltas, "") != "查看事件" {
+		t.Fatalf("unexpected text %q, deltas %v", result.Text, deltas)
+	}
+	if result.ResponseId != "msg_1" || result.StopReason != "tool_use" {
+		t.Fatalf("unexpected id %q or stop reason %q", result.ResponseId, result.StopReason)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].CallID != "toolu_1" || result.ToolCalls[0].Arguments != `{"cluster":"dev"}` {
+		t.Fatalf("unexpected tool calls %+v", result.ToolCalls)
+	}
+	if len(result.Content) != 2 || result.Content[1]["type"] != "tool_use" {
+		t.Fatalf("unexpected content blocks %+v", result.Content)
+	}
+
+	usage := extractResponseUsage(result.Raw)
+	if usage.InputTokens != 25 || usage.OutputTokens != 15 || usage.TotalTokens != 40 || usage.CachedTokens != 5 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestParseAnthropicSSEError(t *testing.T) {
+	stream := anthropicSSE(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	if _, err := parseAnthropicSSE(strings.NewReader(stream), discardEvents); err == nil || err.Error() != "Overloaded" {
+		t.Fatalf("expected overloaded error, got %v", err)
+	}
+}
+
+func TestRunAnthropicLoopStream(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-test" || r.Header.Get("anthropic-version") != anthropicAPIVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+
+		mu.Lock()
+		requests = append(requests, payload)
+		round := len(requests)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if round == 1 {
+			_, _ = io.WriteString(w, anthropicSSE(
+				`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":10,"output_tokens":1}}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"missing_tool","input":{}}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":5}}`,
+			))
+			return
+		}
+		_, _ = io.WriteString(w, anthropicSSE(
+			`{"type":"message_start","message":{"id":"msg_2","usage":{"input_tokens":30,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"工具不存在"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":7}}`,
+		))
+	}))
+	defer srv.Close()
+
+	c := &controller{client: srv.Client()}
+	inputItems := []map[string]interface{}{
+		{"role": "user", "content": []map[string]interface{}{{"type": "input_text", "text": "你好"}}},
+		{"role": "user", "content": []map[string]interface{}{{"type": "input_text", "text": "查一下事件"}}},
+	}
+	raw, text, responseId, err := c.runAnthropicLoopStream(context.Background(), srv.URL, "sk-test", "claude", 1024, inputItems, discardEvents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "工具不存在" || responseId != "msg_2" {
+		t.Fatalf("unexpected text %q or response id %q", text, responseId)
+	}
+	usage := extractResponseUsage(raw)
+	if usage.InputTokens != 40 || usage.OutputTokens != 12 || usage.TotalTokens != 52 {
+		t.Fatalf("unexpected accumulated usage %+v", usage)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	// 连续的 user 消息需要合并，保证角色交替
+	first, _ := requests[0]["messages"].([]interface{})
+	if len(first) != 1 {
+		t.Fatalf("expected merged user message, got %v", first)
+	}
+	second, _ := requests[1]["messages"].([]interface{})
+	if len(second) != 3 {
+		t.Fatalf("expected tool_use and tool_result turns, got %v", second)
+	}
+	last, _ := second[2].(map[string]interface{})
+	blocks, _ := last["content"].([]interface{})
+	block, _ := blocks[0].(map[string]interface{})
+	if last["role"] != "user" || block["type"] != "tool_result" || block["tool_use_id"] != "toolu_1" || block["is_error"] != true {
+		t.Fatalf("unexpected tool_result message %v", last)
+	}
+}
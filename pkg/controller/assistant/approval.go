@@ -0,0 +1,217 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+var (
+	// 等待用户审批的最长时间，超时后记录为过期并拒绝执行
+	approvalTimeout = 5 * time.Minute
+	// 审批结果通过数据库轮询获取，多副本部署下审批请求可能落在其他实例
+	approvalPollInterval = time.Second
+)
+
+// executeWithApproval 暂停工具调用并等待用户审批，审批通过后执行并回写同一条执行记录
+func (c *controller) executeWithApproval(ctx context.Context, tool toolDefinition, callID, rawArgs string, args map[string]interface{}, policy *toolCallPolicy) (string, error) {
+	meta := getToolExecutionMeta(ctx)
+	if meta == nil || meta.Emit == nil || meta.UserId == 0 {
+		return "", fmt.Errorf("mutating tool call requires an interactive session for approval")
+	}
+
+	record := newToolExecution(meta, callID, tool.Name, rawArgs, policy)
+	record.ApprovalStatus = model.ApprovalPending
+	record, err := c.factory.Assistant().Execution().Create(ctx, record)
+	if err != nil {
+		klog.Errorf("failed to create pending ai execution for tool(%s): %v", tool.Name, err)
+		return "", fmt.Errorf("failed to request approval")
+	}
+
+	_ = meta.Emit(&types.AIStreamEvent{
+		Type:        "approval_required",
+		Stage:       "approval",
+		Message:     fmt.Sprintf("变更操作 %s 需要确认后执行", policy.Verb),
+		ToolCallId:  callID,
+		ToolName:    tool.Name,
+		ToolArgs:    truncateToolOutput(rawArgs),
+		ExecutionId: record.Id,
+	})
+
+	decided, err := c.waitForApproval(ctx, record)
+	if err != nil {
+		return "", err
+	}
+	_ = meta.Emit(&types.AIStreamEvent{
+		Type:        "approval_result",
+		Stage:       "approval",
+		Message:     string(decided.ApprovalStatus),
+		ToolCallId:  callID,
+		ToolName:    tool.Name,
+		ExecutionId: decided.Id,
+	})
+
+	switch decided.ApprovalStatus {
+	case model.ApprovalApproved:
+	case model.ApprovalRejected:
+		if decided.Reason != "" {
+			return "", fmt.Errorf("user rejected this operation: %s", decided.Reason)
+		}
+		return "", fmt.Errorf("user rejected this operation")
+	default:
+		return "", fmt.Errorf("approval %s, operation not executed", decided.ApprovalStatus)
+	}
+
+	start := time.Now()
+	output, runErr := tool.Handler(ctx, args)
+	updates := map[string]interface{}{
+		"output":   truncateToolOutput(output),
+		"success":  runErr == nil,
+		"duration": time.Since(start).Milliseconds(),
+	}
+	if runErr != nil {
+		updates["error_message"] = truncateToolOutput(runErr.Error())
+	}
+
+	recordCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err = c.factory.Assistant().Execution().Update(recordCtx, decided.Id, decided.ResourceVersion, updates); err != nil {
+		klog.Errorf("failed to update ai execution(%d) result: %v", decided.Id, err)
+	}
+	return output, runErr
+}
+
+// waitForApproval 轮询执行记录直到用户作出决定、超时或请求被取消
+func (c *controller) waitForApproval(ctx context.Context, record *model.Execution) (*model.Execution, error) {
+	timer := time.NewTimer(approvalTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(approvalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.expireExecution(record.Id)
+			return nil, ctx.Err()
+		case <-timer.C:
+			return c.expireExecution(record.Id), nil
+		case <-ticker.C:
+			object, err := c.factory.Assistant().Execution().Get(ctx, record.Id)
+			if err != nil {
+				klog.Errorf("failed to get ai execution(%d): %v", record.Id, err)
+				continue
+			}
+			if object == nil {
+				return nil, fmt.Errorf("approval record not found")
+			}
+			if object.ApprovalStatus != model.ApprovalPending {
+				return object, nil
+			}
+		}
+	}
+}
+
+// expireExecution 将仍在等待中的审批标记为过期，并返回最终状态的记录
+func (c *controller) expireExecution(id int64) *model.Execution {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	expired := &model.Execution{ApprovalStatus: model.ApprovalExpired}
+	for i := 0; i < 3; i++ {
+		object, err := c.factory.Assistant().Execution().Get(ctx, id)
+		if err != nil || object == nil {
+			klog.Errorf("failed to get ai execution(%d) before expiring: %v", id, err)
+			return expired
+		}
+		if object.ApprovalStatus != model.ApprovalPending {
+			return object
+		}
+		err = c.factory.Assistant().Execution().Update(ctx, id, object.ResourceVersion, map[string]interface{}{
+			"approval_status": model.ApprovalExpired,
+		})
+		if err == nil {
+			object.ApprovalStatus = model.ApprovalExpired
+			object.ResourceVersion++
+			return object
+		}
+		if !errors.IsRecordNotFound(err) {
+			klog.Errorf("failed to expire ai execution(%d): %v", id, err)
+			return expired
+		}
+	}
+	return expired
+}
+
+func (c *controller) ApproveExecution(ctx context.Context, executionId int64, req *types.AIExecutionDecisionRequest) error {
+	return c.decideExecution(ctx, executionId, model.ApprovalApproved, req)
+}
+
+func (c *controller) RejectExecution(ctx context.Context, executionId int64, req *types.AIExecutionDecisionRequest) error {
+	return c.decideExecution(ctx, executionId, model.ApprovalRejected, req)
+}
+
+// decideExecution 只有发起调用的用户（或超级管理员）可以审批，且只能从 pending 转换一次
+func (c *controller) decideExecution(ctx context.Context, executionId int64, status model.ApprovalStatus, req *types.AIExecutionDecisionRequest) error {
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return apierrors.ErrUnauthorized
+	}
+
+	object, err := c.factory.Assistant().Execution().Get(ctx, executionId)
+	if err != nil {
+		klog.Errorf("failed to get ai execution(%d): %v", executionId, err)
+		return apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return apierrors.NewError(fmt.Errorf("execution not found"), http.StatusNotFound)
+	}
+	if object.UserId != user.Id && user.Role != model.RoleRoot {
+		return apierrors.ErrForbidden
+	}
+	if object.ApprovalStatus != model.ApprovalPending {
+		return apierrors.NewError(fmt.Errorf("execution is %s, not pending approval", object.ApprovalStatus), http.StatusConflict)
+	}
+
+	var reason string
+	if req != nil {
+		reason = strings.TrimSpace(req.Reason)
+	}
+	if err = c.factory.Assistant().Execution().Update(ctx, object.Id, object.ResourceVersion, map[string]interface{}{
+		"approval_status": status,
+		"approved_by":     user.Name,
+		"reason":          reason,
+	}); err != nil {
+		if errors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("execution has already been decided"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update ai execution(%d) approval: %v", object.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	assistant   *fakeAssistant
+	permissions *fakePermissions
+	datasources *fakeDatasources
+	alert       *fakeAlert
+}
+
+func (f *fakeFactory) Assistant() db.AssistantInterface   { return f.assistant }
+func (f *fakeFactory) Permission() db.PermissionInterface { return f.permissions }
+func (f *fakeFactory) Datasource() db.DatasourceInterface { return f.datasources }
+func (f *fakeFactory) Alert() db.AlertInterface           { return f.alert }
+
+type fakeAssistant struct {
+	db.AssistantInterface
+	executions *fakeExecutions
+}
+
+func (a *fakeAssistant) Execution() db.ExecutionInterface { return a.executions }
+
+// fakeExecutions 按 resource_version 做乐观锁，与数据库实现保持一致
+type fakeExecutions struct {
+	db.ExecutionInterface
+	mu    sync.Mutex
+	items map[int64]model.Execution
+}
+
+func (e *fakeExecutions) Get(_ context.Context, id int64) (*model.Execution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	object, ok := e.items[id]
+	if !ok {
+		return nil, nil
+	}
+	return &object, nil
+}
+
+func (e *fakeExecutions) Update(_ context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	object, ok := e.items[id]
+	if !ok || object.ResourceVersion != resourceVersion {
+		return errors.ErrRecordNotFound
+	}
+	if status, ok := updates["approval_status"]; ok {
+		object.ApprovalStatus = status.(model.ApprovalStatus)
+	}
+	if by, ok := updates["approved_by"]; ok {
+		object.ApprovedBy = by.(string)
+	}
+	if reason, ok := updates["reason"]; ok {
+		object.Reason = reason.(string)
+	}
+	object.ResourceVersion++
+	e.items[id] = object
+	return nil
+}
+
+func (e *fakeExecutions) status(id int64) model.ApprovalStatus {
+	object, _ := e.Get(context.TODO(), id)
+	return object.ApprovalStatus
+}
+
+func newApprovalController(items ...model.Execution) (*controller, *fakeExecutions) {
+	executions := &fakeExecutions{items: map[int64]model.Execution{}}
+	for _, item := range items {
+		executions.items[item.Id] = item
+	}
+	return &controller{factory: &fakeFactory{assistant: &fakeAssistant{executions: executions}}}, executions
+}
+
+func pendingExecution(id, userId int64) model.Execution {
+	object := model.Execution{UserId: userId, ApprovalStatus: model.ApprovalPending}
+	object.Id = id
+	object.ResourceVersion = 1
+	return object
+}
+
+func userContext(user *model.User) context.Context {
+	c := &gin.Context{}
+	httputils.SetUserToContext(c, user)
+	return c
+}
+
+func newUser(id int64, role model.UserLevel) *model.User {
+	user := &model.User{Name: "user", Role: role}
+	user.Id = id
+	return user
+}
+
+func statusCode(err error) int {
+	if e, ok := err.(apierrors.Error); ok {
+		return e.Code
+	}
+	return 0
+}
+
+func TestDecideExecution(t *testing.T) {
+	cases := []struct {
+		name   string
+		user   *model.User
+		status model.ApprovalStatus
+		code   int
+	}{
+		{name: "owner approves", user: newUser(1, model.RoleUser), status: model.ApprovalApproved},
+		{name: "owner rejects", user: newUser(1, model.RoleUser), status: model.ApprovalRejected},
+		{name: "root approves", user: newUser(2, model.RoleRoot), status: model.ApprovalApproved},
+		{name: "non-owner is forbidden", user: newUser(2, model.RoleUser), status: model.ApprovalApproved, code: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		c, executions := newApprovalController(pendingExecution(10, 1))
+		err := c.decideExecution(userContext(tc.user), 10, tc.status, &types.AIExecutionDecisionRequest{Reason: " ok "})
+		if tc.code != 0 {
+			if statusCode(err) != tc.code {
+				t.Errorf("%s: expected code %d, got %v", tc.name, tc.code, err)
+			}
+			if got := executions.status(10); got != model.ApprovalPending {
+				t.Errorf("%s: expected execution to stay pending, got %s", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		object, _ := executions.Get(context.TODO(), 10)
+		if object.ApprovalStatus != tc.status || object.Reason != "ok" {
+			t.Errorf("%s: unexpected execution %+v", tc.name, object)
+		}
+
+		// 同一条记录只能决定一次
+		err = c.decideExecution(userContext(tc.user), 10, model.ApprovalRejected, nil)
+		if statusCode(err) != http.StatusConflict {
+			t.Errorf("%s: expected second decision to conflict, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestDecideExecutionNotFound(t *testing.T) {
+	c, _ := newApprovalController()
+	err := c.decideExecution(userContext(newUser(1, model.RoleUser)), 10, model.ApprovalApproved, nil)
+	if statusCode(err) != http.StatusNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func shortenApproval(t *testing.T, timeout time.Duration) {
+	oldTimeout, oldInterval := approvalTimeout, approvalPollInterval
+	approvalTimeout, approvalPollInterval = timeout, 5*time.Millisecond
+	t.Cleanup(func() {
+		approvalTimeout, approvalPollInterval = oldTimeout, oldInterval
+	})
+}
+
+func TestWaitForApproval(t *testing.T) {
+	shortenApproval(t, 5*time.Second)
+
+	for _, status := range []model.ApprovalStatus{model.ApprovalApproved, model.ApprovalRejected} {
+		c, _ := newApprovalController(pendingExecution(10, 1))
+		record, _ := c.factory.Assistant().Execution().Get(context.TODO(), 10)
+
+		go func(status model.ApprovalStatus) {
+			time.Sleep(20 * time.Millisecond)
+			_ = c.decideExecution(userContext(newUser(1, model.RoleUser)), 10, status, nil)
+		}(status)
+
+		decided, err := c.waitForApproval(context.TODO(), record)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if decided.ApprovalStatus != status {
+			t.Errorf("expected %s, got %s", status, decided.ApprovalStatus)
+		}
+	}
+}
+
+func TestWaitForApprovalTimeout(t *testing.T) {
+	shortenApproval(t, 30*time.Millisecond)
+
+	c, executions := newApprovalController(pendingExecution(10, 1))
+	record, _ := executions.Get(context.TODO(), 10)
+	decided, err := c.waitForApproval(context.TODO(), record)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decided.ApprovalStatus != model.ApprovalExpired || executions.status(10) != model.ApprovalExpired {
+		t.Errorf("expected execution to expire, got %s", executions.status(10))
+	}
+
+	// 过期后用户再审批返回冲突
+	err = c.decideExecution(userContext(newUser(1, model.RoleUser)), 10, model.ApprovalApproved, nil)
+	if statusCode(err) != http.StatusConflict {
+		t.Errorf("expected conflict after expiry, got %v", err)
+	}
+}
+
+func TestWaitForApprovalCancel(t *testing.T) {
+	shortenApproval(t, 5*time.Second)
+
+	c, executions := newApprovalController(pendingExecution(10, 1))
+	record, _ := executions.Get(context.TODO(), 10)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.waitForApproval(ctx, record); err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+	if got := executions.status(10); got != model.ApprovalExpired {
+		t.Errorf("expected cancelled approval to expire, got %s", got)
+	}
+}
+
+func TestExpireExecution(t *testing.T) {
+	decided := pendingExecution(11, 1)
+	decided.ApprovalStatus = model.ApprovalApproved
+	c, executions := newApprovalController(pendingExecution(10, 1), decided)
+
+	if got := c.expireExecution(10); got.ApprovalStatus != model.ApprovalExpired || executions.status(10) != model.ApprovalExpired {
+		t.Errorf("expected pending execution to expire, got %s", got.ApprovalStatus)
+	}
+	// 已经作出的决定不会被覆盖
+	if got := c.expireExecution(11); got.ApprovalStatus != model.ApprovalApproved || executions.status(11) != model.ApprovalApproved {
+		t.Errorf("expected decided execution to keep approved, got %s", got.ApprovalStatus)
+	}
+	if got := c.expireExecution(12); got.ApprovalStatus != model.ApprovalExpired {
+		t.Errorf("expected missing execution to report expired, got %s", got.ApprovalStatus)
+	}
+}
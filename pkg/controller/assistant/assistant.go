@@ -23,9 +23,12 @@ import (
 
 	"github.com/caoyingjunz/pixiu/cmd/app/config"
 	"github.com/caoyingjunz/pixiu/pkg/controller/account"
+	clustercontroller "github.com/caoyingjunz/pixiu/pkg/controller/cluster"
 	"github.com/caoyingjunz/pixiu/pkg/controller/conversation"
 	"github.com/caoyingjunz/pixiu/pkg/controller/message"
+	"github.com/caoyingjunz/pixiu/pkg/controller/price"
 	"github.com/caoyingjunz/pixiu/pkg/controller/provider"
+	"github.com/caoyingjunz/pixiu/pkg/controller/quota"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
@@ -36,21 +39,28 @@ type Getter interface {
 
 type Interface interface {
 	Stream(ctx context.Context, req *types.AIRespondRequest, emit func(*types.AIStreamEvent) error) (*types.AIRespondResponse, error)
+	ApproveExecution(ctx context.Context, executionId int64, req *types.AIExecutionDecisionRequest) error
+	RejectExecution(ctx context.Context, executionId int64, req *types.AIExecutionDecisionRequest) error
 
 	Provider() provider.Interface
 	Account() account.Interface
 	Conversation() conversation.Interface
 	Message() message.Interface
+	Quota() quota.Interface
+	Price() price.Interface
 }
 
 type controller struct {
 	cc           config.Config
 	factory      db.ShareDaoFactory
 	client       *http.Client
+	cluster      clustercontroller.Interface
 	provider     provider.Interface
 	account      account.Interface
 	conversation conversation.Interface
 	message      message.Interface
+	quota        quota.Interface
+	price        price.Interface
 }
 
 func New(cfg config.Config, f db.ShareDaoFactory) Interface {
@@ -58,10 +68,13 @@ func New(cfg config.Config, f db.ShareDaoFactory) Interface {
 		cc:           cfg,
 		factory:      f,
 		client:       &http.Client{Timeout: 60 * time.Second},
+		cluster:      clustercontroller.NewCluster(cfg, f),
 		provider:     provider.New(cfg, f),
 		account:      account.New(cfg, f),
 		conversation: conversation.New(cfg, f),
 		message:      message.New(cfg, f),
+		quota:        quota.New(cfg, f),
+		price:        price.New(cfg, f),
 	}
 }
 
@@ -80,3 +93,11 @@ func (c *controller) Conversation() conversation.Interface {
 func (c *controller) Message() message.Interface {
 	return c.message
 }
+
+func (c *controller) Quota() quota.Interface {
+	return c.quota
+}
+
+func (c *controller) Price() price.Interface {
+	return c.price
+}
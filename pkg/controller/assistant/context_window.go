@@ -0,0 +1,373 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	// defaultContextWindow provider 未配置上下文窗口时使用的保守默认值
+	defaultContextWindow = 32768
+	// minInputBudget 扣除输出预留和系统指令后的最小输入预算
+	minInputBudget = 1024
+
+	// historyCompactRatio 历史占输入预算的比例超过该值时触发压缩，剩余部分留给本轮的工具输出
+	historyCompactRatio = 0.6
+	// historyKeepRatio 压缩后保留原文的最近消息占输入预算的比例
+	historyKeepRatio = 0.3
+	// summaryMaxTokens 生成摘要时的输出上限
+	summaryMaxTokens = 1024
+
+	// staleToolOutputRunes 较早的工具输出被截断后保留的字符数
+	staleToolOutputRunes = 800
+	// itemOverheadTokens 每条消息的角色、分隔符等额外开销
+	itemOverheadTokens = 4
+)
+
+const historySummaryPrompt = "请把以上对话压缩成一段摘要，供后续对话继续使用。" +
+	"保留用户的目标、涉及的集群 / 命名空间 / 资源名称、已确认的结论和关键证据、已执行的操作及结果、尚未解决的问题。" +
+	"只根据对话内容总结，不要编造，不要调用工具，不超过 500 字。"
+
+// tokenRatio 分词器的经验系数：英文按字符数折算，中日韩等宽字符按个数折算
+type tokenRatio struct {
+	asciiCharsPerToken float64
+	tokensPerWideChar  float64
+}
+
+// protocolTokenRatios 各协议对应模型分词粒度的估算值，宁可高估也不要低估
+var protocolTokenRatios = map[string]tokenRatio{
+	ProtocolOpenAIResponses: {asciiCharsPerToken: 4, tokensPerWideChar: 1},
+	ProtocolOpenAIChat:      {asciiCharsPerToken: 3.5, tokensPerWideChar: 1},
+	ProtocolAnthropic:       {asciiCharsPerToken: 3.5, tokensPerWideChar: 1.3},
+	ProtocolOllama:          {asciiCharsPerToken: 3, tokensPerWideChar: 1.3},
+}
+
+// countTokens 按 provider 协议估算文本的 token 数
+func countTokens(protocol, text string) int {
+	ratio, ok := protocolTokenRatios[normalizeProtocol(protocol)]
+	if !ok {
+		ratio = protocolTokenRatios[ProtocolOpenAIChat]
+	}
+	var ascii, wide int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			wide++
+		}
+	}
+	return int(math.Ceil(float64(ascii)/ratio.asciiCharsPerToken + float64(wide)*ratio.tokensPerWideChar))
+}
+
+type contextWindowKey struct{}
+
+type toolsDisabledKey struct{}
+
+// contextWindow 单次对话可用的上下文预算
+type contextWindow struct {
+	Protocol string
+	// InputBudget 扣除输出预留（provider MaxTokens）、系统指令和工具定义后可用于消息的 token
+	InputBudget int
+}
+
+func newContextWindow(provider *model.AIProvider, instructions string, tools []toolDefinition) *contextWindow {
+	window := defaultContextWindow
+	if provider.ContextWindow > 0 {
+		window = provider.ContextWindow
+	}
+	w := &contextWindow{Protocol: provider.Protocol}
+	budget := window - resolveProviderMaxTokens(provider) - countTokens(provider.Protocol, instructions)
+	if len(tools) > 0 {
+		budget -= w.countValue(toChatCompletionsTools(tools))
+	}
+	if budget < minInputBudget {
+		budget = minInputBudget
+	}
+	w.InputBudget = budget
+	return w
+}
+
+func withContextWindow(ctx context.Context, w *contextWindow) context.Context {
+	return context.WithValue(ctx, contextWindowKey{}, w)
+}
+
+func getContextWindow(ctx context.Context) *contextWindow {
+	w, _ := ctx.Value(contextWindowKey{}).(*contextWindow)
+	return w
+}
+
+// withoutTools 生成摘要等内部请求不需要调用工具
+func withoutTools(ctx context.Context) context.Context {
+	return context.WithValue(ctx, toolsDisabledKey{}, true)
+}
+
+func (c *controller) requestTools(ctx context.Context) []toolDefinition {
+	if disabled, _ := ctx.Value(toolsDisabledKey{}).(bool); disabled {
+		return nil
+	}
+	return c.buildTools()
+}
+
+func (w *contextWindow) countItem(item model.ConversationItem) int {
+	return countTokens(w.Protocol, item.Text()) + itemOverheadTokens
+}
+
+func (w *contextWindow) countItems(items []model.ConversationItem) int {
+	total := 0
+	for _, item := range items {
+		total += w.countItem(item)
+	}
+	return total
+}
+
+// countValue 估算任意请求片段（消息、工具调用、工具输出）序列化后的 token 数
+func (w *contextWindow) countValue(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return countTokens(w.Protocol, string(data))
+}
+
+type toolOutputSlot struct {
+	holder map[string]interface{}
+	key    string
+}
+
+// collectToolOutputSlots 按出现顺序找到各协议消息中的工具输出
+func collectToolOutputSlots(items []map[string]interface{}) []toolOutputSlot {
+	var slots []toolOutputSlot
+	for _, item := range items {
+		if item["type"] == "function_call_output" {
+			slots = append(slots, toolOutputSlot{holder: item, key: "output"})
+			continue
+		}
+		if item["role"] == "tool" {
+			slots = append(slots, toolOutputSlot{holder: item, key: "content"})
+			continue
+		}
+		blocks, _ := item["content"].([]map[string]interface{})
+		for _, block := range blocks {
+			if block["type"] == "tool_result" {
+				slots = append(slots, toolOutputSlot{holder: block, key: "content"})
+			}
+		}
+	}
+	return slots
+}
+
+// trimToolOutputs 输入超出预算时从最早的工具输出开始截断，最近一轮的 keepLatest 个输出保持完整
+func (w *contextWindow) trimToolOutputs(items []map[string]interface{}, keepLatest int) int {
+	total := w.countValue(items)
+	if total <= w.InputBudget {
+		return 0
+	}
+	slots := collectToolOutputSlots(items)
+	if keepLatest > len(slots) {
+		keepLatest = len(slots)
+	}
+
+	trimmed := 0
+	for _, slot := range slots[:len(slots)-keepLatest] {
+		if total <= w.InputBudget {
+			break
+		}
+		text, _ := slot.holder[slot.key].(string)
+		truncated := truncateStaleToolOutput(text)
+		if truncated == text {
+			continue
+		}
+		total -= countTokens(w.Protocol, text) - countTokens(w.Protocol, truncated)
+		slot.holder[slot.key] = truncated
+		trimmed++
+	}
+	return trimmed
+}
+
+// trimToolOutputs 在工具循环的每一轮之后调用，保证下一轮请求不超出上下文窗口
+func trimToolOutputs(ctx context.Context, items []map[string]interface{}, keepLatest int, emit func(*types.AIStreamEvent) error) {
+	w := getContextWindow(ctx)
+	if w == nil {
+		return
+	}
+	if trimmed := w.trimToolOutputs(items, keepLatest); trimmed > 0 {
+		emitStage(emit, "status", "context", fmt.Sprintf("上下文接近上限，已截断 %d 条较早的工具输出", trimmed))
+	}
+}
+
+func truncateStaleToolOutput(text string) string {
+	if utf8.RuneCountInString(text) <= staleToolOutputRunes {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:staleToolOutputRunes]) + "\n...[较早的工具输出已截断，如需完整内容请重新调用工具]"
+}
+
+// compactHistory 历史超过阈值时用模型把较早的消息压缩为摘要，置顶消息和最近的消息保留原文。
+// 压缩结果直接写回 conversation.History，随本轮对话一起持久化
+func (c *controller) compactHistory(
+	ctx context.Context,
+	provider *model.AIProvider,
+	account *model.AIAccount,
+	conversation *model.Conversation,
+	input string,
+	emit func(*types.AIStreamEvent) error,
+) error {
+	w := getContextWindow(ctx)
+	if w == nil || conversation == nil {
+		return nil
+	}
+	items, err := conversation.HistoryItems()
+	if err != nil {
+		return err
+	}
+	inputTokens := countTokens(w.Protocol, input) + itemOverheadTokens
+	if w.countItems(items)+inputTokens <= int(float64(w.InputBudget)*historyCompactRatio) {
+		return nil
+	}
+
+	older, recent := splitHistory(w, items, int(float64(w.InputBudget)*historyKeepRatio))
+	var pinned, stale []model.ConversationItem
+	for _, item := range older {
+		if item.Pinned {
+			pinned = append(pinned, item)
+		} else {
+			stale = append(stale, item)
+		}
+	}
+
+	compacted := items
+	if len(stale) > 0 {
+		emitStage(emit, "status", "context", "对话历史较长，正在压缩较早的消息")
+		summary, err := c.summarizeHistory(ctx, provider, account, conversation.Id, w, stale)
+		if err != nil {
+			klog.Errorf("failed to summarize ai conversation(%d) history: %v", conversation.Id, err)
+			emitStage(emit, "status", "warning", "历史压缩失败，将直接丢弃最早的消息")
+		} else {
+			compacted = make([]model.ConversationItem, 0, len(pinned)+len(recent)+1)
+			compacted = append(compacted, newSummaryItem(summary))
+			compacted = append(compacted, pinned...)
+			compacted = append(compacted, recent...)
+		}
+	}
+
+	// 摘要失败或置顶消息过多时仍可能超出预算，按时间顺序丢弃未置顶的消息
+	compacted = dropOverflow(w, compacted, w.InputBudget-inputTokens)
+	return conversation.SetHistoryItems(compacted)
+}
+
+// splitHistory 从最新的消息往前保留 keepBudget 以内的未置顶消息原文，其余视为较早的消息
+func splitHistory(w *contextWindow, items []model.ConversationItem, keepBudget int) ([]model.ConversationItem, []model.ConversationItem) {
+	used, cut := 0, len(items)
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Summary {
+			break
+		}
+		tokens := w.countItem(items[i])
+		if !items[i].Pinned && used+tokens > keepBudget {
+			break
+		}
+		used += tokens
+		cut = i
+	}
+	return items[:cut], items[cut:]
+}
+
+func dropOverflow(w *contextWindow, items []model.ConversationItem, budget int) []model.ConversationItem {
+	total := w.countItems(items)
+	result := make([]model.ConversationItem, 0, len(items))
+	for i, item := range items {
+		// 置顶消息和摘要不丢弃，且至少保留最后一条，避免丢失上一轮的回复
+		if total > budget && !item.Pinned && !item.Summary && i < len(items)-1 {
+			total -= w.countItem(item)
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func (c *controller) summarizeHistory(
+	ctx context.Context,
+	provider *model.AIProvider,
+	account *model.AIAccount,
+	conversationId int64,
+	w *contextWindow,
+	items []model.ConversationItem,
+) (string, error) {
+	// 待总结的内容本身也不能超出预算，丢弃最早的消息，已有的摘要始终保留
+	items = dropOverflow(w, items, w.InputBudget-countTokens(w.Protocol, historySummaryPrompt))
+
+	inputItems := make([]map[string]interface{}, 0, len(items)+1)
+	for _, item := range items {
+		inputItems = append(inputItems, conversationInputItem(item))
+	}
+	inputItems = append(inputItems, conversationInputItem(newUserItem(historySummaryPrompt)))
+
+	endpoint, err := resolveAIEndpoint(provider)
+	if err != nil {
+		return "", err
+	}
+	startTime := time.Now()
+	discard := func(*types.AIStreamEvent) error { return nil }
+	raw, text, responseID, err := c.runProviderStream(withoutTools(ctx), provider.Protocol, endpoint, account.APIKey, account.ModelName, summaryMaxTokens, inputItems, discard)
+	// 摘要同样消耗 token，需要计入用量
+	c.recordResponseExecution(ctx, provider, conversationId, account.ModelName, "[history compaction]", text, responseID, raw, err, time.Since(startTime))
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func newUserItem(text string) model.ConversationItem {
+	return model.ConversationItem{
+		Role:    "user",
+		Content: []model.ConversationContent{{Type: "input_text", Text: text}},
+	}
+}
+
+func newSummaryItem(summary string) model.ConversationItem {
+	return model.ConversationItem{
+		Role:    "system",
+		Content: []model.ConversationContent{{Type: "input_text", Text: "以下是较早对话的摘要：\n" + summary}},
+		Summary: true,
+	}
+}
+
+// conversationInputItem 转换为发送给模型的输入项，去掉 pixiu 内部字段
+func conversationInputItem(item model.ConversationItem) map[string]interface{} {
+	content := make([]map[string]interface{}, 0, len(item.Content))
+	for _, part := range item.Content {
+		content = append(content, map[string]interface{}{"type": part.Type, "text": part.Text})
+	}
+	return map[string]interface{}{"role": item.Role, "content": content}
+}
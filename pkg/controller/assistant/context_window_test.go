@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestCountTokens(t *testing.T) {
+	if got := countTokens(ProtocolOpenAIResponses, strings.Repeat("a", 400)); got != 100 {
+		t.Errorf("expected 100 tokens for 400 ascii chars, got %d", got)
+	}
+	if got := countTokens(ProtocolOpenAIResponses, strings.Repeat("集", 100)); got != 100 {
+		t.Errorf("expected 100 tokens for 100 cjk chars, got %d", got)
+	}
+	text := "kubectl get pods -n default 查看容器状态"
+	if countTokens(ProtocolAnthropic, text) <= countTokens(ProtocolOpenAIResponses, text) {
+		t.Errorf("expected anthropic estimate to be more conservative than openai")
+	}
+	if countTokens("unknown", text) != countTokens(ProtocolOpenAIChat, text) {
+		t.Errorf("expected unknown protocol to fall back to openai_chat ratio")
+	}
+}
+
+func TestNewContextWindowRespectsMaxTokens(t *testing.T) {
+	provider := &model.AIProvider{Protocol: ProtocolOpenAIChat, MaxTokens: 4096, ContextWindow: 16384}
+	w := newContextWindow(provider, "", nil)
+	if w.InputBudget != 16384-4096 {
+		t.Errorf("expected budget to reserve max tokens for output, got %d", w.InputBudget)
+	}
+
+	provider.ContextWindow = 0
+	if w = newContextWindow(provider, "", nil); w.InputBudget != defaultContextWindow-4096 {
+		t.Errorf("expected default context window, got %d", w.InputBudget)
+	}
+
+	provider.ContextWindow = 4500
+	if w = newContextWindow(provider, strings.Repeat("a", 4000), nil); w.InputBudget != minInputBudget {
+		t.Errorf("expected minimum input budget, got %d", w.InputBudget)
+	}
+}
+
+func TestTrimToolOutputs(t *testing.T) {
+	long := strings.Repeat("x", 4000)
+	items := []map[string]interface{}{
+		{"role": "user", "content": "check pods"},
+		{"type": "function_call_output", "call_id": "1", "output": long},
+		{"role": "tool", "tool_call_id": "2", "content": long},
+		{"role": "user", "content": []map[string]interface{}{
+			{"type": "tool_result", "tool_use_id": "3", "content": long},
+		}},
+		{"role": "tool", "tool_call_id": "4", "content": long},
+	}
+
+	w := &contextWindow{Protocol: ProtocolOpenAIChat, InputBudget: 100000}
+	if trimmed := w.trimToolOutputs(items, 1); trimmed != 0 {
+		t.Fatalf("expected no trimming within budget, got %d", trimmed)
+	}
+
+	w.InputBudget = 2000
+	if trimmed := w.trimToolOutputs(items, 1); trimmed != 3 {
+		t.Fatalf("expected 3 stale outputs trimmed, got %d", trimmed)
+	}
+	for _, output := range []string{
+		items[1]["output"].(string),
+		items[2]["content"].(string),
+		items[3]["content"].([]map[string]interface{})[0]["content"].(string),
+	} {
+		if !strings.HasPrefix(output, strings.Repeat("x", staleToolOutputRunes)) || !strings.Contains(output, "已截断") {
+			t.Errorf("unexpected trimmed output %q", output[:20])
+		}
+	}
+	if items[4]["content"] != long {
+		t.Errorf("expected latest tool output to stay intact")
+	}
+}
+
+func TestCompactHistory(t *testing.T) {
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &payload)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `data: {"id":"chatcmpl-1","choices":[{"delta":{"content":"用户在排查 web 的 CrashLoopBackOff"}}]}`+"\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	var items []model.ConversationItem
+	for i := 0; i < 20; i++ {
+		items = append(items, newUserItem(fmt.Sprintf("第 %d 轮问题 %s", i, strings.Repeat("pod ", 200))))
+	}
+	items[2].Pinned = true
+	conversation := &model.Conversation{}
+	if err := conversation.SetHistoryItems(items); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &model.AIProvider{BaseURL: srv.URL, Protocol: ProtocolOpenAIChat, MaxTokens: 1024, ContextWindow: 6000}
+	account := &model.AIAccount{ModelName: "test", APIKey: "sk-test"}
+	c := &controller{client: srv.Client()}
+	ctx := withContextWindow(context.Background(), &contextWindow{Protocol: ProtocolOpenAIChat, InputBudget: 4000})
+
+	if err := c.compactHistory(ctx, provider, account, conversation, "继续", discardEvents); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload == nil {
+		t.Fatal("expected a summarization request")
+	}
+	if _, ok := payload["tools"]; ok {
+		t.Errorf("summarization request should not carry tools")
+	}
+	if payload["max_tokens"] != float64(summaryMaxTokens) {
+		t.Errorf("unexpected summarization max tokens %v", payload["max_tokens"])
+	}
+	messages, _ := payload["messages"].([]interface{})
+	last, _ := messages[len(messages)-1].(map[string]interface{})
+	if last["content"] != historySummaryPrompt {
+		t.Errorf("expected summary prompt as last message, got %v", last["content"])
+	}
+
+	compacted, err := conversation.HistoryItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compacted) >= len(items) || !compacted[0].Summary || !strings.Contains(compacted[0].Text(), "CrashLoopBackOff") {
+		t.Fatalf("expected summary as first item, got %d items", len(compacted))
+	}
+	if !compacted[1].Pinned || compacted[1].Text() != items[2].Text() {
+		t.Errorf("expected pinned item to be kept verbatim")
+	}
+	if compacted[len(compacted)-1].Text() != items[len(items)-1].Text() {
+		t.Errorf("expected latest item to be kept verbatim")
+	}
+	w := getContextWindow(ctx)
+	if w.countItems(compacted) > int(float64(w.InputBudget)*historyCompactRatio) {
+		t.Errorf("expected compacted history below threshold")
+	}
+
+	// 未超过阈值时不再压缩
+	payload = nil
+	if err = c.compactHistory(ctx, provider, account, conversation, "继续", discardEvents); err != nil {
+		t.Fatal(err)
+	}
+	if payload != nil {
+		t.Errorf("expected no summarization below threshold")
+	}
+}
+
+func TestDropOverflowKeepsPinnedAndSummary(t *testing.T) {
+	w := &contextWindow{Protocol: ProtocolOpenAIChat, InputBudget: 100}
+	items := []model.ConversationItem{
+		newSummaryItem("摘要"),
+		newUserItem(strings.Repeat("a", 400)),
+		{Role: "assistant", Content: []model.ConversationContent{{Type: "output_text", Text: strings.Repeat("b", 400)}}, Pinned: true},
+		newUserItem(strings.Repeat("c", 400)),
+		newUserItem("latest"),
+	}
+	result := dropOverflow(w, items, 50)
+	if len(result) != 3 || !result[0].Summary || !result[1].Pinned || result[2].Text() != "latest" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"fmt"
+
+	restclient "k8s.io/client-go/rest"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// authorizeCluster 校验当前用户是否可访问指定集群，助手的所有集群操作都需先经过该校验
+func (c *controller) authorizeCluster(ctx context.Context, clusterName string) (*model.User, *model.Cluster, error) {
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, nil, errors.ErrUnauthorized
+	}
+	object, err := c.cluster.AuthorizeClusterAccessByName(ctx, user, clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, object, nil
+}
+
+// resolveKubectlConfig 返回本次调用实际使用的集群凭据，保证助手看到的不超过用户在界面上可见的范围
+// 超管和自建集群使用集群自身凭据（授权生成的子集群本身即为 ServiceAccount 凭据），
+// 被授权用户使用覆盖本次调用的授权所对应的 ServiceAccount
+func (c *controller) resolveKubectlConfig(ctx context.Context, clusterName string, action *kubectlAction) (*restclient.Config, error) {
+	user, object, err := c.authorizeCluster(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == model.RoleRoot || object.UserId == user.Id {
+		return c.clusterConfig(ctx, object.Name)
+	}
+
+	perms, err := c.factory.Permission().List(ctx, db.WithUser(user.Id), db.WithOwnerCluster(object.Id))
+	if err != nil {
+		return nil, err
+	}
+	for i := range perms {
+		scope := &accessScope{}
+		scope.addPermission(&perms[i])
+		if scope.authorize(action) != nil {
+			continue
+		}
+		return c.permissionConfig(ctx, object, &perms[i])
+	}
+	return nil, fmt.Errorf("no permission on cluster %q covers this operation", clusterName)
+}
+
+// permissionConfig 优先使用授权生成的子集群凭据，子集群不可用时以主集群凭据模拟授权的 ServiceAccount
+func (c *controller) permissionConfig(ctx context.Context, object *model.Cluster, perm *model.Permission) (*restclient.Config, error) {
+	if perm.ClusterName != "" {
+		if cfg, err := c.clusterConfig(ctx, perm.ClusterName); err == nil {
+			return cfg, nil
+		}
+	}
+	if perm.SAName == "" || perm.SANamespace == "" {
+		return nil, fmt.Errorf("permission %q has no usable credentials", perm.Name)
+	}
+
+	cfg, err := c.clusterConfig(ctx, object.Name)
+	if err != nil {
+		return nil, err
+	}
+	cfg = restclient.CopyConfig(cfg)
+	cfg.Impersonate = restclient.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", perm.SANamespace, perm.SAName),
+	}
+	return cfg, nil
+}
+
+func (c *controller) clusterConfig(ctx context.Context, clusterName string) (*restclient.Config, error) {
+	clusterSet, err := c.cluster.GetClusterSetByName(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if clusterSet.Config == nil {
+		return nil, fmt.Errorf("cluster %q has empty in-memory config", clusterName)
+	}
+	return clusterSet.Config, nil
+}
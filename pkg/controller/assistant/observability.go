@@ -0,0 +1,701 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/controller/helm"
+	"github.com/caoyingjunz/pixiu/pkg/controller/plan"
+	"github.com/caoyingjunz/pixiu/pkg/datasource/query"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+const (
+	defaultQueryRange = time.Hour
+	maxQueryRange     = 7 * 24 * time.Hour
+	// 区间查询每条序列回传给模型的最大采样点数
+	maxSeriesPoints = 30
+	maxSeries       = 20
+	maxToolItems    = 50
+	// 单行日志截断长度，避免少数超长行占满工具输出
+	maxLogLineLength = 500
+	// 读取计划任务日志的最长时间，运行中的任务不会一直阻塞对话
+	taskLogReadTimeout = 10 * time.Second
+)
+
+// observabilityTools 是不依赖 kubectl、直接复用 Pixiu 已有子系统的只读工具
+func (c *controller) observabilityTools() []toolDefinition {
+	return []toolDefinition{
+		{
+			Name:        "prometheus_query",
+			Description: "Run a PromQL instant query against the cluster's Prometheus datasource.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":    stringSchema("Pixiu cluster name."),
+				"query":      stringSchema("PromQL expression."),
+				"datasource": stringSchema("Optional datasource name, defaults to the cluster's default Prometheus datasource."),
+			}, "cluster", "query"),
+			Handler: c.handlePrometheusQuery,
+		},
+		{
+			Name:        "prometheus_query_range",
+			Description: "Run a PromQL range query and return per-series min/max/last plus downsampled points.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":    stringSchema("Pixiu cluster name."),
+				"query":      stringSchema("PromQL expression."),
+				"range":      stringSchema("Lookback window ending now, for example 30m, 6h or 1d. Defaults to 1h, at most 7d."),
+				"step":       stringSchema("Optional resolution such as 30s or 5m. Defaults to range/60."),
+				"datasource": stringSchema("Optional datasource name, defaults to the cluster's default Prometheus datasource."),
+			}, "cluster", "query"),
+			Handler: c.handlePrometheusQueryRange,
+		},
+		{
+			Name:        "log_search",
+			Description: "Search logs through the cluster's Loki (LogQL) or Elasticsearch (query_string) log datasource, newest first.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":    stringSchema("Pixiu cluster name."),
+				"query":      stringSchema(`LogQL for Loki, e.g. {namespace="web"} |= "error"; query_string for Elasticsearch, e.g. kubernetes.namespace_name:web AND error.`),
+				"range":      stringSchema("Lookback window ending now, for example 15m or 2h. Defaults to 1h, at most 7d."),
+				"limit":      integerSchema("Maximum number of log lines, defaults to 50, at most 200."),
+				"index":      stringSchema("Elasticsearch index pattern, ignored for Loki."),
+				"datasource": stringSchema("Optional datasource name, defaults to the cluster's default log datasource."),
+			}, "cluster", "query"),
+			Handler: c.handleLogSearch,
+		},
+		{
+			Name:        "alert_events",
+			Description: "List alert events of a cluster, firing ones by default.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster": stringSchema("Pixiu cluster name."),
+				"status":  enumSchema("Event status filter, defaults to firing.", "firing", "recovered", "acked", "resolved", "all"),
+				"limit":   integerSchema("Maximum number of events, defaults to 50."),
+			}, "cluster"),
+			Handler: c.handleAlertEvents,
+		},
+		{
+			Name:        "alert_silences",
+			Description: "List alert silences that are enabled and currently in effect.",
+			Parameters:  objectSchema(map[string]interface{}{}),
+			Handler:     c.handleAlertSilences,
+		},
+		{
+			Name:        "helm_release_status",
+			Description: "Get the status of a Helm release: revision, chart, app version, status and notes summary.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":   stringSchema("Pixiu cluster name."),
+				"namespace": stringSchema("Release namespace."),
+				"name":      stringSchema("Release name."),
+			}, "cluster", "namespace", "name"),
+			Handler: c.handleHelmReleaseStatus,
+		},
+		{
+			Name:        "helm_release_history",
+			Description: "List the revision history of a Helm release, newest first.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":   stringSchema("Pixiu cluster name."),
+				"namespace": stringSchema("Release namespace."),
+				"name":      stringSchema("Release name."),
+			}, "cluster", "namespace", "name"),
+			Handler: c.handleHelmReleaseHistory,
+		},
+		{
+			Name:        "k8s_events",
+			Description: "Aggregate Kubernetes events of a workload together with its ReplicaSets/Jobs and Pods, newest first.",
+			Parameters: objectSchema(map[string]interface{}{
+				"cluster":   stringSchema("Pixiu cluster name."),
+				"namespace": stringSchema("Workload namespace."),
+				"name":      stringSchema("Workload name."),
+				"kind":      enumSchema("Workload kind.", "deployment", "statefulset", "daemonset", "job", "cronjob"),
+			}, "cluster", "namespace", "name", "kind"),
+			Handler: c.handleK8sEvents,
+		},
+		{
+			Name:        "plan_task_logs",
+			Description: "List the tasks of a Pixiu deployment plan, or read the log tail of one task when task_id is given.",
+			Parameters: objectSchema(map[string]interface{}{
+				"plan_id": integerSchema("Deployment plan id."),
+				"task_id": integerSchema("Optional task id; omit to list tasks and their status."),
+			}, "plan_id"),
+			Handler: c.handlePlanTaskLogs,
+		},
+	}
+}
+
+func (c *controller) handlePrometheusQuery(ctx context.Context, args map[string]interface{}) (string, error) {
+	clusterName, expr := stringArg(args, "cluster"), stringArg(args, "query")
+	if clusterName == "" || expr == "" {
+		return "", fmt.Errorf("cluster and query are required")
+	}
+	ds, err := c.clusterDatasource(ctx, clusterName, stringArg(args, "datasource"), model.DatasourceTypeAlert, model.DatasourceSubTypePrometheus)
+	if err != nil {
+		return "", err
+	}
+
+	samples, err := query.NewClient(c.factory).InstantQuery(ctx, ds, expr)
+	if err != nil {
+		return "", err
+	}
+	items := make([]map[string]interface{}, 0, len(samples))
+	for _, sample := range samples {
+		items = append(items, map[string]interface{}{"labels": sample.Labels, "value": sample.Value})
+	}
+	return marshalToolResult(map[string]interface{}{
+		"datasource": ds.Name,
+		"query":      expr,
+		"total":      len(samples),
+		"result":     limitItems(items, maxToolItems),
+	})
+}
+
+func (c *controller) handlePrometheusQueryRange(ctx context.Context, args map[string]interface{}) (string, error) {
+	clusterName, expr := stringArg(args, "cluster"), stringArg(args, "query")
+	if clusterName == "" || expr == "" {
+		return "", fmt.Errorf("cluster and query are required")
+	}
+	lookback, err := durationArg(args, "range", defaultQueryRange)
+	if err != nil {
+		return "", err
+	}
+	step, err := durationArg(args, "step", lookback/60)
+	if err != nil {
+		return "", err
+	}
+	if step < time.Second {
+		step = time.Second
+	}
+	ds, err := c.clusterDatasource(ctx, clusterName, stringArg(args, "datasource"), model.DatasourceTypeAlert, model.DatasourceSubTypePrometheus)
+	if err != nil {
+		return "", err
+	}
+
+	end := time.Now()
+	series, err := query.NewClient(c.factory).RangeQuery(ctx, ds, expr, end.Add(-lookback), end, step)
+	if err != nil {
+		return "", err
+	}
+	items := make([]map[string]interface{}, 0, len(series))
+	for _, s := range series {
+		items = append(items, summarizeSeries(s))
+	}
+	return marshalToolResult(map[string]interface{}{
+		"datasource": ds.Name,
+		"query":      expr,
+		"range":      lookback.String(),
+		"step":       step.String(),
+		"total":      len(series),
+		"series":     limitItems(items, maxSeries),
+	})
+}
+
+func (c *controller) handleLogSearch(ctx context.Context, args map[string]interface{}) (string, error) {
+	clusterName, expr := stringArg(args, "cluster"), stringArg(args, "query")
+	if clusterName == "" || expr == "" {
+		return "", fmt.Errorf("cluster and query are required")
+	}
+	lookback, err := durationArg(args, "range", defaultQueryRange)
+	if err != nil {
+		return "", err
+	}
+	limit := intArg(args, "limit", maxToolItems)
+	if limit <= 0 || limit > 200 {
+		limit = 200
+	}
+	ds, err := c.clusterDatasource(ctx, clusterName, stringArg(args, "datasource"), model.DatasourceTypeLog, model.DatasourceSubTypeLoki, model.DatasourceSubTypeES)
+	if err != nil {
+		return "", err
+	}
+
+	end := time.Now()
+	entries, err := query.NewClient(c.factory).SearchLogs(ctx, ds, query.LogQuery{
+		Query: expr,
+		Index: stringArg(args, "index"),
+		Start: end.Add(-lookback),
+		End:   end,
+		Limit: limit,
+	})
+	if err != nil {
+		return "", err
+	}
+	lines := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		line := entry.Line
+		if len(line) > maxLogLineLength {
+			line = line[:maxLogLineLength]
+		}
+		lines = append(lines, map[string]interface{}{
+			"time":   entry.Timestamp.Format(time.RFC3339),
+			"labels": entry.Labels,
+			"line":   line,
+		})
+	}
+	return marshalToolResult(map[string]interface{}{
+		"datasource": ds.Name,
+		"type":       ds.SubType,
+		"total":      len(entries),
+		"lines":      lines,
+	})
+}
+
+var alertEventStatuses = map[string]model.AlertEventStatus{
+	"firing":    model.AlertEventStatusFiring,
+	"recovered": model.AlertEventStatusRecovered,
+	"acked":     model.AlertEventStatusAcked,
+	"resolved":  model.AlertEventStatusResolved,
+}
+
+func (c *controller) handleAlertEvents(ctx context.Context, args map[string]interface{}) (string, error) {
+	clusterName := stringArg(args, "cluster")
+	if clusterName == "" {
+		return "", fmt.Errorf("cluster is required")
+	}
+	object, scope, err := c.clusterReadScope(ctx, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	opts := []db.Options{db.WithAlertClusterId(object.Id)}
+	status := stringArg(args, "status")
+	if status == "" {
+		status = "firing"
+	}
+	if status != "all" {
+		value, ok := alertEventStatuses[status]
+		if !ok {
+			return "", fmt.Errorf("unsupported alert event status %q", status)
+		}
+		opts = append(opts, db.WithAlertEventStatus(value))
+	}
+	limit := intArg(args, "limit", maxToolItems)
+	if limit <= 0 || limit > maxToolItems {
+		limit = maxToolItems
+	}
+	opts = append(opts, db.WithModifyOrderByDesc(), db.WithLimit(limit))
+
+	events, err := c.factory.Alert().Event().List(ctx, opts...)
+	if err != nil {
+		return "", err
+	}
+	items := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		// 命名空间级授权只能看到授权命名空间内的告警
+		if !scope.read.all && !scope.read.namespaces[event.ResourceNamespace] {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"id":            event.Id,
+			"rule":          event.RuleName,
+			"status":        event.Status,
+			"severity":      event.Severity,
+			"resource_type": event.ResourceType,
+			"resource":      event.ResourceName,
+			"namespace":     event.ResourceNamespace,
+			"value":         event.TriggerValue,
+			"expr":          event.TriggerExpr,
+			"labels":        event.Labels,
+			"started_at":    event.GmtCreate.Format(time.RFC3339),
+		})
+	}
+	return marshalToolResult(map[string]interface{}{"cluster": clusterName, "total": len(items), "events": items})
+}
+
+func (c *controller) handleAlertSilences(ctx context.Context, _ map[string]interface{}) (string, error) {
+	silences, err := c.factory.Alert().Silence().List(ctx, db.WithEnabled(true), db.WithModifyOrderByDesc())
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	items := make([]map[string]interface{}, 0)
+	for _, silence := range silences {
+		if now.Before(silence.StartsAt) || now.After(silence.EndsAt) {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"id":                silence.Id,
+			"name":              silence.Name,
+			"match_labels":      silence.MatchLabels,
+			"match_expressions": silence.MatchExpressions,
+			"starts_at":         silence.StartsAt.Format(time.RFC3339),
+			"ends_at":           silence.EndsAt.Format(time.RFC3339),
+			"created_by":        silence.CreatedBy,
+			"comment":           silence.Comment,
+		})
+	}
+	return marshalToolResult(map[string]interface{}{"total": len(items), "silences": limitItems(items, maxToolItems)})
+}
+
+func (c *controller) handleHelmReleaseStatus(ctx context.Context, args map[string]interface{}) (string, error) {
+	releases, name, err := c.helmReleases(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	rel, err := releases.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	result := summarizeRelease(rel)
+	if rel.Info != nil && rel.Info.Notes != "" {
+		result["notes"] = truncateToolOutput(rel.Info.Notes)
+	}
+	return marshalToolResult(result)
+}
+
+func (c *controller) handleHelmReleaseHistory(ctx context.Context, args map[string]interface{}) (string, error) {
+	releases, name, err := c.helmReleases(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	history, err := releases.History(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Version > history[j].Version })
+
+	items := make([]map[string]interface{}, 0, len(history))
+	for _, rel := range history {
+		items = append(items, summarizeRelease(rel))
+	}
+	return marshalToolResult(map[string]interface{}{"name": name, "revisions": limitItems(items, maxToolItems)})
+}
+
+// helmReleases 使用当前用户的凭据构造 helm 客户端，读取 release 需要命名空间内 secrets 的读权限
+func (c *controller) helmReleases(ctx context.Context, args map[string]interface{}) (helm.ReleaseInterface, string, error) {
+	clusterName, namespace, name := stringArg(args, "cluster"), stringArg(args, "namespace"), stringArg(args, "name")
+	if clusterName == "" || namespace == "" || name == "" {
+		return nil, "", fmt.Errorf("cluster, namespace and name are required")
+	}
+	cfg, err := c.resolveKubectlConfig(ctx, clusterName, &kubectlAction{
+		Verb:      "get",
+		Namespace: namespace,
+		Resources: []string{"secrets"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	settings := cli.New()
+	settings.SetNamespace(namespace)
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(client.NewHelmRESTClientGetter(cfg), namespace, "secrets", klog.Infof); err != nil {
+		return nil, "", err
+	}
+	return helm.NewReleases(actionConfig, settings, c.factory), name, nil
+}
+
+func (c *controller) handleK8sEvents(ctx context.Context, args map[string]interface{}) (string, error) {
+	clusterName, namespace := stringArg(args, "cluster"), stringArg(args, "namespace")
+	name, kind := stringArg(args, "name"), strings.ToLower(stringArg(args, "kind"))
+	if clusterName == "" || namespace == "" || name == "" || kind == "" {
+		return "", fmt.Errorf("cluster, namespace, name and kind are required")
+	}
+	if _, scope, err := c.clusterReadScope(ctx, clusterName); err != nil {
+		return "", err
+	} else if !scope.read.all && !scope.read.namespaces[namespace] {
+		return "", fmt.Errorf("no read permission on namespace %q", namespace)
+	}
+
+	events, err := c.cluster.AggregateEvents(ctx, clusterName, namespace, name, kind)
+	if err != nil {
+		return "", err
+	}
+	list := events.Items
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastTimestamp.After(list[j].LastTimestamp.Time)
+	})
+
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, event := range list {
+		items = append(items, map[string]interface{}{
+			"type":    event.Type,
+			"reason":  event.Reason,
+			"object":  strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			"message": event.Message,
+			"count":   event.Count,
+			"last":    event.LastTimestamp.Format(time.RFC3339),
+		})
+	}
+	return marshalToolResult(map[string]interface{}{"total": len(items), "events": limitItems(items, maxToolItems)})
+}
+
+func (c *controller) handlePlanTaskLogs(ctx context.Context, args map[string]interface{}) (string, error) {
+	planId := int64(intArg(args, "plan_id", 0))
+	if planId <= 0 {
+		return "", fmt.Errorf("plan_id is required")
+	}
+	planController := plan.NewPlan(c.cc, c.factory)
+	// 复用部署计划的访问校验
+	if _, err := planController.Get(ctx, planId); err != nil {
+		return "", err
+	}
+
+	taskId := int64(intArg(args, "task_id", 0))
+	if taskId <= 0 {
+		tasks, err := planController.ListTasks(ctx, planId)
+		if err != nil {
+			return "", err
+		}
+		items := make([]map[string]interface{}, 0, len(tasks))
+		for _, task := range tasks {
+			items = append(items, map[string]interface{}{
+				"id":      task.Id,
+				"name":    task.Name,
+				"action":  task.Action,
+				"status":  task.Status,
+				"message": task.Message,
+			})
+		}
+		return marshalToolResult(map[string]interface{}{"plan_id": planId, "tasks": items})
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, taskLogReadTimeout)
+	defer cancel()
+	w := newBufferResponseWriter()
+	if err := planController.WatchTaskLog(readCtx, planId, taskId, w, nil); err != nil && readCtx.Err() == nil {
+		return "", err
+	}
+	return marshalToolResult(map[string]interface{}{
+		"plan_id": planId,
+		"task_id": taskId,
+		"log":     tailToolOutput(w.buf.String()),
+	})
+}
+
+// clusterDatasource 选择集群下的数据源：指定名称优先，其次默认数据源，最后取第一个
+// 数据源查询不区分命名空间，因此要求集群级读权限
+func (c *controller) clusterDatasource(ctx context.Context, clusterName, name string, dsType model.DatasourceType, subTypes ...model.DatasourceSubType) (*model.Datasource, error) {
+	if _, scope, err := c.clusterReadScope(ctx, clusterName); err != nil {
+		return nil, err
+	} else if !scope.read.all {
+		return nil, fmt.Errorf("querying datasources requires cluster-wide read permission")
+	}
+
+	list, err := c.factory.Datasource().List(ctx, db.WithClusterName(clusterName), db.WithDatasourceType(dsType))
+	if err != nil {
+		return nil, err
+	}
+	var candidates []model.Datasource
+	for _, ds := range list {
+		for _, subType := range subTypes {
+			if ds.SubType == subType {
+				candidates = append(candidates, ds)
+				break
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("cluster %q has no %s datasource configured", clusterName, subTypes[0])
+	}
+	for i := range candidates {
+		if name != "" && candidates[i].Name == name {
+			return &candidates[i], nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("datasource %q not found in cluster %q", name, clusterName)
+	}
+	for i := range candidates {
+		if candidates[i].IsDefault {
+			return &candidates[i], nil
+		}
+	}
+	return &candidates[0], nil
+}
+
+// clusterReadScope 校验集群访问权限并返回读写范围
+func (c *controller) clusterReadScope(ctx context.Context, clusterName string) (*model.Cluster, *accessScope, error) {
+	user, object, err := c.authorizeCluster(ctx, clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	scope, err := c.resolveAccessScope(ctx, user, object)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scope.read.empty() {
+		return nil, nil, fmt.Errorf("no read permission on cluster %q", clusterName)
+	}
+	return object, scope, nil
+}
+
+func summarizeSeries(s query.MetricSeries) map[string]interface{} {
+	result := map[string]interface{}{"labels": s.Labels, "samples": len(s.Points)}
+	if len(s.Points) == 0 {
+		return result
+	}
+
+	var minValue, maxValue float64
+	for i, point := range s.Points {
+		v, _ := strconv.ParseFloat(point.Value, 64)
+		if i == 0 || v < minValue {
+			minValue = v
+		}
+		if i == 0 || v > maxValue {
+			maxValue = v
+		}
+	}
+	result["min"] = minValue
+	result["max"] = maxValue
+	result["last"] = s.Points[len(s.Points)-1].Value
+
+	stride := (len(s.Points) + maxSeriesPoints - 1) / maxSeriesPoints
+	points := make([][2]string, 0, maxSeriesPoints)
+	for i := 0; i < len(s.Points); i += stride {
+		points = append(points, [2]string{s.Points[i].Timestamp.Format(time.RFC3339), s.Points[i].Value})
+	}
+	result["points"] = points
+	return result
+}
+
+func summarizeRelease(rel *release.Release) map[string]interface{} {
+	result := map[string]interface{}{
+		"name":      rel.Name,
+		"namespace": rel.Namespace,
+		"revision":  rel.Version,
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		result["chart"] = rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+		result["app_version"] = rel.Chart.Metadata.AppVersion
+	}
+	if rel.Info != nil {
+		result["status"] = rel.Info.Status.String()
+		result["description"] = rel.Info.Description
+		result["updated"] = rel.Info.LastDeployed.Format(time.RFC3339)
+	}
+	return result
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func integerSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description}
+}
+
+func enumSchema(description string, values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description, "enum": values}
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	value, _ := args[key].(string)
+	return strings.TrimSpace(value)
+}
+
+func intArg(args map[string]interface{}, key string, defaultValue int) int {
+	switch value := args[key].(type) {
+	case float64:
+		return int(value)
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// durationArg 解析 30m、6h、1d 等时间窗口，上限为 maxQueryRange
+func durationArg(args map[string]interface{}, key string, defaultValue time.Duration) (time.Duration, error) {
+	text := stringArg(args, key)
+	if text == "" {
+		return defaultValue, nil
+	}
+
+	var (
+		d   time.Duration
+		err error
+	)
+	if strings.HasSuffix(text, "d") {
+		var days int
+		days, err = strconv.Atoi(strings.TrimSuffix(text, "d"))
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(text)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, text)
+	}
+	if d > maxQueryRange {
+		d = maxQueryRange
+	}
+	return d, nil
+}
+
+func limitItems(items []map[string]interface{}, limit int) []map[string]interface{} {
+	if len(items) <= limit {
+		return items
+	}
+	return items[:limit]
+}
+
+func marshalToolResult(result interface{}) (string, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// tailToolOutput 与 truncateToolOutput 相反，保留日志末尾
+func tailToolOutput(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= 4000 {
+		return text
+	}
+	return text[len(text)-4000:]
+}
+
+// bufferResponseWriter 把面向 HTTP 的日志输出收集到内存
+type bufferResponseWriter struct {
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func newBufferResponseWriter() *bufferResponseWriter {
+	return &bufferResponseWriter{header: http.Header{}}
+}
+
+func (w *bufferResponseWriter) Header() http.Header         { return w.header }
+func (w *bufferResponseWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+func (w *bufferResponseWriter) WriteHeader(int)             {}
+func (w *bufferResponseWriter) Flush()                      {}
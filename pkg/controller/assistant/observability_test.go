@@ -0,0 +1,260 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	clustercontroller "github.com/caoyingjunz/pixiu/pkg/controller/cluster"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// fakePermissions 按用户返回其在集群上的授权
+type fakePermissions struct {
+	db.PermissionInterface
+	items map[int64][]model.Permission
+}
+
+func (p *fakePermissions) List(ctx context.Context, _ ...db.Options) ([]model.Permission, error) {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return nil, nil
+	}
+	return p.items[user.Id], nil
+}
+
+type fakeDatasources struct {
+	db.DatasourceInterface
+	items []model.Datasource
+}
+
+func (d *fakeDatasources) List(context.Context, ...db.Options) ([]model.Datasource, error) {
+	return d.items, nil
+}
+
+type fakeAlert struct {
+	db.AlertInterface
+	events *fakeAlertEvents
+}
+
+func (a *fakeAlert) Event() db.AlertEventInterface { return a.events }
+
+type fakeAlertEvents struct {
+	db.AlertEventInterface
+	items []model.AlertEvent
+}
+
+func (e *fakeAlertEvents) List(context.Context, ...db.Options) ([]model.AlertEvent, error) {
+	return e.items, nil
+}
+
+// fakeCluster 只暴露一个由用户 1 创建的集群
+type fakeCluster struct {
+	clustercontroller.Interface
+	object *model.Cluster
+	events *v1.EventList
+}
+
+func (c *fakeCluster) AuthorizeClusterAccessByName(_ context.Context, _ *model.User, clusterName string) (*model.Cluster, error) {
+	if clusterName != c.object.Name {
+		return nil, apierrors.ErrClusterNotFound
+	}
+	return c.object, nil
+}
+
+func (c *fakeCluster) AggregateEvents(context.Context, string, string, string, string) (*v1.EventList, error) {
+	return c.events, nil
+}
+
+const (
+	ownerId    = 1
+	rootId     = 2
+	readonlyId = 3
+	customId   = 4
+	strangerId = 5
+)
+
+func newObservabilityController() *controller {
+	object := &model.Cluster{Name: "prod"}
+	object.Id = 100
+	object.UserId = ownerId
+
+	now := time.Now()
+	events := &v1.EventList{Items: []v1.Event{
+		{Reason: "Pulled", InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web-0"}, LastTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+		{Reason: "BackOff", InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web-0"}, LastTimestamp: metav1.NewTime(now)},
+	}}
+
+	return &controller{
+		cluster: &fakeCluster{object: object, events: events},
+		factory: &fakeFactory{
+			permissions: &fakePermissions{items: map[int64][]model.Permission{
+				readonlyId: {{PType: model.PermissionPTypeReadonly}},
+				customId:   {{PType: model.PermissionPTypeCustom, TargetNamespaces: `["web"]`}},
+			}},
+			datasources: &fakeDatasources{items: []model.Datasource{
+				{Name: "loki", SubType: model.DatasourceSubTypeLoki},
+				{Name: "prom-a", SubType: model.DatasourceSubTypePrometheus},
+				{Name: "prom-b", SubType: model.DatasourceSubTypePrometheus, IsDefault: true},
+			}},
+			alert: &fakeAlert{events: &fakeAlertEvents{items: []model.AlertEvent{
+				{RuleName: "web-down", ResourceNamespace: "web"},
+				{RuleName: "dns-down", ResourceNamespace: "kube-system"},
+				{RuleName: "node-down"},
+			}}},
+		},
+	}
+}
+
+func observabilityContext(id int64) context.Context {
+	role := model.RoleUser
+	if id == rootId {
+		role = model.RoleRoot
+	}
+	return userContext(newUser(id, role))
+}
+
+func TestClusterDatasource(t *testing.T) {
+	cases := []struct {
+		name     string
+		userId   int64
+		cluster  string
+		ds       string
+		subType  model.DatasourceSubType
+		expected string
+		denied   bool
+	}{
+		{name: "owner gets default", userId: ownerId, cluster: "prod", subType: model.DatasourceSubTypePrometheus, expected: "prom-b"},
+		{name: "root gets named", userId: rootId, cluster: "prod", ds: "prom-a", subType: model.DatasourceSubTypePrometheus, expected: "prom-a"},
+		{name: "readonly grantee queries", userId: readonlyId, cluster: "prod", subType: model.DatasourceSubTypeLoki, expected: "loki"},
+		{name: "namespaced grantee is denied", userId: customId, cluster: "prod", subType: model.DatasourceSubTypePrometheus, denied: true},
+		{name: "user without permission is denied", userId: strangerId, cluster: "prod", subType: model.DatasourceSubTypePrometheus, denied: true},
+		{name: "unknown cluster", userId: rootId, cluster: "dev", subType: model.DatasourceSubTypePrometheus, denied: true},
+		{name: "unknown datasource", userId: rootId, cluster: "prod", ds: "prom-c", subType: model.DatasourceSubTypePrometheus, denied: true},
+		{name: "missing sub type", userId: rootId, cluster: "prod", subType: model.DatasourceSubTypeES, denied: true},
+	}
+
+	c := newObservabilityController()
+	for _, tc := range cases {
+		ds, err := c.clusterDatasource(observabilityContext(tc.userId), tc.cluster, tc.ds, model.DatasourceTypeAlert, tc.subType)
+		if tc.denied {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", tc.name, ds.Name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if ds.Name != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, ds.Name)
+		}
+	}
+}
+
+func TestHandleAlertEvents(t *testing.T) {
+	cases := []struct {
+		name   string
+		userId int64
+		rules  []string
+		denied bool
+	}{
+		{name: "owner sees all", userId: ownerId, rules: []string{"web-down", "dns-down", "node-down"}},
+		{name: "readonly grantee sees all", userId: readonlyId, rules: []string{"web-down", "dns-down", "node-down"}},
+		{name: "namespaced grantee sees own namespace", userId: customId, rules: []string{"web-down"}},
+		{name: "user without permission is denied", userId: strangerId, denied: true},
+	}
+
+	c := newObservabilityController()
+	for _, tc := range cases {
+		out, err := c.handleAlertEvents(observabilityContext(tc.userId), map[string]interface{}{"cluster": "prod", "status": "all"})
+		if tc.denied {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		var result struct {
+			Events []struct {
+				Rule string `json:"rule"`
+			} `json:"events"`
+		}
+		if err = json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("%s: invalid output %s: %v", tc.name, out, err)
+		}
+		var rules []string
+		for _, event := range result.Events {
+			rules = append(rules, event.Rule)
+		}
+		if strings.Join(rules, ",") != strings.Join(tc.rules, ",") {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.rules, rules)
+		}
+	}
+
+	if _, err := c.handleAlertEvents(observabilityContext(ownerId), map[string]interface{}{"cluster": "prod", "status": "unknown"}); err == nil {
+		t.Errorf("expected unsupported status to be rejected")
+	}
+}
+
+func TestHandleK8sEvents(t *testing.T) {
+	cases := []struct {
+		name      string
+		userId    int64
+		namespace string
+		denied    bool
+	}{
+		{name: "owner reads any namespace", userId: ownerId, namespace: "kube-system"},
+		{name: "namespaced grantee reads own namespace", userId: customId, namespace: "web"},
+		{name: "namespaced grantee cannot read other namespace", userId: customId, namespace: "kube-system", denied: true},
+		{name: "user without permission is denied", userId: strangerId, namespace: "web", denied: true},
+	}
+
+	c := newObservabilityController()
+	for _, tc := range cases {
+		out, err := c.handleK8sEvents(observabilityContext(tc.userId), map[string]interface{}{
+			"cluster": "prod", "namespace": tc.namespace, "name": "web", "kind": "Deployment",
+		})
+		if tc.denied {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		// 最新的事件排在最前
+		if !strings.Contains(out, `"total":2`) || strings.Index(out, "BackOff") > strings.Index(out, "Pulled") {
+			t.Errorf("%s: unexpected output %s", tc.name, out)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+type ollamaStreamResult struct {
+	Raw              map[string]interface{}
+	Text             string
+	ToolCalls        []responseToolCall
+	AssistantMessage map[string]interface{}
+	Usage            responseUsage
+}
+
+// runOllamaLoopStream 对接 Ollama /api/chat，适用于离线环境部署的本地模型
+func (c *controller) runOllamaLoopStream(
+	ctx context.Context,
+	endpoint, apiKey, modelName string,
+	maxTokens int,
+	inputItems []map[string]interface{},
+	emit func(*types.AIStreamEvent) error,
+) (map[string]interface{}, string, string, error) {
+	messages := responseInputToChatMessages(inputItems)
+	messages = append([]map[string]interface{}{{"role": "system", "content": c.defaultAIInstructions()}}, messages...)
+	tools := toChatCompletionsTools(c.requestTools(ctx))
+
+	var usage responseUsage
+	for i := 0; i < 8; i++ {
+		_ = emit(&types.AIStreamEvent{Type: "status", Stage: "model", Message: "AI is generating a response", Model: modelName})
+		result, err := c.callOllamaStream(ctx, endpoint, apiKey, modelName, maxTokens, messages, tools, emit)
+		if err != nil {
+			return usageRaw(usage), "", "", err
+		}
+		usage.add(result.Usage)
+		if len(result.ToolCalls) == 0 {
+			result.Raw["usage"] = usage.toRaw()
+			// Ollama 不返回响应 ID
+			return result.Raw, result.Text, "", nil
+		}
+		if err = c.checkRoundQuota(ctx, usage); err != nil {
+			return usageRaw(usage), "", "", err
+		}
+
+		messages = append(messages, result.AssistantMessage)
+		for _, call := range result.ToolCalls {
+			emitToolEvent(emit, "tool_start", "tool", describeToolCall(call), call.CallID, call.Name, call.Arguments, "")
+			output, toolErr := c.executeTool(ctx, call.CallID, call.Name, call.Arguments)
+			if toolErr != nil {
+				output = fmt.Sprintf(`{"error":%q}`, toolErr.Error())
+				emitToolEvent(emit, "tool_result", "tool", fmt.Sprintf("tool %s failed", call.Name), call.CallID, call.Name, call.Arguments, toolErr.Error())
+			} else {
+				emitToolEvent(emit, "tool_result", "tool", fmt.Sprintf("tool %s completed", call.Name), call.CallID, call.Name, call.Arguments, output)
+			}
+			messages = append(messages, map[string]interface{}{
+				"role":      "tool",
+				"tool_name": call.Name,
+				"content":   output,
+			})
+		}
+		trimToolOutputs(ctx, messages, len(result.ToolCalls), emit)
+	}
+
+	return usageRaw(usage), "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
+}
+
+func (c *controller) callOllamaStream(
+	ctx context.Context,
+	endpoint, apiKey, modelName string,
+	maxTokens int,
+	messages, tools []map[string]interface{},
+	emit func(*types.AIStreamEvent) error,
+) (*ollamaStreamResult, error) {
+	payload := map[string]interface{}{
+		"model":    modelName,
+		"messages": messages,
+		"stream":   true,
+		"options":  map[string]interface{}{"num_predict": maxTokens},
+	}
+	if len(tools) > 0 {
+		payload["tools"] = tools
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apierrors.ErrServerInternal
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, apierrors.ErrServerInternal
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// 本地 Ollama 默认无鉴权，前置网关时才需要 API Key
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		klog.Errorf("failed to request ai endpoint %s: %v", endpoint, err)
+		return nil, apierrors.NewError(fmt.Errorf("request ai endpoint failed: %v", err), http.StatusBadGateway)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, apierrors.NewError(fmt.Errorf("ai endpoint returned status %d: %s", resp.StatusCode, string(responseBody)), http.StatusBadGateway)
+	}
+
+	result, err := parseOllamaNDJSON(resp.Body, emit)
+	if err != nil {
+		klog.Errorf("failed to parse ollama chat stream: %v", err)
+		return nil, apierrors.NewError(fmt.Errorf("invalid ai response"), http.StatusBadGateway)
+	}
+	return result, nil
+}
+
+// parseOllamaNDJSON 解析 /api/chat 的流式响应，每行一个 JSON 对象，done 为 true 的最后一行携带用量
+func parseOllamaNDJSON(reader io.Reader, emit func(*types.AIStreamEvent) error) (*ollamaStreamResult, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	result := &ollamaStreamResult{}
+	var textBuilder strings.Builder
+	var rawToolCalls []interface{}
+	var modelName, doneReason string
+	seenChunk := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var chunk map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			continue
+		}
+		seenChunk = true
+		if message, _ := chunk["error"].(string); message != "" {
+			return nil, errors.New(message)
+		}
+		if name, _ := chunk["model"].(string); name != "" {
+			modelName = name
+		}
+
+		message, _ := chunk["message"].(map[string]interface{})
+		if content, _ := message["content"].(string); content != "" {
+			textBuilder.WriteString(content)
+			_ = emit(&types.AIStreamEvent{Type: "delta", Stage: "message", Delta: content})
+		}
+		// 工具调用在单个 chunk 中完整返回，arguments 为对象而非字符串
+		toolCalls, _ := message["tool_calls"].([]interface{})
+		for _, value := range toolCalls {
+			toolCall, _ := value.(map[string]interface{})
+			function, _ := toolCall["function"].(map[string]interface{})
+			name, _ := function["name"].(string)
+			if name == "" {
+				continue
+			}
+			arguments := "{}"
+			if args, ok := function["arguments"]; ok && args != nil {
+				encoded, err := json.Marshal(args)
+				if err != nil {
+					return nil, fmt.Errorf("invalid tool arguments for %s: %v", name, err)
+				}
+				arguments = string(encoded)
+			}
+			callID, _ := toolCall["id"].(string)
+			if callID == "" {
+				callID = fmt.Sprintf("call_%d", len(result.ToolCalls))
+			}
+			result.ToolCalls = append(result.ToolCalls, responseToolCall{CallID: callID, Name: name, Arguments: arguments})
+			rawToolCalls = append(rawToolCalls, toolCall)
+		}
+
+		if done, _ := chunk["done"].(bool); done {
+			doneReason, _ = chunk["done_reason"].(string)
+			result.Usage.InputTokens = toInt64(chunk["prompt_eval_count"])
+			result.Usage.OutputTokens = toInt64(chunk["eval_count"])
+			result.Usage.TotalTokens = result.Usage.InputTokens + result.Usage.OutputTokens
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !seenChunk {
+		return nil, fmt.Errorf("empty ndjson response")
+	}
+
+	result.Text = textBuilder.String()
+	result.AssistantMessage = map[string]interface{}{"role": "assistant", "content": result.Text}
+	if len(rawToolCalls) > 0 {
+		result.AssistantMessage["tool_calls"] = rawToolCalls
+	}
+	result.Raw = map[string]interface{}{
+		"model":       modelName,
+		"message":     result.AssistantMessage,
+		"done_reason": doneReason,
+		"usage":       result.Usage.toRaw(),
+	}
+	return result, nil
+}
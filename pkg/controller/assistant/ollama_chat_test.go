@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseOllamaNDJSON(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"model":"qwen2.5","message":{"role":"assistant","content":"先看"},"done":false}`,
+		`{"model":"qwen2.5","message":{"role":"assistant","content":"日志"},"done":false}`,
+		`{"model":"qwen2.5","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"log_search","arguments":{"cluster":"dev","query":"error"}}}]},"done":false}`,
+		`{"model":"qwen2.5","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":120,"eval_count":30}`,
+	}, "\n")
+
+	result, err := parseOllamaNDJSON(strings.NewReader(stream), discardEvents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "先看日志" {
+		t.Fatalf("unexpected text %q", result.Text)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].CallID != "call_0" || result.ToolCalls[0].Name != "log_search" {
+		t.Fatalf("unexpected tool calls %+v", result.ToolCalls)
+	}
+	var args map[string]interface{}
+	if err = json.Unmarshal([]byte(result.ToolCalls[0].Arguments), &args); err != nil || args["query"] != "error" {
+		t.Fatalf("unexpected tool arguments %q", result.ToolCalls[0].Arguments)
+	}
+
+	usage := extractResponseUsage(result.Raw)
+	if usage.InputTokens != 120 || usage.OutputTokens != 30 || usage.TotalTokens != 150 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestParseOllamaNDJSONError(t *testing.T) {
+	if _, err := parseOllamaNDJSON(strings.NewReader(`{"error":"model not found"}`), discardEvents); err == nil || err.Error() != "model not found" {
+		t.Fatalf("expected model error, got %v", err)
+	}
+}
+
+func TestRunOllamaLoopStream(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+
+		mu.Lock()
+		requests = append(requests, payload)
+		round := len(requests)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if round == 1 {
+			_, _ = io.WriteString(w, strings.Join([]string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"missing_tool","arguments":{}}}]},"done":false}`,
+				`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":50,"eval_count":10}`,
+			}, "\n"))
+			return
+		}
+		_, _ = io.WriteString(w, strings.Join([]string{
+			`{"message":{"role":"assistant","content":"工具不存在"},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":80,"eval_count":6}`,
+		}, "\n"))
+	}))
+	defer srv.Close()
+
+	c := &controller{client: srv.Client()}
+	inputItems := []map[string]interface{}{
+		{"role": "user", "content": []map[string]interface{}{{"type": "input_text", "text": "查一下日志"}}},
+	}
+	raw, text, _, err := c.runOllamaLoopStream(context.Background(), srv.URL, "", "qwen2.5", 1024, inputItems, discardEvents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "工具不存在" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	usage := extractResponseUsage(raw)
+	if usage.InputTokens != 130 || usage.OutputTokens != 16 || usage.TotalTokens != 146 {
+		t.Fatalf("unexpected accumulated usage %+v", usage)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	options, _ := requests[0]["options"].(map[string]interface{})
+	if options["num_predict"] != float64(1024) {
+		t.Fatalf("unexpected options %v", options)
+	}
+	messages, _ := requests[1]["messages"].([]interface{})
+	last, _ := messages[len(messages)-1].(map[string]interface{})
+	if last["role"] != "tool" || last["tool_name"] != "missing_tool" || !strings.Contains(last["content"].(string), "error") {
+		t.Fatalf("unexpected tool message %v", last)
+	}
+}
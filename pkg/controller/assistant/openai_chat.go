@@ -58,17 +58,23 @@ func (c *controller) runChatCompletionsLoopStream(
 ) (map[string]interface{}, string, string, error) {
 	messages := responseInputToChatMessages(inputItems)
 	messages = append([]map[string]interface{}{{"role": "system", "content": c.defaultAIInstructions()}}, messages...)
-	tools := toChatCompletionsTools(c.buildTools())
+	tools := toChatCompletionsTools(c.requestTools(ctx))
 
+	var usage responseUsage
 	for i := 0; i < 8; i++ {
 		_ = emit(&types.AIStreamEvent{Type: "status", Stage: "model", Message: "AI is generating a response", Model: modelName})
 		result, err := c.callChatCompletionsStream(ctx, endpoint, apiKey, modelName, maxTokens, messages, tools, emit)
 		if err != nil {
-			return nil, "", "", err
+			return usageRaw(usage), "", "", err
 		}
+		usage.add(extractResponseUsage(result.Raw))
 		if len(result.ToolCalls) == 0 {
+			result.Raw["usage"] = usage.toRaw()
 			return result.Raw, result.Text, result.ResponseId, nil
 		}
+		if err = c.checkRoundQuota(ctx, usage); err != nil {
+			return usageRaw(usage), "", "", err
+		}
 
 		messages = append(messages, result.AssistantMessage)
 		for _, call := range result.ToolCalls {
@@ -86,9 +92,10 @@ func (c *controller) runChatCompletionsLoopStream(
 				"content":      output,
 			})
 		}
+		trimToolOutputs(ctx, messages, len(result.ToolCalls), emit)
 	}
 
-	return nil, "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
+	return usageRaw(usage), "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
 }
 
 func (c *controller) callChatCompletionsStream(
@@ -103,6 +110,8 @@ func (c *controller) callChatCompletionsStream(
 		"messages":   messages,
 		"stream":     true,
 		"max_tokens": maxTokens,
+		// 流式响应默认不返回 usage，需显式开启才能在最后一个 chunk 中拿到用量用于计费与配额
+		"stream_options": map[string]interface{}{"include_usage": true},
 	}
 	if len(tools) > 0 {
 		payload["tools"] = tools
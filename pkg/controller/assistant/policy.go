@@ -0,0 +1,550 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// kubectlVerbRule 描述一个 kubectl 子命令的风险等级
+type kubectlVerbRule struct {
+	// 会修改集群状态，执行前需要用户审批
+	mutating bool
+	// 操作集群级对象，命名空间级授权不可用
+	clusterScoped bool
+	// 不读取任何命名空间对象，如 version、explain
+	namespaceless bool
+}
+
+// 未列出的子命令（attach、cp、port-forward、proxy、edit、config、plugin 等）一律拒绝
+var kubectlVerbRules = map[string]kubectlVerbRule{
+	"get":           {},
+	"describe":      {},
+	"logs":          {},
+	"top":           {},
+	"events":        {},
+	"wait":          {},
+	"explain":       {namespaceless: true},
+	"api-resources": {namespaceless: true},
+	"api-versions":  {namespaceless: true},
+	"version":       {namespaceless: true},
+	"cluster-info":  {clusterScoped: true},
+
+	"apply":     {mutating: true},
+	"create":    {mutating: true},
+	"delete":    {mutating: true},
+	"patch":     {mutating: true},
+	"replace":   {mutating: true},
+	"scale":     {mutating: true},
+	"autoscale": {mutating: true},
+	"label":     {mutating: true},
+	"annotate":  {mutating: true},
+	"set":       {mutating: true},
+	"expose":    {mutating: true},
+	"run":       {mutating: true},
+	"exec":      {mutating: true},
+	"debug":     {mutating: true},
+
+	"certificate": {mutating: true, clusterScoped: true},
+	"taint":       {mutating: true, clusterScoped: true},
+	"drain":       {mutating: true, clusterScoped: true},
+	"cordon":      {mutating: true, clusterScoped: true},
+	"uncordon":    {mutating: true, clusterScoped: true},
+}
+
+// 风险由二级子命令决定的命令
+var kubectlSubVerbRules = map[string]map[string]kubectlVerbRule{
+	"auth": {
+		"can-i":     {namespaceless: true},
+		"whoami":    {namespaceless: true},
+		"reconcile": {mutating: true},
+	},
+	"rollout": {
+		"status":  {},
+		"history": {},
+		"restart": {mutating: true},
+		"undo":    {mutating: true},
+		"pause":   {mutating: true},
+		"resume":  {mutating: true},
+	},
+	"set": {
+		"env":            {mutating: true},
+		"image":          {mutating: true},
+		"resources":      {mutating: true},
+		"selector":       {mutating: true},
+		"serviceaccount": {mutating: true},
+		"subject":        {mutating: true},
+	},
+}
+
+// 会替换凭据、目标集群或读取本地文件的参数，助手不允许使用
+var deniedKubectlFlags = map[string]bool{
+	"kubeconfig":               true,
+	"server":                   true,
+	"s":                        true,
+	"token":                    true,
+	"as":                       true,
+	"as-group":                 true,
+	"as-uid":                   true,
+	"context":                  true,
+	"cluster":                  true,
+	"user":                     true,
+	"username":                 true,
+	"password":                 true,
+	"client-certificate":       true,
+	"client-key":               true,
+	"certificate-authority":    true,
+	"insecure-skip-tls-verify": true,
+	"tls-server-name":          true,
+	"f":                        true,
+	"filename":                 true,
+	"k":                        true,
+	"kustomize":                true,
+	"follow":                   true,
+}
+
+// 需要携带取值的参数，用于跳过参数值定位位置参数
+var kubectlValueFlags = map[string]bool{
+	"n":                            true,
+	"namespace":                    true,
+	"o":                            true,
+	"output":                       true,
+	"l":                            true,
+	"selector":                     true,
+	"field-selector":               true,
+	"L":                            true,
+	"label-columns":                true,
+	"chunk-size":                   true,
+	"field-manager":                true,
+	"c":                            true,
+	"container":                    true,
+	"containers":                   true,
+	"tail":                         true,
+	"since":                        true,
+	"since-time":                   true,
+	"limit-bytes":                  true,
+	"max-log-requests":             true,
+	"pod-running-timeout":          true,
+	"sort-by":                      true,
+	"template":                     true,
+	"type":                         true,
+	"image":                        true,
+	"image-pull-policy":            true,
+	"replicas":                     true,
+	"current-replicas":             true,
+	"resource-version":             true,
+	"for":                          true,
+	"timeout":                      true,
+	"raw":                          true,
+	"subresource":                  true,
+	"grace-period":                 true,
+	"to-revision":                  true,
+	"revision":                     true,
+	"patch":                        true,
+	"api-group":                    true,
+	"verbs":                        true,
+	"target":                       true,
+	"profile":                      true,
+	"copy-to":                      true,
+	"set-image":                    true,
+	"e":                            true,
+	"env":                          true,
+	"from-literal":                 true,
+	"labels":                       true,
+	"port":                         true,
+	"protocol":                     true,
+	"target-port":                  true,
+	"name":                         true,
+	"restart":                      true,
+	"limits":                       true,
+	"requests":                     true,
+	"min":                          true,
+	"max":                          true,
+	"cpu-percent":                  true,
+	"pod-selector":                 true,
+	"skip-wait-for-delete-timeout": true,
+}
+
+// 不携带取值的开关参数；kubectl 中可选取值的参数（如 --dry-run、--cascade）只能以 --name=value 形式传值。
+// 既不在此处也不在 kubectlValueFlags 中的参数无法确定是否携带取值，会导致位置参数被误判，一律拒绝
+var kubectlBoolFlags = map[string]bool{
+	"h":                           true,
+	"help":                        true,
+	"show-labels":                 true,
+	"show-kind":                   true,
+	"show-managed-fields":         true,
+	"no-headers":                  true,
+	"ignore-not-found":            true,
+	"allow-missing-template-keys": true,
+	"output-watch-events":         true,
+	"R":                           true,
+	"recursive":                   true,
+	"p":                           true,
+	"previous":                    true,
+	"timestamps":                  true,
+	"all-containers":              true,
+	"prefix":                      true,
+	"ignore-errors":               true,
+	"i":                           true,
+	"stdin":                       true,
+	"t":                           true,
+	"tty":                         true,
+	"q":                           true,
+	"quiet":                       true,
+	"attach":                      true,
+	"rm":                          true,
+	"command":                     true,
+	"share-processes":             true,
+	"same-node":                   true,
+	"replace":                     true,
+	"dry-run":                     true,
+	"validate":                    true,
+	"cascade":                     true,
+	"namespaced":                  true,
+	"cached":                      true,
+	"wait":                        true,
+	"force":                       true,
+	"now":                         true,
+	"all":                         true,
+	"overwrite":                   true,
+	"record":                      true,
+	"local":                       true,
+	"list":                        true,
+	"resolve":                     true,
+	"server-side":                 true,
+	"force-conflicts":             true,
+	"ignore-daemonsets":           true,
+	"delete-emptydir-data":        true,
+	"disable-eviction":            true,
+	"containers-only":             true,
+	"sum":                         true,
+	"use-protocol-buffers":        true,
+}
+
+// 允许的 -o/--output 格式，*-file 等从 Pixiu 服务端读取模板文件的格式一律拒绝；
+// 带模板的格式只能以 format=<内联模板> 形式使用
+var kubectlOutputFormats = map[string]bool{
+	"json":             true,
+	"yaml":             true,
+	"wide":             true,
+	"name":             true,
+	"go-template":      true,
+	"template":         true,
+	"jsonpath":         true,
+	"jsonpath-as-json": true,
+	"custom-columns":   true,
+}
+
+// checkKubectlOutput 校验输出格式，kubectl 在 Pixiu 服务端执行，文件类模板会读取服务端任意文件
+func checkKubectlOutput(value string) error {
+	format := value
+	if idx := strings.Index(value, "="); idx >= 0 {
+		format = value[:idx]
+	}
+	if !kubectlOutputFormats[strings.ToLower(format)] {
+		return fmt.Errorf("kubectl output format %q is not allowed", value)
+	}
+	return nil
+}
+
+// 集群级资源，命名空间级授权无法访问
+var clusterScopedResources = map[string]bool{
+	"node": true, "nodes": true, "no": true,
+	"namespace": true, "namespaces": true, "ns": true,
+	"persistentvolume": true, "persistentvolumes": true, "pv": true,
+	"storageclass": true, "storageclasses": true, "sc": true,
+	"clusterrole": true, "clusterroles": true,
+	"clusterrolebinding": true, "clusterrolebindings": true,
+	"customresourcedefinition": true, "customresourcedefinitions": true, "crd": true, "crds": true,
+	"mutatingwebhookconfiguration": true, "mutatingwebhookconfigurations": true,
+	"validatingwebhookconfiguration": true, "validatingwebhookconfigurations": true,
+	"priorityclass": true, "priorityclasses": true, "pc": true,
+	"certificatesigningrequest": true, "certificatesigningrequests": true, "csr": true,
+	"apiservice": true, "apiservices": true,
+	"ingressclass": true, "ingressclasses": true,
+	"runtimeclass": true, "runtimeclasses": true,
+	"csidriver": true, "csidrivers": true,
+	"csinode": true, "csinodes": true,
+	"volumeattachment": true, "volumeattachments": true,
+	"componentstatus": true, "componentstatuses": true, "cs": true,
+}
+
+// kubectlAction 是解析后的一次 kubectl 调用
+type kubectlAction struct {
+	Verb          string
+	SubVerb       string
+	Namespace     string
+	AllNamespaces bool
+	Raw           bool
+	Resources     []string
+	kubectlVerbRule
+}
+
+// parseKubectlAction 解析 kubectl 参数并按子命令分级，无法识别的调用直接拒绝
+func parseKubectlAction(args []string) (*kubectlAction, error) {
+	action := &kubectlAction{}
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "--" {
+			// 之后的内容交给容器内命令，不再解析
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := splitKubectlFlag(arg)
+		if deniedKubectlFlags[name] {
+			return nil, fmt.Errorf("kubectl flag %q is not allowed", arg)
+		}
+		switch name {
+		case "A", "all-namespaces":
+			action.AllNamespaces = !hasValue || value != "false"
+			continue
+		}
+
+		takesValue := kubectlValueFlags[name] || (name == "p" && len(positional) > 0 && positional[0] == "patch")
+		if !takesValue {
+			if !kubectlBoolFlags[name] {
+				return nil, fmt.Errorf("kubectl flag %q is not supported", arg)
+			}
+			// -it 等合并的短开关，每一位都必须是已知开关
+			if hasValue && !strings.HasPrefix(arg, "--") && !strings.Contains(arg, "=") {
+				for _, r := range value {
+					if string(r) == "A" || deniedKubectlFlags[string(r)] || !kubectlBoolFlags[string(r)] {
+						return nil, fmt.Errorf("kubectl flag %q is not supported", arg)
+					}
+				}
+			}
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = strings.TrimSpace(args[i])
+		}
+		switch name {
+		case "n", "namespace":
+			action.Namespace = value
+		case "o", "output":
+			if err := checkKubectlOutput(value); err != nil {
+				return nil, err
+			}
+		case "raw":
+			action.Raw = true
+		}
+	}
+
+	if len(positional) == 0 {
+		return nil, fmt.Errorf("kubectl command is required")
+	}
+	action.Verb = strings.ToLower(positional[0])
+	rule, ok := kubectlVerbRules[action.Verb]
+	resourceIndex := 1
+	if subRules, hasSub := kubectlSubVerbRules[action.Verb]; hasSub {
+		if len(positional) < 2 {
+			return nil, fmt.Errorf("kubectl %s requires a subcommand", action.Verb)
+		}
+		action.SubVerb = strings.ToLower(positional[1])
+		rule, ok = subRules[action.SubVerb]
+		resourceIndex = 2
+	}
+	if !ok {
+		return nil, fmt.Errorf("kubectl command %q is not allowed", strings.TrimSpace(action.Verb+" "+action.SubVerb))
+	}
+	action.kubectlVerbRule = rule
+
+	switch action.Verb {
+	case "logs", "exec", "debug", "run":
+		action.Resources = []string{"pods"}
+		if len(positional) > 1 && strings.Contains(positional[1], "/") {
+			action.Resources = parseKubectlResources(positional[1])
+		}
+	default:
+		if len(positional) > resourceIndex {
+			action.Resources = parseKubectlResources(positional[resourceIndex])
+		}
+	}
+	return action, nil
+}
+
+// splitKubectlFlag 拆分 --name=value、-n value、-nvalue 等写法
+func splitKubectlFlag(arg string) (name, value string, hasValue bool) {
+	if strings.HasPrefix(arg, "--") {
+		name = strings.TrimPrefix(arg, "--")
+		if idx := strings.Index(name, "="); idx >= 0 {
+			return name[:idx], name[idx+1:], true
+		}
+		return name, "", false
+	}
+
+	name = strings.TrimPrefix(arg, "-")
+	if idx := strings.Index(name, "="); idx >= 0 {
+		return name[:idx], name[idx+1:], true
+	}
+	if len(name) > 1 {
+		return name[:1], name[1:], true
+	}
+	return name, "", false
+}
+
+// parseKubectlResources 将 deploy/foo、pods,svc、deployments.apps 等写法归一为资源类型
+func parseKubectlResources(arg string) []string {
+	var resources []string
+	for _, item := range strings.Split(arg, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			item = item[:idx]
+		}
+		if idx := strings.Index(item, "."); idx >= 0 {
+			item = item[:idx]
+		}
+		if item != "" {
+			resources = append(resources, item)
+		}
+	}
+	return resources
+}
+
+// namespaceScope 是一组可访问的命名空间，all 表示整个集群
+type namespaceScope struct {
+	all        bool
+	namespaces map[string]bool
+}
+
+func (s *namespaceScope) add(namespaces []string) {
+	if len(namespaces) == 0 {
+		s.all = true
+		return
+	}
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]bool)
+	}
+	for _, ns := range namespaces {
+		s.namespaces[ns] = true
+	}
+}
+
+func (s *namespaceScope) empty() bool {
+	return !s.all && len(s.namespaces) == 0
+}
+
+// accessScope 是用户在某个集群上的读写范围
+type accessScope struct {
+	read  namespaceScope
+	write namespaceScope
+}
+
+func fullAccessScope() *accessScope {
+	return &accessScope{read: namespaceScope{all: true}, write: namespaceScope{all: true}}
+}
+
+// addPermission 合并一条集群授权：只读授权仅放开读，自定义授权按目标命名空间放开读写
+func (s *accessScope) addPermission(p *model.Permission) {
+	switch p.PType {
+	case model.PermissionPTypeReadonly:
+		s.read.all = true
+	case model.PermissionPTypeAdmin:
+		s.read.all = true
+		s.write.all = true
+	default:
+		var namespaces []string
+		if p.TargetNamespaces != "" {
+			_ = json.Unmarshal([]byte(p.TargetNamespaces), &namespaces)
+		}
+		s.read.add(namespaces)
+		s.write.add(namespaces)
+	}
+}
+
+// authorize 校验本次调用是否落在用户的授权范围内
+func (s *accessScope) authorize(action *kubectlAction) error {
+	scope, kind := &s.read, "read"
+	if action.mutating {
+		scope, kind = &s.write, "write"
+	}
+	if scope.empty() {
+		return fmt.Errorf("no %s permission on this cluster", kind)
+	}
+	if scope.all || action.namespaceless {
+		return nil
+	}
+
+	// 以下为仅拥有部分命名空间授权的情况
+	if action.clusterScoped || action.Raw {
+		return fmt.Errorf("kubectl %s requires cluster-wide permission", action.Verb)
+	}
+	for _, resource := range action.Resources {
+		if clusterScopedResources[resource] {
+			return fmt.Errorf("resource %q requires cluster-wide permission", resource)
+		}
+	}
+	if action.AllNamespaces {
+		return fmt.Errorf("--all-namespaces requires cluster-wide permission, specify -n explicitly")
+	}
+	if action.Namespace == "" {
+		return fmt.Errorf("namespace is required, specify -n explicitly")
+	}
+	if !scope.namespaces[action.Namespace] {
+		return fmt.Errorf("no %s permission on namespace %q", kind, action.Namespace)
+	}
+	return nil
+}
+
+// resolveAccessScope 计算用户在已通过访问校验的集群上的读写范围
+func (c *controller) resolveAccessScope(ctx context.Context, user *model.User, object *model.Cluster) (*accessScope, error) {
+	if user.Role == model.RoleRoot {
+		return fullAccessScope(), nil
+	}
+
+	scope := &accessScope{}
+	if object.UserId == user.Id {
+		// 自建集群拥有全部权限；授权生成的子集群沿用对应授权的范围
+		if object.PermissionId == 0 {
+			return fullAccessScope(), nil
+		}
+		perm, err := c.factory.Permission().Get(ctx, object.PermissionId)
+		if err != nil {
+			return nil, err
+		}
+		if perm == nil {
+			return nil, fmt.Errorf("permission of cluster %q not found", object.Name)
+		}
+		scope.addPermission(perm)
+		return scope, nil
+	}
+
+	perms, err := c.factory.Permission().List(ctx, db.WithUser(user.Id), db.WithOwnerCluster(object.Id))
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) == 0 {
+		return nil, fmt.Errorf("no permission on cluster %q", object.Name)
+	}
+	for i := range perms {
+		scope.addPermission(&perms[i])
+	}
+	return scope, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assistant
+
+import (
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestParseKubectlAction(t *testing.T) {
+	cases := []struct {
+		args      []string
+		verb      string
+		namespace string
+		mutating  bool
+		resources []string
+		denied    bool
+	}{
+		{args: []string{"get", "pods", "-n", "default", "-o", "json"}, verb: "get", namespace: "default", resources: []string{"pods"}},
+		{args: []string{"-n=kube-system", "describe", "deploy/coredns"}, verb: "describe", namespace: "kube-system", resources: []string{"deploy"}},
+		{args: []string{"get", "deployments.apps,svc", "--namespace", "web"}, verb: "get", namespace: "web", resources: []string{"deployments", "svc"}},
+		{args: []string{"logs", "nginx-0", "-nweb", "--tail", "100"}, verb: "logs", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"rollout", "status", "deployment/web", "-n", "web"}, verb: "rollout", namespace: "web", resources: []string{"deployment"}},
+		{args: []string{"rollout", "restart", "deployment/web", "-n", "web"}, verb: "rollout", namespace: "web", mutating: true, resources: []string{"deployment"}},
+		{args: []string{"scale", "deploy", "web", "--replicas", "3", "-n", "web"}, verb: "scale", namespace: "web", mutating: true, resources: []string{"deploy"}},
+		{args: []string{"patch", "deploy", "web", "-p", `{"spec":{}}`, "-n", "web"}, verb: "patch", namespace: "web", mutating: true, resources: []string{"deploy"}},
+		{args: []string{"exec", "web-0", "-n", "web", "--", "rm", "-f", "/tmp/x"}, verb: "exec", namespace: "web", mutating: true, resources: []string{"pods"}},
+		{args: []string{"delete", "ns", "web"}, verb: "delete", mutating: true, resources: []string{"ns"}},
+		{args: []string{"get", "-L", "app", "nodes", "-n", "web"}, verb: "get", namespace: "web", resources: []string{"nodes"}},
+		{args: []string{"get", "pods", "--chunk-size", "100", "--show-labels", "-n", "web"}, verb: "get", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"logs", "web-0", "-p", "-n", "web"}, verb: "logs", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"exec", "-it", "web-0", "-n", "web", "--", "sh"}, verb: "exec", namespace: "web", mutating: true, resources: []string{"pods"}},
+		{args: []string{"delete", "pod", "web-0", "--dry-run=server", "-n", "web"}, verb: "delete", namespace: "web", mutating: true, resources: []string{"pod"}},
+		{args: []string{"get", "--unknown", "x", "nodes"}, denied: true},
+		{args: []string{"get", "pods", "-v=9"}, denied: true},
+		{args: []string{"get", "pods", "-w"}, denied: true},
+		{args: []string{"exec", "-iw", "web-0"}, denied: true},
+		{args: []string{"apply", "-f", "/etc/passwd"}, denied: true},
+		{args: []string{"get", "pods", "--kubeconfig=/root/.kube/config"}, denied: true},
+		{args: []string{"get", "secrets", "--as", "system:admin"}, denied: true},
+		{args: []string{"logs", "web-0", "--follow"}, denied: true},
+		{args: []string{"port-forward", "svc/web", "8080:80"}, denied: true},
+		{args: []string{"edit", "deploy", "web"}, denied: true},
+		{args: []string{"rollout"}, denied: true},
+		{args: []string{"-o", "json"}, denied: true},
+		{args: []string{"get", "pods", "-o", "jsonpath={.items[*].metadata.name}", "-n", "web"}, verb: "get", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"get", "pods", "-o=go-template", "--template", "{{.kind}}", "-n", "web"}, verb: "get", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"get", "pods", "--output=custom-columns=NAME:.metadata.name", "-n", "web"}, verb: "get", namespace: "web", resources: []string{"pods"}},
+		{args: []string{"get", "ns", "default", "-o", "go-template-file=/etc/pixiu/config.yaml"}, denied: true},
+		{args: []string{"get", "ns", "default", "-o=jsonpath-file=/etc/pixiu/config.yaml"}, denied: true},
+		{args: []string{"get", "ns", "default", "--output", "custom-columns-file=/etc/pixiu/config.yaml"}, denied: true},
+		{args: []string{"get", "ns", "default", "-o", "templatefile", "--template", "/etc/pixiu/config.yaml"}, denied: true},
+		{args: []string{"get", "ns", "default", "--template", "/etc/pixiu/config.yaml", "-o", "go-template-file"}, denied: true},
+		{args: []string{"get", "ns", "default", "-o", "/etc/pixiu/config.yaml"}, denied: true},
+		{args: []string{"cp", "/etc/pixiu/config.yaml", "web/web-0:/tmp/x"}, denied: true},
+		{args: []string{"cp", "web/web-0:/x", "/usr/local/bin/kubectl"}, denied: true},
+	}
+
+	for _, tc := range cases {
+		action, err := parseKubectlAction(tc.args)
+		if tc.denied {
+			if err == nil {
+				t.Errorf("expected %v to be denied", tc.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", tc.args, err)
+			continue
+		}
+		if action.Verb != tc.verb || action.Namespace != tc.namespace || action.mutating != tc.mutating {
+			t.Errorf("unexpected action for %v: %+v", tc.args, action)
+		}
+		if len(action.Resources) != len(tc.resources) {
+			t.Errorf("unexpected resources for %v: %v", tc.args, action.Resources)
+			continue
+		}
+		for i := range tc.resources {
+			if action.Resources[i] != tc.resources[i] {
+				t.Errorf("unexpected resources for %v: %v", tc.args, action.Resources)
+			}
+		}
+	}
+}
+
+func TestAccessScopeAuthorize(t *testing.T) {
+	readonly := &accessScope{}
+	readonly.addPermission(&model.Permission{PType: model.PermissionPTypeReadonly})
+
+	custom := &accessScope{}
+	custom.addPermission(&model.Permission{PType: model.PermissionPTypeCustom, TargetNamespaces: `["web","db"]`})
+
+	cases := []struct {
+		name    string
+		scope   *accessScope
+		args    []string
+		allowed bool
+	}{
+		{"full access mutates", fullAccessScope(), []string{"delete", "ns", "web"}, true},
+		{"readonly reads all namespaces", readonly, []string{"get", "pods", "-A"}, true},
+		{"readonly cannot mutate", readonly, []string{"scale", "deploy", "web", "--replicas", "0", "-n", "web"}, false},
+		{"custom reads own namespace", custom, []string{"get", "pods", "-n", "web"}, true},
+		{"custom mutates own namespace", custom, []string{"rollout", "restart", "deploy/web", "-n", "db"}, true},
+		{"custom requires explicit namespace", custom, []string{"get", "pods"}, false},
+		{"custom cannot read other namespace", custom, []string{"get", "pods", "-n", "kube-system"}, false},
+		{"custom cannot list all namespaces", custom, []string{"get", "pods", "-A", "-n", "web"}, false},
+		{"custom cannot read nodes", custom, []string{"get", "nodes", "-n", "web"}, false},
+		{"custom cannot drain", custom, []string{"drain", "node-1", "-n", "web"}, false},
+		{"custom cannot use raw", custom, []string{"get", "--raw", "/api/v1/secrets", "-n", "web"}, false},
+		{"custom may run discovery", custom, []string{"api-resources"}, true},
+	}
+
+	for _, tc := range cases {
+		action, err := parseKubectlAction(tc.args)
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", tc.name, err)
+		}
+		err = tc.scope.authorize(action)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: expected allowed, got %v", tc.name, err)
+		}
+		if !tc.allowed && err == nil {
+			t.Errorf("%s: expected denied", tc.name)
+		}
+	}
+}
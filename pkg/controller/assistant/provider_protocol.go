@@ -31,6 +31,10 @@ import (
 const (
 	ProtocolOpenAIChat      = "openai_chat"
 	ProtocolOpenAIResponses = "openai_responses"
+	// ProtocolAnthropic 对应 Anthropic Messages API
+	ProtocolAnthropic = "anthropic_messages"
+	// ProtocolOllama 对应 Ollama 原生 /api/chat，适用于离线和本地部署的模型
+	ProtocolOllama = "ollama_chat"
 )
 
 var versionPathPattern = regexp.MustCompile(`/v[0-9]+$`)
@@ -44,17 +48,26 @@ func resolveAIEndpoint(provider *model.AIProvider) (string, error) {
 		return "", apierrors.NewError(fmt.Errorf("ai provider base_url is empty"), http.StatusBadRequest)
 	}
 
+	baseURL := strings.TrimRight(strings.TrimSpace(provider.BaseURL), "/")
+
 	var resource string
 	switch normalizeProtocol(provider.Protocol) {
 	case ProtocolOpenAIResponses:
 		resource = "responses"
 	case ProtocolOpenAIChat:
 		resource = "chat/completions"
+	case ProtocolAnthropic:
+		resource = "messages"
+	case ProtocolOllama:
+		// Ollama 不带版本号，base_url 可以是服务根地址或 /api
+		if strings.HasSuffix(baseURL, "/api") {
+			return baseURL + "/chat", nil
+		}
+		return baseURL + "/api/chat", nil
 	default:
 		return "", apierrors.NewError(fmt.Errorf("unsupported ai protocol %q", provider.Protocol), http.StatusBadRequest)
 	}
 
-	baseURL := strings.TrimRight(strings.TrimSpace(provider.BaseURL), "/")
 	if versionPathPattern.MatchString(baseURL) {
 		return baseURL + "/" + resource, nil
 	}
@@ -73,7 +86,60 @@ func (c *controller) runProviderStream(
 		return c.runResponsesLoopStream(ctx, endpoint, apiKey, modelName, maxTokens, inputItems, emit)
 	case ProtocolOpenAIChat:
 		return c.runChatCompletionsLoopStream(ctx, endpoint, apiKey, modelName, maxTokens, inputItems, emit)
+	case ProtocolAnthropic:
+		return c.runAnthropicLoopStream(ctx, endpoint, apiKey, modelName, maxTokens, inputItems, emit)
+	case ProtocolOllama:
+		return c.runOllamaLoopStream(ctx, endpoint, apiKey, modelName, maxTokens, inputItems, emit)
 	default:
 		return nil, "", "", apierrors.NewError(fmt.Errorf("unsupported ai protocol %q", protocol), http.StatusBadRequest)
 	}
 }
+
+// add 累加一轮请求的用量，工具循环中每一轮请求都会计费
+func (u *responseUsage) add(other responseUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+	u.CachedTokens += other.CachedTokens
+	u.ReasoningTokens += other.ReasoningTokens
+}
+
+// tokens 返回本次用量计入配额的 token 数
+func (u responseUsage) tokens() int64 {
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.InputTokens + u.OutputTokens
+}
+
+// usageRaw 工具循环中途失败时仅携带已消耗的用量，供 recordResponseExecution 入账
+func usageRaw(u responseUsage) map[string]interface{} {
+	return map[string]interface{}{"usage": u.toRaw()}
+}
+
+// checkRoundQuota 在工具循环的两轮请求之间校验配额，计入本次对话已消耗但尚未入账的 token，
+// 配额耗尽时停止循环，避免单次对话通过多轮工具调用大幅超出配额
+func (c *controller) checkRoundQuota(ctx context.Context, usage responseUsage) error {
+	meta := getToolExecutionMeta(ctx)
+	if meta == nil || c.quota == nil {
+		return nil
+	}
+	user := &model.User{TenantId: meta.TenantId}
+	user.Id = meta.UserId
+	return c.quota.Check(ctx, user, meta.AccountId, usage.tokens())
+}
+
+// toRaw 转成 extractResponseUsage 可识别的 usage 格式
+func (u responseUsage) toRaw() map[string]interface{} {
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.InputTokens + u.OutputTokens
+	}
+	return map[string]interface{}{
+		"input_tokens":          u.InputTokens,
+		"output_tokens":         u.OutputTokens,
+		"total_tokens":          total,
+		"input_tokens_details":  map[string]interface{}{"cached_tokens": u.CachedTokens},
+		"output_tokens_details": map[string]interface{}{"reasoning_tokens": u.ReasoningTokens},
+	}
+}
@@ -34,6 +34,7 @@ import (
 
 	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
 	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/controller/price"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
@@ -50,6 +51,12 @@ type toolExecutionMeta struct {
 	ModelName      string
 	Authorization  string
 	Cookies        []*http.Cookie
+	UserId         int64
+	UserName       string
+	TenantId       int64
+	AccountId      int64
+	// Emit 用于在工具调用过程中向前端推送审批事件
+	Emit func(*types.AIStreamEvent) error
 }
 
 type responseUsage struct {
@@ -74,11 +81,6 @@ func (c *controller) Stream(ctx context.Context, req *types.AIRespondRequest, em
 
 	modelName := account.ModelName
 
-	inputItems, err := buildConversationInput(conversation, req.Input)
-	if err != nil {
-		return nil, apierrors.ErrServerInternal
-	}
-
 	var (
 		authorization string
 		cookies       []*http.Cookie
@@ -87,6 +89,14 @@ func (c *controller) Stream(ctx context.Context, req *types.AIRespondRequest, em
 		authorization = ginCtx.GetHeader("Authorization")
 		cookies = ginCtx.Request.Cookies()
 	}
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, apierrors.ErrUnauthorized
+	}
+	// 对话开始前校验配额，配额耗尽时直接拒绝，不再请求模型
+	if err = c.quota.Check(ctx, user, account.Id, 0); err != nil {
+		return nil, err
+	}
 
 	ctx = withToolExecutionMeta(ctx, &toolExecutionMeta{
 		RequestId:      getRequestIDFromContext(ctx),
@@ -96,6 +106,11 @@ func (c *controller) Stream(ctx context.Context, req *types.AIRespondRequest, em
 		ModelName:      modelName,
 		Authorization:  authorization,
 		Cookies:        cookies,
+		UserId:         user.Id,
+		UserName:       user.Name,
+		TenantId:       user.TenantId,
+		AccountId:      account.Id,
+		Emit:           emit,
 	})
 
 	_ = emit(&types.AIStreamEvent{
@@ -105,13 +120,24 @@ func (c *controller) Stream(ctx context.Context, req *types.AIRespondRequest, em
 		Model:   modelName,
 	})
 
+	ctx = withContextWindow(ctx, newContextWindow(provider, c.defaultAIInstructions(), c.buildTools()))
+	if err = c.compactHistory(ctx, provider, account, conversation, req.Input, emit); err != nil {
+		klog.Errorf("failed to compact ai conversation history: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+	inputItems, err := buildConversationInput(conversation, req.Input)
+	if err != nil {
+		return nil, apierrors.ErrServerInternal
+	}
+
 	endpoint, err := resolveAIEndpoint(provider)
 	if err != nil {
 		return nil, err
 	}
 	raw, text, responseID, err := c.runProviderStream(ctx, provider.Protocol, endpoint, account.APIKey, modelName, resolveProviderMaxTokens(provider), inputItems, emit)
 	if err != nil {
-		c.recordResponseExecution(ctx, provider, req.ConversationId, modelName, req.Input, "", "", nil, err, time.Since(startTime))
+		// 失败前各轮已消耗的 token 同样入账，raw 中仅携带 usage
+		c.recordResponseExecution(ctx, provider, req.ConversationId, modelName, req.Input, "", "", raw, err, time.Since(startTime))
 		return nil, err
 	}
 
@@ -131,6 +157,14 @@ func (c *controller) Stream(ctx context.Context, req *types.AIRespondRequest, em
 	}
 
 	c.recordResponseExecution(ctx, provider, conversationID, modelName, req.Input, text, responseID, raw, nil, time.Since(startTime))
+	// 本轮用量入账后再次校验，提示用户后续对话将被拒绝
+	if err = c.quota.Check(ctx, user, account.Id, 0); err != nil {
+		_ = emit(&types.AIStreamEvent{
+			Type:    "status",
+			Stage:   "quota_exceeded",
+			Message: "本轮回复已完成，AI token 配额已用尽，后续对话将被拒绝",
+		})
+	}
 
 	resp := &types.AIRespondResponse{
 		ConversationId: conversationID,
@@ -206,6 +240,18 @@ func (c *controller) recordResponseExecution(
 		TotalTokens:     usage.TotalTokens,
 		CachedTokens:    usage.CachedTokens,
 		ReasoningTokens: usage.ReasoningTokens,
+		UserId:          meta.UserId,
+		TenantId:        meta.TenantId,
+		AccountId:       meta.AccountId,
+	}
+	if usage.TotalTokens > 0 {
+		modelPrice, err := c.factory.Assistant().ModelPrice().GetByModel(recordCtx, provider.Id, modelName)
+		if err != nil {
+			klog.Errorf("failed to get ai model price for %s: %v", modelName, err)
+		} else if modelPrice != nil {
+			record.Cost = price.Cost(modelPrice, usage.InputTokens, usage.CachedTokens, usage.OutputTokens)
+			record.Currency = modelPrice.Currency
+		}
 	}
 	if runErr != nil {
 		record.ErrorMessage = truncateAuditText(runErr.Error())
@@ -223,9 +269,10 @@ func (c *controller) runResponsesLoopStream(
 	inputItems []map[string]interface{},
 	emit func(*types.AIStreamEvent) error,
 ) (map[string]interface{}, string, string, error) {
-	tools := toResponsesTools(c.buildTools())
+	tools := toResponsesTools(c.requestTools(ctx))
 	maxIterations := 8
 
+	var usage responseUsage
 	for i := 0; i < maxIterations; i++ {
 		_ = emit(&types.AIStreamEvent{
 			Type:    "status",
@@ -236,13 +283,18 @@ func (c *controller) runResponsesLoopStream(
 
 		raw, text, err := c.callResponsesAPIStream(ctx, endpoint, apiKey, modelName, maxTokens, inputItems, tools, emit)
 		if err != nil {
-			return nil, "", "", err
+			return usageRaw(usage), "", "", err
 		}
+		usage.add(extractResponseUsage(raw))
 
 		toolCalls := extractToolCalls(raw)
 		if len(toolCalls) == 0 {
+			raw["usage"] = usage.toRaw()
 			return raw, text, extractResponseID(raw), nil
 		}
+		if err = c.checkRoundQuota(ctx, usage); err != nil {
+			return usageRaw(usage), "", "", err
+		}
 
 		outputItems := extractResponseOutputItems(raw)
 		if len(outputItems) > 0 {
@@ -289,9 +341,10 @@ func (c *controller) runResponsesLoopStream(
 				"output":  toolOutput,
 			})
 		}
+		trimToolOutputs(ctx, inputItems, len(toolCalls), emit)
 	}
 
-	return nil, "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
+	return usageRaw(usage), "", "", apierrors.NewError(fmt.Errorf("tool loop exceeded max iterations"), http.StatusBadGateway)
 }
 
 func (c *controller) callResponsesAPIStream(
@@ -349,8 +402,12 @@ func (c *controller) callResponsesAPIStream(
 func (c *controller) defaultAIInstructions() string {
 	instructions := []string{
 		"你是 Pixiu 平台里的中文 Kubernetes 运维助手，负责直接排查、定位和给出修复建议。",
-		"优先使用 k8s 工具获取真实结果，不要凭空猜测；只要工具可用，就先查再答。",
+		"优先使用工具获取真实结果，不要凭空猜测；只要工具可用，就先查再答。",
+		"指标用 prometheus_query / prometheus_query_range，日志用 log_search，告警用 alert_events / alert_silences，Helm 发布用 helm_release_status / helm_release_history，工作负载事件用 k8s_events，部署计划用 plan_task_logs，其余资源操作用 k8s 工具。",
+		"定位故障时把指标、日志、告警和事件交叉印证，给出的关键证据要注明来源。",
 		"默认只做查询和分析，不要主动执行删除、重启、扩缩容、修改配置等变更操作，除非用户明确要求。",
+		"所有变更操作都会暂停并等待用户确认后才执行；用户拒绝后不要换一种写法重试同一操作。",
+		"查询时尽量显式指定 -n 命名空间；工具返回无权限时直接告知用户，不要尝试绕过。",
 		"回答必须简洁，优先输出结论，不要写成长篇报告，不要大段复述原始日志、事件或 YAML。",
 		"故障分析默认按这个结构输出：1) 结论 2) 直接原因 3) 关键证据 4) 修复建议。",
 		"每个部分尽量控制在 1 到 3 条短句或短 bullet，优先保留最关键的信息。",
@@ -399,21 +456,15 @@ func (c *controller) getAccountProvider(ctx context.Context, accountId int64) (*
 }
 
 func buildConversationInput(conversation *model.Conversation, input string) ([]map[string]interface{}, error) {
-	items := make([]map[string]interface{}, 0)
-	if conversation != nil && strings.TrimSpace(conversation.History) != "" {
-		if err := json.Unmarshal([]byte(conversation.History), &items); err != nil {
-			return nil, err
-		}
+	history, err := conversation.HistoryItems()
+	if err != nil {
+		return nil, err
 	}
-	items = append(items, map[string]interface{}{
-		"role": "user",
-		"content": []map[string]interface{}{
-			{
-				"type": "input_text",
-				"text": input,
-			},
-		},
-	})
+	items := make([]map[string]interface{}, 0, len(history)+1)
+	for _, item := range history {
+		items = append(items, conversationInputItem(item))
+	}
+	items = append(items, conversationInputItem(newUserItem(input)))
 	return items, nil
 }
 
@@ -482,31 +533,16 @@ func (c *controller) persistConversation(
 }
 
 func appendConversationHistory(conversation *model.Conversation, input, outputText string) (string, error) {
-	items := make([]map[string]interface{}, 0)
-	if conversation != nil && strings.TrimSpace(conversation.History) != "" {
-		if err := json.Unmarshal([]byte(conversation.History), &items); err != nil {
-			return "", err
-		}
+	items, err := conversation.HistoryItems()
+	if err != nil {
+		return "", err
 	}
 
-	items = append(items, map[string]interface{}{
-		"role": "user",
-		"content": []map[string]interface{}{
-			{
-				"type": "input_text",
-				"text": input,
-			},
-		},
-	})
+	items = append(items, newUserItem(input))
 	if strings.TrimSpace(outputText) != "" {
-		items = append(items, map[string]interface{}{
-			"role": "assistant",
-			"content": []map[string]interface{}{
-				{
-					"type": "output_text",
-					"text": outputText,
-				},
-			},
+		items = append(items, model.ConversationItem{
+			Role:    "assistant",
+			Content: []model.ConversationContent{{Type: "output_text", Text: outputText}},
 		})
 	}
 
@@ -566,6 +602,13 @@ func extractResponseUsage(raw map[string]interface{}) responseUsage {
 	if details, ok := usageObj["output_tokens_details"].(map[string]interface{}); ok {
 		usage.ReasoningTokens = toInt64(details["reasoning_tokens"])
 	}
+	// Chat Completions 使用 prompt/completion 命名
+	if details, ok := usageObj["prompt_tokens_details"].(map[string]interface{}); ok && usage.CachedTokens == 0 {
+		usage.CachedTokens = toInt64(details["cached_tokens"])
+	}
+	if details, ok := usageObj["completion_tokens_details"].(map[string]interface{}); ok && usage.ReasoningTokens == 0 {
+		usage.ReasoningTokens = toInt64(details["reasoning_tokens"])
+	}
 
 	return usage
 }
@@ -27,9 +27,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/caoyingjunz/pixiu/pkg/client"
-	clustercontroller "github.com/caoyingjunz/pixiu/pkg/controller/cluster"
-	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -42,33 +39,51 @@ type toolDefinition struct {
 	Description string
 	Parameters  map[string]interface{}
 	Handler     func(ctx context.Context, args map[string]interface{}) (string, error)
+	// Authorize 在执行前校验调用范围，变更类调用需要用户审批后才会执行
+	Authorize func(ctx context.Context, args map[string]interface{}) (*toolCallPolicy, error)
+}
+
+// toolCallPolicy 记录一次工具调用的授权结果
+type toolCallPolicy struct {
+	Cluster   string
+	Namespace string
+	Verb      string
+	Mutating  bool
 }
 
 func (c *controller) buildTools() []toolDefinition {
-	return []toolDefinition{
-		{
-			Name:        "k8s",
-			Description: "Operate on Kubernetes resources in a Pixiu cluster via preinstalled kubectl and in-memory cluster config.",
-			Parameters: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"cluster": map[string]interface{}{
-						"type":        "string",
-						"description": "Pixiu cluster name.",
-					},
-					"args": map[string]interface{}{
-						"type":        "array",
-						"description": "kubectl arguments excluding kubeconfig, for example ['get','pods','-A','-o','json'] or ['logs','pod-name','-n','default'].",
-						"items": map[string]interface{}{
-							"type": "string",
-						},
+	tools := c.observabilityTools()
+	// kubectl 不可用时仍然可以使用原生工具排查
+	if _, err := findKubectlBinary(); err != nil {
+		return tools
+	}
+	return append(tools, c.kubectlTool())
+}
+
+func (c *controller) kubectlTool() toolDefinition {
+	return toolDefinition{
+		Name:        "k8s",
+		Description: "Operate on Kubernetes resources in a Pixiu cluster via preinstalled kubectl and in-memory cluster config.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"cluster": map[string]interface{}{
+					"type":        "string",
+					"description": "Pixiu cluster name.",
+				},
+				"args": map[string]interface{}{
+					"type":        "array",
+					"description": "kubectl arguments excluding kubeconfig, for example ['get','pods','-A','-o','json'] or ['logs','pod-name','-n','default']. Mutating commands wait for user approval; -f, --kubeconfig, --as and similar flags are rejected.",
+					"items": map[string]interface{}{
+						"type": "string",
 					},
 				},
-				"required":             []string{"cluster", "args"},
-				"additionalProperties": false,
 			},
-			Handler: c.handleK8s,
+			"required":             []string{"cluster", "args"},
+			"additionalProperties": false,
 		},
+		Handler:   c.handleK8s,
+		Authorize: c.authorizeK8s,
 	}
 }
 
@@ -96,49 +111,102 @@ func (c *controller) executeTool(ctx context.Context, callID, toolName, rawArgs
 	start := time.Now()
 	var (
 		output string
+		policy *toolCallPolicy
 		err    error
 	)
 	for _, tool := range c.buildTools() {
-		if tool.Name == toolName {
-			output, err = tool.Handler(ctx, args)
-			c.recordToolExecution(ctx, callID, toolName, rawArgs, output, err, time.Since(start))
-			return output, err
+		if tool.Name != toolName {
+			continue
+		}
+		if tool.Authorize != nil {
+			if policy, err = tool.Authorize(ctx, args); err != nil {
+				c.recordToolExecution(ctx, callID, toolName, rawArgs, "", policy, err, time.Since(start))
+				return "", err
+			}
 		}
+		if policy != nil && policy.Mutating {
+			return c.executeWithApproval(ctx, tool, callID, rawArgs, args, policy)
+		}
+
+		output, err = tool.Handler(ctx, args)
+		c.recordToolExecution(ctx, callID, toolName, rawArgs, output, policy, err, time.Since(start))
+		return output, err
 	}
 	err = fmt.Errorf("unknown tool: %s", toolName)
-	c.recordToolExecution(ctx, callID, toolName, rawArgs, "", err, time.Since(start))
+	c.recordToolExecution(ctx, callID, toolName, rawArgs, "", nil, err, time.Since(start))
 	return "", err
 }
 
 func (c *controller) handleK8s(ctx context.Context, args map[string]interface{}) (string, error) {
-	clusterName, _ := args["cluster"].(string)
-	clusterName = strings.TrimSpace(clusterName)
-	if clusterName == "" {
-		return "", fmt.Errorf("cluster is required")
+	clusterName, kubectlArgs, err := parseK8sToolArgs(args)
+	if err != nil {
+		return "", err
 	}
-
-	kubectlArgs, err := parseStringArgs(args["args"])
+	action, err := parseKubectlAction(kubectlArgs)
 	if err != nil {
 		return "", err
 	}
-	if len(kubectlArgs) == 0 {
-		return "", fmt.Errorf("args is required")
+	cfg, err := c.resolveKubectlConfig(ctx, clusterName, action)
+	if err != nil {
+		return "", err
 	}
 
-	body, err := c.runKubectlWithCluster(ctx, clusterName, kubectlArgs)
+	body, err := c.runKubectlWithConfig(ctx, clusterName, cfg, kubectlArgs)
 	if err != nil {
 		return "", err
 	}
 	return wrapK8SResult(clusterName, kubectlArgs, string(body))
 }
 
-func (c *controller) runKubectlWithCluster(ctx context.Context, clusterName string, args []string) ([]byte, error) {
-	clusterSet, err := c.getClusterSetForAI(ctx, clusterName)
+func (c *controller) authorizeK8s(ctx context.Context, args map[string]interface{}) (*toolCallPolicy, error) {
+	clusterName, kubectlArgs, err := parseK8sToolArgs(args)
 	if err != nil {
 		return nil, err
 	}
+	action, err := parseKubectlAction(kubectlArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &toolCallPolicy{
+		Cluster:   clusterName,
+		Namespace: action.Namespace,
+		Verb:      strings.TrimSpace(action.Verb + " " + action.SubVerb),
+		Mutating:  action.mutating,
+	}
+	user, object, err := c.authorizeCluster(ctx, clusterName)
+	if err != nil {
+		return policy, err
+	}
+	scope, err := c.resolveAccessScope(ctx, user, object)
+	if err != nil {
+		return policy, err
+	}
+	if err = scope.authorize(action); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+func parseK8sToolArgs(args map[string]interface{}) (string, []string, error) {
+	clusterName, _ := args["cluster"].(string)
+	clusterName = strings.TrimSpace(clusterName)
+	if clusterName == "" {
+		return "", nil, fmt.Errorf("cluster is required")
+	}
 
-	kubeconfigBytes, err := buildKubeconfigFromRESTConfig(clusterName, clusterSet.Config)
+	kubectlArgs, err := parseStringArgs(args["args"])
+	if err != nil {
+		return "", nil, err
+	}
+	if len(kubectlArgs) == 0 {
+		return "", nil, fmt.Errorf("args is required")
+	}
+	return clusterName, kubectlArgs, nil
+}
+
+func (c *controller) runKubectlWithConfig(ctx context.Context, clusterName string, cfg *restclient.Config, args []string) ([]byte, error) {
+	kubeconfigBytes, err := buildKubeconfigFromRESTConfig(clusterName, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -183,35 +251,6 @@ func (c *controller) runKubectlWithCluster(ctx context.Context, clusterName stri
 	return stdout.Bytes(), nil
 }
 
-func (c *controller) getClusterSetForAI(ctx context.Context, clusterName string) (client.ClusterSet, error) {
-	clusterSet, ok := clustercontroller.ClusterIndexer.Get(clusterName)
-	if ok {
-		if clusterSet.Config == nil {
-			return client.ClusterSet{}, fmt.Errorf("cluster %q has empty in-memory config", clusterName)
-		}
-		return clusterSet, nil
-	}
-
-	object, err := c.factory.Cluster().GetBy(ctx, db.WithName(clusterName))
-	if err != nil {
-		return client.ClusterSet{}, err
-	}
-	if object == nil {
-		return client.ClusterSet{}, fmt.Errorf("cluster %q not found", clusterName)
-	}
-	if strings.TrimSpace(object.KubeConfig) == "" {
-		return client.ClusterSet{}, fmt.Errorf("cluster %q kubeconfig is empty", clusterName)
-	}
-
-	newClusterSet, err := client.NewClusterSet(object.KubeConfig)
-	if err != nil {
-		return client.ClusterSet{}, fmt.Errorf("build cluster config for %q failed: %v", clusterName, err)
-	}
-
-	clustercontroller.ClusterIndexer.Set(clusterName, *newClusterSet)
-	return *newClusterSet, nil
-}
-
 func findKubectlBinary() (string, error) {
 	if path, err := exec.LookPath("kubectl"); err == nil {
 		return path, nil
@@ -253,6 +292,8 @@ func buildKubeconfigFromRESTConfig(clusterName string, cfg *restclient.Config) (
 		Password:              cfg.Password,
 		ClientCertificateData: cfg.TLSClientConfig.CertData,
 		ClientKeyData:         cfg.TLSClientConfig.KeyData,
+		Impersonate:           cfg.Impersonate.UserName,
+		ImpersonateGroups:     cfg.Impersonate.Groups,
 	}
 
 	kubeconfig := clientcmdapi.Config{
@@ -323,7 +364,7 @@ func truncateToolOutput(text string) string {
 	return text[:4000]
 }
 
-func (c *controller) recordToolExecution(ctx context.Context, callID, toolName, rawArgs, output string, runErr error, duration time.Duration) {
+func (c *controller) recordToolExecution(ctx context.Context, callID, toolName, rawArgs, output string, policy *toolCallPolicy, runErr error, duration time.Duration) {
 	meta := getToolExecutionMeta(ctx)
 	if meta == nil {
 		return
@@ -332,24 +373,36 @@ func (c *controller) recordToolExecution(ctx context.Context, callID, toolName,
 	recordCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	record := newToolExecution(meta, callID, toolName, rawArgs, policy)
+	record.Output = truncateToolOutput(output)
+	record.Success = runErr == nil
+	record.Duration = duration.Milliseconds()
+	if runErr != nil {
+		record.ErrorMessage = truncateToolOutput(runErr.Error())
+	}
+
+	if _, err := c.factory.Assistant().Execution().Create(recordCtx, record); err != nil {
+		klog.Errorf("failed to create ai execution record for tool(%s): %v", toolName, err)
+	}
+}
+
+func newToolExecution(meta *toolExecutionMeta, callID, toolName, rawArgs string, policy *toolCallPolicy) *model.Execution {
 	record := &model.Execution{
 		RequestId:      meta.RequestId,
 		ProviderId:     meta.ProviderId,
 		ConversationId: meta.ConversationId,
 		Provider:       meta.Provider,
 		ModelName:      meta.ModelName,
+		UserId:         meta.UserId,
 		ToolName:       toolName,
 		CallId:         callID,
 		Arguments:      truncateToolOutput(rawArgs),
-		Output:         truncateToolOutput(output),
-		Success:        runErr == nil,
-		Duration:       duration.Milliseconds(),
 	}
-	if runErr != nil {
-		record.ErrorMessage = truncateToolOutput(runErr.Error())
-	}
-
-	if _, err := c.factory.Assistant().Execution().Create(recordCtx, record); err != nil {
-		klog.Errorf("failed to create ai execution record for tool(%s): %v", toolName, err)
+	if policy != nil {
+		record.Cluster = policy.Cluster
+		record.Namespace = policy.Namespace
+		record.Verb = policy.Verb
+		record.Mutating = policy.Mutating
 	}
+	return record
 }
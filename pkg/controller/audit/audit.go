@@ -18,14 +18,18 @@ package audit
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/caoyingjunz/pixiu/api/server/errors"
 	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	"github.com/caoyingjunz/pixiu/pkg/auditchain"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/recording"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
 
@@ -36,11 +40,24 @@ type AuditGetter interface {
 type Interface interface {
 	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
 	Get(ctx context.Context, aid int64) (*types.Audit, error)
+	// ListObjectDiffs 获取审计记录关联的 K8s 对象变更差异
+	ListObjectDiffs(ctx context.Context, aid int64) ([]types.AuditObjectDiff, error)
+	// ListSinks 获取审计日志外部推送 sink 的投递统计
+	ListSinks(ctx context.Context) ([]auditsink.Stats, error)
+	// Verify 校验审计哈希链，报告缺失或被修改的记录
+	Verify(ctx context.Context) (*auditchain.Report, error)
+
+	// 交互式终端会话录像
+	ListSessions(ctx context.Context, listOption types.ListOptions) (interface{}, error)
+	GetSession(ctx context.Context, sessionId int64) (*types.TerminalRecording, error)
+	ReplaySession(ctx context.Context, sessionId int64, w http.ResponseWriter) error
 }
 
 type audit struct {
 	cc      config.Config
 	factory db.ShareDaoFactory
+
+	recordings recording.Storage
 }
 
 func (a *audit) Get(ctx context.Context, aid int64) (*types.Audit, error) {
@@ -55,6 +72,64 @@ func (a *audit) Get(ctx context.Context, aid int64) (*types.Audit, error) {
 	return a.model2Type(object), nil
 }
 
+func (a *audit) ListObjectDiffs(ctx context.Context, aid int64) ([]types.AuditObjectDiff, error) {
+	if _, err := a.Get(ctx, aid); err != nil {
+		return nil, err
+	}
+
+	objects, err := a.factory.Audit().ObjectDiff().List(ctx, db.WithAuditId(aid))
+	if err != nil {
+		klog.Errorf("failed to list object diffs of audit %d: %v", aid, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	diffs := make([]types.AuditObjectDiff, 0)
+	for _, object := range objects {
+		diffs = append(diffs, types.AuditObjectDiff{
+			PixiuMeta: types.PixiuMeta{
+				Id:              object.Id,
+				ResourceVersion: object.ResourceVersion,
+			},
+			TimeMeta: types.TimeMeta{
+				GmtCreate:   object.GmtCreate,
+				GmtModified: object.GmtModified,
+			},
+			AuditId:    object.AuditId,
+			Cluster:    object.Cluster,
+			APIVersion: object.APIVersion,
+			Kind:       object.Kind,
+			Namespace:  object.Namespace,
+			Name:       object.Name,
+			Before:     object.Before,
+			After:      object.After,
+			Diff:       object.Diff,
+		})
+	}
+	return diffs, nil
+}
+
+func (a *audit) ListSinks(ctx context.Context) ([]auditsink.Stats, error) {
+	d := auditsink.Default()
+	if d == nil {
+		return []auditsink.Stats{}, nil
+	}
+	return d.Stats(), nil
+}
+
+func (a *audit) Verify(ctx context.Context) (*auditchain.Report, error) {
+	chain, err := auditchain.NewChain(a.cc.AuditChain, a.factory)
+	if err != nil {
+		klog.Errorf("failed to load audit chain: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+	report, err := chain.Verify(ctx)
+	if err != nil {
+		klog.Errorf("failed to verify audit chain: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+	return report, nil
+}
+
 func (a *audit) List(ctx context.Context, listOption types.ListOptions) (interface{}, error) {
 	listOption.SetDefaultPageOption()
 
@@ -110,6 +185,18 @@ func buildAuditFilterOpts(opt types.ListOptions) []db.Options {
 	if opt.ClusterName != "" {
 		opts = append(opts, db.WithAuditCluster(opt.ClusterName))
 	}
+	if opt.Resource != "" {
+		opts = append(opts, db.WithAuditResource(opt.Resource))
+	}
+	if opt.ResourceName != "" {
+		opts = append(opts, db.WithAuditResourceName(opt.ResourceName))
+	}
+	if opt.ResourceNamespace != "" {
+		opts = append(opts, db.WithAuditResourceNamespace(opt.ResourceNamespace))
+	}
+	if opt.TokenJTI != "" {
+		opts = append(opts, db.WithAuditTokenJTI(opt.TokenJTI))
+	}
 	if opt.Status != nil {
 		opts = append(opts, db.WithAuditStatus(uint8(*opt.Status)))
 	}
@@ -147,6 +234,10 @@ func (a *audit) model2Type(o *model.Audit) *types.Audit {
 		Cluster:           o.Cluster,
 		ResourceName:      o.ResourceName,
 		ResourceNamespace: o.ResourceNamespace,
+		Resource:          o.Resource,
+		TokenJTI:          o.TokenJTI,
+		PrevHash:          o.PrevHash,
+		Hash:              o.Hash,
 	}
 }
 
@@ -154,5 +245,7 @@ func NewAudit(cfg config.Config, f db.ShareDaoFactory) *audit {
 	return &audit{
 		cc:      cfg,
 		factory: f,
+
+		recordings: recording.NewLocalStorage(cfg.Recording.Dir),
 	}
 }
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+// ListSessions 查看交互式终端会话录像，支持按用户、集群、Pod 与节点过滤；非超级管理员仅能查看自己的会话
+func (a *audit) ListSessions(ctx context.Context, listOption types.ListOptions) (interface{}, error) {
+	listOption.SetDefaultPageOption()
+
+	pageResult := types.PageResult{
+		PageRequest: types.PageRequest{
+			Page:  listOption.Page,
+			Limit: listOption.Limit,
+		},
+	}
+
+	opts := []db.Options{
+		db.WithUser(listOption.UserId),
+		db.WithTerminalSession(model.TerminalSessionKind(listOption.SessionKind), listOption.ClusterName, listOption.Pod, listOption.Node),
+	}
+	if listOption.Operator != "" {
+		opts = append(opts, db.WithAuditOperatorLike(listOption.Operator))
+	}
+	if listOption.StartTime != "" {
+		if t, err := time.Parse(time.RFC3339, listOption.StartTime); err == nil {
+			opts = append(opts, db.WithCreatedAfter(t))
+		}
+	}
+	if listOption.EndTime != "" {
+		if t, err := time.Parse(time.RFC3339, listOption.EndTime); err == nil {
+			opts = append(opts, db.WithCreatedBefore(t))
+		}
+	}
+
+	var err error
+	pageResult.Total, err = a.factory.Audit().TerminalSession().Count(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to count terminal sessions: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+
+	offset := (listOption.Page - 1) * listOption.Limit
+	opts = append(opts,
+		db.WithOffset(offset),
+		db.WithLimit(listOption.Limit),
+		db.WithOrderByDesc(),
+	)
+	objects, err := a.factory.Audit().TerminalSession().List(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to list terminal sessions: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+
+	items := make([]types.TerminalRecording, 0)
+	for i := range objects {
+		items = append(items, *model2Recording(&objects[i]))
+	}
+	pageResult.Items = items
+	return pageResult, nil
+}
+
+func (a *audit) GetSession(ctx context.Context, sessionId int64) (*types.TerminalRecording, error) {
+	object, err := a.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return model2Recording(object), nil
+}
+
+// ReplaySession 输出 asciicast v2 格式的会话录像，可直接由 asciinema-player 回放
+func (a *audit) ReplaySession(ctx context.Context, sessionId int64, w http.ResponseWriter) error {
+	object, err := a.getSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	if object.StorageKey == "" {
+		return errors.NewError(fmt.Errorf("会话录像不存在"), http.StatusNotFound)
+	}
+
+	r, err := a.recordings.Open(object.StorageKey)
+	if err != nil {
+		klog.Errorf("failed to open recording %s: %v", object.StorageKey, err)
+		return errors.NewError(fmt.Errorf("会话录像不存在"), http.StatusNotFound)
+	}
+	defer r.Close()
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="session-%d.cast"`, object.Id))
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, r); err != nil {
+		klog.Errorf("failed to write recording %s: %v", object.StorageKey, err)
+	}
+	return nil
+}
+
+func (a *audit) getSession(ctx context.Context, sessionId int64) (*model.TerminalSession, error) {
+	object, err := a.factory.Audit().TerminalSession().Get(ctx, sessionId)
+	if err != nil {
+		klog.Errorf("failed to get terminal session %d: %v", sessionId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.NewError(fmt.Errorf("终端会话不存在"), http.StatusNotFound)
+	}
+	if err = util.CheckResourceOwner(ctx, object.UserId); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func model2Recording(o *model.TerminalSession) *types.TerminalRecording {
+	return &types.TerminalRecording{
+		PixiuMeta: types.PixiuMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		AuditId:   o.AuditId,
+		Kind:      o.Kind,
+		UserId:    o.UserId,
+		Operator:  o.Operator,
+		Ip:        o.Ip,
+		Cluster:   o.Cluster,
+		Namespace: o.Namespace,
+		Pod:       o.Pod,
+		Container: o.Container,
+		Node:      o.Node,
+		Size:      o.Size,
+		Duration:  o.Duration,
+		StartedAt: o.StartedAt,
+		EndedAt:   o.EndedAt,
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	agentdeploy "github.com/caoyingjunz/pixiu/deploy/cluster-agent"
+)
+
+const (
+	// agentServerPlaceholder 未配置 public_url 且无法从请求推断时的占位地址，需用户手动替换
+	agentServerPlaceholder = "https://<pixiu-server>"
+	agentTokenPlaceholder  = "<agent-token>"
+)
+
+// agentImagePattern 匹配清单中的 cluster-agent 镜像
+var agentImagePattern = regexp.MustCompile(`(image: )\S+/pixiu-cluster-agent:\S+`)
+
+// renderAgentManifest 基于 deploy/cluster-agent/cluster-agent.yaml 生成隧道模式集群的 cluster-agent 部署清单，
+// image 非空时替换清单中的镜像（default.agent_image）
+func renderAgentManifest(clusterName, server, agentToken, image string) (string, error) {
+	manifest := agentdeploy.Manifest
+	if image = strings.TrimSpace(image); image != "" {
+		if !agentImagePattern.MatchString(manifest) {
+			return "", fmt.Errorf("cluster-agent image not found in manifest")
+		}
+		manifest = agentImagePattern.ReplaceAllLiteralString(manifest, "image: "+image)
+	}
+
+	for placeholder, value := range map[string]string{
+		agentServerPlaceholder: server,
+		agentTokenPlaceholder:  agentToken,
+	} {
+		quoted := strconv.Quote(placeholder)
+		if !strings.Contains(manifest, quoted) {
+			return "", fmt.Errorf("placeholder %s not found in cluster-agent manifest", quoted)
+		}
+		manifest = strings.ReplaceAll(manifest, quoted, strconv.Quote(value))
+	}
+	return "# cluster: " + clusterName + "\n" + manifest, nil
+}
+
+// agentServerURL 返回 Agent 连接 Pixiu 的地址，优先使用 default.public_url
+func (c *cluster) agentServerURL(ctx context.Context) string {
+	base := strings.TrimRight(strings.TrimSpace(c.cc.Default.PublicURL), "/")
+	if base == "" {
+		base = inferPublicURL(ctx)
+	}
+	if base == "" {
+		return agentServerPlaceholder
+	}
+	return base
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	agentdeploy "github.com/caoyingjunz/pixiu/deploy/cluster-agent"
+)
+
+func TestRenderAgentManifest(t *testing.T) {
+	manifest, err := renderAgentManifest("prod", "https://pixiu.example.com", "token-123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(manifest, "# cluster: prod\n") {
+		t.Errorf("manifest should start with the cluster name")
+	}
+	if !agentImagePattern.MatchString(manifest) {
+		t.Errorf("agent image should default to the one in deploy/cluster-agent")
+	}
+	for _, want := range []string{`value: "https://pixiu.example.com"`, `value: "token-123"`} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("manifest missing %s", want)
+		}
+	}
+	for _, placeholder := range []string{agentServerPlaceholder, agentTokenPlaceholder} {
+		if strings.Contains(manifest, placeholder) {
+			t.Errorf("placeholder %s was not replaced", placeholder)
+		}
+	}
+
+	// 除镜像与占位符外，渲染结果与 deploy 目录下的清单一致
+	docs := strings.Split(manifest, "\n---\n")
+	if want := strings.Count(agentdeploy.Manifest, "\n---\n") + 1; len(docs) != want {
+		t.Fatalf("expected %d documents, got %d", want, len(docs))
+	}
+	for _, doc := range docs {
+		var object map[string]interface{}
+		if err = yaml.Unmarshal([]byte(doc), &object); err != nil || object["kind"] == nil {
+			t.Errorf("invalid document %q: %v", doc, err)
+		}
+	}
+}
+
+func TestRenderAgentManifestImage(t *testing.T) {
+	image := "registry.example.com/pixiu/pixiu-cluster-agent:v2.1.0"
+	manifest, err := renderAgentManifest("prod", "https://pixiu.example.com", "token-123", image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(manifest, "image: "+image+"\n") || strings.Count(manifest, "pixiu-cluster-agent:") != 1 {
+		t.Errorf("agent image should be replaced with %s:\n%s", image, manifest)
+	}
+}
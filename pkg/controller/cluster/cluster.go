@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -28,14 +29,17 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
@@ -48,6 +52,9 @@ import (
 	controllerutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/recording"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/tunnel"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 	"github.com/caoyingjunz/pixiu/pkg/util"
@@ -69,12 +76,22 @@ type Interface interface {
 	// GetKubeConfig 获取集群的 kubeconfig
 	GetKubeConfig(ctx context.Context, cid int64) (*types.KubeConfigResponse, error)
 
+	// ListImportContexts 解析多上下文 kubeconfig，列出可导入的上下文
+	ListImportContexts(ctx context.Context, req *types.ParseKubeconfigRequest) ([]types.KubeconfigContext, error)
+	// Import 从多上下文 kubeconfig 批量导入集群
+	Import(ctx context.Context, req *types.ImportClustersRequest) (*types.ImportClustersResult, error)
+
 	// Ping 检查和 k8s 集群的连通性
 	Ping(ctx context.Context, kubeConfig string) error
 
 	// Protect 设置集群的保护策略
 	Protect(ctx context.Context, cid int64, req *types.ProtectClusterRequest) error
 
+	// RotateCredential 签发新的 Pixiu ServiceAccount token 并替换集群凭据
+	RotateCredential(ctx context.Context, cid int64, req *types.RotateCredentialRequest) (*types.Cluster, error)
+	// SyncCredentials 刷新凭据过期信息、自动轮换并告警，由定时任务调用
+	SyncCredentials(ctx context.Context) (*types.CredentialSyncResult, error)
+
 	// GetEventList 获取指定对象的事件，支持做聚合
 	GetEventList(ctx context.Context, cluster string, options types.EventOptions) (*v1.EventList, error)
 
@@ -109,6 +126,9 @@ type Interface interface {
 	// ProxyKubeconfig 代理 KubeConfig 管理（签发/获取/吊销/校验）
 	ProxyKubeconfig() ProxyKubeconfigInterface
 
+	// Health 集群健康历史（状态事件、可用率、节点抖动）
+	Health() HealthInterface
+
 	// AuthorizeClusterAccess 校验用户是否可访问集群
 	AuthorizeClusterAccess(ctx context.Context, user *model.User, clusterId int64) (*model.Cluster, error)
 	AuthorizeClusterAccessByName(ctx context.Context, user *model.User, clusterName string) (*model.Cluster, error)
@@ -128,6 +148,7 @@ var ClusterIndexer client.Cache
 
 func init() {
 	ClusterIndexer = *client.NewClusterCache()
+	metrics.RegisterInformerCache(ClusterIndexer.InformerCacheSizes)
 }
 
 type (
@@ -148,6 +169,12 @@ type cluster struct {
 
 	listerFuncs map[string]listerFunc
 	getterFuncs map[string]getterFunc
+
+	// 交互式终端会话录像存储
+	recordings recording.Storage
+
+	// 凭据过期告警去重状态，仅凭据同步任务使用
+	credentialWarnings *credentialWarnings
 }
 
 func (c *cluster) preCreate(ctx context.Context, req *types.CreateClusterRequest) error {
@@ -170,6 +197,15 @@ func (c *cluster) Create(ctx context.Context, req *types.CreateClusterRequest) (
 		return nil, errors.NewError(fmt.Errorf("kube_config is required"), http.StatusBadRequest)
 	}
 
+	tunnelTargets, err := marshalTunnelTargets(req.ConnectMode, req.TunnelTargets)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	labels, err := marshalClusterLabels(req.Labels)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
 	agentToken := ""
 	if req.ConnectMode == model.ConnectModeTunnel {
 		if req.AgentToken != "" {
@@ -194,6 +230,7 @@ func (c *cluster) Create(ctx context.Context, req *types.CreateClusterRequest) (
 
 	kubeNode := types.KubeNode{}
 	nodes, _ := kubeNode.Marshal()
+	credentialType, credentialExpiresAt := parseCredentialFields(req.KubeConfig)
 	obj, err := c.factory.Cluster().Create(ctx, &model.Cluster{
 		Name:           req.Name,
 		UserId:         req.UserId,
@@ -203,10 +240,15 @@ func (c *cluster) Create(ctx context.Context, req *types.CreateClusterRequest) (
 		KubeConfig:     req.KubeConfig,
 		ConnectMode:    req.ConnectMode,
 		AgentToken:     agentToken,
+		TunnelTargets:  tunnelTargets,
 		Description:    req.Description,
+		Labels:         labels,
 		PermissionId:   req.PermissionId,
 		OwnerReference: req.OwnerReference,
 		Nodes:          nodes,
+
+		CredentialType:      credentialType,
+		CredentialExpiresAt: credentialExpiresAt,
 	}, txFunc)
 	if err != nil {
 		klog.Errorf("failed to create cluster %s: %v", req.Name, err)
@@ -307,28 +349,29 @@ func (c *cluster) addPixiuClusterRole(ctx context.Context, req *types.CreateClus
 }
 
 // 更新前置检查
-func (c *cluster) preUpdate(ctx context.Context, cid int64) error {
+func (c *cluster) preUpdate(ctx context.Context, cid int64) (*model.Cluster, error) {
 	object, err := c.factory.Cluster().Get(ctx, cid)
 	if err != nil {
 		klog.Errorf("failed to get cluster %d: %v", cid, err)
-		return errors.ErrServerInternal
+		return nil, errors.ErrServerInternal
 	}
 	if object == nil {
 		klog.Errorf("cluster %d not found", cid)
-		return errors.ErrClusterNotFound
+		return nil, errors.ErrClusterNotFound
 	}
 
 	// 非超级管理员只能更新自己的集群或被 scope 授权的集群
 	if err = controllerutil.CheckResourceAccess(ctx, c.factory, object.UserId, types.ResourceTypeCluster, cid); err != nil {
 		klog.Errorf("failed to check cluster access: %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return object, nil
 }
 
 func (c *cluster) Update(ctx context.Context, cid int64, req *types.UpdateClusterRequest) error {
-	if err := c.preUpdate(ctx, cid); err != nil {
+	object, err := c.preUpdate(ctx, cid)
+	if err != nil {
 		klog.Errorf("pre-update check failed for cluster(%d): %v", cid, err)
 		return err
 	}
@@ -341,14 +384,34 @@ func (c *cluster) Update(ctx context.Context, cid int64, req *types.UpdateCluste
 	if req.Description != nil {
 		updates["description"] = *req.Description
 	}
+	if req.TunnelTargets != nil {
+		if object.ConnectMode != model.ConnectModeTunnel {
+			return errors.NewError(fmt.Errorf("tunnel_targets 仅支持隧道模式集群"), http.StatusBadRequest)
+		}
+		tunnelTargets, err := marshalTunnelTargets(object.ConnectMode, *req.TunnelTargets)
+		if err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+		updates["tunnel_targets"] = tunnelTargets
+	}
+	if req.Labels != nil {
+		labels, err := marshalClusterLabels(*req.Labels)
+		if err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+		updates["labels"] = labels
+	}
 	if len(updates) == 0 {
 		klog.V(2).Infof("cluster(%d): no fields to update", cid)
 		return errors.ErrInvalidRequest
 	}
-	if err := c.factory.Cluster().Update(ctx, cid, req.ResourceVersion, updates); err != nil {
+	if err = c.factory.Cluster().Update(ctx, cid, req.ResourceVersion, updates); err != nil {
 		klog.Errorf("failed to update cluster(%d): %v", cid, err)
 		return errors.ErrServerInternal
 	}
+	if req.TunnelTargets != nil {
+		tunnel.Default().InvalidateTargets(object.Name)
+	}
 	return nil
 }
 
@@ -1006,13 +1069,16 @@ func (c *cluster) GetKubeConfigByName(ctx context.Context, name string) (*restcl
 }
 
 // GetClusterSetByName 获取 ClusterSet， 缓存中不存在时，构建缓存再返回
-func (c *cluster) GetClusterSetByName(ctx context.Context, name string) (client.ClusterSet, error) {
+func (c *cluster) GetClusterSetByName(ctx context.Context, name string) (_ client.ClusterSet, err error) {
 	cs, ok := ClusterIndexer.Get(name)
 	if ok {
 		klog.V(2).Infof("Get %s clusterSet from cache", name)
 		return cs, nil
 	}
 
+	ctx, span := tracing.Start(ctx, "cluster.BuildClusterSet", attribute.String("pixiu.cluster", name))
+	defer func() { tracing.End(span, err) }()
+
 	klog.Infof("building clusterSet for %s", name)
 	// 缓存中不存在，则新建并重写回缓存
 	object, err := c.factory.Cluster().GetBy(ctx, db.WithName(name))
@@ -1195,11 +1261,17 @@ func (c *cluster) model2Type(o *model.Cluster) *types.Cluster {
 		Protected:         o.Protected,
 		ConnectMode:       o.ConnectMode,
 		AgentToken:        o.AgentToken,
+		TunnelTargets:     unmarshalTunnelTargets(o.TunnelTargets),
 		Description:       o.Description,
+		Labels:            UnmarshalClusterLabels(o.Labels),
 		ProbeStatus:       o.ProbeStatus,
 		ProbeReason:       o.ProbeReason,
 		ProbeMessage:      o.ProbeMessage,
 		LastProbeTime:     o.LastProbeTime,
+
+		CredentialType:      o.CredentialType,
+		CredentialExpiresAt: o.CredentialExpiresAt,
+		CredentialDaysLeft:  credentialDaysLeft(o.CredentialExpiresAt),
 	}
 
 	if o.ConnectMode == model.ConnectModeTunnel {
@@ -1247,7 +1319,6 @@ func (c *cluster) registerIndexers(informerResources ...InformerResource) {
 
 func (c *cluster) Run(ctx context.Context, workers int) error {
 	klog.Infof("starting cluster manager")
-
 	return nil
 }
 
@@ -1255,6 +1326,67 @@ func generateAgentToken() (string, error) {
 	return token.Generate()
 }
 
+// marshalTunnelTargets 校验并序列化隧道允许名单，直连集群不保存允许名单
+func marshalTunnelTargets(mode model.ConnectMode, targets []string) (string, error) {
+	if mode != model.ConnectModeTunnel || len(targets) == 0 {
+		return "", nil
+	}
+	normalized, err := tunnel.NormalizeTargets(targets)
+	if err != nil {
+		return "", err
+	}
+	if len(normalized) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func unmarshalTunnelTargets(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var targets []string
+	if err := json.Unmarshal([]byte(s), &targets); err != nil {
+		// 非核心数据
+		klog.Warningf("failed to unmarshal cluster tunnel targets: %v", err)
+		return nil
+	}
+	return targets
+}
+
+// marshalClusterLabels 校验并序列化集群标签
+func marshalClusterLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+	if errs := metav1validation.ValidateLabels(labels, field.NewPath("labels")); len(errs) != 0 {
+		return "", errs.ToAggregate()
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// UnmarshalClusterLabels 解析集群标签，解析失败时视为无标签
+func UnmarshalClusterLabels(s string) map[string]string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(s), &labels); err != nil {
+		// 非核心数据
+		klog.Warningf("failed to unmarshal cluster labels: %v", err)
+		return nil
+	}
+	return labels
+}
+
 func NewCluster(cfg config.Config, f db.ShareDaoFactory) *cluster {
 	c := &cluster{
 		cc:      cfg,
@@ -1262,6 +1394,10 @@ func NewCluster(cfg config.Config, f db.ShareDaoFactory) *cluster {
 
 		listerFuncs: make(map[string]listerFunc),
 		getterFuncs: make(map[string]getterFunc),
+
+		recordings: recording.NewLocalStorage(cfg.Recording.Dir),
+
+		credentialWarnings: newCredentialWarnings(),
 	}
 
 	// TODO: code generation?
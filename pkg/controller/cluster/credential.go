@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/controller/alert/notify"
+	controllerutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	// credentialWarnInterval 同一集群过期告警的最小间隔
+	credentialWarnInterval  = 24 * time.Hour
+	credentialRotateTimeout = 30 * time.Second
+)
+
+// credentialWarnings 记录各集群最近一次凭据过期告警，由执行凭据同步任务的控制器持有
+type credentialWarnings struct {
+	mu   sync.Mutex
+	last map[int64]credentialWarning // key: clusterId
+}
+
+type credentialWarning struct {
+	expiresAt time.Time
+	at        time.Time
+}
+
+func newCredentialWarnings() *credentialWarnings {
+	return &credentialWarnings{last: make(map[int64]credentialWarning)}
+}
+
+// shouldWarn 同一凭据在 credentialWarnInterval 内只告警一次；凭据轮换或更新后过期时间变化，重新计算
+func (w *credentialWarnings) shouldWarn(clusterId int64, expiresAt, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if last, ok := w.last[clusterId]; ok && last.expiresAt.Equal(expiresAt) && now.Sub(last.at) < credentialWarnInterval {
+		return false
+	}
+	w.last[clusterId] = credentialWarning{expiresAt: expiresAt, at: now}
+	return true
+}
+
+// RotateCredential 为集群签发新的 Pixiu ServiceAccount token 并替换 kubeconfig 凭据。
+// 新凭据验证可用后才写库，轮换期间旧凭据持续有效，不影响正在进行的访问。
+func (c *cluster) RotateCredential(ctx context.Context, cid int64, req *types.RotateCredentialRequest) (*types.Cluster, error) {
+	object, err := c.factory.Cluster().Get(ctx, cid)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d): %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, errors.ErrClusterNotFound
+	}
+	// 轮换会修改集群凭据，仅所有者或超级管理员可操作
+	if err = controllerutil.CheckResourceAccess(ctx, c.factory, object.UserId, types.ResourceTypeCluster, cid); err != nil {
+		return nil, err
+	}
+	if object.PermissionId != 0 {
+		return nil, errors.NewError(fmt.Errorf("authorized cluster credentials are managed by its owner cluster"), http.StatusBadRequest)
+	}
+
+	hours := req.ExpireHours
+	if hours == 0 {
+		hours = c.cc.ClusterCredential.TokenExpireHours
+	}
+	if err = c.rotateCredential(ctx, object, time.Duration(hours)*time.Hour); err != nil {
+		klog.Errorf("failed to rotate credential for cluster(%s): %v", object.Name, err)
+		return nil, errors.NewError(err, http.StatusInternalServerError)
+	}
+
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	object, err = c.AuthorizeClusterAccess(ctx, user, cid)
+	if err != nil {
+		return nil, err
+	}
+	return c.model2Type(object), nil
+}
+
+func (c *cluster) rotateCredential(ctx context.Context, object *model.Cluster, expiration time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, credentialRotateTimeout)
+	defer cancel()
+
+	opts := client.ClusterSetOptions{ClusterName: object.Name, ConnectMode: object.ConnectMode}
+	cs, err := client.NewClusterSetWithOptions(object.KubeConfig, opts)
+	if err != nil {
+		return err
+	}
+	if err = c.ensurePixiuSystemNamespace(ctx, cs); err != nil {
+		return err
+	}
+	kubeConfig, info, err := client.IssueServiceAccountKubeconfig(ctx, cs.Client, object.KubeConfig, expiration)
+	if err != nil {
+		return err
+	}
+
+	// 先以新凭据访问集群，确认可用后再替换
+	newCS, err := client.NewClusterSetWithOptions(kubeConfig, opts)
+	if err != nil {
+		return err
+	}
+	if _, err = newCS.Client.CoreV1().Namespaces().Get(ctx, pixiuSystemNamespace, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("new credential verification failed: %v", err)
+	}
+
+	if err = c.factory.Cluster().InternalUpdate(ctx, object.Id, map[string]interface{}{
+		"kube_config":           kubeConfig,
+		"credential_type":       info.Type,
+		"credential_expires_at": info.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+	ClusterIndexer.Set(object.Name, *newCS)
+	klog.Infof("rotated credential for cluster(%s), expires at %v", object.Name, info.ExpiresAt)
+	return nil
+}
+
+// parseCredentialFields 解析 kubeconfig 凭据，解析失败时返回 unknown，不影响集群创建
+func parseCredentialFields(kubeConfig string) (string, *time.Time) {
+	info, err := client.ParseCredential(kubeConfig)
+	if err != nil {
+		klog.V(2).Infof("failed to parse kubeconfig credential: %v", err)
+		return client.CredentialUnknown, nil
+	}
+	return info.Type, info.ExpiresAt
+}
+
+// credentialDaysLeft 返回凭据剩余天数，已过期时为负数
+func credentialDaysLeft(expiresAt *time.Time) *int {
+	if expiresAt == nil {
+		return nil
+	}
+	left := time.Until(*expiresAt)
+	days := int(left / (24 * time.Hour))
+	if left < 0 {
+		days--
+	}
+	return &days
+}
+
+// credentialAction 判断凭据是否需要自动轮换或过期告警。
+// 仅运行中集群的 Pixiu 签发 token 会在剩余不足 RotateBeforeDays 时轮换，剩余不足 WarnDays 时告警
+func credentialAction(opts config.ClusterCredentialOptions, credType string, expiresAt *time.Time, status model.ClusterStatus, now time.Time) (rotate, warn bool) {
+	if expiresAt == nil {
+		return false, false
+	}
+	left := expiresAt.Sub(now)
+	rotate = credType == client.CredentialPixiuServiceAccount && opts.AutoRotateEnabled() &&
+		left < time.Duration(opts.RotateBeforeDays)*24*time.Hour && status == model.ClusterStatusRunning
+	warn = left < time.Duration(opts.WarnDays)*24*time.Hour
+	return rotate, warn
+}
+
+// SyncCredentials 刷新各集群凭据信息，自动轮换即将过期的 Pixiu token，并对即将过期的凭据告警
+func (c *cluster) SyncCredentials(ctx context.Context) (*types.CredentialSyncResult, error) {
+	objects, err := c.factory.Cluster().List(ctx, db.WithPermissionID(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %v", err)
+	}
+
+	result := &types.CredentialSyncResult{}
+	opts := c.cc.ClusterCredential
+	now := time.Now()
+	for i := range objects {
+		object := &objects[i]
+		if object.KubeConfig == "" {
+			continue
+		}
+		result.Checked++
+		credType, expiresAt := parseCredentialFields(object.KubeConfig)
+		if credType != object.CredentialType || !sameTime(expiresAt, object.CredentialExpiresAt) {
+			if err = c.factory.Cluster().InternalUpdate(ctx, object.Id, map[string]interface{}{
+				"credential_type":       credType,
+				"credential_expires_at": expiresAt,
+			}); err != nil {
+				klog.Errorf("[CredentialSyncer] failed to update credential of cluster(%s): %v", object.Name, err)
+				continue
+			}
+		}
+
+		rotate, warn := credentialAction(opts, credType, expiresAt, object.ClusterStatus, now)
+		if rotate {
+			if err = c.rotateCredential(ctx, object, time.Duration(opts.TokenExpireHours)*time.Hour); err == nil {
+				result.Rotated++
+				continue
+			}
+			klog.Errorf("[CredentialSyncer] failed to rotate credential of cluster(%s): %v", object.Name, err)
+		}
+		if warn && c.warnCredentialExpiry(ctx, object, credType, *expiresAt, now) {
+			result.Warned++
+		}
+	}
+	return result, nil
+}
+
+// warnCredentialExpiry 发送凭据过期告警，返回是否已发送
+func (c *cluster) warnCredentialExpiry(ctx context.Context, object *model.Cluster, credType string, expiresAt, now time.Time) bool {
+	channels := c.cc.ClusterCredential.NotifyChannels
+	if len(channels) == 0 || !c.credentialWarnings.shouldWarn(object.Id, expiresAt, now) {
+		return false
+	}
+
+	name := object.AliasName
+	if name == "" {
+		name = object.Name
+	}
+	days := *credentialDaysLeft(&expiresAt)
+	title := fmt.Sprintf("集群 %s 凭据即将过期", name)
+	content := fmt.Sprintf("集群 %s(%s) 的 kubeconfig 凭据（%s）将于 %s 过期，剩余 %d 天，请及时轮换。",
+		name, object.Name, credType, expiresAt.Format("2006-01-02 15:04:05"), days)
+	severity := model.AlertSeverityWarning
+	if days <= 0 {
+		title = fmt.Sprintf("集群 %s 凭据已过期", name)
+		content = fmt.Sprintf("集群 %s(%s) 的 kubeconfig 凭据（%s）已于 %s 过期，请尽快更新。",
+			name, object.Name, credType, expiresAt.Format("2006-01-02 15:04:05"))
+		severity = model.AlertSeverityCritical
+	}
+	if err := notify.NewManager(c.factory).EnqueueMessage(ctx, channels, title, content, severity); err != nil {
+		klog.Errorf("[CredentialSyncer] failed to enqueue expiry notification for cluster(%s): %v", object.Name, err)
+		return false
+	}
+	return true
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Unix() == b.Unix()
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestCredentialAction(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	days := func(n float64) *time.Time {
+		t := now.Add(time.Duration(n * float64(24*time.Hour)))
+		return &t
+	}
+	disabled := false
+	opts := config.ClusterCredentialOptions{WarnDays: 30, RotateBeforeDays: 7}
+
+	tests := []struct {
+		name       string
+		opts       config.ClusterCredentialOptions
+		credType   string
+		expiresAt  *time.Time
+		status     model.ClusterStatus
+		wantRotate bool
+		wantWarn   bool
+	}{
+		{name: "never expires", opts: opts, credType: client.CredentialServiceAccountToken, status: model.ClusterStatusRunning},
+		{name: "far from expiry", opts: opts, credType: client.CredentialClientCertificate, expiresAt: days(90), status: model.ClusterStatusRunning},
+		{name: "certificate within warn days", opts: opts, credType: client.CredentialClientCertificate, expiresAt: days(20), status: model.ClusterStatusRunning, wantWarn: true},
+		{name: "certificate expired", opts: opts, credType: client.CredentialClientCertificate, expiresAt: days(-1), status: model.ClusterStatusRunning, wantWarn: true},
+		{name: "pixiu token not yet due", opts: opts, credType: client.CredentialPixiuServiceAccount, expiresAt: days(10), status: model.ClusterStatusRunning, wantWarn: true},
+		{name: "pixiu token due", opts: opts, credType: client.CredentialPixiuServiceAccount, expiresAt: days(6.5), status: model.ClusterStatusRunning, wantRotate: true, wantWarn: true},
+		{name: "pixiu token due on unhealthy cluster", opts: opts, credType: client.CredentialPixiuServiceAccount, expiresAt: days(3), status: model.ClusterStatusError, wantWarn: true},
+		{name: "auto rotate disabled", opts: config.ClusterCredentialOptions{WarnDays: 30, RotateBeforeDays: 7, AutoRotate: &disabled},
+			credType: client.CredentialPixiuServiceAccount, expiresAt: days(3), status: model.ClusterStatusRunning, wantWarn: true},
+		{name: "foreign token is never rotated", opts: opts, credType: client.CredentialServiceAccountToken, expiresAt: days(3), status: model.ClusterStatusRunning, wantWarn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rotate, warn := credentialAction(tt.opts, tt.credType, tt.expiresAt, tt.status, now)
+			if rotate != tt.wantRotate || warn != tt.wantWarn {
+				t.Fatalf("credentialAction() = (rotate %v, warn %v), want (%v, %v)", rotate, warn, tt.wantRotate, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestCredentialWarningsShouldWarn(t *testing.T) {
+	w := newCredentialWarnings()
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(10 * 24 * time.Hour)
+
+	if !w.shouldWarn(1, expiresAt, now) {
+		t.Fatal("first warning should be sent")
+	}
+	if w.shouldWarn(1, expiresAt, now.Add(time.Hour)) {
+		t.Fatal("same credential should not be warned again within the interval")
+	}
+	if !w.shouldWarn(2, expiresAt, now.Add(time.Hour)) {
+		t.Fatal("warnings are tracked per cluster")
+	}
+	if !w.shouldWarn(1, expiresAt, now.Add(credentialWarnInterval)) {
+		t.Fatal("warning should be repeated after the interval")
+	}
+	// 凭据更换后过期时间变化，立即按新凭据重新告警
+	if !w.shouldWarn(1, expiresAt.Add(time.Hour), now.Add(credentialWarnInterval+time.Minute)) {
+		t.Fatal("a replaced credential should be warned immediately")
+	}
+}
+
+func TestCredentialDaysLeft(t *testing.T) {
+	if credentialDaysLeft(nil) != nil {
+		t.Fatal("unknown expiry should have no days left")
+	}
+	tests := []struct {
+		in   time.Duration
+		want int
+	}{
+		{in: 10*24*time.Hour + time.Hour, want: 10},
+		{in: 12 * time.Hour, want: 0},
+		{in: -time.Hour, want: -1},
+		{in: -(24*time.Hour + time.Hour), want: -2},
+	}
+	for _, tt := range tests {
+		at := time.Now().Add(tt.in)
+		if got := credentialDaysLeft(&at); got == nil || *got != tt.want {
+			t.Errorf("credentialDaysLeft(now%+v) = %v, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	defaultHealthWindow = 30 * 24 * time.Hour
+	// maxHealthWindow 统计窗口上限，避免一次性加载过多事件
+	maxHealthWindow = 366 * 24 * time.Hour
+)
+
+type HealthInterface interface {
+	// ListEvents 分页查询集群健康事件（倒序）
+	ListEvents(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.PageResult, error)
+	// Availability 统计窗口内集群可用率
+	Availability(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.ClusterAvailability, error)
+	// NodeFlaps 统计窗口内节点 Ready/NotReady 抖动
+	NodeFlaps(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.ClusterNodeFlaps, error)
+}
+
+type health struct {
+	c *cluster
+}
+
+func (c *cluster) Health() HealthInterface {
+	return &health{c: c}
+}
+
+func (h *health) authorize(ctx context.Context, cid int64) (*model.Cluster, error) {
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return h.c.AuthorizeClusterAccess(ctx, user, cid)
+}
+
+// healthWindow 解析统计窗口，返回 [start, end)
+func healthWindow(query *types.ClusterHealthQuery) (time.Time, time.Time, error) {
+	end := time.Now()
+	if !query.End.IsZero() && query.End.Before(end) {
+		end = query.End
+	}
+	if !query.Start.IsZero() {
+		if !query.Start.Before(end) {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be before end")
+		}
+		if end.Sub(query.Start) > maxHealthWindow {
+			return time.Time{}, time.Time{}, fmt.Errorf("window must not exceed %s", maxHealthWindow)
+		}
+		return query.Start, end, nil
+	}
+
+	window := defaultHealthWindow
+	if query.Window != "" {
+		d, err := time.ParseDuration(query.Window)
+		if err != nil || d <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window %q", query.Window)
+		}
+		if d > maxHealthWindow {
+			return time.Time{}, time.Time{}, fmt.Errorf("window must not exceed %s", maxHealthWindow)
+		}
+		window = d
+	}
+	return end.Add(-window), end, nil
+}
+
+func (h *health) ListEvents(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.PageResult, error) {
+	if _, err := h.authorize(ctx, cid); err != nil {
+		return nil, err
+	}
+
+	opts := []db.Options{
+		db.WithClusterId(cid),
+		db.WithClusterEventType(model.ClusterEventType(query.Type)),
+		db.WithClusterEventObject(query.Object),
+		db.WithCreatedAfter(query.Start),
+	}
+	if !query.End.IsZero() {
+		opts = append(opts, db.WithCreatedBefore(query.End))
+	}
+
+	listOption := types.ListOptions{PageRequest: query.PageRequest}
+	listOption.SetDefaultPageOption()
+	pageResult := &types.PageResult{PageRequest: listOption.PageRequest}
+
+	var err error
+	if pageResult.Total, err = h.c.factory.Cluster().Event().Count(ctx, opts...); err != nil {
+		klog.Errorf("failed to count cluster(%d) events: %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	objects, err := h.c.factory.Cluster().Event().List(ctx, append(opts,
+		db.WithOffset((listOption.Page-1)*listOption.Limit),
+		db.WithLimit(listOption.Limit),
+		db.WithOrderByDesc(),
+	)...)
+	if err != nil {
+		klog.Errorf("failed to list cluster(%d) events: %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	items := make([]types.ClusterEvent, 0, len(objects))
+	for i := range objects {
+		items = append(items, clusterEvent2Type(&objects[i]))
+	}
+	pageResult.Items = items
+	return pageResult, nil
+}
+
+func (h *health) Availability(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.ClusterAvailability, error) {
+	object, err := h.authorize(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := healthWindow(query)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	// 集群创建前的时间不计入统计
+	if object.GmtCreate.After(start) {
+		start = object.GmtCreate
+	}
+
+	result := &types.ClusterAvailability{
+		ClusterId:   object.Id,
+		ClusterName: object.Name,
+		Start:       start,
+		End:         end,
+	}
+	if !start.Before(end) {
+		result.Current = model.ClusterStatusName(object.ClusterStatus)
+		return result, nil
+	}
+
+	events, err := h.c.factory.Cluster().Event().List(ctx,
+		db.WithClusterId(cid),
+		db.WithClusterEventType(model.ClusterEventStatus),
+		db.WithCreatedAfter(start),
+		db.WithCreatedBefore(end),
+		db.WithOrderByASC(),
+	)
+	if err != nil {
+		klog.Errorf("failed to list cluster(%d) status events: %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+	state, err := h.stateAt(ctx, object, start, events)
+	if err != nil {
+		return nil, err
+	}
+
+	running := model.ClusterStatusName(model.ClusterStatusRunning)
+	unavailable := model.ClusterStatusName(model.ClusterStatusError)
+	var outageStart time.Time
+	accumulate := func(from, to time.Time) {
+		d := int64(to.Sub(from).Seconds())
+		switch state {
+		case running:
+			result.UpSeconds += d
+		case unavailable:
+			result.DownSeconds += d
+		default:
+			result.OtherSeconds += d
+		}
+	}
+	closeOutage := func(at time.Time) {
+		if outageStart.IsZero() {
+			return
+		}
+		if d := int64(at.Sub(outageStart).Seconds()); d > result.LongestOutageSeconds {
+			result.LongestOutageSeconds = d
+		}
+		outageStart = time.Time{}
+	}
+	if state == unavailable {
+		outageStart = start
+	}
+
+	cursor := start
+	for _, e := range events {
+		accumulate(cursor, e.GmtCreate)
+		if e.To == unavailable && state != unavailable {
+			outageStart = e.GmtCreate
+			if state == running {
+				result.Outages++
+			}
+		} else if e.To != unavailable {
+			closeOutage(e.GmtCreate)
+		}
+		state, cursor = e.To, e.GmtCreate
+		result.Transitions++
+	}
+	accumulate(cursor, end)
+	closeOutage(end)
+
+	result.Current = state
+	if measured := result.UpSeconds + result.DownSeconds; measured > 0 {
+		percent := float64(result.UpSeconds) * 100 / float64(measured)
+		result.Availability = &percent
+	}
+	return result, nil
+}
+
+// stateAt 推断窗口起点的集群状态：优先取起点前最后一条状态事件，其次取窗口内首条事件的 From，
+// 均不存在时说明窗口内状态未变化，使用集群当前状态
+func (h *health) stateAt(ctx context.Context, object *model.Cluster, start time.Time, events []model.ClusterEvent) (string, error) {
+	before, err := h.c.factory.Cluster().Event().List(ctx,
+		db.WithClusterId(object.Id),
+		db.WithClusterEventType(model.ClusterEventStatus),
+		db.WithCreatedBefore(start),
+		db.WithOrderByDesc(),
+		db.WithLimit(1),
+	)
+	if err != nil {
+		klog.Errorf("failed to get cluster(%d) status before %v: %v", object.Id, start, err)
+		return "", errors.ErrServerInternal
+	}
+	if len(before) != 0 {
+		return before[0].To, nil
+	}
+	if len(events) != 0 {
+		return events[0].From, nil
+	}
+	return model.ClusterStatusName(object.ClusterStatus), nil
+}
+
+func (h *health) NodeFlaps(ctx context.Context, cid int64, query *types.ClusterHealthQuery) (*types.ClusterNodeFlaps, error) {
+	object, err := h.authorize(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := healthWindow(query)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
+	events, err := h.c.factory.Cluster().Event().List(ctx,
+		db.WithClusterId(cid),
+		db.WithClusterEventType(model.ClusterEventNode),
+		db.WithClusterEventObject(query.Object),
+		db.WithCreatedAfter(start),
+		db.WithCreatedBefore(end),
+		db.WithOrderByASC(),
+	)
+	if err != nil {
+		klog.Errorf("failed to list cluster(%d) node events: %v", cid, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	flaps := make(map[string]*types.NodeFlap)
+	for _, e := range events {
+		f, ok := flaps[e.Object]
+		if !ok {
+			f = &types.NodeFlap{Node: e.Object}
+			flaps[e.Object] = f
+		}
+		f.Flaps++
+		if e.To == model.NodeStateNotReady {
+			f.NotReadyCount++
+		}
+		f.LastState, f.LastChange = e.To, e.GmtCreate
+	}
+
+	nodes := make([]types.NodeFlap, 0, len(flaps))
+	for _, f := range flaps {
+		nodes = append(nodes, *f)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Flaps != nodes[j].Flaps {
+			return nodes[i].Flaps > nodes[j].Flaps
+		}
+		return nodes[i].Node < nodes[j].Node
+	})
+
+	return &types.ClusterNodeFlaps{
+		ClusterId:   object.Id,
+		ClusterName: object.Name,
+		Start:       start,
+		End:         end,
+		Nodes:       nodes,
+	}, nil
+}
+
+func clusterEvent2Type(o *model.ClusterEvent) types.ClusterEvent {
+	return types.ClusterEvent{
+		Id:          o.Id,
+		ClusterId:   o.ClusterId,
+		ClusterName: o.ClusterName,
+		Type:        o.Type,
+		Source:      o.Source,
+		Object:      o.Object,
+		From:        o.From,
+		To:          o.To,
+		Reason:      o.Reason,
+		Message:     o.Message,
+		GmtCreate:   o.GmtCreate,
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+// dryRun 仅用于把查询选项渲染成 SQL，不会连接数据库
+var dryRun, _ = gorm.Open(mysql.New(mysql.Config{
+	DSN:                       "pixiu:pixiu@tcp(127.0.0.1:3306)/pixiu?parseTime=true",
+	SkipInitializeWithVersion: true,
+}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+
+func renderSQL(object interface{}, opts []db.Options) string {
+	tx := dryRun.Model(object)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	stmt := tx.Find(object).Statement
+	return dryRun.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+}
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	clusters *fakeClusters
+}
+
+func (f *fakeFactory) Cluster() db.ClusterInterface { return f.clusters }
+
+type fakeClusters struct {
+	db.ClusterInterface
+	object *model.Cluster
+	events *fakeClusterEvents
+}
+
+func (c *fakeClusters) Get(_ context.Context, cid int64, _ ...db.Options) (*model.Cluster, error) {
+	if c.object == nil || c.object.Id != cid {
+		return nil, nil
+	}
+	return c.object, nil
+}
+
+func (c *fakeClusters) Event() db.ClusterEventInterface { return c.events }
+
+// fakeClusterEvents 倒序且 LIMIT 1 的查询返回窗口起点前的事件，其余返回窗口内事件
+type fakeClusterEvents struct {
+	db.ClusterEventInterface
+	before []model.ClusterEvent
+	window []model.ClusterEvent
+	err    error
+}
+
+func (e *fakeClusterEvents) List(_ context.Context, opts ...db.Options) ([]model.ClusterEvent, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	sql := renderSQL(&[]model.ClusterEvent{}, opts)
+	if strings.Contains(sql, "ORDER BY id DESC LIMIT 1") {
+		return e.before, nil
+	}
+	return e.window, nil
+}
+
+func statusEvent(at time.Time, from, to model.ClusterStatus) model.ClusterEvent {
+	e := model.ClusterEvent{Type: model.ClusterEventStatus, From: model.ClusterStatusName(from), To: model.ClusterStatusName(to)}
+	e.GmtCreate = at
+	return e
+}
+
+func newHealth(object *model.Cluster, events *fakeClusterEvents) *health {
+	return &health{c: &cluster{factory: &fakeFactory{clusters: &fakeClusters{object: object, events: events}}}}
+}
+
+func rootContext() context.Context {
+	c := &gin.Context{}
+	user := &model.User{Name: "root", Role: model.RoleRoot}
+	user.Id = 1
+	httputils.SetUserToContext(c, user)
+	return c
+}
+
+func TestStateAt(t *testing.T) {
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	object := &model.Cluster{Name: "prod", ClusterStatus: model.ClusterStatusRunning}
+
+	tests := []struct {
+		name   string
+		events *fakeClusterEvents
+		window []model.ClusterEvent
+		want   string
+		err    bool
+	}{
+		{
+			name:   "last event before start",
+			events: &fakeClusterEvents{before: []model.ClusterEvent{statusEvent(start.Add(-time.Hour), model.ClusterStatusRunning, model.ClusterStatusError)}},
+			window: []model.ClusterEvent{statusEvent(start.Add(time.Hour), model.ClusterStatusDeploy, model.ClusterStatusRunning)},
+			want:   "Error",
+		},
+		{
+			name:   "first window event from state",
+			events: &fakeClusterEvents{},
+			window: []model.ClusterEvent{statusEvent(start.Add(time.Hour), model.ClusterStatusDeploy, model.ClusterStatusRunning)},
+			want:   "Deploying",
+		},
+		{name: "no events uses current status", events: &fakeClusterEvents{}, want: "Running"},
+		{name: "list failure", events: &fakeClusterEvents{err: fmt.Errorf("db down")}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newHealth(object, tt.events).stateAt(context.TODO(), object, start, tt.window)
+			if tt.err {
+				if err != errors.ErrServerInternal {
+					t.Errorf("expected internal error, got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("stateAt() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	end := time.Now().Add(-time.Hour).Truncate(time.Second)
+	start := end.Add(-10 * time.Hour)
+	at := func(h float64) time.Time { return start.Add(time.Duration(h * float64(time.Hour))) }
+	hours := func(h float64) int64 { return int64(h * 3600) }
+	running, failed, deploying := model.ClusterStatusRunning, model.ClusterStatusError, model.ClusterStatusDeploy
+
+	tests := []struct {
+		name      string
+		created   time.Time
+		status    model.ClusterStatus
+		before    []model.ClusterEvent
+		window    []model.ClusterEvent
+		want      types.ClusterAvailability
+		wantRatio float64
+	}{
+		{
+			name:      "running without events",
+			created:   start.Add(-24 * time.Hour),
+			status:    running,
+			want:      types.ClusterAvailability{UpSeconds: hours(10), Current: "Running"},
+			wantRatio: 100,
+		},
+		{
+			name:    "one outage",
+			created: start.Add(-24 * time.Hour),
+			status:  running,
+			before:  []model.ClusterEvent{statusEvent(start.Add(-time.Hour), deploying, running)},
+			window:  []model.ClusterEvent{statusEvent(at(1), running, failed), statusEvent(at(3), failed, running)},
+			want: types.ClusterAvailability{
+				UpSeconds: hours(8), DownSeconds: hours(2),
+				Outages: 1, LongestOutageSeconds: hours(2), Transitions: 2, Current: "Running",
+			},
+			wantRatio: 80,
+		},
+		{
+			name:    "down at window start is not a new outage",
+			created: start.Add(-24 * time.Hour),
+			status:  running,
+			window:  []model.ClusterEvent{statusEvent(at(2.5), failed, running)},
+			want: types.ClusterAvailability{
+				UpSeconds: hours(7.5), DownSeconds: hours(2.5),
+				LongestOutageSeconds: hours(2.5), Transitions: 1, Current: "Running",
+			},
+			wantRatio: 75,
+		},
+		{
+			name:    "outage lasting until window end",
+			created: start.Add(-24 * time.Hour),
+			status:  failed,
+			before:  []model.ClusterEvent{statusEvent(start.Add(-time.Hour), deploying, running)},
+			window:  []model.ClusterEvent{statusEvent(at(6), running, failed)},
+			want: types.ClusterAvailability{
+				UpSeconds: hours(6), DownSeconds: hours(4),
+				Outages: 1, LongestOutageSeconds: hours(4), Transitions: 1, Current: "Error",
+			},
+			wantRatio: 60,
+		},
+		{
+			name:      "created inside the window",
+			created:   at(5),
+			status:    running,
+			before:    []model.ClusterEvent{statusEvent(at(5), 0, running)},
+			want:      types.ClusterAvailability{UpSeconds: hours(5), Current: "Running"},
+			wantRatio: 100,
+		},
+		{
+			name:    "deploying is not measured",
+			created: start.Add(-24 * time.Hour),
+			status:  deploying,
+			want:    types.ClusterAvailability{OtherSeconds: hours(10), Current: "Deploying"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object := &model.Cluster{Name: "prod", ClusterStatus: tt.status}
+			object.Id = 7
+			object.GmtCreate = tt.created
+
+			got, err := newHealth(object, &fakeClusterEvents{before: tt.before, window: tt.window}).
+				Availability(rootContext(), 7, &types.ClusterHealthQuery{Start: start, End: end})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.UpSeconds != tt.want.UpSeconds || got.DownSeconds != tt.want.DownSeconds || got.OtherSeconds != tt.want.OtherSeconds ||
+				got.Outages != tt.want.Outages || got.LongestOutageSeconds != tt.want.LongestOutageSeconds ||
+				got.Transitions != tt.want.Transitions || got.Current != tt.want.Current {
+				t.Errorf("unexpected availability %+v, want %+v", got, tt.want)
+			}
+			if tt.wantRatio == 0 {
+				if got.Availability != nil {
+					t.Errorf("expected no availability, got %v", *got.Availability)
+				}
+			} else if got.Availability == nil || *got.Availability != tt.wantRatio {
+				t.Errorf("expected availability %v, got %v", tt.wantRatio, got.Availability)
+			}
+		})
+	}
+}
+
+func TestAvailabilityInvalidWindow(t *testing.T) {
+	object := &model.Cluster{Name: "prod"}
+	object.Id = 7
+	h := newHealth(object, &fakeClusterEvents{})
+
+	if _, err := h.Availability(rootContext(), 7, &types.ClusterHealthQuery{Window: "abc"}); err == nil {
+		t.Errorf("expected invalid window to be rejected")
+	}
+	if _, err := h.Availability(rootContext(), 8, &types.ClusterHealthQuery{}); err != errors.ErrClusterNotFound {
+		t.Errorf("expected cluster not found, got %v", err)
+	}
+	if _, err := h.Availability(context.TODO(), 7, &types.ClusterHealthQuery{}); err != errors.ErrUnauthorized {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
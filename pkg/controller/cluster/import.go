@@ -0,0 +1,367 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	// importPingTimeout 单个上下文连通测试超时
+	importPingTimeout = 10 * time.Second
+	// importPingWorkers 并发连通测试数
+	importPingWorkers = 8
+	// maxImportContexts 单次导入的上下文数量上限
+	maxImportContexts = 100
+	maxClusterNameLen = 63
+)
+
+var invalidClusterNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ListImportContexts 解析多上下文 kubeconfig，返回各上下文的连接信息与可导入性
+func (c *cluster) ListImportContexts(ctx context.Context, req *types.ParseKubeconfigRequest) ([]types.KubeconfigContext, error) {
+	cfg, err := loadImportKubeconfig(req.KubeConfig)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	existing, err := c.existingClusterIndex(ctx)
+	if err != nil {
+		return nil, errors.ErrServerInternal
+	}
+
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contexts := make([]types.KubeconfigContext, 0, len(names))
+	for _, name := range names {
+		kc := describeKubeconfigContext(cfg, name)
+		kc.ExistingCluster = existing.lookup(importClusterName(name, ""), kc.Server)
+		contexts = append(contexts, kc)
+	}
+	return contexts, nil
+}
+
+// Import 批量导入选中的上下文：逐个连通测试后创建集群，返回每个上下文的处理结果。
+// 单个上下文失败不影响其他上下文。
+func (c *cluster) Import(ctx context.Context, req *types.ImportClustersRequest) (*types.ImportClustersResult, error) {
+	if len(req.Contexts) > maxImportContexts {
+		return nil, errors.NewError(fmt.Errorf("at most %d contexts can be imported at once", maxImportContexts), http.StatusBadRequest)
+	}
+	cfg, err := loadImportKubeconfig(req.KubeConfig)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	existing, err := c.existingClusterIndex(ctx)
+	if err != nil {
+		return nil, errors.ErrServerInternal
+	}
+
+	type candidate struct {
+		result     *types.ImportContextResult
+		req        types.ImportContext
+		kubeConfig string
+		pingErr    error
+	}
+
+	result := &types.ImportClustersResult{Items: make([]types.ImportContextResult, len(req.Contexts))}
+	var pending []*candidate
+	for i, item := range req.Contexts {
+		r := &result.Items[i]
+		r.Context = item.Context
+		r.Name = importClusterName(item.Context, item.Name)
+
+		if _, ok := cfg.Contexts[item.Context]; !ok {
+			r.Status, r.Reason = types.ImportStatusFailed, "context not found in kubeconfig"
+			continue
+		}
+		kc := describeKubeconfigContext(cfg, item.Context)
+		if !kc.Supported {
+			r.Status, r.Reason = types.ImportStatusFailed, kc.Reason
+			continue
+		}
+		if dup := existing.lookup(r.Name, kc.Server); dup != "" {
+			r.Status, r.Reason = types.ImportStatusSkipped, fmt.Sprintf("duplicate of cluster %s", dup)
+			continue
+		}
+		kubeConfig, err := extractContextKubeconfig(cfg, item.Context)
+		if err != nil {
+			r.Status, r.Reason = types.ImportStatusFailed, err.Error()
+			continue
+		}
+		// 同批次内的重复上下文也只导入一次
+		existing.add(r.Name, kc.Server)
+		pending = append(pending, &candidate{result: r, req: item, kubeConfig: kubeConfig})
+	}
+
+	// 并发连通测试
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, importPingWorkers)
+	for _, p := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p *candidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pingCtx, cancel := context.WithTimeout(ctx, importPingTimeout)
+			defer cancel()
+			p.pingErr = c.Ping(pingCtx, p.kubeConfig)
+		}(p)
+	}
+	wg.Wait()
+
+	server := c.agentServerURL(ctx)
+	for _, p := range pending {
+		r := p.result
+		createReq := &types.CreateClusterRequest{
+			Name:        r.Name,
+			AliasName:   p.req.AliasName,
+			UserId:      req.UserId,
+			KubeConfig:  p.kubeConfig,
+			ConnectMode: model.ConnectModeDirect,
+			Description: p.req.Description,
+			Protected:   req.Protected,
+		}
+		if createReq.AliasName == "" {
+			createReq.AliasName = p.req.Context
+		}
+		if p.pingErr != nil {
+			if !req.TunnelOnUnreachable {
+				r.Status, r.Reason = types.ImportStatusUnreachable, p.pingErr.Error()
+				continue
+			}
+			createReq.ConnectMode = model.ConnectModeTunnel
+		}
+
+		obj, err := c.Create(ctx, createReq)
+		if err != nil {
+			klog.Errorf("failed to import context %s as cluster %s: %v", r.Context, r.Name, err)
+			r.Status, r.Reason = types.ImportStatusFailed, err.Error()
+			continue
+		}
+		r.ClusterId = obj.Id
+		if createReq.ConnectMode != model.ConnectModeTunnel {
+			r.Status = types.ImportStatusCreated
+			continue
+		}
+
+		r.Status = types.ImportStatusTunnel
+		r.Reason = fmt.Sprintf("%v; deploy cluster-agent to connect", p.pingErr)
+		if r.AgentManifest, err = renderAgentManifest(obj.Name, server, obj.AgentToken, c.cc.Default.AgentImage); err != nil {
+			klog.Errorf("failed to render agent manifest for cluster %s: %v", obj.Name, err)
+		}
+	}
+
+	for _, r := range result.Items {
+		switch r.Status {
+		case types.ImportStatusCreated:
+			result.Created++
+		case types.ImportStatusTunnel:
+			result.Tunnel++
+		case types.ImportStatusSkipped:
+			result.Skipped++
+		case types.ImportStatusUnreachable:
+			result.Unreachable++
+		default:
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+func loadImportKubeconfig(raw string) (*clientcmdapi.Config, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("kube_config must be base64 encoded: %v", err)
+	}
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	if len(cfg.Contexts) == 0 {
+		return nil, fmt.Errorf("kubeconfig has no contexts")
+	}
+	return cfg, nil
+}
+
+// describeKubeconfigContext 描述上下文并判断 Pixiu 能否使用其凭据。
+// 云厂商导出的 kubeconfig 常通过 exec 调用本地 CLI 获取 token，服务端无法执行，需换成静态凭据。
+func describeKubeconfigContext(cfg *clientcmdapi.Config, name string) types.KubeconfigContext {
+	kctx := cfg.Contexts[name]
+	kc := types.KubeconfigContext{
+		Name:      name,
+		Cluster:   kctx.Cluster,
+		User:      kctx.AuthInfo,
+		Namespace: kctx.Namespace,
+		Current:   cfg.CurrentContext == name,
+	}
+
+	cluster, ok := cfg.Clusters[kctx.Cluster]
+	if !ok {
+		kc.Reason = fmt.Sprintf("cluster %q not found", kctx.Cluster)
+		return kc
+	}
+	kc.Server = cluster.Server
+	authInfo, ok := cfg.AuthInfos[kctx.AuthInfo]
+	if !ok {
+		kc.AuthType, kc.Reason = "none", fmt.Sprintf("user %q not found", kctx.AuthInfo)
+		return kc
+	}
+
+	switch {
+	case authInfo.Exec != nil:
+		kc.AuthType = "exec"
+		kc.Reason = fmt.Sprintf("credentials come from exec plugin %q which Pixiu cannot run; use a static token or client certificate", authInfo.Exec.Command)
+	case authInfo.AuthProvider != nil:
+		kc.AuthType = "auth-provider"
+		kc.Reason = fmt.Sprintf("auth provider %q is not supported; use a static token or client certificate", authInfo.AuthProvider.Name)
+	case authInfo.Token != "":
+		kc.AuthType = "token"
+	case authInfo.TokenFile != "":
+		kc.AuthType, kc.Reason = "token", "token file references a local path; embed the token instead"
+	case len(authInfo.ClientCertificateData) != 0 && len(authInfo.ClientKeyData) != 0:
+		kc.AuthType = "client-certificate"
+	case authInfo.ClientCertificate != "" || authInfo.ClientKey != "":
+		kc.AuthType, kc.Reason = "client-certificate", "client certificate references a local path; embed the certificate data instead"
+	case authInfo.Username != "":
+		kc.AuthType = "basic"
+	default:
+		kc.AuthType, kc.Reason = "none", "no credentials configured"
+	}
+	if kc.Reason == "" && cluster.Server == "" {
+		kc.Reason = "cluster server is empty"
+	}
+	if kc.Reason == "" && cluster.CertificateAuthority != "" && len(cluster.CertificateAuthorityData) == 0 {
+		kc.Reason = "certificate authority references a local path; embed the CA data instead"
+	}
+	kc.Supported = kc.Reason == ""
+	return kc
+}
+
+// extractContextKubeconfig 生成只包含指定上下文的 kubeconfig（base64），作为单个集群的 KubeConfig 存储
+func extractContextKubeconfig(cfg *clientcmdapi.Config, name string) (string, error) {
+	kctx := cfg.Contexts[name]
+	out := clientcmdapi.NewConfig()
+	out.Clusters[kctx.Cluster] = cfg.Clusters[kctx.Cluster]
+	out.AuthInfos[kctx.AuthInfo] = cfg.AuthInfos[kctx.AuthInfo]
+	out.Contexts[name] = kctx
+	out.CurrentContext = name
+
+	data, err := clientcmd.Write(*out)
+	if err != nil {
+		return "", fmt.Errorf("failed to build kubeconfig for context %s: %v", name, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// importClusterName 返回导入后的集群名：优先使用指定名称，否则由上下文名转换为小写字母、数字与中划线
+func importClusterName(contextName, name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	name = invalidClusterNameChars.ReplaceAllString(strings.ToLower(contextName), "-")
+	if len(name) > maxClusterNameLen {
+		name = name[:maxClusterNameLen]
+	}
+	return strings.Trim(name, "-")
+}
+
+// clusterIndex 已接入集群的名称与 server 地址索引，用于识别重复导入
+type clusterIndex struct {
+	names   map[string]string
+	servers map[string]string
+}
+
+func (c *cluster) existingClusterIndex(ctx context.Context) (*clusterIndex, error) {
+	objects, err := c.factory.Cluster().List(ctx, db.WithPermissionID(0))
+	if err != nil {
+		klog.Errorf("failed to list clusters: %v", err)
+		return nil, err
+	}
+	idx := &clusterIndex{
+		names:   make(map[string]string, len(objects)),
+		servers: make(map[string]string, len(objects)),
+	}
+	for _, object := range objects {
+		idx.names[object.Name] = object.Name
+		if server := kubeconfigServer(object.KubeConfig); server != "" {
+			idx.servers[server] = object.Name
+		}
+	}
+	return idx, nil
+}
+
+func (idx *clusterIndex) lookup(name, server string) string {
+	if existing, ok := idx.names[name]; ok && name != "" {
+		return existing
+	}
+	if existing, ok := idx.servers[normalizeServer(server)]; ok && server != "" {
+		return existing
+	}
+	return ""
+}
+
+func (idx *clusterIndex) add(name, server string) {
+	idx.names[name] = name
+	if server != "" {
+		idx.servers[normalizeServer(server)] = name
+	}
+}
+
+// kubeconfigServer 返回已存储 kubeconfig 当前上下文的 server 地址
+func kubeconfigServer(kubeConfig string) string {
+	data, err := client.ParseKubeConfigBytes(kubeConfig)
+	if err != nil {
+		return ""
+	}
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return ""
+	}
+	kctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return ""
+	}
+	if cluster, ok := cfg.Clusters[kctx.Cluster]; ok {
+		return normalizeServer(cluster.Server)
+	}
+	return ""
+}
+
+func normalizeServer(server string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(server)), "/")
+}
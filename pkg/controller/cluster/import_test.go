@@ -0,0 +1,194 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// newImportKubeconfig 生成包含多种凭据类型上下文的 kubeconfig
+func newImportKubeconfig() *clientcmdapi.Config {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://10.0.0.1:6443", CertificateAuthorityData: []byte("ca")}
+	cfg.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev.example.com:6443/", CertificateAuthority: "/etc/ca.crt"}
+	cfg.Clusters["empty"] = &clientcmdapi.Cluster{}
+
+	cfg.AuthInfos["token"] = &clientcmdapi.AuthInfo{Token: "abc"}
+	cfg.AuthInfos["cert"] = &clientcmdapi.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}
+	cfg.AuthInfos["cert-file"] = &clientcmdapi.AuthInfo{ClientCertificate: "/etc/cert.crt", ClientKey: "/etc/cert.key"}
+	cfg.AuthInfos["exec"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws"}}
+	cfg.AuthInfos["provider"] = &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "gcp"}}
+	cfg.AuthInfos["basic"] = &clientcmdapi.AuthInfo{Username: "admin", Password: "admin"}
+	cfg.AuthInfos["none"] = &clientcmdapi.AuthInfo{}
+
+	contexts := map[string][2]string{
+		"prod-token":    {"prod", "token"},
+		"prod-cert":     {"prod", "cert"},
+		"prod-certfile": {"prod", "cert-file"},
+		"prod-exec":     {"prod", "exec"},
+		"prod-provider": {"prod", "provider"},
+		"prod-basic":    {"prod", "basic"},
+		"prod-none":     {"prod", "none"},
+		"prod-nouser":   {"prod", "missing"},
+		"dev-token":     {"dev", "token"},
+		"empty-token":   {"empty", "token"},
+		"missing":       {"missing", "token"},
+	}
+	for name, ref := range contexts {
+		cfg.Contexts[name] = &clientcmdapi.Context{Cluster: ref[0], AuthInfo: ref[1], Namespace: "default"}
+	}
+	cfg.CurrentContext = "prod-token"
+	return cfg
+}
+
+func encodeKubeconfig(t *testing.T, cfg *clientcmdapi.Config) string {
+	t.Helper()
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestLoadImportKubeconfig(t *testing.T) {
+	if _, err := loadImportKubeconfig(" " + encodeKubeconfig(t, newImportKubeconfig()) + "\n"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "not base64", raw: "not base64!"},
+		{name: "not kubeconfig", raw: base64.StdEncoding.EncodeToString([]byte("{{"))},
+		{name: "no contexts", raw: encodeKubeconfig(t, clientcmdapi.NewConfig())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadImportKubeconfig(tt.raw); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestDescribeKubeconfigContext(t *testing.T) {
+	cfg := newImportKubeconfig()
+	tests := []struct {
+		context   string
+		authType  string
+		supported bool
+		reason    string
+	}{
+		{context: "prod-token", authType: "token", supported: true},
+		{context: "prod-cert", authType: "client-certificate", supported: true},
+		{context: "prod-basic", authType: "basic", supported: true},
+		{context: "prod-certfile", authType: "client-certificate", reason: "local path"},
+		{context: "prod-exec", authType: "exec", reason: `exec plugin "aws"`},
+		{context: "prod-provider", authType: "auth-provider", reason: `auth provider "gcp"`},
+		{context: "prod-none", authType: "none", reason: "no credentials"},
+		{context: "prod-nouser", authType: "none", reason: `user "missing" not found`},
+		{context: "dev-token", authType: "token", reason: "certificate authority references a local path"},
+		{context: "empty-token", authType: "token", reason: "cluster server is empty"},
+		{context: "missing", reason: `cluster "missing" not found`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			kc := describeKubeconfigContext(cfg, tt.context)
+			if kc.AuthType != tt.authType || kc.Supported != tt.supported || !strings.Contains(kc.Reason, tt.reason) {
+				t.Errorf("unexpected context %+v", kc)
+			}
+			if tt.supported && kc.Reason != "" {
+				t.Errorf("supported context has reason %q", kc.Reason)
+			}
+		})
+	}
+
+	if kc := describeKubeconfigContext(cfg, "prod-token"); !kc.Current || kc.Server != "https://10.0.0.1:6443" || kc.Namespace != "default" {
+		t.Errorf("unexpected current context %+v", kc)
+	}
+}
+
+func TestExtractContextKubeconfig(t *testing.T) {
+	raw, err := extractContextKubeconfig(newImportKubeconfig(), "prod-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(raw)
+	out, err := clientcmd.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Contexts) != 1 || len(out.Clusters) != 1 || len(out.AuthInfos) != 1 || out.CurrentContext != "prod-cert" {
+		t.Errorf("unexpected kubeconfig %+v", out)
+	}
+	if out.AuthInfos["cert"] == nil || string(out.AuthInfos["cert"].ClientKeyData) != "key" {
+		t.Errorf("credentials of the context were not kept")
+	}
+	if server := kubeconfigServer(raw); server != "https://10.0.0.1:6443" {
+		t.Errorf("unexpected server %q", server)
+	}
+}
+
+func TestImportClusterName(t *testing.T) {
+	tests := []struct {
+		context, name, want string
+	}{
+		{context: "ctx", name: " custom ", want: "custom"},
+		{context: "arn:aws:eks:us-east-1:123:cluster/Prod", want: "arn-aws-eks-us-east-1-123-cluster-prod"},
+		{context: "_Kind_Dev_", want: "kind-dev"},
+		{context: strings.Repeat("a", 70), want: strings.Repeat("a", maxClusterNameLen)},
+		{context: strings.Repeat("a", 62) + "@b", want: strings.Repeat("a", 62)},
+	}
+	for _, tt := range tests {
+		if got := importClusterName(tt.context, tt.name); got != tt.want {
+			t.Errorf("importClusterName(%q, %q) = %q, want %q", tt.context, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClusterIndex(t *testing.T) {
+	idx := &clusterIndex{
+		names:   map[string]string{"prod": "prod"},
+		servers: map[string]string{"https://10.0.0.1:6443": "prod"},
+	}
+	idx.add("dev", "HTTPS://Dev.example.com:6443/")
+
+	tests := []struct {
+		name, server, want string
+	}{
+		{name: "prod", want: "prod"},
+		{name: "other", server: "https://10.0.0.1:6443/", want: "prod"},
+		{name: "other", server: "https://dev.example.com:6443", want: "dev"},
+		{name: "other", server: "https://10.0.0.2:6443"},
+		{name: "", server: ""},
+	}
+	for _, tt := range tests {
+		if got := idx.lookup(tt.name, tt.server); got != tt.want {
+			t.Errorf("lookup(%q, %q) = %q, want %q", tt.name, tt.server, got, tt.want)
+		}
+	}
+
+	if server := kubeconfigServer("not base64!"); server != "" {
+		t.Errorf("expected empty server for invalid kubeconfig, got %q", server)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/auditsink"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/recording"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+// terminalRecording 一次交互式终端会话的录像
+type terminalRecording struct {
+	*recording.Recorder
+
+	c       *cluster
+	session *model.TerminalSession
+}
+
+// startRecording 为交互式终端会话写入审计记录并开始录像，未开启录像时返回 nil。
+// 合规要求保留生产环境交互访问记录，录像无法开始时拒绝建立会话
+func (c *cluster) startRecording(ctx context.Context, r *http.Request, session *model.TerminalSession) (*terminalRecording, error) {
+	if !c.cc.Recording.IsEnabled() {
+		return nil, nil
+	}
+
+	if user, err := httputils.GetUserFromContext(ctx); err == nil {
+		session.UserId = user.Id
+		session.Operator = user.Name
+	}
+	audit := &model.Audit{
+		Action:            r.Method,
+		Operator:          session.Operator,
+		Path:              r.RequestURI,
+		ObjectType:        model.ObjectCluster,
+		Status:            model.AuditOpSuccess,
+		ResponseCode:      http.StatusSwitchingProtocols,
+		Cluster:           session.Cluster,
+		ResourceName:      session.Pod,
+		ResourceNamespace: session.Namespace,
+	}
+	if session.Kind == model.TerminalSessionNode {
+		audit.ObjectType = model.ObjectNode
+		audit.ResourceName = session.Node
+	}
+	if gc, ok := ctx.(*gin.Context); ok {
+		audit.RequestId = requestid.Get(gc)
+		audit.Ip = gc.ClientIP()
+	}
+	if _, err := c.factory.Audit().Create(ctx, audit); err != nil {
+		klog.Errorf("failed to create audit for %s terminal session: %v", session.Kind, err)
+		return nil, fmt.Errorf("failed to record terminal session: %v", err)
+	}
+	auditsink.Publish(audit)
+
+	session.AuditId = audit.Id
+	session.Ip = audit.Ip
+	session.StartedAt = time.Now()
+	if _, err := c.factory.Audit().TerminalSession().Create(ctx, session); err != nil {
+		klog.Errorf("failed to create %s terminal session: %v", session.Kind, err)
+		return nil, fmt.Errorf("failed to record terminal session: %v", err)
+	}
+
+	session.StorageKey = fmt.Sprintf("%s/%d.cast", session.StartedAt.Format("2006/01/02"), session.Id)
+	w, err := c.recordings.Create(session.StorageKey)
+	if err != nil {
+		klog.Errorf("failed to create recording %s: %v", session.StorageKey, err)
+		return nil, fmt.Errorf("failed to record terminal session: %v", err)
+	}
+	recorder, err := recording.NewRecorder(w, 0, 0, terminalTitle(session))
+	if err != nil {
+		_ = w.Close()
+		klog.Errorf("failed to start recording %s: %v", session.StorageKey, err)
+		return nil, fmt.Errorf("failed to record terminal session: %v", err)
+	}
+	if err = c.factory.Audit().TerminalSession().InternalUpdate(ctx, session.Id, map[string]interface{}{
+		"storage_key": session.StorageKey,
+	}); err != nil {
+		klog.Errorf("failed to update terminal session %d: %v", session.Id, err)
+	}
+
+	klog.Infof("recording %s terminal session %d of user %s to %s", session.Kind, session.Id, session.Operator, session.StorageKey)
+	return &terminalRecording{Recorder: recorder, c: c, session: session}, nil
+}
+
+// Finish 结束录像并回写会话时长与录像大小
+func (t *terminalRecording) Finish() {
+	if t == nil {
+		return
+	}
+	if err := t.Close(); err != nil {
+		klog.Errorf("failed to write recording %s: %v", t.session.StorageKey, err)
+	}
+
+	// 请求上下文可能已随连接关闭而取消
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	now := time.Now()
+	if err := t.c.factory.Audit().TerminalSession().InternalUpdate(ctx, t.session.Id, map[string]interface{}{
+		"ended_at": now,
+		"duration": int64(t.Duration().Seconds()),
+		"size":     t.Size(),
+	}); err != nil {
+		klog.Errorf("failed to update terminal session %d: %v", t.session.Id, err)
+	}
+}
+
+// recorder 返回 types.TerminalRecorder，未开启录像时为 nil 接口，避免 typed nil
+func (t *terminalRecording) recorder() types.TerminalRecorder {
+	if t == nil {
+		return nil
+	}
+	return t.Recorder
+}
+
+func terminalTitle(session *model.TerminalSession) string {
+	switch session.Kind {
+	case model.TerminalSessionNode:
+		return fmt.Sprintf("%s@%s", session.Operator, session.Node)
+	default:
+		return fmt.Sprintf("%s@%s/%s/%s", session.Operator, session.Cluster, session.Namespace, session.Pod)
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package cluster
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"net/http"
@@ -38,21 +37,39 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog/v2"
 
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
 )
 
 func (c *cluster) WsPodHandler(ctx context.Context, opt *types.WebShellOptions, w http.ResponseWriter, r *http.Request) error {
+	return c.wsPodHandler(ctx, opt, model.TerminalSessionPod, w, r)
+}
+
+func (c *cluster) wsPodHandler(ctx context.Context, opt *types.WebShellOptions, kind model.TerminalSessionKind, w http.ResponseWriter, r *http.Request) error {
 	cs, err := c.GetClusterSetByName(ctx, opt.Cluster)
 	if err != nil {
 		klog.Errorf("failed to get cluster(%s) client set: %v", opt.Cluster, err)
 		return err
 	}
 
+	rec, err := c.startRecording(ctx, r, &model.TerminalSession{
+		Kind:      kind,
+		Cluster:   opt.Cluster,
+		Namespace: opt.Namespace,
+		Pod:       opt.Pod,
+		Container: opt.Container,
+	})
+	if err != nil {
+		return err
+	}
+	defer rec.Finish()
+
 	session, err := types.NewTerminalSession(w, r)
 	if err != nil {
 		return err
 	}
+	session.Recorder = rec.recorder()
 	// 处理关闭
 	defer func() {
 		_ = session.Close()
@@ -105,14 +122,21 @@ func (c *cluster) WsPodHandler(ctx context.Context, opt *types.WebShellOptions,
 	return nil
 }
 
-var BufPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
-
 func (c *cluster) WsNodeHandler(ctx context.Context, req types.WebSSHRequest, w http.ResponseWriter, r *http.Request) error {
 	sshConfig, err := c.ResolveSSHConfigForHost(ctx, req.Host)
 	if err != nil {
 		return err
 	}
 
+	rec, err := c.startRecording(ctx, r, &model.TerminalSession{
+		Kind: model.TerminalSessionNode,
+		Node: sshConfig.Host,
+	})
+	if err != nil {
+		return err
+	}
+	defer rec.Finish()
+
 	upgrader := &websocket.Upgrader{
 		ReadBufferSize:   1024,
 		WriteBufferSize:  1024 * 10,
@@ -146,6 +170,7 @@ func (c *cluster) WsNodeHandler(ctx context.Context, req types.WebSSHRequest, w
 		return nil
 	}
 	defer turn.Close()
+	turn.Recorder = rec.recorder()
 
 	// 处理连接
 	handler(turn)
@@ -154,16 +179,12 @@ func (c *cluster) WsNodeHandler(ctx context.Context, req types.WebSSHRequest, w
 }
 
 func handler(turn *types.Turn) {
-	logBuff := BufPool.Get().(*bytes.Buffer)
-	logBuff.Reset()
-	defer BufPool.Put(logBuff)
-
 	wg := &sync.WaitGroup{}
 	wg.Add(2)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go turn.StartLoopRead(ctx, wg, logBuff)
+	go turn.StartLoopRead(ctx, wg)
 	go turn.StartSessionWait(wg)
 
 	wg.Wait()
@@ -215,13 +236,13 @@ func (c *cluster) WsClusterHandler(ctx context.Context, req types.ClusterWebRequ
 		}
 	}
 
-	return c.WsPodHandler(ctx, &types.WebShellOptions{
+	return c.wsPodHandler(ctx, &types.WebShellOptions{
 		Cluster:     ownerClusterName,
 		Namespace:   namespace,
 		Pod:         podName,
 		Container:   "pixiu-ws-toolbox",
 		CommandArgs: cloudShellBashCommand(),
-	}, w, r)
+	}, model.TerminalSessionCloudShell, w, r)
 }
 
 // cloudShellBashCommand 启动带彩色提示符的交互 bash，风格参考云厂商 CloudShell。
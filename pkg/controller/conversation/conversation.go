@@ -35,6 +35,8 @@ type Interface interface {
 	Delete(ctx context.Context, id int64) error
 	Get(ctx context.Context, id int64) (*types.Conversation, error)
 	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
+	// Pin 置顶或取消置顶历史中的一条消息，置顶的消息在压缩历史时保留原文
+	Pin(ctx context.Context, req *types.PinConversationItemRequest) error
 }
 
 type controller struct {
@@ -78,6 +80,43 @@ func (c *controller) Get(ctx context.Context, id int64) (*types.Conversation, er
 	return modelToType(object), nil
 }
 
+func (c *controller) Pin(ctx context.Context, req *types.PinConversationItemRequest) error {
+	object, err := c.factory.Assistant().Conversation().Get(ctx, req.Id)
+	if err != nil {
+		klog.Errorf("failed to get conversation(%d): %v", req.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return apierrors.NewError(fmt.Errorf("conversation not found"), http.StatusNotFound)
+	}
+
+	items, err := object.HistoryItems()
+	if err != nil {
+		klog.Errorf("failed to parse conversation(%d) history: %v", req.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	index := *req.Index
+	if index < 0 || index >= len(items) {
+		return apierrors.NewError(fmt.Errorf("conversation item %d not found", index), http.StatusNotFound)
+	}
+	items[index].Pinned = req.Pinned
+	if err = object.SetHistoryItems(items); err != nil {
+		return apierrors.ErrServerInternal
+	}
+
+	// 以会话当前版本更新，避免覆盖同时进行的对话写入
+	if err = c.factory.Assistant().Conversation().Update(ctx, object.Id, object.ResourceVersion, map[string]interface{}{
+		"history": object.History,
+	}); err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("conversation was modified concurrently, please retry"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update conversation(%d): %v", req.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
 func (c *controller) List(ctx context.Context, listOption types.ListOptions) (interface{}, error) {
 	listOption.SetDefaultPageOption()
 
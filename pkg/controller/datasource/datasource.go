@@ -135,7 +135,7 @@ func (c *controller) Create(ctx context.Context, req *types.CreateDatasourceRequ
 }
 
 // validateRedisConstraint 校验 Redis 数据源的约束：
-// 1. type 与 sub_type 必须配对（redis 可搭配缓存/中间件类型）；2. 仅支持外部直连；3. 必须提供连接地址
+// 1. type 与 sub_type 必须配对（redis 可搭配缓存/中间件类型）；2. 外部直连或经隧道访问集群内单机实例；3. 必须提供连接地址
 func validateRedisConstraint(req *types.CreateDatasourceRequest) error {
 	isRedisType := req.Type == model.DatasourceTypeRedis
 	isRedisSubType := req.SubType == model.DatasourceSubTypeRedis
@@ -156,15 +156,16 @@ func validateRedisConstraint(req *types.CreateDatasourceRequest) error {
 	if !isRedisSubType {
 		return nil
 	}
-	if !req.External {
+	if req.Config == nil || req.Config.Redis == nil {
 		return apierrors.NewError(
-			fmt.Errorf("redis datasource only supports external direct connection, please enable external"),
+			fmt.Errorf("redis datasource requires config.redis"),
 			http.StatusBadRequest,
 		)
 	}
-	if req.Config == nil || req.Config.Redis == nil {
+	// 集群内 Redis 经 Agent 隧道按服务名拨号，哨兵/集群模式会返回 Pod IP，暂不支持
+	if !req.External && req.Config.Redis.NormalizeMode() != types.RedisModeStandalone {
 		return apierrors.NewError(
-			fmt.Errorf("redis datasource requires config.redis"),
+			fmt.Errorf("in-cluster redis datasource only supports standalone mode, please enable external"),
 			http.StatusBadRequest,
 		)
 	}
@@ -14,13 +14,14 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package redis 提供 Redis 中间件管理能力（外部直连，支持单机/哨兵/集群）
+// Package redis 提供 Redis 中间件管理能力（外部直连支持单机/哨兵/集群，隧道集群内仅支持单机）
 package redis
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ import (
 	controllerutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/tunnel"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
 
@@ -159,8 +161,11 @@ func validateRedisConfig(cfg *types.RedisSourceConfig) error {
 	return nil
 }
 
+// dialFunc 自定义拨号，集群内数据源经 Agent 隧道拨号；nil 表示直连
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
 // buildRedisClient 按部署模式构造 redis 客户端（调用方负责 Close）
-func buildRedisClient(cfg *types.RedisSourceConfig) (goredis.UniversalClient, error) {
+func buildRedisClient(cfg *types.RedisSourceConfig, dialer dialFunc) (goredis.UniversalClient, error) {
 	if err := validateRedisConfig(cfg); err != nil {
 		return nil, apierrors.NewError(err, http.StatusBadRequest)
 	}
@@ -172,6 +177,7 @@ func buildRedisClient(cfg *types.RedisSourceConfig) (goredis.UniversalClient, er
 			Password:         cfg.Password,
 			SentinelPassword: cfg.SentinelPassword,
 			DB:               cfg.DB,
+			Dialer:           dialer,
 			DialTimeout:      redisDialTimeout,
 			ReadTimeout:      redisOpTimeout,
 			WriteTimeout:     redisOpTimeout,
@@ -182,6 +188,7 @@ func buildRedisClient(cfg *types.RedisSourceConfig) (goredis.UniversalClient, er
 		return goredis.NewClusterClient(&goredis.ClusterOptions{
 			Addrs:           cfg.Addresses,
 			Password:        cfg.Password,
+			Dialer:          dialer,
 			DialTimeout:     redisDialTimeout,
 			ReadTimeout:     redisOpTimeout,
 			WriteTimeout:    redisOpTimeout,
@@ -193,6 +200,7 @@ func buildRedisClient(cfg *types.RedisSourceConfig) (goredis.UniversalClient, er
 			Addr:            strings.TrimSpace(cfg.Address),
 			Password:        cfg.Password,
 			DB:              cfg.DB,
+			Dialer:          dialer,
 			DialTimeout:     redisDialTimeout,
 			ReadTimeout:     redisOpTimeout,
 			WriteTimeout:    redisOpTimeout,
@@ -223,9 +231,6 @@ func (c *controller) clientFor(ctx context.Context, datasourceId int64, db *int)
 		object.SubType != model.DatasourceSubTypeRedis {
 		return nil, nil, apierrors.NewError(fmt.Errorf("datasource(%d) is not a redis datasource", datasourceId), http.StatusBadRequest)
 	}
-	if !object.External {
-		return nil, nil, apierrors.NewError(fmt.Errorf("redis datasource only supports external direct connection"), http.StatusBadRequest)
-	}
 	// 归属校验：root/owner 放行，其余须命中角色 scope，与 datasource controller 的 Get 完全一致
 	if err := controllerutil.CheckResourceAccess(ctx, c.factory, object.UserId, types.ResourceTypeDatasource, datasourceId); err != nil {
 		klog.Warningf("datasource(%d) access denied: %v", datasourceId, err)
@@ -246,6 +251,13 @@ func (c *controller) clientFor(ctx context.Context, datasourceId int64, db *int)
 	}
 	cfg.Redis.DB = effectiveDB
 
+	var dialer dialFunc
+	if !object.External {
+		if dialer, err = c.tunnelDialer(ctx, object.ClusterName, cfg.Redis); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -264,7 +276,7 @@ func (c *controller) clientFor(ctx context.Context, datasourceId int64, db *int)
 		delete(c.clients, cacheKey)
 	}
 
-	client, err := buildRedisClient(cfg.Redis)
+	client, err := buildRedisClient(cfg.Redis, dialer)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -291,6 +303,35 @@ func (c *controller) clientFor(ctx context.Context, datasourceId int64, db *int)
 	return client, cfg.Redis, nil
 }
 
+// tunnelDialer 集群内 Redis 经 Agent 反向隧道拨号：仅支持隧道集群的单机模式，
+// 且地址必须在集群隧道允许名单内（哨兵/集群模式会返回 Pod IP，无法按服务名放行）
+func (c *controller) tunnelDialer(ctx context.Context, clusterName string, cfg *types.RedisSourceConfig) (dialFunc, error) {
+	if cfg.NormalizeMode() != types.RedisModeStandalone {
+		return nil, apierrors.NewError(fmt.Errorf("in-cluster redis datasource only supports standalone mode"), http.StatusBadRequest)
+	}
+	cluster, err := c.factory.Cluster().GetBy(ctx, db.WithName(clusterName))
+	if err != nil {
+		klog.Errorf("failed to get cluster(%s): %v", clusterName, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if cluster == nil {
+		return nil, apierrors.ErrClusterNotFound
+	}
+	tm := tunnel.Default()
+	if cluster.ConnectMode != model.ConnectModeTunnel || tm == nil {
+		return nil, apierrors.NewError(fmt.Errorf("in-cluster redis datasource requires a tunnel mode cluster"), http.StatusBadRequest)
+	}
+	allowed, err := tm.TargetAllowed(ctx, cluster.Name, strings.TrimSpace(cfg.Address))
+	if err != nil {
+		klog.Errorf("failed to check tunnel targets of cluster(%s): %v", cluster.Name, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if !allowed {
+		return nil, apierrors.NewError(fmt.Errorf("redis address %s is not in cluster %s tunnel targets", cfg.Address, cluster.Name), http.StatusForbidden)
+	}
+	return tm.ServiceDialContext(cluster.Name), nil
+}
+
 // wrapRedisErr 将 redis 操作错误转换为带语义错误码的网关类错误：
 // key 不存在→404；认证失败/连接层故障/其余命令失败→502
 // 注意：上游认证失败不能返回 401，前端全局拦截器将 401 视为 Pixiu 登录失效并登出跳转
@@ -349,7 +390,7 @@ func (c *controller) PingAdhoc(ctx context.Context, cfg *types.RedisSourceConfig
 	}
 
 	result := &types.RedisPing{Address: cfg.DisplayAddress(), DB: cfg.DB}
-	client, err := buildRedisClient(cfg)
+	client, err := buildRedisClient(cfg, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,739 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/controller/cluster"
+	controllerutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+const (
+	// 单个集群安装、升级或回滚的超时时间
+	applicationTargetTimeout = 10 * time.Minute
+	// 发布过程中每个目标开始执行时刷新应用的 gmt_modified，超过该时长未刷新说明执行发布的实例已退出
+	applicationStaleTimeout = applicationTargetTimeout + 5*time.Minute
+)
+
+var errApplicationProgressing = apierrors.NewError(fmt.Errorf("helm application is being rolled out"), http.StatusConflict)
+
+type ApplicationInterface interface {
+	Create(ctx context.Context, req *types.CreateHelmApplication) (*types.HelmApplication, error)
+	Update(ctx context.Context, id int64, req *types.UpdateHelmApplication) error
+	// Delete 仅删除应用记录，已发布到各集群的 release 不会被卸载
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*types.HelmApplication, error)
+	List(ctx context.Context) ([]types.HelmApplication, error)
+
+	// Deploy 按批次将当前配置安装或升级到所有目标集群
+	Deploy(ctx context.Context, id int64) error
+	// Rollback 按批次将各集群的 release 回滚到最近一次 Deploy 前的版本
+	Rollback(ctx context.Context, id int64) error
+}
+
+type Application struct {
+	helm    *Helm
+	factory db.ShareDaoFactory
+}
+
+func NewApplication(h *Helm) *Application {
+	return &Application{helm: h, factory: h.factory}
+}
+
+var _ ApplicationInterface = &Application{}
+
+func (a *Application) Create(ctx context.Context, req *types.CreateHelmApplication) (*types.HelmApplication, error) {
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReleaseName == "" {
+		req.ReleaseName = req.Name
+	}
+	if errs := validation.IsDNS1123Label(req.Namespace); len(errs) != 0 {
+		return nil, apierrors.NewError(fmt.Errorf("invalid namespace %q: %s", req.Namespace, strings.Join(errs, ", ")), http.StatusBadRequest)
+	}
+	// helm 要求 release 名称符合 DNS-1123 且不超过 53 个字符
+	if errs := validation.IsDNS1123Label(req.ReleaseName); len(errs) != 0 || len(req.ReleaseName) > 53 {
+		return nil, apierrors.NewError(fmt.Errorf("invalid release name %q", req.ReleaseName), http.StatusBadRequest)
+	}
+
+	object := &model.HelmApplication{
+		Name:             req.Name,
+		Description:      req.Description,
+		UserId:           user.Id,
+		Namespace:        req.Namespace,
+		ReleaseName:      req.ReleaseName,
+		Status:           model.HelmApplicationPending,
+		FailureThreshold: req.FailureThreshold,
+	}
+	if err = a.setSpec(ctx, object, req.RepositoryId, req.Chart, req.Version, req.Values, req.Overrides, req.Clusters, req.ClusterSelector, req.BatchSize); err != nil {
+		return nil, err
+	}
+
+	old, err := a.factory.HelmApplication().GetByName(ctx, req.Name)
+	if err != nil {
+		klog.Errorf("failed to get helm application %s: %v", req.Name, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if old != nil {
+		return nil, apierrors.NewError(fmt.Errorf("helm application %s already exists", req.Name), http.StatusConflict)
+	}
+	if object, err = a.factory.HelmApplication().Create(ctx, object); err != nil {
+		klog.Errorf("failed to create helm application %s: %v", req.Name, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	return a.model2Type(object, nil), nil
+}
+
+func (a *Application) Update(ctx context.Context, id int64, req *types.UpdateHelmApplication) error {
+	object, err := a.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	object.FailureThreshold = req.FailureThreshold
+	object.Description = req.Description
+	if err = a.setSpec(ctx, object, req.RepositoryId, req.Chart, req.Version, req.Values, req.Overrides, req.Clusters, req.ClusterSelector, req.BatchSize); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"description":       object.Description,
+		"repository_id":     object.RepositoryId,
+		"chart":             object.Chart,
+		"version":           object.Version,
+		"values":            object.Values,
+		"overrides":         object.Overrides,
+		"clusters":          object.Clusters,
+		"cluster_selector":  object.ClusterSelector,
+		"batch_size":        object.BatchSize,
+		"failure_threshold": object.FailureThreshold,
+	}
+	if err = a.factory.HelmApplication().Update(ctx, id, *req.ResourceVersion, updates); err != nil {
+		if errors.Is(err, utilerrors.ErrRecordNotUpdate) {
+			return apierrors.NewError(fmt.Errorf("helm application has been modified, please refresh and retry"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update helm application(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+// setSpec 校验并写入应用的 chart、values 与目标集群配置
+func (a *Application) setSpec(ctx context.Context, object *model.HelmApplication, repositoryId int64, chart, version string,
+	values map[string]interface{}, overrides map[string]map[string]interface{}, clusters []string, selector string, batchSize int) error {
+	if repositoryId != 0 {
+		if _, err := a.factory.Repository().Get(ctx, repositoryId); err != nil {
+			if utilerrors.IsRecordNotFound(err) {
+				return apierrors.NewError(fmt.Errorf("repository not found"), http.StatusBadRequest)
+			}
+			return apierrors.ErrServerInternal
+		}
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return apierrors.NewError(fmt.Errorf("invalid cluster selector: %v", err), http.StatusBadRequest)
+	}
+	if len(clusters) == 0 && strings.TrimSpace(selector) == "" {
+		return apierrors.NewError(fmt.Errorf("clusters or cluster_selector is required"), http.StatusBadRequest)
+	}
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	valuesData, err := marshalJSON(values)
+	if err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
+	overridesData, err := marshalJSON(overrides)
+	if err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
+	clustersData, err := marshalJSON(normalizeNames(clusters))
+	if err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
+
+	object.RepositoryId = repositoryId
+	object.Chart = chart
+	object.Version = version
+	object.Values = valuesData
+	object.Overrides = overridesData
+	object.Clusters = clustersData
+	object.ClusterSelector = strings.TrimSpace(selector)
+	object.BatchSize = batchSize
+	return nil
+}
+
+func (a *Application) Delete(ctx context.Context, id int64) error {
+	object, err := a.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if object.Status == model.HelmApplicationProgressing {
+		return errApplicationProgressing
+	}
+	if err = a.factory.HelmApplication().Delete(ctx, id); err != nil {
+		klog.Errorf("failed to delete helm application(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (a *Application) Get(ctx context.Context, id int64) (*types.HelmApplication, error) {
+	object, err := a.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := a.factory.HelmApplication().ListTargets(ctx, id)
+	if err != nil {
+		klog.Errorf("failed to list targets of helm application(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	return a.model2Type(object, targets), nil
+}
+
+func (a *Application) List(ctx context.Context) ([]types.HelmApplication, error) {
+	userId, err := controllerutil.EffectiveUserID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	var opts []db.Options
+	if userId != 0 {
+		opts = append(opts, db.WithUser(userId))
+	}
+	objects, err := a.factory.HelmApplication().List(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to list helm applications: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+	result := make([]types.HelmApplication, 0, len(objects))
+	for i := range objects {
+		result = append(result, *a.model2Type(&objects[i], nil))
+	}
+	return result, nil
+}
+
+// get 获取应用并校验当前用户为应用的 owner
+func (a *Application) get(ctx context.Context, id int64) (*model.HelmApplication, error) {
+	object, err := a.factory.HelmApplication().Get(ctx, id)
+	if err != nil {
+		klog.Errorf("failed to get helm application(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, apierrors.NewError(fmt.Errorf("helm application not found"), http.StatusNotFound)
+	}
+	if err = controllerutil.CheckResourceOwner(ctx, object.UserId); err != nil {
+		return nil, err
+	}
+	if err = a.recoverStale(ctx, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// recoverStale 执行发布的实例重启或退出后，应用会停留在发布中；超时未刷新时置为失败，允许重新发布或删除
+func (a *Application) recoverStale(ctx context.Context, object *model.HelmApplication) error {
+	if object.Status != model.HelmApplicationProgressing || time.Since(object.GmtModified) < applicationStaleTimeout {
+		return nil
+	}
+	interrupted, err := a.factory.HelmApplication().InterruptRollout(ctx, object.Id, time.Now().Add(-applicationStaleTimeout), rolloutInterruptedMessage)
+	if err != nil {
+		klog.Errorf("failed to interrupt stale rollout of helm application(%d): %v", object.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	if !interrupted {
+		// 发布已刷新或已被其他请求处理，以数据库中的状态为准
+		return nil
+	}
+	klog.Warningf("helm application(%d) rollout has not reported for %v, marked as failed", object.Id, applicationStaleTimeout)
+	object.Status = model.HelmApplicationFailed
+	object.Message = rolloutInterruptedMessage
+	object.ResourceVersion++
+	return nil
+}
+
+func (a *Application) Deploy(ctx context.Context, id int64) error {
+	object, err := a.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	clusters, err := a.resolveClusters(ctx, object)
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		return apierrors.NewError(fmt.Errorf("no cluster matches the application targets"), http.StatusBadRequest)
+	}
+
+	targets := make([]model.HelmApplicationTarget, 0, len(clusters))
+	for i, name := range clusters {
+		targets = append(targets, model.HelmApplicationTarget{
+			Cluster:      name,
+			Status:       model.HelmTargetPending,
+			Action:       model.HelmApplicationDeploy,
+			Batch:        i / object.BatchSize,
+			ChartVersion: object.Version,
+		})
+	}
+	return a.start(ctx, object, model.HelmApplicationDeploy, targets)
+}
+
+func (a *Application) Rollback(ctx context.Context, id int64) error {
+	object, err := a.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	old, err := a.factory.HelmApplication().ListTargets(ctx, id)
+	if err != nil {
+		klog.Errorf("failed to list targets of helm application(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+
+	targets := rollbackTargets(old, object.BatchSize)
+	if len(targets) == 0 {
+		return apierrors.NewError(fmt.Errorf("helm application has not been deployed to any cluster"), http.StatusBadRequest)
+	}
+	return a.start(ctx, object, model.HelmApplicationRollback, targets)
+}
+
+// rollbackTargets 回滚到最近一次 deploy 前记录的版本，而不是 release 的上一个版本，重复回滚不会来回切换；
+// 由该次 deploy 首次安装的集群没有可回滚的版本，跳过；批次按集群重新编排
+func rollbackTargets(old []model.HelmApplicationTarget, batchSize int) []model.HelmApplicationTarget {
+	var targets []model.HelmApplicationTarget
+	for _, target := range old {
+		if target.PreviousRevision == 0 {
+			continue
+		}
+		targets = append(targets, model.HelmApplicationTarget{
+			Cluster:          target.Cluster,
+			Status:           model.HelmTargetPending,
+			Action:           model.HelmApplicationRollback,
+			Batch:            len(targets) / batchSize,
+			ChartVersion:     target.ChartVersion,
+			Revision:         target.Revision,
+			PreviousRevision: target.PreviousRevision,
+		})
+	}
+	return targets
+}
+
+// resolveClusters 计算目标集群：按名称指定的集群必须存在且有权限访问，标签选中的集群跳过无权限的集群
+func (a *Application) resolveClusters(ctx context.Context, object *model.HelmApplication) ([]string, error) {
+	var names []string
+	if object.Clusters != "" {
+		if err := json.Unmarshal([]byte(object.Clusters), &names); err != nil {
+			return nil, apierrors.NewError(fmt.Errorf("invalid application clusters: %v", err), http.StatusBadRequest)
+		}
+	}
+	selector := labels.Nothing()
+	if object.ClusterSelector != "" {
+		s, err := labels.Parse(object.ClusterSelector)
+		if err != nil {
+			return nil, apierrors.NewError(fmt.Errorf("invalid cluster selector: %v", err), http.StatusBadRequest)
+		}
+		selector = s
+	}
+
+	objects, err := a.factory.Cluster().List(ctx)
+	if err != nil {
+		klog.Errorf("failed to list clusters: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+	byName := make(map[string]model.Cluster, len(objects))
+	for _, o := range objects {
+		byName[o.Name] = o
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range names {
+		o, ok := byName[name]
+		if !ok {
+			return nil, apierrors.NewError(fmt.Errorf("cluster %s not found", name), http.StatusBadRequest)
+		}
+		if err = controllerutil.CheckResourceAccess(ctx, a.factory, o.UserId, types.ResourceTypeCluster, o.Id); err != nil {
+			return nil, apierrors.NewError(fmt.Errorf("no permission to access cluster %s", name), http.StatusForbidden)
+		}
+		selected[name] = true
+	}
+	for _, o := range objects {
+		if selected[o.Name] || !selector.Matches(labels.Set(cluster.UnmarshalClusterLabels(o.Labels))) {
+			continue
+		}
+		if controllerutil.CheckResourceAccess(ctx, a.factory, o.UserId, types.ResourceTypeCluster, o.Id) != nil {
+			continue
+		}
+		selected[o.Name] = true
+	}
+
+	result := make([]string, 0, len(selected))
+	for name := range selected {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// start 将应用切换为发布中，记录目标集群并在后台执行发布
+func (a *Application) start(ctx context.Context, object *model.HelmApplication, action model.HelmApplicationAction, targets []model.HelmApplicationTarget) error {
+	if err := a.markProgressing(ctx, object, action); err != nil {
+		return err
+	}
+	if err := a.factory.HelmApplication().ReplaceTargets(ctx, object.Id, targets); err != nil {
+		klog.Errorf("failed to save targets of helm application(%d): %v", object.Id, err)
+		a.finish(ctx, object.Id, model.HelmApplicationFailed, "failed to save rollout targets")
+		return apierrors.ErrServerInternal
+	}
+
+	go a.rollout(context.Background(), object)
+	return nil
+}
+
+// markProgressing 以 resource_version 乐观锁将应用切换为发布中，保证同一应用同时只有一次发布，
+// 多个 pixiu 实例之间同样生效
+func (a *Application) markProgressing(ctx context.Context, object *model.HelmApplication, action model.HelmApplicationAction) error {
+	if object.Status == model.HelmApplicationProgressing {
+		return errApplicationProgressing
+	}
+	if err := a.factory.HelmApplication().Update(ctx, object.Id, object.ResourceVersion, map[string]interface{}{
+		"status":      model.HelmApplicationProgressing,
+		"last_action": action,
+		"message":     "",
+	}); err != nil {
+		if errors.Is(err, utilerrors.ErrRecordNotUpdate) {
+			return apierrors.NewError(fmt.Errorf("helm application has been modified or is being rolled out, please refresh and retry"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update helm application(%d): %v", object.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+// rollout 逐批执行发布并回写应用的最终状态
+func (a *Application) rollout(ctx context.Context, object *model.HelmApplication) {
+	// ReplaceTargets 会回填主键，重新读取以获取目标记录 ID
+	saved, err := a.factory.HelmApplication().ListTargets(ctx, object.Id)
+	if err != nil {
+		klog.Errorf("failed to list targets of helm application(%d): %v", object.Id, err)
+		a.finish(ctx, object.Id, model.HelmApplicationFailed, err.Error())
+		return
+	}
+
+	failed, aborted := runBatches(groupBatches(saved), object.FailureThreshold,
+		func(target model.HelmApplicationTarget) bool {
+			a.touch(ctx, object.Id)
+			return a.runTarget(ctx, object, target)
+		},
+		func(target model.HelmApplicationTarget) {
+			a.updateTarget(ctx, target.Id, map[string]interface{}{
+				"status":  model.HelmTargetSkipped,
+				"message": rolloutAbortedMessage,
+			})
+		})
+	status, message := rolloutResult(failed, len(saved), aborted)
+	a.finish(ctx, object.Id, status, message)
+}
+
+const (
+	rolloutAbortedMessage     = "rollout aborted: failure threshold exceeded"
+	rolloutInterruptedMessage = "rollout interrupted: pixiu instance exited before the rollout finished"
+)
+
+// groupBatches 按目标记录的批次号分组，批次号从 0 开始
+func groupBatches(targets []model.HelmApplicationTarget) [][]model.HelmApplicationTarget {
+	var batches [][]model.HelmApplicationTarget
+	for _, target := range targets {
+		for len(batches) <= target.Batch {
+			batches = append(batches, nil)
+		}
+		batches[target.Batch] = append(batches[target.Batch], target)
+	}
+	return batches
+}
+
+// runBatches 逐批执行，每批内的集群并发执行；累计失败数超过阈值后，后续批次交由 skip 标记为跳过
+func runBatches(batches [][]model.HelmApplicationTarget, threshold int,
+	run func(model.HelmApplicationTarget) bool, skip func(model.HelmApplicationTarget)) (failed int, aborted bool) {
+	for _, batch := range batches {
+		if aborted {
+			for _, target := range batch {
+				skip(target)
+			}
+			continue
+		}
+
+		var (
+			wg   sync.WaitGroup
+			lock sync.Mutex
+		)
+		for _, target := range batch {
+			wg.Add(1)
+			go func(target model.HelmApplicationTarget) {
+				defer wg.Done()
+				if !run(target) {
+					lock.Lock()
+					failed++
+					lock.Unlock()
+				}
+			}(target)
+		}
+		wg.Wait()
+		aborted = failed > threshold
+	}
+	return failed, aborted
+}
+
+// rolloutResult 根据失败数计算应用的最终状态
+func rolloutResult(failed, total int, aborted bool) (model.HelmApplicationStatus, string) {
+	message := fmt.Sprintf("%d/%d clusters failed", failed, total)
+	switch {
+	case aborted:
+		return model.HelmApplicationFailed, message + ", rollout aborted"
+	case failed > 0:
+		return model.HelmApplicationPartial, message
+	default:
+		return model.HelmApplicationSucceeded, ""
+	}
+}
+
+// runTarget 在单个集群上执行安装、升级或回滚，返回是否成功
+func (a *Application) runTarget(ctx context.Context, object *model.HelmApplication, target model.HelmApplicationTarget) bool {
+	now := time.Now()
+	a.updateTarget(ctx, target.Id, map[string]interface{}{"status": model.HelmTargetRunning, "started_at": &now})
+
+	applyCtx, cancel := context.WithTimeout(ctx, applicationTargetTimeout)
+	defer cancel()
+
+	rel, previous, err := a.apply(applyCtx, object, target)
+	finished := time.Now()
+	updates := map[string]interface{}{"finished_at": &finished}
+	if target.Action == model.HelmApplicationDeploy {
+		updates["previous_revision"] = previous
+	}
+	if err != nil {
+		klog.Errorf("failed to %s helm application %s on cluster %s: %v", target.Action, object.Name, target.Cluster, err)
+		updates["status"] = model.HelmTargetFailed
+		updates["message"] = err.Error()
+	} else {
+		updates["status"] = model.HelmTargetSucceeded
+		updates["revision"] = rel.Version
+		updates["message"] = ""
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			updates["chart_version"] = rel.Chart.Metadata.Version
+		}
+	}
+	a.updateTarget(ctx, target.Id, updates)
+	return err == nil
+}
+
+// apply 在单个集群上执行发布，返回执行后的 release 以及 deploy 前 release 的版本号（不存在时为 0）
+func (a *Application) apply(ctx context.Context, object *model.HelmApplication, target model.HelmApplicationTarget) (*release.Release, int, error) {
+	// 集群不可用时 Release 无法构造有效的客户端
+	if cs := a.helm.MustGetClusterSetByName(ctx, target.Cluster); cs.Config == nil {
+		return nil, 0, fmt.Errorf("cluster %s is unavailable", target.Cluster)
+	}
+	releases := a.helm.Release(target.Cluster, object.Namespace)
+
+	if target.Action == model.HelmApplicationRollback {
+		if err := releases.Rollback(ctx, object.ReleaseName, target.PreviousRevision); err != nil {
+			return nil, 0, err
+		}
+		rel, err := releases.Get(ctx, object.ReleaseName)
+		if err != nil {
+			return nil, 0, err
+		}
+		return rel, 0, nil
+	}
+
+	values, err := clusterValues(object, target.Cluster)
+	if err != nil {
+		return nil, 0, err
+	}
+	form := &types.Release{
+		Name:         object.ReleaseName,
+		RepositoryId: object.RepositoryId,
+		Chart:        object.Chart,
+		Version:      object.Version,
+		Values:       values,
+	}
+	current, err := releases.Get(ctx, object.ReleaseName)
+	switch {
+	case errors.Is(err, driver.ErrReleaseNotFound):
+		rel, err := releases.Install(ctx, form)
+		if err != nil {
+			return nil, 0, err
+		}
+		return rel, 0, nil
+	case err != nil:
+		return nil, 0, err
+	}
+	rel, err := releases.Upgrade(ctx, form)
+	if err != nil {
+		return nil, current.Version, err
+	}
+	return rel, current.Version, nil
+}
+
+func (a *Application) updateTarget(ctx context.Context, id int64, updates map[string]interface{}) {
+	if err := a.factory.HelmApplication().UpdateTarget(ctx, id, updates); err != nil {
+		klog.Errorf("failed to update helm application target(%d): %v", id, err)
+	}
+}
+
+// touch 刷新应用的 gmt_modified，表示发布仍在进行
+func (a *Application) touch(ctx context.Context, id int64) {
+	if err := a.factory.HelmApplication().InternalUpdate(ctx, id, map[string]interface{}{}); err != nil {
+		klog.Errorf("failed to refresh helm application(%d): %v", id, err)
+	}
+}
+
+func (a *Application) finish(ctx context.Context, id int64, status model.HelmApplicationStatus, message string) {
+	if err := a.factory.HelmApplication().InternalUpdate(ctx, id, map[string]interface{}{
+		"status":  status,
+		"message": message,
+	}); err != nil {
+		klog.Errorf("failed to update helm application(%d): %v", id, err)
+	}
+}
+
+// clusterValues 以基础 values 为底，合并目标集群的覆盖配置
+func clusterValues(object *model.HelmApplication, clusterName string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if object.Values != "" {
+		if err := json.Unmarshal([]byte(object.Values), &values); err != nil {
+			return nil, fmt.Errorf("invalid application values: %v", err)
+		}
+	}
+	if object.Overrides == "" {
+		return values, nil
+	}
+	var overrides map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(object.Overrides), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid application overrides: %v", err)
+	}
+	return mergeValues(values, overrides[clusterName]), nil
+}
+
+// mergeValues 深度合并 values，src 优先；非 map 类型的值（包括列表）整体覆盖
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if sv, ok := v.(map[string]interface{}); ok {
+			if dv, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = mergeValues(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+func (a *Application) model2Type(o *model.HelmApplication, targets []model.HelmApplicationTarget) *types.HelmApplication {
+	app := &types.HelmApplication{
+		PixiuMeta: types.PixiuMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		Name:             o.Name,
+		Description:      o.Description,
+		UserId:           o.UserId,
+		RepositoryId:     o.RepositoryId,
+		Chart:            o.Chart,
+		Version:          o.Version,
+		Namespace:        o.Namespace,
+		ReleaseName:      o.ReleaseName,
+		ClusterSelector:  o.ClusterSelector,
+		BatchSize:        o.BatchSize,
+		FailureThreshold: o.FailureThreshold,
+		Status:           string(o.Status),
+		LastAction:       string(o.LastAction),
+		Message:          o.Message,
+	}
+	// 非核心数据，解析失败时忽略
+	if o.Values != "" {
+		_ = json.Unmarshal([]byte(o.Values), &app.Values)
+	}
+	if o.Overrides != "" {
+		_ = json.Unmarshal([]byte(o.Overrides), &app.Overrides)
+	}
+	if o.Clusters != "" {
+		_ = json.Unmarshal([]byte(o.Clusters), &app.Clusters)
+	}
+	for _, t := range targets {
+		app.Targets = append(app.Targets, types.HelmApplicationTarget{
+			Cluster:          t.Cluster,
+			Status:           string(t.Status),
+			Action:           string(t.Action),
+			Batch:            t.Batch,
+			ChartVersion:     t.ChartVersion,
+			Revision:         t.Revision,
+			PreviousRevision: t.PreviousRevision,
+			Message:          t.Message,
+			StartedAt:        t.StartedAt,
+			FinishedAt:       t.FinishedAt,
+		})
+	}
+	return app
+}
+
+func marshalJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if s := string(data); s != "null" {
+		return s, nil
+	}
+	return "", nil
+}
+
+func normalizeNames(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+func TestClusterValues(t *testing.T) {
+	object := &model.HelmApplication{
+		Values:    `{"image":{"repository":"nginx","tag":"1.25"},"replicas":2,"hosts":["a.example.com"]}`,
+		Overrides: `{"prod":{"image":{"tag":"1.26"},"hosts":["b.example.com"]}}`,
+	}
+
+	values, err := clusterValues(object, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.26"},
+		"replicas": float64(2),
+		"hosts":    []interface{}{"b.example.com"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values for prod: %v", values)
+	}
+
+	values, err = clusterValues(object, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["image"].(map[string]interface{})["tag"] != "1.25" {
+		t.Errorf("cluster without overrides should use base values: %v", values)
+	}
+}
+
+func batchTargets(batches ...[]string) []model.HelmApplicationTarget {
+	var targets []model.HelmApplicationTarget
+	for i, clusters := range batches {
+		for _, name := range clusters {
+			targets = append(targets, model.HelmApplicationTarget{Cluster: name, Batch: i})
+		}
+	}
+	return targets
+}
+
+func clusterNames(targets []model.HelmApplicationTarget) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Cluster)
+	}
+	return names
+}
+
+func TestGroupBatches(t *testing.T) {
+	targets := batchTargets([]string{"a", "b"}, []string{"c"}, nil, []string{"d"})
+
+	batches := groupBatches(targets)
+	if len(batches) != 4 {
+		t.Fatalf("expected 4 batches, got %d", len(batches))
+	}
+	expected := [][]string{{"a", "b"}, {"c"}, {}, {"d"}}
+	for i, batch := range batches {
+		if names := clusterNames(batch); !reflect.DeepEqual(names, expected[i]) {
+			t.Errorf("batch %d: expected %v, got %v", i, expected[i], names)
+		}
+	}
+	if batches := groupBatches(nil); len(batches) != 0 {
+		t.Errorf("expected no batches for no targets, got %d", len(batches))
+	}
+}
+
+func TestRunBatches(t *testing.T) {
+	testCases := []struct {
+		name      string
+		batches   [][]string
+		failing   []string
+		threshold int
+		ran       []string
+		skipped   []string
+		failed    int
+		aborted   bool
+	}{
+		{
+			name:    "all succeeded",
+			batches: [][]string{{"a", "b"}, {"c"}},
+			ran:     []string{"a", "b", "c"},
+		},
+		{
+			name:      "failures within threshold",
+			batches:   [][]string{{"a", "b"}, {"c"}},
+			failing:   []string{"a"},
+			threshold: 1,
+			ran:       []string{"a", "b", "c"},
+			failed:    1,
+		},
+		{
+			name:    "abort after failed batch",
+			batches: [][]string{{"a", "b"}, {"c"}, {"d"}},
+			failing: []string{"b"},
+			ran:     []string{"a", "b"},
+			skipped: []string{"c", "d"},
+			failed:  1,
+			aborted: true,
+		},
+		{
+			name:      "failures accumulate across batches",
+			batches:   [][]string{{"a"}, {"b"}, {"c"}},
+			failing:   []string{"a", "b"},
+			threshold: 1,
+			ran:       []string{"a", "b"},
+			skipped:   []string{"c"},
+			failed:    2,
+			aborted:   true,
+		},
+		{
+			name:    "failure in last batch",
+			batches: [][]string{{"a"}, {"b"}},
+			failing: []string{"b"},
+			ran:     []string{"a", "b"},
+			failed:  1,
+			aborted: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			failing := make(map[string]bool)
+			for _, name := range tc.failing {
+				failing[name] = true
+			}
+			var (
+				lock         sync.Mutex
+				ran, skipped []string
+			)
+			failed, aborted := runBatches(groupBatches(batchTargets(tc.batches...)), tc.threshold,
+				func(target model.HelmApplicationTarget) bool {
+					lock.Lock()
+					defer lock.Unlock()
+					ran = append(ran, target.Cluster)
+					return !failing[target.Cluster]
+				},
+				func(target model.HelmApplicationTarget) {
+					skipped = append(skipped, target.Cluster)
+				})
+
+			sort.Strings(ran)
+			if !reflect.DeepEqual(ran, tc.ran) {
+				t.Errorf("expected ran %v, got %v", tc.ran, ran)
+			}
+			if !reflect.DeepEqual(skipped, tc.skipped) {
+				t.Errorf("expected skipped %v, got %v", tc.skipped, skipped)
+			}
+			if failed != tc.failed || aborted != tc.aborted {
+				t.Errorf("expected failed=%d aborted=%v, got failed=%d aborted=%v", tc.failed, tc.aborted, failed, aborted)
+			}
+		})
+	}
+}
+
+func TestRolloutResult(t *testing.T) {
+	testCases := []struct {
+		failed, total int
+		aborted       bool
+		status        model.HelmApplicationStatus
+		message       string
+	}{
+		{0, 3, false, model.HelmApplicationSucceeded, ""},
+		{1, 3, false, model.HelmApplicationPartial, "1/3 clusters failed"},
+		{2, 3, true, model.HelmApplicationFailed, "2/3 clusters failed, rollout aborted"},
+	}
+	for _, tc := range testCases {
+		status, message := rolloutResult(tc.failed, tc.total, tc.aborted)
+		if status != tc.status || message != tc.message {
+			t.Errorf("rolloutResult(%d, %d, %v) = %s %q, expected %s %q", tc.failed, tc.total, tc.aborted, status, message, tc.status, tc.message)
+		}
+	}
+}
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	applications *fakeApplications
+}
+
+func (f *fakeFactory) HelmApplication() db.HelmApplicationInterface { return f.applications }
+
+// fakeApplications 按 resource_version 乐观锁更新单个应用
+type fakeApplications struct {
+	db.HelmApplicationInterface
+	object model.HelmApplication
+}
+
+func (f *fakeApplications) Update(_ context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	if f.object.Id != id {
+		return utilerrors.ErrRecordNotFound
+	}
+	if f.object.ResourceVersion != resourceVersion {
+		return utilerrors.ErrRecordNotUpdate
+	}
+	f.object.ResourceVersion++
+	if status, ok := updates["status"]; ok {
+		f.object.Status = status.(model.HelmApplicationStatus)
+	}
+	if action, ok := updates["last_action"]; ok {
+		f.object.LastAction = action.(model.HelmApplicationAction)
+	}
+	return nil
+}
+
+func TestMarkProgressing(t *testing.T) {
+	applications := &fakeApplications{object: model.HelmApplication{Status: model.HelmApplicationSucceeded}}
+	applications.object.Id = 1
+	a := &Application{factory: &fakeFactory{applications: applications}}
+
+	// 两次发布读取到同一版本，只有先提交的一次生效
+	first, second := applications.object, applications.object
+	if err := a.markProgressing(context.TODO(), &first, model.HelmApplicationDeploy); err != nil {
+		t.Fatalf("expected first rollout to start, got %v", err)
+	}
+	if applications.object.Status != model.HelmApplicationProgressing || applications.object.LastAction != model.HelmApplicationDeploy {
+		t.Errorf("expected application to be progressing, got %s/%s", applications.object.Status, applications.object.LastAction)
+	}
+	if err := a.markProgressing(context.TODO(), &second, model.HelmApplicationRollback); !isConflict(err) {
+		t.Errorf("expected concurrent rollout to conflict, got %v", err)
+	}
+
+	// 重新读取后应用处于发布中
+	latest := applications.object
+	if err := a.markProgressing(context.TODO(), &latest, model.HelmApplicationDeploy); !isConflict(err) {
+		t.Errorf("expected progressing application to conflict, got %v", err)
+	}
+	if applications.object.ResourceVersion != 1 {
+		t.Errorf("expected a single status transition, got resource version %d", applications.object.ResourceVersion)
+	}
+}
+
+func (f *fakeApplications) InterruptRollout(_ context.Context, id int64, before time.Time, message string) (bool, error) {
+	if f.object.Id != id || f.object.Status != model.HelmApplicationProgressing || !f.object.GmtModified.Before(before) {
+		return false, nil
+	}
+	f.object.Status = model.HelmApplicationFailed
+	f.object.Message = message
+	f.object.ResourceVersion++
+	return true, nil
+}
+
+func TestRecoverStale(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   model.HelmApplicationStatus
+		modified time.Duration
+		expected model.HelmApplicationStatus
+	}{
+		{name: "active rollout", status: model.HelmApplicationProgressing, modified: time.Minute, expected: model.HelmApplicationProgressing},
+		{name: "stale rollout", status: model.HelmApplicationProgressing, modified: applicationStaleTimeout + time.Minute, expected: model.HelmApplicationFailed},
+		{name: "finished rollout", status: model.HelmApplicationSucceeded, modified: applicationStaleTimeout + time.Minute, expected: model.HelmApplicationSucceeded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			applications := &fakeApplications{}
+			applications.object.Id = 1
+			applications.object.Status = tc.status
+			applications.object.GmtModified = time.Now().Add(-tc.modified)
+			a := &Application{factory: &fakeFactory{applications: applications}}
+
+			object := applications.object
+			if err := a.recoverStale(context.TODO(), &object); err != nil {
+				t.Fatalf("recover stale: %v", err)
+			}
+			if object.Status != tc.expected || applications.object.Status != tc.expected {
+				t.Errorf("expected status %s, got %s (stored %s)", tc.expected, object.Status, applications.object.Status)
+			}
+			if object.ResourceVersion != applications.object.ResourceVersion {
+				t.Errorf("expected resource version %d, got %d", applications.object.ResourceVersion, object.ResourceVersion)
+			}
+
+			// 恢复后可以重新发布
+			if tc.expected != model.HelmApplicationProgressing {
+				if err := a.markProgressing(context.TODO(), &object, model.HelmApplicationDeploy); err != nil {
+					t.Errorf("expected rollout to start after recovery, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRollbackTargets(t *testing.T) {
+	old := []model.HelmApplicationTarget{
+		{Cluster: "a", Revision: 3, PreviousRevision: 2, ChartVersion: "1.1.0"},
+		{Cluster: "b", Revision: 1, PreviousRevision: 0, ChartVersion: "1.1.0"}, // 首次安装
+		{Cluster: "c", Revision: 0, PreviousRevision: 4, ChartVersion: "1.1.0"}, // 升级失败
+		{Cluster: "d", Revision: 7, PreviousRevision: 5, ChartVersion: "1.1.0"},
+	}
+
+	targets := rollbackTargets(old, 2)
+	if names := clusterNames(targets); !reflect.DeepEqual(names, []string{"a", "c", "d"}) {
+		t.Fatalf("unexpected rollback targets %v", names)
+	}
+	for i, expected := range []struct{ batch, previous int }{{0, 2}, {0, 4}, {1, 5}} {
+		if targets[i].Batch != expected.batch || targets[i].PreviousRevision != expected.previous ||
+			targets[i].Action != model.HelmApplicationRollback || targets[i].Status != model.HelmTargetPending {
+			t.Errorf("unexpected rollback target %+v", targets[i])
+		}
+	}
+
+	// 回滚后再次回滚，目标版本不变
+	again := rollbackTargets(targets, 2)
+	for i := range again {
+		if again[i].PreviousRevision != targets[i].PreviousRevision {
+			t.Errorf("repeated rollback of %s targets revision %d, expected %d", again[i].Cluster, again[i].PreviousRevision, targets[i].PreviousRevision)
+		}
+	}
+}
+
+func isConflict(err error) bool {
+	e, ok := err.(apierrors.Error)
+	return ok && e.Code == http.StatusConflict
+}
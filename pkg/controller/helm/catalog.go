@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/chartrepo"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+const maxSearchLimit = 100
+
+func (r *Repository) Sync(ctx context.Context, id int64) error {
+	repository, err := r.factory.Repository().Get(ctx, id)
+	if err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("repository not found"), http.StatusNotFound)
+		}
+		klog.Errorf("failed to get repository(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+	if err = r.indexer.Sync(ctx, repository); err != nil {
+		if errors.Is(err, chartrepo.ErrSyncRunning) {
+			return apierrors.NewError(err, http.StatusConflict)
+		}
+		return apierrors.NewError(fmt.Errorf("failed to sync repository %s: %v", repository.Name, err), http.StatusBadGateway)
+	}
+	return nil
+}
+
+// syncInBackground 仓库创建或更新后异步刷新 chart 目录，不阻塞请求
+func (r *Repository) syncInBackground(repository *model.Repository) {
+	go func() {
+		if err := r.indexer.Sync(context.Background(), repository); err != nil && !errors.Is(err, chartrepo.ErrSyncRunning) {
+			klog.Warningf("failed to sync repository %s in background: %v", repository.Name, err)
+		}
+	}()
+}
+
+func (r *Repository) SearchCharts(ctx context.Context, req *types.SearchCharts) (*types.PageResult, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 || req.Limit > maxSearchLimit {
+		req.Limit = 20
+	}
+	result := &types.PageResult{PageRequest: req.PageRequest}
+
+	opts := []db.Options{
+		db.WithRepository(req.RepositoryId),
+		db.WithChartKeyword(req.Keyword),
+		db.WithDeprecated(req.IncludeDeprecated),
+		db.WithChartSearch(req.Query),
+	}
+	var err error
+	if result.Total, err = r.factory.Repository().Chart().Count(ctx, opts...); err != nil {
+		klog.Errorf("failed to count charts: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+	opts = append(opts, db.WithOffset((req.Page-1)*req.Limit), db.WithLimit(req.Limit))
+	objects, err := r.factory.Repository().Chart().List(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to search charts: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	charts := make([]types.HelmChart, 0, len(objects))
+	for i := range objects {
+		charts = append(charts, *modelChart2Type(&objects[i], false))
+	}
+	result.Items = charts
+	return result, nil
+}
+
+func (r *Repository) GetChart(ctx context.Context, id int64, name string) (*types.HelmChart, error) {
+	object, err := r.factory.Repository().Chart().Get(ctx, id, name)
+	if err != nil {
+		klog.Errorf("failed to get chart %s of repository(%d): %v", name, id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, apierrors.NewError(fmt.Errorf("chart %s not found", name), http.StatusNotFound)
+	}
+	return modelChart2Type(object, true), nil
+}
+
+// catalogIndex 由 chart 目录缓存还原仓库索引，最新版本带完整元数据，其余版本只有摘要
+func (r *Repository) catalogIndex(ctx context.Context, id int64) (*model.ChartIndex, error) {
+	objects, err := r.factory.Repository().Chart().List(ctx, db.WithRepository(id))
+	if err != nil {
+		klog.Errorf("failed to list charts of repository(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	index := &model.ChartIndex{APIVersion: "v1", Entries: model.Entries{}}
+	for i := range objects {
+		chart := modelChart2Type(&objects[i], true)
+		versions := make([]model.ChartVersion, 0, len(chart.Versions))
+		for _, v := range chart.Versions {
+			cv := model.ChartVersion{
+				Name:       chart.Name,
+				Version:    v.Version,
+				AppVersion: v.AppVersion,
+				Digest:     v.Digest,
+				URLs:       v.URLs,
+				Deprecated: v.Deprecated,
+			}
+			if v.Created != nil {
+				cv.Created = *v.Created
+			}
+			versions = append(versions, cv)
+		}
+		if len(versions) != 0 {
+			versions[0].Description = chart.Description
+			versions[0].Icon = chart.Icon
+			versions[0].Home = chart.Home
+			versions[0].Keywords = chart.Keywords
+			versions[0].Dependencies = chart.Dependencies
+		}
+		index.Entries[chart.Name] = versions
+	}
+	return index, nil
+}
+
+func modelChart2Type(o *model.HelmChart, withVersions bool) *types.HelmChart {
+	chart := &types.HelmChart{
+		RepositoryId:   o.RepositoryId,
+		RepositoryName: o.RepositoryName,
+		Name:           o.Name,
+		LatestVersion:  o.LatestVersion,
+		AppVersion:     o.AppVersion,
+		Description:    o.Description,
+		Icon:           o.Icon,
+		Home:           o.Home,
+		Keywords:       []string{},
+		Deprecated:     o.Deprecated,
+		VersionCount:   o.VersionCount,
+		Created:        o.Created,
+	}
+	if keywords := strings.Trim(o.Keywords, ","); keywords != "" {
+		chart.Keywords = strings.Split(keywords, ",")
+	}
+	// 非核心数据，解析失败时忽略
+	if o.Dependencies != "" {
+		_ = json.Unmarshal([]byte(o.Dependencies), &chart.Dependencies)
+	}
+	if withVersions && o.Versions != "" {
+		_ = json.Unmarshal([]byte(o.Versions), &chart.Versions)
+	}
+	return chart
+}
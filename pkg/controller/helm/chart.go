@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/chartrepo"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+// fetchChart 从指定仓库（或匿名 oci:// 地址）加载 chart，repositoryId 为 0 且非 oci:// 地址时返回 nil
+func fetchChart(ctx context.Context, f db.ShareDaoFactory, repositoryId int64, name, version string) (*chart.Chart, error) {
+	var repository *model.Repository
+	switch {
+	case repositoryId != 0:
+		object, err := f.Repository().Get(ctx, repositoryId)
+		if err != nil {
+			if utilerrors.IsRecordNotFound(err) {
+				return nil, apierrors.NewError(fmt.Errorf("repository not found"), http.StatusNotFound)
+			}
+			return nil, err
+		}
+		repository = object
+	case strings.HasPrefix(name, "oci://"):
+		repoURL, chartName, tag, err := chartrepo.ParseOCIChartRef(name)
+		if err != nil {
+			return nil, apierrors.NewError(err, http.StatusBadRequest)
+		}
+		if version == "" {
+			version = tag
+		}
+		repository, name = &model.Repository{URL: repoURL}, chartName
+	default:
+		return nil, nil
+	}
+
+	source, err := chartrepo.NewSource(repository)
+	if err != nil {
+		return nil, apierrors.NewError(err, http.StatusBadRequest)
+	}
+	return chartrepo.LoadChart(ctx, source, name, version)
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	"github.com/caoyingjunz/pixiu/pkg/util/diff"
+)
+
+const (
+	resourceAdded    = "added"
+	resourceRemoved  = "removed"
+	resourceModified = "modified"
+
+	driftModified = "modified"
+	driftDeleted  = "deleted"
+	driftUnknown  = "unknown"
+)
+
+// Diff 比较 release 两个版本的 values 与渲染后的 manifest
+func (r *Releases) Diff(ctx context.Context, name string, opts types.ReleaseDiffOptions) (*types.ReleaseDiff, error) {
+	to, err := r.getRevision(name, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion := opts.From
+	if fromVersion == 0 {
+		fromVersion = to.Version - 1
+	}
+	if fromVersion < 1 {
+		return nil, apierrors.NewError(fmt.Errorf("release %s has no revision before %d", name, to.Version), http.StatusBadRequest)
+	}
+	from, err := r.getRevision(name, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	fromValues, err := valuesYAML(from)
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := valuesYAML(to)
+	if err != nil {
+		return nil, err
+	}
+	fromName, toName := fmt.Sprintf("%s@%d", name, from.Version), fmt.Sprintf("%s@%d", name, to.Version)
+
+	result := &types.ReleaseDiff{
+		Name:         name,
+		From:         from.Version,
+		To:           to.Version,
+		FromChart:    chartName(from),
+		ToChart:      chartName(to),
+		ValuesDiff:   diff.Unified(fromName, toName, fromValues, toValues),
+		ManifestDiff: diff.Unified(fromName, toName, from.Manifest, to.Manifest),
+		Resources:    []types.ReleaseResourceChange{},
+	}
+
+	fromObjects, toObjects := manifestsByKey(from.Manifest), manifestsByKey(to.Manifest)
+	for key, object := range toObjects {
+		old, ok := fromObjects[key]
+		switch {
+		case !ok:
+			result.Resources = append(result.Resources, object.change(resourceAdded))
+		case old.content != object.content:
+			result.Resources = append(result.Resources, object.change(resourceModified))
+		}
+	}
+	for key, object := range fromObjects {
+		if _, ok := toObjects[key]; !ok {
+			result.Resources = append(result.Resources, object.change(resourceRemoved))
+		}
+	}
+	sort.Slice(result.Resources, func(i, j int) bool {
+		a, b := result.Resources[i], result.Resources[j]
+		return a.Kind+"/"+a.Namespace+"/"+a.Name < b.Kind+"/"+b.Namespace+"/"+b.Name
+	})
+	return result, nil
+}
+
+// Drift 将当前版本渲染出的资源与集群中的线上资源比对，找出被手动修改或删除的资源。
+// 只比较 chart 中声明的字段，由 apiserver 补齐的默认值与 status 不视为漂移
+func (r *Releases) Drift(ctx context.Context, name string) (*types.ReleaseDrift, error) {
+	rel, err := r.getRevision(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.actionConfig.RESTClientGetter == nil {
+		return nil, fmt.Errorf("cluster client is not available")
+	}
+	restConfig, err := r.actionConfig.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := r.actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ReleaseDrift{
+		Name:      name,
+		Revision:  rel.Version,
+		CheckedAt: time.Now(),
+		Resources: []types.ReleaseDriftResource{},
+	}
+	for _, object := range sortedManifests(rel.Manifest) {
+		result.Total++
+		if res := checkDrift(ctx, dynamicClient, mapper, rel.Namespace, object); res != nil {
+			result.Resources = append(result.Resources, *res)
+		}
+	}
+	result.Drifted = len(result.Resources) != 0
+	return result, nil
+}
+
+func checkDrift(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, namespace string, object *manifestObject) *types.ReleaseDriftResource {
+	gvk := schema.FromAPIVersionAndKind(object.APIVersion, object.Kind)
+	res := &types.ReleaseDriftResource{
+		APIVersion: object.APIVersion,
+		Kind:       object.Kind,
+		Name:       object.Name,
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		res.Status, res.Message = driftUnknown, err.Error()
+		return res
+	}
+	var live *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		res.Namespace = object.Namespace
+		if res.Namespace == "" {
+			res.Namespace = namespace
+		}
+		live, err = client.Resource(mapping.Resource).Namespace(res.Namespace).Get(ctx, object.Name, metav1.GetOptions{})
+	} else {
+		live, err = client.Resource(mapping.Resource).Get(ctx, object.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			res.Status = driftDeleted
+			return res
+		}
+		res.Status, res.Message = driftUnknown, err.Error()
+		return res
+	}
+
+	desired := normalizeDesired(object.object)
+	projected, equal := project(desired, live.Object)
+	if equal {
+		return nil
+	}
+	res.Status = driftModified
+	// Secret 内容不在接口中回显
+	if object.Kind == "Secret" {
+		res.Message = "secret data differs from the release"
+		return res
+	}
+	desiredYAML, _ := yaml.Marshal(desired)
+	liveYAML, _ := yaml.Marshal(projected)
+	res.Diff = diff.Unified("release", "live", string(desiredYAML), string(liveYAML))
+	return res
+}
+
+// normalizeDesired 去掉比对时无意义的字段，Secret 的 stringData 转换为 data
+func normalizeDesired(object map[string]interface{}) map[string]interface{} {
+	desired := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		if k != "status" {
+			desired[k] = v
+		}
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		m := make(map[string]interface{})
+		for _, k := range []string{"name", "labels", "annotations"} {
+			if v, ok := metadata[k]; ok {
+				m[k] = v
+			}
+		}
+		desired["metadata"] = m
+	}
+	if stringData, ok := desired["stringData"].(map[string]interface{}); ok {
+		data, _ := desired["data"].(map[string]interface{})
+		merged := make(map[string]interface{}, len(data)+len(stringData))
+		for k, v := range data {
+			merged[k] = v
+		}
+		for k, v := range stringData {
+			merged[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+		}
+		desired["data"] = merged
+		delete(desired, "stringData")
+	}
+	return desired
+}
+
+// project 按 desired 的结构截取 live 中的对应字段，并判断 desired 声明的字段是否与 live 一致
+func project(desired, live interface{}) (interface{}, bool) {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live, false
+		}
+		projected := make(map[string]interface{}, len(d))
+		equal := true
+		for k, dv := range d {
+			lv, ok := l[k]
+			if !ok {
+				// chart 中声明为空值的字段在线上通常被省略
+				if !isEmpty(dv) {
+					equal = false
+				}
+				continue
+			}
+			pv, eq := project(dv, lv)
+			projected[k] = pv
+			equal = equal && eq
+		}
+		return projected, equal
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live, false
+		}
+		equal := len(d) == len(l)
+		projected := make([]interface{}, 0, len(l))
+		for i, lv := range l {
+			if i >= len(d) {
+				projected = append(projected, lv)
+				continue
+			}
+			pv, eq := project(d[i], lv)
+			projected = append(projected, pv)
+			equal = equal && eq
+		}
+		return projected, equal
+	default:
+		return live, scalarEqual(desired, live)
+	}
+}
+
+func scalarEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func isEmpty(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(x) == 0
+	case []interface{}:
+		return len(x) == 0
+	case string:
+		return x == ""
+	}
+	return false
+}
+
+// manifestObject release manifest 中的单个资源
+type manifestObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	content    string
+	object     map[string]interface{}
+}
+
+func (o *manifestObject) key() string {
+	return strings.Join([]string{o.APIVersion, o.Kind, o.Namespace, o.Name}, "/")
+}
+
+func (o *manifestObject) change(action string) types.ReleaseResourceChange {
+	return types.ReleaseResourceChange{Kind: o.Kind, Namespace: o.Namespace, Name: o.Name, Action: action}
+}
+
+// parseManifest 解析 helm 渲染出的 manifest，跳过空文档与无法识别的内容
+func parseManifest(manifest string) []*manifestObject {
+	var objects []*manifestObject
+	for _, content := range releaseutil.SplitManifests(manifest) {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(content), &object); err != nil || object == nil {
+			continue
+		}
+		u := unstructured.Unstructured{Object: object}
+		if u.GetKind() == "" || u.GetName() == "" {
+			continue
+		}
+		objects = append(objects, &manifestObject{
+			APIVersion: u.GetAPIVersion(),
+			Kind:       u.GetKind(),
+			Namespace:  u.GetNamespace(),
+			Name:       u.GetName(),
+			content:    content,
+			object:     object,
+		})
+	}
+	return objects
+}
+
+func manifestsByKey(manifest string) map[string]*manifestObject {
+	objects := make(map[string]*manifestObject)
+	for _, object := range parseManifest(manifest) {
+		objects[object.key()] = object
+	}
+	return objects
+}
+
+func sortedManifests(manifest string) []*manifestObject {
+	objects := parseManifest(manifest)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].key() < objects[j].key() })
+	return objects
+}
+
+// getRevision 获取 release 指定版本，version 为 0 时返回当前版本
+func (r *Releases) getRevision(name string, version int) (*release.Release, error) {
+	client := action.NewGet(r.actionConfig)
+	client.Version = version
+	rel, err := client.Run(name)
+	if err != nil {
+		if version != 0 && strings.Contains(err.Error(), "not found") {
+			return nil, apierrors.NewError(fmt.Errorf("release %s revision %d not found", name, version), http.StatusNotFound)
+		}
+		return nil, err
+	}
+	return rel, nil
+}
+
+func valuesYAML(rel *release.Release) (string, error) {
+	if len(rel.Config) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(rel.Config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func chartName(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return ""
+	}
+	return rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+}
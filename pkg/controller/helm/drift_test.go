@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestProject(t *testing.T) {
+	desired := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+	live := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 1234
+  resourceVersion: "99"
+  labels:
+    app: web
+spec:
+  replicas: 2
+  progressDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 2
+`
+	var d, l map[string]interface{}
+	if err := yaml.Unmarshal([]byte(desired), &d); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(live), &l); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, equal := project(normalizeDesired(d), l); !equal {
+		t.Error("expected defaulted fields not to be reported as drift")
+	}
+
+	l["spec"].(map[string]interface{})["replicas"] = int64(5)
+	projected, equal := project(normalizeDesired(d), l)
+	if equal {
+		t.Fatal("expected replicas change to be reported as drift")
+	}
+	spec := projected.(map[string]interface{})["spec"].(map[string]interface{})
+	if _, ok := spec["progressDeadlineSeconds"]; ok {
+		t.Error("projected object should only contain fields declared by the release")
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	manifest := `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: web/templates/empty.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`
+	objects := manifestsByKey(manifest)
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if _, ok := objects["v1/Service//web"]; !ok {
+		t.Errorf("service not found in %v", objects)
+	}
+}
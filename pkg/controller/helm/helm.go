@@ -23,6 +23,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli"
 	"k8s.io/klog/v2"
 
+	"github.com/caoyingjunz/pixiu/cmd/app/config"
 	"github.com/caoyingjunz/pixiu/pkg/client"
 	"github.com/caoyingjunz/pixiu/pkg/controller/cluster"
 	"github.com/caoyingjunz/pixiu/pkg/db"
@@ -35,9 +36,11 @@ type HelmGetter interface {
 type Interface interface {
 	Release(cluster, namespace string) ReleaseInterface
 	Repository() RepositoryInterface
+	Application() ApplicationInterface
 }
 
 type Helm struct {
+	cc      config.Config
 	factory db.ShareDaoFactory
 }
 
@@ -53,15 +56,20 @@ func (h *Helm) Release(cluster, namespace string) ReleaseInterface {
 		"secrets",
 		klog.Infof,
 	)
-	return NewReleases(actionConfig, settings)
+	return NewReleases(actionConfig, settings, h.factory)
 }
 
 func (h *Helm) Repository() RepositoryInterface {
-	return NewRepository(h.factory)
+	return NewRepository(h.cc.ChartCatalog, h.factory)
 }
 
-func NewHelm(factory db.ShareDaoFactory) Interface {
+func (h *Helm) Application() ApplicationInterface {
+	return NewApplication(h)
+}
+
+func NewHelm(cfg config.Config, factory db.ShareDaoFactory) Interface {
 	return &Helm{
+		cc:      cfg,
 		factory: factory,
 	}
 }
@@ -31,6 +31,7 @@ import (
 	"helm.sh/helm/v3/pkg/release"
 	"k8s.io/klog/v2"
 
+	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/types"
 )
 
@@ -43,17 +44,21 @@ type ReleaseInterface interface {
 	Upgrade(ctx context.Context, form *types.Release) (*release.Release, error)
 	History(ctx context.Context, name string) ([]*release.Release, error)
 	Rollback(ctx context.Context, name string, toVersion int) error
+	Diff(ctx context.Context, name string, opts types.ReleaseDiffOptions) (*types.ReleaseDiff, error)
+	Drift(ctx context.Context, name string) (*types.ReleaseDrift, error)
 }
 
 type Releases struct {
 	settings     *cli.EnvSettings
 	actionConfig *action.Configuration
+	factory      db.ShareDaoFactory
 }
 
-func NewReleases(actionConfig *action.Configuration, settings *cli.EnvSettings) *Releases {
+func NewReleases(actionConfig *action.Configuration, settings *cli.EnvSettings, f db.ShareDaoFactory) *Releases {
 	return &Releases{
 		actionConfig: actionConfig,
 		settings:     settings,
+		factory:      f,
 	}
 }
 
@@ -80,7 +85,7 @@ func (r *Releases) Install(ctx context.Context, form *types.Release) (*release.R
 	if client.DryRun {
 		client.Description = "server"
 	}
-	chart, err := r.locateChart(client.ChartPathOptions, form.Chart, r.settings)
+	chart, err := r.resolveChart(ctx, client.ChartPathOptions, form)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +110,8 @@ func (r *Releases) Upgrade(ctx context.Context, form *types.Release) (*release.R
 		client.Description = "server"
 	}
 
-	chart, err := r.locateChart(client.ChartPathOptions, form.Chart, r.settings)
+	client.Version = form.Version
+	chart, err := r.resolveChart(ctx, client.ChartPathOptions, form)
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +140,28 @@ func (r *Releases) Rollback(ctx context.Context, name string, toVersion int) err
 	return client.Run(name)
 }
 
+// resolveChart 指定仓库或 oci:// 地址时通过仓库配置拉取 chart，否则沿用 helm 的 chart 定位逻辑
+func (r *Releases) resolveChart(ctx context.Context, pathOpts action.ChartPathOptions, form *types.Release) (*chart.Chart, error) {
+	ch, err := fetchChart(ctx, r.factory, form.RepositoryId, form.Chart, form.Version)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return r.locateChart(pathOpts, form.Chart, r.settings)
+	}
+
+	if err = checkIfInstallable(ch); err != nil {
+		return nil, err
+	}
+	// 仓库中的 chart 包需自带依赖，无法在服务端执行 helm dependency build
+	if req := ch.Metadata.Dependencies; req != nil {
+		if err = action.CheckDependencies(ch, req); err != nil {
+			return nil, fmt.Errorf("chart %s has missing dependencies: %v", form.Chart, err)
+		}
+	}
+	return ch, nil
+}
+
 func (r *Releases) locateChart(pathOpts action.ChartPathOptions, chart string, settings *cli.EnvSettings) (*chart.Chart, error) {
 	// from cmd/helm/install.go and cmd/helm/upgrade.go
 	cp, err := pathOpts.LocateChart(chart, settings)
@@ -20,18 +20,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strings"
 
 	"k8s.io/klog/v2"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/getter"
-	"helm.sh/helm/v3/pkg/repo"
-	"k8s.io/apimachinery/pkg/util/yaml"
 
 	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/chartrepo"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	"github.com/caoyingjunz/pixiu/pkg/types"
@@ -51,36 +49,58 @@ type RepositoryInterface interface {
 
 	GetChartsById(ctx context.Context, id int64) (*model.ChartIndex, error)
 	GetChartsByURL(ctx context.Context, repoURL string) (*model.ChartIndex, error)
-	GetChartValues(ctx context.Context, chart, version string) (string, error)
+	GetChartValues(ctx context.Context, repositoryId int64, chart, version string) (string, error)
+
+	// Sync 立即刷新仓库的 chart 目录缓存
+	Sync(ctx context.Context, id int64) error
+	// SearchCharts 在所有仓库的 chart 目录缓存中搜索
+	SearchCharts(ctx context.Context, req *types.SearchCharts) (*types.PageResult, error)
+	GetChart(ctx context.Context, id int64, name string) (*types.HelmChart, error)
 }
 
 type Repository struct {
 	settings     *cli.EnvSettings
 	actionConfig *action.Configuration
 	factory      db.ShareDaoFactory
+	indexer      *chartrepo.Indexer
 }
 
-func NewRepository(f db.ShareDaoFactory) *Repository {
+func NewRepository(cfg chartrepo.CatalogOptions, f db.ShareDaoFactory) *Repository {
 	settings := cli.New()
 	actionConfig := new(action.Configuration)
 	actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), "secrets", klog.Infof)
-	return &Repository{factory: f, settings: settings, actionConfig: actionConfig}
+	return &Repository{factory: f, settings: settings, actionConfig: actionConfig, indexer: chartrepo.NewIndexer(cfg, f)}
 }
 
 var _ RepositoryInterface = &Repository{}
 
 func (r *Repository) Create(ctx context.Context, repo *types.CreateRepository) error {
-
+	t, err := chartrepo.DetectType(repo.URL)
+	if err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
 	repoModel := &model.Repository{
-		Name: repo.Name,
-		URL:  repo.URL,
+		Name:                  repo.Name,
+		URL:                   strings.TrimSpace(repo.URL),
+		Type:                  t,
+		Username:              repo.Username,
+		Password:              repo.Password,
+		CAData:                repo.CAData,
+		InsecureSkipTLSVerify: repo.InsecureSkipTLSVerify,
+		Charts:                joinCharts(repo.Charts),
+	}
+	if _, err = chartrepo.NewSource(repoModel); err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
 	}
 	if res, _ := r.GetByName(ctx, repoModel.Name); res != nil {
 		return fmt.Errorf("repository %s already exists", repoModel.Name)
 	}
 
-	_, err := r.factory.Repository().Create(ctx, repoModel)
-	return err
+	if repoModel, err = r.factory.Repository().Create(ctx, repoModel); err != nil {
+		return err
+	}
+	r.syncInBackground(repoModel)
+	return nil
 }
 
 func (r *Repository) Delete(ctx context.Context, id int64) error {
@@ -120,52 +140,95 @@ func (r *Repository) List(ctx context.Context) ([]*model.Repository, error) {
 }
 
 // 更新前置检查：资源存在（Helm 仓库无标准 ErrXxxNotFound，错误原样透传）
-func (r *Repository) preUpdate(ctx context.Context, id int64) error {
-	if _, err := r.factory.Repository().Get(ctx, id); err != nil {
+func (r *Repository) preUpdate(ctx context.Context, id int64) (*model.Repository, error) {
+	object, err := r.factory.Repository().Get(ctx, id)
+	if err != nil {
 		klog.Errorf("failed to get repository(%d): %v", id, err)
-		return err
+		return nil, err
 	}
-	return nil
+	return object, nil
 }
 
 func (r *Repository) Update(ctx context.Context, id int64, update *types.UpdateRepository) error {
-	if err := r.preUpdate(ctx, id); err != nil {
+	old, err := r.preUpdate(ctx, id)
+	if err != nil {
 		klog.Errorf("pre-update check failed for repository(%d): %v", id, err)
 		return err
 	}
+	t, err := chartrepo.DetectType(update.URL)
+	if err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
+
+	// 密码不回显，未填写时沿用原密码；取消用户名时一并清除
+	password := update.Password
+	if password == "" {
+		password = old.Password
+	}
+	if update.Username == "" {
+		password = ""
+	}
+	if _, err = chartrepo.NewHTTPClient(update.CAData, update.InsecureSkipTLSVerify); err != nil {
+		return apierrors.NewError(err, http.StatusBadRequest)
+	}
 
 	updates := map[string]interface{}{
-		"name":     update.Name,
-		"url":      update.URL,
-		"username": update.Username,
-		"password": update.Password,
+		"name":                     update.Name,
+		"url":                      strings.TrimSpace(update.URL),
+		"type":                     t,
+		"username":                 update.Username,
+		"password":                 password,
+		"ca_data":                  update.CAData,
+		"insecure_skip_tls_verify": update.InsecureSkipTLSVerify,
+		"charts":                   joinCharts(update.Charts),
 	}
-	return r.factory.Repository().Update(ctx, id, *update.ResourceVersion, updates)
+	if err = r.factory.Repository().Update(ctx, id, *update.ResourceVersion, updates); err != nil {
+		return err
+	}
+	// 地址或凭证可能已变化，重新同步 chart 目录
+	if object, err := r.factory.Repository().Get(ctx, id); err == nil {
+		r.syncInBackground(object)
+	}
+	return nil
 }
 
+// GetChartsById 优先返回缓存的 chart 目录，从未同步成功时实时拉取
 func (r *Repository) GetChartsById(ctx context.Context, id int64) (*model.ChartIndex, error) {
 	repository, err := r.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if repository.SyncStatus == model.RepositorySyncSucceeded || repository.ChartCount > 0 {
+		return r.catalogIndex(ctx, id)
+	}
 
-	entry := &repo.Entry{
-		Name:     repository.Name,
-		URL:      repository.URL,
-		Username: repository.Username,
-		Password: repository.Password,
+	index, err := r.fetch(ctx, repository)
+	if err != nil {
+		return nil, err
 	}
-	return r.fetch(ctx, entry)
+	r.syncInBackground(repository)
+	return index, nil
 }
 
 func (r *Repository) GetChartsByURL(ctx context.Context, repoURL string) (*model.ChartIndex, error) {
-	entry := &repo.Entry{
-		URL: repoURL,
-	}
-	return r.fetch(ctx, entry)
+	return r.fetch(ctx, &model.Repository{URL: repoURL})
 }
 
-func (r *Repository) GetChartValues(_ context.Context, chart, version string) (string, error) {
+// GetChartValues 获取 chart 默认 values，指定仓库时使用仓库的凭证与 TLS 配置
+func (r *Repository) GetChartValues(ctx context.Context, repositoryId int64, chart, version string) (string, error) {
+	ch, err := fetchChart(ctx, r.factory, repositoryId, chart, version)
+	if err != nil {
+		return "", err
+	}
+	if ch != nil {
+		for _, f := range ch.Raw {
+			if f.Name == chartutil.ValuesfileName {
+				return string(f.Data), nil
+			}
+		}
+		return "", nil
+	}
+
 	client := action.NewShowWithConfig(action.ShowValues, r.actionConfig)
 	client.Version = version
 	cp, err := client.ChartPathOptions.LocateChart(chart, r.settings)
@@ -181,61 +244,20 @@ func (r *Repository) GetChartValues(_ context.Context, chart, version string) (s
 	return out, nil
 }
 
-func (r *Repository) resolveReferenceURL(baseURL, refURL string) (string, error) {
-	parsedRefURL, err := url.Parse(refURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse %s as URL, %v", refURL, err)
-	}
-
-	if parsedRefURL.IsAbs() {
-		return refURL, nil
-	}
-
-	parsedBaseURL, err := url.Parse(baseURL)
+func (r *Repository) fetch(ctx context.Context, repository *model.Repository) (*model.ChartIndex, error) {
+	source, err := chartrepo.NewSource(repository)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse %s as URL, %v", baseURL, err)
+		return nil, apierrors.NewError(err, http.StatusBadRequest)
 	}
-
-	parsedBaseURL.RawPath = strings.TrimSuffix(parsedBaseURL.RawPath, "/") + "/"
-	parsedBaseURL.Path = strings.TrimSuffix(parsedBaseURL.Path, "/") + "/"
-
-	resolvedURL := parsedBaseURL.ResolveReference(parsedRefURL)
-	resolvedURL.RawQuery = parsedBaseURL.RawQuery
-	return resolvedURL.String(), nil
+	return source.Index(ctx)
 }
 
-func (r *Repository) fetch(_ context.Context, entry *repo.Entry) (*model.ChartIndex, error) {
-	var charts model.ChartIndex
-
-	rep, err := repo.NewChartRepository(entry, getter.All(r.settings))
-	if err != nil {
-		return nil, err
-	}
-
-	// download index
-	if _, err = rep.DownloadIndexFile(); err != nil {
-		return nil, err
-	}
-
-	indexURL, err := r.resolveReferenceURL(rep.Config.URL, "index.yaml")
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := rep.Client.Get(indexURL,
-		getter.WithURL(rep.Config.URL),
-		getter.WithInsecureSkipVerifyTLS(rep.Config.InsecureSkipTLSverify),
-		getter.WithTLSClientConfig(rep.Config.CertFile, rep.Config.KeyFile, rep.Config.CAFile),
-		getter.WithBasicAuth(rep.Config.Username, rep.Config.Password),
-		getter.WithPassCredentialsAll(rep.Config.PassCredentialsAll),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	err = yaml.Unmarshal(resp.Bytes(), &charts)
-	if err != nil {
-		return nil, err
+func joinCharts(charts []string) string {
+	var names []string
+	for _, name := range charts {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
 	}
-	return &charts, nil
+	return strings.Join(names, ",")
 }
@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"k8s.io/klog/v2"
 
 	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
 	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	ctrlutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	"github.com/caoyingjunz/pixiu/pkg/types"
@@ -35,8 +37,12 @@ type Interface interface {
 	Delete(ctx context.Context, id int64) error
 	Get(ctx context.Context, id int64) (*types.Message, error)
 	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
+	// Usage 按用户、租户、模型或天聚合 token 用量和费用，非超级管理员只能查看自己的用量
+	Usage(ctx context.Context, req *types.AIUsageReportRequest) ([]types.AIUsageItem, error)
 }
 
+const usageDateLayout = "2006-01-02"
+
 type controller struct {
 	cc      config.Config
 	factory db.ShareDaoFactory
@@ -122,6 +128,58 @@ func (c *controller) List(ctx context.Context, listOption types.ListOptions) (in
 	return pageResult, nil
 }
 
+func (c *controller) Usage(ctx context.Context, req *types.AIUsageReportRequest) ([]types.AIUsageItem, error) {
+	userId, err := ctrlutil.EffectiveUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, apierrors.ErrUnauthorized
+	}
+
+	opts := []db.Options{
+		db.WithUser(userId),
+		db.WithAIAccountId(req.AccountId),
+		db.WithAIModel(req.Model),
+	}
+	if req.TenantId != 0 {
+		opts = append(opts, db.WithTenantId(req.TenantId))
+	}
+	if req.Start != "" {
+		start, err := time.ParseInLocation(usageDateLayout, req.Start, time.Local)
+		if err != nil {
+			return nil, apierrors.NewError(fmt.Errorf("invalid start date %q", req.Start), http.StatusBadRequest)
+		}
+		opts = append(opts, db.WithCreatedAfter(start))
+	}
+	if req.End != "" {
+		end, err := time.ParseInLocation(usageDateLayout, req.End, time.Local)
+		if err != nil {
+			return nil, apierrors.NewError(fmt.Errorf("invalid end date %q", req.End), http.StatusBadRequest)
+		}
+		opts = append(opts, db.WithCreatedBefore(end.AddDate(0, 0, 1)))
+	}
+
+	objects, err := c.factory.Assistant().Message().AggregateUsage(ctx, req.GroupBy, opts...)
+	if err != nil {
+		klog.Errorf("failed to aggregate ai usage by %s: %v", req.GroupBy, err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	items := make([]types.AIUsageItem, 0, len(objects))
+	for _, object := range objects {
+		items = append(items, types.AIUsageItem{
+			Key:             object.Dimension,
+			Currency:        object.Currency,
+			Requests:        object.Requests,
+			InputTokens:     object.InputTokens,
+			OutputTokens:    object.OutputTokens,
+			TotalTokens:     object.TotalTokens,
+			CachedTokens:    object.CachedTokens,
+			ReasoningTokens: object.ReasoningTokens,
+			Cost:            object.Cost,
+		})
+	}
+	return items, nil
+}
+
 func modelToType(object *model.Message) *types.Message {
 	return &types.Message{
 		PixiuMeta: types.PixiuMeta{
@@ -148,5 +206,10 @@ func modelToType(object *model.Message) *types.Message {
 		TotalTokens:     object.TotalTokens,
 		CachedTokens:    object.CachedTokens,
 		ReasoningTokens: object.ReasoningTokens,
+		UserId:          object.UserId,
+		TenantId:        object.TenantId,
+		AccountId:       object.AccountId,
+		Cost:            object.Cost,
+		Currency:        object.Currency,
 	}
 }
@@ -0,0 +1,254 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+// hostAddr 获取节点 SSH 地址（ip + 认证配置中的端口），并校验节点访问权限
+func (n *nodeController) hostAddr(ctx context.Context, nodeId int64) (string, int, error) {
+	object, err := n.factory.Plan().GetNode(ctx, nodeId)
+	if err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return "", 0, errors.ErrNodeNotFound
+		}
+		klog.Errorf("get node %d: %v", nodeId, err)
+		return "", 0, errors.ErrServerInternal
+	}
+	if err = util.CheckResourceAccess(ctx, n.factory, object.UserId, types.ResourceTypeNode, nodeId); err != nil {
+		return "", 0, err
+	}
+
+	var auth types.PlanNodeAuth
+	if err = auth.Unmarshal(object.Auth); err != nil {
+		klog.Errorf("unmarshal auth of node %d: %v", nodeId, err)
+		return "", 0, errors.ErrInvalidRequest
+	}
+	return object.Ip, auth.SSHPort(), nil
+}
+
+// GetHostKey 查看节点记录的 SSH 主机公钥及待确认公钥
+func (n *nodeController) GetHostKey(ctx context.Context, nodeId int64) (*types.NodeHostKeyResult, error) {
+	host, port, err := n.hostAddr(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	object, err := n.factory.Plan().HostKey().Get(ctx, host, port)
+	if err != nil {
+		klog.Errorf("get ssh host key of %s:%d: %v", host, port, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil {
+		return &types.NodeHostKeyResult{Host: host, Port: port}, nil
+	}
+	return model2HostKey(object), nil
+}
+
+// PinHostKey 手动固定节点的主机公钥或指纹，覆盖已记录的公钥
+func (n *nodeController) PinHostKey(ctx context.Context, nodeId int64, req *types.PinNodeHostKeyRequest) error {
+	var keyType, fingerprint, publicKey string
+	switch {
+	case req.PublicKey != "":
+		key, err := sshutil.ParsePublicKey(req.PublicKey)
+		if err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+		keyType, fingerprint, publicKey = key.Type(), ssh.FingerprintSHA256(key), sshutil.MarshalPublicKey(key)
+	case req.Fingerprint != "":
+		fp, err := sshutil.NormalizeFingerprint(req.Fingerprint)
+		if err != nil {
+			return errors.NewError(err, http.StatusBadRequest)
+		}
+		fingerprint = fp
+	default:
+		return errors.NewError(fmt.Errorf("public_key 与 fingerprint 不能同时为空"), http.StatusBadRequest)
+	}
+
+	host, port, err := n.hostAddr(ctx, nodeId)
+	if err != nil {
+		return err
+	}
+	return n.setHostKey(ctx, host, port, keyType, fingerprint, publicKey)
+}
+
+// AcceptHostKey 接受最近一次校验失败时收到的公钥，用于确认主机重装或更换公钥后恢复连接
+func (n *nodeController) AcceptHostKey(ctx context.Context, nodeId int64) error {
+	host, port, err := n.hostAddr(ctx, nodeId)
+	if err != nil {
+		return err
+	}
+	object, err := n.factory.Plan().HostKey().Get(ctx, host, port)
+	if err != nil {
+		klog.Errorf("get ssh host key of %s:%d: %v", host, port, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil || object.PendingFingerprint == "" {
+		return errors.NewError(fmt.Errorf("节点没有待确认的主机公钥"), http.StatusConflict)
+	}
+	return n.setHostKey(ctx, host, port, object.PendingKeyType, object.PendingFingerprint, object.PendingPublicKey)
+}
+
+// ResetHostKey 删除节点记录的主机公钥，下次连接时重新首次信任
+func (n *nodeController) ResetHostKey(ctx context.Context, nodeId int64) error {
+	host, port, err := n.hostAddr(ctx, nodeId)
+	if err != nil {
+		return err
+	}
+	if err = n.factory.Plan().HostKey().Delete(ctx, host, port); err != nil {
+		klog.Errorf("delete ssh host key of %s:%d: %v", host, port, err)
+		return errors.ErrServerInternal
+	}
+	klog.Infof("ssh host key of %s:%d has been reset", host, port)
+	return nil
+}
+
+// setHostKey 将指定公钥写为节点的已确认公钥，并清除待确认公钥
+func (n *nodeController) setHostKey(ctx context.Context, host string, port int, keyType, fingerprint, publicKey string) error {
+	dao := n.factory.Plan().HostKey()
+	object, err := dao.Get(ctx, host, port)
+	if err != nil {
+		klog.Errorf("get ssh host key of %s:%d: %v", host, port, err)
+		return errors.ErrServerInternal
+	}
+	if object == nil {
+		if _, err = dao.Create(ctx, &model.SSHHostKey{
+			Host:        host,
+			Port:        port,
+			KeyType:     keyType,
+			Fingerprint: fingerprint,
+			PublicKey:   publicKey,
+			Pinned:      true,
+		}); err != nil {
+			klog.Errorf("create ssh host key of %s:%d: %v", host, port, err)
+			return errors.ErrServerInternal
+		}
+	} else {
+		if err = dao.Update(ctx, object.Id, object.ResourceVersion, map[string]interface{}{
+			"key_type":            keyType,
+			"fingerprint":         fingerprint,
+			"public_key":          publicKey,
+			"pinned":              true,
+			"pending_key_type":    "",
+			"pending_fingerprint": "",
+			"pending_public_key":  "",
+		}); err != nil {
+			if err == utilerrors.ErrRecordNotUpdate {
+				return errors.NewError(fmt.Errorf("主机公钥已被修改，请刷新后重试"), http.StatusConflict)
+			}
+			klog.Errorf("update ssh host key of %s:%d: %v", host, port, err)
+			return errors.ErrServerInternal
+		}
+	}
+	klog.Infof("ssh host key of %s:%d has been pinned to %s", host, port, fingerprint)
+	return nil
+}
+
+func model2HostKey(o *model.SSHHostKey) *types.NodeHostKeyResult {
+	return &types.NodeHostKeyResult{
+		Host:               o.Host,
+		Port:               o.Port,
+		Recorded:           true,
+		KeyType:            o.KeyType,
+		Fingerprint:        o.Fingerprint,
+		PublicKey:          o.PublicKey,
+		Pinned:             o.Pinned,
+		PendingKeyType:     o.PendingKeyType,
+		PendingFingerprint: o.PendingFingerprint,
+		PendingPublicKey:   o.PendingPublicKey,
+		LastSeen:           o.LastSeen,
+	}
+}
+
+// HostKeyStore 基于数据库的主机公钥存储：首次连接记录公钥（TOFU），之后不一致则拒绝连接，
+// 服务启动时通过 sshutil.SetHostKeyStore 注册
+type HostKeyStore struct {
+	Factory db.ShareDaoFactory
+}
+
+func (s HostKeyStore) Verify(ctx context.Context, host string, port int, key ssh.PublicKey) error {
+	dao := s.Factory.Plan().HostKey()
+	fingerprint := ssh.FingerprintSHA256(key)
+	now := time.Now()
+
+	object, err := dao.Get(ctx, host, port)
+	if err != nil {
+		return fmt.Errorf("failed to get ssh host key of %s:%d: %v", host, port, err)
+	}
+	if object == nil {
+		_, err = dao.Create(ctx, &model.SSHHostKey{
+			Host:        host,
+			Port:        port,
+			KeyType:     key.Type(),
+			Fingerprint: fingerprint,
+			PublicKey:   sshutil.MarshalPublicKey(key),
+			LastSeen:    &now,
+		})
+		if err == nil {
+			klog.Infof("recorded ssh host key of %s:%d on first use: %s %s", host, port, key.Type(), fingerprint)
+			return nil
+		}
+		// 并发首次连接时可能唯一索引冲突，按已记录的公钥校验
+		if object, _ = dao.Get(ctx, host, port); object == nil {
+			return fmt.Errorf("failed to record ssh host key of %s:%d: %v", host, port, err)
+		}
+	}
+
+	if object.Fingerprint == fingerprint {
+		updates := map[string]interface{}{"last_seen": now}
+		// 仅固定了指纹时，首次连接补齐公钥
+		if object.PublicKey == "" {
+			updates["key_type"] = key.Type()
+			updates["public_key"] = sshutil.MarshalPublicKey(key)
+		}
+		if object.PendingFingerprint != "" {
+			updates["pending_key_type"] = ""
+			updates["pending_fingerprint"] = ""
+			updates["pending_public_key"] = ""
+		}
+		if err = dao.InternalUpdate(ctx, object.Id, updates); err != nil {
+			klog.Warningf("failed to update ssh host key of %s:%d: %v", host, port, err)
+		}
+		return nil
+	}
+
+	klog.Warningf("ssh host key mismatch for %s:%d: expected %s, got %s", host, port, object.Fingerprint, fingerprint)
+	if object.PendingFingerprint != fingerprint {
+		if err = dao.InternalUpdate(ctx, object.Id, map[string]interface{}{
+			"pending_key_type":    key.Type(),
+			"pending_fingerprint": fingerprint,
+			"pending_public_key":  sshutil.MarshalPublicKey(key),
+		}); err != nil {
+			klog.Warningf("failed to record pending ssh host key of %s:%d: %v", host, port, err)
+		}
+	}
+	return &sshutil.HostKeyMismatchError{Host: host, Port: port, Expected: object.Fingerprint, Actual: fingerprint}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	plan *fakePlan
+}
+
+func (f *fakeFactory) Plan() db.PlanInterface { return f.plan }
+
+type fakePlan struct {
+	db.PlanInterface
+	hostKeys *fakeHostKeys
+}
+
+func (p *fakePlan) HostKey() db.HostKeyInterface { return p.hostKeys }
+
+// fakeHostKeys 内存中的主机公钥表，createErr 用于模拟并发首次连接时的唯一索引冲突
+type fakeHostKeys struct {
+	db.HostKeyInterface
+	object    *model.SSHHostKey
+	createErr error
+	racer     *model.SSHHostKey
+}
+
+func (f *fakeHostKeys) Get(_ context.Context, host string, port int) (*model.SSHHostKey, error) {
+	if f.object == nil || f.object.Host != host || f.object.Port != port {
+		return nil, nil
+	}
+	o := *f.object
+	return &o, nil
+}
+
+func (f *fakeHostKeys) Create(_ context.Context, object *model.SSHHostKey) (*model.SSHHostKey, error) {
+	if f.createErr != nil {
+		f.object = f.racer
+		return nil, f.createErr
+	}
+	object.Id = 1
+	f.object = object
+	return object, nil
+}
+
+func (f *fakeHostKeys) InternalUpdate(_ context.Context, _ int64, updates map[string]interface{}) error {
+	for k, v := range updates {
+		switch k {
+		case "key_type":
+			f.object.KeyType = v.(string)
+		case "public_key":
+			f.object.PublicKey = v.(string)
+		case "pending_key_type":
+			f.object.PendingKeyType = v.(string)
+		case "pending_fingerprint":
+			f.object.PendingFingerprint = v.(string)
+		case "pending_public_key":
+			f.object.PendingPublicKey = v.(string)
+		}
+	}
+	return nil
+}
+
+func newHostPublicKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newStore(keys *fakeHostKeys) HostKeyStore {
+	return HostKeyStore{Factory: &fakeFactory{plan: &fakePlan{hostKeys: keys}}}
+}
+
+func TestHostKeyStoreTrustOnFirstUse(t *testing.T) {
+	keys := &fakeHostKeys{}
+	key := newHostPublicKey(t)
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, key); err != nil {
+		t.Fatalf("first use should be trusted: %v", err)
+	}
+	if keys.object == nil || keys.object.Fingerprint != ssh.FingerprintSHA256(key) ||
+		keys.object.PublicKey != sshutil.MarshalPublicKey(key) || keys.object.LastSeen == nil {
+		t.Fatalf("host key not recorded on first use: %+v", keys.object)
+	}
+	// 之后同一公钥可继续连接
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, key); err != nil {
+		t.Fatalf("recorded key should verify: %v", err)
+	}
+}
+
+func TestHostKeyStoreMismatch(t *testing.T) {
+	recorded, actual := newHostPublicKey(t), newHostPublicKey(t)
+	keys := &fakeHostKeys{object: &model.SSHHostKey{
+		Model: pixiu.Model{Id: 1}, Host: "10.0.0.1", Port: 22,
+		KeyType: recorded.Type(), Fingerprint: ssh.FingerprintSHA256(recorded), PublicKey: sshutil.MarshalPublicKey(recorded),
+	}}
+
+	err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, actual)
+	var mismatch *sshutil.HostKeyMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected HostKeyMismatchError, got %v", err)
+	}
+	if mismatch.Expected != ssh.FingerprintSHA256(recorded) || mismatch.Actual != ssh.FingerprintSHA256(actual) {
+		t.Fatalf("unexpected mismatch detail: %+v", mismatch)
+	}
+	if keys.object.Fingerprint != ssh.FingerprintSHA256(recorded) {
+		t.Fatal("recorded host key must not be replaced on mismatch")
+	}
+	if keys.object.PendingFingerprint != ssh.FingerprintSHA256(actual) || keys.object.PendingPublicKey != sshutil.MarshalPublicKey(actual) {
+		t.Fatalf("mismatched key should be recorded as pending: %+v", keys.object)
+	}
+
+	// 恢复原公钥连接后清除待确认公钥
+	if err = newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if keys.object.PendingFingerprint != "" || keys.object.PendingPublicKey != "" {
+		t.Fatalf("pending key should be cleared after a matching connection: %+v", keys.object)
+	}
+}
+
+func TestHostKeyStorePinnedFingerprint(t *testing.T) {
+	key := newHostPublicKey(t)
+	keys := &fakeHostKeys{object: &model.SSHHostKey{
+		Model: pixiu.Model{Id: 1}, Host: "10.0.0.1", Port: 2222, Fingerprint: ssh.FingerprintSHA256(key), Pinned: true,
+	}}
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 2222, key); err != nil {
+		t.Fatal(err)
+	}
+	if keys.object.PublicKey != sshutil.MarshalPublicKey(key) || keys.object.KeyType != key.Type() {
+		t.Fatalf("public key should be filled for a pinned fingerprint: %+v", keys.object)
+	}
+}
+
+func TestHostKeyStoreConcurrentFirstUse(t *testing.T) {
+	winner, loser := newHostPublicKey(t), newHostPublicKey(t)
+	racer := &model.SSHHostKey{Model: pixiu.Model{Id: 1}, Host: "10.0.0.1", Port: 22, Fingerprint: ssh.FingerprintSHA256(winner)}
+
+	keys := &fakeHostKeys{createErr: errors.New("duplicate entry"), racer: racer}
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, winner); err != nil {
+		t.Fatalf("key recorded by a concurrent connection should verify: %v", err)
+	}
+
+	keys = &fakeHostKeys{createErr: errors.New("duplicate entry"), racer: racer}
+	var mismatch *sshutil.HostKeyMismatchError
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, loser); !errors.As(err, &mismatch) {
+		t.Fatalf("expected mismatch against concurrently recorded key, got %v", err)
+	}
+
+	keys = &fakeHostKeys{createErr: errors.New("connection refused")}
+	if err := newStore(keys).Verify(context.TODO(), "10.0.0.1", 22, loser); err == nil {
+		t.Fatal("expected error when host key cannot be recorded")
+	}
+}
@@ -18,6 +18,7 @@ package node
 
 import (
 	"context"
+	stderrors "errors"
 
 	"k8s.io/klog/v2"
 
@@ -42,6 +43,12 @@ type Interface interface {
 	Get(ctx context.Context, nodeId int64) (*types.NodeResult, error)
 	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
 	CheckConnectivity(ctx context.Context, req *types.NodeConnectivityRequest) (*types.NodeConnectivityResult, error)
+
+	// 节点 SSH 主机公钥（known_hosts）管理
+	GetHostKey(ctx context.Context, nodeId int64) (*types.NodeHostKeyResult, error)
+	PinHostKey(ctx context.Context, nodeId int64, req *types.PinNodeHostKeyRequest) error
+	AcceptHostKey(ctx context.Context, nodeId int64) error
+	ResetHostKey(ctx context.Context, nodeId int64) error
 }
 
 type nodeController struct {
@@ -238,6 +245,8 @@ func (n *nodeController) CheckConnectivity(ctx context.Context, req *types.NodeC
 	if e != nil {
 		// 连通性失败返回 200 + connected=false + message，不将业务失败当 HTTP 错误
 		result.Message = e.Error()
+		var mismatch *sshutil.HostKeyMismatchError
+		result.HostKeyMismatch = stderrors.As(e, &mismatch)
 		return result, nil
 	}
 	defer client.Close()
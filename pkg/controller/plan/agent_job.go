@@ -27,6 +27,7 @@ import (
 
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
 )
 
 const (
@@ -107,17 +108,29 @@ func (a AgentJob) Run() error {
 	}
 }
 
-func buildRegisterPayload(nodes []model.Node) (string, error) {
+func buildRegisterPayload(ctx context.Context, factory db.ShareDaoFactory, nodes []model.Node) (string, error) {
 	type nodeAuth struct {
 		Name string `json:"name"`
 		Ip   string `json:"ip"`
 		Role string `json:"role"`
 		Auth string `json:"auth"`
+		// HostKeyFingerprint 控制面记录的主机公钥指纹，Agent 据此校验；为空时 Agent 首次信任
+		HostKeyFingerprint string `json:"host_key_fingerprint,omitempty"`
 	}
 	var masters []nodeAuth
 	for _, n := range nodes {
 		if strings.Contains(n.Role, model.MasterRole) {
-			masters = append(masters, nodeAuth{Name: n.Name, Ip: n.Ip, Role: n.Role, Auth: n.Auth})
+			m := nodeAuth{Name: n.Name, Ip: n.Ip, Role: n.Role, Auth: n.Auth}
+			var auth types.PlanNodeAuth
+			if err := auth.Unmarshal(n.Auth); err == nil {
+				object, err := factory.Plan().HostKey().Get(ctx, n.Ip, auth.SSHPort())
+				if err != nil {
+					klog.Warningf("failed to get ssh host key of %s: %v", n.Ip, err)
+				} else if object != nil {
+					m.HostKeyFingerprint = object.Fingerprint
+				}
+			}
+			masters = append(masters, m)
 		}
 	}
 	b, err := json.Marshal(map[string]interface{}{"masters": masters})
@@ -0,0 +1,265 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/certs"
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/controller/cluster"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	certRenewActionPrefix = "cert-renew"
+	certRenewRotate       = certRenewActionPrefix + "-rotate"
+	certRenewKubeConfig   = certRenewActionPrefix + "-kubeconfig"
+
+	// certRenewLogFile 证书续期日志，位于计划工作目录下
+	certRenewLogFile = "cert-renew.log"
+
+	certRenewTimeout = time.Hour
+)
+
+func (p *plan) GetCertificates(ctx context.Context, planId int64) (*types.PlanCertificates, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+
+	objects, err := p.factory.Plan().Certificate().List(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to list plan(%d) certificates: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	return p.modelCertificates2Type(objects), nil
+}
+
+// RefreshCertificates 立即采集 master 节点证书并返回最新结果
+func (p *plan) RefreshCertificates(ctx context.Context, planId int64) (*types.PlanCertificates, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+	pp, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		return nil, errors.ErrServerInternal
+	}
+	if pp.ExecMode == model.PlanExecModeAgent {
+		return nil, errors.NewError(fmt.Errorf("agent 模式的部署计划暂不支持证书巡检"), http.StatusBadRequest)
+	}
+
+	objects, err := certs.NewChecker(p.cc.Certificate, p.factory).Refresh(ctx, planId)
+	if len(objects) == 0 {
+		if err == nil {
+			err = fmt.Errorf("未采集到证书")
+		}
+		klog.Errorf("failed to refresh plan(%d) certificates: %v", planId, err)
+		return nil, errors.NewError(err, http.StatusInternalServerError)
+	}
+
+	result := p.modelCertificates2Type(objects)
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result, nil
+}
+
+// RenewCertificates 续期全部 master 节点证书，逐台重启控制面并刷新集群 kubeconfig，
+// 执行过程作为部署任务记录
+func (p *plan) RenewCertificates(ctx context.Context, planId int64, req *types.RenewCertificatesRequest) error {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return err
+	}
+	data, err := p.getTaskData(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d) task data: %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	if req.Confirm != data.Plan.Name {
+		return errors.NewError(fmt.Errorf("续期会逐台重启控制面，请将 confirm 填写为部署计划名称 %s", data.Plan.Name), http.StatusBadRequest)
+	}
+	if data.Plan.ExecMode == model.PlanExecModeAgent {
+		return errors.NewError(fmt.Errorf("agent 模式的部署计划暂不支持证书续期"), http.StatusBadRequest)
+	}
+	masters := certs.MasterNodes(data.Nodes)
+	if len(masters) == 0 {
+		return errors.NewError(fmt.Errorf("部署计划没有 master 节点"), http.StatusBadRequest)
+	}
+	// 先抢占计划再打开任务日志，避免覆盖正在执行的任务日志
+	if err = p.claimPlan(ctx, data.Plan); err != nil {
+		return err
+	}
+	log, err := openTaskLog(p.WorkDir(), planId, certRenewLogFile)
+	if err != nil {
+		klog.Errorf("failed to open plan(%d) certificate renew log: %v", planId, err)
+		p.releasePlan(ctx, data.Plan)
+		return errors.ErrServerInternal
+	}
+	rctx, cancel := context.WithTimeout(context.Background(), certRenewTimeout)
+	runner := newNodeRunner(rctx, log)
+	handlers := []Handler{
+		CertRenew{handlerTask: newHandlerTask(data), p: p, runner: runner, masters: masters, name: "续期证书", action: certRenewRotate},
+		CertRenew{handlerTask: newHandlerTask(data), p: p, runner: runner, masters: masters, name: "刷新集群凭证", action: certRenewKubeConfig},
+	}
+	go func() {
+		defer cancel()
+		defer log.Close()
+		if err := p.syncTasks(handlers...); err != nil {
+			klog.Errorf("failed to renew plan(%d) certificates: %v", planId, err)
+		}
+	}()
+	return nil
+}
+
+func (p *plan) modelCertificates2Type(objects []model.PlanCertificate) *types.PlanCertificates {
+	now := time.Now()
+	result := &types.PlanCertificates{
+		Level:        types.CertificateOK,
+		Certificates: make([]types.PlanCertificate, 0, len(objects)),
+	}
+	for _, o := range objects {
+		level := p.cc.Certificate.Evaluate(o.NotAfter, now)
+		result.Level = certs.WorseLevel(result.Level, level)
+		if result.CheckedAt == nil || o.CheckedAt.After(*result.CheckedAt) {
+			checkedAt := o.CheckedAt
+			result.CheckedAt = &checkedAt
+		}
+		result.Certificates = append(result.Certificates, types.PlanCertificate{
+			Node:         o.Node,
+			Name:         o.Name,
+			Kind:         o.Kind,
+			Path:         o.Path,
+			Subject:      o.Subject,
+			NotAfter:     o.NotAfter,
+			ResidualDays: certs.ResidualDays(o.NotAfter, now),
+			Level:        level,
+		})
+	}
+	return result
+}
+
+type CertRenew struct {
+	handlerTask
+
+	p       *plan
+	runner  *nodeRunner
+	masters []model.Node
+	name    string
+	action  string
+}
+
+func (c CertRenew) Name() string      { return c.name }
+func (c CertRenew) GetAction() string { return c.action }
+
+func (c CertRenew) Step() model.PlanStep {
+	if c.action == certRenewKubeConfig {
+		return model.CompletedPlanStep
+	}
+	return model.RunningPlanStep
+}
+
+func (c CertRenew) Run() error {
+	c.runner.logf("==> %s", c.name)
+	var err error
+	switch c.action {
+	case certRenewRotate:
+		err = c.rotate()
+	case certRenewKubeConfig:
+		err = c.refreshKubeConfig()
+	default:
+		err = fmt.Errorf("未知的续期步骤 %s", c.action)
+	}
+	if err != nil {
+		c.runner.logf("%s失败: %v", c.name, err)
+		return err
+	}
+	c.runner.logf("%s完成", c.name)
+	return nil
+}
+
+// rotate 逐台续期并重启控制面，上一台 kube-apiserver 就绪后才处理下一台，任一节点失败即中止
+func (c CertRenew) rotate() error {
+	apiPort := localAPIPort(c.data.Config)
+	for _, node := range c.masters {
+		c.runner.logf("[%s] 续期证书", node.Name)
+		if err := c.runner.run(node, certs.RenewScript, nil, nil); err != nil {
+			return err
+		}
+		c.runner.logf("[%s] 重启控制面", node.Name)
+		if err := c.runner.run(node, certs.RestartControlPlaneScript(apiPort), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshKubeConfig 使用续期后的 admin.conf 更新集群凭证，并重新巡检证书
+func (c CertRenew) refreshKubeConfig() error {
+	var (
+		kubeConfig []byte
+		err        error
+	)
+	for _, node := range c.masters {
+		if kubeConfig, err = getKubeConfigFromMasterNode(node); err == nil && len(kubeConfig) != 0 {
+			break
+		}
+		c.runner.logf("[%s] 读取 %s 失败: %v", node.Name, KubeConfigFile, err)
+	}
+	if len(kubeConfig) == 0 {
+		return fmt.Errorf("未能从 master 节点获取 kubeconfig")
+	}
+	config64 := base64.StdEncoding.EncodeToString(kubeConfig)
+	updates := map[string]interface{}{"kube_config": config64}
+	// 同步凭据过期时间，避免 ClusterSyncer 继续按旧证书判定为过期
+	if info, err := client.ParseCredential(config64); err == nil {
+		updates["credential_type"] = info.Type
+		updates["credential_expires_at"] = info.ExpiresAt
+	} else {
+		c.runner.logf("解析新凭据失败: %v", err)
+	}
+	if err = c.p.factory.Cluster().UpdateByPlan(c.runner.ctx, c.GetPlanId(), updates); err != nil {
+		return fmt.Errorf("更新集群 kubeconfig 失败: %v", err)
+	}
+	c.runner.logf("集群 kubeconfig 已更新")
+
+	// 缓存中的客户端仍使用旧证书，移除后按新 kubeconfig 重建
+	clusters, err := c.p.factory.Cluster().List(c.runner.ctx, db.WithPlan(c.GetPlanId()))
+	if err != nil {
+		c.runner.logf("获取部署计划关联集群失败: %v", err)
+	}
+	for _, object := range clusters {
+		cluster.ClusterIndexer.Delete(object.Name)
+	}
+
+	objects, err := certs.NewChecker(c.p.cc.Certificate, c.p.factory).Refresh(c.runner.ctx, c.GetPlanId())
+	if err != nil {
+		// 续期已完成，巡检失败不影响结果
+		c.runner.logf("重新巡检证书失败: %v", err)
+		return nil
+	}
+	c.runner.logf("已重新巡检 %d 个证书", len(objects))
+	return nil
+}
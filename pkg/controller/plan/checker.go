@@ -16,15 +16,560 @@ limitations under the License.
 
 package plan
 
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+const (
+	checkAction = "prechecks"
+	// checkReportFile 预检查报告，位于计划工作目录下，作为预检查任务的日志
+	checkReportFile = "prechecks.log"
+
+	checkWorkers        = 10
+	checkCommandTimeout = 30 * time.Second
+	reachTimeoutSeconds = 3
+
+	// 资源下限，参考 kubeadm 的最低要求
+	minMasterCPU      = 2
+	minNodeCPU        = 1
+	minMasterMemoryMB = 1700
+	minNodeMemoryMB   = 1000
+	minDiskFreeGB     = 10
+
+	timeSkewWarn  = 30 * time.Second
+	timeSkewBlock = 5 * time.Minute
+)
+
+// osFamilies 将 /etc/os-release 的 ID 映射为 Distribution.Family
+var osFamilies = map[string]string{
+	"centos":    "CentOS",
+	"ubuntu":    "Ubuntu",
+	"debian":    "Debian",
+	"openeuler": "OpenEuler",
+	"rocky":     "RockyLinux",
+	"kylin":     "Kylin",
+}
+
+var (
+	masterPorts     = []int{2379, 2380, 10250, 10257, 10259}
+	nodePorts       = []int{10250}
+	requiredModules = []string{"br_netfilter", "overlay"}
+	ipvsModules     = []string{"ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh"}
+)
+
+type checkLevel string
+
+const (
+	checkPass checkLevel = "PASS"
+	checkWarn checkLevel = "WARN"
+	checkFail checkLevel = "FAIL" // 阻断项
+)
+
+type checkItem struct {
+	Name    string
+	Level   checkLevel
+	Message string
+}
+
+// nodeReport 单个节点的预检查结果
+type nodeReport struct {
+	Name  string
+	Ip    string
+	Roles []string
+	Items []checkItem
+	// deployed 节点已参与过本计划的部署，重跑时其端口可能由上次部署的组件占用
+	deployed bool
+
+	// 远端采集的信息
+	facts     map[string]string
+	sshReq    *types.WebSSHRequest
+	sshPort   int
+	skewTime  time.Duration
+	skewKnown bool
+}
+
+func (r *nodeReport) add(name string, level checkLevel, format string, args ...interface{}) {
+	r.Items = append(r.Items, checkItem{Name: name, Level: level, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *nodeReport) isMaster() bool {
+	for _, role := range r.Roles {
+		if role == model.MasterRole {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 部署预检查：通过 SSH 登录每个节点检查操作系统、资源、端口、内核模块、时间偏差与节点互通，
+// 输出逐节点报告，存在阻断项时终止部署。
 type Check struct {
 	handlerTask
+
+	factory db.ShareDaoFactory
+	dir     string
+	// remote 为 false 时仅做静态检查（agent 模式下节点仅对边缘 Agent 可达）
+	remote bool
 }
 
 func (c Check) Name() string      { return "部署预检查" }
-func (c Check) GetAction() string { return "prechecks" }
+func (c Check) GetAction() string { return checkAction }
 func (c Check) Run() error {
 	if err := c.data.validate(); err != nil {
 		return err
 	}
+
+	reports := c.newReports()
+	var global []checkItem
+	global = append(global, c.checkDuplicateIPs(reports)...)
+
+	if c.remote {
+		c.collect(reports)
+		c.checkOS(reports)
+		for _, r := range reports {
+			c.checkResources(r)
+		}
+		global = append(global, c.checkDuplicateHostnames(reports)...)
+		c.checkReachability(reports)
+	} else {
+		global = append(global, checkItem{Name: "远程检查", Level: checkWarn, Message: "agent 模式由边缘 Agent 访问节点，跳过 SSH 检查"})
+	}
+
+	report, blocking := renderCheckReport(c.GetPlanId(), reports, global)
+	if err := c.writeReport(report); err != nil {
+		klog.Warningf("failed to write plan(%d) precheck report: %v", c.GetPlanId(), err)
+	}
+	klog.Infof("plan(%d) precheck report:\n%s", c.GetPlanId(), report)
+
+	if len(blocking) != 0 {
+		return fmt.Errorf("预检查未通过(%d 项): %s", len(blocking), strings.Join(blocking, "; "))
+	}
 	return nil
 }
+
+func (c Check) newReports() []*nodeReport {
+	deployedAt := c.lastDeployedAt()
+	reports := make([]*nodeReport, 0, len(c.data.Nodes))
+	for _, node := range c.data.Nodes {
+		reports = append(reports, &nodeReport{
+			Name:     node.Name,
+			Ip:       node.Ip,
+			Roles:    strings.Split(node.Role, ","),
+			deployed: !deployedAt.IsZero() && node.GmtCreate.Before(deployedAt),
+		})
+	}
+	return reports
+}
+
+// lastDeployedAt 返回本计划上一次开始部署的时间，从未部署过时返回零值
+func (c Check) lastDeployedAt() time.Time {
+	task, err := c.factory.Plan().GetTaskByName(context.TODO(), c.GetPlanId(), BootStrap{}.Name())
+	if err != nil {
+		if !errors.IsRecordNotFound(err) {
+			klog.Warningf("failed to get plan(%d) task(%s): %v", c.GetPlanId(), BootStrap{}.Name(), err)
+		}
+		return time.Time{}
+	}
+	if task.Status == model.UnStartPlanStatus {
+		return time.Time{}
+	}
+	return task.GmtCreate
+}
+
+func (c Check) checkDuplicateIPs(reports []*nodeReport) []checkItem {
+	seen := make(map[string][]string)
+	for _, r := range reports {
+		seen[r.Ip] = append(seen[r.Ip], r.Name)
+	}
+	var items []checkItem
+	for ip, names := range seen {
+		if len(names) > 1 {
+			items = append(items, checkItem{Name: "IP 重复", Level: checkFail, Message: fmt.Sprintf("%s 被节点 %s 重复使用", ip, strings.Join(names, ", "))})
+		}
+	}
+	if len(items) == 0 {
+		items = append(items, checkItem{Name: "IP 重复", Level: checkPass, Message: "节点 IP 均唯一"})
+	}
+	return items
+}
+
+func (c Check) checkDuplicateHostnames(reports []*nodeReport) []checkItem {
+	// 开启自动修改主机名时部署会以节点名称作为主机名
+	var ks types.KubernetesSpec
+	_ = ks.Unmarshal(c.data.Config.Kubernetes)
+	if ks.SetHostname {
+		return []checkItem{{Name: "主机名重复", Level: checkPass, Message: "已开启自动修改主机名"}}
+	}
+
+	seen := make(map[string][]string)
+	for _, r := range reports {
+		if hostname := r.facts["hostname"]; hostname != "" {
+			seen[hostname] = append(seen[hostname], r.Name)
+		}
+	}
+	var items []checkItem
+	for hostname, names := range seen {
+		if len(names) > 1 {
+			items = append(items, checkItem{Name: "主机名重复", Level: checkFail, Message: fmt.Sprintf("节点 %s 的主机名均为 %s", strings.Join(names, ", "), hostname)})
+		}
+	}
+	if len(items) == 0 {
+		items = append(items, checkItem{Name: "主机名重复", Level: checkPass, Message: "节点主机名均唯一"})
+	}
+	return items
+}
+
+// collect 并发登录各节点采集信息
+func (c Check) collect(reports []*nodeReport) {
+	authByName := make(map[string]string, len(c.data.Nodes))
+	for _, node := range c.data.Nodes {
+		authByName[node.Name] = node.Auth
+	}
+	script := c.factsScript()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, checkWorkers)
+	for _, r := range reports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *nodeReport) {
+			defer func() { <-sem; wg.Done() }()
+
+			var auth types.PlanNodeAuth
+			if err := auth.Unmarshal(authByName[r.Name]); err != nil {
+				r.add("SSH 连接", checkFail, "解析节点认证信息失败: %v", err)
+				return
+			}
+			req, err := sshutil.ResolveAuth(&auth)
+			if err != nil {
+				r.add("SSH 连接", checkFail, "%v", err)
+				return
+			}
+			req.Host = r.Ip
+			r.sshReq = req
+			r.sshPort = auth.SSHPort()
+
+			start := time.Now()
+			out, err := runRemote(req, script)
+			if err != nil {
+				r.add("SSH 连接", checkFail, "%v", err)
+				return
+			}
+			end := time.Now()
+			r.facts = parseFacts(out)
+			r.add("SSH 连接", checkPass, "%s@%s:%d", req.User, r.Ip, req.Port)
+
+			if epoch, err := strconv.ParseInt(r.facts["time"], 10, 64); err == nil {
+				local := start.Add(end.Sub(start) / 2)
+				r.skewTime = time.Unix(epoch, 0).Sub(local)
+				r.skewKnown = true
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// factsScript 节点信息采集脚本，每行输出 key=value
+func (c Check) factsScript() string {
+	modules := append([]string{}, requiredModules...)
+	var ns types.NetworkSpec
+	_ = ns.Unmarshal(c.data.Config.Network)
+	if ns.KubeProxy == "ipvs" {
+		modules = append(modules, ipvsModules...)
+	}
+
+	return strings.Join([]string{
+		`. /etc/os-release 2>/dev/null; echo "os_id=$ID"; echo "os_version=$VERSION_ID"; echo "os_name=$PRETTY_NAME"`,
+		`echo "hostname=$(hostname)"`,
+		`echo "cpu=$(nproc)"`,
+		`echo "mem_kb=$(awk '/^MemTotal:/{print $2}' /proc/meminfo)"`,
+		`echo "swap_kb=$(awk '/^SwapTotal:/{print $2}' /proc/meminfo)"`,
+		`echo "disk_kb=$(df -Pk / | awk 'NR==2{print $4}')"`,
+		`echo "time=$(date +%s)"`,
+		`echo "listen=$( (ss -ltn 2>/dev/null || netstat -ltn 2>/dev/null) | awk '{print $4}' | awk -F: '{print $NF}' | grep -E '^[0-9]+$' | sort -u | tr '\n' ',')"`,
+		`missing=""; for m in ` + strings.Join(modules, " ") + `; do [ -d /sys/module/$m ] || modinfo $m >/dev/null 2>&1 || /sbin/modinfo $m >/dev/null 2>&1 || missing="$missing$m,"; done; echo "modules_missing=$missing"`,
+	}, "\n")
+}
+
+func (c Check) checkOS(reports []*nodeReport) {
+	var family string
+	dist, err := c.factory.Distribution().GetDistributionByName(context.TODO(), c.data.Config.OSImage)
+	if err != nil || dist == nil {
+		klog.Warningf("failed to get distribution %s: %v", c.data.Config.OSImage, err)
+	} else {
+		family = dist.Family
+	}
+
+	for _, r := range reports {
+		if r.facts == nil {
+			continue
+		}
+		id, version := strings.ToLower(r.facts["os_id"]), r.facts["os_version"]
+		actual := osFamilies[id]
+		switch {
+		case family == "":
+			r.add("操作系统", checkWarn, "%s，未找到部署系统 %s 的定义，跳过校验", r.facts["os_name"], c.data.Config.OSImage)
+		case actual == "":
+			r.add("操作系统", checkFail, "%s 不在支持的发行版中", r.facts["os_name"])
+		case !strings.EqualFold(actual, family):
+			r.add("操作系统", checkFail, "%s 与部署系统 %s(%s) 不一致", r.facts["os_name"], c.data.Config.OSImage, family)
+		case version != "" && !strings.Contains(strings.ToLower(c.data.Config.OSImage), strings.ToLower(version)):
+			r.add("操作系统", checkWarn, "%s 版本与部署系统 %s 不一致", r.facts["os_name"], c.data.Config.OSImage)
+		default:
+			r.add("操作系统", checkPass, "%s", r.facts["os_name"])
+		}
+	}
+}
+
+func (c Check) checkResources(r *nodeReport) {
+	if r.facts == nil {
+		return
+	}
+
+	minCPU, minMem, ports := minNodeCPU, minNodeMemoryMB, nodePorts
+	if r.isMaster() {
+		minCPU, minMem = minMasterCPU, minMasterMemoryMB
+		ports = append([]int{c.apiPort()}, masterPorts...)
+	}
+
+	if cpu, err := strconv.Atoi(r.facts["cpu"]); err != nil {
+		r.add("CPU", checkWarn, "无法获取 CPU 核数")
+	} else if cpu < minCPU {
+		r.add("CPU", checkFail, "%d 核，至少需要 %d 核", cpu, minCPU)
+	} else {
+		r.add("CPU", checkPass, "%d 核", cpu)
+	}
+
+	if memKB, err := strconv.ParseInt(r.facts["mem_kb"], 10, 64); err != nil {
+		r.add("内存", checkWarn, "无法获取内存大小")
+	} else if memMB := memKB / 1024; memMB < int64(minMem) {
+		r.add("内存", checkFail, "%d MiB，至少需要 %d MiB", memMB, minMem)
+	} else {
+		r.add("内存", checkPass, "%d MiB", memMB)
+	}
+
+	if diskKB, err := strconv.ParseInt(r.facts["disk_kb"], 10, 64); err != nil {
+		r.add("磁盘", checkWarn, "无法获取根分区可用空间")
+	} else if diskGB := diskKB / 1024 / 1024; diskGB < minDiskFreeGB {
+		r.add("磁盘", checkFail, "根分区可用 %d GiB，至少需要 %d GiB", diskGB, minDiskFreeGB)
+	} else {
+		r.add("磁盘", checkPass, "根分区可用 %d GiB", diskGB)
+	}
+
+	if swapKB, _ := strconv.ParseInt(r.facts["swap_kb"], 10, 64); swapKB > 0 {
+		r.add("Swap", checkWarn, "已开启 %d MiB，部署时将被关闭", swapKB/1024)
+	} else {
+		r.add("Swap", checkPass, "未开启")
+	}
+
+	listening := make(map[int]bool)
+	for _, p := range strings.Split(r.facts["listen"], ",") {
+		if port, err := strconv.Atoi(p); err == nil {
+			listening[port] = true
+		}
+	}
+	var occupied []string
+	for _, port := range ports {
+		if listening[port] {
+			occupied = append(occupied, strconv.Itoa(port))
+		}
+	}
+	switch {
+	case len(occupied) != 0 && r.deployed:
+		r.add("端口", checkWarn, "端口 %s 已被占用，节点已参与过本计划的部署，按重跑处理", strings.Join(occupied, ", "))
+	case len(occupied) != 0:
+		r.add("端口", checkFail, "端口 %s 已被占用", strings.Join(occupied, ", "))
+	default:
+		r.add("端口", checkPass, "端口 %s 可用", joinInts(ports))
+	}
+
+	if missing := strings.Trim(r.facts["modules_missing"], ","); missing != "" {
+		r.add("内核模块", checkFail, "缺少内核模块 %s", missing)
+	} else {
+		r.add("内核模块", checkPass, "所需内核模块可用")
+	}
+
+	skew := r.skewTime
+	if skew < 0 {
+		skew = -skew
+	}
+	switch {
+	case !r.skewKnown:
+		r.add("时间偏差", checkWarn, "无法获取节点时间，偏差未知")
+	case skew >= timeSkewBlock:
+		r.add("时间偏差", checkFail, "与控制面相差 %s，超过 %s", skew.Round(time.Second), timeSkewBlock)
+	case skew >= timeSkewWarn:
+		r.add("时间偏差", checkWarn, "与控制面相差 %s，建议配置时间同步", skew.Round(time.Second))
+	default:
+		r.add("时间偏差", checkPass, "与控制面相差 %s", skew.Round(time.Second))
+	}
+}
+
+// checkReachability 从每个 master 检查到其他节点 SSH 端口的连通性
+func (c Check) checkReachability(reports []*nodeReport) {
+	var wg sync.WaitGroup
+	for _, r := range reports {
+		if !r.isMaster() || r.facts == nil {
+			continue
+		}
+
+		var targets []string
+		for _, other := range reports {
+			if other == r || other.sshPort == 0 {
+				continue
+			}
+			targets = append(targets, fmt.Sprintf("%s:%d", other.Ip, other.sshPort))
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *nodeReport, targets []string) {
+			defer wg.Done()
+
+			script := fmt.Sprintf(`failed=""; for t in %s; do ip=${t%%:*}; port=${t##*:}; `+
+				`if command -v nc >/dev/null 2>&1; then nc -z -w %d $ip $port >/dev/null 2>&1; else timeout %d bash -c "</dev/tcp/$ip/$port" >/dev/null 2>&1; fi || failed="$failed$t,"; `+
+				`done; echo "unreachable=$failed"`, strings.Join(targets, " "), reachTimeoutSeconds, reachTimeoutSeconds)
+			out, err := runRemote(r.sshReq, script)
+			if err != nil {
+				r.add("节点互通", checkWarn, "检查失败: %v", err)
+				return
+			}
+			if unreachable := strings.Trim(parseFacts(out)["unreachable"], ","); unreachable != "" {
+				r.add("节点互通", checkFail, "无法访问 %s", unreachable)
+				return
+			}
+			r.add("节点互通", checkPass, "可访问其他 %d 个节点", len(targets))
+		}(r, targets)
+	}
+	wg.Wait()
+}
+
+func (c Check) apiPort() int {
+	var ks types.KubernetesSpec
+	_ = ks.Unmarshal(c.data.Config.Kubernetes)
+	if port, err := strconv.Atoi(ks.ApiPort); err == nil && port > 0 {
+		return port
+	}
+	return 6443
+}
+
+func (c Check) writeReport(report string) error {
+	dir := filepath.Join(c.dir, fmt.Sprintf("%d", c.GetPlanId()))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, checkReportFile), []byte(report), 0o644)
+}
+
+// renderCheckReport 生成逐节点的预检查报告，并返回阻断项
+func renderCheckReport(planId int64, reports []*nodeReport, global []checkItem) (string, []string) {
+	var b strings.Builder
+	var blocking []string
+	warnings := 0
+
+	writeItem := func(prefix string, item checkItem) {
+		fmt.Fprintf(&b, "  [%s] %s: %s\n", item.Level, item.Name, item.Message)
+		switch item.Level {
+		case checkFail:
+			blocking = append(blocking, prefix+item.Name+": "+item.Message)
+		case checkWarn:
+			warnings++
+		}
+	}
+
+	fmt.Fprintf(&b, "部署预检查 plan(%d)，共 %d 个节点，检查时间 %s\n", planId, len(reports), time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "== 全局 ==\n")
+	for _, item := range global {
+		writeItem("", item)
+	}
+
+	sorted := append([]*nodeReport{}, reports...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, r := range sorted {
+		fmt.Fprintf(&b, "== 节点 %s (%s) [%s] ==\n", r.Name, r.Ip, strings.Join(r.Roles, ","))
+		for _, item := range r.Items {
+			writeItem(r.Name+" ", item)
+		}
+	}
+
+	fmt.Fprintf(&b, "== 汇总 ==\n  阻断项 %d，警告 %d\n", len(blocking), warnings)
+	return b.String(), blocking
+}
+
+func runRemote(req *types.WebSSHRequest, script string) (string, error) {
+	client, err := sshutil.NewSSHClient(req)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	return runWithTimeout(client, script, checkCommandTimeout)
+}
+
+func runWithTimeout(client *ssh.Client, script string, timeout time.Duration) (string, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	type result struct {
+		out []byte
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		out, err := session.CombinedOutput(script)
+		ch <- result{out, err}
+	}()
+
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			return "", fmt.Errorf("%v: %s", res.err, strings.TrimSpace(string(res.out)))
+		}
+		return string(res.out), nil
+	case <-time.After(timeout):
+		_ = client.Close()
+		return "", fmt.Errorf("执行超时(%s)", timeout)
+	}
+}
+
+func parseFacts(out string) map[string]string {
+	facts := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if k, v, ok := strings.Cut(scanner.Text(), "="); ok {
+			facts[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return facts
+}
+
+func joinInts(values []int) string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, strconv.Itoa(v))
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// cannedFacts 为 factsScript 在一台 4C8G 节点上的输出
+const cannedFacts = `os_id=ubuntu
+os_version="22.04"
+os_name=Ubuntu 22.04.4 LTS
+hostname=node-1
+cpu=4
+mem_kb=8009420
+swap_kb=0
+disk_kb=41152736
+time=1780000000
+listen=22,53,
+modules_missing=
+garbage line without separator
+`
+
+func TestParseFacts(t *testing.T) {
+	facts := parseFacts(cannedFacts + " key = \"a=b\" \r\n")
+
+	expected := map[string]string{
+		"os_id":           "ubuntu",
+		"os_version":      "22.04",
+		"os_name":         "Ubuntu 22.04.4 LTS",
+		"hostname":        "node-1",
+		"cpu":             "4",
+		"mem_kb":          "8009420",
+		"disk_kb":         "41152736",
+		"listen":          "22,53,",
+		"modules_missing": "",
+		"key":             "a=b",
+	}
+	for k, v := range expected {
+		if got, ok := facts[k]; !ok || got != v {
+			t.Errorf("facts[%s] = %q, want %q", k, got, v)
+		}
+	}
+	if len(parseFacts("")) != 0 {
+		t.Errorf("expected no facts from empty output")
+	}
+}
+
+func withFacts(overrides map[string]string) map[string]string {
+	facts := parseFacts(cannedFacts)
+	for k, v := range overrides {
+		facts[k] = v
+	}
+	return facts
+}
+
+func levelsOf(r *nodeReport) map[string]checkLevel {
+	levels := make(map[string]checkLevel, len(r.Items))
+	for _, item := range r.Items {
+		levels[item.Name] = item.Level
+	}
+	return levels
+}
+
+func TestCheckResources(t *testing.T) {
+	check := Check{handlerTask: handlerTask{data: TaskData{Config: &model.Config{Kubernetes: `{"api_port":"8443"}`}}}}
+
+	tests := []struct {
+		name     string
+		role     string
+		facts    map[string]string
+		skew     time.Duration
+		deployed bool
+		want     map[string]checkLevel
+		detail   string
+	}{
+		{
+			name:  "healthy master",
+			role:  model.MasterRole,
+			facts: withFacts(nil),
+			want:  map[string]checkLevel{"CPU": checkPass, "内存": checkPass, "磁盘": checkPass, "Swap": checkPass, "端口": checkPass, "内核模块": checkPass, "时间偏差": checkPass},
+		},
+		{
+			name:   "small master",
+			role:   model.MasterRole + ",node",
+			facts:  withFacts(map[string]string{"cpu": "1", "mem_kb": "1048576", "disk_kb": "5242880"}),
+			want:   map[string]checkLevel{"CPU": checkFail, "内存": checkFail, "磁盘": checkFail},
+			detail: "至少需要 2 核",
+		},
+		{
+			name:  "small node is enough",
+			role:  "node",
+			facts: withFacts(map[string]string{"cpu": "1", "mem_kb": "1048576"}),
+			want:  map[string]checkLevel{"CPU": checkPass, "内存": checkPass},
+		},
+		{
+			name:   "api port occupied on master",
+			role:   model.MasterRole,
+			facts:  withFacts(map[string]string{"listen": "22,8443,2379,"}),
+			want:   map[string]checkLevel{"端口": checkFail},
+			detail: "端口 8443, 2379 已被占用",
+		},
+		{
+			name:     "ports occupied by previous deployment",
+			role:     model.MasterRole,
+			facts:    withFacts(map[string]string{"listen": "22,8443,2379,10250,"}),
+			deployed: true,
+			want:     map[string]checkLevel{"端口": checkWarn},
+			detail:   "按重跑处理",
+		},
+		{
+			name:  "master ports are free on node",
+			role:  "node",
+			facts: withFacts(map[string]string{"listen": "22,8443,2379,"}),
+			want:  map[string]checkLevel{"端口": checkPass},
+		},
+		{
+			name:   "swap and missing modules",
+			role:   "node",
+			facts:  withFacts(map[string]string{"swap_kb": "2097152", "modules_missing": "br_netfilter,overlay,"}),
+			want:   map[string]checkLevel{"Swap": checkWarn, "内核模块": checkFail},
+			detail: "缺少内核模块 br_netfilter,overlay",
+		},
+		{
+			name:  "unknown facts",
+			role:  "node",
+			facts: map[string]string{},
+			want:  map[string]checkLevel{"CPU": checkWarn, "内存": checkWarn, "磁盘": checkWarn, "Swap": checkPass, "时间偏差": checkWarn},
+		},
+		{name: "time skew warning", role: "node", facts: withFacts(nil), skew: -time.Minute, want: map[string]checkLevel{"时间偏差": checkWarn}},
+		{name: "time skew blocks", role: "node", facts: withFacts(nil), skew: 10 * time.Minute, want: map[string]checkLevel{"时间偏差": checkFail}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &nodeReport{
+				Name: "node-1", Roles: strings.Split(tt.role, ","), deployed: tt.deployed,
+				facts: tt.facts, skewTime: tt.skew, skewKnown: tt.facts["time"] != "",
+			}
+			check.checkResources(r)
+
+			levels := levelsOf(r)
+			for name, level := range tt.want {
+				if levels[name] != level {
+					t.Errorf("%s: expected %s, got %s (%+v)", name, level, levels[name], r.Items)
+				}
+			}
+			if tt.detail != "" {
+				report, _ := renderCheckReport(1, []*nodeReport{r}, nil)
+				if !strings.Contains(report, tt.detail) {
+					t.Errorf("report missing %q:\n%s", tt.detail, report)
+				}
+			}
+		})
+	}
+
+	// 采集失败的节点不做资源检查
+	r := &nodeReport{Name: "node-1"}
+	check.checkResources(r)
+	if len(r.Items) != 0 {
+		t.Errorf("expected no items without facts, got %+v", r.Items)
+	}
+}
+
+func TestNewReportsMarksDeployedNodes(t *testing.T) {
+	deployedAt := time.Now().Add(-time.Hour)
+	nodes := []model.Node{{Name: "old", Role: model.MasterRole}, {Name: "new", Role: "node"}}
+	nodes[0].GmtCreate = deployedAt.Add(-time.Hour)
+	nodes[1].GmtCreate = deployedAt.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		tasks []model.Task
+		want  map[string]bool
+	}{
+		{name: "first run", want: map[string]bool{"old": false, "new": false}},
+		{
+			name:  "bootstrap never started",
+			tasks: []model.Task{{Name: BootStrap{}.Name(), Status: model.UnStartPlanStatus}},
+			want:  map[string]bool{"old": false, "new": false},
+		},
+		{
+			name:  "re-run after deployment",
+			tasks: []model.Task{{Name: BootStrap{}.Name(), Status: model.FailedPlanStatus}},
+			want:  map[string]bool{"old": true, "new": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.tasks {
+				tt.tasks[i].GmtCreate = deployedAt
+			}
+			check := Check{
+				handlerTask: handlerTask{data: TaskData{Nodes: nodes}},
+				factory:     &fakeFactory{plans: &fakePlans{tasks: tt.tasks}},
+			}
+			for _, r := range check.newReports() {
+				if r.deployed != tt.want[r.Name] {
+					t.Errorf("%s: expected deployed %v, got %v", r.Name, tt.want[r.Name], r.deployed)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderCheckReport(t *testing.T) {
+	reports := []*nodeReport{
+		{Name: "node-2", Ip: "10.0.0.2", Roles: []string{"node"}, Items: []checkItem{
+			{Name: "CPU", Level: checkFail, Message: "1 核，至少需要 2 核"},
+			{Name: "Swap", Level: checkWarn, Message: "已开启 2048 MiB，部署时将被关闭"},
+		}},
+		{Name: "node-1", Ip: "10.0.0.1", Roles: []string{"master", "node"}, Items: []checkItem{
+			{Name: "CPU", Level: checkPass, Message: "4 核"},
+		}},
+	}
+	global := []checkItem{
+		{Name: "IP 重复", Level: checkPass, Message: "节点 IP 均唯一"},
+		{Name: "远程检查", Level: checkWarn, Message: "跳过 SSH 检查"},
+	}
+
+	report, blocking := renderCheckReport(42, reports, global)
+	if len(blocking) != 1 || blocking[0] != "node-2 CPU: 1 核，至少需要 2 核" {
+		t.Errorf("unexpected blocking items %v", blocking)
+	}
+	for _, want := range []string{
+		"部署预检查 plan(42)，共 2 个节点",
+		"== 全局 ==\n  [PASS] IP 重复: 节点 IP 均唯一\n  [WARN] 远程检查: 跳过 SSH 检查\n",
+		"== 节点 node-1 (10.0.0.1) [master,node] ==\n  [PASS] CPU: 4 核\n",
+		"== 汇总 ==\n  阻断项 1，警告 2\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	// 节点按名称排序输出
+	if strings.Index(report, "node-1 (") > strings.Index(report, "node-2 (") {
+		t.Errorf("nodes are not sorted:\n%s", report)
+	}
+
+	if _, blocking = renderCheckReport(42, nil, []checkItem{{Name: "IP 重复", Level: checkFail, Message: "重复"}}); len(blocking) != 1 || blocking[0] != "IP 重复: 重复" {
+		t.Errorf("unexpected global blocking items %v", blocking)
+	}
+}
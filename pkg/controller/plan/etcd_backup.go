@@ -0,0 +1,390 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/etcdbackup"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+// etcd 恢复涉及全部 master 的停机与数据替换，整体限时
+const etcdRestoreTimeout = time.Hour
+
+func (p *plan) GetEtcdBackupPolicy(ctx context.Context, planId int64) (*types.EtcdBackupPolicy, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+
+	object, err := p.factory.Plan().EtcdBackup().GetPolicy(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d) etcd backup policy: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	// 未配置时返回默认策略（未启用）
+	if object == nil {
+		pp, err := p.factory.Plan().Get(ctx, planId)
+		if err != nil {
+			return nil, errors.ErrServerInternal
+		}
+		return &types.EtcdBackupPolicy{
+			PlanId:    planId,
+			Schedule:  "0 2 * * *",
+			Retention: etcdbackup.DefaultRetention,
+			Method:    defaultBackupMethod(pp),
+		}, nil
+	}
+	return modelEtcdPolicy2Type(object), nil
+}
+
+func (p *plan) UpdateEtcdBackupPolicy(ctx context.Context, planId int64, req *types.UpdateEtcdBackupPolicyRequest) error {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return err
+	}
+	if _, err := etcdbackup.ParseSchedule(req.Schedule); err != nil {
+		return errors.NewError(fmt.Errorf("无效的 cron 表达式: %v", err), http.StatusBadRequest)
+	}
+	pp, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		return errors.ErrServerInternal
+	}
+	if req.Retention == 0 {
+		req.Retention = etcdbackup.DefaultRetention
+	}
+	if req.Method == "" {
+		req.Method = defaultBackupMethod(pp)
+	}
+
+	object, err := p.factory.Plan().EtcdBackup().GetPolicy(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d) etcd backup policy: %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	// 以修改时间作为下一次调度的起点，避免修改后立即触发补偿备份
+	now := time.Now()
+	if object == nil {
+		if _, err = p.factory.Plan().EtcdBackup().CreatePolicy(ctx, &model.EtcdBackupPolicy{
+			PlanId:           planId,
+			Enabled:          req.Enabled,
+			Schedule:         req.Schedule,
+			Retention:        req.Retention,
+			Method:           req.Method,
+			LastScheduleTime: &now,
+		}); err != nil {
+			klog.Errorf("failed to create plan(%d) etcd backup policy: %v", planId, err)
+			return errors.ErrServerInternal
+		}
+		return nil
+	}
+
+	if err = p.factory.Plan().EtcdBackup().UpdatePolicy(ctx, object.Id, object.ResourceVersion, map[string]interface{}{
+		"enabled":            req.Enabled,
+		"schedule":           req.Schedule,
+		"retention":          req.Retention,
+		"method":             req.Method,
+		"last_schedule_time": now,
+	}); err != nil {
+		klog.Errorf("failed to update plan(%d) etcd backup policy: %v", planId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+// CreateEtcdSnapshot 立即触发一次备份，采集在后台进行，可通过快照列表查看结果
+func (p *plan) CreateEtcdSnapshot(ctx context.Context, planId int64, req *types.CreateEtcdSnapshotRequest) (*types.EtcdSnapshot, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+	pp, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		return nil, errors.ErrServerInternal
+	}
+	method := req.Method
+	if method == "" {
+		method = defaultBackupMethod(pp)
+	}
+
+	m, err := etcdbackup.NewManager(p.cc.EtcdBackup, p.factory)
+	if err != nil {
+		klog.Errorf("failed to create etcd backup manager: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+	object, err := m.Begin(ctx, planId, method, model.EtcdSnapshotManual)
+	if err != nil {
+		if err == etcdbackup.ErrBackupRunning {
+			return nil, errors.NewError(err, http.StatusConflict)
+		}
+		klog.Errorf("failed to create plan(%d) etcd snapshot: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	go func() {
+		if err := m.Run(context.Background(), object); err != nil {
+			klog.Errorf("failed to take plan(%d) etcd snapshot: %v", planId, err)
+		}
+	}()
+
+	return modelEtcdSnapshot2Type(object), nil
+}
+
+func (p *plan) ListEtcdSnapshots(ctx context.Context, planId int64, listOption types.ListOptions) (interface{}, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+	listOption.SetDefaultPageOption()
+
+	pageResult := types.PageResult{
+		PageRequest: types.PageRequest{
+			Page:  listOption.Page,
+			Limit: listOption.Limit,
+		},
+	}
+	opts := []db.Options{
+		db.WithPlan(planId),
+		db.WithEtcdSnapshot(listOption.SnapshotStatus, listOption.Trigger),
+	}
+
+	var err error
+	pageResult.Total, err = p.factory.Plan().EtcdBackup().CountSnapshots(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to count plan(%d) etcd snapshots: %v", planId, err)
+		pageResult.Message = err.Error()
+	}
+
+	offset := (listOption.Page - 1) * listOption.Limit
+	opts = append(opts, []db.Options{
+		db.WithOrderByDesc(),
+		db.WithOffset(offset),
+		db.WithLimit(listOption.Limit),
+	}...)
+	objects, err := p.factory.Plan().EtcdBackup().ListSnapshots(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to list plan(%d) etcd snapshots: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+
+	items := make([]types.EtcdSnapshot, 0, len(objects))
+	for i := range objects {
+		items = append(items, *modelEtcdSnapshot2Type(&objects[i]))
+	}
+	pageResult.Items = items
+
+	return pageResult, nil
+}
+
+func (p *plan) DeleteEtcdSnapshot(ctx context.Context, planId int64, snapshotId int64) error {
+	object, err := p.getEtcdSnapshot(ctx, planId, snapshotId)
+	if err != nil {
+		return err
+	}
+	if object.Status == model.EtcdSnapshotRunning {
+		return errors.NewError(fmt.Errorf("快照正在采集中，无法删除"), http.StatusConflict)
+	}
+
+	m, err := etcdbackup.NewManager(p.cc.EtcdBackup, p.factory)
+	if err != nil {
+		klog.Errorf("failed to create etcd backup manager: %v", err)
+		return errors.ErrServerInternal
+	}
+	if err = m.Delete(ctx, object); err != nil {
+		klog.Errorf("failed to delete plan(%d) etcd snapshot(%d): %v", planId, snapshotId, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+func (p *plan) DownloadEtcdSnapshot(ctx context.Context, planId int64, snapshotId int64, w http.ResponseWriter) error {
+	object, err := p.getEtcdSnapshot(ctx, planId, snapshotId)
+	if err != nil {
+		return err
+	}
+	if object.Status != model.EtcdSnapshotSucceeded {
+		return errors.NewError(fmt.Errorf("快照未成功采集，无法下载"), http.StatusBadRequest)
+	}
+
+	storage, err := p.cc.EtcdBackup.NewStorage()
+	if err != nil {
+		klog.Errorf("failed to create etcd backup storage: %v", err)
+		return errors.ErrServerInternal
+	}
+	rc, err := storage.Get(ctx, object.StorageKey)
+	if err != nil {
+		if err == etcdbackup.ErrSnapshotNotFound {
+			return errors.NewError(err, http.StatusNotFound)
+		}
+		klog.Errorf("failed to get plan(%d) etcd snapshot(%d) from storage: %v", planId, snapshotId, err)
+		return errors.ErrServerInternal
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filepath.Base(object.StorageKey)))
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.Header().Set("X-Checksum-Sha256", object.Checksum)
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// RestoreEtcd 从快照恢复 etcd。恢复会停止全部 master 的控制面，dry_run 时仅返回恢复步骤，
+// 正式执行需将 confirm 填写为部署计划名称，执行过程作为部署任务记录
+func (p *plan) RestoreEtcd(ctx context.Context, planId int64, req *types.RestoreEtcdRequest) (*types.EtcdRestorePlan, error) {
+	snapshot, err := p.getEtcdSnapshot(ctx, planId, req.SnapshotId)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Status != model.EtcdSnapshotSucceeded {
+		return nil, errors.NewError(fmt.Errorf("只能从成功的快照恢复"), http.StatusBadRequest)
+	}
+
+	data, err := p.getTaskData(ctx, planId)
+	if err != nil {
+		klog.Errorf("failed to get plan(%d) task data: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	masters := etcdbackup.MasterNodes(data.Nodes)
+	if len(masters) == 0 {
+		return nil, errors.NewError(fmt.Errorf("部署计划没有 master 节点"), http.StatusBadRequest)
+	}
+
+	result := &types.EtcdRestorePlan{
+		Snapshot: *modelEtcdSnapshot2Type(snapshot),
+		Steps:    etcdRestoreSteps(),
+	}
+	for _, node := range masters {
+		result.Nodes = append(result.Nodes, node.Name)
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	if req.Confirm != data.Plan.Name {
+		return nil, errors.NewError(fmt.Errorf("恢复会停止集群控制面，请将 confirm 填写为部署计划名称 %s", data.Plan.Name), http.StatusBadRequest)
+	}
+	if data.Plan.ExecMode == model.PlanExecModeAgent {
+		return nil, errors.NewError(fmt.Errorf("agent 模式的部署计划暂不支持 etcd 恢复"), http.StatusBadRequest)
+	}
+	storage, err := p.cc.EtcdBackup.NewStorage()
+	if err != nil {
+		klog.Errorf("failed to create etcd backup storage: %v", err)
+		return nil, errors.ErrServerInternal
+	}
+	// 先抢占计划再打开任务日志，避免覆盖正在执行的任务日志
+	if err = p.claimPlan(ctx, data.Plan); err != nil {
+		return nil, err
+	}
+	log, err := openTaskLog(p.WorkDir(), planId, etcdRestoreLogFile)
+	if err != nil {
+		klog.Errorf("failed to open plan(%d) etcd restore log: %v", planId, err)
+		p.releasePlan(ctx, data.Plan)
+		return nil, errors.ErrServerInternal
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), etcdRestoreTimeout)
+	rc := &etcdRestoreContext{
+		nodeRunner: newNodeRunner(rctx, log),
+		storage:    storage,
+		snapshot:   snapshot,
+		masters:    masters,
+		members:    make(map[string]etcdMember),
+		apiPort:    localAPIPort(data.Config),
+	}
+	go func() {
+		defer cancel()
+		defer log.Close()
+		if err := p.syncTasks(newEtcdRestoreHandlers(data, rc)...); err != nil {
+			klog.Errorf("failed to restore plan(%d) etcd: %v", planId, err)
+		}
+	}()
+
+	result.Started = true
+	return result, nil
+}
+
+func (p *plan) getEtcdSnapshot(ctx context.Context, planId int64, snapshotId int64) (*model.EtcdSnapshot, error) {
+	if err := p.checkPlanAccess(ctx, planId); err != nil {
+		return nil, err
+	}
+	object, err := p.factory.Plan().EtcdBackup().GetSnapshot(ctx, snapshotId)
+	if err != nil {
+		klog.Errorf("failed to get etcd snapshot(%d): %v", snapshotId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if object == nil || object.PlanId != planId {
+		return nil, errors.NewError(fmt.Errorf("快照不存在"), http.StatusNotFound)
+	}
+	return object, nil
+}
+
+// defaultBackupMethod agent 模式下控制面通常无法直连节点，默认通过集群 Pod 备份
+func defaultBackupMethod(pp *model.Plan) model.EtcdBackupMethod {
+	if pp.ExecMode == model.PlanExecModeAgent {
+		return model.EtcdBackupPod
+	}
+	return model.EtcdBackupSSH
+}
+
+func modelEtcdPolicy2Type(o *model.EtcdBackupPolicy) *types.EtcdBackupPolicy {
+	return &types.EtcdBackupPolicy{
+		PixiuMeta: types.PixiuMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		PlanId:           o.PlanId,
+		Enabled:          o.Enabled,
+		Schedule:         o.Schedule,
+		Retention:        o.Retention,
+		Method:           o.Method,
+		LastScheduleTime: o.LastScheduleTime,
+	}
+}
+
+func modelEtcdSnapshot2Type(o *model.EtcdSnapshot) *types.EtcdSnapshot {
+	return &types.EtcdSnapshot{
+		PixiuMeta: types.PixiuMeta{
+			Id:              o.Id,
+			ResourceVersion: o.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   o.GmtCreate,
+			GmtModified: o.GmtModified,
+		},
+		PlanId:     o.PlanId,
+		Method:     o.Method,
+		Trigger:    o.Trigger,
+		Node:       o.Node,
+		Size:       o.Size,
+		Checksum:   o.Checksum,
+		Status:     o.Status,
+		Message:    o.Message,
+		FinishedAt: o.FinishedAt,
+	}
+}
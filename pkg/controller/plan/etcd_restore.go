@@ -0,0 +1,216 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/etcdbackup"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	etcdRestoreActionPrefix = "etcd-restore"
+	etcdRestorePrepare      = etcdRestoreActionPrefix + "-prepare"
+	etcdRestoreStop         = etcdRestoreActionPrefix + "-stop"
+	etcdRestoreData         = etcdRestoreActionPrefix + "-data"
+	etcdRestoreStart        = etcdRestoreActionPrefix + "-start"
+
+	// etcdRestoreLogFile 恢复日志，位于计划工作目录下，作为恢复任务的日志
+	etcdRestoreLogFile = "etcd-restore.log"
+)
+
+var etcdRestoreStepList = []types.EtcdRestoreStep{
+	{Name: "恢复准备", Action: etcdRestorePrepare, Description: "校验快照并上传至全部 master 节点，收集 etcd 成员信息"},
+	{Name: "停止控制面", Action: etcdRestoreStop, Description: "移出 kube-apiserver 与 etcd 静态 Pod 清单并等待 etcd 停止"},
+	{Name: "恢复数据", Action: etcdRestoreData, Description: "备份原 etcd 数据目录，使用快照重建各成员数据"},
+	{Name: "启动控制面", Action: etcdRestoreStart, Description: "移回静态 Pod 清单并等待 kube-apiserver 就绪"},
+}
+
+func etcdRestoreSteps() []types.EtcdRestoreStep {
+	steps := make([]types.EtcdRestoreStep, len(etcdRestoreStepList))
+	copy(steps, etcdRestoreStepList)
+	return steps
+}
+
+type etcdMember struct {
+	name string
+	peer string
+}
+
+// etcdRestoreContext 恢复各步骤共享的状态
+type etcdRestoreContext struct {
+	*nodeRunner
+
+	storage  etcdbackup.Storage
+	snapshot *model.EtcdSnapshot
+	masters  []model.Node
+	members  map[string]etcdMember
+	apiPort  int
+}
+
+// initialCluster 生成 --initial-cluster 参数
+func (rc *etcdRestoreContext) initialCluster() string {
+	parts := make([]string, 0, len(rc.masters))
+	for _, node := range rc.masters {
+		m := rc.members[node.Name]
+		parts = append(parts, m.name+"="+m.peer)
+	}
+	return strings.Join(parts, ",")
+}
+
+type EtcdRestore struct {
+	handlerTask
+	name   string
+	action string
+	rc     *etcdRestoreContext
+}
+
+func newEtcdRestoreHandlers(data TaskData, rc *etcdRestoreContext) []Handler {
+	handlers := make([]Handler, 0, len(etcdRestoreStepList))
+	for _, step := range etcdRestoreStepList {
+		handlers = append(handlers, EtcdRestore{
+			handlerTask: newHandlerTask(data),
+			name:        step.Name,
+			action:      step.Action,
+			rc:          rc,
+		})
+	}
+	return handlers
+}
+
+func (e EtcdRestore) Name() string      { return e.name }
+func (e EtcdRestore) GetAction() string { return e.action }
+
+func (e EtcdRestore) Step() model.PlanStep {
+	if e.action == etcdRestoreStart {
+		return model.CompletedPlanStep
+	}
+	return model.RunningPlanStep
+}
+
+func (e EtcdRestore) Run() error {
+	e.rc.logf("==> %s", e.name)
+	var err error
+	switch e.action {
+	case etcdRestorePrepare:
+		err = e.prepare()
+	case etcdRestoreStop:
+		err = e.eachMaster(func(node model.Node) error {
+			return e.rc.run(node, etcdbackup.RestoreStopScript, nil, nil)
+		})
+	case etcdRestoreData:
+		initialCluster := e.rc.initialCluster()
+		e.rc.logf("initial-cluster: %s", initialCluster)
+		err = e.eachMaster(func(node model.Node) error {
+			m := e.rc.members[node.Name]
+			return e.rc.run(node, etcdbackup.RestoreDataScript(m.name, m.peer, initialCluster), nil, nil)
+		})
+	case etcdRestoreStart:
+		if err = e.eachMaster(func(node model.Node) error {
+			return e.rc.run(node, etcdbackup.RestoreStartScript, nil, nil)
+		}); err == nil {
+			err = e.rc.run(e.rc.masters[0], etcdbackup.RestoreWaitScript(e.rc.apiPort), nil, nil)
+		}
+	default:
+		err = fmt.Errorf("未知的恢复步骤 %s", e.action)
+	}
+	if err != nil {
+		e.rc.logf("%s失败: %v", e.name, err)
+		return err
+	}
+	e.rc.logf("%s完成", e.name)
+	return nil
+}
+
+// eachMaster 依次在 master 节点执行，任一节点失败即中止，避免集群处于更不一致的状态
+func (e EtcdRestore) eachMaster(fn func(node model.Node) error) error {
+	for _, node := range e.rc.masters {
+		if err := fn(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepare 下载并校验快照，收集成员信息后上传至各 master 节点
+func (e EtcdRestore) prepare() error {
+	rc := e.rc
+	tmp, err := os.CreateTemp("", "pixiu-etcd-restore-*.db")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rd, err := rc.storage.Get(rc.ctx, rc.snapshot.StorageKey)
+	if err != nil {
+		return fmt.Errorf("读取快照失败: %v", err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), rd)
+	rd.Close()
+	if err != nil {
+		return fmt.Errorf("下载快照失败: %v", err)
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	if rc.snapshot.Checksum != "" && checksum != rc.snapshot.Checksum {
+		return fmt.Errorf("快照校验失败: 期望 %s，实际 %s", rc.snapshot.Checksum, checksum)
+	}
+	rc.logf("快照 %s 校验通过 (sha256 %s)", rc.snapshot.StorageKey, checksum)
+
+	return e.eachMaster(func(node model.Node) error {
+		var out strings.Builder
+		if err := rc.run(node, etcdbackup.RestoreMemberScript, nil, &out); err != nil {
+			return err
+		}
+		member := etcdMember{name: node.Name, peer: fmt.Sprintf("https://%s:2380", node.Ip)}
+		for _, line := range strings.Split(out.String(), "\n") {
+			k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || v == "" {
+				continue
+			}
+			switch k {
+			case "name":
+				member.name = v
+			case "peer":
+				member.peer = v
+			}
+		}
+		rc.members[node.Name] = member
+		rc.logf("[%s] etcd 成员 %s %s", node.Name, member.name, member.peer)
+
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		out.Reset()
+		if err := rc.run(node, etcdbackup.RestoreUploadScript, tmp, &out); err != nil {
+			return err
+		}
+		if got := strings.TrimSpace(out.String()); got != checksum {
+			return fmt.Errorf("%s: 快照上传校验失败: %s", node.Name, got)
+		}
+		rc.logf("[%s] 快照已上传", node.Name)
+		return nil
+	})
+}
@@ -51,6 +51,11 @@ type Interface interface {
 
 	GetWithSubResources(ctx context.Context, planId int64) (*types.Plan, error)
 
+	// Export 导出部署计划声明式文档
+	Export(ctx context.Context, planId int64, mode types.PlanSecretMode) ([]byte, error)
+	// Apply 幂等导入部署计划声明式文档，dryRun 时仅返回差异
+	Apply(ctx context.Context, data []byte, dryRun bool) (*types.ApplyPlanResult, error)
+
 	// Start 启动部署任务
 	Start(ctx context.Context, pid int64) error
 	// Destroy 销毁k8s集群
@@ -67,6 +72,20 @@ type Interface interface {
 
 	// Config 计划配置子接口
 	Config() ConfigInterface
+
+	// etcd 备份与恢复
+	GetEtcdBackupPolicy(ctx context.Context, planId int64) (*types.EtcdBackupPolicy, error)
+	UpdateEtcdBackupPolicy(ctx context.Context, planId int64, req *types.UpdateEtcdBackupPolicyRequest) error
+	CreateEtcdSnapshot(ctx context.Context, planId int64, req *types.CreateEtcdSnapshotRequest) (*types.EtcdSnapshot, error)
+	ListEtcdSnapshots(ctx context.Context, planId int64, listOption types.ListOptions) (interface{}, error)
+	DeleteEtcdSnapshot(ctx context.Context, planId int64, snapshotId int64) error
+	DownloadEtcdSnapshot(ctx context.Context, planId int64, snapshotId int64, w http.ResponseWriter) error
+	RestoreEtcd(ctx context.Context, planId int64, req *types.RestoreEtcdRequest) (*types.EtcdRestorePlan, error)
+
+	// 证书巡检与续期
+	GetCertificates(ctx context.Context, planId int64) (*types.PlanCertificates, error)
+	RefreshCertificates(ctx context.Context, planId int64) (*types.PlanCertificates, error)
+	RenewCertificates(ctx context.Context, planId int64, req *types.RenewCertificatesRequest) error
 }
 
 var taskQueue workqueue.RateLimitingInterface
@@ -382,6 +401,16 @@ func (p *plan) Delete(ctx context.Context, planId int64) error {
 		klog.Errorf("failed to delete plan(%d) nodes: %v", planId, err)
 		return err
 	}
+	// 5. 删除 etcd 备份策略，已有快照保留在备份存储中
+	if err = p.factory.Plan().EtcdBackup().DeletePolicy(ctx, planId); err != nil {
+		klog.Errorf("failed to delete plan(%d) etcd backup policy: %v", planId, err)
+		return err
+	}
+	// 6. 删除证书巡检结果
+	if err = p.factory.Plan().Certificate().DeleteByPlan(ctx, planId); err != nil {
+		klog.Errorf("failed to delete plan(%d) certificates: %v", planId, err)
+		return err
+	}
 
 	return nil
 }
@@ -621,6 +650,27 @@ func (p *plan) IsRunning(ctx context.Context, planId int64) (bool, error) {
 	}
 }
 
+// claimPlan 在启动后台任务前将部署计划原子地置为运行中，
+// 并发请求或计划已在运行时只有一个能抢占成功，其余返回 ErrNotAcceptable
+func (p *plan) claimPlan(ctx context.Context, object *model.Plan) error {
+	if err := p.factory.Plan().ClaimStatus(ctx, object.Id, object.ResourceVersion, model.RunningPlanStatus); err != nil {
+		if utilerrors.IsNotUpdated(err) {
+			klog.Warningf("plan %d is running or has been modified", object.Id)
+			return errors.ErrNotAcceptable
+		}
+		klog.Errorf("failed to claim plan %d: %v", object.Id, err)
+		return errors.ErrServerInternal
+	}
+	return nil
+}
+
+// releasePlan 后台任务未能启动时恢复抢占前的计划状态
+func (p *plan) releasePlan(ctx context.Context, object *model.Plan) {
+	if err := p.factory.Plan().UpdateStatus(ctx, object.Id, object.Status); err != nil {
+		klog.Errorf("failed to release plan %d: %v", object.Id, err)
+	}
+}
+
 // checkPlanAccess 校验当前用户是否有权操作该部署计划：超管放行 → owner 放行 → scope 命中放行。
 func (p *plan) checkPlanAccess(ctx context.Context, pid int64) error {
 	object, err := p.factory.Plan().Get(ctx, pid)
@@ -0,0 +1,518 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/api/server/httputils"
+	"github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	"github.com/caoyingjunz/pixiu/pkg/util/diff"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+// changedSecret diff 中标记被修改的敏感字段，避免泄露新旧值
+const changedSecret = "<redacted:changed>"
+
+// Export 将部署计划导出为声明式 YAML 文档，敏感字段始终不以明文导出
+func (p *plan) Export(ctx context.Context, planId int64, mode types.PlanSecretMode) ([]byte, error) {
+	object, err := p.factory.Plan().Get(ctx, planId)
+	if err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, errors.ErrPlanNotFound
+		}
+		klog.Errorf("failed to get plan %d: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if err = util.CheckResourceAccess(ctx, p.factory, object.UserId, types.ResourceTypePlan, planId); err != nil {
+		return nil, err
+	}
+
+	doc, err := p.buildPlanDocument(ctx, object)
+	if err != nil {
+		klog.Errorf("failed to build plan(%d) document: %v", planId, err)
+		return nil, errors.ErrServerInternal
+	}
+	if mode == types.PlanSecretReference {
+		if err = p.referenceNodeAuth(ctx, doc); err != nil {
+			klog.Errorf("failed to reference plan(%d) node auth: %v", planId, err)
+			return nil, errors.ErrServerInternal
+		}
+	}
+
+	return yaml.Marshal(redactPlanDocument(doc, doc))
+}
+
+// Apply 幂等导入部署计划文档：同名计划不存在则创建，存在则更新；
+// 文档中的 <redacted> 沿用已有值，auth_from 引用主机清单中的节点认证。
+func (p *plan) Apply(ctx context.Context, data []byte, dryRun bool) (*types.ApplyPlanResult, error) {
+	desired, err := parsePlanDocument(data)
+	if err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+
+	existing, err := p.getPlanByName(ctx, desired.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	var current *types.PlanDocument
+	if existing != nil {
+		if err = util.CheckResourceAccess(ctx, p.factory, existing.UserId, types.ResourceTypePlan, existing.Id); err != nil {
+			return nil, err
+		}
+		if current, err = p.buildPlanDocument(ctx, existing); err != nil {
+			klog.Errorf("failed to build plan(%d) document: %v", existing.Id, err)
+			return nil, errors.ErrServerInternal
+		}
+	}
+	if err = p.resolvePlanDocument(ctx, desired, current); err != nil {
+		return nil, errors.NewError(err, http.StatusBadRequest)
+	}
+	normalizePlanDocument(desired)
+
+	result := &types.ApplyPlanResult{DryRun: dryRun}
+	desiredYAML, err := yaml.Marshal(redactPlanDocument(desired, current))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		result.Action = types.PlanApplyCreate
+		result.Diff = diff.Unified("/dev/null", desired.Metadata.Name, "", string(desiredYAML))
+	} else {
+		result.PlanId = existing.Id
+		currentYAML, err := yaml.Marshal(redactPlanDocument(current, current))
+		if err != nil {
+			return nil, err
+		}
+		result.Diff = diff.Unified(current.Metadata.Name, desired.Metadata.Name, string(currentYAML), string(desiredYAML))
+
+		result.Action = types.PlanApplyUpdate
+		if equal, err := planDocumentsEqual(current, desired); err != nil {
+			return nil, err
+		} else if equal {
+			result.Action = types.PlanApplyUnchanged
+		}
+	}
+	if dryRun || result.Action == types.PlanApplyUnchanged {
+		return result, nil
+	}
+
+	if result.Action == types.PlanApplyCreate {
+		user, err := httputils.GetUserFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req := &types.CreatePlanRequest{
+			Name:          desired.Metadata.Name,
+			Description:   desired.Metadata.Description,
+			UserId:        user.Id,
+			ExecMode:      desired.Spec.ExecMode,
+			DeployAgentId: desired.Spec.DeployAgentId,
+			Config:        documentConfig2Request(desired.Spec.Config),
+			Nodes:         documentNodes2Request(desired.Spec.Nodes),
+		}
+		if err = p.Create(ctx, req); err != nil {
+			return nil, err
+		}
+		created, err := p.getPlanByName(ctx, desired.Metadata.Name)
+		if err != nil {
+			return nil, err
+		}
+		if created != nil {
+			result.PlanId = created.Id
+		}
+		return result, nil
+	}
+
+	req := &types.UpdatePlanRequest{
+		Name:            desired.Metadata.Name,
+		ResourceVersion: &existing.ResourceVersion,
+		Description:     desired.Metadata.Description,
+		ExecMode:        desired.Spec.ExecMode,
+		DeployAgentId:   desired.Spec.DeployAgentId,
+		Config:          documentConfig2Request(desired.Spec.Config),
+		Nodes:           documentNodes2Request(desired.Spec.Nodes),
+	}
+	if err = p.Update(ctx, existing.Id, req); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parsePlanDocument(data []byte) (*types.PlanDocument, error) {
+	var doc types.PlanDocument
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析部署文档失败: %v", err)
+	}
+	if doc.APIVersion != types.PlanDocumentAPIVersion {
+		return nil, fmt.Errorf("不支持的 apiVersion %q，期望 %s", doc.APIVersion, types.PlanDocumentAPIVersion)
+	}
+	if doc.Kind != types.PlanDocumentKind {
+		return nil, fmt.Errorf("不支持的 kind %q，期望 %s", doc.Kind, types.PlanDocumentKind)
+	}
+	if len(doc.Metadata.Name) == 0 {
+		return nil, fmt.Errorf("metadata.name 不能为空")
+	}
+	if len(doc.Spec.Config.OSImage) == 0 {
+		return nil, fmt.Errorf("spec.config.os_image 不能为空")
+	}
+
+	names := make(map[string]bool)
+	for _, node := range doc.Spec.Nodes {
+		if len(node.Name) == 0 {
+			return nil, fmt.Errorf("节点名称不能为空")
+		}
+		if names[node.Name] {
+			return nil, fmt.Errorf("节点 %s 重复", node.Name)
+		}
+		names[node.Name] = true
+		if node.Auth != nil && len(node.AuthFrom) != 0 {
+			return nil, fmt.Errorf("节点 %s 的 auth 与 auth_from 不能同时指定", node.Name)
+		}
+	}
+	return &doc, nil
+}
+
+// 部署计划名称全局唯一，不存在时返回 nil
+func (p *plan) getPlanByName(ctx context.Context, name string) (*model.Plan, error) {
+	objects, err := p.factory.Plan().List(ctx, db.WithName(name))
+	if err != nil {
+		klog.Errorf("failed to get plan by name %s: %v", name, err)
+		return nil, errors.ErrServerInternal
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	return &objects[0], nil
+}
+
+// buildPlanDocument 构造包含明文敏感字段的完整文档，仅用于内部比较和导出前处理
+func (p *plan) buildPlanDocument(ctx context.Context, object *model.Plan) (*types.PlanDocument, error) {
+	cfg, err := p.factory.Plan().GetConfigByPlan(ctx, object.Id)
+	if err != nil {
+		return nil, err
+	}
+	planConfig, err := p.modelConfig2Type(cfg)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := p.factory.Plan().ListNodes(ctx, object.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &types.PlanDocument{
+		APIVersion: types.PlanDocumentAPIVersion,
+		Kind:       types.PlanDocumentKind,
+		Metadata: types.PlanDocumentMetadata{
+			Name:        object.Name,
+			Description: object.Description,
+		},
+		Spec: types.PlanDocumentSpec{
+			ExecMode:      object.ExecMode,
+			DeployAgentId: object.DeployAgentId,
+			Config: types.PlanDocumentConfig{
+				Region:     planConfig.Region,
+				OSImage:    planConfig.OSImage,
+				Kubernetes: planConfig.Kubernetes,
+				Network:    planConfig.Network,
+				Runtime:    planConfig.Runtime,
+				Component:  planConfig.Component,
+			},
+			Nodes: []types.PlanDocumentNode{},
+		},
+	}
+	for _, node := range nodes {
+		auth := types.PlanNodeAuth{}
+		if err = auth.Unmarshal(node.Auth); err != nil {
+			return nil, err
+		}
+		var role []string
+		if len(node.Role) != 0 {
+			role = strings.Split(node.Role, ",")
+		}
+		doc.Spec.Nodes = append(doc.Spec.Nodes, types.PlanDocumentNode{
+			Name: node.Name,
+			Role: role,
+			CRI:  node.CRI,
+			Ip:   node.Ip,
+			Auth: &auth,
+		})
+	}
+	normalizePlanDocument(doc)
+	return doc, nil
+}
+
+// referenceNodeAuth 节点认证与主机清单中同 IP 主机一致时，改为 auth_from 引用
+func (p *plan) referenceNodeAuth(ctx context.Context, doc *types.PlanDocument) error {
+	for i := range doc.Spec.Nodes {
+		node := &doc.Spec.Nodes[i]
+		host, err := p.getInventoryHost(ctx, node.Ip)
+		if err != nil {
+			return err
+		}
+		if host == nil {
+			continue
+		}
+		if equal, err := jsonEqual(host, node.Auth); err != nil {
+			return err
+		} else if equal {
+			node.Auth = nil
+			node.AuthFrom = types.PlanNodeAuthFromPrefix + node.Ip
+		}
+	}
+	return nil
+}
+
+// getInventoryHost 获取当前用户可访问的主机清单（plan_id 为 0）中指定 IP 的主机认证，不存在时返回 nil
+func (p *plan) getInventoryHost(ctx context.Context, ip string) (*types.PlanNodeAuth, error) {
+	hosts, err := p.factory.Plan().ListNodes(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range hosts {
+		if host.Ip != ip {
+			continue
+		}
+		if err = util.CheckResourceAccess(ctx, p.factory, host.UserId, types.ResourceTypeNode, host.Id); err != nil {
+			if err == errors.ErrForbidden {
+				return nil, nil
+			}
+			return nil, err
+		}
+		auth := &types.PlanNodeAuth{}
+		if err = auth.Unmarshal(host.Auth); err != nil {
+			return nil, err
+		}
+		return auth, nil
+	}
+	return nil, nil
+}
+
+// resolvePlanDocument 将文档中的 auth_from 引用和 <redacted> 占位符还原为实际值
+func (p *plan) resolvePlanDocument(ctx context.Context, doc, current *types.PlanDocument) error {
+	currentNodes := make(map[string]*types.PlanNodeAuth)
+	if current != nil {
+		for _, node := range current.Spec.Nodes {
+			currentNodes[node.Name] = node.Auth
+		}
+	}
+
+	for i := range doc.Spec.Nodes {
+		node := &doc.Spec.Nodes[i]
+		if len(node.AuthFrom) != 0 {
+			ip := strings.TrimPrefix(node.AuthFrom, types.PlanNodeAuthFromPrefix)
+			if ip == node.AuthFrom || len(ip) == 0 {
+				return fmt.Errorf("节点 %s 的 auth_from %q 格式错误，应为 %s<ip>", node.Name, node.AuthFrom, types.PlanNodeAuthFromPrefix)
+			}
+			host, err := p.getInventoryHost(ctx, ip)
+			if err != nil {
+				klog.Errorf("failed to get inventory host %s: %v", ip, err)
+				return errors.ErrServerInternal
+			}
+			if host == nil {
+				return fmt.Errorf("节点 %s 引用的主机 %s 不存在或无权访问", node.Name, ip)
+			}
+			node.Auth = host
+			node.AuthFrom = ""
+			continue
+		}
+		if node.Auth == nil {
+			return fmt.Errorf("节点 %s 未指定 auth 或 auth_from", node.Name)
+		}
+
+		old := currentNodes[node.Name]
+		if node.Auth.Key != nil {
+			var stored *string
+			if old != nil && old.Key != nil {
+				stored = &old.Key.Data
+			}
+			if err := restoreSecret(&node.Auth.Key.Data, stored, fmt.Sprintf("节点 %s 的密钥", node.Name)); err != nil {
+				return err
+			}
+		}
+		if node.Auth.Password != nil {
+			var stored *string
+			if old != nil && old.Password != nil {
+				stored = &old.Password.Password
+			}
+			if err := restoreSecret(&node.Auth.Password.Password, stored, fmt.Sprintf("节点 %s 的密码", node.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if grafana := doc.Spec.Config.Component.Grafana; grafana != nil {
+		var stored *string
+		if current != nil && current.Spec.Config.Component.Grafana != nil {
+			stored = &current.Spec.Config.Component.Grafana.GrafanaAdminPassword
+		}
+		if err := restoreSecret(&grafana.GrafanaAdminPassword, stored, "grafana 管理员密码"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreSecret 将 <redacted> 还原为已有值 stored（nil 表示无已有值）；
+// <redacted:changed> 仅出现在 diff 中，作为输入时拒绝，避免被当作明文写入
+func restoreSecret(value, stored *string, field string) error {
+	switch *value {
+	case types.RedactedSecret:
+		if stored == nil {
+			return fmt.Errorf("%s已脱敏且无已有值可沿用，请提供明文", field)
+		}
+		*value = *stored
+	case changedSecret:
+		return fmt.Errorf("%s为 diff 中的占位符 %s，请提供明文或 %s", field, changedSecret, types.RedactedSecret)
+	}
+	return nil
+}
+
+// normalizePlanDocument 与 Create/Update 的默认处理保持一致，保证重复导入时结果稳定
+func normalizePlanDocument(doc *types.PlanDocument) {
+	if len(doc.Spec.ExecMode) == 0 {
+		doc.Spec.ExecMode = model.PlanExecModeLocal
+	}
+	if doc.Spec.ExecMode == model.PlanExecModeLocal {
+		doc.Spec.DeployAgentId = 0
+	}
+	if !doc.Spec.Config.Kubernetes.EnablePublicIp {
+		doc.Spec.Config.Kubernetes.ApiServer = ""
+	}
+	if doc.Spec.Nodes == nil {
+		doc.Spec.Nodes = []types.PlanDocumentNode{}
+	}
+	for i := range doc.Spec.Nodes {
+		if len(doc.Spec.Nodes[i].Role) == 0 {
+			doc.Spec.Nodes[i].Role = nil
+		}
+	}
+}
+
+// redactPlanDocument 返回脱敏后的文档副本；与 current 不一致的敏感字段标记为已修改
+func redactPlanDocument(doc, current *types.PlanDocument) *types.PlanDocument {
+	out := &types.PlanDocument{}
+	copyJSON(doc, out)
+
+	currentNodes := make(map[string]*types.PlanNodeAuth)
+	var currentGrafana *types.Grafana
+	if current != nil {
+		for _, node := range current.Spec.Nodes {
+			currentNodes[node.Name] = node.Auth
+		}
+		currentGrafana = current.Spec.Config.Component.Grafana
+	}
+
+	for i := range out.Spec.Nodes {
+		auth := out.Spec.Nodes[i].Auth
+		if auth == nil {
+			continue
+		}
+		old := currentNodes[out.Spec.Nodes[i].Name]
+		if auth.Key != nil {
+			var oldKey string
+			if old != nil && old.Key != nil {
+				oldKey = old.Key.Data
+			}
+			auth.Key.Data = redactSecret(auth.Key.Data, oldKey)
+		}
+		if auth.Password != nil {
+			var oldPassword string
+			if old != nil && old.Password != nil {
+				oldPassword = old.Password.Password
+			}
+			auth.Password.Password = redactSecret(auth.Password.Password, oldPassword)
+		}
+	}
+	if grafana := out.Spec.Config.Component.Grafana; grafana != nil {
+		var oldPassword string
+		if currentGrafana != nil {
+			oldPassword = currentGrafana.GrafanaAdminPassword
+		}
+		grafana.GrafanaAdminPassword = redactSecret(grafana.GrafanaAdminPassword, oldPassword)
+	}
+	return out
+}
+
+func redactSecret(value, current string) string {
+	if len(value) == 0 {
+		return ""
+	}
+	if value == current {
+		return types.RedactedSecret
+	}
+	return changedSecret
+}
+
+func planDocumentsEqual(a, b *types.PlanDocument) (bool, error) {
+	return jsonEqual(a, b)
+}
+
+func jsonEqual(a, b interface{}) (bool, error) {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	dbb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return string(da) == string(dbb), nil
+}
+
+// copyJSON 深拷贝，文档均为可 JSON 序列化的纯数据结构
+func copyJSON(in, out interface{}) {
+	data, _ := json.Marshal(in)
+	_ = json.Unmarshal(data, out)
+}
+
+func documentConfig2Request(cfg types.PlanDocumentConfig) types.CreatePlanConfigRequest {
+	return types.CreatePlanConfigRequest{
+		Region:     cfg.Region,
+		OSImage:    cfg.OSImage,
+		Kubernetes: cfg.Kubernetes,
+		Network:    cfg.Network,
+		Runtime:    cfg.Runtime,
+		Component:  cfg.Component,
+	}
+}
+
+func documentNodes2Request(nodes []types.PlanDocumentNode) []types.CreatePlanNodeRequest {
+	reqs := make([]types.CreatePlanNodeRequest, 0, len(nodes))
+	for _, node := range nodes {
+		reqs = append(reqs, types.CreatePlanNodeRequest{
+			Name: node.Name,
+			Role: node.Role,
+			CRI:  node.CRI,
+			Ip:   node.Ip,
+			Auth: *node.Auth,
+		})
+	}
+	return reqs
+}
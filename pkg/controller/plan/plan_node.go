@@ -153,6 +153,7 @@ func (p *plan) modelNode2Type(o *model.Node) (*types.PlanNode, error) {
 		Name:   o.Name,
 		UserId: o.UserId,
 		Role:   strings.Split(o.Role, ","),
+		CRI:    o.CRI,
 		Ip:     o.Ip,
 		Auth:   auth,
 	}, nil
@@ -166,6 +167,9 @@ func (p *plan) buildNodeUpdates(old, object *model.Node) map[string]interface{}
 	if old.Role != object.Role {
 		updates["role"] = object.Role
 	}
+	if old.CRI != object.CRI {
+		updates["cri"] = object.CRI
+	}
 	if old.Auth != object.Auth {
 		updates["auth"] = object.Auth
 	}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -101,6 +102,19 @@ func (p *plan) WatchTaskLog(ctx context.Context, planId int64, taskId int64, w h
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
+	// 预检查、etcd 恢复等任务在控制面执行，日志为落盘的文件
+	if file, ok := localTaskLogFile(task.Action); ok {
+		log, err := os.ReadFile(taskLogPath(p.WorkDir(), planId, file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("任务日志不存在")
+			}
+			return err
+		}
+		_, err = w.Write(log)
+		return err
+	}
+
 	cli, err := container.NewContainer("", planId, "")
 	if err != nil {
 		return err
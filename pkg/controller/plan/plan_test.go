@@ -0,0 +1,54 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestClaimPlan(t *testing.T) {
+	plans := &fakePlans{object: &model.Plan{Name: "demo", Status: model.SuccessPlanStatus}}
+	plans.object.Id = 1
+	p := &plan{factory: &fakeFactory{plans: plans}}
+
+	// 两个并发请求读到同一版本，只有先到的能抢占
+	first, second := *plans.object, *plans.object
+	if err := p.claimPlan(context.TODO(), &first); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if plans.object.Status != model.RunningPlanStatus {
+		t.Errorf("expected plan running, got %s", plans.object.Status)
+	}
+	if err := p.claimPlan(context.TODO(), &second); err != errors.ErrNotAcceptable {
+		t.Errorf("expected second claim rejected, got %v", err)
+	}
+
+	// 版本最新但计划仍在运行时同样拒绝
+	latest := *plans.object
+	if err := p.claimPlan(context.TODO(), &latest); err != errors.ErrNotAcceptable {
+		t.Errorf("expected running plan rejected, got %v", err)
+	}
+
+	p.releasePlan(context.TODO(), &first)
+	if plans.object.Status != model.SuccessPlanStatus {
+		t.Errorf("expected status restored, got %s", plans.object.Status)
+	}
+}
@@ -19,6 +19,7 @@ package plan
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -36,6 +37,7 @@ import (
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 	"github.com/caoyingjunz/pixiu/pkg/types"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
 	"github.com/caoyingjunz/pixiu/pkg/util/token"
 	"github.com/caoyingjunz/pixiu/pkg/util/uuid"
 )
@@ -91,6 +93,26 @@ func (c Register) Run() error {
 	return c.finishWithKubeConfig(config64)
 }
 
+// finishWithAgentResult 处理 Agent 上报的 fetch_kubeconfig 结果：先记录 Agent 首次信任的
+// master 主机公钥，再完成集群注册。兼容旧版 Agent 直接上报 kubeconfig(base64) 的结果。
+func (c Register) finishWithAgentResult(result string) error {
+	var res types.FetchKubeconfigResult
+	if err := json.Unmarshal([]byte(result), &res); err != nil {
+		return c.finishWithKubeConfig(result)
+	}
+	if hk := res.HostKey; hk != nil {
+		key, err := sshutil.ParsePublicKey(hk.PublicKey)
+		if err != nil {
+			return fmt.Errorf("invalid host key of %s:%d reported by agent: %v", hk.Host, hk.Port, err)
+		}
+		// 与控制面直连相同：未记录时写入，已记录且不一致时拒绝注册
+		if err = sshutil.VerifyHostKey(context.TODO(), hk.Host, hk.Port, key); err != nil {
+			return err
+		}
+	}
+	return c.finishWithKubeConfig(res.KubeConfig)
+}
+
 // finishWithKubeConfig 使用已拿到的 kubeconfig(base64) 完成集群注册。
 func (c Register) finishWithKubeConfig(config64 string) error {
 	// 1. 创建/更新集群
@@ -240,10 +262,8 @@ func newSftpClient(node model.Node) (*sftp.Client, error) {
 			Auth: []ssh.AuthMethod{
 				ssh.Password(nodeAuth.Password.Password),
 			},
-			Timeout: 30 * time.Second,
-			HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-				return nil
-			},
+			Timeout:         30 * time.Second,
+			HostKeyCallback: sshutil.HostKeyCallback(),
 		}
 	case types.KeyAuth:
 		//2. 使用秘钥
@@ -258,7 +278,7 @@ func newSftpClient(node model.Node) (*sftp.Client, error) {
 				ssh.PublicKeys(signer),
 			},
 			Timeout:         30 * time.Second,
-			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			HostKeyCallback: sshutil.HostKeyCallback(),
 		}
 	default:
 		return nil, fmt.Errorf("unsupported ssh auth type: %s", nodeAuth.Type)
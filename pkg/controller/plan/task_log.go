@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+const defaultAPIPort = 6443
+
+// localTaskLogs 在控制面执行（不启动部署容器）的任务，日志落盘在计划工作目录下
+var localTaskLogs = []struct {
+	actionPrefix string
+	file         string
+}{
+	{checkAction, checkReportFile},
+	{etcdRestoreActionPrefix, etcdRestoreLogFile},
+	{certRenewActionPrefix, certRenewLogFile},
+}
+
+func taskLogPath(workDir string, planId int64, file string) string {
+	return filepath.Join(workDir, fmt.Sprintf("%d", planId), file)
+}
+
+// localTaskLogFile 返回控制面任务的日志文件名，部署容器执行的任务返回 false
+func localTaskLogFile(action string) (string, bool) {
+	for _, l := range localTaskLogs {
+		if strings.HasPrefix(action, l.actionPrefix) {
+			return l.file, true
+		}
+	}
+	return "", false
+}
+
+// openTaskLog 创建（截断）控制面任务的日志文件
+func openTaskLog(workDir string, planId int64, file string) (*os.File, error) {
+	path := taskLogPath(workDir, planId, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+}
+
+// localAPIPort 本机 kube-apiserver 端口，高可用时 api_port 为负载均衡端口，仍使用默认端口
+func localAPIPort(cfg *model.Config) int {
+	var ks types.KubernetesSpec
+	if cfg == nil || ks.Unmarshal(cfg.Kubernetes) != nil || ks.EnableHA {
+		return defaultAPIPort
+	}
+	if port, err := strconv.Atoi(ks.ApiPort); err == nil && port > 0 {
+		return port
+	}
+	return defaultAPIPort
+}
+
+// nodeRunner 通过 SSH 在节点上执行脚本，输出按节点加前缀写入任务日志
+type nodeRunner struct {
+	ctx context.Context
+
+	lock sync.Mutex
+	log  io.Writer
+}
+
+func newNodeRunner(ctx context.Context, log io.Writer) *nodeRunner {
+	return &nodeRunner{ctx: ctx, log: log}
+}
+
+func (r *nodeRunner) logf(format string, args ...interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	fmt.Fprintf(r.log, format+"\n", args...)
+}
+
+// run 在节点上执行脚本，stdout 为空时输出写入任务日志
+func (r *nodeRunner) run(node model.Node, script string, stdin io.Reader, stdout io.Writer) error {
+	var auth types.PlanNodeAuth
+	if err := auth.Unmarshal(node.Auth); err != nil {
+		return fmt.Errorf("%s: 解析节点认证信息失败: %v", node.Name, err)
+	}
+	if stdout == nil {
+		stdout = &prefixWriter{r: r, prefix: node.Name}
+	}
+	if err := sshutil.RunScript(r.ctx, node.Ip, &auth, script, stdin, stdout, &prefixWriter{r: r, prefix: node.Name}); err != nil {
+		return fmt.Errorf("%s: %v", node.Name, err)
+	}
+	return nil
+}
+
+type prefixWriter struct {
+	r      *nodeRunner
+	prefix string
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(p)))
+	for scanner.Scan() {
+		w.r.logf("[%s] %s", w.prefix, scanner.Text())
+	}
+	return len(p), nil
+}
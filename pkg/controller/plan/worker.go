@@ -22,10 +22,13 @@ import (
 	"time"
 
 	"github.com/caoyingjunz/pixiu/pkg/db"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 	"github.com/caoyingjunz/pixiu/pkg/util/errors"
 )
 
@@ -166,7 +169,7 @@ func (p *plan) buildLocalHandlers(task handlerTask, runner string) []Handler {
 	return []Handler{
 		Runner{handlerTask: task, image: runner, factory: p.factory},
 		Render{handlerTask: task, dir: dir},
-		Check{handlerTask: task},
+		Check{handlerTask: task, factory: p.factory, dir: dir, remote: true},
 		BootStrap{handlerTask: task, dir: dir, runner: runner},
 		DeployMaster{handlerTask: task, dir: dir, runner: runner},
 		DeployNode{handlerTask: task, dir: dir, runner: runner},
@@ -178,10 +181,10 @@ func (p *plan) buildLocalHandlers(task handlerTask, runner string) []Handler {
 func (p *plan) buildAgentHandlers(task handlerTask, runner string, data TaskData) []Handler {
 	agentId := data.Plan.DeployAgentId
 	reg := Register{handlerTask: task, factory: p.factory}
-	payload, _ := buildRegisterPayload(data.Nodes)
+	payload, _ := buildRegisterPayload(context.TODO(), p.factory, data.Nodes)
 
 	return []Handler{
-		Check{handlerTask: task},
+		Check{handlerTask: task, factory: p.factory, dir: p.WorkDir()},
 		// 镜像拉取、配置渲染与部署均在边缘 Agent 执行；控制面只下发 Job 并等待结果
 		AgentJob{
 			handlerTask: task, factory: p.factory, agentId: agentId,
@@ -219,7 +222,7 @@ func (p *plan) buildAgentHandlers(task handlerTask, runner string, data TaskData
 			kind: model.JobFetchKubeconfig, action: "register", payload: payload,
 			timeout: 15 * time.Minute,
 			onSuccess: func(result string) error {
-				return reg.finishWithKubeConfig(result)
+				return reg.finishWithAgentResult(result)
 			},
 		},
 		AgentJob{
@@ -353,7 +356,12 @@ func (p *plan) syncTasks(tasks ...Handler) error {
 		message := "执行成功"
 
 		// 执行检查
+		runStart := time.Now()
+		_, span := tracing.Start(context.TODO(), "plan.RunTask",
+			attribute.Int64("pixiu.plan_id", planId), attribute.String("pixiu.plan.task", name))
 		runErr := task.Run()
+		tracing.End(span, runErr)
+		metrics.ObservePlanTask(name, time.Since(runStart), runErr)
 		if runErr != nil {
 			status = model.FailedPlanStatus
 			step = model.FailedPlanStep
@@ -0,0 +1,219 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package price
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	ctrlutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type Interface interface {
+	Create(ctx context.Context, req *types.CreateAIModelPriceRequest) error
+	Update(ctx context.Context, req *types.UpdateAIModelPriceRequest) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*types.AIModelPrice, error)
+	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
+}
+
+type controller struct {
+	cc      config.Config
+	factory db.ShareDaoFactory
+}
+
+func New(cfg config.Config, f db.ShareDaoFactory) Interface {
+	return &controller{cc: cfg, factory: f}
+}
+
+// Cost 按每百万 token 单价计算一次请求的费用；未配置缓存单价时缓存命中部分按输入单价计
+func Cost(price *model.AIModelPrice, inputTokens, cachedTokens, outputTokens int64) float64 {
+	if price == nil {
+		return 0
+	}
+	cachedPrice := price.CachedInputPrice
+	if cachedPrice <= 0 {
+		cachedPrice = price.InputPrice
+	}
+	if cachedTokens > inputTokens {
+		cachedTokens = inputTokens
+	}
+	cost := float64(inputTokens-cachedTokens)*price.InputPrice +
+		float64(cachedTokens)*cachedPrice +
+		float64(outputTokens)*price.OutputPrice
+	return cost / 1e6
+}
+
+func (c *controller) Create(ctx context.Context, req *types.CreateAIModelPriceRequest) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+	if err := c.ensureProvider(ctx, req.ProviderId); err != nil {
+		return err
+	}
+
+	_, err := c.factory.Assistant().ModelPrice().Create(ctx, &model.AIModelPrice{
+		ProviderId:       req.ProviderId,
+		ModelName:        strings.TrimSpace(req.Model),
+		Currency:         normalizeCurrency(req.Currency),
+		InputPrice:       req.InputPrice,
+		CachedInputPrice: req.CachedInputPrice,
+		OutputPrice:      req.OutputPrice,
+	})
+	if err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return apierrors.NewError(fmt.Errorf("price for model %s already exists", req.Model), http.StatusConflict)
+		}
+		klog.Errorf("failed to create ai model price: %v", err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Update(ctx context.Context, req *types.UpdateAIModelPriceRequest) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"currency":           normalizeCurrency(req.Currency),
+		"input_price":        req.InputPrice,
+		"cached_input_price": req.CachedInputPrice,
+		"output_price":       req.OutputPrice,
+	}
+	if err := c.factory.Assistant().ModelPrice().Update(ctx, req.Id, req.ResourceVersion, updates); err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("ai model price not found or resource version conflict"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update ai model price(%d): %v", req.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Delete(ctx context.Context, id int64) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+
+	if err := c.factory.Assistant().ModelPrice().Delete(ctx, id); err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("ai model price not found"), http.StatusNotFound)
+		}
+		klog.Errorf("failed to delete ai model price(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Get(ctx context.Context, id int64) (*types.AIModelPrice, error) {
+	object, err := c.factory.Assistant().ModelPrice().Get(ctx, id)
+	if err != nil {
+		klog.Errorf("failed to get ai model price(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, apierrors.NewError(fmt.Errorf("ai model price not found"), http.StatusNotFound)
+	}
+	return modelToType(object), nil
+}
+
+func (c *controller) List(ctx context.Context, listOption types.ListOptions) (interface{}, error) {
+	listOption.SetDefaultPageOption()
+
+	pageResult := types.PageResult{
+		PageRequest: types.PageRequest{
+			Page:  listOption.Page,
+			Limit: listOption.Limit,
+		},
+	}
+
+	opts := []db.Options{db.WithAIProviderId(listOption.ProviderId)}
+
+	var err error
+	pageResult.Total, err = c.factory.Assistant().ModelPrice().Count(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to count ai model prices: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	offset := (listOption.Page - 1) * listOption.Limit
+	opts = append(opts,
+		db.WithModifyOrderByDesc(),
+		db.WithOffset(offset),
+		db.WithLimit(listOption.Limit),
+	)
+
+	objects, err := c.factory.Assistant().ModelPrice().List(ctx, opts...)
+	if err != nil {
+		klog.Errorf("failed to list ai model prices: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	items := make([]types.AIModelPrice, 0)
+	for i := range objects {
+		items = append(items, *modelToType(&objects[i]))
+	}
+	pageResult.Items = items
+
+	return pageResult, nil
+}
+
+func (c *controller) ensureProvider(ctx context.Context, providerId int64) error {
+	provider, err := c.factory.Assistant().Provider().Get(ctx, providerId)
+	if err != nil {
+		klog.Errorf("failed to get ai provider(%d): %v", providerId, err)
+		return apierrors.ErrServerInternal
+	}
+	if provider == nil {
+		return apierrors.NewError(fmt.Errorf("ai provider not found"), http.StatusBadRequest)
+	}
+	return nil
+}
+
+func modelToType(object *model.AIModelPrice) *types.AIModelPrice {
+	return &types.AIModelPrice{
+		PixiuMeta: types.PixiuMeta{
+			Id:              object.Id,
+			ResourceVersion: object.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   object.GmtCreate,
+			GmtModified: object.GmtModified,
+		},
+		ProviderId:       object.ProviderId,
+		Model:            object.ModelName,
+		Currency:         object.Currency,
+		InputPrice:       object.InputPrice,
+		CachedInputPrice: object.CachedInputPrice,
+		OutputPrice:      object.OutputPrice,
+	}
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
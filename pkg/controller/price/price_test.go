@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package price
+
+import (
+	"math"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestCost(t *testing.T) {
+	cases := []struct {
+		name   string
+		price  *model.AIModelPrice
+		input  int64
+		cached int64
+		output int64
+		want   float64
+	}{
+		{name: "no price", price: nil, input: 1000, output: 1000, want: 0},
+		{name: "input and output", price: &model.AIModelPrice{InputPrice: 2, OutputPrice: 8}, input: 1_000_000, output: 500_000, want: 6},
+		{name: "cached input", price: &model.AIModelPrice{InputPrice: 2, CachedInputPrice: 0.5, OutputPrice: 8}, input: 1_000_000, cached: 400_000, want: 1.4},
+		{name: "cached falls back to input price", price: &model.AIModelPrice{InputPrice: 2}, input: 1_000_000, cached: 400_000, want: 2},
+		{name: "cached capped by input", price: &model.AIModelPrice{InputPrice: 2, CachedInputPrice: 1}, input: 100, cached: 1_000_000, want: 0.0001},
+	}
+	for _, tc := range cases {
+		if got := Cost(tc.price, tc.input, tc.cached, tc.output); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: expected cost %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
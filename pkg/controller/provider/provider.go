@@ -53,6 +53,9 @@ func (c *controller) Create(ctx context.Context, req *types.CreateProviderReques
 	if err := validateProviderFields(req.Name, req.BaseURL, req.Protocol); err != nil {
 		return err
 	}
+	if err := validateContextWindow(req.ContextWindow, req.MaxTokens); err != nil {
+		return err
+	}
 
 	_, err := c.factory.Assistant().Provider().Create(ctx, &model.AIProvider{
 		Name:        strings.TrimSpace(req.Name),
@@ -60,6 +63,8 @@ func (c *controller) Create(ctx context.Context, req *types.CreateProviderReques
 		Protocol:    normalizeProtocol(req.Protocol),
 		Description: req.Description,
 		MaxTokens:   resolveMaxTokens(req.MaxTokens, 0),
+		// 上下文窗口为 0 时由助手使用默认值
+		ContextWindow: req.ContextWindow,
 	})
 	if err != nil {
 		klog.Errorf("failed to create assistant provider: %v", err)
@@ -74,6 +79,9 @@ func (c *controller) preUpdate(ctx context.Context, req *types.UpdateProviderReq
 	if err := validateProviderFields(req.Name, req.BaseURL, req.Protocol); err != nil {
 		return err
 	}
+	if err := validateContextWindow(req.ContextWindow, req.MaxTokens); err != nil {
+		return err
+	}
 
 	old, err := c.factory.Assistant().Provider().Get(ctx, req.Id)
 	if err != nil {
@@ -104,6 +112,9 @@ func (c *controller) Update(ctx context.Context, req *types.UpdateProviderReques
 	if req.MaxTokens > 0 {
 		updates["max_tokens"] = req.MaxTokens
 	}
+	if req.ContextWindow > 0 {
+		updates["context_window"] = req.ContextWindow
+	}
 
 	if err := c.factory.Assistant().Provider().Update(ctx, req.Id, req.ResourceVersion, updates); err != nil {
 		if utilerrors.IsRecordNotFound(err) {
@@ -210,12 +221,13 @@ func modelToType(object *model.AIProvider) *types.AIProvider {
 			GmtCreate:   object.GmtCreate,
 			GmtModified: object.GmtModified,
 		},
-		Name:        object.Name,
-		BaseURL:     object.BaseURL,
-		Protocol:    object.Protocol,
-		Description: object.Description,
-		MaxTokens:   resolveMaxTokens(object.MaxTokens, 0),
-		Builtin:     object.Builtin,
+		Name:          object.Name,
+		BaseURL:       object.BaseURL,
+		Protocol:      object.Protocol,
+		Description:   object.Description,
+		MaxTokens:     resolveMaxTokens(object.MaxTokens, 0),
+		ContextWindow: object.ContextWindow,
+		Builtin:       object.Builtin,
 	}
 }
 
@@ -240,13 +252,24 @@ func validateProviderFields(name, baseURL, protocol string) error {
 		return apierrors.NewError(fmt.Errorf("protocol is required"), http.StatusBadRequest)
 	}
 	switch normalizeProtocol(protocol) {
-	case "openai_chat", "openai_responses":
+	case "openai_chat", "openai_responses", "anthropic_messages", "ollama_chat":
 	default:
 		return apierrors.NewError(fmt.Errorf("unsupported protocol %q", protocol), http.StatusBadRequest)
 	}
 	return nil
 }
 
+// validateContextWindow 上下文窗口需要为输出预留 max_tokens
+func validateContextWindow(contextWindow, maxTokens int) error {
+	if contextWindow == 0 {
+		return nil
+	}
+	if contextWindow <= resolveMaxTokens(maxTokens, 0) {
+		return apierrors.NewError(fmt.Errorf("context_window must be greater than max_tokens"), http.StatusBadRequest)
+	}
+	return nil
+}
+
 func normalizeProtocol(protocol string) string {
 	return strings.ToLower(strings.TrimSpace(protocol))
 }
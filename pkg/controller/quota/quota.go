@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/cmd/app/config"
+	ctrlutil "github.com/caoyingjunz/pixiu/pkg/controller/util"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type Interface interface {
+	Create(ctx context.Context, req *types.CreateAIQuotaRequest) error
+	Update(ctx context.Context, req *types.UpdateAIQuotaRequest) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*types.AIQuota, error)
+	List(ctx context.Context, listOption types.ListOptions) (interface{}, error)
+
+	// Check 校验用户、所属租户以及 AI 账号的配额，任一配额在当前周期内耗尽即返回 429。
+	// pending 为本次对话已消耗但尚未入账的 token，工具循环中途校验时传入
+	Check(ctx context.Context, user *model.User, accountId int64, pending int64) error
+}
+
+type controller struct {
+	cc      config.Config
+	factory db.ShareDaoFactory
+}
+
+func New(cfg config.Config, f db.ShareDaoFactory) Interface {
+	return &controller{cc: cfg, factory: f}
+}
+
+func (c *controller) Create(ctx context.Context, req *types.CreateAIQuotaRequest) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+
+	_, err := c.factory.Assistant().Quota().Create(ctx, &model.AIQuota{
+		Scope:       req.Scope,
+		ScopeId:     req.ScopeId,
+		Period:      req.Period,
+		TokenLimit:  req.TokenLimit,
+		Description: req.Description,
+	})
+	if err != nil {
+		if utilerrors.IsUniqueConstraintError(err) {
+			return apierrors.NewError(fmt.Errorf("%s quota for %s %d already exists", req.Period, req.Scope, req.ScopeId), http.StatusConflict)
+		}
+		klog.Errorf("failed to create ai quota: %v", err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Update(ctx context.Context, req *types.UpdateAIQuotaRequest) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"token_limit": req.TokenLimit,
+		"description": req.Description,
+	}
+	if err := c.factory.Assistant().Quota().Update(ctx, req.Id, req.ResourceVersion, updates); err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("ai quota not found or resource version conflict"), http.StatusConflict)
+		}
+		klog.Errorf("failed to update ai quota(%d): %v", req.Id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Delete(ctx context.Context, id int64) error {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return err
+	}
+
+	if err := c.factory.Assistant().Quota().Delete(ctx, id); err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return apierrors.NewError(fmt.Errorf("ai quota not found"), http.StatusNotFound)
+		}
+		klog.Errorf("failed to delete ai quota(%d): %v", id, err)
+		return apierrors.ErrServerInternal
+	}
+	return nil
+}
+
+func (c *controller) Get(ctx context.Context, id int64) (*types.AIQuota, error) {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return nil, err
+	}
+
+	object, err := c.factory.Assistant().Quota().Get(ctx, id)
+	if err != nil {
+		klog.Errorf("failed to get ai quota(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	if object == nil {
+		return nil, apierrors.NewError(fmt.Errorf("ai quota not found"), http.StatusNotFound)
+	}
+	used, err := c.usedTokens(ctx, object, time.Now())
+	if err != nil {
+		klog.Errorf("failed to sum used tokens for ai quota(%d): %v", id, err)
+		return nil, apierrors.ErrServerInternal
+	}
+	return modelToType(object, used), nil
+}
+
+func (c *controller) List(ctx context.Context, listOption types.ListOptions) (interface{}, error) {
+	if err := ctrlutil.RequireRoot(ctx); err != nil {
+		return nil, err
+	}
+	listOption.SetDefaultPageOption()
+
+	pageResult := types.PageResult{
+		PageRequest: types.PageRequest{
+			Page:  listOption.Page,
+			Limit: listOption.Limit,
+		},
+	}
+
+	var err error
+	pageResult.Total, err = c.factory.Assistant().Quota().Count(ctx)
+	if err != nil {
+		klog.Errorf("failed to count ai quotas: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	offset := (listOption.Page - 1) * listOption.Limit
+	objects, err := c.factory.Assistant().Quota().List(ctx,
+		db.WithModifyOrderByDesc(),
+		db.WithOffset(offset),
+		db.WithLimit(listOption.Limit),
+	)
+	if err != nil {
+		klog.Errorf("failed to list ai quotas: %v", err)
+		return nil, apierrors.ErrServerInternal
+	}
+
+	now := time.Now()
+	items := make([]types.AIQuota, 0)
+	for i := range objects {
+		used, err := c.usedTokens(ctx, &objects[i], now)
+		if err != nil {
+			klog.Errorf("failed to sum used tokens for ai quota(%d): %v", objects[i].Id, err)
+			return nil, apierrors.ErrServerInternal
+		}
+		items = append(items, *modelToType(&objects[i], used))
+	}
+	pageResult.Items = items
+
+	return pageResult, nil
+}
+
+func (c *controller) Check(ctx context.Context, user *model.User, accountId int64, pending int64) error {
+	if user == nil {
+		return apierrors.ErrUnauthorized
+	}
+
+	scopes := []struct {
+		scope model.AIQuotaScope
+		id    int64
+	}{
+		{model.AIQuotaScopeUser, user.Id},
+		{model.AIQuotaScopeTenant, user.TenantId},
+		{model.AIQuotaScopeAccount, accountId},
+	}
+	now := time.Now()
+	for _, s := range scopes {
+		if s.id == 0 {
+			continue
+		}
+		quotas, err := c.factory.Assistant().Quota().List(ctx, db.WithAIQuotaScope(s.scope, s.id))
+		if err != nil {
+			klog.Errorf("failed to list ai quotas for %s(%d): %v", s.scope, s.id, err)
+			return apierrors.ErrServerInternal
+		}
+		for i := range quotas {
+			used, err := c.usedTokens(ctx, &quotas[i], now)
+			if err != nil {
+				klog.Errorf("failed to sum used tokens for ai quota(%d): %v", quotas[i].Id, err)
+				return apierrors.ErrServerInternal
+			}
+			used += pending
+			if used >= quotas[i].TokenLimit {
+				return apierrors.NewError(fmt.Errorf("%s %s token quota exhausted: used %d of %d tokens",
+					quotas[i].Period, quotas[i].Scope, used, quotas[i].TokenLimit), http.StatusTooManyRequests)
+			}
+		}
+	}
+	return nil
+}
+
+// usedTokens 统计配额对象在当前周期内已消耗的 token
+func (c *controller) usedTokens(ctx context.Context, quota *model.AIQuota, now time.Time) (int64, error) {
+	opts := []db.Options{db.WithCreatedAfter(PeriodStart(quota.Period, now))}
+	switch quota.Scope {
+	case model.AIQuotaScopeUser:
+		opts = append(opts, db.WithUser(quota.ScopeId))
+	case model.AIQuotaScopeTenant:
+		opts = append(opts, db.WithTenantId(quota.ScopeId))
+	case model.AIQuotaScopeAccount:
+		opts = append(opts, db.WithAIAccountId(quota.ScopeId))
+	default:
+		return 0, fmt.Errorf("unsupported quota scope %q", quota.Scope)
+	}
+	return c.factory.Assistant().Message().SumTokens(ctx, opts...)
+}
+
+// PeriodStart 返回 now 所在统计周期的起点
+func PeriodStart(period model.AIQuotaPeriod, now time.Time) time.Time {
+	year, month, day := now.Date()
+	if period == model.AIQuotaMonthly {
+		return time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+func modelToType(object *model.AIQuota, used int64) *types.AIQuota {
+	return &types.AIQuota{
+		PixiuMeta: types.PixiuMeta{
+			Id:              object.Id,
+			ResourceVersion: object.ResourceVersion,
+		},
+		TimeMeta: types.TimeMeta{
+			GmtCreate:   object.GmtCreate,
+			GmtModified: object.GmtModified,
+		},
+		Scope:       string(object.Scope),
+		ScopeId:     object.ScopeId,
+		Period:      string(object.Period),
+		TokenLimit:  object.TokenLimit,
+		Used:        used,
+		Description: object.Description,
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	apierrors "github.com/caoyingjunz/pixiu/api/server/errors"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+// dryRun 仅用于把查询选项渲染成 SQL，不会连接数据库
+var dryRun, _ = gorm.Open(mysql.New(mysql.Config{
+	DSN:                       "pixiu:pixiu@tcp(127.0.0.1:3306)/pixiu?parseTime=true",
+	SkipInitializeWithVersion: true,
+}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+
+func renderSQL(object interface{}, opts []db.Options) string {
+	tx := dryRun.Model(object)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	stmt := tx.Find(object).Statement
+	return dryRun.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+}
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	assistant *fakeAssistant
+}
+
+func (f *fakeFactory) Assistant() db.AssistantInterface { return f.assistant }
+
+type fakeAssistant struct {
+	db.AssistantInterface
+	quotas   *fakeQuotas
+	messages *fakeMessages
+}
+
+func (a *fakeAssistant) Quota() db.AIQuotaInterface   { return a.quotas }
+func (a *fakeAssistant) Message() db.MessageInterface { return a.messages }
+
+// fakeQuotas 按 "scope = 'x' AND scope_id = n" 条件返回配额
+type fakeQuotas struct {
+	db.AIQuotaInterface
+	items map[string][]model.AIQuota
+}
+
+func (q *fakeQuotas) List(_ context.Context, opts ...db.Options) ([]model.AIQuota, error) {
+	sql := renderSQL(&[]model.AIQuota{}, opts)
+	for cond, items := range q.items {
+		if strings.Contains(sql, cond) {
+			return items, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeMessages 按 "user_id = n" 等条件返回已用 token，并记录统计起点
+type fakeMessages struct {
+	db.MessageInterface
+	used    map[string]int64
+	queries []string
+}
+
+func (m *fakeMessages) SumTokens(_ context.Context, opts ...db.Options) (int64, error) {
+	sql := renderSQL(&[]model.Message{}, opts)
+	m.queries = append(m.queries, sql)
+	for cond, used := range m.used {
+		if strings.Contains(sql, cond) {
+			return used, nil
+		}
+	}
+	return 0, nil
+}
+
+func newQuota(id int64, scope model.AIQuotaScope, scopeId, limit int64) model.AIQuota {
+	return model.AIQuota{
+		Model:      pixiu.Model{Id: id},
+		Scope:      scope,
+		ScopeId:    scopeId,
+		Period:     model.AIQuotaDaily,
+		TokenLimit: limit,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	quotas := &fakeQuotas{items: map[string][]model.AIQuota{
+		"scope = 'user' AND scope_id = 1":    {newQuota(1, model.AIQuotaScopeUser, 1, 1000)},
+		"scope = 'tenant' AND scope_id = 10": {newQuota(2, model.AIQuotaScopeTenant, 10, 5000)},
+		"scope = 'account' AND scope_id = 7": {newQuota(3, model.AIQuotaScopeAccount, 7, 2000)},
+	}}
+	user := &model.User{TenantId: 10}
+	user.Id = 1
+
+	cases := []struct {
+		name    string
+		used    map[string]int64
+		account int64
+		pending int64
+		wantErr string
+	}{
+		{name: "under all limits", used: map[string]int64{"user_id = 1": 500, "tenant_id = 10": 500, "account_id = 7": 500}, account: 7},
+		{name: "user exhausted", used: map[string]int64{"user_id = 1": 1000}, account: 7, wantErr: "daily user token quota exhausted"},
+		{name: "tenant exhausted", used: map[string]int64{"tenant_id = 10": 6000}, account: 7, wantErr: "daily tenant token quota exhausted"},
+		{name: "account exhausted", used: map[string]int64{"account_id = 7": 2000}, account: 7, wantErr: "daily account token quota exhausted"},
+		{name: "account quota ignored for other account", used: map[string]int64{"account_id = 7": 2000}, account: 8},
+		{name: "pending tokens counted", used: map[string]int64{"user_id = 1": 600}, account: 7, pending: 400, wantErr: "used 1000 of 1000"},
+		{name: "pending below limit", used: map[string]int64{"user_id = 1": 600}, account: 7, pending: 399},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := &fakeMessages{used: tc.used}
+			c := &controller{factory: &fakeFactory{assistant: &fakeAssistant{quotas: quotas, messages: messages}}}
+			err := c.Check(context.Background(), user, tc.account, tc.pending)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if apiErr, ok := err.(apierrors.Error); !ok || apiErr.Code != http.StatusTooManyRequests {
+				t.Fatalf("expected 429 error, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestCheckCountsCurrentPeriodOnly(t *testing.T) {
+	quotas := &fakeQuotas{items: map[string][]model.AIQuota{
+		"scope = 'user' AND scope_id = 1": {newQuota(1, model.AIQuotaScopeUser, 1, 1000)},
+	}}
+	messages := &fakeMessages{}
+	c := &controller{factory: &fakeFactory{assistant: &fakeAssistant{quotas: quotas, messages: messages}}}
+	user := &model.User{}
+	user.Id = 1
+	if err := c.Check(context.Background(), user, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages.queries) != 1 {
+		t.Fatalf("expected one usage query, got %d", len(messages.queries))
+	}
+	start := PeriodStart(model.AIQuotaDaily, time.Now()).Format("2006-01-02 15:04:05")
+	if !strings.Contains(messages.queries[0], "gmt_create >= '"+start) {
+		t.Fatalf("usage query %q does not start at %s", messages.queries[0], start)
+	}
+}
+
+func TestCheckRequiresUser(t *testing.T) {
+	c := &controller{}
+	if err := c.Check(context.Background(), nil, 1, 0); err != apierrors.ErrUnauthorized {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2026, 3, 15, 18, 30, 45, 123, loc)
+	cases := []struct {
+		period model.AIQuotaPeriod
+		want   time.Time
+	}{
+		{model.AIQuotaDaily, time.Date(2026, 3, 15, 0, 0, 0, 0, loc)},
+		{model.AIQuotaMonthly, time.Date(2026, 3, 1, 0, 0, 0, 0, loc)},
+		{"", time.Date(2026, 3, 15, 0, 0, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		if got := PeriodStart(tc.period, now); !got.Equal(tc.want) {
+			t.Errorf("PeriodStart(%q) = %v, want %v", tc.period, got, tc.want)
+		}
+	}
+	// 月初当天的日周期与月周期起点一致
+	first := time.Date(2026, 4, 1, 0, 0, 1, 0, loc)
+	if !PeriodStart(model.AIQuotaDaily, first).Equal(PeriodStart(model.AIQuotaMonthly, first)) {
+		t.Error("daily and monthly period should start together on the first day of month")
+	}
+}
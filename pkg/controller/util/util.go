@@ -49,6 +49,18 @@ func EffectiveUserID(ctx context.Context, reqUserID int64) (int64, error) {
 	return user.Id, nil
 }
 
+// RequireRoot 仅允许超级管理员执行，用于全局配置类资源（如 AI 配额、模型价格）的管理。
+func RequireRoot(ctx context.Context) error {
+	user, err := httputils.GetUserFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if user.Role != model.RoleRoot {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
 // CheckResourceOwner 校验当前用户是否有权操作该资源：非超级管理员必须为 owner。
 func CheckResourceOwner(ctx context.Context, resourceOwnerID int64) error {
 	user, err := httputils.GetUserFromContext(ctx)
@@ -21,15 +21,21 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/caoyingjunz/pixiu/pkg/client"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
+	"github.com/caoyingjunz/pixiu/pkg/tunnel"
 )
 
 const defaultHTTPTimeout = 15 * time.Second
@@ -53,16 +59,25 @@ func NewClient(factory db.ShareDaoFactory) *Client {
 	return &Client{
 		factory: factory,
 		httpClient: &http.Client{
-			Timeout: defaultHTTPTimeout,
+			Timeout:   defaultHTTPTimeout,
+			Transport: tracing.NewTransport(http.DefaultTransport),
 		},
 	}
 }
 
 // Do 根据 datasource.external / cluster_name / sub_type 路由请求。
-func (c *Client) Do(ctx context.Context, ds *model.Datasource, req Request) ([]byte, int, error) {
+func (c *Client) Do(ctx context.Context, ds *model.Datasource, req Request) (body []byte, code int, err error) {
 	if ds == nil {
 		return nil, 0, fmt.Errorf("datasource is nil")
 	}
+	ctx, span := tracing.Start(ctx, "datasource.Do",
+		attribute.Int64("pixiu.datasource.id", ds.Id),
+		attribute.String("pixiu.datasource.name", ds.Name),
+		attribute.Bool("pixiu.datasource.external", ds.External),
+		attribute.String("pixiu.datasource.api_path", req.APIPath),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	ep, err := ParseEndpoint(ds)
 	if err != nil {
 		return nil, 0, err
@@ -83,6 +98,11 @@ func (c *Client) doEndpoint(ctx context.Context, ep *Endpoint, req Request) ([]b
 }
 
 func (c *Client) doExternal(ctx context.Context, ep *Endpoint, method string, req Request) ([]byte, int, error) {
+	return c.doHTTP(ctx, c.httpClient, ep, method, req)
+}
+
+// doHTTP 直接向 ep.BaseURL 发送请求，外部数据源与隧道直连共用
+func (c *Client) doHTTP(ctx context.Context, httpClient *http.Client, ep *Endpoint, method string, req Request) ([]byte, int, error) {
 	target, err := url.Parse(ep.BaseURL)
 	if err != nil {
 		return nil, 0, fmt.Errorf("invalid datasource url: %w", err)
@@ -105,7 +125,7 @@ func (c *Client) doExternal(ctx context.Context, ep *Endpoint, method string, re
 	}
 	applyAuthAndHeaders(httpReq, ep, req.Headers)
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := httpClient.Do(httpReq)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -133,7 +153,24 @@ func (c *Client) doInternal(ctx context.Context, ep *Endpoint, method string, re
 	if cluster == nil {
 		return nil, 0, fmt.Errorf("cluster %q not found", ep.ClusterName)
 	}
-	clusterSet, err := client.NewClusterSet(cluster.KubeConfig)
+	// 隧道集群且目标在允许名单内：经 Agent 隧道直连 Service，不依赖 apiserver service proxy
+	if cluster.ConnectMode == model.ConnectModeTunnel {
+		if tm := tunnel.Default(); tm != nil {
+			addr := net.JoinHostPort(inCluster.ServiceName+"."+inCluster.Namespace, strconv.Itoa(inCluster.Port))
+			allowed, err := tm.TargetAllowed(ctx, cluster.Name, addr)
+			if err != nil {
+				return nil, 0, err
+			}
+			if allowed {
+				return c.doHTTP(ctx, tunnelHTTPClient(tm, cluster.Name), ep, method, req)
+			}
+		}
+	}
+
+	clusterSet, err := client.NewClusterSetWithOptions(cluster.KubeConfig, client.ClusterSetOptions{
+		ClusterName: cluster.Name,
+		ConnectMode: cluster.ConnectMode,
+	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to build cluster client: %w", err)
 	}
@@ -167,6 +204,26 @@ func (c *Client) doInternal(ctx context.Context, ep *Endpoint, method string, re
 	return raw, http.StatusOK, nil
 }
 
+// tunnelTransports 按集群缓存隧道 Transport，复用连接；允许名单在每次拨号时校验
+var tunnelTransports sync.Map
+
+func tunnelHTTPClient(tm *tunnel.Manager, clusterName string) *http.Client {
+	rt, ok := tunnelTransports.Load(clusterName)
+	if !ok {
+		rt, _ = tunnelTransports.LoadOrStore(clusterName, &http.Transport{
+			DialContext:           tm.ServiceDialContext(clusterName),
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: defaultHTTPTimeout,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConnsPerHost:   4,
+		})
+	}
+	return &http.Client{
+		Timeout:   defaultHTTPTimeout,
+		Transport: tracing.NewTransport(rt.(*http.Transport)),
+	}
+}
+
 func applyAuthAndHeaders(req *http.Request, ep *Endpoint, extra map[string]string) {
 	for key, value := range ep.Headers {
 		if strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
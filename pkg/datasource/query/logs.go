@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+const (
+	defaultLogLimit = 100
+	maxLogLimit     = 1000
+)
+
+// LogQuery 描述一次日志检索；Query 对 loki 为 LogQL，对 es 为 query_string 语法
+type LogQuery struct {
+	Query string
+	// Index 仅 es 使用，为空时检索全部索引
+	Index string
+	Start time.Time
+	End   time.Time
+	Limit int
+}
+
+// LogEntry 是归一化后的一条日志
+type LogEntry struct {
+	Timestamp time.Time
+	Line      string
+	Labels    map[string]string
+}
+
+// SearchLogs 按数据源 sub_type 检索日志，结果按时间倒序。
+// 集群内数据源只能经 service proxy 发送 GET 请求，因此 es 使用 URI 检索。
+func (c *Client) SearchLogs(ctx context.Context, ds *model.Datasource, q LogQuery) ([]LogEntry, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("datasource is nil")
+	}
+	q.Query = strings.TrimSpace(q.Query)
+	if q.Query == "" {
+		return nil, fmt.Errorf("empty log query")
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLogLimit
+	}
+	if q.Limit > maxLogLimit {
+		q.Limit = maxLogLimit
+	}
+
+	var (
+		apiPath string
+		query   = url.Values{}
+		parse   func([]byte) ([]LogEntry, error)
+	)
+	switch ds.SubType {
+	case model.DatasourceSubTypeLoki:
+		apiPath = "/loki/api/v1/query_range"
+		query.Set("query", q.Query)
+		query.Set("limit", strconv.Itoa(q.Limit))
+		query.Set("direction", "backward")
+		if !q.Start.IsZero() {
+			query.Set("start", strconv.FormatInt(q.Start.UnixNano(), 10))
+		}
+		if !q.End.IsZero() {
+			query.Set("end", strconv.FormatInt(q.End.UnixNano(), 10))
+		}
+		parse = ParseLokiQueryResponse
+	case model.DatasourceSubTypeES:
+		apiPath = "/_search"
+		if index := strings.Trim(strings.TrimSpace(q.Index), "/"); index != "" {
+			apiPath = "/" + index + "/_search"
+		}
+		expr := "(" + q.Query + ")"
+		if !q.Start.IsZero() || !q.End.IsZero() {
+			expr += fmt.Sprintf(" AND @timestamp:[%s TO %s]", esTime(q.Start), esTime(q.End))
+		}
+		query.Set("q", expr)
+		query.Set("size", strconv.Itoa(q.Limit))
+		query.Set("sort", "@timestamp:desc")
+		query.Set("ignore_unavailable", "true")
+		parse = ParseESSearchResponse
+	default:
+		return nil, fmt.Errorf("datasource sub_type %q is not supported for log search", ds.SubType)
+	}
+
+	body, status, err := c.Do(ctx, ds, Request{
+		Method:  http.MethodGet,
+		APIPath: apiPath,
+		Query:   query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%s query status %d: %s", ds.SubType, status, truncateForError(string(body)))
+	}
+	return parse(body)
+}
+
+func esTime(t time.Time) string {
+	if t.IsZero() {
+		return "*"
+	}
+	return `"` + t.UTC().Format(time.RFC3339) + `"`
+}
+
+type lokiQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// ParseLokiQueryResponse parses Loki /loki/api/v1/query_range streams JSON.
+func ParseLokiQueryResponse(body []byte) ([]LogEntry, error) {
+	var resp lokiQueryResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid loki response: %w", err)
+	}
+	if !strings.EqualFold(resp.Status, "success") {
+		msg := strings.TrimSpace(resp.Error)
+		if msg == "" {
+			msg = "unknown loki error"
+		}
+		return nil, fmt.Errorf("loki status=%s error=%s", resp.Status, msg)
+	}
+	if resp.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("loki result type %q is not a log stream, use a log query instead of a metric query", resp.Data.ResultType)
+	}
+
+	var entries []LogEntry
+	for _, stream := range resp.Data.Result {
+		for _, value := range stream.Values {
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, LogEntry{
+				Timestamp: time.Unix(0, ns),
+				Line:      value[1],
+				Labels:    cloneStringMap(stream.Stream),
+			})
+		}
+	}
+	sortLogEntries(entries)
+	return entries, nil
+}
+
+type esSearchResponse struct {
+	Error interface{} `json:"error"`
+	Hits  struct {
+		Hits []struct {
+			Index  string                 `json:"_index"`
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// ParseESSearchResponse parses Elasticsearch _search JSON, taking message / log as the log line.
+func ParseESSearchResponse(body []byte) ([]LogEntry, error) {
+	var resp esSearchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid es response: %w", err)
+	}
+	if resp.Error != nil {
+		raw, _ := json.Marshal(resp.Error)
+		return nil, fmt.Errorf("es error: %s", truncateForError(string(raw)))
+	}
+
+	entries := make([]LogEntry, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		entry := LogEntry{Labels: map[string]string{"_index": hit.Index}}
+		for _, key := range []string{"message", "log", "msg"} {
+			if line, ok := hit.Source[key].(string); ok {
+				entry.Line = line
+				break
+			}
+		}
+		if entry.Line == "" {
+			raw, _ := json.Marshal(hit.Source)
+			entry.Line = string(raw)
+		}
+		if ts, ok := hit.Source["@timestamp"].(string); ok {
+			entry.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+		}
+		if k8s, ok := hit.Source["kubernetes"].(map[string]interface{}); ok {
+			for _, key := range []string{"namespace_name", "pod_name", "container_name"} {
+				if v, ok := k8s[key].(string); ok {
+					entry.Labels[key] = v
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sortLogEntries(entries)
+	return entries, nil
+}
+
+func sortLogEntries(entries []LogEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+}
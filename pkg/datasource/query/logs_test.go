@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLokiQueryResponse(t *testing.T) {
+	body := []byte(`{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"namespace":"web","pod":"web-0"},"values":[["1700000000000000000","older"],["1700000002000000000","newest"]]},
+		{"stream":{"namespace":"web","pod":"web-1"},"values":[["1700000001000000000","middle"]]}
+	]}}`)
+
+	entries, err := ParseLokiQueryResponse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Line != "newest" || entries[1].Line != "middle" || entries[2].Line != "older" {
+		t.Errorf("entries should be sorted newest first: %+v", entries)
+	}
+	if entries[1].Labels["pod"] != "web-1" {
+		t.Errorf("unexpected labels: %v", entries[1].Labels)
+	}
+
+	if _, err = ParseLokiQueryResponse([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`)); err == nil {
+		t.Errorf("metric query result should be rejected")
+	}
+}
+
+func TestParseESSearchResponse(t *testing.T) {
+	body := []byte(`{"hits":{"hits":[
+		{"_index":"logs-1","_source":{"@timestamp":"2026-01-02T03:04:05Z","message":"boom","kubernetes":{"namespace_name":"web","pod_name":"web-0"}}},
+		{"_index":"logs-1","_source":{"@timestamp":"2026-01-02T03:04:06Z","level":"info"}}
+	]}}`)
+
+	entries, err := ParseESSearchResponse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Line != `{"@timestamp":"2026-01-02T03:04:06Z","level":"info"}` {
+		t.Errorf("source without message should be returned as json: %q", entries[0].Line)
+	}
+	if entries[1].Line != "boom" || entries[1].Labels["pod_name"] != "web-0" {
+		t.Errorf("unexpected entry: %+v", entries[1])
+	}
+	if !entries[1].Timestamp.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected timestamp: %v", entries[1].Timestamp)
+	}
+
+	if _, err = ParseESSearchResponse([]byte(`{"error":{"type":"index_not_found_exception"}}`)); err == nil {
+		t.Errorf("es error should be returned")
+	}
+}
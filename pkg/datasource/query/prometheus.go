@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 )
@@ -78,6 +79,53 @@ func (c *Client) prometheusInstantQuery(ctx context.Context, ds *model.Datasourc
 	return ParsePrometheusQueryResponse(body)
 }
 
+// MetricSeries 是区间查询返回的一条时间序列
+type MetricSeries struct {
+	Labels map[string]string
+	Points []MetricPoint
+}
+
+// MetricPoint 是时间序列上的一个采样点
+type MetricPoint struct {
+	Timestamp time.Time
+	Value     string
+}
+
+// RangeQuery 按数据源 sub_type 执行区间查询（目前仅 prometheus）。
+func (c *Client) RangeQuery(ctx context.Context, ds *model.Datasource, expression string, start, end time.Time, step time.Duration) ([]MetricSeries, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("datasource is nil")
+	}
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("empty query expression")
+	}
+	if !end.After(start) || step <= 0 {
+		return nil, fmt.Errorf("invalid range: start=%s end=%s step=%s", start, end, step)
+	}
+	if ds.SubType != model.DatasourceSubTypePrometheus {
+		return nil, fmt.Errorf("datasource sub_type %q is not supported for metric range query", ds.SubType)
+	}
+
+	query := url.Values{}
+	query.Set("query", expression)
+	query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	query.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	body, status, err := c.Do(ctx, ds, Request{
+		Method:  http.MethodGet,
+		APIPath: "/api/v1/query_range",
+		Query:   query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("prometheus query status %d: %s", status, truncateForError(string(body)))
+	}
+	return ParsePrometheusRangeResponse(body)
+}
+
 type prometheusAPIResponse struct {
 	Status    string `json:"status"`
 	ErrorType string `json:"errorType"`
@@ -149,6 +197,54 @@ func ParsePrometheusQueryResponse(body []byte) ([]MetricSample, error) {
 	}
 }
 
+type prometheusMatrixItem struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values"`
+}
+
+// ParsePrometheusRangeResponse parses Prometheus /api/v1/query_range JSON.
+func ParsePrometheusRangeResponse(body []byte) ([]MetricSeries, error) {
+	var resp prometheusAPIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid prometheus response: %w", err)
+	}
+	if !strings.EqualFold(resp.Status, "success") {
+		msg := strings.TrimSpace(resp.Error)
+		if msg == "" {
+			msg = "unknown prometheus error"
+		}
+		return nil, fmt.Errorf("prometheus status=%s error=%s", resp.Status, msg)
+	}
+	if resp.Data.ResultType != "matrix" {
+		return nil, nil
+	}
+
+	var items []prometheusMatrixItem
+	if err := json.Unmarshal(resp.Data.Result, &items); err != nil {
+		return nil, fmt.Errorf("invalid prometheus matrix: %w", err)
+	}
+	series := make([]MetricSeries, 0, len(items))
+	for _, item := range items {
+		points := make([]MetricPoint, 0, len(item.Values))
+		for _, pair := range item.Values {
+			value, ok := prometheusSampleValue(pair)
+			if !ok {
+				continue
+			}
+			ts, ok := pair[0].(float64)
+			if !ok {
+				continue
+			}
+			points = append(points, MetricPoint{
+				Timestamp: time.Unix(0, int64(ts*float64(time.Second))),
+				Value:     value,
+			})
+		}
+		series = append(series, MetricSeries{Labels: cloneStringMap(item.Metric), Points: points})
+	}
+	return series, nil
+}
+
 func prometheusSampleValue(pair []interface{}) (string, bool) {
 	if len(pair) < 2 {
 		return "", false
@@ -0,0 +1,169 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestParsePrometheusRangeResponse(t *testing.T) {
+	body := []byte(`{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"pod":"web-0"},"values":[[1700000000,"1"],[1700000060,"NaN"],[1700000120,"3"]]}
+	]}}`)
+
+	series, err := ParsePrometheusRangeResponse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || len(series[0].Points) != 2 {
+		t.Fatalf("unexpected series: %+v", series)
+	}
+	if series[0].Points[1].Value != "3" || series[0].Points[1].Timestamp.Unix() != 1700000120 {
+		t.Errorf("unexpected point: %+v", series[0].Points[1])
+	}
+	if series[0].Labels["pod"] != "web-0" {
+		t.Errorf("unexpected labels: %v", series[0].Labels)
+	}
+}
+
+func TestParsePrometheusRangeResponseEdgeCases(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		series  int
+		points  int
+		wantErr bool
+	}{
+		{name: "error status", body: `{"status":"error","errorType":"bad_data","error":"parse error"}`, wantErr: true},
+		{name: "invalid json", body: `not json`, wantErr: true},
+		{name: "invalid matrix", body: `{"status":"success","data":{"resultType":"matrix","result":{}}}`, wantErr: true},
+		{name: "vector result is ignored", body: `{"status":"success","data":{"resultType":"vector","result":[]}}`},
+		{name: "empty matrix", body: `{"status":"success","data":{"resultType":"matrix","result":[]}}`},
+		{
+			name:   "malformed samples are skipped",
+			body:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[["x","1"],[1700000000],[1700000060,"+Inf"],[1700000120.5,"2"]]}]}}`,
+			series: 1,
+			points: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		series, err := ParsePrometheusRangeResponse([]byte(tc.body))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if len(series) != tc.series {
+			t.Errorf("%s: expected %d series, got %+v", tc.name, tc.series, series)
+			continue
+		}
+		if tc.series > 0 && len(series[0].Points) != tc.points {
+			t.Errorf("%s: expected %d points, got %+v", tc.name, tc.points, series[0].Points)
+		}
+	}
+}
+
+func prometheusDatasource(url string) *model.Datasource {
+	return &model.Datasource{
+		Name:     "prometheus",
+		Type:     model.DatasourceTypeAlert,
+		SubType:  model.DatasourceSubTypePrometheus,
+		External: true,
+		Config:   `{"alert":{"url":"` + url + `/","user_name":"admin","password":"secret"}}`,
+	}
+}
+
+func TestRangeQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("query") != "up" || q.Get("start") != "1700000000" || q.Get("end") != "1700003600" || q.Get("step") != "60" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","error":"unexpected query ` + r.URL.RawQuery + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"node"},"values":[[1700000000,"1"],[1700000060,"1"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	start := time.Unix(1700000000, 0)
+	end := start.Add(time.Hour)
+	c := NewClient(nil)
+
+	series, err := c.RangeQuery(context.TODO(), prometheusDatasource(server.URL), " up ", start, end, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(series) != 1 || series[0].Labels["job"] != "node" || len(series[0].Points) != 2 {
+		t.Errorf("unexpected series: %+v", series)
+	}
+
+	// 非 2xx 状态码返回错误并附带响应内容
+	_, err = c.RangeQuery(context.TODO(), prometheusDatasource(server.URL), "down", start, end, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+func TestRangeQueryInvalidArguments(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	loki := prometheusDatasource("http://127.0.0.1:0")
+	loki.SubType = model.DatasourceSubTypeLoki
+
+	cases := []struct {
+		name string
+		ds   *model.Datasource
+		expr string
+		end  time.Time
+		step time.Duration
+	}{
+		{name: "nil datasource", expr: "up", end: start.Add(time.Hour), step: time.Minute},
+		{name: "empty expression", ds: prometheusDatasource("http://127.0.0.1:0"), expr: " ", end: start.Add(time.Hour), step: time.Minute},
+		{name: "end before start", ds: prometheusDatasource("http://127.0.0.1:0"), expr: "up", end: start, step: time.Minute},
+		{name: "zero step", ds: prometheusDatasource("http://127.0.0.1:0"), expr: "up", end: start.Add(time.Hour)},
+		{name: "unsupported sub type", ds: loki, expr: "up", end: start.Add(time.Hour), step: time.Minute},
+	}
+
+	c := NewClient(nil)
+	for _, tc := range cases {
+		if _, err := c.RangeQuery(context.TODO(), tc.ds, tc.expr, start, tc.end, tc.step); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
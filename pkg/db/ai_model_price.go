@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type AIModelPriceInterface interface {
+	Create(ctx context.Context, object *model.AIModelPrice) (*model.AIModelPrice, error)
+	Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*model.AIModelPrice, error)
+	GetByModel(ctx context.Context, providerId int64, modelName string) (*model.AIModelPrice, error)
+	List(ctx context.Context, opts ...Options) ([]model.AIModelPrice, error)
+	Count(ctx context.Context, opts ...Options) (int64, error)
+}
+
+type aiModelPrice struct {
+	db *gorm.DB
+}
+
+func newAIModelPrice(db *gorm.DB) AIModelPriceInterface {
+	return &aiModelPrice{db}
+}
+
+func (a *aiModelPrice) Create(ctx context.Context, object *model.AIModelPrice) (*model.AIModelPrice, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := a.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (a *aiModelPrice) Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := a.db.WithContext(ctx).Model(&model.AIModelPrice{}).Where("id = ? and resource_version = ?", id, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *aiModelPrice) Delete(ctx context.Context, id int64) error {
+	f := a.db.WithContext(ctx).Where("id = ?", id).Delete(&model.AIModelPrice{})
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *aiModelPrice) Get(ctx context.Context, id int64) (*model.AIModelPrice, error) {
+	var object model.AIModelPrice
+	if err := a.db.WithContext(ctx).Where("id = ?", id).First(&object).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (a *aiModelPrice) GetByModel(ctx context.Context, providerId int64, modelName string) (*model.AIModelPrice, error) {
+	var object model.AIModelPrice
+	if err := a.db.WithContext(ctx).Where("provider_id = ? AND model = ?", providerId, modelName).First(&object).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (a *aiModelPrice) List(ctx context.Context, opts ...Options) ([]model.AIModelPrice, error) {
+	var objects []model.AIModelPrice
+	tx := a.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (a *aiModelPrice) Count(ctx context.Context, opts ...Options) (int64, error) {
+	var total int64
+	tx := a.db.WithContext(ctx).Model(&model.AIModelPrice{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
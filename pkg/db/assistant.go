@@ -25,6 +25,8 @@ type AssistantInterface interface {
 	Conversation() ConversationInterface
 	Execution() ExecutionInterface
 	Message() MessageInterface
+	Quota() AIQuotaInterface
+	ModelPrice() AIModelPriceInterface
 }
 
 type assistant struct {
@@ -54,3 +56,11 @@ func (a *assistant) Execution() ExecutionInterface {
 func (a *assistant) Message() MessageInterface {
 	return newMessage(a.db)
 }
+
+func (a *assistant) Quota() AIQuotaInterface {
+	return newAIQuota(a.db)
+}
+
+func (a *assistant) ModelPrice() AIModelPriceInterface {
+	return newAIModelPrice(a.db)
+}
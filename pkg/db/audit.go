@@ -21,18 +21,31 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
 	"github.com/caoyingjunz/pixiu/pkg/util/errors"
 )
 
 type AuditInterface interface {
 	List(ctx context.Context, opts ...Options) ([]model.Audit, error)
 	Get(ctx context.Context, id int64) (*model.Audit, error)
+	// Create 写入审计记录并链接到哈希链
 	Create(ctx context.Context, object *model.Audit) (*model.Audit, error)
 	BatchDelete(ctx context.Context, opts ...Options) (int64, error)
 
 	Count(ctx context.Context, opts ...Options) (int64, error)
+
+	// TerminalSession 交互式终端会话录像
+	TerminalSession() TerminalSessionInterface
+	// ObjectDiff 经集群代理更新 K8s 对象的前后差异
+	ObjectDiff() AuditObjectDiffInterface
+
+	// GetChainHead 获取哈希链链头，尚未写入链式记录时返回 nil
+	GetChainHead(ctx context.Context) (*model.AuditChainHead, error)
+	// Checkpoint 哈希链签名检查点与清理锚点
+	Checkpoint() AuditCheckpointInterface
 }
 
 type audit struct {
@@ -43,17 +56,81 @@ func newAudit(db *gorm.DB) AuditInterface {
 	return &audit{db: db}
 }
 
+func (a *audit) TerminalSession() TerminalSessionInterface {
+	return newTerminalSession(a.db)
+}
+
+func (a *audit) ObjectDiff() AuditObjectDiffInterface {
+	return newAuditObjectDiff(a.db)
+}
+
+func (a *audit) Checkpoint() AuditCheckpointInterface {
+	return newAuditCheckpoint(a.db)
+}
+
 func (a *audit) Create(ctx context.Context, object *model.Audit) (*model.Audit, error) {
-	now := time.Now()
+	// datetime 列精度为秒，截断后哈希与落库内容一致
+	now := time.Now().Truncate(time.Second)
 	object.GmtCreate = now
 	object.GmtModified = now
 
-	if err := a.db.WithContext(ctx).Create(object).Error; err != nil {
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head, err := lockChainHead(tx)
+		if err != nil {
+			return err
+		}
+
+		object.PrevHash = head.LastHash
+		object.Hash = object.ComputeHash()
+		if err = tx.Create(object).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.AuditChainHead{}).Where("id = ?", head.Id).Updates(map[string]interface{}{
+			"last_audit_id": object.Id,
+			"last_hash":     object.Hash,
+			"gmt_modified":  now,
+		}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return object, nil
 }
 
+// lockChainHead 以 SELECT ... FOR UPDATE 锁定链头，多副本并发写入时保证链接顺序与 id 顺序一致
+func lockChainHead(tx *gorm.DB) (*model.AuditChainHead, error) {
+	var head model.AuditChainHead
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", model.AuditChainHeadId).First(&head).Error
+	if err == nil {
+		return &head, nil
+	}
+	if !errors.IsRecordNotFound(err) {
+		return nil, err
+	}
+
+	// 首次写入时初始化链头，并发初始化由主键冲突忽略
+	now := time.Now()
+	object := &model.AuditChainHead{Model: pixiu.Model{Id: model.AuditChainHeadId, GmtCreate: now, GmtModified: now}}
+	if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(object).Error; err != nil {
+		return nil, err
+	}
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", model.AuditChainHeadId).First(&head).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+func (a *audit) GetChainHead(ctx context.Context) (*model.AuditChainHead, error) {
+	var head model.AuditChainHead
+	if err := a.db.WithContext(ctx).Where("id = ?", model.AuditChainHeadId).First(&head).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &head, nil
+}
+
 func (a *audit) Get(ctx context.Context, aid int64) (*model.Audit, error) {
 	var object model.Audit
 	if err := a.db.WithContext(ctx).Where("id = ?", aid).First(&object).Error; err != nil {
@@ -84,8 +161,8 @@ func (a *audit) BatchDelete(ctx context.Context, opts ...Options) (int64, error)
 		tx = opt(tx)
 	}
 
-	err := tx.Delete(&model.Audit{}).Error
-	return tx.RowsAffected, err
+	result := tx.Delete(&model.Audit{})
+	return result.RowsAffected, result.Error
 }
 
 func (a *audit) Count(ctx context.Context, opts ...Options) (int64, error) {
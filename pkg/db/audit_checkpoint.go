@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type AuditCheckpointInterface interface {
+	Create(ctx context.Context, object *model.AuditCheckpoint) (*model.AuditCheckpoint, error)
+	List(ctx context.Context, opts ...Options) ([]model.AuditCheckpoint, error)
+	// GetLatest 获取指定类型链上位置最靠后的检查点，不存在时返回 nil
+	GetLatest(ctx context.Context, kind model.AuditCheckpointKind) (*model.AuditCheckpoint, error)
+}
+
+type auditCheckpoint struct {
+	db *gorm.DB
+}
+
+func newAuditCheckpoint(db *gorm.DB) AuditCheckpointInterface {
+	return &auditCheckpoint{db: db}
+}
+
+func (a *auditCheckpoint) Create(ctx context.Context, object *model.AuditCheckpoint) (*model.AuditCheckpoint, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	if err := a.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (a *auditCheckpoint) List(ctx context.Context, opts ...Options) ([]model.AuditCheckpoint, error) {
+	var objects []model.AuditCheckpoint
+	tx := a.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (a *auditCheckpoint) GetLatest(ctx context.Context, kind model.AuditCheckpointKind) (*model.AuditCheckpoint, error) {
+	var object model.AuditCheckpoint
+	if err := a.db.WithContext(ctx).Where("kind = ?", kind).Order("audit_id DESC, id DESC").First(&object).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+type AuditObjectDiffInterface interface {
+	Create(ctx context.Context, object *model.AuditObjectDiff) (*model.AuditObjectDiff, error)
+	List(ctx context.Context, opts ...Options) ([]model.AuditObjectDiff, error)
+	BatchDelete(ctx context.Context, opts ...Options) (int64, error)
+}
+
+type auditObjectDiff struct {
+	db *gorm.DB
+}
+
+func newAuditObjectDiff(db *gorm.DB) AuditObjectDiffInterface {
+	return &auditObjectDiff{db: db}
+}
+
+func (a *auditObjectDiff) Create(ctx context.Context, object *model.AuditObjectDiff) (*model.AuditObjectDiff, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+
+	if err := a.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (a *auditObjectDiff) List(ctx context.Context, opts ...Options) ([]model.AuditObjectDiff, error) {
+	var objects []model.AuditObjectDiff
+	tx := a.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (a *auditObjectDiff) BatchDelete(ctx context.Context, opts ...Options) (int64, error) {
+	tx := a.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+
+	result := tx.Delete(&model.AuditObjectDiff{})
+	return result.RowsAffected, result.Error
+}
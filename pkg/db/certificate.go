@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+type CertificateInterface interface {
+	// Replace 使用最新巡检结果整体替换部署计划的证书记录
+	Replace(ctx context.Context, planId int64, objects []model.PlanCertificate) error
+	List(ctx context.Context, planId int64) ([]model.PlanCertificate, error)
+	DeleteByPlan(ctx context.Context, planId int64) error
+}
+
+type certificate struct {
+	db *gorm.DB
+}
+
+func newCertificate(db *gorm.DB) CertificateInterface {
+	return &certificate{db: db}
+}
+
+func (c *certificate) Replace(ctx context.Context, planId int64, objects []model.PlanCertificate) error {
+	now := time.Now()
+	for i := range objects {
+		objects[i].PlanId = planId
+		objects[i].GmtCreate = now
+		objects[i].GmtModified = now
+	}
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", planId).Delete(&model.PlanCertificate{}).Error; err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		return tx.Create(&objects).Error
+	})
+}
+
+func (c *certificate) List(ctx context.Context, planId int64) ([]model.PlanCertificate, error) {
+	var objects []model.PlanCertificate
+	if err := c.db.WithContext(ctx).Where("plan_id = ?", planId).Order("not_after").Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (c *certificate) DeleteByPlan(ctx context.Context, planId int64) error {
+	return c.db.WithContext(ctx).Where("plan_id = ?", planId).Delete(&model.PlanCertificate{}).Error
+}
@@ -23,6 +23,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
 	"github.com/caoyingjunz/pixiu/pkg/util/errors"
 )
 
@@ -41,8 +42,15 @@ type ClusterInterface interface {
 	UpdateByPlan(ctx context.Context, planId int64, updates map[string]interface{}) error
 
 	AccessToken() AccessTokenInterface
+	Event() ClusterEventInterface
 }
 
+// 集群创建与部署计划写入的健康事件来源，syncer 写入的事件以任务名为来源
+const (
+	clusterEventSourceCreate = "cluster-create"
+	clusterEventSourcePlan   = "deploy-plan"
+)
+
 type cluster struct {
 	db *gorm.DB
 }
@@ -56,6 +64,18 @@ func (c *cluster) Create(ctx context.Context, object *model.Cluster, fns ...func
 		if err := tx.Create(object).Error; err != nil {
 			return err
 		}
+		// 记录集群的初始状态，可用率统计以此作为窗口起点的状态
+		if err := tx.Create(&model.ClusterEvent{
+			ClusterId:   object.Id,
+			ClusterName: object.Name,
+			Type:        model.ClusterEventStatus,
+			Source:      clusterEventSourceCreate,
+			To:          model.ClusterStatusName(object.ClusterStatus),
+			Reason:      "Created",
+			Model:       pixiu.Model{GmtCreate: now, GmtModified: now},
+		}).Error; err != nil {
+			return err
+		}
 
 		for _, fn := range fns {
 			if err := fn(object); err != nil {
@@ -175,18 +195,55 @@ func (c *cluster) GetBy(ctx context.Context, opts ...Options) (*model.Cluster, e
 	return &object, nil
 }
 
+// UpdateByPlan 更新部署计划关联的集群，状态发生变化时在同一事务内记录状态事件
 func (c *cluster) UpdateByPlan(ctx context.Context, planId int64, updates map[string]interface{}) error {
-	updates["gmt_modified"] = time.Now()
+	now := time.Now()
+	updates["gmt_modified"] = now
 
-	f := c.db.WithContext(ctx).Model(&model.Cluster{}).Where("plan_id = ?", planId).Updates(updates)
-	if f.Error != nil {
-		return f.Error
-	}
-	if f.RowsAffected == 0 {
-		return errors.ErrRecordNotUpdate
-	}
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before []model.Cluster
+		status, statusChanged := updates["status"].(model.ClusterStatus)
+		if statusChanged {
+			if err := tx.Select("id", "name", "status").Where("plan_id = ?", planId).Find(&before).Error; err != nil {
+				return err
+			}
+		}
 
-	return nil
+		f := tx.Model(&model.Cluster{}).Where("plan_id = ?", planId).Updates(updates)
+		if f.Error != nil {
+			return f.Error
+		}
+		if f.RowsAffected == 0 {
+			return errors.ErrRecordNotUpdate
+		}
+
+		events := planStatusEvents(before, status, now)
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.Create(events).Error
+	})
+}
+
+// planStatusEvents 为状态发生变化的集群生成状态事件
+func planStatusEvents(before []model.Cluster, status model.ClusterStatus, now time.Time) []*model.ClusterEvent {
+	var events []*model.ClusterEvent
+	for _, object := range before {
+		if object.ClusterStatus == status {
+			continue
+		}
+		events = append(events, &model.ClusterEvent{
+			ClusterId:   object.Id,
+			ClusterName: object.Name,
+			Type:        model.ClusterEventStatus,
+			Source:      clusterEventSourcePlan,
+			From:        model.ClusterStatusName(object.ClusterStatus),
+			To:          model.ClusterStatusName(status),
+			Reason:      "PlanStatusChanged",
+			Model:       pixiu.Model{GmtCreate: now, GmtModified: now},
+		})
+	}
+	return events
 }
 
 func newCluster(db *gorm.DB) ClusterInterface {
@@ -196,3 +253,7 @@ func newCluster(db *gorm.DB) ClusterInterface {
 func (c *cluster) AccessToken() AccessTokenInterface {
 	return newAccessToken(c.db)
 }
+
+func (c *cluster) Event() ClusterEventInterface {
+	return newClusterEvent(c.db)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+type ClusterEventInterface interface {
+	Create(ctx context.Context, object *model.ClusterEvent) (*model.ClusterEvent, error)
+	BatchCreate(ctx context.Context, objects []*model.ClusterEvent) error
+	List(ctx context.Context, opts ...Options) ([]model.ClusterEvent, error)
+	Count(ctx context.Context, opts ...Options) (int64, error)
+	Delete(ctx context.Context, opts ...Options) (int64, error)
+}
+
+type clusterEvent struct {
+	db *gorm.DB
+}
+
+func newClusterEvent(db *gorm.DB) ClusterEventInterface {
+	return &clusterEvent{db: db}
+}
+
+func (c *clusterEvent) Create(ctx context.Context, object *model.ClusterEvent) (*model.ClusterEvent, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := c.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (c *clusterEvent) BatchCreate(ctx context.Context, objects []*model.ClusterEvent) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, object := range objects {
+		object.GmtCreate = now
+		object.GmtModified = now
+	}
+	return c.db.WithContext(ctx).Create(objects).Error
+}
+
+func (c *clusterEvent) List(ctx context.Context, opts ...Options) ([]model.ClusterEvent, error) {
+	var objects []model.ClusterEvent
+	tx := c.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (c *clusterEvent) Count(ctx context.Context, opts ...Options) (int64, error) {
+	tx := c.db.WithContext(ctx).Model(&model.ClusterEvent{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (c *clusterEvent) Delete(ctx context.Context, opts ...Options) (int64, error) {
+	tx := c.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	f := tx.Delete(&model.ClusterEvent{})
+	if f.Error != nil {
+		return 0, f.Error
+	}
+	return f.RowsAffected, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+func TestPlanStatusEvents(t *testing.T) {
+	now := time.Now()
+	clusters := func(statuses ...model.ClusterStatus) []model.Cluster {
+		var objects []model.Cluster
+		for i, status := range statuses {
+			object := model.Cluster{Name: "c", ClusterStatus: status}
+			object.Id = int64(i + 1)
+			objects = append(objects, object)
+		}
+		return objects
+	}
+
+	tests := []struct {
+		name   string
+		before []model.Cluster
+		status model.ClusterStatus
+		want   []string
+	}{
+		{name: "no cluster", status: model.ClusterStatusRunning},
+		{name: "unchanged", before: clusters(model.ClusterStatusDeploy), status: model.ClusterStatusDeploy},
+		{name: "deploy started", before: clusters(model.ClusterStatusUnStart), status: model.ClusterStatusDeploy, want: []string{"UnStart->Deploying"}},
+		{
+			name:   "only changed clusters",
+			before: clusters(model.ClusterStatusDeploy, model.ClusterStatusFailed),
+			status: model.ClusterStatusFailed,
+			want:   []string{"Deploying->Failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := planStatusEvents(tt.before, tt.status, now)
+			if len(events) != len(tt.want) {
+				t.Fatalf("expected %d events, got %d", len(tt.want), len(events))
+			}
+			for i, e := range events {
+				if got := e.From + "->" + e.To; got != tt.want[i] {
+					t.Errorf("expected %s, got %s", tt.want[i], got)
+				}
+				if e.Type != model.ClusterEventStatus || e.Source != clusterEventSourcePlan || !e.GmtCreate.Equal(now) {
+					t.Errorf("unexpected event %+v", e)
+				}
+			}
+		})
+	}
+}
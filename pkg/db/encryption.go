@@ -0,0 +1,234 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/envelope"
+)
+
+// encryptedTagSetting 敏感字段标记，如 gorm:"column:api_key;type:text;encrypted"
+const encryptedTagSetting = "ENCRYPTED"
+
+type secretCodec struct {
+	env *envelope.Envelope
+}
+
+// RegisterEncryption 注册 gorm 回调，对标记 encrypted 的字段在写入前加密、读取后解密。
+// 结构体与 map 方式的写入均会加密，写入完成后调用方持有的结构体恢复为明文；历史明文数据读取时原样返回。
+func RegisterEncryption(db *gorm.DB, env *envelope.Envelope) error {
+	c := &secretCodec{env: env}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("pixiu:encrypt_secrets", c.encrypt); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("pixiu:decrypt_secrets", c.decrypt); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("pixiu:encrypt_secrets", c.encrypt); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("pixiu:decrypt_secrets", c.decrypt); err != nil {
+		return err
+	}
+	return cb.Query().After("gorm:query").Register("pixiu:decrypt_secrets", c.decrypt)
+}
+
+func (c *secretCodec) encrypt(db *gorm.DB) {
+	stmt := db.Statement
+	fields := secretFields(stmt.Schema)
+	if len(fields) == 0 {
+		return
+	}
+
+	c.transformObjects(db, fields, c.env.Encrypt)
+
+	// map 方式更新（Updates(map[string]interface{}{...})）不经过结构体字段，需单独加密，
+	// 复制一份避免修改调用方的 map
+	if dest, ok := stmt.Dest.(map[string]interface{}); ok {
+		values := make(map[string]interface{}, len(dest))
+		for k, v := range dest {
+			if s, ok := v.(string); ok {
+				if field := stmt.Schema.LookUpField(k); field != nil && isSecretField(field) {
+					enc, err := c.env.Encrypt(s)
+					if err != nil {
+						_ = db.AddError(fmt.Errorf("failed to encrypt %s.%s: %v", stmt.Schema.Table, field.DBName, err))
+						return
+					}
+					v = enc
+				}
+			}
+			values[k] = v
+		}
+		stmt.Dest = values
+	}
+}
+
+func (c *secretCodec) decrypt(db *gorm.DB) {
+	fields := secretFields(db.Statement.Schema)
+	if len(fields) == 0 {
+		return
+	}
+	c.transformObjects(db, fields, c.env.Decrypt)
+}
+
+// transformObjects 处理语句涉及的模型对象，包括 Model 与结构体形式的 Dest
+func (c *secretCodec) transformObjects(db *gorm.DB, fields []*schema.Field, fn func(string) (string, error)) {
+	stmt := db.Statement
+	c.transform(db, stmt.ReflectValue, fields, fn)
+	if stmt.Dest != nil {
+		c.transform(db, reflect.Indirect(reflect.ValueOf(stmt.Dest)), fields, fn)
+	}
+}
+
+func (c *secretCodec) transform(db *gorm.DB, rv reflect.Value, fields []*schema.Field, fn func(string) (string, error)) {
+	stmt := db.Statement
+	modelType := stmt.Schema.ModelType
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			c.transform(db, reflect.Indirect(rv.Index(i)), fields, fn)
+		}
+	case reflect.Struct:
+		if rv.Type() != modelType {
+			return
+		}
+		for _, field := range fields {
+			fv := field.ReflectValueOf(stmt.Context, rv)
+			if fv.Kind() != reflect.String || !fv.CanSet() || fv.String() == "" {
+				continue
+			}
+			out, err := fn(fv.String())
+			if err != nil {
+				_ = db.AddError(fmt.Errorf("%s.%s: %v", stmt.Schema.Table, field.DBName, err))
+				return
+			}
+			fv.SetString(out)
+		}
+	}
+}
+
+func secretFields(s *schema.Schema) []*schema.Field {
+	if s == nil {
+		return nil
+	}
+	var fields []*schema.Field
+	for _, field := range s.Fields {
+		if isSecretField(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func isSecretField(field *schema.Field) bool {
+	_, ok := field.TagSettings[encryptedTagSetting]
+	return ok && field.DBName != ""
+}
+
+// ReencryptSecrets 重新加密所有模型的敏感字段，返回更新的字段数。
+// plaintextOnly 为 true 时仅加密历史明文数据（启动迁移）；否则同时将非主密钥加密的数据以主密钥重新加密（密钥轮换）。
+func ReencryptSecrets(ctx context.Context, db *gorm.DB, env *envelope.Envelope, plaintextOnly bool) (int64, error) {
+	var total int64
+	for _, m := range model.GetMigrationModels() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return total, err
+		}
+		s := stmt.Schema
+		if s.PrioritizedPrimaryField == nil {
+			continue
+		}
+		for _, field := range secretFields(s) {
+			n, err := reencryptColumn(ctx, db, env, s.Table, s.PrioritizedPrimaryField.DBName, field.DBName, plaintextOnly)
+			total += n
+			if err != nil {
+				return total, fmt.Errorf("failed to re-encrypt %s.%s: %v", s.Table, field.DBName, err)
+			}
+		}
+	}
+	return total, nil
+}
+
+func reencryptColumn(ctx context.Context, db *gorm.DB, env *envelope.Envelope, table, pk, column string, plaintextOnly bool) (int64, error) {
+	// 通过 Table 查询不会触发解密回调，读取的是库中原始值
+	pattern := escapeLike(envelope.Prefix) + "%"
+	if !plaintextOnly {
+		pattern = escapeLike(envelope.Prefix+env.PrimaryKeyID()+":") + "%"
+	}
+	type row struct {
+		Id    int64
+		Value string
+	}
+	var pending []row
+	rows, err := db.WithContext(ctx).Table(table).
+		Select(pk, column).
+		Where("? IS NOT NULL AND ? != '' AND ? NOT LIKE ?", clause.Column{Name: column}, clause.Column{Name: column}, clause.Column{Name: column}, pattern).
+		Rows()
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var r row
+		if err = rows.Scan(&r.Id, &r.Value); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		pending = append(pending, r)
+	}
+	_ = rows.Close()
+
+	var updated int64
+	for _, r := range pending {
+		plaintext, err := env.Decrypt(r.Value)
+		if err != nil {
+			return updated, fmt.Errorf("row %d: %v", r.Id, err)
+		}
+		enc, err := env.Encrypt(plaintext)
+		if err != nil {
+			return updated, fmt.Errorf("row %d: %v", r.Id, err)
+		}
+		// 带上原值条件，避免覆盖期间被并发修改的数据
+		f := db.WithContext(ctx).Exec("UPDATE ? SET ? = ? WHERE ? = ? AND ? = ?",
+			clause.Table{Name: table}, clause.Column{Name: column}, enc,
+			clause.Column{Name: pk}, r.Id, clause.Column{Name: column}, r.Value)
+		if f.Error != nil {
+			return updated, fmt.Errorf("row %d: %v", r.Id, f.Error)
+		}
+		updated += f.RowsAffected
+	}
+	if updated > 0 {
+		klog.Infof("re-encrypted %d value(s) of %s.%s", updated, table, column)
+	}
+	return updated, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
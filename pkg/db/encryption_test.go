@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/envelope"
+)
+
+// newDryRunDB 返回仅生成 SQL、不连接数据库的 gorm 实例
+func newDryRunDB(t *testing.T) (*gorm.DB, *envelope.Envelope) {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "pixiu@tcp(127.0.0.1:0)/pixiu", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := envelope.GenerateKeyLine("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := envelope.ParseKeys([]byte(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := envelope.New(provider)
+	if err = RegisterEncryption(db, env); err != nil {
+		t.Fatal(err)
+	}
+	return db, env
+}
+
+// encryptedVar 返回 SQL 参数中的加密值
+func encryptedVar(vars []interface{}) string {
+	for _, v := range vars {
+		if s, ok := v.(string); ok && envelope.IsEncrypted(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+func TestEncryptOnWrite(t *testing.T) {
+	db, env := newDryRunDB(t)
+
+	cluster := &model.Cluster{Name: "pixiu", KubeConfig: "kubeconfig"}
+	tx := db.Create(cluster)
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	enc := encryptedVar(tx.Statement.Vars)
+	if enc == "" {
+		t.Fatalf("kube_config is not encrypted on create: %v", tx.Statement.Vars)
+	}
+	if plaintext, err := env.Decrypt(enc); err != nil || plaintext != "kubeconfig" {
+		t.Fatalf("Decrypt = %q, %v", plaintext, err)
+	}
+	if cluster.KubeConfig != "kubeconfig" {
+		t.Fatalf("caller object should be restored to plaintext, got %q", cluster.KubeConfig)
+	}
+
+	updates := map[string]interface{}{"kube_config": "new-kubeconfig", "name": "pixiu"}
+	tx = db.Model(&model.Cluster{}).Where("id = ?", 1).Updates(updates)
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	if encryptedVar(tx.Statement.Vars) == "" {
+		t.Fatalf("kube_config is not encrypted on map update: %v", tx.Statement.Vars)
+	}
+	if updates["kube_config"] != "new-kubeconfig" {
+		t.Fatal("caller map should not be modified")
+	}
+
+	email := &model.Email{Password: "password"}
+	tx = db.Model(&model.Email{}).Where("id = ?", 1).Updates(email)
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	if encryptedVar(tx.Statement.Vars) == "" || email.Password != "password" {
+		t.Fatalf("password is not encrypted on struct update: %v", tx.Statement.Vars)
+	}
+
+	tx = db.Model(&model.Cluster{}).Where("id = ?", 1).Update("name", "pixiu")
+	if encryptedVar(tx.Statement.Vars) != "" {
+		t.Fatal("non-secret column should not be encrypted")
+	}
+}
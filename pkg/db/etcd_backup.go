@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type EtcdBackupInterface interface {
+	CreatePolicy(ctx context.Context, object *model.EtcdBackupPolicy) (*model.EtcdBackupPolicy, error)
+	UpdatePolicy(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error
+	// InternalUpdatePolicy 不校验 resource_version，用于调度回写 last_schedule_time
+	InternalUpdatePolicy(ctx context.Context, id int64, updates map[string]interface{}) error
+	// GetPolicy 未找到时返回 nil, nil
+	GetPolicy(ctx context.Context, planId int64) (*model.EtcdBackupPolicy, error)
+	ListPolicies(ctx context.Context, opts ...Options) ([]model.EtcdBackupPolicy, error)
+	DeletePolicy(ctx context.Context, planId int64) error
+
+	CreateSnapshot(ctx context.Context, object *model.EtcdSnapshot) (*model.EtcdSnapshot, error)
+	InternalUpdateSnapshot(ctx context.Context, id int64, updates map[string]interface{}) error
+	// GetSnapshot 未找到时返回 nil, nil
+	GetSnapshot(ctx context.Context, id int64) (*model.EtcdSnapshot, error)
+	ListSnapshots(ctx context.Context, opts ...Options) ([]model.EtcdSnapshot, error)
+	CountSnapshots(ctx context.Context, opts ...Options) (int64, error)
+	DeleteSnapshot(ctx context.Context, id int64) error
+}
+
+type etcdBackup struct {
+	db *gorm.DB
+}
+
+func newEtcdBackup(db *gorm.DB) EtcdBackupInterface {
+	return &etcdBackup{db: db}
+}
+
+func (e *etcdBackup) CreatePolicy(ctx context.Context, object *model.EtcdBackupPolicy) (*model.EtcdBackupPolicy, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := e.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (e *etcdBackup) UpdatePolicy(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := e.db.WithContext(ctx).Model(&model.EtcdBackupPolicy{}).Where("id = ? and resource_version = ?", id, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return utilerrors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+func (e *etcdBackup) InternalUpdatePolicy(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return e.db.WithContext(ctx).Model(&model.EtcdBackupPolicy{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (e *etcdBackup) GetPolicy(ctx context.Context, planId int64) (*model.EtcdBackupPolicy, error) {
+	var object model.EtcdBackupPolicy
+	if err := e.db.WithContext(ctx).Where("plan_id = ?", planId).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (e *etcdBackup) ListPolicies(ctx context.Context, opts ...Options) ([]model.EtcdBackupPolicy, error) {
+	var objects []model.EtcdBackupPolicy
+	tx := e.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (e *etcdBackup) DeletePolicy(ctx context.Context, planId int64) error {
+	return e.db.WithContext(ctx).Where("plan_id = ?", planId).Delete(&model.EtcdBackupPolicy{}).Error
+}
+
+func (e *etcdBackup) CreateSnapshot(ctx context.Context, object *model.EtcdSnapshot) (*model.EtcdSnapshot, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := e.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (e *etcdBackup) InternalUpdateSnapshot(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return e.db.WithContext(ctx).Model(&model.EtcdSnapshot{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (e *etcdBackup) GetSnapshot(ctx context.Context, id int64) (*model.EtcdSnapshot, error) {
+	var object model.EtcdSnapshot
+	if err := e.db.WithContext(ctx).Where("id = ?", id).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (e *etcdBackup) ListSnapshots(ctx context.Context, opts ...Options) ([]model.EtcdSnapshot, error) {
+	var objects []model.EtcdSnapshot
+	tx := e.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (e *etcdBackup) CountSnapshots(ctx context.Context, opts ...Options) (int64, error) {
+	var count int64
+	tx := e.db.WithContext(ctx).Model(&model.EtcdSnapshot{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (e *etcdBackup) DeleteSnapshot(ctx context.Context, id int64) error {
+	return e.db.WithContext(ctx).Where("id = ?", id).Delete(&model.EtcdSnapshot{}).Error
+}
@@ -23,10 +23,13 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
 )
 
 type ExecutionInterface interface {
 	Create(ctx context.Context, object *model.Execution) (*model.Execution, error)
+	Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error
+	Get(ctx context.Context, id int64) (*model.Execution, error)
 }
 
 type execution struct {
@@ -46,3 +49,28 @@ func (a *execution) Create(ctx context.Context, object *model.Execution) (*model
 	}
 	return object, nil
 }
+
+func (a *execution) Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+	f := a.db.WithContext(ctx).Model(&model.Execution{}).
+		Where("id = ? and resource_version = ?", id, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *execution) Get(ctx context.Context, id int64) (*model.Execution, error) {
+	var object model.Execution
+	if err := a.db.WithContext(ctx).Where("id = ?", id).First(&object).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
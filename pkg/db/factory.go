@@ -37,6 +37,7 @@ type ShareDaoFactory interface {
 	Assistant() AssistantInterface
 	Alert() AlertInterface
 	Email() EmailInterface
+	HelmApplication() HelmApplicationInterface
 }
 
 type shareDaoFactory struct {
@@ -59,6 +60,9 @@ func (f *shareDaoFactory) Runner() RunnerInterface             { return newRunne
 func (f *shareDaoFactory) Assistant() AssistantInterface       { return newAssistant(f.db) }
 func (f *shareDaoFactory) Alert() AlertInterface               { return newAlert(f.db) }
 func (f *shareDaoFactory) Email() EmailInterface               { return newEmail(f.db) }
+func (f *shareDaoFactory) HelmApplication() HelmApplicationInterface {
+	return newHelmApplication(f.db)
+}
 
 func NewDaoFactory(db *gorm.DB, migrate bool) (ShareDaoFactory, error) {
 	if migrate {
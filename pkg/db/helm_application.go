@@ -0,0 +1,194 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type HelmApplicationInterface interface {
+	Create(ctx context.Context, object *model.HelmApplication) (*model.HelmApplication, error)
+	Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error
+	// InternalUpdate 不校验 resource_version，用于发布过程回写状态
+	InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error
+	Delete(ctx context.Context, id int64) error
+	// Get 未找到时返回 nil, nil
+	Get(ctx context.Context, id int64) (*model.HelmApplication, error)
+	GetByName(ctx context.Context, name string) (*model.HelmApplication, error)
+	List(ctx context.Context, opts ...Options) ([]model.HelmApplication, error)
+
+	// ReplaceTargets 以本次发布的目标集群替换原有记录
+	ReplaceTargets(ctx context.Context, applicationId int64, objects []model.HelmApplicationTarget) error
+	UpdateTarget(ctx context.Context, id int64, updates map[string]interface{}) error
+	// InterruptRollout 将 before 之前即停止回写的发布中应用及其未完成的目标置为失败，返回是否有应用被更新
+	InterruptRollout(ctx context.Context, id int64, before time.Time, message string) (bool, error)
+	ListTargets(ctx context.Context, applicationId int64) ([]model.HelmApplicationTarget, error)
+}
+
+type helmApplication struct {
+	db *gorm.DB
+}
+
+func newHelmApplication(db *gorm.DB) HelmApplicationInterface {
+	return &helmApplication{db: db}
+}
+
+func (h *helmApplication) Create(ctx context.Context, object *model.HelmApplication) (*model.HelmApplication, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := h.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (h *helmApplication) Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := h.db.WithContext(ctx).Model(&model.HelmApplication{}).Where("id = ? and resource_version = ?", id, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return utilerrors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+func (h *helmApplication) InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return h.db.WithContext(ctx).Model(&model.HelmApplication{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (h *helmApplication) Delete(ctx context.Context, id int64) error {
+	return h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", id).Delete(&model.HelmApplicationTarget{}).Error; err != nil {
+			return err
+		}
+		f := tx.Where("id = ?", id).Delete(&model.HelmApplication{})
+		if f.Error != nil {
+			return f.Error
+		}
+		if f.RowsAffected == 0 {
+			return utilerrors.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (h *helmApplication) Get(ctx context.Context, id int64) (*model.HelmApplication, error) {
+	var object model.HelmApplication
+	if err := h.db.WithContext(ctx).Where("id = ?", id).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (h *helmApplication) GetByName(ctx context.Context, name string) (*model.HelmApplication, error) {
+	var object model.HelmApplication
+	if err := h.db.WithContext(ctx).Where("name = ?", name).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (h *helmApplication) List(ctx context.Context, opts ...Options) ([]model.HelmApplication, error) {
+	var objects []model.HelmApplication
+	tx := h.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (h *helmApplication) ReplaceTargets(ctx context.Context, applicationId int64, objects []model.HelmApplicationTarget) error {
+	now := time.Now()
+	return h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", applicationId).Delete(&model.HelmApplicationTarget{}).Error; err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		for i := range objects {
+			objects[i].ApplicationId = applicationId
+			objects[i].GmtCreate = now
+			objects[i].GmtModified = now
+		}
+		return tx.Create(&objects).Error
+	})
+}
+
+func (h *helmApplication) UpdateTarget(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return h.db.WithContext(ctx).Model(&model.HelmApplicationTarget{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (h *helmApplication) InterruptRollout(ctx context.Context, id int64, before time.Time, message string) (bool, error) {
+	now := time.Now()
+	interrupted := false
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		f := tx.Model(&model.HelmApplication{}).
+			Where("id = ? and status = ? and gmt_modified < ?", id, model.HelmApplicationProgressing, before).
+			Updates(map[string]interface{}{
+				"status":           model.HelmApplicationFailed,
+				"message":          message,
+				"gmt_modified":     now,
+				"resource_version": gorm.Expr("resource_version + 1"),
+			})
+		if f.Error != nil {
+			return f.Error
+		}
+		if f.RowsAffected == 0 {
+			return nil
+		}
+		interrupted = true
+		return tx.Model(&model.HelmApplicationTarget{}).
+			Where("application_id = ? and status in ?", id, []model.HelmTargetStatus{model.HelmTargetPending, model.HelmTargetRunning}).
+			Updates(map[string]interface{}{
+				"status":       model.HelmTargetFailed,
+				"message":      message,
+				"gmt_modified": now,
+			}).Error
+	})
+	return interrupted, err
+}
+
+func (h *helmApplication) ListTargets(ctx context.Context, applicationId int64) ([]model.HelmApplicationTarget, error) {
+	var objects []model.HelmApplicationTarget
+	if err := h.db.WithContext(ctx).Where("application_id = ?", applicationId).Order("batch, cluster").Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
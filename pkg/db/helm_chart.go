@@ -0,0 +1,105 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+// chart 目录批量写入的批大小，大仓库（如 bitnami）有上百个 chart
+const helmChartBatchSize = 100
+
+type HelmChartInterface interface {
+	// Replace 以最新同步结果整体替换仓库的 chart 目录
+	Replace(ctx context.Context, repositoryId int64, objects []model.HelmChart) error
+	DeleteByRepository(ctx context.Context, repositoryId int64) error
+	// Get 未找到时返回 nil, nil
+	Get(ctx context.Context, repositoryId int64, name string) (*model.HelmChart, error)
+	List(ctx context.Context, opts ...Options) ([]model.HelmChart, error)
+	Count(ctx context.Context, opts ...Options) (int64, error)
+}
+
+type helmChart struct {
+	db *gorm.DB
+}
+
+func newHelmChart(db *gorm.DB) HelmChartInterface {
+	return &helmChart{db: db}
+}
+
+func (h *helmChart) Replace(ctx context.Context, repositoryId int64, objects []model.HelmChart) error {
+	now := time.Now()
+	return h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("repository_id = ?", repositoryId).Delete(&model.HelmChart{}).Error; err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		for i := range objects {
+			objects[i].RepositoryId = repositoryId
+			objects[i].GmtCreate = now
+			objects[i].GmtModified = now
+		}
+		return tx.CreateInBatches(&objects, helmChartBatchSize).Error
+	})
+}
+
+func (h *helmChart) DeleteByRepository(ctx context.Context, repositoryId int64) error {
+	return h.db.WithContext(ctx).Where("repository_id = ?", repositoryId).Delete(&model.HelmChart{}).Error
+}
+
+func (h *helmChart) Get(ctx context.Context, repositoryId int64, name string) (*model.HelmChart, error) {
+	var object model.HelmChart
+	if err := h.db.WithContext(ctx).Where("repository_id = ? and name = ?", repositoryId, name).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (h *helmChart) List(ctx context.Context, opts ...Options) ([]model.HelmChart, error) {
+	var objects []model.HelmChart
+	tx := h.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (h *helmChart) Count(ctx context.Context, opts ...Options) (int64, error) {
+	var total int64
+	tx := h.db.WithContext(ctx).Model(&model.HelmChart{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -18,6 +18,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -32,6 +33,50 @@ type MessageInterface interface {
 	Get(ctx context.Context, id int64) (*model.Message, error)
 	List(ctx context.Context, opts ...Options) ([]model.Message, error)
 	Count(ctx context.Context, opts ...Options) (int64, error)
+
+	// SumTokens 统计满足条件的消息消耗的 token 总量
+	SumTokens(ctx context.Context, opts ...Options) (int64, error)
+	// AggregateUsage 按维度聚合用量和费用，dimension 取值见 MessageUsageDimensions
+	AggregateUsage(ctx context.Context, dimension string, opts ...Options) ([]MessageUsage, error)
+}
+
+// MessageUsageDimensions 用量报表支持的聚合维度及其对应的分组表达式
+var MessageUsageDimensions = map[string]string{
+	"user":   "CAST(user_id AS CHAR)",
+	"tenant": "CAST(tenant_id AS CHAR)",
+	"model":  "model",
+	"day":    "DATE_FORMAT(gmt_create, '%Y-%m-%d')",
+}
+
+// MessageUsage 聚合后的一行用量，不同币种的费用分开统计
+type MessageUsage struct {
+	Dimension       string
+	Currency        string
+	Requests        int64
+	InputTokens     int64
+	OutputTokens    int64
+	TotalTokens     int64
+	CachedTokens    int64
+	ReasoningTokens int64
+	Cost            float64
+}
+
+func WithAIAccountId(accountId int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if accountId == 0 {
+			return tx
+		}
+		return tx.Where("account_id = ?", accountId)
+	}
+}
+
+func WithAIModel(modelName string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if modelName == "" {
+			return tx
+		}
+		return tx.Where("model = ?", modelName)
+	}
 }
 
 type message struct {
@@ -97,3 +142,39 @@ func (a *message) Count(ctx context.Context, opts ...Options) (int64, error) {
 	}
 	return total, nil
 }
+
+func (a *message) SumTokens(ctx context.Context, opts ...Options) (int64, error) {
+	var total int64
+	tx := a.db.WithContext(ctx).Model(&model.Message{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (a *message) AggregateUsage(ctx context.Context, dimension string, opts ...Options) ([]MessageUsage, error) {
+	expr, ok := MessageUsageDimensions[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported usage dimension %q", dimension)
+	}
+
+	tx := a.db.WithContext(ctx).Model(&model.Message{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	var objects []MessageUsage
+	err := tx.Select(expr + " AS dimension, currency, COUNT(*) AS requests, " +
+		"COALESCE(SUM(input_tokens), 0) AS input_tokens, COALESCE(SUM(output_tokens), 0) AS output_tokens, " +
+		"COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cached_tokens), 0) AS cached_tokens, " +
+		"COALESCE(SUM(reasoning_tokens), 0) AS reasoning_tokens, COALESCE(SUM(cost), 0) AS cost").
+		Group(expr + ", currency").
+		Order("dimension ASC").
+		Scan(&objects).Error
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
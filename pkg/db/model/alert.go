@@ -81,6 +81,23 @@ const (
 	AlertNotifyChannelFeishu   AlertNotifyChannel = 5
 )
 
+func (c AlertNotifyChannel) String() string {
+	switch c {
+	case AlertNotifyChannelEmail:
+		return "email"
+	case AlertNotifyChannelDingTalk:
+		return "dingtalk"
+	case AlertNotifyChannelWeCom:
+		return "wecom"
+	case AlertNotifyChannelWebhook:
+		return "webhook"
+	case AlertNotifyChannelFeishu:
+		return "feishu"
+	default:
+		return "unknown"
+	}
+}
+
 type AlertNotificationStatus int
 
 const (
@@ -177,7 +194,7 @@ type AlertChannel struct {
 	Name        string             `gorm:"column:name;type:varchar(128);not null;index:idx_alert_channels_name" json:"name"`
 	Description string             `gorm:"column:description;type:text" json:"description"`
 	ChannelType AlertNotifyChannel `gorm:"column:channel_type;not null;index:idx_alert_channels_type" json:"channel_type"`
-	Config      string             `gorm:"column:config;type:text" json:"config"`
+	Config      string             `gorm:"column:config;type:text;encrypted" json:"config"`
 	Enabled     bool               `gorm:"column:enabled;default:true;not null;index:idx_alert_channels_enabled" json:"enabled"`
 	CreatedBy   string             `gorm:"column:created_by;type:varchar(128)" json:"created_by"`
 	Extension   string             `gorm:"column:extension;type:text" json:"extension"`
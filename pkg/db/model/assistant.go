@@ -16,10 +16,15 @@ limitations under the License.
 
 package model
 
-import "github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
 
 func init() {
-	register(&AIProvider{}, &AIAccount{}, &Conversation{}, &Message{}, &Execution{})
+	register(&AIProvider{}, &AIAccount{}, &Conversation{}, &Message{}, &Execution{}, &AIQuota{}, &AIModelPrice{})
 }
 
 // AIProvider stores an AI vendor endpoint and its wire protocol.
@@ -31,7 +36,9 @@ type AIProvider struct {
 	Protocol    string `gorm:"column:protocol;type:varchar(32);not null" json:"protocol"`
 	Description string `gorm:"column:description;type:text" json:"description"`
 	MaxTokens   int    `gorm:"column:max_tokens;not null;default:4096" json:"max_tokens"`
-	Builtin     bool   `gorm:"column:builtin;not null;default:false" json:"builtin"`
+	// ContextWindow 模型的上下文窗口大小，为 0 时使用默认值
+	ContextWindow int  `gorm:"column:context_window;not null;default:0" json:"context_window"`
+	Builtin       bool `gorm:"column:builtin;not null;default:false" json:"builtin"`
 }
 
 func (AIProvider) TableName() string {
@@ -45,7 +52,7 @@ type AIAccount struct {
 	UserId     int64       `gorm:"column:user_id;not null;index:idx_ai_accounts_user_id;uniqueIndex:uk_ai_accounts_user_provider_name,priority:1" json:"user_id"`
 	ProviderId int64       `gorm:"column:provider_id;not null;index:idx_ai_accounts_provider_id;uniqueIndex:uk_ai_accounts_user_provider_name,priority:2" json:"provider_id"`
 	Name       string      `gorm:"column:name;type:varchar(128);not null;uniqueIndex:uk_ai_accounts_user_provider_name,priority:3" json:"name"`
-	APIKey     string      `gorm:"column:api_key;type:text;not null" json:"-"`
+	APIKey     string      `gorm:"column:api_key;type:text;not null;encrypted" json:"-"`
 	ModelName  string      `gorm:"column:model;type:varchar(128);not null" json:"model"`
 	Provider   *AIProvider `gorm:"foreignKey:ProviderId;references:Id" json:"-"`
 }
@@ -70,6 +77,55 @@ func (Conversation) TableName() string {
 	return "conversations"
 }
 
+// ConversationItem is one turn stored in Conversation.History.
+// Pinned 和 Summary 只在 pixiu 内部使用，发送给模型前会被去掉
+type ConversationItem struct {
+	Role    string                `json:"role"`
+	Content []ConversationContent `json:"content"`
+	// Pinned 置顶的消息在压缩历史时保留原文
+	Pinned bool `json:"pinned,omitempty"`
+	// Summary 标识由模型生成的历史摘要
+	Summary bool `json:"summary,omitempty"`
+}
+
+type ConversationContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Text 返回消息的全部文本
+func (item *ConversationItem) Text() string {
+	parts := make([]string, 0, len(item.Content))
+	for _, content := range item.Content {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// HistoryItems 解析会话历史，历史为空时返回空列表
+func (conversation *Conversation) HistoryItems() ([]ConversationItem, error) {
+	items := make([]ConversationItem, 0)
+	if conversation == nil || strings.TrimSpace(conversation.History) == "" {
+		return items, nil
+	}
+	if err := json.Unmarshal([]byte(conversation.History), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetHistoryItems 序列化并覆盖会话历史
+func (conversation *Conversation) SetHistoryItems(items []ConversationItem) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	conversation.History = string(data)
+	return nil
+}
+
 type Message struct {
 	pixiu.Model
 
@@ -89,6 +145,13 @@ type Message struct {
 	TotalTokens     int64  `gorm:"column:total_tokens;type:bigint;default:0" json:"total_tokens"`
 	CachedTokens    int64  `gorm:"column:cached_tokens;type:bigint;default:0" json:"cached_tokens"`
 	ReasoningTokens int64  `gorm:"column:reasoning_tokens;type:bigint;default:0" json:"reasoning_tokens"`
+
+	// 以下字段用于配额统计和费用报表
+	UserId    int64   `gorm:"column:user_id;index:idx_messages_user_id" json:"user_id"`
+	TenantId  int64   `gorm:"column:tenant_id;index:idx_messages_tenant_id" json:"tenant_id"`
+	AccountId int64   `gorm:"column:account_id;index:idx_messages_account_id" json:"account_id"`
+	Cost      float64 `gorm:"column:cost;type:decimal(20,6);default:0" json:"cost"`
+	Currency  string  `gorm:"column:currency;type:varchar(8)" json:"currency"`
 }
 
 func (Message) TableName() string {
@@ -110,8 +173,76 @@ type Execution struct {
 	Success        bool   `gorm:"column:success;not null;default:false;index:idx_executions_success" json:"success"`
 	ErrorMessage   string `gorm:"column:error_message;type:text" json:"error_message"`
 	Duration       int64  `gorm:"column:duration;type:bigint;default:0" json:"duration"`
+
+	// 以下字段用于变更类工具调用的审批
+	UserId         int64          `gorm:"column:user_id;index:idx_executions_user_id" json:"user_id"`
+	Cluster        string         `gorm:"column:cluster;type:varchar(128)" json:"cluster"`
+	Namespace      string         `gorm:"column:namespace;type:varchar(128)" json:"namespace"`
+	Verb           string         `gorm:"column:verb;type:varchar(64)" json:"verb"`
+	Mutating       bool           `gorm:"column:mutating;not null;default:false" json:"mutating"`
+	ApprovalStatus ApprovalStatus `gorm:"column:approval_status;type:varchar(16);index:idx_executions_approval_status" json:"approval_status"`
+	ApprovedBy     string         `gorm:"column:approved_by;type:varchar(128)" json:"approved_by"`
+	Reason         string         `gorm:"column:reason;type:text" json:"reason"`
 }
 
+// ApprovalStatus 变更类工具调用的审批状态，只读调用为空
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+	ApprovalExpired  ApprovalStatus = "expired"
+)
+
 func (Execution) TableName() string {
 	return "executions"
 }
+
+// AIQuotaScope 配额的作用对象
+type AIQuotaScope string
+
+const (
+	AIQuotaScopeUser    AIQuotaScope = "user"
+	AIQuotaScopeTenant  AIQuotaScope = "tenant"
+	AIQuotaScopeAccount AIQuotaScope = "account"
+)
+
+// AIQuotaPeriod 配额的统计周期，按服务端本地时间的自然日和自然月计算
+type AIQuotaPeriod string
+
+const (
+	AIQuotaDaily   AIQuotaPeriod = "daily"
+	AIQuotaMonthly AIQuotaPeriod = "monthly"
+)
+
+// AIQuota limits the tokens a user, tenant or AI account may consume in one period.
+type AIQuota struct {
+	pixiu.Model
+
+	Scope       AIQuotaScope  `gorm:"column:scope;type:varchar(16);not null;uniqueIndex:uk_ai_quotas_scope_period,priority:1" json:"scope"`
+	ScopeId     int64         `gorm:"column:scope_id;not null;uniqueIndex:uk_ai_quotas_scope_period,priority:2" json:"scope_id"`
+	Period      AIQuotaPeriod `gorm:"column:period;type:varchar(16);not null;uniqueIndex:uk_ai_quotas_scope_period,priority:3" json:"period"`
+	TokenLimit  int64         `gorm:"column:token_limit;type:bigint;not null" json:"token_limit"`
+	Description string        `gorm:"column:description;type:text" json:"description"`
+}
+
+func (AIQuota) TableName() string {
+	return "ai_quotas"
+}
+
+// AIModelPrice stores the per-million-token price of one provider model.
+type AIModelPrice struct {
+	pixiu.Model
+
+	ProviderId       int64   `gorm:"column:provider_id;not null;uniqueIndex:uk_ai_model_prices_provider_model,priority:1" json:"provider_id"`
+	ModelName        string  `gorm:"column:model;type:varchar(128);not null;uniqueIndex:uk_ai_model_prices_provider_model,priority:2" json:"model"`
+	Currency         string  `gorm:"column:currency;type:varchar(8);not null" json:"currency"`
+	InputPrice       float64 `gorm:"column:input_price;type:decimal(20,6);default:0" json:"input_price"`
+	CachedInputPrice float64 `gorm:"column:cached_input_price;type:decimal(20,6);default:0" json:"cached_input_price"`
+	OutputPrice      float64 `gorm:"column:output_price;type:decimal(20,6);default:0" json:"output_price"`
+}
+
+func (AIModelPrice) TableName() string {
+	return "ai_model_prices"
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
@@ -60,6 +63,12 @@ type Audit struct {
 	Cluster           string               `gorm:"column:cluster;type:varchar(255)" json:"cluster"`                       // K8s 集群名
 	ResourceName      string               `gorm:"column:resource_name;type:varchar(255)" json:"resource_name"`           // 资源名称
 	ResourceNamespace string               `gorm:"column:resource_namespace;type:varchar(255)" json:"resource_namespace"` // 资源命名空间
+	Resource          string               `gorm:"column:resource;type:varchar(128)" json:"resource"`                     // K8s 资源类型，如 deployments
+	TokenJTI          string               `gorm:"column:token_jti;type:varchar(64);index" json:"token_jti"`              // kube gateway 访问令牌 JTI
+
+	// 防篡改哈希链：Hash 覆盖本条内容与上一条记录的 Hash
+	PrevHash string `gorm:"column:prev_hash;type:varchar(64)" json:"prev_hash"`
+	Hash     string `gorm:"column:hash;type:varchar(64)" json:"hash"`
 }
 
 func (a *Audit) String() string {
@@ -67,6 +76,35 @@ func (a *Audit) String() string {
 		a.Path, a.Action, a.Status.String(), a.Duration)
 }
 
+// auditHashVersion 参与哈希的字段集合版本，字段调整时需升级
+const auditHashVersion = "v1"
+
+// ComputeHash 计算审计记录的链式哈希，覆盖 PrevHash 与除 id、Hash 外的全部审计字段。
+// gmt_create 以秒参与计算，与 datetime 列精度一致。
+func (a *Audit) ComputeHash() string {
+	content, _ := json.Marshal([]interface{}{
+		auditHashVersion,
+		a.PrevHash,
+		a.GmtCreate.Unix(),
+		a.RequestId,
+		a.Ip,
+		a.Action,
+		a.Operator,
+		a.Path,
+		a.ObjectType,
+		a.Status,
+		a.Duration,
+		a.ResponseCode,
+		a.Cluster,
+		a.ResourceName,
+		a.ResourceNamespace,
+		a.Resource,
+		a.TokenJTI,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 func (a *Audit) TableName() string {
 	return "audits"
 }
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&AuditChainHead{}, &AuditCheckpoint{})
+}
+
+// AuditChainHeadId 审计哈希链链头固定为单行
+const AuditChainHeadId int64 = 1
+
+// AuditChainHead 审计哈希链链头，写入审计记录时加行锁串行化链接顺序
+type AuditChainHead struct {
+	pixiu.Model
+
+	LastAuditId int64  `gorm:"column:last_audit_id" json:"last_audit_id"`
+	LastHash    string `gorm:"column:last_hash;type:varchar(64)" json:"last_hash"`
+}
+
+func (*AuditChainHead) TableName() string {
+	return "audit_chain_heads"
+}
+
+type AuditCheckpointKind string
+
+const (
+	// AuditCheckpointPeriodic 定期签名的链上位置
+	AuditCheckpointPeriodic AuditCheckpointKind = "checkpoint"
+	// AuditCheckpointAnchor 清理过期记录时写入，记录最后一条被删除记录的哈希，校验从此处接续
+	AuditCheckpointAnchor AuditCheckpointKind = "anchor"
+)
+
+// AuditCheckpoint 审计哈希链的签名检查点
+type AuditCheckpoint struct {
+	pixiu.Model
+
+	Kind      AuditCheckpointKind `gorm:"column:kind;type:varchar(32);index" json:"kind"`
+	AuditId   int64               `gorm:"column:audit_id;index" json:"audit_id"`
+	Hash      string              `gorm:"column:hash;type:varchar(64)" json:"hash"`
+	Count     int64               `gorm:"column:count" json:"count"` // anchor 为本次删除的记录数
+	SignedAt  time.Time           `gorm:"column:signed_at" json:"signed_at"`
+	KeyId     string              `gorm:"column:key_id;type:varchar(64)" json:"key_id"`
+	Signature string              `gorm:"column:signature;type:varchar(255)" json:"signature"` // base64 ed25519 签名
+}
+
+func (*AuditCheckpoint) TableName() string {
+	return "audit_checkpoints"
+}
+
+// SigningPayload 签名内容，覆盖类型、链上位置、哈希、数量与签名时间
+func (c *AuditCheckpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("pixiu-audit-checkpoint|%s|%d|%s|%d|%d", c.Kind, c.AuditId, c.Hash, c.Count, c.SignedAt.Unix()))
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&AuditObjectDiff{})
+}
+
+// AuditObjectDiff 经集群代理更新（PUT/PATCH）K8s 对象前后的快照与差异，关联对应的审计记录。
+// Secret 的 data/stringData 在入库前已脱敏。
+type AuditObjectDiff struct {
+	pixiu.Model
+
+	AuditId    int64  `gorm:"column:audit_id;index" json:"audit_id"`
+	Cluster    string `gorm:"column:cluster;type:varchar(255)" json:"cluster"`
+	APIVersion string `gorm:"column:api_version;type:varchar(128)" json:"api_version"`
+	Kind       string `gorm:"column:kind;type:varchar(128)" json:"kind"`
+	Namespace  string `gorm:"column:namespace;type:varchar(255)" json:"namespace"`
+	Name       string `gorm:"column:name;type:varchar(255)" json:"name"`
+	Before     string `gorm:"column:before;type:mediumtext" json:"before"` // 变更前对象（YAML）
+	After      string `gorm:"column:after;type:mediumtext" json:"after"`   // 变更后对象（YAML）
+	Diff       string `gorm:"column:diff;type:mediumtext" json:"diff"`     // unified diff
+}
+
+func (*AuditObjectDiff) TableName() string {
+	return "audit_object_diffs"
+}
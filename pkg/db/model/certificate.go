@@ -0,0 +1,54 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&PlanCertificate{})
+}
+
+// CertificateKind 证书类别
+type CertificateKind string
+
+const (
+	CertificateCA           CertificateKind = "ca"            // 根证书
+	CertificateControlPlane CertificateKind = "control-plane" // 控制面组件证书及 kubeconfig 内嵌证书
+	CertificateKubelet      CertificateKind = "kubelet"       // kubelet 服务端与客户端证书
+)
+
+// PlanCertificate 部署计划 master 节点上的证书，每次巡检整体替换
+type PlanCertificate struct {
+	pixiu.Model
+
+	PlanId    int64           `gorm:"index:idx_plan_certificate_plan" json:"plan_id"`
+	Node      string          `gorm:"type:varchar(128)" json:"node"`
+	Name      string          `gorm:"type:varchar(128)" json:"name"` // 例如 apiserver、admin.conf
+	Kind      CertificateKind `gorm:"type:varchar(32)" json:"kind"`
+	Path      string          `gorm:"type:varchar(255)" json:"path"`
+	Subject   string          `gorm:"type:varchar(255)" json:"subject"`
+	NotAfter  time.Time       `json:"not_after"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+func (*PlanCertificate) TableName() string {
+	return "plan_certificates"
+}
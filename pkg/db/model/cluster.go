@@ -98,7 +98,7 @@ type Cluster struct {
 	Protected bool `json:"protected"`
 
 	// k8s kubeConfig base64 字段（隧道模式下 server 地址需对 Agent 可达，如 kubernetes.default.svc）
-	KubeConfig string `json:"kube_config"`
+	KubeConfig string `gorm:"encrypted" json:"kube_config"`
 
 	// 连接模式：0 直连 1 Agent 反向隧道
 	ConnectMode ConnectMode `gorm:"type:tinyint;default:0" json:"connect_mode"`
@@ -106,6 +106,9 @@ type Cluster struct {
 	// 集群用途描述，可以为空
 	Description string `gorm:"type:text" json:"description"`
 
+	// 集群标签，json 对象，用于按标签选择集群（如多集群应用分发）
+	Labels string `gorm:"type:text" json:"labels"`
+
 	// 集群连通性探测状态（Ready condition 语义），由 syncer 定期维护
 	ProbeStatus  ClusterProbeStatus `gorm:"column:probe_status;type:tinyint;default:0" json:"probe_status"`
 	ProbeReason  string             `gorm:"column:probe_reason;type:varchar(128)" json:"probe_reason"`
@@ -115,6 +118,13 @@ type Cluster struct {
 
 	// Agent 建连 token（仅隧道模式），全局唯一
 	AgentToken string `gorm:"type:varchar(128);index:idx_agent_token" json:"agent_token,omitempty"`
+
+	// 隧道允许访问的集群内服务（仅隧道模式），json 字符串数组，如 ["prometheus.monitoring:9090"]
+	TunnelTargets string `gorm:"type:text" json:"tunnel_targets"`
+
+	// kubeconfig 凭据类型与过期时间，由 kubeconfig 解析得到，无法获知或永不过期时为 NULL
+	CredentialType      string     `gorm:"column:credential_type;type:varchar(64)" json:"credential_type"`
+	CredentialExpiresAt *time.Time `gorm:"column:credential_expires_at;type:datetime;default:null" json:"credential_expires_at"`
 }
 
 func (*Cluster) TableName() string {
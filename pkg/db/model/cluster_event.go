@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&ClusterEvent{})
+}
+
+// ClusterEventType 集群健康事件类型
+type ClusterEventType string
+
+const (
+	ClusterEventStatus ClusterEventType = "status" // 集群状态变化，From/To 为 ClusterStatus
+	ClusterEventProbe  ClusterEventType = "probe"  // 连通性探测状态变化，From/To 为 ClusterProbeStatus
+	ClusterEventNode   ClusterEventType = "node"   // 节点 Ready/NotReady 变化，Object 为节点名
+)
+
+const (
+	NodeStateReady    = "Ready"
+	NodeStateNotReady = "NotReady"
+	NodeStateAbsent   = "Absent"
+)
+
+// ClusterEvent 集群健康状态迁移记录，由 syncer、集群创建与部署计划在状态变化时写入，用于可用率统计与抖动检测
+type ClusterEvent struct {
+	pixiu.Model
+
+	ClusterId   int64            `gorm:"column:cluster_id;index:idx_cluster_type,priority:1" json:"cluster_id"`
+	ClusterName string           `gorm:"column:cluster_name;type:varchar(128)" json:"cluster_name"`
+	Type        ClusterEventType `gorm:"column:type;type:varchar(32);index:idx_cluster_type,priority:2" json:"type"`
+	Source      string           `gorm:"column:source;type:varchar(64)" json:"source"` // 记录者，例如 cluster-syncer / tunnel-syncer / deploy-plan
+	Object      string           `gorm:"column:object;type:varchar(255)" json:"object,omitempty"`
+	From        string           `gorm:"column:from_state;type:varchar(32)" json:"from"`
+	To          string           `gorm:"column:to_state;type:varchar(32)" json:"to"`
+	Reason      string           `gorm:"column:reason;type:varchar(128)" json:"reason"`
+	Message     string           `gorm:"column:message;type:text" json:"message"`
+}
+
+func (*ClusterEvent) TableName() string {
+	return "cluster_events"
+}
+
+// ClusterStatusName 返回集群状态在事件中记录的名称
+func ClusterStatusName(s ClusterStatus) string {
+	switch s {
+	case ClusterStatusRunning:
+		return "Running"
+	case ClusterStatusDeploy:
+		return "Deploying"
+	case ClusterStatusUnStart:
+		return "UnStart"
+	case ClusterStatusFailed:
+		return "Failed"
+	case ClusterStatusError:
+		return "Error"
+	case ClusterStatusPending:
+		return "Pending"
+	default:
+		return "Unknown"
+	}
+}
+
+// ClusterProbeStatusName 返回连通性探测状态在事件中记录的名称
+func ClusterProbeStatusName(s ClusterProbeStatus) string {
+	switch s {
+	case ClusterProbeHealthy:
+		return "Healthy"
+	case ClusterProbeUnhealthy:
+		return "Unhealthy"
+	default:
+		return "Unknown"
+	}
+}
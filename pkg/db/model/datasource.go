@@ -48,7 +48,7 @@ type Datasource struct {
 	Name        string            `gorm:"column:name;type:varchar(128);not null" json:"name"`
 	Type        DatasourceType    `gorm:"column:type;not null" json:"type"`
 	SubType     DatasourceSubType `gorm:"column:sub_type;type:varchar(32);not null" json:"sub_type"`
-	Config      string            `gorm:"column:config;type:text" json:"config"`
+	Config      string            `gorm:"column:config;type:text;encrypted" json:"config"`
 	IsDefault   bool              `gorm:"column:is_default;default:false;not null" json:"is_default"`
 	External    bool              `gorm:"column:external;default:false;not null" json:"external"`
 	Description string            `gorm:"column:description;type:text" json:"description"`
@@ -23,14 +23,14 @@ func init() {
 }
 
 // Email 系统邮件 SMTP 配置（支持多条，is_default 标记默认配置）。
-// Password 明文落库，对外接口不回显明文（仅 PasswordSet）。
+// Password 开启数据库加密时加密落库，对外接口不回显明文（仅 PasswordSet）。
 type Email struct {
 	pixiu.Model
 	Name        string `gorm:"column:name;type:varchar(128);not null" json:"name"`
 	SmtpHost    string `gorm:"column:smtp_host;type:varchar(255);not null" json:"smtp_host"`
 	SmtpPort    int    `gorm:"column:smtp_port;not null" json:"smtp_port"`
 	Username    string `gorm:"column:username;type:varchar(255)" json:"username"`
-	Password    string `gorm:"column:password;type:varchar(512)" json:"-"`
+	Password    string `gorm:"column:password;type:varchar(512);encrypted" json:"-"`
 	FromEmail   string `gorm:"column:from_email;type:varchar(255);not null" json:"from_email"`
 	FromName    string `gorm:"column:from_name;type:varchar(128)" json:"from_name"`
 	Encryption  string `gorm:"column:encryption;type:varchar(16);default:'none'" json:"encryption"`
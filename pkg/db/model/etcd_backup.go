@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&EtcdBackupPolicy{}, &EtcdSnapshot{})
+}
+
+// EtcdBackupMethod etcd 快照采集方式
+type EtcdBackupMethod string
+
+const (
+	EtcdBackupSSH EtcdBackupMethod = "ssh" // SSH 登录 master 节点执行 etcdctl
+	EtcdBackupPod EtcdBackupMethod = "pod" // 通过已注册集群内的 etcd 静态 Pod 与辅助 Pod 执行
+)
+
+// EtcdSnapshotTrigger 快照触发方式
+type EtcdSnapshotTrigger string
+
+const (
+	EtcdSnapshotManual    EtcdSnapshotTrigger = "manual"
+	EtcdSnapshotScheduled EtcdSnapshotTrigger = "scheduled"
+)
+
+type EtcdSnapshotStatus string
+
+const (
+	EtcdSnapshotRunning   EtcdSnapshotStatus = "running"
+	EtcdSnapshotSucceeded EtcdSnapshotStatus = "succeeded"
+	EtcdSnapshotFailed    EtcdSnapshotStatus = "failed"
+)
+
+// EtcdBackupPolicy 部署计划的 etcd 定时备份策略，每个 plan 至多一条
+type EtcdBackupPolicy struct {
+	pixiu.Model
+
+	PlanId    int64            `gorm:"column:plan_id;uniqueIndex" json:"plan_id"`
+	Enabled   bool             `gorm:"column:enabled;default:false" json:"enabled"`
+	Schedule  string           `gorm:"column:schedule;type:varchar(64)" json:"schedule"` // 标准 cron 表达式
+	Retention int              `gorm:"column:retention;default:7" json:"retention"`      // 保留最近 N 份成功的定时快照
+	Method    EtcdBackupMethod `gorm:"column:method;type:varchar(32);default:'ssh'" json:"method"`
+
+	// 最近一次按计划触发的时间，用于计算下一次执行时间
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time,omitempty"`
+}
+
+func (*EtcdBackupPolicy) TableName() string {
+	return "etcd_backup_policies"
+}
+
+// EtcdSnapshot etcd 快照记录，快照文件保存在备份存储中
+type EtcdSnapshot struct {
+	pixiu.Model
+
+	PlanId     int64               `gorm:"column:plan_id;index" json:"plan_id"`
+	Method     EtcdBackupMethod    `gorm:"column:method;type:varchar(32)" json:"method"`
+	Trigger    EtcdSnapshotTrigger `gorm:"column:trigger_type;type:varchar(32)" json:"trigger"`
+	Node       string              `gorm:"column:node;type:varchar(255)" json:"node"` // 采集快照的 master 节点
+	StorageKey string              `gorm:"column:storage_key;type:varchar(255)" json:"storage_key"`
+	Size       int64               `gorm:"column:size;default:0" json:"size"`
+	Checksum   string              `gorm:"column:checksum;type:varchar(128)" json:"checksum"` // sha256
+	Status     EtcdSnapshotStatus  `gorm:"column:status;type:varchar(32);index" json:"status"`
+	Message    string              `gorm:"column:message;type:text" json:"message"`
+	FinishedAt *time.Time          `gorm:"column:finished_at" json:"finished_at,omitempty"`
+}
+
+func (*EtcdSnapshot) TableName() string {
+	return "etcd_snapshots"
+}
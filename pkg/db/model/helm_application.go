@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&HelmApplication{}, &HelmApplicationTarget{})
+}
+
+// HelmApplicationStatus 多集群应用的整体发布状态
+type HelmApplicationStatus string
+
+const (
+	HelmApplicationPending     HelmApplicationStatus = "pending"     // 尚未发布
+	HelmApplicationProgressing HelmApplicationStatus = "progressing" // 发布中
+	HelmApplicationSucceeded   HelmApplicationStatus = "succeeded"   // 所有集群成功
+	HelmApplicationPartial     HelmApplicationStatus = "partial"     // 部分集群失败，但未超过失败阈值
+	HelmApplicationFailed      HelmApplicationStatus = "failed"      // 超过失败阈值，发布中止
+)
+
+// HelmApplicationAction 一次发布的操作类型
+type HelmApplicationAction string
+
+const (
+	HelmApplicationDeploy   HelmApplicationAction = "deploy"   // 安装或升级到当前配置
+	HelmApplicationRollback HelmApplicationAction = "rollback" // 回滚到各集群最近一次发布前的版本
+)
+
+// HelmTargetStatus 应用在单个集群上的状态
+type HelmTargetStatus string
+
+const (
+	HelmTargetPending   HelmTargetStatus = "pending"
+	HelmTargetRunning   HelmTargetStatus = "running"
+	HelmTargetSucceeded HelmTargetStatus = "succeeded"
+	HelmTargetFailed    HelmTargetStatus = "failed"
+	HelmTargetSkipped   HelmTargetStatus = "skipped" // 发布因超过失败阈值中止，未执行
+)
+
+// HelmApplication 多集群 Helm 应用：同一个 chart 按批次分发到一组集群
+type HelmApplication struct {
+	pixiu.Model
+
+	Name         string `gorm:"column:name;type:varchar(128);index:idx_name,unique" json:"name"`
+	Description  string `gorm:"column:description;type:text" json:"description"`
+	UserId       int64  `gorm:"column:user_id;index" json:"user_id"`
+	RepositoryId int64  `gorm:"column:repository_id" json:"repository_id"`
+	Chart        string `gorm:"column:chart;type:varchar(512)" json:"chart"`
+	Version      string `gorm:"column:version;type:varchar(128)" json:"version"`
+	Namespace    string `gorm:"column:namespace;type:varchar(128)" json:"namespace"`
+	ReleaseName  string `gorm:"column:release_name;type:varchar(128)" json:"release_name"`
+
+	// 基础 values，json 对象
+	Values string `gorm:"column:values;type:longtext" json:"values"`
+	// 按集群覆盖的 values，json 对象：集群名 -> values
+	Overrides string `gorm:"column:overrides;type:longtext" json:"overrides"`
+	// 目标集群：按名称指定（json 数组）与按标签选择，两者取并集
+	Clusters        string `gorm:"column:clusters;type:text" json:"clusters"`
+	ClusterSelector string `gorm:"column:cluster_selector;type:varchar(512)" json:"cluster_selector"`
+
+	// 每批发布的集群数与允许失败的集群数，超过阈值时中止后续批次
+	BatchSize        int `gorm:"column:batch_size;default:1" json:"batch_size"`
+	FailureThreshold int `gorm:"column:failure_threshold;default:0" json:"failure_threshold"`
+
+	Status     HelmApplicationStatus `gorm:"column:status;type:varchar(32);default:'pending'" json:"status"`
+	LastAction HelmApplicationAction `gorm:"column:last_action;type:varchar(32)" json:"last_action"`
+	Message    string                `gorm:"column:message;type:text" json:"message"`
+}
+
+func (*HelmApplication) TableName() string {
+	return "helm_applications"
+}
+
+// HelmApplicationTarget 应用在单个集群上的发布记录
+type HelmApplicationTarget struct {
+	pixiu.Model
+
+	ApplicationId int64                 `gorm:"column:application_id;index:idx_application_cluster,unique" json:"application_id"`
+	Cluster       string                `gorm:"column:cluster;type:varchar(128);index:idx_application_cluster,unique" json:"cluster"`
+	Status        HelmTargetStatus      `gorm:"column:status;type:varchar(32)" json:"status"`
+	Action        HelmApplicationAction `gorm:"column:action;type:varchar(32)" json:"action"`
+	Batch         int                   `gorm:"column:batch" json:"batch"`
+	ChartVersion  string                `gorm:"column:chart_version;type:varchar(128)" json:"chart_version"`
+	Revision      int                   `gorm:"column:revision" json:"revision"` // 执行后 release 的版本号
+	// 最近一次 deploy 前 release 的版本号，0 表示由该次 deploy 首次安装；回滚以此为目标，重复回滚结果不变
+	PreviousRevision int        `gorm:"column:previous_revision" json:"previous_revision"`
+	Message          string     `gorm:"column:message;type:text" json:"message"`
+	StartedAt        *time.Time `gorm:"column:started_at" json:"started_at,omitempty"`
+	FinishedAt       *time.Time `gorm:"column:finished_at" json:"finished_at,omitempty"`
+}
+
+func (*HelmApplicationTarget) TableName() string {
+	return "helm_application_targets"
+}
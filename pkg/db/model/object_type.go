@@ -25,6 +25,7 @@ const (
 	ObjectTenant  ObjectType = "tenants"
 	ObjectPlan    ObjectType = "plans"
 	ObjectAuth    ObjectType = "auth"
+	ObjectNode    ObjectType = "nodes"
 	ObjectAll     ObjectType = "*"
 )
 
@@ -38,5 +39,6 @@ var ObjectTypeMap = map[ObjectType]struct{}{
 	ObjectTenant:  {},
 	ObjectPlan:    {},
 	ObjectAuth:    {},
+	ObjectNode:    {},
 	ObjectAll:     {},
 }
@@ -70,7 +70,7 @@ type Node struct {
 	Role   string `json:"role"` // k8s 节点的角色，master 和 node
 	CRI    CRI    `json:"cri"`
 	Ip     string `json:"ip"`
-	Auth   string `json:"auth"`
+	Auth   string `gorm:"encrypted" json:"auth"`
 }
 
 func (node *Node) TableName() string {
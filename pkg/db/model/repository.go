@@ -23,15 +23,43 @@ import (
 )
 
 func init() {
-	register(&Repository{})
+	register(&Repository{}, &HelmChart{})
 }
 
+// RepositorySyncStatus 仓库 chart 目录的同步状态，为空表示尚未同步
+type RepositorySyncStatus string
+
+const (
+	RepositorySyncSucceeded RepositorySyncStatus = "succeeded"
+	RepositorySyncFailed    RepositorySyncStatus = "failed"
+)
+
+// RepositoryType chart 仓库类型，由 URL 协议决定
+type RepositoryType string
+
+const (
+	RepositoryHTTP RepositoryType = "http" // 传统 index.yaml 仓库
+	RepositoryOCI  RepositoryType = "oci"  // OCI 镜像仓库，例如 Harbor
+)
+
 type Repository struct {
 	pixiu.Model
-	Name     string `gorm:"column:name; index:idx_name,unique; not null" json:"name"`
-	URL      string `gorm:"column:url;not null" json:"url"`
-	Username string `gorm:"column:username" json:"username"`
-	Password string `gorm:"column:password" json:"password"`
+	Name     string         `gorm:"column:name; index:idx_name,unique; not null" json:"name"`
+	URL      string         `gorm:"column:url;not null" json:"url"`
+	Type     RepositoryType `gorm:"column:type;type:varchar(16);default:'http'" json:"type"`
+	Username string         `gorm:"column:username" json:"username"`
+	Password string         `gorm:"column:password;encrypted" json:"-"`
+	// 自定义 CA（PEM），用于自签名证书的仓库
+	CAData                string `gorm:"column:ca_data;type:text" json:"ca_data"`
+	InsecureSkipTLSVerify bool   `gorm:"column:insecure_skip_tls_verify" json:"insecure_skip_tls_verify"`
+	// OCI 仓库未开放 _catalog 接口时，通过该列表浏览 chart，逗号分隔
+	Charts string `gorm:"column:charts;type:text" json:"charts"`
+
+	// chart 目录同步状态，由后台索引任务维护
+	SyncStatus    RepositorySyncStatus `gorm:"column:sync_status;type:varchar(16)" json:"sync_status"`
+	LastSyncTime  *time.Time           `gorm:"column:last_sync_time" json:"last_sync_time,omitempty"`
+	LastSyncError string               `gorm:"column:last_sync_error;type:text" json:"last_sync_error"`
+	ChartCount    int                  `gorm:"column:chart_count" json:"chart_count"`
 }
 
 func (*Repository) TableName() string {
@@ -54,6 +82,9 @@ type ChartVersion struct {
 	Description  string            `json:"description"`
 	Digest       string            `json:"digest"`
 	Icon         string            `json:"icon"`
+	Home         string            `json:"home,omitempty"`
+	Keywords     []string          `json:"keywords,omitempty"`
+	Deprecated   bool              `json:"deprecated,omitempty"`
 	Maintainers  []Maintainer      `json:"maintainers"`
 	Name         string            `json:"name"`
 	Sources      []string          `json:"sources"`
@@ -73,3 +104,39 @@ type Dependency struct {
 type Maintainer struct {
 	Name string `json:"name"`
 }
+
+// HelmChart 仓库 chart 目录缓存，每个仓库的每个 chart 一条记录，元数据取自最新版本
+type HelmChart struct {
+	pixiu.Model
+
+	RepositoryId   int64  `gorm:"column:repository_id;index:idx_repository_chart,unique" json:"repository_id"`
+	RepositoryName string `gorm:"column:repository_name;type:varchar(255)" json:"repository_name"`
+	Name           string `gorm:"column:name;type:varchar(255);index:idx_repository_chart,unique;index:idx_chart_name" json:"name"`
+	LatestVersion  string `gorm:"column:latest_version;type:varchar(128)" json:"latest_version"`
+	AppVersion     string `gorm:"column:app_version;type:varchar(128)" json:"app_version"`
+	Description    string `gorm:"column:description;type:text" json:"description"`
+	Icon           string `gorm:"column:icon;type:varchar(1024)" json:"icon"`
+	Home           string `gorm:"column:home;type:varchar(1024)" json:"home"`
+	// 小写关键字，以逗号包裹（,a,b,）便于精确匹配
+	Keywords     string `gorm:"column:keywords;type:text" json:"keywords"`
+	Deprecated   bool   `gorm:"column:deprecated" json:"deprecated"`
+	Dependencies string `gorm:"column:dependencies;type:text" json:"dependencies"` // 最新版本的依赖，json 数组
+	Versions     string `gorm:"column:versions;type:longtext" json:"versions"`     // 所有版本摘要，json 数组，按版本倒序
+	VersionCount int    `gorm:"column:version_count" json:"version_count"`
+	// 最新版本的发布时间
+	Created *time.Time `gorm:"column:created" json:"created,omitempty"`
+}
+
+func (*HelmChart) TableName() string {
+	return "helm_charts"
+}
+
+// HelmChartVersion chart 版本摘要，缓存在 HelmChart.Versions 中
+type HelmChartVersion struct {
+	Version    string     `json:"version"`
+	AppVersion string     `json:"app_version,omitempty"`
+	Created    *time.Time `json:"created,omitempty"`
+	Digest     string     `json:"digest,omitempty"`
+	URLs       []string   `json:"urls,omitempty"`
+	Deprecated bool       `json:"deprecated,omitempty"`
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&SSHHostKey{})
+}
+
+// SSHHostKey 节点 SSH 主机公钥（known_hosts），按 host+port 唯一。
+// 首次连接时自动记录（TOFU），之后公钥不一致将拒绝连接，并将收到的公钥记录为待确认公钥
+type SSHHostKey struct {
+	pixiu.Model
+
+	Host        string `gorm:"column:host;type:varchar(128);not null;uniqueIndex:idx_host_port,priority:1" json:"host"`
+	Port        int    `gorm:"column:port;not null;uniqueIndex:idx_host_port,priority:2" json:"port"`
+	KeyType     string `gorm:"column:key_type;type:varchar(64)" json:"key_type"`
+	Fingerprint string `gorm:"column:fingerprint;type:varchar(128)" json:"fingerprint"` // SHA256:xxx
+	PublicKey   string `gorm:"column:public_key;type:text" json:"public_key"`           // authorized_keys 格式，仅固定指纹时为空
+	Pinned      bool   `gorm:"column:pinned;default:false" json:"pinned"`               // 管理员手动固定
+
+	// 最近一次校验失败时收到的公钥，确认主机确实更换了公钥后可通过 accept 接受
+	PendingKeyType     string `gorm:"column:pending_key_type;type:varchar(64)" json:"pending_key_type,omitempty"`
+	PendingFingerprint string `gorm:"column:pending_fingerprint;type:varchar(128)" json:"pending_fingerprint,omitempty"`
+	PendingPublicKey   string `gorm:"column:pending_public_key;type:text" json:"pending_public_key,omitempty"`
+
+	LastSeen *time.Time `gorm:"column:last_seen" json:"last_seen,omitempty"`
+}
+
+func (*SSHHostKey) TableName() string {
+	return "ssh_host_keys"
+}
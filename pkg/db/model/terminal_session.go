@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func init() {
+	register(&TerminalSession{})
+}
+
+// TerminalSessionKind 交互式终端类型
+type TerminalSessionKind string
+
+const (
+	TerminalSessionPod        TerminalSessionKind = "pod"        // Pod exec
+	TerminalSessionNode       TerminalSessionKind = "node"       // 节点 SSH
+	TerminalSessionCloudShell TerminalSessionKind = "cloudshell" // 集群 CloudShell
+)
+
+// TerminalSession 交互式终端会话录像（asciicast v2），关联会话建立时写入的审计记录
+type TerminalSession struct {
+	pixiu.Model
+
+	AuditId   int64               `gorm:"column:audit_id;index" json:"audit_id"`
+	Kind      TerminalSessionKind `gorm:"column:kind;type:varchar(32)" json:"kind"`
+	UserId    int64               `gorm:"column:user_id;index" json:"user_id"`
+	Operator  string              `gorm:"column:operator;type:varchar(255)" json:"operator"`
+	Ip        string              `gorm:"column:ip;type:varchar(128)" json:"ip"`
+	Cluster   string              `gorm:"column:cluster;type:varchar(255);index" json:"cluster"`
+	Namespace string              `gorm:"column:namespace;type:varchar(255)" json:"namespace"`
+	Pod       string              `gorm:"column:pod;type:varchar(255)" json:"pod"`
+	Container string              `gorm:"column:container;type:varchar(255)" json:"container"`
+	Node      string              `gorm:"column:node;type:varchar(128);index" json:"node"` // 节点 IP
+
+	StorageKey string     `gorm:"column:storage_key;type:varchar(255)" json:"storage_key"`
+	Size       int64      `gorm:"column:size;default:0" json:"size"`         // 录像大小（字节）
+	Duration   int64      `gorm:"column:duration;default:0" json:"duration"` // 会话时长（秒）
+	StartedAt  time.Time  `gorm:"column:started_at" json:"started_at"`
+	EndedAt    *time.Time `gorm:"column:ended_at" json:"ended_at,omitempty"` // 为空表示会话进行中或异常中断
+}
+
+func (*TerminalSession) TableName() string {
+	return "terminal_sessions"
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package db
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
 )
@@ -50,6 +52,15 @@ func WithCreatedBefore(t time.Time) Options {
 	}
 }
 
+func WithCreatedAfter(t time.Time) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if t.IsZero() {
+			return tx
+		}
+		return tx.Where("gmt_create >= ?", t)
+	}
+}
+
 func WithModifyOrderByDesc() Options {
 	return func(tx *gorm.DB) *gorm.DB {
 		return tx.Order("gmt_modified DESC")
@@ -202,6 +213,60 @@ func WithAuditCluster(cluster string) Options {
 	}
 }
 
+func WithAuditResource(resource string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("resource = ?", resource)
+	}
+}
+
+func WithAuditResourceName(name string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("resource_name = ?", name)
+	}
+}
+
+func WithAuditResourceNamespace(namespace string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("resource_namespace = ?", namespace)
+	}
+}
+
+func WithAuditTokenJTI(jti string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("token_jti = ?", jti)
+	}
+}
+
+func WithIdAfter(id int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id > ?", id)
+	}
+}
+
+func WithIdAtMost(id int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id <= ?", id)
+	}
+}
+
+func WithAuditCheckpointKind(kind model.AuditCheckpointKind) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("kind = ?", kind)
+	}
+}
+
+func WithAuditIdAfter(auditId int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("audit_id > ?", auditId)
+	}
+}
+
+func WithAuditId(auditId int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("audit_id = ?", auditId)
+	}
+}
+
 func WithPlan(pid int64) Options {
 	return func(tx *gorm.DB) *gorm.DB {
 		if pid == 0 {
@@ -443,3 +508,104 @@ func WithTokenHash(hash string) Options {
 		return tx.Where("token_hash = ?", hash)
 	}
 }
+
+func WithClusterEventType(t model.ClusterEventType) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if t == "" {
+			return tx
+		}
+		return tx.Where("type = ?", t)
+	}
+}
+
+func WithClusterEventObject(object string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if object == "" {
+			return tx
+		}
+		return tx.Where("object = ?", object)
+	}
+}
+
+// WithTerminalSession 按终端类型、集群、Pod 与节点过滤会话录像，空值不过滤
+func WithTerminalSession(kind model.TerminalSessionKind, cluster, pod, node string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if kind != "" {
+			tx = tx.Where("kind = ?", kind)
+		}
+		if cluster != "" {
+			tx = tx.Where("cluster = ?", cluster)
+		}
+		if pod != "" {
+			tx = tx.Where("pod = ?", pod)
+		}
+		if node != "" {
+			tx = tx.Where("node = ?", node)
+		}
+		return tx
+	}
+}
+
+// WithEtcdSnapshot 按快照状态与触发方式过滤，空值不过滤
+func WithEtcdSnapshot(status model.EtcdSnapshotStatus, trigger model.EtcdSnapshotTrigger) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if status != "" {
+			tx = tx.Where("status = ?", status)
+		}
+		if trigger != "" {
+			tx = tx.Where("trigger_type = ?", trigger)
+		}
+		return tx
+	}
+}
+
+// WithRepository 按 chart 仓库过滤，0 不过滤
+func WithRepository(repositoryId int64) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if repositoryId == 0 {
+			return tx
+		}
+		return tx.Where("repository_id = ?", repositoryId)
+	}
+}
+
+// WithChartSearch 按空格分隔的关键词搜索 chart 名称、描述与关键字，每个词都需命中；
+// 名称完全匹配与前缀匹配的结果排在前面
+func WithChartSearch(query string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		terms := strings.Fields(strings.ToLower(query))
+		if len(terms) == 0 {
+			return tx.Order("name ASC")
+		}
+		for _, term := range terms {
+			like := "%" + escapeLike(term) + "%"
+			tx = tx.Where("(LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR keywords LIKE ?)", like, like, like)
+		}
+		return tx.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "LOWER(name) = ? DESC, LOWER(name) LIKE ? DESC, name ASC",
+			Vars:               []interface{}{terms[0], escapeLike(terms[0]) + "%"},
+			WithoutParentheses: true,
+		}})
+	}
+}
+
+// WithChartKeyword 按 chart 关键字精确过滤
+func WithChartKeyword(keyword string) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			return tx
+		}
+		return tx.Where("keywords LIKE ?", "%,"+escapeLike(keyword)+",%")
+	}
+}
+
+// WithDeprecated 为 false 时排除已废弃的 chart
+func WithDeprecated(include bool) Options {
+	return func(tx *gorm.DB) *gorm.DB {
+		if include {
+			return tx
+		}
+		return tx.Where("deprecated = ?", false)
+	}
+}
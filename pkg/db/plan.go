@@ -68,10 +68,20 @@ type PlanInterface interface {
 	UpdateTaskBy(ctx context.Context, updates map[string]interface{}, opts ...Options) error
 	// UpdateStatus 直接回写 plan 冗余状态
 	UpdateStatus(ctx context.Context, planId int64, status model.TaskStatus) error
+	// ClaimStatus 以 resource_version 为条件将空闲的 plan 置为 status，
+	// 计划已被修改或处于运行中/停止中/销毁中时返回 ErrRecordNotUpdate
+	ClaimStatus(ctx context.Context, planId int64, resourceVersion int64, status model.TaskStatus) error
 
 	GetNewestTask(ctx context.Context, pid int64) (*model.Task, error)
 	GetTaskByName(ctx context.Context, planId int64, name string) (*model.Task, error)
 	GetTaskById(ctx context.Context, taskId int64) (*model.Task, error)
+
+	// HostKey 节点 SSH 主机公钥（known_hosts）
+	HostKey() HostKeyInterface
+	// EtcdBackup etcd 备份策略与快照
+	EtcdBackup() EtcdBackupInterface
+	// Certificate master 节点证书巡检结果
+	Certificate() CertificateInterface
 }
 
 type plan struct {
@@ -452,6 +462,24 @@ func (p *plan) UpdateStatus(ctx context.Context, planId int64, status model.Task
 	}).Error
 }
 
+func (p *plan) ClaimStatus(ctx context.Context, planId int64, resourceVersion int64, status model.TaskStatus) error {
+	f := p.db.WithContext(ctx).Model(&model.Plan{}).
+		Where("id = ? and resource_version = ?", planId, resourceVersion).
+		Where("status NOT IN ?", []model.TaskStatus{model.RunningPlanStatus, model.StoppingPlanStatus, model.DestroyingPlanStatus}).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"resource_version": resourceVersion + 1,
+			"gmt_modified":     time.Now(),
+		})
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return errors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
 func (p *plan) GetNewestTask(ctx context.Context, pid int64) (*model.Task, error) {
 	var objects []model.Task
 	if err := p.db.WithContext(ctx).Where("plan_id = ?", pid).Order("id DESC").Limit(1).Find(&objects).Error; err != nil {
@@ -485,3 +513,15 @@ func (p *plan) GetTaskByName(ctx context.Context, planId int64, name string) (*m
 func newPlan(db *gorm.DB) *plan {
 	return &plan{db}
 }
+
+func (p *plan) HostKey() HostKeyInterface {
+	return newHostKey(p.db)
+}
+
+func (p *plan) EtcdBackup() EtcdBackupInterface {
+	return newEtcdBackup(p.db)
+}
+
+func (p *plan) Certificate() CertificateInterface {
+	return newCertificate(p.db)
+}
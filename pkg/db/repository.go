@@ -32,6 +32,10 @@ type RepositoryInterface interface {
 	Get(ctx context.Context, id int64) (*model.Repository, error)
 	GetByName(ctx context.Context, name string) (*model.Repository, error)
 	List(ctx context.Context) ([]*model.Repository, error)
+	// UpdateSyncStatus 不校验 resource_version，用于回写 chart 目录同步状态
+	UpdateSyncStatus(ctx context.Context, id int64, updates map[string]interface{}) error
+
+	Chart() HelmChartInterface
 }
 
 type repository struct {
@@ -73,16 +77,21 @@ func (r *repository) Update(ctx context.Context, id int64, resourceVersion int64
 }
 
 func (r *repository) Delete(ctx context.Context, id int64) error {
-	f := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Repository{})
-	if f.Error != nil {
-		return f.Error
-	}
-
-	if f.RowsAffected == 0 {
-		return errors.ErrRecordNotFound
-	}
-
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("repository_id = ?", id).Delete(&model.HelmChart{}).Error; err != nil {
+			return err
+		}
+		f := tx.Where("id = ?", id).Delete(&model.Repository{})
+		if f.Error != nil {
+			return f.Error
+		}
+
+		if f.RowsAffected == 0 {
+			return errors.ErrRecordNotFound
+		}
+
+		return nil
+	})
 }
 
 func (r *repository) Get(ctx context.Context, id int64) (*model.Repository, error) {
@@ -111,3 +120,11 @@ func (r *repository) List(ctx context.Context) ([]*model.Repository, error) {
 
 	return repos, nil
 }
+
+func (r *repository) UpdateSyncStatus(ctx context.Context, id int64, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&model.Repository{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *repository) Chart() HelmChartInterface {
+	return newHelmChart(r.db)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type HostKeyInterface interface {
+	Create(ctx context.Context, object *model.SSHHostKey) (*model.SSHHostKey, error)
+	Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error
+	// InternalUpdate 不校验 resource_version，用于记录 last_seen 与待确认公钥
+	InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error
+	Delete(ctx context.Context, host string, port int) error
+	// Get 未找到时返回 nil, nil
+	Get(ctx context.Context, host string, port int) (*model.SSHHostKey, error)
+}
+
+type hostKey struct {
+	db *gorm.DB
+}
+
+func newHostKey(db *gorm.DB) HostKeyInterface {
+	return &hostKey{db: db}
+}
+
+func (h *hostKey) Create(ctx context.Context, object *model.SSHHostKey) (*model.SSHHostKey, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := h.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (h *hostKey) Update(ctx context.Context, id int64, resourceVersion int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	updates["resource_version"] = resourceVersion + 1
+
+	f := h.db.WithContext(ctx).Model(&model.SSHHostKey{}).Where("id = ? and resource_version = ?", id, resourceVersion).Updates(updates)
+	if f.Error != nil {
+		return f.Error
+	}
+	if f.RowsAffected == 0 {
+		return utilerrors.ErrRecordNotUpdate
+	}
+	return nil
+}
+
+func (h *hostKey) InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return h.db.WithContext(ctx).Model(&model.SSHHostKey{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (h *hostKey) Delete(ctx context.Context, host string, port int) error {
+	return h.db.WithContext(ctx).Where("host = ? and port = ?", host, port).Delete(&model.SSHHostKey{}).Error
+}
+
+func (h *hostKey) Get(ctx context.Context, host string, port int) (*model.SSHHostKey, error) {
+	var object model.SSHHostKey
+	if err := h.db.WithContext(ctx).Where("host = ? and port = ?", host, port).First(&object).Error; err != nil {
+		if utilerrors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/util/errors"
+)
+
+type TerminalSessionInterface interface {
+	Create(ctx context.Context, object *model.TerminalSession) (*model.TerminalSession, error)
+	// InternalUpdate 不校验 resource_version，用于会话结束时回写时长与大小
+	InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error
+	Get(ctx context.Context, id int64) (*model.TerminalSession, error)
+	List(ctx context.Context, opts ...Options) ([]model.TerminalSession, error)
+	Count(ctx context.Context, opts ...Options) (int64, error)
+	// BatchDelete 仅删除会话记录，录像文件由调用方按 storage_key 清理
+	BatchDelete(ctx context.Context, opts ...Options) (int64, error)
+}
+
+type terminalSession struct {
+	db *gorm.DB
+}
+
+func newTerminalSession(db *gorm.DB) TerminalSessionInterface {
+	return &terminalSession{db: db}
+}
+
+func (t *terminalSession) Create(ctx context.Context, object *model.TerminalSession) (*model.TerminalSession, error) {
+	now := time.Now()
+	object.GmtCreate = now
+	object.GmtModified = now
+	if err := t.db.WithContext(ctx).Create(object).Error; err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (t *terminalSession) InternalUpdate(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["gmt_modified"] = time.Now()
+	return t.db.WithContext(ctx).Model(&model.TerminalSession{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (t *terminalSession) Get(ctx context.Context, id int64) (*model.TerminalSession, error) {
+	var object model.TerminalSession
+	if err := t.db.WithContext(ctx).Where("id = ?", id).First(&object).Error; err != nil {
+		if errors.IsRecordNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (t *terminalSession) List(ctx context.Context, opts ...Options) ([]model.TerminalSession, error) {
+	var objects []model.TerminalSession
+	tx := t.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	if err := tx.Find(&objects).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (t *terminalSession) Count(ctx context.Context, opts ...Options) (int64, error) {
+	tx := t.db.WithContext(ctx).Model(&model.TerminalSession{})
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (t *terminalSession) BatchDelete(ctx context.Context, opts ...Options) (int64, error) {
+	tx := t.db.WithContext(ctx)
+	for _, opt := range opts {
+		tx = opt(tx)
+	}
+
+	result := tx.Delete(&model.TerminalSession{})
+	return result.RowsAffected, result.Error
+}
@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/sftp"
@@ -65,6 +66,8 @@ func fetchKubeconfig(ag *Agent, job *types.Job) error {
 			Name string `json:"name"`
 			Ip   string `json:"ip"`
 			Auth string `json:"auth"`
+			// 控制面记录的主机公钥指纹，为空表示尚未记录
+			HostKeyFingerprint string `json:"host_key_fingerprint"`
 		} `json:"masters"`
 	}
 	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
@@ -72,13 +75,24 @@ func fetchKubeconfig(ag *Agent, job *types.Job) error {
 	}
 	var lastErr error
 	for _, m := range payload.Masters {
-		cfg, err := sshGetAdminConf(m.Ip, m.Auth)
+		cfg, observed, err := sshGetAdminConf(m.Ip, m.Auth, m.HostKeyFingerprint)
+		if observed != nil {
+			_ = ag.Logs(job.Id, fmt.Sprintf("master %s: host key %s is not recorded by pixiu, trusted on first use\n", m.Ip, observed.Fingerprint))
+		}
 		if err != nil {
 			lastErr = err
 			_ = ag.Logs(job.Id, fmt.Sprintf("master %s: %v\n", m.Ip, err))
 			continue
 		}
-		return ag.Report(job.Id, true, "ok", base64.StdEncoding.EncodeToString(cfg))
+		// 首次信任的公钥随结果上报，由控制面记录，后续连接按记录严格校验
+		result, err := json.Marshal(types.FetchKubeconfigResult{
+			KubeConfig: base64.StdEncoding.EncodeToString(cfg),
+			HostKey:    observed.hostKey(),
+		})
+		if err != nil {
+			return err
+		}
+		return ag.Report(job.Id, true, "ok", string(result))
 	}
 	if lastErr == nil {
 		lastErr = fmt.Errorf("no master nodes in payload")
@@ -86,10 +100,30 @@ func fetchKubeconfig(ag *Agent, job *types.Job) error {
 	return lastErr
 }
 
-func sshGetAdminConf(ip, authJSON string) ([]byte, error) {
-	var auth types.PlanNodeAuth
+// observedHostKey 首次信任时观测到的主机公钥
+type observedHostKey struct {
+	Host        string
+	Port        int
+	Fingerprint string
+	PublicKey   string
+}
+
+func (o *observedHostKey) hostKey() *types.AgentHostKey {
+	if o == nil {
+		return nil
+	}
+	return &types.AgentHostKey{Host: o.Host, Port: o.Port, PublicKey: o.PublicKey}
+}
+
+// sshGetAdminConf 通过 SSH 读取 master 的 admin.conf。fingerprint 非空时严格校验主机公钥；
+// 为空时首次信任，并返回实际的公钥供上报控制面记录
+func sshGetAdminConf(ip, authJSON, fingerprint string) ([]byte, *observedHostKey, error) {
+	var (
+		auth     types.PlanNodeAuth
+		observed *observedHostKey
+	)
 	if err := auth.Unmarshal(authJSON); err != nil {
-		return nil, err
+		return nil, observed, err
 	}
 	var (
 		user       string
@@ -98,42 +132,59 @@ func sshGetAdminConf(ip, authJSON string) ([]byte, error) {
 	switch auth.Type {
 	case types.PasswordAuth:
 		if auth.Password == nil {
-			return nil, fmt.Errorf("password auth missing")
+			return nil, observed, fmt.Errorf("password auth missing")
 		}
 		user = auth.Password.User
 		authMethod = ssh.Password(auth.Password.Password)
 	case types.KeyAuth:
 		if auth.Key == nil {
-			return nil, fmt.Errorf("key auth missing")
+			return nil, observed, fmt.Errorf("key auth missing")
 		}
 		signer, err := ssh.ParsePrivateKey([]byte(auth.Key.Data))
 		if err != nil {
-			return nil, err
+			return nil, observed, err
 		}
 		user = "root"
 		authMethod = ssh.PublicKeys(signer)
 	default:
-		return nil, fmt.Errorf("unsupported auth type %v", auth.Type)
+		return nil, observed, fmt.Errorf("unsupported auth type %v", auth.Type)
+	}
+	hostKeyCallback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		actual := ssh.FingerprintSHA256(key)
+		if fingerprint == "" {
+			observed = &observedHostKey{
+				Host:        ip,
+				Port:        auth.SSHPort(),
+				Fingerprint: actual,
+				PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+			}
+			return nil
+		}
+		if actual != fingerprint {
+			return fmt.Errorf("ssh host key mismatch for %s: expected %s, got %s, possible man-in-the-middle attack", hostname, fingerprint, actual)
+		}
+		return nil
 	}
 	sshClient, err := ssh.Dial("tcp", net.JoinHostPort(ip, strconv.Itoa(auth.SSHPort())), &ssh.ClientConfig{
 		User:            user,
 		Auth:            []ssh.AuthMethod{authMethod},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         15 * time.Second,
 	})
 	if err != nil {
-		return nil, err
+		return nil, observed, err
 	}
 	defer sshClient.Close()
 	sftpClient, err := sftp.NewClient(sshClient)
 	if err != nil {
-		return nil, err
+		return nil, observed, err
 	}
 	defer sftpClient.Close()
 	f, err := sftpClient.Open("/etc/kubernetes/admin.conf")
 	if err != nil {
-		return nil, err
+		return nil, observed, err
 	}
 	defer f.Close()
-	return io.ReadAll(f)
+	cfg, err := io.ReadAll(f)
+	return cfg, observed, err
 }
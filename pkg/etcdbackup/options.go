@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	// DefaultSchedule 备份调度巡检周期，每分钟检查一次到期的备份策略
+	DefaultSchedule    = "* * * * *"
+	DefaultRetention   = 7
+	DefaultHelperImage = "busybox:1.36"
+	DefaultS3Region    = "us-east-1"
+
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+// Options etcd 备份配置，备份策略按部署计划保存在数据库中
+type Options struct {
+	Schedule string `yaml:"schedule"`
+	// pod 方式下用于读取快照文件的辅助镜像，需包含 sh 与 cat
+	HelperImage string         `yaml:"helper_image"`
+	Storage     StorageOptions `yaml:"storage"`
+}
+
+// StorageOptions 快照存储后端，支持本地目录与 S3 兼容对象存储
+type StorageOptions struct {
+	Type string `yaml:"type"`
+	// 本地存储目录，默认 <work_dir>/etcd-backups
+	Dir string    `yaml:"dir"`
+	S3  S3Options `yaml:"s3"`
+}
+
+type S3Options struct {
+	Endpoint  string `yaml:"endpoint"` // 例如 https://minio.example.com:9000
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	Prefix    string `yaml:"prefix"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+}
+
+func (o *Options) SetDefaults(workDir string) {
+	if o.Schedule == "" {
+		o.Schedule = DefaultSchedule
+	}
+	if o.HelperImage == "" {
+		o.HelperImage = DefaultHelperImage
+	}
+	if o.Storage.Type == "" {
+		o.Storage.Type = StorageLocal
+	}
+	if o.Storage.Dir == "" {
+		o.Storage.Dir = filepath.Join(workDir, "etcd-backups")
+	}
+	if o.Storage.S3.Region == "" {
+		o.Storage.S3.Region = DefaultS3Region
+	}
+}
+
+func (o *Options) Valid() error {
+	switch o.Storage.Type {
+	case StorageLocal, "":
+	case StorageS3:
+		s3 := o.Storage.S3
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			return fmt.Errorf("etcd_backup.storage.s3 需要配置 endpoint、bucket、access_key 与 secret_key")
+		}
+	default:
+		return fmt.Errorf("不支持的 etcd 备份存储类型 %q", o.Storage.Type)
+	}
+	return nil
+}
+
+// NewStorage 根据配置创建快照存储
+func (o *Options) NewStorage() (Storage, error) {
+	switch o.Storage.Type {
+	case StorageLocal, "":
+		return NewLocalStorage(o.Storage.Dir), nil
+	case StorageS3:
+		return NewS3Storage(o.Storage.S3), nil
+	}
+	return nil, fmt.Errorf("不支持的 etcd 备份存储类型 %q", o.Storage.Type)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/client"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/util/uuid"
+)
+
+const (
+	etcdNamespace     = "kube-system"
+	etcdLabelSelector = "component=etcd"
+	helperMountPath   = "/pixiu-etcd"
+)
+
+// podSnapshot 在已注册集群中执行快照：先在 etcd 静态 Pod 内保存快照到数据目录，
+// 再通过调度到同一节点、挂载数据目录的辅助 Pod 读取快照文件（etcd 镜像通常不含 shell）
+func (m *Manager) podSnapshot(ctx context.Context, planId int64, w io.Writer) (string, error) {
+	object, err := m.factory.Cluster().GetBy(ctx, db.WithPlan(planId))
+	if err != nil {
+		return "", err
+	}
+	if object == nil || len(object.KubeConfig) == 0 {
+		return "", fmt.Errorf("部署计划尚未注册集群，无法通过 Pod 采集快照")
+	}
+	cs, err := client.NewClusterSetWithOptions(object.KubeConfig, client.ClusterSetOptions{
+		ClusterName: object.Name,
+		ConnectMode: object.ConnectMode,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	pods, err := cs.Client.CoreV1().Pods(etcdNamespace).List(ctx, metav1.ListOptions{LabelSelector: etcdLabelSelector})
+	if err != nil {
+		return "", err
+	}
+	var etcdPod *v1.Pod
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == v1.PodRunning {
+			etcdPod = &pods.Items[i]
+			break
+		}
+	}
+	if etcdPod == nil {
+		return "", fmt.Errorf("集群中未找到运行中的 etcd 静态 Pod")
+	}
+	nodeName := etcdPod.Spec.NodeName
+
+	var stderr strings.Builder
+	save := append([]string{"etcdctl"}, strings.Fields(etcdClientFlags)...)
+	save = append(save, "snapshot", "save", snapshotTmpFile)
+	if err = podExec(ctx, cs, etcdPod.Name, "etcd", save, io.Discard, &stderr); err != nil {
+		return nodeName, fmt.Errorf("etcd Pod 内保存快照失败: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	helper, err := m.createHelperPod(ctx, cs, nodeName)
+	if err != nil {
+		return nodeName, err
+	}
+	defer func() {
+		if err := cs.Client.CoreV1().Pods(etcdNamespace).Delete(context.Background(), helper, metav1.DeleteOptions{}); err != nil {
+			klog.Warningf("failed to delete etcd backup helper pod %s: %v", helper, err)
+		}
+	}()
+
+	file := helperMountPath + strings.TrimPrefix(snapshotTmpFile, etcdDataDir)
+	stderr.Reset()
+	if err = podExec(ctx, cs, helper, "", []string{"cat", file}, w, &stderr); err != nil {
+		return nodeName, fmt.Errorf("读取快照文件失败: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err = podExec(ctx, cs, helper, "", []string{"rm", "-f", file}, io.Discard, io.Discard); err != nil {
+		klog.Warningf("failed to remove etcd snapshot temp file on node %s: %v", nodeName, err)
+	}
+	return nodeName, nil
+}
+
+// createHelperPod 创建挂载 etcd 数据目录的辅助 Pod 并等待其运行
+func (m *Manager) createHelperPod(ctx context.Context, cs *client.ClusterSet, nodeName string) (string, error) {
+	hostPathType := v1.HostPathDirectory
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pixiu-etcd-backup-" + strings.ToLower(uuid.NewRandName(8)),
+			Namespace: etcdNamespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "pixiu"},
+		},
+		Spec: v1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{{
+				Name:    "helper",
+				Image:   m.opts.HelperImage,
+				Command: []string{"sleep", "3600"},
+				VolumeMounts: []v1.VolumeMount{{
+					Name:      "etcd-data",
+					MountPath: helperMountPath,
+				}},
+			}},
+			Volumes: []v1.Volume{{
+				Name: "etcd-data",
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{Path: etcdDataDir, Type: &hostPathType},
+				},
+			}},
+		},
+	}
+	created, err := cs.Client.CoreV1().Pods(etcdNamespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("创建辅助 Pod 失败: %v", err)
+	}
+
+	err = wait.PollImmediateWithContext(ctx, 2*time.Second, 3*time.Minute, func(ctx context.Context) (bool, error) {
+		p, err := cs.Client.CoreV1().Pods(etcdNamespace).Get(ctx, created.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch p.Status.Phase {
+		case v1.PodRunning:
+			return true, nil
+		case v1.PodFailed, v1.PodSucceeded:
+			return false, fmt.Errorf("辅助 Pod 异常退出: %s", p.Status.Phase)
+		}
+		return false, nil
+	})
+	if err != nil {
+		_ = cs.Client.CoreV1().Pods(etcdNamespace).Delete(context.Background(), created.Name, metav1.DeleteOptions{})
+		return "", fmt.Errorf("等待辅助 Pod 运行失败: %v", err)
+	}
+	return created.Name, nil
+}
+
+func podExec(ctx context.Context, cs *client.ClusterSet, pod, container string, command []string, stdout, stderr io.Writer) error {
+	req := cs.Client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(etcdNamespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := client.NewSPDYExecutor(cs.Config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+// ParseSchedule 校验并解析标准 5 段 cron 表达式
+func ParseSchedule(schedule string) (cron.Schedule, error) {
+	return cron.ParseStandard(schedule)
+}
+
+// IsDue 判断自 last 之后的下一次触发时间是否已到
+func IsDue(schedule string, last, now time.Time) (bool, error) {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		return false, err
+	}
+	return !s.Next(last).After(now), nil
+}
+
+// RunDue 执行所有到期的备份策略，返回触发的快照数量
+func (m *Manager) RunDue(ctx context.Context) (int, error) {
+	policies, err := m.factory.Plan().EtcdBackup().ListPolicies(ctx, db.WithEnabled(true))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	triggered := 0
+	for _, policy := range policies {
+		last := policy.GmtModified
+		if policy.LastScheduleTime != nil && policy.LastScheduleTime.After(last) {
+			last = *policy.LastScheduleTime
+		}
+		due, err := IsDue(policy.Schedule, last, now)
+		if err != nil {
+			klog.Warningf("invalid etcd backup schedule %q for plan(%d): %v", policy.Schedule, policy.PlanId, err)
+			continue
+		}
+		if !due {
+			continue
+		}
+
+		// 先记录调度时间，避免备份失败时每次巡检都重复触发
+		if err = m.factory.Plan().EtcdBackup().InternalUpdatePolicy(ctx, policy.Id, map[string]interface{}{"last_schedule_time": now}); err != nil {
+			klog.Errorf("failed to update etcd backup policy(%d) schedule time: %v", policy.Id, err)
+			continue
+		}
+		snapshot, err := m.Begin(ctx, policy.PlanId, policy.Method, model.EtcdSnapshotScheduled)
+		if err != nil {
+			klog.Warningf("skip scheduled etcd backup for plan(%d): %v", policy.PlanId, err)
+			continue
+		}
+		triggered++
+
+		wg.Add(1)
+		go func(policy model.EtcdBackupPolicy, snapshot *model.EtcdSnapshot) {
+			defer wg.Done()
+			if err := m.Run(ctx, snapshot); err != nil {
+				return
+			}
+			if err := m.Prune(ctx, policy.PlanId, policy.Retention); err != nil {
+				klog.Errorf("failed to prune etcd snapshots for plan(%d): %v", policy.PlanId, err)
+			}
+		}(policy, snapshot)
+	}
+	wg.Wait()
+
+	return triggered, nil
+}
+
+// Prune 按保留份数清理定时快照，手动快照不受影响
+func (m *Manager) Prune(ctx context.Context, planId int64, retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+	snapshots, err := m.factory.Plan().EtcdBackup().ListSnapshots(ctx,
+		db.WithPlan(planId),
+		db.WithEtcdSnapshot("", model.EtcdSnapshotScheduled),
+		db.WithOrderByDesc(),
+	)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range ExpiredSnapshots(snapshots, retention) {
+		if err = m.Delete(ctx, &snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpiredSnapshots 输入按时间倒序的快照，保留最近 retention 份成功快照，
+// 最早一份保留快照之前的记录（含失败记录）均视为过期；运行中的快照不清理
+func ExpiredSnapshots(snapshots []model.EtcdSnapshot, retention int) []model.EtcdSnapshot {
+	var expired []model.EtcdSnapshot
+	kept := 0
+	for _, snapshot := range snapshots {
+		if snapshot.Status == model.EtcdSnapshotRunning {
+			continue
+		}
+		if kept >= retention {
+			expired = append(expired, snapshot)
+			continue
+		}
+		if snapshot.Status == model.EtcdSnapshotSucceeded {
+			kept++
+		}
+	}
+	return expired
+}
+
+// Delete 删除快照文件与记录
+func (m *Manager) Delete(ctx context.Context, snapshot *model.EtcdSnapshot) error {
+	if snapshot.Status == model.EtcdSnapshotSucceeded {
+		if err := m.storage.Delete(ctx, snapshot.StorageKey); err != nil {
+			return err
+		}
+	}
+	return m.factory.Plan().EtcdBackup().DeleteSnapshot(ctx, snapshot.Id)
+}
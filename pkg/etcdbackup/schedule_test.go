@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/db/model/pixiu"
+)
+
+func TestIsDue(t *testing.T) {
+	last := time.Date(2026, 10, 19, 2, 0, 0, 0, time.Local)
+	cases := []struct {
+		schedule string
+		now      time.Time
+		want     bool
+	}{
+		{"0 2 * * *", last.Add(time.Hour), false},
+		{"0 2 * * *", last.Add(24 * time.Hour), true},
+		{"*/30 * * * *", last.Add(29 * time.Minute), false},
+		{"*/30 * * * *", last.Add(30 * time.Minute), true},
+	}
+	for _, c := range cases {
+		got, err := IsDue(c.schedule, last, c.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("IsDue(%q, %s) = %v, want %v", c.schedule, c.now, got, c.want)
+		}
+	}
+
+	if _, err := IsDue("invalid", last, last); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
+
+func TestExpiredSnapshots(t *testing.T) {
+	snapshot := func(id int64, status model.EtcdSnapshotStatus) model.EtcdSnapshot {
+		return model.EtcdSnapshot{Model: pixiu.Model{Id: id}, Status: status}
+	}
+	// 按时间倒序
+	snapshots := []model.EtcdSnapshot{
+		snapshot(7, model.EtcdSnapshotRunning),
+		snapshot(6, model.EtcdSnapshotFailed),
+		snapshot(5, model.EtcdSnapshotSucceeded),
+		snapshot(4, model.EtcdSnapshotSucceeded),
+		snapshot(3, model.EtcdSnapshotFailed),
+		snapshot(2, model.EtcdSnapshotSucceeded),
+		snapshot(1, model.EtcdSnapshotSucceeded),
+	}
+
+	var ids []int64
+	for _, s := range ExpiredSnapshots(snapshots, 2) {
+		ids = append(ids, s.Id)
+	}
+	want := []int64{3, 2, 1}
+	if len(ids) != len(want) {
+		t.Fatalf("ExpiredSnapshots() = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ExpiredSnapshots() = %v, want %v", ids, want)
+		}
+	}
+
+	if got := ExpiredSnapshots(snapshots, 10); len(got) != 0 {
+		t.Fatalf("ExpiredSnapshots() with large retention = %v, want none", got)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"fmt"
+)
+
+// kubeadm 部署的 etcd 证书目录与静态 Pod 清单
+const (
+	etcdPKIDir      = "/etc/kubernetes/pki/etcd"
+	manifestsDir    = "/etc/kubernetes/manifests"
+	restoreStageDir = "/etc/kubernetes/pixiu-restore"
+
+	// etcdDataDir 宿主机与 etcd 静态 Pod 内路径一致，快照临时文件放在此处便于两种方式共用
+	etcdDataDir      = "/var/lib/etcd"
+	snapshotTmpFile  = etcdDataDir + "/pixiu-snapshot.db"
+	RestoreSnapshot  = "/var/lib/pixiu/etcd-restore.db"
+	etcdClientFlags  = "--endpoints=https://127.0.0.1:2379 --cacert=" + etcdPKIDir + "/ca.crt --cert=" + etcdPKIDir + "/server.crt --key=" + etcdPKIDir + "/server.key"
+	etcdCriSelector  = "crictl ps --name '^etcd$' -q 2>/dev/null | head -n1"
+	etcdDockerFilter = "docker ps -q --filter name=k8s_etcd_ 2>/dev/null | head -n1"
+)
+
+// snapshotScript 在 master 节点上保存快照并输出到标准输出，诊断信息输出到标准错误。
+// 优先使用宿主机 etcdctl，否则进入 etcd 容器执行
+var snapshotScript = `set -e
+SNAP=` + snapshotTmpFile + `
+rm -f $SNAP
+if command -v etcdctl >/dev/null 2>&1; then
+  ETCDCTL_API=3 etcdctl ` + etcdClientFlags + ` snapshot save $SNAP >&2
+elif command -v crictl >/dev/null 2>&1 && [ -n "$(` + etcdCriSelector + `)" ]; then
+  crictl exec "$(` + etcdCriSelector + `)" etcdctl ` + etcdClientFlags + ` snapshot save $SNAP >&2
+elif command -v docker >/dev/null 2>&1 && [ -n "$(` + etcdDockerFilter + `)" ]; then
+  docker exec -e ETCDCTL_API=3 "$(` + etcdDockerFilter + `)" etcdctl ` + etcdClientFlags + ` snapshot save $SNAP >&2
+else
+  echo "未找到 etcdctl 或运行中的 etcd 容器" >&2
+  exit 1
+fi
+cat $SNAP
+rm -f $SNAP
+`
+
+// RestoreUploadScript 接收标准输入中的快照文件并输出 sha256，用于校验上传完整性
+const RestoreUploadScript = `set -e
+mkdir -p $(dirname ` + RestoreSnapshot + `)
+cat > ` + RestoreSnapshot + `
+sha256sum ` + RestoreSnapshot + ` | awk '{print $1}'
+`
+
+// RestoreMemberScript 输出本节点 etcd 成员名称与 peer 地址，每行 key=value
+const RestoreMemberScript = `set -e
+MANIFEST=` + manifestsDir + `/etcd.yaml
+[ -f $MANIFEST ] || MANIFEST=` + restoreStageDir + `/etcd.yaml
+[ -f $MANIFEST ] || { echo "未找到 etcd 静态 Pod 清单" >&2; exit 1; }
+echo "name=$(grep -E -- '--name=' $MANIFEST | head -n1 | sed -E 's/.*--name=([^ "]+).*/\1/')"
+echo "peer=$(grep -E -- '--initial-advertise-peer-urls=' $MANIFEST | head -n1 | sed -E 's/.*--initial-advertise-peer-urls=([^ "]+).*/\1/')"
+`
+
+// RestoreStopScript 移出 kube-apiserver 与 etcd 静态 Pod 清单，并等待 etcd 停止
+const RestoreStopScript = `set -e
+mkdir -p ` + restoreStageDir + `
+for f in kube-apiserver.yaml etcd.yaml; do
+  if [ -f ` + manifestsDir + `/$f ]; then mv ` + manifestsDir + `/$f ` + restoreStageDir + `/$f; fi
+done
+[ -f ` + restoreStageDir + `/etcd.yaml ] || { echo "未找到 etcd 静态 Pod 清单" >&2; exit 1; }
+for i in $(seq 1 90); do
+  if [ -z "$(` + etcdCriSelector + `)" ] && [ -z "$(` + etcdDockerFilter + `)" ]; then
+    echo "etcd 已停止"
+    exit 0
+  fi
+  sleep 2
+done
+echo "等待 etcd 停止超时" >&2
+exit 1
+`
+
+// RestoreDataScript 备份原数据目录并从快照恢复。宿主机无 etcdutl/etcdctl 时，
+// 使用 etcd 静态 Pod 清单中的镜像通过 docker 或 ctr 执行恢复
+func RestoreDataScript(name, peerURL, initialCluster string) string {
+	args := fmt.Sprintf("snapshot restore %s --name=%s --initial-cluster=%s --initial-advertise-peer-urls=%s --data-dir=%s",
+		RestoreSnapshot, name, initialCluster, peerURL, etcdDataDir)
+	return `set -e
+DATA=` + etcdDataDir + `
+if [ -d $DATA ]; then
+  BAK=$DATA.pixiu-$(date +%Y%m%d%H%M%S)
+  mv $DATA $BAK
+  echo "原数据目录已备份至 $BAK"
+fi
+if command -v etcdutl >/dev/null 2>&1; then
+  etcdutl ` + args + `
+elif command -v etcdctl >/dev/null 2>&1; then
+  ETCDCTL_API=3 etcdctl ` + args + `
+else
+  IMAGE=$(grep -E 'image:' ` + restoreStageDir + `/etcd.yaml | head -n1 | awk '{print $2}')
+  [ -n "$IMAGE" ] || { echo "未找到 etcd 镜像" >&2; exit 1; }
+  if command -v docker >/dev/null 2>&1 && docker info >/dev/null 2>&1; then
+    docker run --rm --net=host -e ETCDCTL_API=3 -v /var/lib:/var/lib $IMAGE etcdctl ` + args + `
+  elif command -v ctr >/dev/null 2>&1; then
+    ctr -n k8s.io run --rm --net-host --env ETCDCTL_API=3 --mount type=bind,src=/var/lib,dst=/var/lib,options=rbind:rw $IMAGE pixiu-etcd-restore etcdctl ` + args + `
+  else
+    echo "未找到 etcdutl、etcdctl 或可用的容器运行时" >&2
+    exit 1
+  fi
+fi
+echo "etcd 数据已从快照恢复"
+`
+}
+
+// RestoreStartScript 移回静态 Pod 清单并清理上传的快照
+const RestoreStartScript = `set -e
+for f in etcd.yaml kube-apiserver.yaml; do
+  if [ -f ` + restoreStageDir + `/$f ]; then mv ` + restoreStageDir + `/$f ` + manifestsDir + `/$f; fi
+done
+rm -f ` + RestoreSnapshot + `
+echo "控制面静态 Pod 清单已恢复"
+`
+
+// RestoreWaitScript 等待 kube-apiserver 就绪
+func RestoreWaitScript(apiPort int) string {
+	return fmt.Sprintf(`for i in $(seq 1 150); do
+  if curl -sk https://127.0.0.1:%d/healthz | grep -q ok; then
+    echo "kube-apiserver 已就绪"
+    exit 0
+  fi
+  sleep 2
+done
+echo "等待 kube-apiserver 就绪超时" >&2
+exit 1
+`, apiPort)
+}
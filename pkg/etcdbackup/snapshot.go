@@ -0,0 +1,249 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+	sshutil "github.com/caoyingjunz/pixiu/pkg/util/ssh"
+)
+
+// 快照采集超时
+const snapshotTimeout = 30 * time.Minute
+
+// running 记录正在备份的 plan，同一 plan 同时只允许一个快照任务
+var running sync.Map
+
+// Manager 负责 etcd 快照的采集、存储与保留策略
+type Manager struct {
+	opts    Options
+	factory db.ShareDaoFactory
+	storage Storage
+}
+
+func NewManager(opts Options, f db.ShareDaoFactory) (*Manager, error) {
+	storage, err := opts.NewStorage()
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerWithStorage(opts, f, storage), nil
+}
+
+func NewManagerWithStorage(opts Options, f db.ShareDaoFactory, storage Storage) *Manager {
+	return &Manager{opts: opts, factory: f, storage: storage}
+}
+
+func (m *Manager) Storage() Storage {
+	return m.storage
+}
+
+// ErrBackupRunning 同一部署计划同时只允许一个备份
+var ErrBackupRunning = errors.New("已有正在进行的 etcd 备份")
+
+// Begin 创建运行中的快照记录并占用 plan 的备份锁，需随后调用 Run 完成采集
+func (m *Manager) Begin(ctx context.Context, planId int64, method model.EtcdBackupMethod, trigger model.EtcdSnapshotTrigger) (*model.EtcdSnapshot, error) {
+	if method == "" {
+		method = model.EtcdBackupSSH
+	}
+	if _, loaded := running.LoadOrStore(planId, struct{}{}); loaded {
+		return nil, ErrBackupRunning
+	}
+
+	now := time.Now()
+	object, err := m.factory.Plan().EtcdBackup().CreateSnapshot(ctx, &model.EtcdSnapshot{
+		PlanId:     planId,
+		Method:     method,
+		Trigger:    trigger,
+		StorageKey: fmt.Sprintf("etcd/%d/%s.db", planId, now.Format("20060102-150405")),
+		Status:     model.EtcdSnapshotRunning,
+	})
+	if err != nil {
+		running.Delete(planId)
+		return nil, err
+	}
+	return object, nil
+}
+
+// Run 采集快照并写入存储，结束后回写快照状态并释放备份锁
+func (m *Manager) Run(ctx context.Context, snapshot *model.EtcdSnapshot) error {
+	defer running.Delete(snapshot.PlanId)
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	updates := map[string]interface{}{}
+	node, size, checksum, err := m.take(ctx, snapshot)
+	if err != nil {
+		klog.Errorf("failed to take plan(%d) etcd snapshot(%d): %v", snapshot.PlanId, snapshot.Id, err)
+		updates["status"] = model.EtcdSnapshotFailed
+		updates["message"] = err.Error()
+	} else {
+		updates["status"] = model.EtcdSnapshotSucceeded
+		updates["size"] = size
+		updates["checksum"] = checksum
+		updates["message"] = ""
+	}
+	updates["node"] = node
+	updates["finished_at"] = time.Now()
+
+	// 采集可能耗时较长，回写状态时不使用已超时的上下文
+	if updateErr := m.factory.Plan().EtcdBackup().InternalUpdateSnapshot(context.Background(), snapshot.Id, updates); updateErr != nil {
+		klog.Errorf("failed to update etcd snapshot(%d) status: %v", snapshot.Id, updateErr)
+		if err == nil {
+			err = updateErr
+		}
+	}
+	return err
+}
+
+// Snapshot 同步完成一次快照
+func (m *Manager) Snapshot(ctx context.Context, planId int64, method model.EtcdBackupMethod, trigger model.EtcdSnapshotTrigger) (*model.EtcdSnapshot, error) {
+	snapshot, err := m.Begin(ctx, planId, method, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, m.Run(ctx, snapshot)
+}
+
+// take 将快照先落到临时文件并计算校验和，再写入存储
+func (m *Manager) take(ctx context.Context, snapshot *model.EtcdSnapshot) (string, int64, string, error) {
+	tmp, err := os.CreateTemp("", "pixiu-etcd-snapshot-*.db")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := &hashWriter{w: tmp, h: sha256.New()}
+	var node string
+	switch snapshot.Method {
+	case model.EtcdBackupPod:
+		node, err = m.podSnapshot(ctx, snapshot.PlanId, w)
+	default:
+		node, err = m.sshSnapshot(ctx, snapshot.PlanId, w)
+	}
+	if err != nil {
+		return node, 0, "", err
+	}
+	if w.n == 0 {
+		return node, 0, "", fmt.Errorf("快照内容为空")
+	}
+
+	checksum := hex.EncodeToString(w.h.Sum(nil))
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return node, 0, "", err
+	}
+	if err = m.storage.Put(ctx, snapshot.StorageKey, tmp, w.n, checksum); err != nil {
+		return node, 0, "", fmt.Errorf("写入备份存储失败: %v", err)
+	}
+	return node, w.n, checksum, nil
+}
+
+// MasterNodes 返回部署计划中的 master 节点
+func MasterNodes(nodes []model.Node) []model.Node {
+	var masters []model.Node
+	for _, node := range nodes {
+		for _, role := range strings.Split(node.Role, ",") {
+			if strings.TrimSpace(role) == "master" {
+				masters = append(masters, node)
+				break
+			}
+		}
+	}
+	return masters
+}
+
+// sshSnapshot 依次尝试 master 节点，直到成功采集快照
+func (m *Manager) sshSnapshot(ctx context.Context, planId int64, w io.Writer) (string, error) {
+	nodes, err := m.factory.Plan().ListNodes(ctx, planId)
+	if err != nil {
+		return "", err
+	}
+	masters := MasterNodes(nodes)
+	if len(masters) == 0 {
+		return "", fmt.Errorf("部署计划没有 master 节点")
+	}
+
+	var errs []string
+	for _, node := range masters {
+		var auth types.PlanNodeAuth
+		if err = auth.Unmarshal(node.Auth); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: 解析节点认证信息失败: %v", node.Name, err))
+			continue
+		}
+		// 失败的节点可能已写入部分数据，重试前需要丢弃
+		if err = resetWriter(w); err != nil {
+			return node.Name, err
+		}
+		var stderr strings.Builder
+		if err = sshutil.RunScript(ctx, node.Ip, &auth, snapshotScript, nil, w, &stderr); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v %s", node.Name, err, strings.TrimSpace(stderr.String())))
+			continue
+		}
+		return node.Name, nil
+	}
+	return "", fmt.Errorf("所有 master 节点采集快照失败: %s", strings.Join(errs, "; "))
+}
+
+type hashWriter struct {
+	w io.Writer
+	h hash.Hash
+	n int64
+}
+
+func (hw *hashWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	hw.n += int64(n)
+	return n, err
+}
+
+// resetWriter 丢弃已写入的内容
+func resetWriter(w io.Writer) error {
+	hw, ok := w.(*hashWriter)
+	if !ok || hw.n == 0 {
+		return nil
+	}
+	f, ok := hw.w.(*os.File)
+	if !ok {
+		return fmt.Errorf("无法重置快照写入")
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hw.h.Reset()
+	hw.n = 0
+	return nil
+}
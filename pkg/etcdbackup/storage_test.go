@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// testStorage 对任意存储实现执行写入、读取与删除
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.TODO()
+	data := []byte("etcd snapshot data")
+	key := "etcd/1/20261019-020000.db"
+
+	if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data)), checksum(data)); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Get() = %q, want %q", got, data)
+	}
+
+	if err = s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if _, err = s.Get(ctx, key); err != ErrSnapshotNotFound {
+		t.Fatalf("Get() after delete error = %v, want ErrSnapshotNotFound", err)
+	}
+	// 重复删除不报错
+	if err = s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() twice error: %v", err)
+	}
+
+	for _, bad := range []string{"", "/", "../escape.db", "etcd/../../escape.db"} {
+		if err = s.Put(ctx, bad, bytes.NewReader(data), int64(len(data)), checksum(data)); err == nil {
+			t.Errorf("Put(%q) expected error", bad)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	testStorage(t, NewLocalStorage(t.TempDir()))
+}
+
+// fakeS3 本地 S3 替身：校验签名头与请求体哈希，并在内存中保存对象
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		http.Error(w, "bad authorization: "+auth, http.StatusForbidden)
+		return
+	}
+	if _, err := time.Parse(s3TimeLayout, r.Header.Get("x-amz-date")); err != nil {
+		http.Error(w, "bad x-amz-date", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("x-amz-content-sha256") != checksum(body) {
+			http.Error(w, "payload hash mismatch", http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := NewS3Storage(S3Options{
+		Endpoint:  server.URL,
+		Bucket:    "backups",
+		Prefix:    "pixiu/",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+	})
+	testStorage(t, s)
+
+	// 对象路径为 path-style：/<bucket>/<prefix>/<key>
+	data := []byte("x")
+	if err := s.Put(context.TODO(), "etcd/2/a.db", bytes.NewReader(data), 1, checksum(data)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.objects["/backups/pixiu/etcd/2/a.db"]; !ok {
+		t.Fatalf("unexpected object paths: %v", fake.objects)
+	}
+}
+
+func TestS3SignatureDeterministic(t *testing.T) {
+	s := NewS3Storage(S3Options{Endpoint: "https://s3.example.com", Bucket: "b", AccessKey: "AKID", SecretKey: "secret"})
+	s.now = func() time.Time { return time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC) }
+
+	sign := func(path string) string {
+		req, _ := http.NewRequest(http.MethodGet, "https://s3.example.com"+path, nil)
+		s.sign(req, emptyPayloadHash)
+		return req.Header.Get("Authorization")
+	}
+	a, b := sign("/b/etcd/1/a.db"), sign("/b/etcd/1/a.db")
+	if a != b {
+		t.Fatalf("signature is not deterministic: %s != %s", a, b)
+	}
+	if !strings.Contains(a, "Credential=AKID/20261019/us-east-1/s3/aws4_request") {
+		t.Fatalf("unexpected credential scope: %s", a)
+	}
+	if a == sign("/b/etcd/1/b.db") {
+		t.Fatal("signature does not cover the request path")
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/auditchain"
+)
+
+// AuditCheckpointer 定期对审计哈希链链头签名
+type AuditCheckpointer struct {
+	cfg   auditchain.Options
+	chain *auditchain.Chain
+}
+
+func NewAuditCheckpointer(cfg auditchain.Options, chain *auditchain.Chain) *AuditCheckpointer {
+	return &AuditCheckpointer{cfg: cfg, chain: chain}
+}
+
+func (a *AuditCheckpointer) Name() string {
+	return "audit-checkpointer"
+}
+
+func (a *AuditCheckpointer) CronSpec() string {
+	return a.cfg.CheckpointSchedule
+}
+
+func (a *AuditCheckpointer) LogLevel() AccessLogLevel {
+	return AccessLogDebug
+}
+
+func (a *AuditCheckpointer) Do(ctx *JobContext) error {
+	cp, err := a.chain.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		ctx.WithLogFields(map[string]interface{}{
+			"audit_id": cp.AuditId,
+			"records":  cp.Count,
+		})
+	}
+	return nil
+}
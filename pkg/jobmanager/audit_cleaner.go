@@ -17,19 +17,30 @@ limitations under the License.
 package jobmanager
 
 import (
+	"context"
 	"time"
 
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/auditchain"
 	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/recording"
 )
 
 const (
 	DefaultSchedule     = "0 0 * * 6" // 每周六 0 点执行
 	DefaultDaysReserved = 30          // 保留 30 天的审计日志
+
+	sessionPurgeBatch = 500
 )
 
+// AuditsCleaner 清理过期审计记录，清理前写入哈希链锚点以保持剩余记录可校验；
+// 终端会话（含录像文件）与对象变更 diff 依附于审计记录，按相同保留期一并清理
 type AuditsCleaner struct {
-	cfg AuditOptions
-	dao db.ShareDaoFactory
+	cfg        AuditOptions
+	chain      *auditchain.Chain
+	dao        db.ShareDaoFactory
+	recordings recording.Storage
 }
 
 type AuditOptions struct {
@@ -44,10 +55,12 @@ func DefaultOptions() AuditOptions {
 	}
 }
 
-func NewAuditsCleaner(cfg AuditOptions, dao db.ShareDaoFactory) *AuditsCleaner {
+func NewAuditsCleaner(cfg AuditOptions, chain *auditchain.Chain, dao db.ShareDaoFactory, recordings recording.Storage) *AuditsCleaner {
 	return &AuditsCleaner{
-		cfg: cfg,
-		dao: dao,
+		cfg:        cfg,
+		chain:      chain,
+		dao:        dao,
+		recordings: recordings,
 	}
 }
 
@@ -70,12 +83,53 @@ func (ac *AuditsCleaner) Do(ctx *JobContext) (err error) {
 		"days_reserved": resv,
 		"deadline":      before,
 	}
-	entries["records_deleted"], err = ac.dao.Audit().BatchDelete(ctx, db.WithCreatedBefore(before))
-	ctx.WithLogFields(entries)
+	defer ctx.WithLogFields(entries)
 
+	if entries["records_deleted"], err = ac.chain.Purge(ctx, before); err != nil {
+		return
+	}
+	if entries["object_diffs_deleted"], err = ac.dao.Audit().ObjectDiff().BatchDelete(ctx, db.WithCreatedBefore(before)); err != nil {
+		return
+	}
+	entries["sessions_deleted"], err = ac.purgeSessions(ctx, before)
 	return
 }
 
+// purgeSessions 分批删除过期终端会话：先删录像文件再删记录，
+// 文件删除失败的会话保留记录，留待下次清理重试，避免留下无主录像
+func (ac *AuditsCleaner) purgeSessions(ctx context.Context, before time.Time) (int64, error) {
+	var lastId, deleted int64
+	for {
+		sessions, err := ac.dao.Audit().TerminalSession().List(ctx,
+			db.WithCreatedBefore(before), db.WithIdAfter(lastId), db.WithOrderByASC(), db.WithLimit(sessionPurgeBatch))
+		if err != nil {
+			return deleted, err
+		}
+
+		ids := make([]int64, 0, len(sessions))
+		for _, session := range sessions {
+			lastId = session.Id
+			if session.StorageKey != "" {
+				if err = ac.recordings.Delete(session.StorageKey); err != nil {
+					klog.Warningf("[AuditsCleaner] failed to delete recording %s of session %d: %v", session.StorageKey, session.Id, err)
+					continue
+				}
+			}
+			ids = append(ids, session.Id)
+		}
+		if len(ids) != 0 {
+			n, err := ac.dao.Audit().TerminalSession().BatchDelete(ctx, db.WithIDIn(ids...))
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if len(sessions) < sessionPurgeBatch {
+			return deleted, nil
+		}
+	}
+}
+
 func (a *AuditOptions) Valid() error {
 	// TODO
 	return nil
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/certs"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+)
+
+// CertificateChecker 巡检自建集群 master 节点证书有效期，并在到期前告警
+type CertificateChecker struct {
+	cfg     certs.Options
+	checker *certs.Checker
+}
+
+func NewCertificateChecker(cfg certs.Options, dao db.ShareDaoFactory) *CertificateChecker {
+	return &CertificateChecker{cfg: cfg, checker: certs.NewChecker(cfg, dao)}
+}
+
+func (c *CertificateChecker) Name() string {
+	return "certificate-checker"
+}
+
+func (c *CertificateChecker) CronSpec() string {
+	return c.cfg.Schedule
+}
+
+func (c *CertificateChecker) LogLevel() AccessLogLevel {
+	return AccessLogInfo
+}
+
+func (c *CertificateChecker) Do(ctx *JobContext) error {
+	checked, err := c.checker.RunAll(ctx)
+	ctx.WithLogFields(map[string]interface{}{"plans_checked": checked})
+	return err
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/chartrepo"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+)
+
+// ChartIndexer 定期刷新所有 Helm 仓库的 chart 目录缓存
+type ChartIndexer struct {
+	cfg     chartrepo.CatalogOptions
+	indexer *chartrepo.Indexer
+}
+
+func NewChartIndexer(cfg chartrepo.CatalogOptions, dao db.ShareDaoFactory) *ChartIndexer {
+	return &ChartIndexer{cfg: cfg, indexer: chartrepo.NewIndexer(cfg, dao)}
+}
+
+func (c *ChartIndexer) Name() string {
+	return "chart-indexer"
+}
+
+func (c *ChartIndexer) CronSpec() string {
+	return c.cfg.Schedule
+}
+
+func (c *ChartIndexer) LogLevel() AccessLogLevel {
+	return AccessLogInfo
+}
+
+func (c *ChartIndexer) Do(ctx *JobContext) error {
+	synced, failed, err := c.indexer.SyncAll(ctx)
+	ctx.WithLogFields(map[string]interface{}{
+		"repositories_synced": synced,
+		"repositories_failed": failed,
+	})
+	return err
+}
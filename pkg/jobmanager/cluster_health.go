@@ -0,0 +1,273 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/controller/alert/notify"
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	DefaultClusterHealthSchedule     = "30 2 * * *"
+	DefaultClusterHealthDaysReserved = 90
+	DefaultClusterFlapWindowMinutes  = 30
+	DefaultClusterFlapThreshold      = 4
+)
+
+// ClusterHealthOptions 集群健康历史配置
+type ClusterHealthOptions struct {
+	// 健康事件清理的 cron 表达式与保留天数（月度 SLA 统计至少需要 31 天）
+	Schedule     string `yaml:"schedule"`
+	DaysReserved int    `yaml:"days_reserved"`
+
+	// 抖动告警：FlapWindowMinutes 内集群状态变化次数 >= FlapThreshold 视为抖动，
+	// 向 NotifyChannels 指定的告警渠道发送通知；NotifyChannels 为空则不告警
+	FlapWindowMinutes int     `yaml:"flap_window_minutes"`
+	FlapThreshold     int     `yaml:"flap_threshold"`
+	NotifyChannels    []int64 `yaml:"notify_channels"`
+}
+
+func (o *ClusterHealthOptions) Valid() error {
+	return nil
+}
+
+func (o *ClusterHealthOptions) flapWindow() time.Duration {
+	return time.Duration(o.FlapWindowMinutes) * time.Minute
+}
+
+// ClusterHealthRecorder 对比 syncer 写库前后的集群状态，将状态迁移记录为健康事件，
+// 并在集群抖动时触发告警。ClusterSyncer 与 TunnelSyncer 共享同一实例。
+type ClusterHealthRecorder struct {
+	cfg     ClusterHealthOptions
+	factory db.ShareDaoFactory
+	notify  *notify.Manager
+
+	mu sync.Mutex
+	// lastAlert 记录集群最近一次抖动告警时间，同一窗口内只告警一次
+	lastAlert map[int64]time.Time
+}
+
+func NewClusterHealthRecorder(cfg ClusterHealthOptions, f db.ShareDaoFactory) *ClusterHealthRecorder {
+	return &ClusterHealthRecorder{
+		cfg:       cfg,
+		factory:   f,
+		notify:    notify.NewManager(f),
+		lastAlert: make(map[int64]time.Time),
+	}
+}
+
+// Record 根据即将写入的 updates 与当前集群记录生成健康事件。
+// 必须在 updates 成功写库后调用，updates 中未出现的字段视为未变化。
+func (r *ClusterHealthRecorder) Record(ctx context.Context, source string, cluster *model.Cluster, updates map[string]interface{}) {
+	if r == nil {
+		return
+	}
+
+	reason, _ := updates["probe_reason"].(string)
+	message, _ := updates["probe_message"].(string)
+	newEvent := func(t model.ClusterEventType, object, from, to string) *model.ClusterEvent {
+		return &model.ClusterEvent{
+			ClusterId:   cluster.Id,
+			ClusterName: cluster.Name,
+			Type:        t,
+			Source:      source,
+			Object:      object,
+			From:        from,
+			To:          to,
+			Reason:      reason,
+			Message:     message,
+		}
+	}
+
+	var events []*model.ClusterEvent
+	statusChanged := false
+	if status, ok := updates["status"].(model.ClusterStatus); ok && status != cluster.ClusterStatus {
+		statusChanged = true
+		events = append(events, newEvent(model.ClusterEventStatus, "",
+			model.ClusterStatusName(cluster.ClusterStatus), model.ClusterStatusName(status)))
+	}
+	if probe, ok := updates["probe_status"].(model.ClusterProbeStatus); ok && probe != cluster.ProbeStatus {
+		events = append(events, newEvent(model.ClusterEventProbe, "",
+			model.ClusterProbeStatusName(cluster.ProbeStatus), model.ClusterProbeStatusName(probe)))
+	}
+	if nodes, ok := updates["nodes"].(string); ok {
+		for _, d := range diffNodeStates(cluster.Nodes, nodes) {
+			e := newEvent(model.ClusterEventNode, d.name, d.from, d.to)
+			e.Reason, e.Message = "NodeStatusChanged", ""
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := r.factory.Cluster().Event().BatchCreate(ctx, events); err != nil {
+		klog.Errorf("failed to record health events for cluster(%s): %v", cluster.Name, err)
+		return
+	}
+	if statusChanged {
+		r.checkFlapping(ctx, cluster)
+	}
+}
+
+// checkFlapping 统计窗口内的状态变化次数，超过阈值时发送抖动告警
+func (r *ClusterHealthRecorder) checkFlapping(ctx context.Context, cluster *model.Cluster) {
+	if len(r.cfg.NotifyChannels) == 0 || r.cfg.FlapThreshold <= 0 || r.cfg.FlapWindowMinutes <= 0 {
+		return
+	}
+
+	now := time.Now()
+	window := r.cfg.flapWindow()
+	r.mu.Lock()
+	last, alerted := r.lastAlert[cluster.Id]
+	r.mu.Unlock()
+	if alerted && now.Sub(last) < window {
+		return
+	}
+
+	changes, err := r.factory.Cluster().Event().Count(ctx,
+		db.WithClusterId(cluster.Id),
+		db.WithClusterEventType(model.ClusterEventStatus),
+		db.WithCreatedAfter(now.Add(-window)),
+	)
+	if err != nil {
+		klog.Errorf("failed to count status changes for cluster(%s): %v", cluster.Name, err)
+		return
+	}
+	if changes < int64(r.cfg.FlapThreshold) {
+		return
+	}
+
+	r.mu.Lock()
+	r.lastAlert[cluster.Id] = now
+	r.mu.Unlock()
+
+	name := cluster.AliasName
+	if name == "" {
+		name = cluster.Name
+	}
+	title := fmt.Sprintf("集群 %s 状态抖动", name)
+	content := fmt.Sprintf("集群 %s(%s) 在最近 %d 分钟内状态变化 %d 次（阈值 %d），请检查控制面与网络连通性。",
+		name, cluster.Name, r.cfg.FlapWindowMinutes, changes, r.cfg.FlapThreshold)
+	if err = r.notify.EnqueueMessage(ctx, r.cfg.NotifyChannels, title, content, model.AlertSeverityWarning); err != nil {
+		klog.Errorf("failed to enqueue flapping notification for cluster(%s): %v", cluster.Name, err)
+	}
+}
+
+type nodeStateChange struct {
+	name, from, to string
+}
+
+// diffNodeStates 对比新旧节点 JSON，返回状态发生变化的节点。
+// 任一侧为空（首次同步或集群失联清空节点）时不产生节点事件，失联由集群状态事件表达。
+func diffNodeStates(oldData, newData string) []nodeStateChange {
+	if oldData == "" || newData == "" || oldData == newData {
+		return nil
+	}
+	oldStates, err := parseNodeStates(oldData)
+	if err != nil {
+		return nil
+	}
+	newStates, err := parseNodeStates(newData)
+	if err != nil {
+		return nil
+	}
+
+	names := make(map[string]struct{}, len(oldStates)+len(newStates))
+	for name := range oldStates {
+		names[name] = struct{}{}
+	}
+	for name := range newStates {
+		names[name] = struct{}{}
+	}
+
+	var changes []nodeStateChange
+	for name := range names {
+		from, ok := oldStates[name]
+		if !ok {
+			from = model.NodeStateAbsent
+		}
+		to, ok := newStates[name]
+		if !ok {
+			to = model.NodeStateAbsent
+		}
+		if from != to {
+			changes = append(changes, nodeStateChange{name: name, from: from, to: to})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].name < changes[j].name })
+	return changes
+}
+
+func parseNodeStates(data string) (map[string]string, error) {
+	var kubeNode types.KubeNode
+	if err := kubeNode.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	states := make(map[string]string, len(kubeNode.Ready)+len(kubeNode.NotReady))
+	for _, name := range kubeNode.Ready {
+		states[name] = model.NodeStateReady
+	}
+	for _, name := range kubeNode.NotReady {
+		states[name] = model.NodeStateNotReady
+	}
+	return states, nil
+}
+
+// ClusterEventsCleaner 定期清理过期的集群健康事件
+type ClusterEventsCleaner struct {
+	cfg ClusterHealthOptions
+	dao db.ShareDaoFactory
+}
+
+func NewClusterEventsCleaner(cfg ClusterHealthOptions, dao db.ShareDaoFactory) *ClusterEventsCleaner {
+	return &ClusterEventsCleaner{cfg: cfg, dao: dao}
+}
+
+func (cc *ClusterEventsCleaner) Name() string {
+	return "cluster-events-cleaner"
+}
+
+func (cc *ClusterEventsCleaner) CronSpec() string {
+	return cc.cfg.Schedule
+}
+
+func (cc *ClusterEventsCleaner) LogLevel() AccessLogLevel {
+	return AccessLogInfo
+}
+
+func (cc *ClusterEventsCleaner) Do(ctx *JobContext) (err error) {
+	resv := cc.cfg.DaysReserved
+	before := time.Now().AddDate(0, 0, -resv)
+	entries := map[string]interface{}{
+		"days_reserved": resv,
+		"deadline":      before,
+	}
+	entries["records_deleted"], err = cc.dao.Cluster().Event().Delete(ctx, db.WithCreatedBefore(before))
+	ctx.WithLogFields(entries)
+	return
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+func nodeData(ready, notReady []string) string {
+	data, _ := (&types.KubeNode{Ready: ready, NotReady: notReady}).Marshal()
+	return data
+}
+
+func TestDiffNodeStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []nodeStateChange
+	}{
+		{name: "first sync", old: "", new: nodeData([]string{"a"}, nil)},
+		{name: "cluster lost", old: nodeData([]string{"a"}, nil), new: ""},
+		{name: "unchanged", old: nodeData([]string{"a"}, []string{"b"}), new: nodeData([]string{"a"}, []string{"b"})},
+		{name: "invalid json", old: "{", new: nodeData([]string{"a"}, nil)},
+		{
+			name: "ready flips",
+			old:  nodeData([]string{"a", "b"}, nil),
+			new:  nodeData([]string{"b"}, []string{"a"}),
+			want: []nodeStateChange{{name: "a", from: "Ready", to: "NotReady"}},
+		},
+		{
+			name: "nodes added and removed",
+			old:  nodeData([]string{"c"}, []string{"b"}),
+			new:  nodeData([]string{"a", "b"}, nil),
+			want: []nodeStateChange{
+				{name: "a", from: "Absent", to: "Ready"},
+				{name: "b", from: "NotReady", to: "Ready"},
+				{name: "c", from: "Ready", to: "Absent"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffNodeStates(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffNodeStates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -41,13 +41,15 @@ type ClusterSyncer struct {
 	factory  db.ShareDaoFactory
 	disabled bool
 	backoff  *probeBackoff
+	health   *ClusterHealthRecorder
 }
 
-func NewClusterSyncer(f db.ShareDaoFactory, disabled bool) *ClusterSyncer {
+func NewClusterSyncer(f db.ShareDaoFactory, disabled bool, health *ClusterHealthRecorder) *ClusterSyncer {
 	return &ClusterSyncer{
 		factory:  f,
 		disabled: disabled,
 		backoff:  newProbeBackoff(),
+		health:   health,
 	}
 }
 
@@ -146,6 +148,9 @@ func (cs *ClusterSyncer) doSync(cluster model.Cluster) error {
 		connected = false
 		probeReason = "ProbeFailed"
 		probeMessage = err.Error()
+		if cluster.CredentialExpiresAt != nil && cluster.CredentialExpiresAt.Before(now) {
+			probeReason = "CredentialExpired"
+		}
 	}
 	cs.backoff.markResult(cluster.Name, connected, now)
 
@@ -158,7 +163,9 @@ func (cs *ClusterSyncer) doSync(cluster model.Cluster) error {
 
 	if err = cs.factory.Cluster().InternalUpdate(context.TODO(), cluster.Id, updates); err != nil {
 		klog.Errorf("failed to update cluster(%s) status: %v", cluster.Name, err)
+		return nil
 	}
+	cs.health.Record(context.TODO(), cs.Name(), &cluster, updates)
 	return nil
 }
 
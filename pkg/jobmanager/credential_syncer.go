@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"context"
+
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+const (
+	DefaultCredentialSyncSchedule = "0 * * * *" // 每小时执行
+)
+
+// ClusterCredentialSyncer 由 cluster 控制器实现，刷新集群凭据过期信息、自动轮换并告警
+type ClusterCredentialSyncer interface {
+	SyncCredentials(ctx context.Context) (*types.CredentialSyncResult, error)
+}
+
+// CredentialSyncer 定期检查集群 kubeconfig 凭据有效期
+type CredentialSyncer struct {
+	schedule string
+	syncer   ClusterCredentialSyncer
+}
+
+func NewCredentialSyncer(schedule string, syncer ClusterCredentialSyncer) *CredentialSyncer {
+	return &CredentialSyncer{schedule: schedule, syncer: syncer}
+}
+
+func (c *CredentialSyncer) Name() string {
+	return "credential-syncer"
+}
+
+func (c *CredentialSyncer) CronSpec() string {
+	return c.schedule
+}
+
+func (c *CredentialSyncer) LogLevel() AccessLogLevel {
+	return AccessLogInfo
+}
+
+func (c *CredentialSyncer) Do(ctx *JobContext) error {
+	result, err := c.syncer.SyncCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.WithLogFields(map[string]interface{}{
+		"clusters_checked": result.Checked,
+		"clusters_rotated": result.Rotated,
+		"clusters_warned":  result.Warned,
+	})
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobmanager
+
+import (
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/etcdbackup"
+)
+
+// EtcdBackupScheduler 按各部署计划的备份策略触发 etcd 定时快照并清理过期快照
+type EtcdBackupScheduler struct {
+	cfg etcdbackup.Options
+	dao db.ShareDaoFactory
+}
+
+func NewEtcdBackupScheduler(cfg etcdbackup.Options, dao db.ShareDaoFactory) *EtcdBackupScheduler {
+	return &EtcdBackupScheduler{cfg: cfg, dao: dao}
+}
+
+func (s *EtcdBackupScheduler) Name() string {
+	return "etcd-backup-scheduler"
+}
+
+func (s *EtcdBackupScheduler) CronSpec() string {
+	return s.cfg.Schedule
+}
+
+func (s *EtcdBackupScheduler) LogLevel() AccessLogLevel {
+	return AccessLogDebug
+}
+
+func (s *EtcdBackupScheduler) Do(ctx *JobContext) error {
+	manager, err := etcdbackup.NewManager(s.cfg, s.dao)
+	if err != nil {
+		return err
+	}
+	triggered, err := manager.RunDue(ctx)
+	ctx.WithLogFields(map[string]interface{}{"snapshots_triggered": triggered})
+	return err
+}
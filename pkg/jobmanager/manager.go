@@ -17,9 +17,14 @@ limitations under the License.
 package jobmanager
 
 import (
+	"time"
+
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/caoyingjunz/pixiu/pkg/accesslog"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
+	"github.com/caoyingjunz/pixiu/pkg/tracing"
 )
 
 type Job interface {
@@ -51,7 +56,13 @@ func NewManager(lc *accesslog.Options, jobs ...Job) *Manager {
 		job := job
 		_, _ = c.AddFunc(job.CronSpec(), func() {
 			ctx := NewJobContext(job.Name(), lc)
-			ctx.Log(job.LogLevel(), job.Do(ctx))
+			var span trace.Span
+			ctx.Context, span = tracing.Start(ctx.Context, "job."+job.Name())
+
+			err := job.Do(ctx)
+			tracing.End(span, err)
+			metrics.ObserveJobRun(job.Name(), time.Since(ctx.startTime), err)
+			ctx.Log(job.LogLevel(), err)
 		})
 	}
 	return &Manager{c}
@@ -25,6 +25,7 @@ import (
 	"github.com/caoyingjunz/pixiu/pkg/client"
 	"github.com/caoyingjunz/pixiu/pkg/db"
 	"github.com/caoyingjunz/pixiu/pkg/db/model"
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
 	"github.com/caoyingjunz/pixiu/pkg/tunnel"
 	utilerrors "github.com/caoyingjunz/pixiu/pkg/util/errors"
 )
@@ -39,12 +40,14 @@ const (
 type TunnelSyncer struct {
 	factory db.ShareDaoFactory
 	backoff *probeBackoff
+	health  *ClusterHealthRecorder
 }
 
-func NewTunnelSyncer(f db.ShareDaoFactory) *TunnelSyncer {
+func NewTunnelSyncer(f db.ShareDaoFactory, health *ClusterHealthRecorder) *TunnelSyncer {
 	return &TunnelSyncer{
 		factory: f,
 		backoff: newProbeBackoff(),
+		health:  health,
 	}
 }
 
@@ -117,6 +120,7 @@ func (ts *TunnelSyncer) checkTunnelCluster(ctx context.Context, tm *tunnel.Manag
 
 	connected, reason, message := probeTunnelL7(ctx, tm, obj)
 	ts.backoff.markResult(obj.Name, connected, now)
+	metrics.ObserveTunnelProbe(obj.Name, connected, reason)
 
 	prev := tm.AgentConnected(obj.Name)
 	tm.SetAgentConnected(obj.Name, connected)
@@ -143,6 +147,7 @@ func (ts *TunnelSyncer) checkTunnelCluster(ctx context.Context, tm *tunnel.Manag
 		}
 		return err
 	}
+	ts.health.Record(ctx, ts.Name(), obj, updates)
 
 	klog.V(2).Infof("[TunnelSyncer] cluster %s status %d -> %d (agent_connected=%v)", obj.Name, obj.ClusterStatus, desired, connected)
 	return nil
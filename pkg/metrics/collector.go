@@ -0,0 +1,63 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterTunnelSessions 暴露当前在线的 Agent 隧道会话数
+func RegisterTunnelSessions(count func() int) {
+	MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "tunnel",
+		Name:      "sessions",
+		Help:      "Number of connected agent tunnel sessions.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// CacheSizer 返回 集群 -> 资源 -> 缓存对象数
+type CacheSizer func() map[string]map[string]int
+
+type informerCacheCollector struct {
+	desc  *prometheus.Desc
+	sizer CacheSizer
+}
+
+// RegisterInformerCache 暴露各集群 informer 缓存中的对象数，抓取时实时统计
+func RegisterInformerCache(sizer CacheSizer) {
+	MustRegister(&informerCacheCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "informer", "cache_objects"),
+			"Number of objects in the informer cache by cluster and resource.",
+			[]string{"cluster", "resource"}, nil,
+		),
+		sizer: sizer,
+	})
+}
+
+func (c *informerCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *informerCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for cluster, resources := range c.sizer() {
+		for resource, n := range resources {
+			ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), cluster, resource)
+		}
+	}
+}
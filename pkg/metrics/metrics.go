@@ -0,0 +1,201 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pixiu"
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// registry 独立于 prometheus.DefaultRegisterer，避免依赖库注册的指标混入
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "code"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	planTaskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "plan",
+		Name:      "task_duration_seconds",
+		Help:      "Plan task execution duration by task and result.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"task", "result"})
+	planTaskFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "plan",
+		Name:      "task_failures_total",
+		Help:      "Total number of failed plan tasks.",
+	}, []string{"task"})
+
+	alertEvaluationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "alert",
+		Name:      "evaluation_duration_seconds",
+		Help:      "Alert rule evaluation latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"rule_id", "rule"})
+	alertEvaluationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alert",
+		Name:      "evaluation_errors_total",
+		Help:      "Total number of failed alert rule evaluations.",
+	}, []string{"rule_id", "rule"})
+
+	notificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alert",
+		Name:      "notifications_total",
+		Help:      "Total number of alert notification sends by channel type and result.",
+	}, []string{"channel", "result"})
+
+	tunnelProbesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tunnel",
+		Name:      "probes_total",
+		Help:      "Total number of agent tunnel probes by cluster, result and failure reason.",
+	}, []string{"cluster", "result", "reason"})
+
+	jobRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "runs_total",
+		Help:      "Total number of background job runs by job and result.",
+	}, []string{"job", "result"})
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "duration_seconds",
+		Help:      "Background job run duration.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		planTaskDuration,
+		planTaskFailures,
+		alertEvaluationDuration,
+		alertEvaluationErrors,
+		notificationsTotal,
+		tunnelProbesTotal,
+		jobRunsTotal,
+		jobDuration,
+	)
+}
+
+// Handler 返回 /metrics 处理器，token 非空时校验 Bearer Token
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return h
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// MustRegister 注册额外的采集器，如数据库连接池、informer 缓存
+func MustRegister(cs ...prometheus.Collector) {
+	registry.MustRegister(cs...)
+}
+
+// RegisterDBStats 暴露数据库连接池统计
+func RegisterDBStats(name string, db *sql.DB) {
+	MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ObserveHTTPRequest route 为 gin 路由模板，未匹配路由时调用方应传入固定占位，避免标签基数膨胀
+func ObserveHTTPRequest(method, route string, code int, elapsed time.Duration) {
+	status := strconv.Itoa(code)
+	httpRequestsTotal.WithLabelValues(method, route, status).Inc()
+	httpRequestDuration.WithLabelValues(method, route, status).Observe(elapsed.Seconds())
+}
+
+func ObservePlanTask(task string, elapsed time.Duration, err error) {
+	planTaskDuration.WithLabelValues(task, result(err)).Observe(elapsed.Seconds())
+	if err != nil {
+		planTaskFailures.WithLabelValues(task).Inc()
+	}
+}
+
+func ObserveAlertEvaluation(ruleId int64, rule string, elapsed time.Duration, err error) {
+	id := strconv.FormatInt(ruleId, 10)
+	alertEvaluationDuration.WithLabelValues(id, rule).Observe(elapsed.Seconds())
+	if err != nil {
+		alertEvaluationErrors.WithLabelValues(id, rule).Inc()
+	}
+}
+
+func ObserveNotification(channel string, err error) {
+	notificationsTotal.WithLabelValues(channel, result(err)).Inc()
+}
+
+// ObserveTunnelProbe reason 为探测结论，如 Healthy、AgentDisconnected
+func ObserveTunnelProbe(cluster string, connected bool, reason string) {
+	r := ResultSuccess
+	if !connected {
+		r = ResultFailure
+	}
+	tunnelProbesTotal.WithLabelValues(cluster, r, reason).Inc()
+}
+
+func ObserveJobRun(job string, elapsed time.Duration, err error) {
+	jobRunsTotal.WithLabelValues(job, result(err)).Inc()
+	jobDuration.WithLabelValues(job).Observe(elapsed.Seconds())
+}
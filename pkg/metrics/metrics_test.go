@@ -0,0 +1,53 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerBearerToken(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "/pixiu/clusters", http.StatusOK, 10*time.Millisecond)
+
+	h := Handler("secret")
+	cases := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, DefaultPath, nil)
+		if c.auth != "" {
+			req.Header.Set("Authorization", c.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("auth %q: got status %d, want %d", c.auth, rec.Code, c.want)
+		}
+		if rec.Code == http.StatusOK && !strings.Contains(rec.Body.String(), `pixiu_http_requests_total{code="200",method="GET",route="/pixiu/clusters"} 1`) {
+			t.Errorf("http request counter not exported:\n%s", rec.Body.String())
+		}
+	}
+}
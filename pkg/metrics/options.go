@@ -0,0 +1,53 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultPath = "/metrics"
+
+// Options Prometheus 指标暴露配置
+type Options struct {
+	// 默认开启
+	Enabled *bool  `yaml:"enabled"`
+	Path    string `yaml:"path"`
+	// 抓取鉴权，设置后请求需携带 Authorization: Bearer <token>；留空则不鉴权
+	BearerToken string `yaml:"bearer_token"`
+}
+
+func (o *Options) IsEnabled() bool {
+	if o == nil || o.Enabled == nil {
+		return true
+	}
+	return *o.Enabled
+}
+
+func (o *Options) SetDefaults() {
+	if o.Path == "" {
+		o.Path = DefaultPath
+	}
+}
+
+func (o *Options) Valid() error {
+	if !strings.HasPrefix(o.Path, "/") {
+		return fmt.Errorf("metrics.path 必须以 / 开头: %s", o.Path)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// asciicast v2 事件类型，参考 https://docs.asciinema.org/manual/asciicast/v2/
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+const (
+	defaultWidth  = 150
+	defaultHeight = 30
+)
+
+// Header asciicast v2 文件头
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder 以 asciicast v2 格式记录终端会话，并发安全。
+// 写入失败后不再记录，避免影响终端本身的交互
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.WriteCloser
+	start   time.Time
+	written int64
+	err     error
+	closed  bool
+
+	// 终端输出可能在多字节字符中间被截断，暂存不完整的 UTF-8 尾部，与下一段数据合并后再记录
+	pending map[string][]byte
+}
+
+// NewRecorder 写入文件头并返回记录器，width/height 为 0 时使用默认终端大小
+func NewRecorder(w io.WriteCloser, width, height int, title string) (*Recorder, error) {
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+	start := time.Now()
+	r := &Recorder{w: w, start: start, pending: make(map[string][]byte)}
+	header, err := json.Marshal(Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = r.writeLine(header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Output 记录终端输出
+func (r *Recorder) Output(p []byte) {
+	r.record(EventOutput, p)
+}
+
+// Input 记录用户输入
+func (r *Recorder) Input(p []byte) {
+	r.record(EventInput, p)
+}
+
+// Resize 记录终端大小变化
+func (r *Recorder) Resize(cols, rows int) {
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	r.record(EventResize, []byte(fmt.Sprintf("%dx%d", cols, rows)))
+}
+
+func (r *Recorder) record(code string, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+
+	data := p
+	if code != EventResize {
+		data = append(r.pending[code], p...)
+		n := completeUTF8(data)
+		r.pending[code] = append([]byte(nil), data[n:]...)
+		data = data[:n]
+		if len(data) == 0 {
+			return
+		}
+	}
+	r.writeEvent(code, data)
+}
+
+func (r *Recorder) writeEvent(code string, data []byte) {
+	elapsed := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{float64(int64(elapsed*1e6)) / 1e6, code, string(data)})
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.err = r.writeLine(line)
+}
+
+func (r *Recorder) writeLine(line []byte) error {
+	n, err := r.w.Write(append(line, '\n'))
+	r.written += int64(n)
+	return err
+}
+
+// Size 返回已写入的字节数
+func (r *Recorder) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.written
+}
+
+// Duration 返回会话已持续的时长
+func (r *Recorder) Duration() time.Duration {
+	return time.Since(r.start)
+}
+
+// Err 返回记录过程中的第一个写入错误
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close 写出暂存的数据并关闭底层存储
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	for _, code := range []string{EventInput, EventOutput} {
+		if data := r.pending[code]; len(data) != 0 && r.err == nil {
+			r.writeEvent(code, data)
+		}
+	}
+	if err := r.w.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// completeUTF8 返回 p 中以完整 UTF-8 字符结尾的前缀长度；非法字节不做暂存
+func completeUTF8(p []byte) int {
+	// UTF-8 字符最长 4 字节，只需检查末尾 3 个字节是否为不完整字符的开头
+	for i := len(p) - 1; i >= 0 && i >= len(p)-3; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			return i
+		}
+		break
+	}
+	return len(p)
+}
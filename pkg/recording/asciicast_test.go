@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	buf := &bufferCloser{}
+	r, err := NewRecorder(buf, 0, 0, "admin@pixiu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Input([]byte("ls\r"))
+	// "你" 为 3 字节，拆成两段输出
+	out := []byte("你好\r\n")
+	r.Output(out[:2])
+	r.Output(out[2:])
+	r.Resize(80, 24)
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !buf.closed {
+		t.Fatal("underlying writer is not closed")
+	}
+	if r.Size() != int64(buf.Len()) {
+		t.Fatalf("Size() = %d, want %d", r.Size(), buf.Len())
+	}
+
+	scanner := bufio.NewScanner(&buf.Buffer)
+	if !scanner.Scan() {
+		t.Fatal("missing header")
+	}
+	var header Header
+	if err = json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != defaultWidth || header.Height != defaultHeight || header.Title != "admin@pixiu" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	expected := [][2]string{
+		{EventInput, "ls\r"},
+		{EventOutput, "你好\r\n"},
+		{EventResize, "80x24"},
+	}
+	var events [][2]string
+	for scanner.Scan() {
+		var event []interface{}
+		if err = json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 {
+			t.Fatalf("unexpected event: %s", scanner.Text())
+		}
+		if _, ok := event[0].(float64); !ok {
+			t.Fatalf("event time is not a number: %s", scanner.Text())
+		}
+		events = append(events, [2]string{event[1].(string), event[2].(string)})
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(expected), events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("event %d = %q, want %q", i, events[i], expected[i])
+		}
+	}
+
+	// 关闭后不再记录
+	size := r.Size()
+	r.Output([]byte("after close"))
+	if r.Size() != size {
+		t.Fatal("recorder should not write after close")
+	}
+}
+
+func TestLocalStorageRejectsTraversal(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+	for _, key := range []string{"", "/", "../x.cast", "a/../../x.cast"} {
+		if _, err := s.Create(key); err == nil {
+			t.Fatalf("Create(%q) should fail", key)
+		}
+	}
+	w, err := s.Create("2026/10/19/1.cast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	if _, err = s.Create("2026/10/19/1.cast"); err == nil {
+		t.Fatal("existing recording should not be overwritten")
+	}
+}
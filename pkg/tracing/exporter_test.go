@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracesURL(t *testing.T) {
+	cases := []struct {
+		endpoint string
+		insecure bool
+		want     string
+	}{
+		{"collector:4318", true, "http://collector:4318/v1/traces"},
+		{"collector:4318", false, "https://collector:4318/v1/traces"},
+		{"https://otel.example.com/", false, "https://otel.example.com/v1/traces"},
+		{"https://otel.example.com/otlp/v1/traces", true, "https://otel.example.com/otlp/v1/traces"},
+	}
+	for _, c := range cases {
+		got, err := tracesURL(c.endpoint, c.insecure)
+		if err != nil {
+			t.Fatalf("tracesURL(%q): %v", c.endpoint, err)
+		}
+		if got != c.want {
+			t.Errorf("tracesURL(%q) = %q, want %q", c.endpoint, got, c.want)
+		}
+	}
+}
+
+func TestExporterExportSpans(t *testing.T) {
+	var (
+		got    otlpRequest
+		header string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != defaultTracesPath {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		header = r.Header.Get("X-Token")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	exp, err := newExporter(Options{Endpoint: srv.URL, Headers: map[string]string{"X-Token": "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exp),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "pixiu"))),
+	)
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	_, parent := tp.Tracer("test").Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	parent.End()
+
+	if header != "secret" {
+		t.Errorf("custom header not sent, got %q", header)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	rs := got.ResourceSpans[0]
+	if len(rs.Resource.Attributes) != 1 || *rs.Resource.Attributes[0].Value.StringValue != "pixiu" {
+		t.Errorf("unexpected resource attributes: %+v", rs.Resource.Attributes)
+	}
+	span := rs.ScopeSpans[0].Spans[0]
+	if span.Name != "parent" || span.Kind != int(trace.SpanKindServer) || span.ParentSpanId != "" {
+		t.Errorf("unexpected parent span: %+v", span)
+	}
+	if span.TraceId != parent.SpanContext().TraceID().String() {
+		t.Errorf("trace id = %s, want %s", span.TraceId, parent.SpanContext().TraceID())
+	}
+}
+
+func TestEncodeSpanStatusAndAttributes(t *testing.T) {
+	var spans []sdktrace.ReadOnlySpan
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporterFunc(func(s []sdktrace.ReadOnlySpan) {
+		spans = append(spans, s...)
+	})))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, child := tp.Tracer("test").Start(ctx, "child", trace.WithAttributes(
+		attribute.Int64("rows", 3),
+		attribute.StringSlice("tags", []string{"a", "b"}),
+	))
+	End(child, errors.New("boom"))
+	parent.End()
+
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	span := encodeSpan(spans[0])
+	if span.ParentSpanId != parent.SpanContext().SpanID().String() {
+		t.Errorf("parent span id = %s, want %s", span.ParentSpanId, parent.SpanContext().SpanID())
+	}
+	if span.Status.Code != 2 || span.Status.Message != "boom" {
+		t.Errorf("unexpected status: %+v", span.Status)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("error event not recorded: %+v", span.Events)
+	}
+	if *span.Attributes[0].Value.IntValue != "3" || len(span.Attributes[1].Value.ArrayValue.Values) != 2 {
+		t.Errorf("unexpected attributes: %+v", span.Attributes)
+	}
+}
+
+type exporterFunc func([]sdktrace.ReadOnlySpan)
+
+func (f exporterFunc) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	f(spans)
+	return nil
+}
+
+func (f exporterFunc) Shutdown(context.Context) error { return nil }
@@ -0,0 +1,81 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "pixiu:tracing_span"
+
+// RegisterGorm 为每条 SQL 生成 span，父 span 取自 gorm 语句的 context
+func RegisterGorm(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", beforeGorm("gorm.create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", afterGorm),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", beforeGorm("gorm.query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", afterGorm),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", beforeGorm("gorm.update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", afterGorm),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeGorm("gorm.delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterGorm),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", beforeGorm("gorm.row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", afterGorm),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeGorm("gorm.raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterGorm),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func beforeGorm(name string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		_, span := Tracer().Start(tx.Statement.Context, name, trace.WithSpanKind(trace.SpanKindClient))
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterGorm(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	// 只记录带占位符的 SQL，不记录参数值
+	span.SetAttributes(
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"fmt"
+)
+
+const (
+	DefaultServiceName = "pixiu"
+	DefaultSampleRatio = 1.0
+)
+
+// Options OTLP 链路追踪配置，默认关闭
+type Options struct {
+	Enabled bool `yaml:"enabled"`
+	// OTLP/HTTP 接收端地址，例如 otel-collector:4318 或 https://otel.example.com/v1/traces
+	Endpoint string `yaml:"endpoint"`
+	// endpoint 未指定协议时使用 http 而非 https
+	Insecure bool `yaml:"insecure"`
+	// 附加请求头，例如接收端鉴权
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"service_name"`
+	// 采样比例 (0, 1]，按 trace id 采样并遵循上游的采样决定
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+func (o *Options) SetDefaults() {
+	if o.ServiceName == "" {
+		o.ServiceName = DefaultServiceName
+	}
+	if o.SampleRatio == 0 {
+		o.SampleRatio = DefaultSampleRatio
+	}
+}
+
+func (o *Options) Valid() error {
+	if !o.Enabled {
+		return nil
+	}
+	if o.Endpoint == "" {
+		return fmt.Errorf("tracing.endpoint 不能为空")
+	}
+	if o.SampleRatio < 0 || o.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio 必须在 (0, 1] 之间: %v", o.SampleRatio)
+	}
+	return nil
+}
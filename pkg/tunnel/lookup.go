@@ -18,16 +18,37 @@ package tunnel
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/caoyingjunz/pixiu/pkg/db"
 )
 
-// FactoryLookup looks up cluster names by agent tunnel token via DAO.
+// allowedTargetsTTL bounds how long an allow-list change made on another
+// Pixiu instance takes to apply here; local changes invalidate immediately.
+const allowedTargetsTTL = 30 * time.Second
+
+// FactoryLookup looks up cluster names by agent tunnel token and the
+// in-cluster tunnel allow-list via DAO.
 type FactoryLookup struct {
 	Factory db.ShareDaoFactory
+
+	mu      sync.Mutex
+	targets map[string]cachedTargets
+}
+
+type cachedTargets struct {
+	targets   []string
+	expiresAt time.Time
+}
+
+func NewFactoryLookup(f db.ShareDaoFactory) *FactoryLookup {
+	return &FactoryLookup{Factory: f, targets: make(map[string]cachedTargets)}
 }
 
-func (l FactoryLookup) ClusterName(ctx context.Context, token string) (string, error) {
+func (l *FactoryLookup) ClusterName(ctx context.Context, token string) (string, error) {
 	if token == "" {
 		return "", nil
 	}
@@ -40,3 +61,46 @@ func (l FactoryLookup) ClusterName(ctx context.Context, token string) (string, e
 	}
 	return obj.Name, nil
 }
+
+// AllowedTargets is called on every service dial, so results are cached for allowedTargetsTTL.
+func (l *FactoryLookup) AllowedTargets(ctx context.Context, clusterName string) ([]string, error) {
+	if clusterName == "" {
+		return nil, nil
+	}
+	l.mu.Lock()
+	cached, ok := l.targets[clusterName]
+	l.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.targets, nil
+	}
+
+	targets, err := l.loadTargets(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.targets[clusterName] = cachedTargets{targets: targets, expiresAt: time.Now().Add(allowedTargetsTTL)}
+	l.mu.Unlock()
+	return targets, nil
+}
+
+func (l *FactoryLookup) InvalidateTargets(clusterName string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.targets, clusterName)
+}
+
+func (l *FactoryLookup) loadTargets(ctx context.Context, clusterName string) ([]string, error) {
+	obj, err := l.Factory.Cluster().GetBy(ctx, db.WithName(clusterName))
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil || strings.TrimSpace(obj.TunnelTargets) == "" {
+		return nil, nil
+	}
+	var targets []string
+	if err = json.Unmarshal([]byte(obj.TunnelTargets), &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
@@ -26,6 +26,8 @@ import (
 
 	"github.com/rancher/remotedialer"
 	"k8s.io/klog/v2"
+
+	"github.com/caoyingjunz/pixiu/pkg/metrics"
 )
 
 const (
@@ -67,6 +69,7 @@ func Init(lookup TokenLookup) *Manager {
 			return proto == "tcp"
 		}
 		defaultManager = m
+		metrics.RegisterTunnelSessions(m.SessionCount)
 		klog.Info("tunnel manager initialized")
 	})
 	return defaultManager
@@ -141,18 +144,79 @@ func (m *Manager) SeenSession(clusterName string) bool {
 	return m.seen != nil && m.seen[clusterName]
 }
 
+// SessionCount returns the number of agent tunnels currently connected to this process.
+func (m *Manager) SessionCount() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	names := make([]string, 0, len(m.seen))
+	for name := range m.seen {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	n := 0
+	for _, name := range names {
+		if m.HasSession(name) {
+			n++
+		}
+	}
+	return n
+}
+
 // Dialer returns a net.Dialer-compatible function that dials through the agent tunnel.
 func (m *Manager) Dialer(clusterName string) func(context.Context, string, string) (net.Conn, error) {
 	return m.server.Dialer(clusterName)
 }
 
 // ClusterDialContext returns a DialContext for rest.Config.
+// The kubeconfig host is dialed as-is so external load balancers and proxies keep
+// working, and the agent's allow-list decides whether it is reachable; loopback or
+// unresolvable hosts are meaningless to the agent and are sent to APIServerAlias.
 // If the agent is offline it returns ErrAgentDisconnected.
 func (m *Manager) ClusterDialContext(clusterName string) func(ctx context.Context, network, address string) (net.Conn, error) {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		if m == nil || !m.HasSession(clusterName) {
 			return nil, ErrAgentDisconnected
 		}
-		return m.Dialer(clusterName)(ctx, network, address)
+		return m.Dialer(clusterName)(ctx, network, apiServerDialAddress(ctx, net.DefaultResolver, address))
+	}
+}
+
+// hostResolver is satisfied by *net.Resolver.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// apiServerDialAddress returns the address the agent should dial for kube-apiserver.
+func apiServerDialAddress(ctx context.Context, resolver hostResolver, address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return APIServerAlias
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return APIServerAlias
+		}
+		return address
+	}
+	if strings.EqualFold(strings.TrimSuffix(host, "."), "localhost") {
+		return APIServerAlias
+	}
+	if _, err = resolver.LookupHost(ctx, host); err != nil {
+		klog.V(2).Infof("apiserver host %s is unresolvable, dialing %s through the tunnel: %v", host, APIServerAlias, err)
+		return APIServerAlias
+	}
+	return address
+}
+
+// InvalidateTargets drops the cached allow-list of a cluster after it is updated.
+func (m *Manager) InvalidateTargets(clusterName string) {
+	if m == nil {
+		return
+	}
+	if c, ok := m.lookup.(interface{ InvalidateTargets(string) }); ok {
+		c.InvalidateTargets(clusterName)
 	}
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caoyingjunz/pixiu/pkg/db"
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+type fakeResolver map[string]bool
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if r[host] {
+		return []string{"203.0.113.10"}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestAPIServerDialAddress(t *testing.T) {
+	resolver := fakeResolver{"lb.example.com": true}
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"203.0.113.10:6443", "203.0.113.10:6443"},
+		{"lb.example.com:443", "lb.example.com:443"},
+		{"127.0.0.1:6443", APIServerAlias},
+		{"[::1]:6443", APIServerAlias},
+		{"localhost:6443", APIServerAlias},
+		{"0.0.0.0:6443", APIServerAlias},
+		{"apiserver.internal:6443", APIServerAlias},
+		{"bad-address", APIServerAlias},
+	}
+	for _, tt := range tests {
+		if got := apiServerDialAddress(context.TODO(), resolver, tt.address); got != tt.want {
+			t.Errorf("apiServerDialAddress(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+type fakeFactory struct {
+	db.ShareDaoFactory
+	clusters *fakeClusters
+}
+
+func (f *fakeFactory) Cluster() db.ClusterInterface { return f.clusters }
+
+type fakeClusters struct {
+	db.ClusterInterface
+	object *model.Cluster
+	gets   int
+}
+
+func (c *fakeClusters) GetBy(_ context.Context, _ ...db.Options) (*model.Cluster, error) {
+	c.gets++
+	return c.object, nil
+}
+
+func TestAllowedTargetsCache(t *testing.T) {
+	clusters := &fakeClusters{object: &model.Cluster{Name: "cluster-a", TunnelTargets: `["prometheus.monitoring:9090"]`}}
+	l := NewFactoryLookup(&fakeFactory{clusters: clusters})
+
+	for i := 0; i < 3; i++ {
+		targets, err := l.AllowedTargets(context.TODO(), "cluster-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(targets) != 1 || targets[0] != "prometheus.monitoring:9090" {
+			t.Fatalf("unexpected targets %v", targets)
+		}
+	}
+	if clusters.gets != 1 {
+		t.Errorf("expected allow-list to be loaded once, got %d", clusters.gets)
+	}
+
+	clusters.object.TunnelTargets = `["loki.logging:3100"]`
+	l.InvalidateTargets("cluster-a")
+	targets, err := l.AllowedTargets(context.TODO(), "cluster-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0] != "loki.logging:3100" || clusters.gets != 2 {
+		t.Errorf("expected reload after invalidation, got %v (%d loads)", targets, clusters.gets)
+	}
+}
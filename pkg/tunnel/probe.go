@@ -18,16 +18,17 @@ package tunnel
 
 import (
 	"context"
-	"fmt"
-	"net"
-	"net/url"
-	"strings"
 	"time"
 )
 
 const (
 	// DefaultProbeTimeout bounds a single control-plane tunnel dial probe.
 	DefaultProbeTimeout = 5 * time.Second
+
+	// APIServerAlias 集群内 kube-apiserver 的地址，用于 Probe，以及 kubeconfig 中 apiserver
+	// 地址为回环或服务端无法解析时的替代；agent 将其解析为集群内 kubernetes Service，
+	// TLS 校验仍以 kubeconfig 的 server 主机名为准。
+	APIServerAlias = "kubernetes.default.svc:443"
 )
 
 // AgentConnected returns the last control-plane probe result when available,
@@ -71,18 +72,12 @@ func (m *Manager) ClearAgentConnected(clusterName string) {
 }
 
 // Probe reports whether the agent tunnel is usable for the given cluster.
-// It requires an active session and a successful TCP dial to apiServerURL
-// through the tunnel (kube-apiserver host from kubeconfig).
-func (m *Manager) Probe(ctx context.Context, clusterName, apiServerURL string) bool {
+// It requires an active session and a successful TCP dial to kube-apiserver
+// through the tunnel (via APIServerAlias, resolved by the agent in-cluster).
+func (m *Manager) Probe(ctx context.Context, clusterName string) bool {
 	if m == nil || !m.HasSession(clusterName) {
 		return false
 	}
-	addr, err := dialAddressFromAPIServer(apiServerURL)
-	if err != nil || addr == "" {
-		// Session exists but we cannot derive a probe target; treat as connected
-		// at the transport layer only.
-		return true
-	}
 
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -90,37 +85,10 @@ func (m *Manager) Probe(ctx context.Context, clusterName, apiServerURL string) b
 		defer cancel()
 	}
 
-	conn, err := m.Dialer(clusterName)(ctx, "tcp", addr)
+	conn, err := m.Dialer(clusterName)(ctx, "tcp", APIServerAlias)
 	if err != nil {
 		return false
 	}
 	_ = conn.Close()
 	return true
 }
-
-func dialAddressFromAPIServer(apiServerURL string) (string, error) {
-	host := strings.TrimSpace(apiServerURL)
-	if host == "" {
-		return "", fmt.Errorf("empty api server url")
-	}
-	if !strings.Contains(host, "://") {
-		host = "https://" + host
-	}
-	u, err := url.Parse(host)
-	if err != nil {
-		return "", err
-	}
-	if u.Hostname() == "" {
-		return "", fmt.Errorf("missing host in %q", apiServerURL)
-	}
-	port := u.Port()
-	if port == "" {
-		switch u.Scheme {
-		case "http":
-			port = "80"
-		default:
-			port = "443"
-		}
-	}
-	return net.JoinHostPort(u.Hostname(), port), nil
-}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// AnyPort 允许目标服务的任意端口，例如 "prometheus.monitoring:*"
+const AnyPort = "*"
+
+var ErrTargetNotAllowed = errors.New("tunnel target is not in the cluster allow-list")
+
+// TargetLookup resolves the in-cluster TCP targets a tunnel cluster allows
+// Pixiu to dial besides kube-apiserver.
+type TargetLookup interface {
+	AllowedTargets(ctx context.Context, clusterName string) ([]string, error)
+}
+
+// Target 集群内服务地址，统一为 <service>.<namespace>:<port> 形式比较
+type Target struct {
+	Service   string
+	Namespace string
+	Port      string
+}
+
+func (t Target) String() string {
+	return t.Service + "." + t.Namespace + ":" + t.Port
+}
+
+// Match 判断 other 是否命中当前（允许名单中的）目标
+func (t Target) Match(other Target) bool {
+	if t.Service != other.Service || t.Namespace != other.Namespace {
+		return false
+	}
+	return t.Port == AnyPort || t.Port == other.Port
+}
+
+// ParseTarget 解析集群内 Service 地址，支持 svc.ns:port、svc.ns.svc:port 与
+// svc.ns.svc.cluster.local:port；IP 地址与缺少命名空间的主机名会被拒绝。
+func ParseTarget(address string) (Target, error) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(address))
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid target %q: %v", address, err)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return Target{}, fmt.Errorf("target %q must be an in-cluster service name", address)
+	}
+	if port != AnyPort {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return Target{}, fmt.Errorf("invalid port in target %q", address)
+		}
+	}
+
+	parts := strings.Split(host, ".")
+	// 去掉 svc / svc.<cluster-domain> 后缀，仅保留 service 与 namespace
+	for i, p := range parts {
+		if p == "svc" {
+			parts = parts[:i]
+			break
+		}
+	}
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Target{}, fmt.Errorf("target %q must be in the form <service>.<namespace>:<port>", address)
+	}
+
+	return Target{Service: parts[0], Namespace: parts[1], Port: port}, nil
+}
+
+// NormalizeTargets 校验并去重允许名单，返回规范化后的地址列表
+func NormalizeTargets(targets []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(targets))
+	out := make([]string, 0, len(targets))
+	for _, raw := range targets {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		t, err := ParseTarget(raw)
+		if err != nil {
+			return nil, err
+		}
+		key := t.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, key)
+	}
+	return out, nil
+}
+
+// TargetAllowed 判断 address 是否在集群的隧道允许名单中
+func (m *Manager) TargetAllowed(ctx context.Context, clusterName, address string) (bool, error) {
+	if m == nil {
+		return false, nil
+	}
+	lookup, ok := m.lookup.(TargetLookup)
+	if !ok {
+		return false, nil
+	}
+	target, err := ParseTarget(address)
+	if err != nil || target.Port == AnyPort {
+		return false, nil
+	}
+	allowed, err := lookup.AllowedTargets(ctx, clusterName)
+	if err != nil {
+		return false, err
+	}
+	for _, raw := range allowed {
+		a, err := ParseTarget(raw)
+		if err != nil {
+			continue
+		}
+		if a.Match(target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ServiceDialContext returns a DialContext that reaches allow-listed in-cluster
+// services through the agent tunnel. The agent resolves the service name with
+// the cluster DNS, so address is passed through unchanged.
+func (m *Manager) ServiceDialContext(clusterName string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if m == nil || !m.HasSession(clusterName) {
+			return nil, ErrAgentDisconnected
+		}
+		if network != "tcp" {
+			return nil, fmt.Errorf("unsupported tunnel network %q", network)
+		}
+		ok, err := m.TargetAllowed(ctx, clusterName, address)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTargetNotAllowed, address)
+		}
+		return m.Dialer(clusterName)(ctx, network, address)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import "testing"
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		want    string
+		wantErr bool
+	}{
+		{name: "short", address: "prometheus.monitoring:9090", want: "prometheus.monitoring:9090"},
+		{name: "svc suffix", address: "prometheus.monitoring.svc:9090", want: "prometheus.monitoring:9090"},
+		{name: "fqdn", address: "Redis.DB.svc.cluster.local.:6379", want: "redis.db:6379"},
+		{name: "any port", address: "loki.logging:*", want: "loki.logging:*"},
+		{name: "ip", address: "10.0.0.1:6379", wantErr: true},
+		{name: "no namespace", address: "redis:6379", wantErr: true},
+		{name: "no port", address: "redis.db", wantErr: true},
+		{name: "bad port", address: "redis.db:70000", wantErr: true},
+		{name: "pod dns", address: "redis-0.redis.db.svc:6379", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTarget(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTarget(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseTarget(%q) = %q, want %q", tt.address, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetMatch(t *testing.T) {
+	allowed, _ := ParseTarget("redis.db:*")
+	target, _ := ParseTarget("redis.db.svc.cluster.local:6379")
+	if !allowed.Match(target) {
+		t.Errorf("expected %s to match %s", target, allowed)
+	}
+	other, _ := ParseTarget("redis.cache:6379")
+	if allowed.Match(other) {
+		t.Errorf("expected %s not to match %s", other, allowed)
+	}
+}
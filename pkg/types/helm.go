@@ -16,12 +16,20 @@ limitations under the License.
 
 package types
 
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
 type Release struct {
-	Name    string                 `json:"name" binding:"required"`
-	Chart   string                 `json:"chart" binding:"required"`
-	Version string                 `json:"version" binding:"required"`
-	Values  map[string]interface{} `json:"values"`
-	Preview bool                   `json:"preview"`
+	Name string `json:"name" binding:"required"`
+	// 指定仓库时 chart 为仓库内的 chart 名称，否则为 chart 地址（支持 oci://）
+	RepositoryId int64                  `json:"repository_id"`
+	Chart        string                 `json:"chart" binding:"required"`
+	Version      string                 `json:"version" binding:"required"`
+	Values       map[string]interface{} `json:"values"`
+	Preview      bool                   `json:"preview"`
 }
 
 type RepoId struct {
@@ -37,25 +45,195 @@ type RepoURL struct {
 	Url string `form:"url" binding:"required"`
 }
 type ChartValues struct {
-	Chart   string `form:"chart" binding:"required"`
-	Version string `form:"version" binding:"required"`
+	RepositoryId int64  `form:"repository_id"`
+	Chart        string `form:"chart" binding:"required"`
+	Version      string `form:"version" binding:"required"`
 }
 
 type ReleaseHistory struct {
 	Version int `form:"version"`
 }
 
+// CreateRepository URL 支持 http(s):// 与 oci://
 type CreateRepository struct {
-	Name     string `json:"name" binding:"required"`
-	URL      string `json:"url" binding:"required"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Name                  string   `json:"name" binding:"required"`
+	URL                   string   `json:"url" binding:"required"`
+	Username              string   `json:"username"`
+	Password              string   `json:"password"`
+	CAData                string   `json:"ca_data"`
+	InsecureSkipTLSVerify bool     `json:"insecure_skip_tls_verify"`
+	Charts                []string `json:"charts"` // OCI 仓库未开放 _catalog 时用于浏览的 chart 名称
 }
 
+// UpdateRepository password 为空时保留原密码，username 为空时同时清除密码
 type UpdateRepository struct {
-	Name            string `json:"name" binding:"required"`
-	URL             string `json:"url" binding:"required"`
-	Username        string `json:"username"`
-	Password        string `json:"password"`
-	ResourceVersion *int64 `json:"resource_version" binding:"required"`
+	Name                  string   `json:"name" binding:"required"`
+	URL                   string   `json:"url" binding:"required"`
+	Username              string   `json:"username"`
+	Password              string   `json:"password"`
+	CAData                string   `json:"ca_data"`
+	InsecureSkipTLSVerify bool     `json:"insecure_skip_tls_verify"`
+	Charts                []string `json:"charts"`
+	ResourceVersion       *int64   `json:"resource_version" binding:"required"`
+}
+
+// ReleaseDiffOptions from 为空时取 to 的上一个版本，to 为空时取当前版本
+type ReleaseDiffOptions struct {
+	From int `form:"from"`
+	To   int `form:"to"`
+}
+
+// ReleaseResourceChange 两个版本间发生变化的资源
+type ReleaseResourceChange struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Action    string `json:"action"` // added, removed, modified
+}
+
+type ReleaseDiff struct {
+	Name         string                  `json:"name"`
+	From         int                     `json:"from"`
+	To           int                     `json:"to"`
+	FromChart    string                  `json:"from_chart"`
+	ToChart      string                  `json:"to_chart"`
+	ValuesDiff   string                  `json:"values_diff"`   // 用户提供 values 的 unified diff
+	ManifestDiff string                  `json:"manifest_diff"` // 渲染后 manifest 的 unified diff
+	Resources    []ReleaseResourceChange `json:"resources"`
+}
+
+// ReleaseDriftResource 与 release 渲染结果不一致的线上资源
+type ReleaseDriftResource struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Status     string `json:"status"` // modified, deleted, unknown
+	Diff       string `json:"diff,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+type ReleaseDrift struct {
+	Name      string                 `json:"name"`
+	Revision  int                    `json:"revision"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Drifted   bool                   `json:"drifted"`
+	Total     int                    `json:"total"` // 参与比对的资源数
+	Resources []ReleaseDriftResource `json:"resources"`
+}
+
+type HelmApplicationId struct {
+	Id int64 `uri:"id" binding:"required"`
+}
+
+// CreateHelmApplication 多集群应用，目标集群为 clusters 与 cluster_selector 匹配结果的并集
+type CreateHelmApplication struct {
+	Name         string `json:"name" binding:"required"`
+	Description  string `json:"description"`
+	RepositoryId int64  `json:"repository_id"`
+	Chart        string `json:"chart" binding:"required"`
+	Version      string `json:"version" binding:"required"`
+	Namespace    string `json:"namespace" binding:"required"`
+	ReleaseName  string `json:"release_name"` // 为空时与应用名称相同
+
+	Values    map[string]interface{}            `json:"values"`
+	Overrides map[string]map[string]interface{} `json:"overrides"` // 集群名 -> 覆盖的 values
+
+	Clusters        []string `json:"clusters"`
+	ClusterSelector string   `json:"cluster_selector"` // kubernetes label selector，例如 env=prod,region in (bj,sh)
+
+	BatchSize        int `json:"batch_size" binding:"omitempty,min=1"`
+	FailureThreshold int `json:"failure_threshold" binding:"omitempty,min=0"`
+}
+
+// UpdateHelmApplication 命名空间与 release 名称创建后不可修改，更新后需重新发布才会生效
+type UpdateHelmApplication struct {
+	Description  string `json:"description"`
+	RepositoryId int64  `json:"repository_id"`
+	Chart        string `json:"chart" binding:"required"`
+	Version      string `json:"version" binding:"required"`
+
+	Values    map[string]interface{}            `json:"values"`
+	Overrides map[string]map[string]interface{} `json:"overrides"`
+
+	Clusters        []string `json:"clusters"`
+	ClusterSelector string   `json:"cluster_selector"`
+
+	BatchSize        int    `json:"batch_size" binding:"omitempty,min=1"`
+	FailureThreshold int    `json:"failure_threshold" binding:"omitempty,min=0"`
+	ResourceVersion  *int64 `json:"resource_version" binding:"required"`
+}
+
+type HelmApplication struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	UserId       int64  `json:"user_id"`
+	RepositoryId int64  `json:"repository_id"`
+	Chart        string `json:"chart"`
+	Version      string `json:"version"`
+	Namespace    string `json:"namespace"`
+	ReleaseName  string `json:"release_name"`
+
+	Values    map[string]interface{}            `json:"values"`
+	Overrides map[string]map[string]interface{} `json:"overrides"`
+
+	Clusters        []string `json:"clusters"`
+	ClusterSelector string   `json:"cluster_selector"`
+
+	BatchSize        int `json:"batch_size"`
+	FailureThreshold int `json:"failure_threshold"`
+
+	Status     string                  `json:"status"`
+	LastAction string                  `json:"last_action"`
+	Message    string                  `json:"message"`
+	Targets    []HelmApplicationTarget `json:"targets,omitempty"`
+}
+
+type HelmApplicationTarget struct {
+	Cluster          string     `json:"cluster"`
+	Status           string     `json:"status"`
+	Action           string     `json:"action"`
+	Batch            int        `json:"batch"`
+	ChartVersion     string     `json:"chart_version"`
+	Revision         int        `json:"revision"`
+	PreviousRevision int        `json:"previous_revision"`
+	Message          string     `json:"message"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// SearchCharts 跨仓库搜索 chart 目录，q 按空格分词匹配名称、描述与关键字
+type SearchCharts struct {
+	PageRequest `form:",inline"`
+
+	Query             string `form:"q"`
+	RepositoryId      int64  `form:"repository_id"`
+	Keyword           string `form:"keyword"`
+	IncludeDeprecated bool   `form:"include_deprecated"`
+}
+
+type ChartName struct {
+	Id    int64  `uri:"id" binding:"required"`
+	Chart string `uri:"chart" binding:"required"`
+}
+
+type HelmChart struct {
+	RepositoryId   int64              `json:"repository_id"`
+	RepositoryName string             `json:"repository_name"`
+	Name           string             `json:"name"`
+	LatestVersion  string             `json:"latest_version"`
+	AppVersion     string             `json:"app_version"`
+	Description    string             `json:"description"`
+	Icon           string             `json:"icon"`
+	Home           string             `json:"home"`
+	Keywords       []string           `json:"keywords"`
+	Deprecated     bool               `json:"deprecated"`
+	VersionCount   int                `json:"version_count"`
+	Created        *time.Time         `json:"created,omitempty"`
+	Dependencies   []model.Dependency `json:"dependencies,omitempty"`
+	// 仅详情接口返回
+	Versions []model.HelmChartVersion `json:"versions,omitempty"`
 }
@@ -17,7 +17,6 @@ limitations under the License.
 package types
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -121,9 +120,16 @@ func (t *TerminalSession) Read(p []byte) (int, error) {
 	switch msg.Operation {
 	// 如果是标准输入
 	case "stdin":
-		return copy(p, msg.Data), nil
+		n := copy(p, msg.Data)
+		if t.Recorder != nil {
+			t.Recorder.Input(p[:n])
+		}
+		return n, nil
 	// 窗口调整大小
 	case "resize":
+		if t.Recorder != nil {
+			t.Recorder.Resize(int(msg.Cols), int(msg.Rows))
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	// ping	无内容交互
@@ -146,6 +152,9 @@ func (t *TerminalSession) Write(p []byte) (int, error) {
 	if err = t.wsConn.WriteMessage(websocket.TextMessage, msg); err != nil {
 		return 0, err
 	}
+	if t.Recorder != nil {
+		t.Recorder.Output(p)
+	}
 	return len(p), nil
 }
 
@@ -206,7 +215,11 @@ func (t *Turn) Write(p []byte) (n int, err error) {
 	}
 	defer writer.Close()
 
-	return writer.Write(p)
+	n, err = writer.Write(p)
+	if t.Recorder != nil && n > 0 {
+		t.Recorder.Output(p[:n])
+	}
+	return n, err
 }
 
 func (t *Turn) Close() error {
@@ -229,15 +242,15 @@ func (t *Turn) Read(p []byte) (n int, err error) {
 	}
 }
 
-func (t *Turn) StartLoopRead(ctx context.Context, wg *sync.WaitGroup, logBuff *bytes.Buffer) {
+func (t *Turn) StartLoopRead(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	err := t.loopRead(logBuff, ctx)
+	err := t.loopRead(ctx)
 	if err != nil {
 		klog.Errorf("LoopRead exit, err:%s", err)
 	}
 }
 
-func (t *Turn) loopRead(logBuff *bytes.Buffer, context context.Context) error {
+func (t *Turn) loopRead(context context.Context) error {
 	for {
 		select {
 		case <-context.Done():
@@ -255,7 +268,7 @@ func (t *Turn) loopRead(logBuff *bytes.Buffer, context context.Context) error {
 					return err
 				}
 			case MsgData:
-				if err := t.dataDo(body, logBuff); err != nil {
+				if err := t.dataDo(body); err != nil {
 					return err
 				}
 			}
@@ -263,13 +276,13 @@ func (t *Turn) loopRead(logBuff *bytes.Buffer, context context.Context) error {
 	}
 }
 
-func (t *Turn) dataDo(body []byte, logBuff *bytes.Buffer) error {
+func (t *Turn) dataDo(body []byte) error {
 	if _, err := t.StdinPipe.Write(body); err != nil {
 		return fmt.Errorf("StdinPipe write err:%s", err)
 	}
 
-	if _, err := logBuff.Write(body); err != nil {
-		return fmt.Errorf("logBuff write err:%s", err)
+	if t.Recorder != nil {
+		t.Recorder.Input(body)
 	}
 	return nil
 }
@@ -290,6 +303,9 @@ func (t *Turn) resizeDo(body []byte) error {
 		if err := t.Session.WindowChange(args.Rows, args.Columns); err != nil {
 			return fmt.Errorf("ssh pty resize windows err:%s", err)
 		}
+		if t.Recorder != nil {
+			t.Recorder.Resize(args.Columns, args.Rows)
+		}
 	}
 	return nil
 }
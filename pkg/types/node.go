@@ -16,6 +16,8 @@ limitations under the License.
 
 package types
 
+import "time"
+
 // NodeAuthResult 节点认证对外返回（仅认证类型与端口，不含密钥/密码）
 type NodeAuthResult struct {
 	Type AuthType `json:"type"`
@@ -54,3 +56,29 @@ type UpdateNodeRequest struct {
 	Ip   *string       `json:"ip"`
 	Auth *PlanNodeAuth `json:"auth"`
 }
+
+// NodeHostKeyResult GET /pixiu/nodes/:nodeId/hostkey
+type NodeHostKeyResult struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// Recorded 是否已记录主机公钥，未记录时下次连接将首次信任（TOFU）
+	Recorded    bool   `json:"recorded"`
+	KeyType     string `json:"key_type,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	PublicKey   string `json:"public_key,omitempty"`
+	Pinned      bool   `json:"pinned"`
+
+	// 最近一次校验失败时收到的公钥
+	PendingKeyType     string `json:"pending_key_type,omitempty"`
+	PendingFingerprint string `json:"pending_fingerprint,omitempty"`
+	PendingPublicKey   string `json:"pending_public_key,omitempty"`
+
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// PinNodeHostKeyRequest POST /pixiu/nodes/:nodeId/hostkey/pin
+// PublicKey（authorized_keys 或 known_hosts 格式）与 Fingerprint（SHA256）二选一
+type PinNodeHostKeyRequest struct {
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"time"
+
+	"github.com/caoyingjunz/pixiu/pkg/db/model"
+)
+
+const (
+	PlanDocumentAPIVersion = "pixiu.io/v1"
+	PlanDocumentKind       = "Plan"
+
+	// RedactedSecret 导出时替换敏感字段的占位符，导入时表示沿用已有值
+	RedactedSecret = "<redacted>"
+	// PlanNodeAuthFromPrefix auth_from 引用主机清单中同 IP 节点的认证信息，如 host:10.0.0.1
+	PlanNodeAuthFromPrefix = "host:"
+)
+
+type PlanSecretMode string
+
+const (
+	PlanSecretRedact    PlanSecretMode = "redact"    // 敏感字段脱敏（默认）
+	PlanSecretReference PlanSecretMode = "reference" // 节点认证优先引用主机清单，其余脱敏
+)
+
+type PlanApplyAction string
+
+const (
+	PlanApplyCreate    PlanApplyAction = "create"
+	PlanApplyUpdate    PlanApplyAction = "update"
+	PlanApplyUnchanged PlanApplyAction = "unchanged"
+)
+
+// PlanDocument 部署计划声明式文档（plan-as-code），以 YAML 形式导出和导入
+type PlanDocument struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Metadata   PlanDocumentMetadata `json:"metadata"`
+	Spec       PlanDocumentSpec     `json:"spec"`
+}
+
+type PlanDocumentMetadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type PlanDocumentSpec struct {
+	ExecMode      model.PlanExecMode `json:"exec_mode,omitempty"`
+	DeployAgentId int64              `json:"deploy_agent_id,omitempty"`
+
+	Config PlanDocumentConfig `json:"config"`
+	Nodes  []PlanDocumentNode `json:"nodes"`
+}
+
+type PlanDocumentConfig struct {
+	Region  string `json:"region,omitempty"`
+	OSImage string `json:"os_image"`
+
+	Kubernetes KubernetesSpec `json:"kubernetes"`
+	Network    NetworkSpec    `json:"network"`
+	Runtime    RuntimeSpec    `json:"runtime"`
+	Component  ComponentSpec  `json:"component"`
+}
+
+// PlanDocumentNode auth 与 auth_from 二选一
+type PlanDocumentNode struct {
+	Name     string        `json:"name"`
+	Role     []string      `json:"role"`
+	CRI      model.CRI     `json:"cri,omitempty"`
+	Ip       string        `json:"ip"`
+	Auth     *PlanNodeAuth `json:"auth,omitempty"`
+	AuthFrom string        `json:"auth_from,omitempty"`
+}
+
+// ExportPlanOptions GET /pixiu/plans/:planId/export
+type ExportPlanOptions struct {
+	Secrets PlanSecretMode `form:"secrets" binding:"omitempty,oneof=redact reference"`
+}
+
+// ApplyPlanOptions POST /pixiu/plans/apply
+type ApplyPlanOptions struct {
+	DryRun bool `form:"dry_run"`
+}
+
+// ApplyPlanResult 导入结果，diff 中的敏感字段始终脱敏
+type ApplyPlanResult struct {
+	Action PlanApplyAction `json:"action"`
+	PlanId int64           `json:"plan_id,omitempty"`
+	DryRun bool            `json:"dry_run"`
+	Diff   string          `json:"diff"`
+}
+
+// EtcdBackupPolicy GET /pixiu/plans/:planId/etcd/policy
+type EtcdBackupPolicy struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	PlanId           int64                  `json:"plan_id"`
+	Enabled          bool                   `json:"enabled"`
+	Schedule         string                 `json:"schedule"`
+	Retention        int                    `json:"retention"`
+	Method           model.EtcdBackupMethod `json:"method"`
+	LastScheduleTime *time.Time             `json:"last_schedule_time,omitempty"`
+}
+
+// UpdateEtcdBackupPolicyRequest PUT /pixiu/plans/:planId/etcd/policy，策略不存在时创建
+type UpdateEtcdBackupPolicyRequest struct {
+	Enabled   bool                   `json:"enabled"`
+	Schedule  string                 `json:"schedule" binding:"required"` // 标准 5 段 cron 表达式
+	Retention int                    `json:"retention" binding:"omitempty,min=1,max=365"`
+	Method    model.EtcdBackupMethod `json:"method" binding:"omitempty,oneof=ssh pod"`
+}
+
+// CreateEtcdSnapshotRequest POST /pixiu/plans/:planId/etcd/snapshots
+type CreateEtcdSnapshotRequest struct {
+	Method model.EtcdBackupMethod `json:"method" binding:"omitempty,oneof=ssh pod"`
+}
+
+type EtcdSnapshot struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	PlanId     int64                     `json:"plan_id"`
+	Method     model.EtcdBackupMethod    `json:"method"`
+	Trigger    model.EtcdSnapshotTrigger `json:"trigger"`
+	Node       string                    `json:"node"`
+	Size       int64                     `json:"size"`
+	Checksum   string                    `json:"checksum"`
+	Status     model.EtcdSnapshotStatus  `json:"status"`
+	Message    string                    `json:"message,omitempty"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+}
+
+// RestoreEtcdRequest POST /pixiu/plans/:planId/etcd/restore
+// 恢复会停止整个控制面，需将 confirm 填写为部署计划名称；dry_run 仅返回恢复步骤
+type RestoreEtcdRequest struct {
+	SnapshotId int64  `json:"snapshot_id" binding:"required"`
+	Confirm    string `json:"confirm"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+type EtcdRestoreStep struct {
+	Name        string `json:"name"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// EtcdRestorePlan 恢复计划，恢复以部署任务方式执行，可通过任务接口查看进度与日志
+type EtcdRestorePlan struct {
+	Snapshot EtcdSnapshot      `json:"snapshot"`
+	Nodes    []string          `json:"nodes"` // 参与恢复的 master 节点
+	Steps    []EtcdRestoreStep `json:"steps"`
+	Started  bool              `json:"started"`
+}
+
+// CertificateLevel 证书剩余有效期等级
+type CertificateLevel string
+
+const (
+	CertificateOK       CertificateLevel = "ok"
+	CertificateWarning  CertificateLevel = "warning"
+	CertificateCritical CertificateLevel = "critical"
+	CertificateExpired  CertificateLevel = "expired"
+)
+
+type PlanCertificate struct {
+	Node         string                `json:"node"`
+	Name         string                `json:"name"`
+	Kind         model.CertificateKind `json:"kind"`
+	Path         string                `json:"path"`
+	Subject      string                `json:"subject"`
+	NotAfter     time.Time             `json:"not_after"`
+	ResidualDays int                   `json:"residual_days"`
+	Level        CertificateLevel      `json:"level"`
+}
+
+// PlanCertificates GET /pixiu/plans/:planId/certificates，按到期时间升序
+type PlanCertificates struct {
+	CheckedAt    *time.Time        `json:"checked_at,omitempty"`
+	Level        CertificateLevel  `json:"level"` // 所有证书中最严重的等级
+	Certificates []PlanCertificate `json:"certificates"`
+	// 部分节点采集失败时的错误信息
+	Message string `json:"message,omitempty"`
+}
+
+// RenewCertificatesRequest POST /pixiu/plans/:planId/certificates/renew
+// 续期会逐台重启控制面，需将 confirm 填写为部署计划名称
+type RenewCertificatesRequest struct {
+	Confirm string `json:"confirm"`
+}
@@ -73,16 +73,52 @@ type (
 		AgentToken  string            `json:"agent_token" binding:"omitempty"`            // optional, only for tunnel
 		Description string            `json:"description" binding:"omitempty"`            // optional
 		Protected   bool              `json:"protected" binding:"omitempty"`              // optional
+		// 隧道允许访问的集群内服务（svc.ns:port），仅隧道模式生效
+		TunnelTargets []string `json:"tunnel_targets" binding:"omitempty"` // optional
+		// 集群标签，键值需符合 kubernetes label 规范
+		Labels map[string]string `json:"labels" binding:"omitempty"` // optional
 
 		PermissionId   int64
 		OwnerReference int64
 	}
 
+	// ParseKubeconfigRequest 解析多上下文 kubeconfig，列出可导入的上下文
+	ParseKubeconfigRequest struct {
+		KubeConfig string `json:"kube_config" binding:"required"` // kubeconfig 文件 base64
+	}
+
+	// ImportClustersRequest 从多上下文 kubeconfig 批量导入集群
+	ImportClustersRequest struct {
+		UserId     int64           `json:"user_id"`
+		KubeConfig string          `json:"kube_config" binding:"required"` // kubeconfig 文件 base64
+		Contexts   []ImportContext `json:"contexts" binding:"required,min=1,dive"`
+		Protected  bool            `json:"protected" binding:"omitempty"` // optional
+		// 连通测试失败的上下文以隧道模式创建并返回 Agent 部署清单；为 false 时仅报告不可达
+		TunnelOnUnreachable bool `json:"tunnel_on_unreachable" binding:"omitempty"`
+	}
+
+	ImportContext struct {
+		Context     string `json:"context" binding:"required"`
+		Name        string `json:"name" binding:"omitempty"`       // optional，默认由上下文名生成
+		AliasName   string `json:"alias_name" binding:"omitempty"` // optional，默认为上下文名
+		Description string `json:"description" binding:"omitempty"`
+	}
+
 	UpdateClusterRequest struct {
 		ResourceVersion int64 `json:"resource_version"`
 
 		AliasName   *string `json:"alias_name" binding:"omitempty"`  // optional
 		Description *string `json:"description" binding:"omitempty"` // optional
+		// 隧道允许访问的集群内服务（svc.ns:port），仅隧道模式可设置，传空数组表示清空
+		TunnelTargets *[]string `json:"tunnel_targets" binding:"omitempty"` // optional
+		// 集群标签，整体替换，传空对象表示清空
+		Labels *map[string]string `json:"labels" binding:"omitempty"` // optional
+	}
+
+	// RotateCredentialRequest 为集群签发新的 Pixiu ServiceAccount token 并替换 kubeconfig 凭据
+	RotateCredentialRequest struct {
+		// ExpireHours token 有效期（小时），为空则使用 cluster_credential.token_expire_hours
+		ExpireHours int `json:"expire_hours" binding:"omitempty,min=1"`
 	}
 
 	ProtectClusterRequest struct {
@@ -98,6 +134,19 @@ type (
 		ExpiresAt *time.Time `json:"expires_at"`
 	}
 
+	// ClusterHealthQuery 集群健康历史查询条件。Start/End（RFC3339）同时指定时优先，
+	// 否则统计截至当前的最近 Window（Go duration，如 24h、720h，默认 720h）
+	ClusterHealthQuery struct {
+		Start  time.Time `form:"start" time_format:"2006-01-02T15:04:05Z07:00"`
+		End    time.Time `form:"end" time_format:"2006-01-02T15:04:05Z07:00"`
+		Window string    `form:"window"`
+
+		// 以下仅用于事件列表过滤
+		Type        string `form:"type"`   // status / probe / node
+		Object      string `form:"object"` // 节点名
+		PageRequest `form:",inline"`
+	}
+
 	CreateDatasourceRequest struct {
 		UserId      int64                   `json:"user_id"` // 关联用户（router 层从会话解析填充，不接受客户端指定）
 		Name        string                  `json:"name" binding:"required"`
@@ -154,6 +203,8 @@ type (
 		Protocol    string `json:"protocol" binding:"required"`
 		Description string `json:"description" binding:"omitempty"`
 		MaxTokens   int    `json:"max_tokens" binding:"omitempty"`
+		// ContextWindow 模型上下文窗口，需大于 MaxTokens，不填使用默认值
+		ContextWindow int `json:"context_window" binding:"omitempty,min=0"`
 	}
 
 	UpdateProviderRequest struct {
@@ -164,11 +215,13 @@ type (
 		Protocol    string `json:"protocol" binding:"required"`
 		Description string `json:"description" binding:"omitempty"`
 		MaxTokens   int    `json:"max_tokens" binding:"omitempty"`
+		// ContextWindow 模型上下文窗口，需大于 MaxTokens，不填使用默认值
+		ContextWindow int `json:"context_window" binding:"omitempty,min=0"`
 	}
 
 	CreateAIAccountRequest struct {
 		Name       string `json:"name" binding:"required"`
-		APIKey     string `json:"api_key" binding:"required"`
+		APIKey     string `json:"api_key" binding:"omitempty"`
 		Model      string `json:"model" binding:"required"`
 		ProviderId int64  `json:"provider_id" binding:"required"`
 	}
@@ -182,6 +235,59 @@ type (
 		ProviderId int64  `json:"provider_id" binding:"required"`
 	}
 
+	// PinConversationItemRequest Index 为消息在会话历史中的下标
+	PinConversationItemRequest struct {
+		PixiuMeta `json:",inline"`
+
+		Index  *int `json:"index" binding:"required,min=0"`
+		Pinned bool `json:"pinned"`
+	}
+
+	CreateAIQuotaRequest struct {
+		Scope       model.AIQuotaScope  `json:"scope" binding:"required,oneof=user tenant account"`
+		ScopeId     int64               `json:"scope_id" binding:"required"`
+		Period      model.AIQuotaPeriod `json:"period" binding:"required,oneof=daily monthly"`
+		TokenLimit  int64               `json:"token_limit" binding:"required,min=1"`
+		Description string              `json:"description" binding:"omitempty"`
+	}
+
+	UpdateAIQuotaRequest struct {
+		PixiuMeta `json:",inline"`
+
+		TokenLimit  int64  `json:"token_limit" binding:"required,min=1"`
+		Description string `json:"description" binding:"omitempty"`
+	}
+
+	// CreateAIModelPriceRequest 单价按每百万 token 计
+	CreateAIModelPriceRequest struct {
+		ProviderId       int64   `json:"provider_id" binding:"required"`
+		Model            string  `json:"model" binding:"required"`
+		Currency         string  `json:"currency" binding:"required,max=8"`
+		InputPrice       float64 `json:"input_price" binding:"min=0"`
+		CachedInputPrice float64 `json:"cached_input_price" binding:"min=0"`
+		OutputPrice      float64 `json:"output_price" binding:"min=0"`
+	}
+
+	UpdateAIModelPriceRequest struct {
+		PixiuMeta `json:",inline"`
+
+		Currency         string  `json:"currency" binding:"required,max=8"`
+		InputPrice       float64 `json:"input_price" binding:"min=0"`
+		CachedInputPrice float64 `json:"cached_input_price" binding:"min=0"`
+		OutputPrice      float64 `json:"output_price" binding:"min=0"`
+	}
+
+	// AIUsageReportRequest start / end 格式为 2006-01-02，end 当天包含在内
+	AIUsageReportRequest struct {
+		GroupBy   string `form:"group_by" binding:"required,oneof=user tenant model day"`
+		Start     string `form:"start" binding:"omitempty"`
+		End       string `form:"end" binding:"omitempty"`
+		UserId    int64  `form:"user_id" binding:"omitempty"`
+		TenantId  int64  `form:"tenant_id" binding:"omitempty"`
+		AccountId int64  `form:"account_id" binding:"omitempty"`
+		Model     string `form:"model" binding:"omitempty"`
+	}
+
 	CreateTenantRequest struct {
 		Name        string  `json:"name" binding:"required"`         // required
 		Description *string `json:"description" binding:"omitempty"` // optional
@@ -318,6 +424,11 @@ type (
 		Input          string `json:"input" binding:"required"`
 	}
 
+	// AIExecutionDecisionRequest 确认或拒绝助手发起的变更操作
+	AIExecutionDecisionRequest struct {
+		Reason string `json:"reason"`
+	}
+
 	// WebSSHRequest 主机 ssh 跳转请求
 	WebSSHRequest struct {
 		Host       string `form:"host" json:"host" binding:"required"`
@@ -344,6 +455,8 @@ type (
 		Port      int    `json:"port"`
 		User      string `json:"user"`
 		Message   string `json:"message"`
+		// HostKeyMismatch 主机公钥与记录不一致，需确认后在节点主机公钥中接受或重置
+		HostKeyMismatch bool `json:"host_key_mismatch,omitempty"`
 	}
 
 	ClusterWebRequest struct {
@@ -356,6 +469,9 @@ type (
 // SetUserID 实现 UserIDSetter 接口。
 func (r *CreateClusterRequest) SetUserID(id int64) { r.UserId = id }
 
+// SetUserID 实现 UserIDSetter 接口。
+func (r *ImportClustersRequest) SetUserID(id int64) { r.UserId = id }
+
 // SetUserID 实现 UserIDSetter 接口。
 func (r *CreatePlanRequest) SetUserID(id int64) { r.UserId = id }
 
@@ -181,12 +181,16 @@ type Cluster struct {
 	AgentConnected bool `json:"agent_connected,omitempty"`
 	// Agent 建连 token（仅创建/详情时返回，用于安装 Agent）
 	AgentToken string `json:"agent_token,omitempty"`
+	// 隧道允许访问的集群内服务（仅隧道模式）
+	TunnelTargets []string `json:"tunnel_targets,omitempty"`
 
 	// k8s kubeConfig base64 字段
 	KubeConfig string `json:"kube_config,omitempty"`
 
 	// 集群用途描述，可以为空
 	Description string `json:"description"`
+	// 集群标签
+	Labels map[string]string `json:"labels,omitempty"`
 
 	// 集群连通性探测状态（Ready condition 语义），由 syncer 定期维护
 	ProbeStatus   model.ClusterProbeStatus `json:"probe_status"`
@@ -194,10 +198,22 @@ type Cluster struct {
 	ProbeMessage  string                   `json:"probe_message"`
 	LastProbeTime *time.Time               `json:"last_probe_time,omitempty"`
 
+	// kubeconfig 凭据类型、过期时间与剩余天数（无法获知或永不过期时为空）
+	CredentialType      string     `json:"credential_type,omitempty"`
+	CredentialExpiresAt *time.Time `json:"credential_expires_at,omitempty"`
+	CredentialDaysLeft  *int       `json:"credential_days_left,omitempty"`
+
 	KubernetesMeta `json:",inline"`
 	TimeMeta       `json:",inline"`
 }
 
+// CredentialSyncResult 一次集群凭据同步的统计
+type CredentialSyncResult struct {
+	Checked int
+	Rotated int
+	Warned  int
+}
+
 type Datasource struct {
 	PixiuMeta `json:",inline"`
 	TimeMeta  `json:",inline"`
@@ -237,12 +253,13 @@ type AIProvider struct {
 	PixiuMeta `json:",inline"`
 	TimeMeta  `json:",inline"`
 
-	Name        string `json:"name"`
-	BaseURL     string `json:"base_url"`
-	Protocol    string `json:"protocol"`
-	Description string `json:"description"`
-	MaxTokens   int    `json:"max_tokens"`
-	Builtin     bool   `json:"builtin"`
+	Name          string `json:"name"`
+	BaseURL       string `json:"base_url"`
+	Protocol      string `json:"protocol"`
+	Description   string `json:"description"`
+	MaxTokens     int    `json:"max_tokens"`
+	ContextWindow int    `json:"context_window"`
+	Builtin       bool   `json:"builtin"`
 }
 
 type AIAccount struct {
@@ -273,22 +290,64 @@ type Message struct {
 	PixiuMeta `json:",inline"`
 	TimeMeta  `json:",inline"`
 
-	RequestId       string `json:"request_id"`
-	ProviderId      int64  `json:"provider_id"`
-	ConversationId  int64  `json:"conversation_id"`
-	Provider        string `json:"provider"`
-	Model           string `json:"model"`
-	ResponseId      string `json:"response_id"`
-	InputText       string `json:"input_text"`
-	OutputText      string `json:"output_text"`
-	Success         bool   `json:"success"`
-	ErrorMessage    string `json:"error_message"`
-	Duration        int64  `json:"duration"`
-	InputTokens     int64  `json:"input_tokens"`
-	OutputTokens    int64  `json:"output_tokens"`
-	TotalTokens     int64  `json:"total_tokens"`
-	CachedTokens    int64  `json:"cached_tokens"`
-	ReasoningTokens int64  `json:"reasoning_tokens"`
+	RequestId       string  `json:"request_id"`
+	ProviderId      int64   `json:"provider_id"`
+	ConversationId  int64   `json:"conversation_id"`
+	Provider        string  `json:"provider"`
+	Model           string  `json:"model"`
+	ResponseId      string  `json:"response_id"`
+	InputText       string  `json:"input_text"`
+	OutputText      string  `json:"output_text"`
+	Success         bool    `json:"success"`
+	ErrorMessage    string  `json:"error_message"`
+	Duration        int64   `json:"duration"`
+	InputTokens     int64   `json:"input_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	TotalTokens     int64   `json:"total_tokens"`
+	CachedTokens    int64   `json:"cached_tokens"`
+	ReasoningTokens int64   `json:"reasoning_tokens"`
+	UserId          int64   `json:"user_id"`
+	TenantId        int64   `json:"tenant_id"`
+	AccountId       int64   `json:"account_id"`
+	Cost            float64 `json:"cost"`
+	Currency        string  `json:"currency"`
+}
+
+type AIQuota struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	Scope       string `json:"scope"`
+	ScopeId     int64  `json:"scope_id"`
+	Period      string `json:"period"`
+	TokenLimit  int64  `json:"token_limit"`
+	Used        int64  `json:"used"` // 当前周期已消耗的 token
+	Description string `json:"description"`
+}
+
+type AIModelPrice struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	ProviderId       int64   `json:"provider_id"`
+	Model            string  `json:"model"`
+	Currency         string  `json:"currency"`
+	InputPrice       float64 `json:"input_price"`
+	CachedInputPrice float64 `json:"cached_input_price"`
+	OutputPrice      float64 `json:"output_price"`
+}
+
+// AIUsageItem 用量报表中的一行，Key 为分组维度的值
+type AIUsageItem struct {
+	Key             string  `json:"key"`
+	Currency        string  `json:"currency"`
+	Requests        int64   `json:"requests"`
+	InputTokens     int64   `json:"input_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	TotalTokens     int64   `json:"total_tokens"`
+	CachedTokens    int64   `json:"cached_tokens"`
+	ReasoningTokens int64   `json:"reasoning_tokens"`
+	Cost            float64 `json:"cost"`
 }
 
 type AIRespondResponse struct {
@@ -312,6 +371,7 @@ type AIStreamEvent struct {
 	ToolOutput     string      `json:"tool_output,omitempty"`
 	ConversationId int64       `json:"conversation_id,omitempty"`
 	ResponseId     string      `json:"response_id,omitempty"`
+	ExecutionId    int64       `json:"execution_id,omitempty"`
 	Raw            interface{} `json:"raw,omitempty"`
 }
 
@@ -465,6 +525,47 @@ type Audit struct {
 	Cluster           string                     `json:"cluster"`            // K8s 集群名
 	ResourceName      string                     `json:"resource_name"`      // 资源名称
 	ResourceNamespace string                     `json:"resource_namespace"` // 资源命名空间
+	Resource          string                     `json:"resource"`           // K8s 资源类型
+	TokenJTI          string                     `json:"token_jti"`          // kube gateway 访问令牌 JTI
+	PrevHash          string                     `json:"prev_hash"`          // 哈希链上一条记录的哈希
+	Hash              string                     `json:"hash"`               // 本条记录的哈希
+}
+
+// AuditObjectDiff 经集群代理更新 K8s 对象的前后快照与差异（Secret 数据已脱敏）
+type AuditObjectDiff struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	AuditId    int64  `json:"audit_id"`
+	Cluster    string `json:"cluster"`
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Diff       string `json:"diff"`
+}
+
+// TerminalRecording 交互式终端会话录像，回放内容为 asciicast v2 格式
+type TerminalRecording struct {
+	PixiuMeta `json:",inline"`
+	TimeMeta  `json:",inline"`
+
+	AuditId   int64                     `json:"audit_id"`
+	Kind      model.TerminalSessionKind `json:"kind"` // pod / node / cloudshell
+	UserId    int64                     `json:"user_id"`
+	Operator  string                    `json:"operator"`
+	Ip        string                    `json:"ip"`
+	Cluster   string                    `json:"cluster"`
+	Namespace string                    `json:"namespace"`
+	Pod       string                    `json:"pod"`
+	Container string                    `json:"container"`
+	Node      string                    `json:"node"`
+	Size      int64                     `json:"size"`     // 录像大小（字节）
+	Duration  int64                     `json:"duration"` // 会话时长（秒）
+	StartedAt time.Time                 `json:"started_at"`
+	EndedAt   *time.Time                `json:"ended_at,omitempty"`
 }
 
 type AuthType string
@@ -586,16 +687,29 @@ type TerminalMessage struct {
 // wsConn 是 websocket 连接
 // sizeChan 用来定义终端输入和输出的宽和高
 // doneChan 用于标记退出终端
+// TerminalRecorder 终端会话录像，记录输入、输出与窗口大小变化
+type TerminalRecorder interface {
+	Input(p []byte)
+	Output(p []byte)
+	Resize(cols, rows int)
+}
+
 type TerminalSession struct {
 	wsConn   *websocket.Conn
 	sizeChan chan remotecommand.TerminalSize
 	doneChan chan struct{}
+
+	// Recorder 非空时记录会话
+	Recorder TerminalRecorder
 }
 
 type Turn struct {
 	StdinPipe io.WriteCloser
 	Session   *ssh.Session
 	WsConn    *websocket.Conn
+
+	// Recorder 非空时记录会话
+	Recorder TerminalRecorder
 }
 
 // ListOptions is the query options to a standard REST list call.
@@ -649,8 +763,22 @@ type CustomMeta struct {
 	StartTime  string `form:"start_time" json:"start_time"`
 	EndTime    string `form:"end_time" json:"end_time"`
 
+	Resource          string `form:"resource" json:"resource"`
+	ResourceName      string `form:"resource_name" json:"resource_name"`
+	ResourceNamespace string `form:"resource_namespace" json:"resource_namespace"`
+	TokenJTI          string `form:"token_jti" json:"token_jti"`
+
+	// terminal session
+	SessionKind string `form:"session_kind" json:"session_kind"`
+	Pod         string `form:"pod" json:"pod"`
+	Node        string `form:"node" json:"node"`
+
 	// agent
 	AgentStatus *model.AgentStatus `form:"agent_status" json:"agent_status"`
+
+	// etcd snapshot
+	SnapshotStatus model.EtcdSnapshotStatus  `form:"snapshot_status" json:"snapshot_status"`
+	Trigger        model.EtcdSnapshotTrigger `form:"trigger" json:"trigger"`
 }
 
 func (o *ListOptions) SetDefaultPageOption() {
@@ -888,6 +1016,103 @@ type KubeConfigResponse struct {
 	Content     string `json:"content"`
 }
 
+// KubeconfigContext kubeconfig 中的一个上下文及其可导入性
+type KubeconfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Current   bool   `json:"current"`
+
+	// AuthType 凭据类型：token / client-certificate / basic / exec / auth-provider / none
+	AuthType  string `json:"auth_type"`
+	Supported bool   `json:"supported"`
+	Reason    string `json:"reason,omitempty"` // 不支持导入的原因
+
+	// ExistingCluster 已接入的同名或同 server 集群名，非空表示重复
+	ExistingCluster string `json:"existing_cluster,omitempty"`
+}
+
+const (
+	ImportStatusCreated     = "created"     // 直连创建成功
+	ImportStatusTunnel      = "tunnel"      // 不可达，已按隧道模式创建，待部署 Agent
+	ImportStatusSkipped     = "skipped"     // 重复，跳过
+	ImportStatusUnreachable = "unreachable" // 不可达，未创建
+	ImportStatusFailed      = "failed"      // 不支持或创建失败
+)
+
+type ImportContextResult struct {
+	Context   string `json:"context"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	ClusterId int64  `json:"cluster_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// AgentManifest 隧道模式集群的 cluster-agent 部署清单（YAML），仅 status=tunnel 时返回
+	AgentManifest string `json:"agent_manifest,omitempty"`
+}
+
+type ImportClustersResult struct {
+	Created     int                   `json:"created"`
+	Tunnel      int                   `json:"tunnel"`
+	Skipped     int                   `json:"skipped"`
+	Unreachable int                   `json:"unreachable"`
+	Failed      int                   `json:"failed"`
+	Items       []ImportContextResult `json:"items"`
+}
+
+// ClusterEvent 集群健康状态迁移事件
+type ClusterEvent struct {
+	Id          int64                  `json:"id"`
+	ClusterId   int64                  `json:"cluster_id"`
+	ClusterName string                 `json:"cluster_name"`
+	Type        model.ClusterEventType `json:"type"`
+	Source      string                 `json:"source"`
+	Object      string                 `json:"object,omitempty"`
+	From        string                 `json:"from"`
+	To          string                 `json:"to"`
+	Reason      string                 `json:"reason"`
+	Message     string                 `json:"message"`
+	GmtCreate   time.Time              `json:"gmt_create"`
+}
+
+// ClusterAvailability 集群在统计窗口内的可用率。
+// 仅 Running 计为可用、Error 计为不可用，部署中/等待接入等其他状态不计入分母。
+type ClusterAvailability struct {
+	ClusterId   int64     `json:"cluster_id"`
+	ClusterName string    `json:"cluster_name"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+
+	UpSeconds    int64 `json:"up_seconds"`
+	DownSeconds  int64 `json:"down_seconds"`
+	OtherSeconds int64 `json:"other_seconds"`
+	// Availability 可用率百分比，窗口内无可用/不可用时长时为空
+	Availability *float64 `json:"availability,omitempty"`
+
+	Outages              int    `json:"outages"`                // Running -> Error 次数
+	LongestOutageSeconds int64  `json:"longest_outage_seconds"` // 最长一次不可用时长
+	Transitions          int    `json:"transitions"`            // 窗口内状态变化次数
+	Current              string `json:"current"`                // 窗口结束时的状态
+}
+
+// NodeFlap 节点在统计窗口内的 Ready/NotReady 抖动情况
+type NodeFlap struct {
+	Node          string    `json:"node"`
+	Flaps         int       `json:"flaps"`           // 状态变化次数
+	NotReadyCount int       `json:"not_ready_count"` // 变为 NotReady 的次数
+	LastState     string    `json:"last_state"`
+	LastChange    time.Time `json:"last_change"`
+}
+
+type ClusterNodeFlaps struct {
+	ClusterId   int64      `json:"cluster_id"`
+	ClusterName string     `json:"cluster_name"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	Nodes       []NodeFlap `json:"nodes"`
+}
+
 // ProxyKubeconfigResponse 指向 Pixiu 网关的标准 kubeconfig（token 仅返回一次）
 type ProxyKubeconfigResponse struct {
 	ClusterId          int64  `json:"cluster_id"`
@@ -936,5 +1161,19 @@ type AgentJobLogsRequest struct {
 type AgentJobResultRequest struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
-	Result  string `json:"result"` // 如 kubeconfig base64
+	Result  string `json:"result"` // 如 FetchKubeconfigResult JSON
+}
+
+// FetchKubeconfigResult fetch_kubeconfig 作业结果
+type FetchKubeconfigResult struct {
+	KubeConfig string `json:"kube_config"` // kubeconfig base64
+	// HostKey Agent 首次信任（TOFU）的 master 主机公钥，由控制面记录为节点主机公钥
+	HostKey *AgentHostKey `json:"host_key,omitempty"`
+}
+
+// AgentHostKey Agent 连接节点时观测到的 SSH 主机公钥
+type AgentHostKey struct {
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	PublicKey string `json:"public_key"` // authorized_keys 格式
 }
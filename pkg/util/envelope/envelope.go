@@ -0,0 +1,172 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envelope 实现敏感字段的信封加密：每个值使用随机生成的数据密钥（DEK）以 AES-256-GCM 加密，
+// DEK 再由密钥加密密钥（KEK）加密后与密文一起保存。KEK 由 KeyProvider 提供，默认为本地密钥文件，可替换为 KMS。
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prefix 加密值的前缀，格式为 enc:v1:<key-id>:<base64(加密后的 DEK)>:<base64(nonce|密文)>
+const Prefix = "enc:v1:"
+
+const dekSize = 32
+
+// KeyProvider 提供密钥加密密钥（KEK），负责加解密数据密钥。
+type KeyProvider interface {
+	// PrimaryKeyID 返回当前用于加密新数据的主密钥标识
+	PrimaryKeyID() string
+	// WrapKey 使用主密钥加密数据密钥，返回所用密钥标识
+	WrapKey(dek []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey 使用 keyID 对应的密钥解密数据密钥
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+type Envelope struct {
+	provider KeyProvider
+}
+
+func New(provider KeyProvider) *Envelope {
+	return &Envelope{provider: provider}
+}
+
+// PrimaryKeyID 返回当前主密钥标识
+func (e *Envelope) PrimaryKeyID() string {
+	return e.provider.PrimaryKeyID()
+}
+
+// Encrypt 加密明文，空串与已加密的值原样返回
+func (e *Envelope) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+
+	dek := make([]byte, dekSize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", err
+	}
+	defer zero(dek)
+
+	keyID, wrapped, err := e.provider.WrapKey(dek)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %v", err)
+	}
+	sealed, err := seal(dek, []byte(plaintext), []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+
+	return Prefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密由 Encrypt 生成的值，未加密的值（历史明文数据）原样返回
+func (e *Envelope) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	keyID := parts[0]
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted data key: %v", err)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted data: %v", err)
+	}
+
+	dek, err := e.provider.UnwrapKey(keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key with key %q: %v", keyID, err)
+	}
+	defer zero(dek)
+
+	plaintext, err := open(dek, sealed, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %v", keyID, err)
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted 判断值是否为 Envelope 加密格式
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// KeyIDOf 返回加密值所使用的密钥标识，未加密时返回空
+func KeyIDOf(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	rest := strings.TrimPrefix(value, Prefix)
+	if i := strings.Index(rest, ":"); i >= 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+// seal 以 AES-GCM 加密，返回 nonce|密文
+func seal(key, plaintext, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(key, sealed, additional []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additional)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustProvider(t *testing.T, ids ...string) *LocalKeyProvider {
+	t.Helper()
+	var lines []string
+	for _, id := range ids {
+		line, err := GenerateKeyLine(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	p, err := ParseKeys([]byte("# pixiu keys\n" + strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	e := New(mustProvider(t, "k1"))
+
+	plaintext := "apiVersion: v1\nkind: Config"
+	enc, err := e.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "kind: Config") {
+		t.Fatalf("value is not encrypted: %s", enc)
+	}
+	if got := KeyIDOf(enc); got != "k1" {
+		t.Fatalf("KeyIDOf = %q, want k1", got)
+	}
+	again, err := e.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == enc {
+		t.Fatal("encrypting twice should produce different ciphertexts")
+	}
+
+	dec, err := e.Decrypt(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != plaintext {
+		t.Fatalf("Decrypt = %q, want %q", dec, plaintext)
+	}
+
+	// 已加密的值不重复加密，空串与历史明文原样返回
+	if v, _ := e.Encrypt(enc); v != enc {
+		t.Fatal("encrypted value should not be encrypted again")
+	}
+	if v, _ := e.Encrypt(""); v != "" {
+		t.Fatal("empty value should stay empty")
+	}
+	if v, _ := e.Decrypt("legacy-plaintext"); v != "legacy-plaintext" {
+		t.Fatal("plaintext should be returned as is")
+	}
+}
+
+func TestDecryptAfterRotation(t *testing.T) {
+	old := mustProvider(t, "k1")
+	enc, err := New(old).Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新主密钥在前，旧密钥保留用于解密
+	rotated := &LocalKeyProvider{primary: "k2", keys: map[string][]byte{"k1": old.keys["k1"]}}
+	rotated.keys["k2"] = mustProvider(t, "k2").keys["k2"]
+	e := New(rotated)
+
+	dec, err := e.Decrypt(enc)
+	if err != nil || dec != "secret" {
+		t.Fatalf("Decrypt = %q, %v", dec, err)
+	}
+	reenc, err := e.Encrypt(dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if KeyIDOf(reenc) != "k2" {
+		t.Fatalf("re-encrypted with %q, want k2", KeyIDOf(reenc))
+	}
+
+	// 旧密钥移除后无法解密旧密文
+	if _, err = New(mustProvider(t, "k2")).Decrypt(enc); err == nil {
+		t.Fatal("expected error when key is missing")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	e := New(mustProvider(t, "k1"))
+	enc, err := e.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := enc[:len(enc)-2] + "AA"
+	if tampered == enc {
+		tampered = enc[:len(enc)-2] + "BB"
+	}
+	if _, err = e.Decrypt(tampered); err == nil {
+		t.Fatal("expected error for tampered ciphertext")
+	}
+	if _, err = e.Decrypt(Prefix + "k1:broken"); err == nil {
+		t.Fatal("expected error for malformed value")
+	}
+}
+
+func TestParseKeysInvalid(t *testing.T) {
+	line, _ := GenerateKeyLine("k1")
+	cases := map[string]string{
+		"empty":        "# only comment\n",
+		"no separator": "k1",
+		"bad id":       "k:1:" + strings.SplitN(line, ":", 2)[1],
+		"short key":    "k1:c2hvcnQ=",
+		"duplicate":    line + "\n" + line,
+	}
+	for name, data := range cases {
+		if _, err := ParseKeys([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const kekSize = 32
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// LocalKeyProvider 基于本地密钥文件的 KeyProvider。
+//
+// 密钥文件每行一个密钥，格式为 "<key-id>:<base64 编码的 32 字节密钥>"，# 开头为注释。
+// 第一个密钥为主密钥，用于加密新数据；其余密钥仅用于解密历史数据，
+// 轮换时将新密钥写在首行，执行 pixiu-server encryption rotate 后即可移除旧密钥。
+type LocalKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// LoadKeyFile 读取本地密钥文件
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %v", err)
+	}
+	p, err := ParseKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key file %s: %v", path, err)
+	}
+	return p, nil
+}
+
+// ParseKeys 解析密钥文件内容
+func ParseKeys(data []byte) (*LocalKeyProvider, error) {
+	p := &LocalKeyProvider{keys: make(map[string][]byte)}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected <key-id>:<base64 key>", lineNo)
+		}
+		id = strings.TrimSpace(id)
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("line %d: invalid key id %q", lineNo, id)
+		}
+		if _, exists := p.keys[id]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key id %q", lineNo, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		if len(key) != kekSize {
+			return nil, fmt.Errorf("line %d: key must be %d bytes, got %d", lineNo, kekSize, len(key))
+		}
+		if p.primary == "" {
+			p.primary = id
+		}
+		p.keys[id] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.primary == "" {
+		return nil, fmt.Errorf("no key found")
+	}
+	return p, nil
+}
+
+func (p *LocalKeyProvider) PrimaryKeyID() string {
+	return p.primary
+}
+
+func (p *LocalKeyProvider) WrapKey(dek []byte) (string, []byte, error) {
+	wrapped, err := seal(p.keys[p.primary], dek, []byte(p.primary))
+	if err != nil {
+		return "", nil, err
+	}
+	return p.primary, wrapped, nil
+}
+
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in key file", keyID)
+	}
+	return open(key, wrapped, []byte(keyID))
+}
+
+// GenerateKeyLine 生成一行可写入密钥文件的新密钥
+func GenerateKeyLine(keyID string) (string, error) {
+	if !keyIDPattern.MatchString(keyID) {
+		return "", fmt.Errorf("invalid key id %q, only letters, digits, '-' and '_' are allowed", keyID)
+	}
+	key := make([]byte, kekSize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return keyID + ":" + base64.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeyStore 主机公钥存储，校验 SSH 服务端公钥
+type HostKeyStore interface {
+	Verify(ctx context.Context, host string, port int, key ssh.PublicKey) error
+}
+
+var (
+	hostKeyStoreMu sync.RWMutex
+	hostKeyStore   HostKeyStore
+
+	// ErrHostKeyStoreNotSet 未设置主机公钥存储时拒绝连接，避免静默跳过主机公钥校验
+	ErrHostKeyStoreNotSet = errors.New("ssh host key store is not configured, refuse to connect without host key verification")
+)
+
+// SetHostKeyStore 设置全局主机公钥存储，服务启动时调用
+func SetHostKeyStore(store HostKeyStore) {
+	hostKeyStoreMu.Lock()
+	defer hostKeyStoreMu.Unlock()
+	hostKeyStore = store
+}
+
+// HostKeyCallback 返回基于主机公钥存储的校验回调；未设置存储时拒绝所有连接
+func HostKeyCallback() ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		host, portStr, err := net.SplitHostPort(hostname)
+		if err != nil {
+			return err
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return err
+		}
+		return VerifyHostKey(context.TODO(), host, port, key)
+	}
+}
+
+// VerifyHostKey 按全局主机公钥存储校验（或首次记录）公钥，用于未经本进程 SSH 连接、
+// 由部署 Agent 上报的主机公钥；未设置存储时返回 ErrHostKeyStoreNotSet
+func VerifyHostKey(ctx context.Context, host string, port int, key ssh.PublicKey) error {
+	hostKeyStoreMu.RLock()
+	store := hostKeyStore
+	hostKeyStoreMu.RUnlock()
+	if store == nil {
+		return ErrHostKeyStoreNotSet
+	}
+	return store.Verify(ctx, host, port, key)
+}
+
+// HostKeyMismatchError 主机公钥与记录不一致
+type HostKeyMismatchError struct {
+	Host     string
+	Port     int
+	Expected string
+	Actual   string
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("主机 %s:%d 的 SSH 公钥与记录不一致（记录 %s，实际 %s），可能存在中间人攻击；"+
+		"如确认主机已重装或更换了公钥，请在节点主机公钥中接受新公钥或重置后重试", e.Host, e.Port, e.Expected, e.Actual)
+}
+
+// NormalizeFingerprint 规范化 SHA256 指纹，支持省略 SHA256: 前缀
+func NormalizeFingerprint(fingerprint string) (string, error) {
+	fp := strings.TrimSpace(fingerprint)
+	fp = strings.TrimPrefix(fp, "SHA256:")
+	fp = strings.TrimRight(fp, "=")
+	b, err := base64.RawStdEncoding.DecodeString(fp)
+	if err != nil || len(b) != 32 {
+		return "", fmt.Errorf("无效的 SHA256 指纹: %s", fingerprint)
+	}
+	return "SHA256:" + fp, nil
+}
+
+// ParsePublicKey 解析 authorized_keys 或 known_hosts 格式的公钥
+func ParsePublicKey(data string) (ssh.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(data))
+	if err == nil {
+		return key, nil
+	}
+	if _, _, key, _, _, err = ssh.ParseKnownHosts([]byte(data)); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("无效的 SSH 公钥: %v", err)
+}
+
+// MarshalPublicKey 以 authorized_keys 格式输出公钥（不含换行）
+func MarshalPublicKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNormalizeFingerprint(t *testing.T) {
+	const fp = "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "with prefix", input: fp, want: fp},
+		{name: "without prefix", input: "nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8", want: fp},
+		{name: "padded and spaced", input: "  " + fp + "=  ", want: fp},
+		{name: "md5 fingerprint", input: "16:27:ac:a5:76:28:2d:36:63:1b:56:4d:eb:df:a6:48", wantErr: true},
+		{name: "wrong length", input: "SHA256:AAAA", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeFingerprint(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeFingerprint(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NormalizeFingerprint(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFingerprintMatchesSSH(t *testing.T) {
+	key := newTestPublicKey(t)
+	want := ssh.FingerprintSHA256(key)
+	got, err := NormalizeFingerprint(want[len("SHA256:"):])
+	if err != nil || got != want {
+		t.Fatalf("NormalizeFingerprint() = %q, %v, want %q", got, err, want)
+	}
+}
+
+type recordingStore struct {
+	host string
+	port int
+	err  error
+}
+
+func (s *recordingStore) Verify(_ context.Context, host string, port int, _ ssh.PublicKey) error {
+	s.host, s.port = host, port
+	return s.err
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	key := newTestPublicKey(t)
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 2222}
+
+	SetHostKeyStore(nil)
+	if err := HostKeyCallback()("10.0.0.1:2222", addr, key); !errors.Is(err, ErrHostKeyStoreNotSet) {
+		t.Fatalf("expected fail closed without store, got %v", err)
+	}
+
+	store := &recordingStore{}
+	SetHostKeyStore(store)
+	defer SetHostKeyStore(nil)
+	if err := HostKeyCallback()("10.0.0.1:2222", addr, key); err != nil {
+		t.Fatal(err)
+	}
+	if store.host != "10.0.0.1" || store.port != 2222 {
+		t.Fatalf("store verified %s:%d, want 10.0.0.1:2222", store.host, store.port)
+	}
+
+	store.err = &HostKeyMismatchError{Host: "10.0.0.1", Port: 2222}
+	if err := HostKeyCallback()("10.0.0.1:2222", addr, key); err == nil {
+		t.Fatal("expected mismatch to reject the connection")
+	}
+}
+
+func newTestPublicKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The Pixiu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/caoyingjunz/pixiu/pkg/types"
+)
+
+// RunScript 在节点上执行脚本，ctx 结束时断开连接以中止执行
+func RunScript(ctx context.Context, ip string, auth *types.PlanNodeAuth, script string, stdin io.Reader, stdout, stderr io.Writer) error {
+	req, err := ResolveAuth(auth)
+	if err != nil {
+		return err
+	}
+	req.Host = ip
+
+	client, err := NewSSHClient(req)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(script) }()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		_ = client.Close()
+		return fmt.Errorf("执行中止: %v", ctx.Err())
+	}
+}
@@ -35,7 +35,7 @@ func NewSSHClient(sshConfig *types.WebSSHRequest) (*ssh.Client, error) {
 	cfg := &ssh.ClientConfig{
 		Timeout:         time.Second * 5,
 		User:            sshConfig.User,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: HostKeyCallback(),
 	}
 
 	if sshConfig.PrivateKey != "" {